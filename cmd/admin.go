@@ -53,6 +53,8 @@ var (
 		Subcommands: []*cli.Command{
 			microcmdAuthAddOauth,
 			microcmdAuthUpdateOauth,
+			microcmdAuthAddSAML,
+			microcmdAuthUpdateSAML,
 			microcmdAuthAddLdapBindDn,
 			microcmdAuthUpdateLdapBindDn,
 			microcmdAuthAddLdapSimpleAuth,
@@ -0,0 +1,263 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/auth/source/saml"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	samlCLIFlags = []cli.Flag{
+		&cli.StringFlag{
+			Name:  "name",
+			Value: "",
+			Usage: "Authentication name",
+		},
+		&cli.StringFlag{
+			Name:  "idp-metadata-url",
+			Value: "",
+			Usage: "URL of the identity provider metadata",
+		},
+		&cli.StringFlag{
+			Name:  "idp-metadata-file",
+			Value: "",
+			Usage: "File containing the identity provider metadata, takes precedence over the metadata URL",
+		},
+		&cli.StringFlag{
+			Name:  "sp-certificate-file",
+			Value: "",
+			Usage: "File containing the PEM encoded service provider certificate, a self-signed one is generated if it's not set",
+		},
+		&cli.StringFlag{
+			Name:  "sp-private-key-file",
+			Value: "",
+			Usage: "File containing the PEM encoded service provider private key",
+		},
+		&cli.BoolFlag{
+			Name:  "sign-requests",
+			Usage: "Set to true to sign the authentication requests",
+		},
+		&cli.StringFlag{
+			Name:  "name-id-format",
+			Value: "persistent",
+			Usage: "NameID format to request: persistent, transient, email or unspecified",
+		},
+		&cli.StringFlag{
+			Name:  "attribute-username",
+			Value: "",
+			Usage: "Attribute providing the username, the NameID is used if it's not set",
+		},
+		&cli.StringFlag{
+			Name:  "attribute-email",
+			Value: "",
+			Usage: "Attribute providing the email address",
+		},
+		&cli.StringFlag{
+			Name:  "attribute-full-name",
+			Value: "",
+			Usage: "Attribute providing the full name",
+		},
+		&cli.StringFlag{
+			Name:  "attribute-groups",
+			Value: "",
+			Usage: "Attribute providing group names for this source",
+		},
+		&cli.BoolFlag{
+			Name:  "auto-create-users",
+			Usage: "Set to true to create the users signing in for the first time",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-local-2fa",
+			Usage: "Set to true to skip local 2fa for users authenticated by this source",
+		},
+		&cli.StringFlag{
+			Name:  "admin-group",
+			Value: "",
+			Usage: "Group attribute value for administrator users",
+		},
+		&cli.StringFlag{
+			Name:  "restricted-group",
+			Value: "",
+			Usage: "Group attribute value for restricted users",
+		},
+		&cli.StringFlag{
+			Name:  "group-team-map",
+			Value: "",
+			Usage: "JSON mapping between groups and org teams",
+		},
+		&cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Activate automatic team membership removal depending on groups",
+		},
+	}
+
+	microcmdAuthAddSAML = &cli.Command{
+		Name:   "add-saml",
+		Usage:  "Add new SAML authentication source",
+		Action: runAddSAML,
+		Flags:  samlCLIFlags,
+	}
+
+	microcmdAuthUpdateSAML = &cli.Command{
+		Name:   "update-saml",
+		Usage:  "Update existing SAML authentication source",
+		Action: runUpdateSAML,
+		Flags:  append(samlCLIFlags[:1], append([]cli.Flag{idFlag}, samlCLIFlags[1:]...)...),
+	}
+)
+
+func readFileFlag(c *cli.Context, name string) (string, error) {
+	if !c.IsSet(name) {
+		return "", nil
+	}
+	data, err := os.ReadFile(c.String(name))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func runAddSAML(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	config := &saml.Source{
+		IdentityProviderMetadataURL: c.String("idp-metadata-url"),
+		SignRequests:                c.Bool("sign-requests"),
+		NameIDFormat:                c.String("name-id-format"),
+		AttributeUsername:           c.String("attribute-username"),
+		AttributeEmail:              c.String("attribute-email"),
+		AttributeFullName:           c.String("attribute-full-name"),
+		AttributeGroups:             c.String("attribute-groups"),
+		AutoCreateUsers:             c.Bool("auto-create-users"),
+		SkipLocalTwoFA:              c.Bool("skip-local-2fa"),
+		AdminGroup:                  c.String("admin-group"),
+		RestrictedGroup:             c.String("restricted-group"),
+		GroupTeamMap:                c.String("group-team-map"),
+		GroupTeamMapRemoval:         c.Bool("group-team-map-removal"),
+	}
+
+	var err error
+	if config.IdentityProviderMetadata, err = readFileFlag(c, "idp-metadata-file"); err != nil {
+		return err
+	}
+	if config.ServiceProviderCertificate, err = readFileFlag(c, "sp-certificate-file"); err != nil {
+		return err
+	}
+	if config.ServiceProviderPrivateKey, err = readFileFlag(c, "sp-private-key-file"); err != nil {
+		return err
+	}
+	if config.ServiceProviderPrivateKey == "" {
+		if config.ServiceProviderCertificate, config.ServiceProviderPrivateKey, err = saml.GenerateServiceProviderKeyPair(setting.Domain); err != nil {
+			return err
+		}
+	}
+
+	return auth_model.CreateSource(ctx, &auth_model.Source{
+		Type:     auth_model.SAML,
+		Name:     c.String("name"),
+		IsActive: true,
+		Cfg:      config,
+	})
+}
+
+func runUpdateSAML(c *cli.Context) error {
+	if !c.IsSet("id") {
+		return errors.New("--id flag is missing")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	source, err := auth_model.GetSourceByID(ctx, c.Int64("id"))
+	if err != nil {
+		return err
+	}
+
+	samlConfig, ok := source.Cfg.(*saml.Source)
+	if !ok {
+		return fmt.Errorf("authentication source %d is not a SAML source", source.ID)
+	}
+
+	if c.IsSet("name") {
+		source.Name = c.String("name")
+	}
+
+	if c.IsSet("idp-metadata-url") {
+		samlConfig.IdentityProviderMetadataURL = c.String("idp-metadata-url")
+	}
+	if c.IsSet("idp-metadata-file") {
+		if samlConfig.IdentityProviderMetadata, err = readFileFlag(c, "idp-metadata-file"); err != nil {
+			return err
+		}
+	}
+	if c.IsSet("sp-certificate-file") {
+		if samlConfig.ServiceProviderCertificate, err = readFileFlag(c, "sp-certificate-file"); err != nil {
+			return err
+		}
+	}
+	if c.IsSet("sp-private-key-file") {
+		if samlConfig.ServiceProviderPrivateKey, err = readFileFlag(c, "sp-private-key-file"); err != nil {
+			return err
+		}
+	}
+	if c.IsSet("sign-requests") {
+		samlConfig.SignRequests = c.Bool("sign-requests")
+	}
+	if c.IsSet("name-id-format") {
+		samlConfig.NameIDFormat = c.String("name-id-format")
+	}
+
+	if c.IsSet("attribute-username") {
+		samlConfig.AttributeUsername = c.String("attribute-username")
+	}
+	if c.IsSet("attribute-email") {
+		samlConfig.AttributeEmail = c.String("attribute-email")
+	}
+	if c.IsSet("attribute-full-name") {
+		samlConfig.AttributeFullName = c.String("attribute-full-name")
+	}
+	if c.IsSet("attribute-groups") {
+		samlConfig.AttributeGroups = c.String("attribute-groups")
+	}
+
+	if c.IsSet("auto-create-users") {
+		samlConfig.AutoCreateUsers = c.Bool("auto-create-users")
+	}
+	if c.IsSet("skip-local-2fa") {
+		samlConfig.SkipLocalTwoFA = c.Bool("skip-local-2fa")
+	}
+	if c.IsSet("admin-group") {
+		samlConfig.AdminGroup = c.String("admin-group")
+	}
+	if c.IsSet("restricted-group") {
+		samlConfig.RestrictedGroup = c.String("restricted-group")
+	}
+	if c.IsSet("group-team-map") {
+		samlConfig.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		samlConfig.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
+
+	source.Cfg = samlConfig
+
+	return auth_model.UpdateSource(ctx, source)
+}
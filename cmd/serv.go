@@ -377,6 +377,9 @@ func runServ(c *cli.Context) error {
 	// to avoid breaking, here only use the minimal environment variables for the "gitea serv" command.
 	// it could be re-considered whether to use the same git.CommonGitCmdEnvs() as "git" command later.
 	gitcmd.Env = append(gitcmd.Env, git.CommonCmdServEnvs()...)
+	if verb == verbUploadPack {
+		gitcmd.Env = append(gitcmd.Env, git.ConfigEnvs(results.UploadPackConfig)...)
+	}
 
 	if err = gitcmd.Run(); err != nil {
 		return fail(ctx, "Failed to execute git command", "Failed to execute git command: %v", err)
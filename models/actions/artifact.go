@@ -134,9 +134,14 @@ func (opts FindArtifactsOptions) ToConds() builder.Cond {
 
 // ActionArtifactMeta is the meta data of an artifact
 type ActionArtifactMeta struct {
+	ID           int64 // the id of the first file of the artifact
+	RunID        int64
 	ArtifactName string
 	FileSize     int64
 	Status       ArtifactStatus
+	CreatedUnix  timeutil.TimeStamp
+	UpdatedUnix  timeutil.TimeStamp
+	ExpiredUnix  timeutil.TimeStamp
 }
 
 // ListUploadedArtifactsMeta returns all uploaded artifacts meta of a run
@@ -149,6 +154,39 @@ func ListUploadedArtifactsMeta(ctx context.Context, runID int64) ([]*ActionArtif
 		Find(&arts)
 }
 
+// FindUploadedArtifactsMeta returns the meta data of the uploaded artifacts and the count of them,
+// the files of an artifact are grouped by the run and the name of the artifact.
+func FindUploadedArtifactsMeta(ctx context.Context, opts FindArtifactsOptions) ([]*ActionArtifactMeta, int64, error) {
+	cond := opts.ToConds().And(builder.In("status", ArtifactStatusUploadConfirmed, ArtifactStatusExpired))
+
+	var count int64
+	if _, err := db.GetEngine(ctx).SQL(builder.Select("COUNT(*)").From(
+		builder.Select("run_id, artifact_name").From("action_artifact").Where(cond).GroupBy("run_id, artifact_name"), "artifact",
+	)).Get(&count); err != nil {
+		return nil, 0, err
+	}
+
+	sess := db.GetEngine(ctx).Table("action_artifact").
+		Where(cond).
+		GroupBy("run_id, artifact_name").
+		Select("min(id) as id, run_id, artifact_name, sum(file_size) as file_size, max(status) as status, " +
+			"min(created_unix) as created_unix, max(updated_unix) as updated_unix, max(expired_unix) as expired_unix").
+		OrderBy("min(id) DESC")
+	if opts.PageSize > 0 {
+		sess = db.SetSessionPagination(sess, &opts.ListOptions)
+	}
+	arts := make([]*ActionArtifactMeta, 0, 10)
+	return arts, count, sess.Find(&arts)
+}
+
+// GetOwnerArtifactsSize returns the size of the stored artifacts of all the repositories of the owner
+func GetOwnerArtifactsSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Where("owner_id = ?", ownerID).
+		In("status", ArtifactStatusUploadPending, ArtifactStatusUploadConfirmed).
+		SumInt(new(ActionArtifact), "file_compressed_size")
+}
+
 // ListNeedExpiredArtifacts returns all need expired artifacts but not deleted
 func ListNeedExpiredArtifacts(ctx context.Context) ([]*ActionArtifact, error) {
 	arts := make([]*ActionArtifact, 0, 10)
@@ -0,0 +1,197 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionCache))
+}
+
+// ActionCache is a dependency cache entry uploaded by `actions/cache`.
+// Like GitHub, an entry is scoped to the repository and the ref of the run which created it,
+// and it is immutable once it's completed.
+type ActionCache struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RepoID      int64              `xorm:"index(repo_ref)"`
+	Ref         string             `xorm:"VARCHAR(255) index(repo_ref)"` // The scope of the cache
+	CacheKey    string             `xorm:"VARCHAR(512)"`
+	Version     string             `xorm:"VARCHAR(255)"` // The hash of the paths and the compression method, computed by the client
+	Size        int64              // The size of the cache in bytes
+	Complete    bool               `xorm:"index"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	UsedUnix    timeutil.TimeStamp `xorm:"index"` // The last time the cache was created or restored, used for the LRU eviction
+}
+
+// StoragePath returns the path of the cache in the storage
+func (c *ActionCache) StoragePath() string {
+	return fmt.Sprintf("%d/%d", c.RepoID, c.ID)
+}
+
+// GetCacheByID returns the cache entry by id
+func GetCacheByID(ctx context.Context, id int64) (*ActionCache, error) {
+	var c ActionCache
+	has, err := db.GetEngine(ctx).ID(id).Get(&c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("action cache with id %d: %w", id, util.ErrNotExist)
+	}
+	return &c, nil
+}
+
+// GetCacheByKey returns the cache entry with exactly the key and version in the scope
+func GetCacheByKey(ctx context.Context, repoID int64, ref, key, version string) (*ActionCache, error) {
+	var c ActionCache
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "ref": ref, "cache_key": key, "version": version}).Get(&c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, util.ErrNotExist
+	}
+	return &c, nil
+}
+
+// InsertCache inserts a new pending cache entry
+func InsertCache(ctx context.Context, c *ActionCache) error {
+	c.Complete = false
+	c.UsedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).Insert(c)
+	return err
+}
+
+// ResetCache makes a pending cache entry ready to be uploaded again
+func ResetCache(ctx context.Context, c *ActionCache) error {
+	c.Size = 0
+	c.UsedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(c.ID).Where("complete = ?", false).Cols("size", "used_unix").Update(c)
+	return err
+}
+
+// CompleteCache marks the cache entry as completed
+func CompleteCache(ctx context.Context, c *ActionCache, size int64) error {
+	c.Size = size
+	c.Complete = true
+	c.UsedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(c.ID).Cols("size", "complete", "used_unix").Update(c)
+	return err
+}
+
+// UpdateCacheUsed refreshes the last used time of the cache entry
+func UpdateCacheUsed(ctx context.Context, c *ActionCache) error {
+	c.UsedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(c.ID).NoAutoTime().Cols("used_unix").Update(c)
+	return err
+}
+
+// DeleteCacheByID deletes the record of the cache entry, the caller should delete the file in the storage
+func DeleteCacheByID(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&ActionCache{})
+	return err
+}
+
+type FindCachesOptions struct {
+	db.ListOptions
+	RepoID     int64
+	Ref        string
+	Version    string
+	Complete   optional.Option[bool]
+	UsedBefore timeutil.TimeStamp
+}
+
+func (opts FindCachesOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Ref != "" {
+		cond = cond.And(builder.Eq{"ref": opts.Ref})
+	}
+	if opts.Version != "" {
+		cond = cond.And(builder.Eq{"version": opts.Version})
+	}
+	if opts.Complete.Has() {
+		cond = cond.And(builder.Eq{"complete": opts.Complete.Value()})
+	}
+	if opts.UsedBefore > 0 {
+		cond = cond.And(builder.Lt{"used_unix": opts.UsedBefore})
+	}
+	return cond
+}
+
+func (opts FindCachesOptions) ToOrders() string {
+	return "used_unix ASC, id ASC"
+}
+
+// FindCacheToRestore finds the cache entry to restore with the same rules as GitHub:
+// the scopes are searched in order, and in each scope an exact match of the key is preferred,
+// then the most recently created entry of which the key starts with the key or the restore keys in order.
+func FindCacheToRestore(ctx context.Context, repoID int64, refs []string, version, key string, restoreKeys []string) (*ActionCache, error) {
+	prefixes := append([]string{key}, restoreKeys...)
+	for _, ref := range refs {
+		caches, err := db.Find[ActionCache](ctx, FindCachesOptions{
+			RepoID:   repoID,
+			Ref:      ref,
+			Version:  version,
+			Complete: optional.Some(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if c := matchCache(caches, key, prefixes); c != nil {
+			return c, nil
+		}
+	}
+	return nil, util.ErrNotExist
+}
+
+func matchCache(caches []*ActionCache, key string, prefixes []string) *ActionCache {
+	for _, c := range caches {
+		if c.CacheKey == key {
+			return c
+		}
+	}
+	for _, prefix := range prefixes {
+		var matched *ActionCache
+		for _, c := range caches {
+			if strings.HasPrefix(c.CacheKey, prefix) && (matched == nil || c.CreatedUnix > matched.CreatedUnix ||
+				c.CreatedUnix == matched.CreatedUnix && c.ID > matched.ID) {
+				matched = c
+			}
+		}
+		if matched != nil {
+			return matched
+		}
+	}
+	return nil
+}
+
+// CacheRepoUsage is the total size of the completed caches of a repository
+type CacheRepoUsage struct {
+	RepoID int64
+	Size   int64
+}
+
+// GetCacheRepoUsagesExceeding returns the repositories whose caches exceed the size limit
+func GetCacheRepoUsagesExceeding(ctx context.Context, limit int64) ([]*CacheRepoUsage, error) {
+	usages := make([]*CacheRepoUsage, 0, 10)
+	return usages, db.GetEngine(ctx).Table("action_cache").
+		Select("repo_id, SUM(size) AS size").
+		Where("complete = ?", true).
+		GroupBy("repo_id").
+		Having(fmt.Sprintf("SUM(size) > %d", limit)).
+		Find(&usages)
+}
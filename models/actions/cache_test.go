@@ -0,0 +1,42 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchCache(t *testing.T) {
+	caches := []*ActionCache{
+		{ID: 1, CacheKey: "npm-linux-a", CreatedUnix: 100},
+		{ID: 2, CacheKey: "npm-linux-b", CreatedUnix: 300},
+		{ID: 3, CacheKey: "npm-linux", CreatedUnix: 200},
+		{ID: 4, CacheKey: "npm-macos-a", CreatedUnix: 400},
+	}
+
+	cases := []struct {
+		key         string
+		restoreKeys []string
+		expected    int64
+	}{
+		{key: "npm-linux-a", restoreKeys: []string{"npm-"}, expected: 1},
+		{key: "npm-linux", expected: 3},
+		{key: "npm-linux-", expected: 2},
+		{key: "npm-windows-a", restoreKeys: []string{"npm-windows-", "npm-linux-", "npm-"}, expected: 2},
+		{key: "npm-windows-a", restoreKeys: []string{"npm-"}, expected: 4},
+		{key: "yarn-linux", restoreKeys: []string{"yarn-"}, expected: 0},
+	}
+	for _, c := range cases {
+		matched := matchCache(caches, c.key, append([]string{c.key}, c.restoreKeys...))
+		if c.expected == 0 {
+			assert.Nil(t, matched, c.key)
+			continue
+		}
+		if assert.NotNil(t, matched, c.key) {
+			assert.EqualValues(t, c.expected, matched.ID, c.key)
+		}
+	}
+}
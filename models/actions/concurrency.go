@@ -0,0 +1,123 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+)
+
+// JobConcurrency is the evaluated `concurrency` setting of a job
+type JobConcurrency struct {
+	Group  string
+	Cancel bool
+}
+
+// ShouldBlockRunByConcurrency returns whether the run should wait because another run in the same concurrency group is in progress
+func ShouldBlockRunByConcurrency(ctx context.Context, run *ActionRun) (bool, error) {
+	if run.ConcurrencyGroup == "" {
+		return false, nil
+	}
+	runs, err := db.Find[ActionRun](ctx, FindRunOptions{
+		RepoID:           run.RepoID,
+		ConcurrencyGroup: run.ConcurrencyGroup,
+		Status:           []Status{StatusRunning, StatusWaiting, StatusBlocked},
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, r := range runs {
+		if r.ID == run.ID {
+			continue
+		}
+		// a blocked run which has never started is pending, it doesn't hold the group
+		if r.Status.In(StatusRunning, StatusWaiting) || !r.Started.IsZero() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ShouldBlockJobByConcurrency returns whether the job should wait because another job in the same concurrency group is in progress
+func ShouldBlockJobByConcurrency(ctx context.Context, job *ActionRunJob) (bool, error) {
+	if job.ConcurrencyGroup == "" {
+		return false, nil
+	}
+	jobs, err := db.Find[ActionRunJob](ctx, FindRunJobOptions{
+		RepoID:           job.RepoID,
+		ConcurrencyGroup: job.ConcurrencyGroup,
+		Statuses:         []Status{StatusRunning, StatusWaiting},
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, j := range jobs {
+		if j.ID != job.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CancelConcurrentRuns cancels the other runs in the concurrency group of the run.
+// All of them are cancelled if the run has cancel-in-progress set, otherwise only the pending ones,
+// so that there is at most one running and one pending run in a group.
+func CancelConcurrentRuns(ctx context.Context, run *ActionRun) error {
+	if run.ConcurrencyGroup == "" {
+		return nil
+	}
+	runs, err := db.Find[ActionRun](ctx, FindRunOptions{
+		RepoID:           run.RepoID,
+		ConcurrencyGroup: run.ConcurrencyGroup,
+		Status:           []Status{StatusRunning, StatusWaiting, StatusBlocked},
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range runs {
+		if r.ID == run.ID {
+			continue
+		}
+		if !run.ConcurrencyCancel && (!r.Status.IsBlocked() || !r.Started.IsZero()) {
+			continue
+		}
+		jobs, err := GetRunJobsByRunID(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		if err := CancelJobs(ctx, jobs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CancelConcurrentJobs cancels the other jobs in the concurrency group of the job.
+// All of them are cancelled if the job has cancel-in-progress set, otherwise only the pending ones.
+// Jobs of the same run are never cancelled, they will be executed one by one.
+func CancelConcurrentJobs(ctx context.Context, job *ActionRunJob) error {
+	if job.ConcurrencyGroup == "" {
+		return nil
+	}
+	statuses := []Status{StatusBlocked}
+	if job.ConcurrencyCancel {
+		statuses = append(statuses, StatusRunning, StatusWaiting)
+	}
+	jobs, err := db.Find[ActionRunJob](ctx, FindRunJobOptions{
+		RepoID:           job.RepoID,
+		ConcurrencyGroup: job.ConcurrencyGroup,
+		Statuses:         statuses,
+	})
+	if err != nil {
+		return err
+	}
+	toCancel := make([]*ActionRunJob, 0, len(jobs))
+	for _, j := range jobs {
+		if j.ID != job.ID && j.RunID != job.RunID {
+			toCancel = append(toCancel, j)
+		}
+	}
+	return CancelJobs(ctx, toCancel)
+}
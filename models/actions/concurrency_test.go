@@ -0,0 +1,98 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertRunWithConcurrency(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+
+	content := []byte(`
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploy
+`)
+	insertRun := func(group string, cancel bool, jobConcurrencies []*JobConcurrency) (*ActionRun, []*ActionRunJob) {
+		workflows, err := jobparser.Parse(content)
+		require.NoError(t, err)
+		run := &ActionRun{
+			Title:             "deploy",
+			RepoID:            4,
+			Repo:              &repo_model.Repository{ID: 4},
+			OwnerID:           1,
+			WorkflowID:        "deploy.yaml",
+			TriggerUserID:     1,
+			Ref:               "refs/heads/master",
+			Status:            StatusWaiting,
+			ConcurrencyGroup:  group,
+			ConcurrencyCancel: cancel,
+		}
+		require.NoError(t, InsertRun(db.DefaultContext, run, workflows, jobConcurrencies, nil, nil, nil))
+		jobs, err := GetRunJobsByRunID(db.DefaultContext, run.ID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		return run, jobs
+	}
+	jobStatus := func(job *ActionRunJob) Status {
+		return unittest.AssertExistsAndLoadBean(t, &ActionRunJob{ID: job.ID}).Status
+	}
+
+	t.Run("Workflow", func(t *testing.T) {
+		_, jobs1 := insertRun("production", false, nil)
+		assert.Equal(t, StatusWaiting, jobStatus(jobs1[0]))
+
+		// the second run waits for the first one
+		run2, jobs2 := insertRun("production", false, nil)
+		assert.Equal(t, StatusBlocked, jobStatus(jobs2[0]))
+		blocked, err := ShouldBlockRunByConcurrency(db.DefaultContext, run2)
+		require.NoError(t, err)
+		assert.True(t, blocked)
+
+		// the third run replaces the pending second one
+		_, jobs3 := insertRun("production", false, nil)
+		assert.Equal(t, StatusCancelled, jobStatus(jobs2[0]))
+		assert.Equal(t, StatusWaiting, jobStatus(jobs1[0]))
+		assert.Equal(t, StatusBlocked, jobStatus(jobs3[0]))
+
+		// cancel-in-progress cancels all of them
+		_, jobs4 := insertRun("production", true, nil)
+		assert.Equal(t, StatusCancelled, jobStatus(jobs1[0]))
+		assert.Equal(t, StatusCancelled, jobStatus(jobs3[0]))
+		assert.Equal(t, StatusWaiting, jobStatus(jobs4[0]))
+
+		// other groups are not affected
+		_, jobs5 := insertRun("staging", false, nil)
+		assert.Equal(t, StatusWaiting, jobStatus(jobs5[0]))
+	})
+
+	t.Run("Job", func(t *testing.T) {
+		_, jobs1 := insertRun("", false, []*JobConcurrency{{Group: "database"}})
+		assert.Equal(t, StatusWaiting, jobStatus(jobs1[0]))
+		assert.Equal(t, "database", jobs1[0].ConcurrencyGroup)
+
+		_, jobs2 := insertRun("", false, []*JobConcurrency{{Group: "database"}})
+		assert.Equal(t, StatusBlocked, jobStatus(jobs2[0]))
+		blocked, err := ShouldBlockJobByConcurrency(db.DefaultContext, jobs2[0])
+		require.NoError(t, err)
+		assert.True(t, blocked)
+
+		_, jobs3 := insertRun("", false, []*JobConcurrency{{Group: "database", Cancel: true}})
+		assert.Equal(t, StatusCancelled, jobStatus(jobs1[0]))
+		assert.Equal(t, StatusCancelled, jobStatus(jobs2[0]))
+		assert.Equal(t, StatusWaiting, jobStatus(jobs3[0]))
+	})
+}
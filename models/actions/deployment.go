@@ -0,0 +1,324 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionDeployment))
+	db.RegisterModel(new(ActionDeploymentReview))
+}
+
+// DeploymentStatus represents the status of ActionDeployment
+type DeploymentStatus int
+
+const (
+	DeploymentStatusUnknown    DeploymentStatus = iota // 0
+	DeploymentStatusWaiting                            // 1, waiting for the protection rules of the environment
+	DeploymentStatusQueued                             // 2, waiting for a runner
+	DeploymentStatusInProgress                         // 3
+	DeploymentStatusSuccess                            // 4
+	DeploymentStatusFailure                            // 5
+	DeploymentStatusCancelled                          // 6
+	DeploymentStatusRejected                           // 7, rejected by a reviewer
+)
+
+var deploymentStatusNames = map[DeploymentStatus]string{
+	DeploymentStatusUnknown:    "unknown",
+	DeploymentStatusWaiting:    "waiting",
+	DeploymentStatusQueued:     "queued",
+	DeploymentStatusInProgress: "in_progress",
+	DeploymentStatusSuccess:    "success",
+	DeploymentStatusFailure:    "failure",
+	DeploymentStatusCancelled:  "cancelled",
+	DeploymentStatusRejected:   "rejected",
+}
+
+// String returns the string name of the DeploymentStatus
+func (s DeploymentStatus) String() string {
+	return deploymentStatusNames[s]
+}
+
+// LocaleString returns the locale string name of the DeploymentStatus
+func (s DeploymentStatus) LocaleString(lang translation.Locale) string {
+	return lang.TrString("actions.deployments.status." + s.String())
+}
+
+// IsDone returns whether the DeploymentStatus is final
+func (s DeploymentStatus) IsDone() bool {
+	return s == DeploymentStatusSuccess || s == DeploymentStatusFailure || s == DeploymentStatusCancelled || s == DeploymentStatusRejected
+}
+
+// DeploymentStatusList returns the known deployment statuses in order
+func DeploymentStatusList() []DeploymentStatus {
+	return []DeploymentStatus{
+		DeploymentStatusWaiting,
+		DeploymentStatusQueued,
+		DeploymentStatusInProgress,
+		DeploymentStatusSuccess,
+		DeploymentStatusFailure,
+		DeploymentStatusCancelled,
+		DeploymentStatusRejected,
+	}
+}
+
+// DeploymentStatusFromString returns the DeploymentStatus by its name
+func DeploymentStatusFromString(name string) (DeploymentStatus, bool) {
+	for s, n := range deploymentStatusNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return DeploymentStatusUnknown, false
+}
+
+// deploymentStatusOfJob returns the status of a released deployment according to the status of its job
+func deploymentStatusOfJob(status Status) DeploymentStatus {
+	switch status {
+	case StatusWaiting:
+		return DeploymentStatusQueued
+	case StatusRunning:
+		return DeploymentStatusInProgress
+	case StatusSuccess:
+		return DeploymentStatusSuccess
+	case StatusFailure:
+		return DeploymentStatusFailure
+	case StatusCancelled, StatusSkipped:
+		return DeploymentStatusCancelled
+	default:
+		return DeploymentStatusWaiting
+	}
+}
+
+// ActionDeployment records a job deploying to an environment.
+// A job could have multiple deployments if it's rerun, only the latest one is effective.
+type ActionDeployment struct {
+	ID            int64                     `xorm:"pk autoincr"`
+	RepoID        int64                     `xorm:"index"`
+	RunID         int64                     `xorm:"index"`
+	Run           *ActionRun                `xorm:"-"`
+	JobID         int64                     `xorm:"index"` // the id of ActionRunJob
+	Job           *ActionRunJob             `xorm:"-"`
+	EnvironmentID int64                     `xorm:"index"`
+	Environment   *ActionEnvironment        `xorm:"-"`
+	Ref           string                    `xorm:"VARCHAR(255)"`
+	CommitSHA     string                    `xorm:"VARCHAR(64)"`
+	CreatorID     int64                     // the user who triggered the run
+	Creator       *user_model.User          `xorm:"-"`
+	Status        DeploymentStatus          `xorm:"index"`
+	ApprovedUnix  timeutil.TimeStamp        // the time when it's approved by a reviewer, zero if it's not approved yet
+	WaitUntil     timeutil.TimeStamp        `xorm:"index"` // the time when the wait timer ends, zero if the timer hasn't started
+	CreatedUnix   timeutil.TimeStamp        `xorm:"created"`
+	UpdatedUnix   timeutil.TimeStamp        `xorm:"updated"`
+	Reviews       []*ActionDeploymentReview `xorm:"-"`
+}
+
+// LoadAttributes loads the run, the job, the environment and the creator of the deployment
+func (d *ActionDeployment) LoadAttributes(ctx context.Context) error {
+	if d.Run == nil {
+		run, err := GetRunByID(ctx, d.RunID)
+		if err != nil {
+			return err
+		}
+		d.Run = run
+	}
+	if err := d.Run.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	if d.Job == nil {
+		job, err := GetRunJobByID(ctx, d.JobID)
+		if err != nil {
+			return err
+		}
+		d.Job = job
+	}
+	if d.Environment == nil {
+		env, err := GetEnvironmentByID(ctx, d.EnvironmentID)
+		if err != nil {
+			return err
+		}
+		d.Environment = env
+	}
+	if d.Creator == nil {
+		u, err := user_model.GetPossibleUserByID(ctx, d.CreatorID)
+		if err != nil {
+			return err
+		}
+		d.Creator = u
+	}
+	return nil
+}
+
+// LoadReviews loads the reviews of the deployment with their reviewers
+func (d *ActionDeployment) LoadReviews(ctx context.Context) error {
+	if d.Reviews != nil {
+		return nil
+	}
+	reviews, err := db.Find[ActionDeploymentReview](ctx, FindDeploymentReviewsOptions{DeploymentID: d.ID})
+	if err != nil {
+		return err
+	}
+	for _, r := range reviews {
+		if r.Reviewer, err = user_model.GetPossibleUserByID(ctx, r.ReviewerID); err != nil {
+			return err
+		}
+	}
+	d.Reviews = reviews
+	return nil
+}
+
+// CreateDeploymentForJob records a new deployment of the job, which is waiting for the protection rules of the environment
+func CreateDeploymentForJob(ctx context.Context, run *ActionRun, job *ActionRunJob) (*ActionDeployment, error) {
+	d := &ActionDeployment{
+		RepoID:        job.RepoID,
+		RunID:         job.RunID,
+		JobID:         job.ID,
+		EnvironmentID: job.EnvironmentID,
+		Ref:           run.Ref,
+		CommitSHA:     job.CommitSHA,
+		CreatorID:     run.TriggerUserID,
+		Status:        DeploymentStatusWaiting,
+	}
+	return d, db.Insert(ctx, d)
+}
+
+// GetDeploymentByID returns the deployment by id
+func GetDeploymentByID(ctx context.Context, id int64) (*ActionDeployment, error) {
+	var d ActionDeployment
+	has, err := db.GetEngine(ctx).ID(id).Get(&d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("deployment with id %d: %w", id, util.ErrNotExist)
+	}
+	return &d, nil
+}
+
+// GetLatestDeploymentOfJob returns the effective deployment of the job
+func GetLatestDeploymentOfJob(ctx context.Context, jobID int64) (*ActionDeployment, error) {
+	var d ActionDeployment
+	has, err := db.GetEngine(ctx).Where("job_id = ?", jobID).Desc("id").Get(&d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("deployment of job %d: %w", jobID, util.ErrNotExist)
+	}
+	return &d, nil
+}
+
+// UpdateDeployment updates the given columns of the deployment
+func UpdateDeployment(ctx context.Context, d *ActionDeployment, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(d.ID).Cols(cols...).Update(d)
+	return err
+}
+
+// syncDeploymentStatus updates the status of the ongoing deployment of the job according to the job status,
+// the deployments which have been rejected or finished before the job is rerun are not affected.
+func syncDeploymentStatus(ctx context.Context, job *ActionRunJob) error {
+	status := deploymentStatusOfJob(job.Status)
+	if status == DeploymentStatusWaiting {
+		return nil
+	}
+	_, err := db.GetEngine(ctx).
+		Where(builder.Eq{"job_id": job.ID}.And(builder.In("status", DeploymentStatusWaiting, DeploymentStatusQueued, DeploymentStatusInProgress))).
+		Cols("status").
+		Update(&ActionDeployment{Status: status})
+	return err
+}
+
+type FindDeploymentsOptions struct {
+	db.ListOptions
+	RepoID        int64
+	RunID         int64
+	EnvironmentID int64
+	Status        []DeploymentStatus
+	WaitUntilLE   timeutil.TimeStamp
+}
+
+func (opts FindDeploymentsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.RunID > 0 {
+		cond = cond.And(builder.Eq{"run_id": opts.RunID})
+	}
+	if opts.EnvironmentID > 0 {
+		cond = cond.And(builder.Eq{"environment_id": opts.EnvironmentID})
+	}
+	if len(opts.Status) > 0 {
+		cond = cond.And(builder.In("status", opts.Status))
+	}
+	if opts.WaitUntilLE > 0 {
+		cond = cond.And(builder.Gt{"wait_until": 0}).And(builder.Lte{"wait_until": opts.WaitUntilLE})
+	}
+	return cond
+}
+
+func (opts FindDeploymentsOptions) ToOrders() string {
+	return "id DESC"
+}
+
+// DeploymentList is a list of deployments
+type DeploymentList []*ActionDeployment
+
+// LoadAttributes loads the attributes of the deployments
+func (deployments DeploymentList) LoadAttributes(ctx context.Context) error {
+	envIDs := make(container.Set[int64])
+	for _, d := range deployments {
+		envIDs.Add(d.EnvironmentID)
+	}
+	envs, err := db.Find[ActionEnvironment](ctx, FindEnvironmentsOptions{IDs: envIDs.Values()})
+	if err != nil {
+		return err
+	}
+	envMap := make(map[int64]*ActionEnvironment, len(envs))
+	for _, env := range envs {
+		envMap[env.ID] = env
+	}
+	for _, d := range deployments {
+		d.Environment = envMap[d.EnvironmentID]
+		if err := d.LoadAttributes(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActionDeploymentReview is the review of a deployment by one of the required reviewers of the environment
+type ActionDeploymentReview struct {
+	ID           int64              `xorm:"pk autoincr"`
+	RepoID       int64              `xorm:"index"`
+	DeploymentID int64              `xorm:"index"`
+	ReviewerID   int64              `xorm:"index"`
+	Reviewer     *user_model.User   `xorm:"-"`
+	Approved     bool               // approved or rejected
+	Comment      string             `xorm:"TEXT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+}
+
+type FindDeploymentReviewsOptions struct {
+	db.ListOptions
+	DeploymentID int64
+}
+
+func (opts FindDeploymentReviewsOptions) ToConds() builder.Cond {
+	return builder.Eq{"deployment_id": opts.DeploymentID}
+}
+
+func (opts FindDeploymentReviewsOptions) ToOrders() string {
+	return "id ASC"
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionEnvironment))
+}
+
+// ActionEnvironment is a deployment target of a repository, the jobs deploying to it
+// have to pass its protection rules before they could be picked up by runners.
+type ActionEnvironment struct {
+	ID                int64              `xorm:"pk autoincr"`
+	RepoID            int64              `xorm:"UNIQUE(repo_name)"`
+	Name              string             `xorm:"VARCHAR(255) UNIQUE(repo_name) NOT NULL"`
+	WaitTimer         int64              // minutes to wait before the deployment starts
+	BranchFilters     []string           `xorm:"JSON TEXT"` // glob patterns of the refs allowed to deploy, all refs are allowed if it's empty
+	RequiredReviewers []int64            `xorm:"JSON TEXT"` // ids of the users who could approve the deployments, one approval is required
+	CreatedUnix       timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix       timeutil.TimeStamp `xorm:"updated"`
+}
+
+// MaxEnvironmentWaitTimer is the max minutes of the wait timer, it's 30 days like GitHub
+const MaxEnvironmentWaitTimer = 43200
+
+// RequiresReview returns whether the deployments to the environment need to be approved
+func (env *ActionEnvironment) RequiresReview() bool {
+	return len(env.RequiredReviewers) > 0
+}
+
+// IsReviewer returns whether the user could review the deployments to the environment
+func (env *ActionEnvironment) IsReviewer(userID int64) bool {
+	return slices.Contains(env.RequiredReviewers, userID)
+}
+
+// IsRefAllowed returns whether the ref is allowed to deploy to the environment,
+// the filters are matched against the short name of the ref, like `main` or `v1.0`.
+func (env *ActionEnvironment) IsRefAllowed(ref string) bool {
+	if len(env.BranchFilters) == 0 {
+		return true
+	}
+	name := git.RefName(ref).ShortName()
+	for _, filter := range env.BranchFilters {
+		g, err := glob.Compile(filter, '/')
+		if err != nil {
+			log.Warn("Invalid branch filter %q of environment %d: %v", filter, env.ID, err)
+			continue
+		}
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEnvironmentByID returns the environment by id
+func GetEnvironmentByID(ctx context.Context, id int64) (*ActionEnvironment, error) {
+	var env ActionEnvironment
+	has, err := db.GetEngine(ctx).ID(id).Get(&env)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("environment with id %d: %w", id, util.ErrNotExist)
+	}
+	return &env, nil
+}
+
+// GetEnvironmentByName returns the environment of the repository by name
+func GetEnvironmentByName(ctx context.Context, repoID int64, name string) (*ActionEnvironment, error) {
+	var env ActionEnvironment
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "name": name}).Get(&env)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("environment %q: %w", name, util.ErrNotExist)
+	}
+	return &env, nil
+}
+
+// GetOrCreateEnvironment returns the environment of the repository by name,
+// a new environment without protection rules is created if it doesn't exist, like GitHub does for the jobs referencing it.
+func GetOrCreateEnvironment(ctx context.Context, repoID int64, name string) (*ActionEnvironment, error) {
+	env, err := GetEnvironmentByName(ctx, repoID, name)
+	if err == nil || !errors.Is(err, util.ErrNotExist) {
+		return env, err
+	}
+	env = &ActionEnvironment{RepoID: repoID, Name: name}
+	return env, CreateEnvironment(ctx, env)
+}
+
+// CreateEnvironment creates a new environment, the name must be unique in the repository
+func CreateEnvironment(ctx context.Context, env *ActionEnvironment) error {
+	exist, err := db.GetEngine(ctx).Exist(&ActionEnvironment{RepoID: env.RepoID, Name: env.Name})
+	if err != nil {
+		return err
+	} else if exist {
+		return fmt.Errorf("environment %q: %w", env.Name, util.ErrAlreadyExist)
+	}
+	return db.Insert(ctx, env)
+}
+
+// UpdateEnvironment updates the protection rules of the environment
+func UpdateEnvironment(ctx context.Context, env *ActionEnvironment) error {
+	_, err := db.GetEngine(ctx).ID(env.ID).Cols("wait_timer", "branch_filters", "required_reviewers").Update(env)
+	return err
+}
+
+// DeleteEnvironment deletes the environment with its variables and deployments,
+// the caller should delete the secrets of the environment in the same transaction.
+func DeleteEnvironment(ctx context.Context, env *ActionEnvironment) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if _, err := e.Where("environment_id = ?", env.ID).Delete(&ActionVariable{}); err != nil {
+			return err
+		}
+		deploymentIDs := builder.Select("id").From("action_deployment").Where(builder.Eq{"environment_id": env.ID})
+		if _, err := e.Where(builder.In("deployment_id", deploymentIDs)).Delete(&ActionDeploymentReview{}); err != nil {
+			return err
+		}
+		if _, err := e.Where("environment_id = ?", env.ID).Delete(&ActionDeployment{}); err != nil {
+			return err
+		}
+		_, err := e.ID(env.ID).Delete(&ActionEnvironment{})
+		return err
+	})
+}
+
+type FindEnvironmentsOptions struct {
+	db.ListOptions
+	RepoID int64
+	IDs    []int64
+}
+
+func (opts FindEnvironmentsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if len(opts.IDs) > 0 {
+		cond = cond.And(builder.In("id", opts.IDs))
+	}
+	return cond
+}
+
+func (opts FindEnvironmentsOptions) ToOrders() string {
+	return "name ASC"
+}
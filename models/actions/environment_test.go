@@ -0,0 +1,108 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionEnvironment_IsRefAllowed(t *testing.T) {
+	env := &ActionEnvironment{}
+	assert.True(t, env.IsRefAllowed("refs/heads/feature"))
+
+	env.BranchFilters = []string{"main", "release/*", "v*"}
+	assert.True(t, env.IsRefAllowed("refs/heads/main"))
+	assert.True(t, env.IsRefAllowed("refs/heads/release/1.0"))
+	assert.True(t, env.IsRefAllowed("refs/tags/v1.0"))
+	assert.False(t, env.IsRefAllowed("refs/heads/feature"))
+	assert.False(t, env.IsRefAllowed("refs/heads/release/1.0/fix"))
+	assert.False(t, env.IsRefAllowed("refs/pull/1/head"))
+}
+
+func TestInsertRunWithEnvironment(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	workflows, err := jobparser.Parse([]byte(`
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    steps:
+      - run: echo deploy
+`))
+	require.NoError(t, err)
+	require.Len(t, workflows, 2)
+	run := &ActionRun{
+		Title:         "deploy",
+		RepoID:        4,
+		Repo:          &repo_model.Repository{ID: 4},
+		OwnerID:       1,
+		WorkflowID:    "deploy.yaml",
+		TriggerUserID: 1,
+		Ref:           "refs/heads/master",
+		Status:        StatusWaiting,
+	}
+	require.NoError(t, InsertRun(ctx, run, workflows, nil, []string{"", "production"}, nil, nil))
+
+	env, err := GetEnvironmentByName(ctx, 4, "production")
+	require.NoError(t, err)
+	assert.False(t, env.RequiresReview())
+
+	jobs, err := GetRunJobsByRunID(ctx, run.ID)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, StatusWaiting, jobs[0].Status)
+	assert.EqualValues(t, 0, jobs[0].EnvironmentID)
+	assert.Equal(t, StatusBlocked, jobs[1].Status)
+	assert.Equal(t, env.ID, jobs[1].EnvironmentID)
+
+	deployment, err := GetLatestDeploymentOfJob(ctx, jobs[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, DeploymentStatusWaiting, deployment.Status)
+	assert.Equal(t, env.ID, deployment.EnvironmentID)
+	assert.Equal(t, run.TriggerUserID, deployment.CreatorID)
+
+	// the deployment follows the status of the job once it leaves the blocked status
+	jobs[1].Status = StatusWaiting
+	_, err = UpdateRunJob(ctx, jobs[1], nil, "status")
+	require.NoError(t, err)
+	deployment = unittest.AssertExistsAndLoadBean(t, &ActionDeployment{ID: deployment.ID})
+	assert.Equal(t, DeploymentStatusQueued, deployment.Status)
+
+	jobs[1].Status = StatusSuccess
+	_, err = UpdateRunJob(ctx, jobs[1], nil, "status")
+	require.NoError(t, err)
+	deployment = unittest.AssertExistsAndLoadBean(t, &ActionDeployment{ID: deployment.ID})
+	assert.Equal(t, DeploymentStatusSuccess, deployment.Status)
+
+	// a finished deployment isn't affected by the rerun of the job
+	jobs[1].Status = StatusFailure
+	_, err = UpdateRunJob(ctx, jobs[1], nil, "status")
+	require.NoError(t, err)
+	deployment = unittest.AssertExistsAndLoadBean(t, &ActionDeployment{ID: deployment.ID})
+	assert.Equal(t, DeploymentStatusSuccess, deployment.Status)
+
+	_, err = InsertEnvironmentVariable(ctx, env, "target", "prod")
+	require.NoError(t, err)
+	require.NoError(t, DeleteEnvironment(ctx, env))
+	_, err = GetEnvironmentByID(ctx, env.ID)
+	assert.ErrorIs(t, err, util.ErrNotExist)
+	unittest.AssertNotExistsBean(t, &ActionDeployment{ID: deployment.ID})
+	unittest.AssertNotExistsBean(t, &ActionVariable{EnvironmentID: env.ID})
+}
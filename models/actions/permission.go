@@ -0,0 +1,198 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// PermissionLevel is the access level granted to a scope by the `permissions` of a workflow or a job
+type PermissionLevel int
+
+const (
+	PermissionNone PermissionLevel = iota
+	PermissionRead
+	PermissionWrite
+)
+
+// PermissionScopes are the scopes which could be set in the `permissions` of a workflow or a job,
+// see https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#permissions
+var PermissionScopes = []string{
+	"actions",
+	"attestations",
+	"checks",
+	"contents",
+	"deployments",
+	"discussions",
+	"id-token",
+	"issues",
+	"packages",
+	"pages",
+	"pull-requests",
+	"repository-projects",
+	"security-events",
+	"statuses",
+}
+
+// PermissionScopeIDToken is the scope allowing a job to request OIDC ID tokens
+const PermissionScopeIDToken = "id-token"
+
+// permissionScopeUnits are the repository units covered by the scopes,
+// the units not covered by any scope could be read like the metadata of the repository.
+var permissionScopeUnits = map[unit.Type]string{
+	unit.TypeCode:         "contents",
+	unit.TypeReleases:     "contents",
+	unit.TypeWiki:         "contents",
+	unit.TypeIssues:       "issues",
+	unit.TypePullRequests: "pull-requests",
+	unit.TypePackages:     "packages",
+	unit.TypeActions:      "actions",
+	unit.TypeProjects:     "repository-projects",
+}
+
+// JobPermissions are the evaluated `permissions` of a job, the scopes missing in a non-nil JobPermissions have no access.
+// A nil JobPermissions means the permissions are not set by the workflow or the job, so the default permissions apply.
+type JobPermissions map[string]PermissionLevel
+
+// NewJobPermissions returns the permissions granting the level to all the scopes
+func NewJobPermissions(level PermissionLevel) JobPermissions {
+	p := make(JobPermissions, len(PermissionScopes))
+	for _, scope := range PermissionScopes {
+		p[scope] = level
+	}
+	return p
+}
+
+// IsSet returns whether the permissions are set by the workflow or the job
+func (p JobPermissions) IsSet() bool {
+	return p != nil
+}
+
+// Level returns the access level of the scope, the default level is returned if the permissions are not set
+func (p JobPermissions) Level(scope string, defaultLevel PermissionLevel) PermissionLevel {
+	if p == nil {
+		return defaultLevel
+	}
+	return p[scope]
+}
+
+// CanRequestIDToken returns whether the job is allowed to request OIDC ID tokens,
+// which is never granted by the default permissions.
+func (p JobPermissions) CanRequestIDToken() bool {
+	return p.Level(PermissionScopeIDToken, PermissionNone) == PermissionWrite
+}
+
+// UnitAccessMode returns the access mode of the repository unit granted by the permissions
+func (p JobPermissions) UnitAccessMode(unitType unit.Type) perm.AccessMode {
+	scope, ok := permissionScopeUnits[unitType]
+	if !ok {
+		return perm.AccessModeRead
+	}
+	switch p[scope] {
+	case PermissionWrite:
+		return perm.AccessModeWrite
+	case PermissionRead:
+		return perm.AccessModeRead
+	default:
+		return perm.AccessModeNone
+	}
+}
+
+// Restrict returns the permissions limited by the permissions of the caller, it's used for the jobs of a reusable workflow
+// since a called workflow could only keep or downgrade the permissions of the job calling it.
+func (p JobPermissions) Restrict(caller JobPermissions) JobPermissions {
+	if caller == nil {
+		return p
+	}
+	if p == nil {
+		return caller
+	}
+	ret := make(JobPermissions, len(p))
+	for scope, level := range p {
+		ret[scope] = min(level, caller[scope])
+	}
+	return ret
+}
+
+// TokenPermissionMode is the default permissions of the tokens of the jobs which don't set their `permissions`
+type TokenPermissionMode string
+
+const (
+	// TokenPermissionModePermissive grants the write access to all the scopes except id-token
+	TokenPermissionModePermissive TokenPermissionMode = "permissive"
+	// TokenPermissionModeRestricted grants the read access to contents and packages only
+	TokenPermissionModeRestricted TokenPermissionMode = "restricted"
+)
+
+// IsValid returns whether the mode is a known mode
+func (m TokenPermissionMode) IsValid() bool {
+	return m == TokenPermissionModePermissive || m == TokenPermissionModeRestricted
+}
+
+// DefaultPermissions returns the permissions of the tokens of the jobs which don't set their `permissions`
+func (m TokenPermissionMode) DefaultPermissions() JobPermissions {
+	if m == TokenPermissionModeRestricted {
+		return JobPermissions{
+			"contents": PermissionRead,
+			"packages": PermissionRead,
+		}
+	}
+	p := NewJobPermissions(PermissionWrite)
+	p[PermissionScopeIDToken] = PermissionNone
+	return p
+}
+
+// GetOwnerTokenPermissionMode returns the default token permission mode of the repositories of the owner
+func GetOwnerTokenPermissionMode(ctx context.Context, ownerID int64) (TokenPermissionMode, error) {
+	mode, err := user_model.GetUserSetting(ctx, ownerID, user_model.SettingsKeyActionsTokenPermissionMode)
+	if err != nil {
+		return "", err
+	}
+	if m := TokenPermissionMode(mode); m.IsValid() {
+		return m, nil
+	}
+	return TokenPermissionModePermissive, nil
+}
+
+// GetRepoTokenPermissionMode returns the default token permission mode of the repository, the mode of the owner is used if the repository doesn't set it
+func GetRepoTokenPermissionMode(ctx context.Context, repo *repo_model.Repository) (TokenPermissionMode, error) {
+	actionsUnit, err := repo.GetUnit(ctx, unit.TypeActions)
+	if err != nil && !errors.Is(err, util.ErrNotExist) && !repo_model.IsErrUnitTypeNotExist(err) {
+		return "", err
+	}
+	if actionsUnit != nil {
+		if m := TokenPermissionMode(actionsUnit.ActionsConfig().TokenPermissionMode); m.IsValid() {
+			return m, nil
+		}
+	}
+	return GetOwnerTokenPermissionMode(ctx, repo.OwnerID)
+}
+
+// GetEffectiveJobPermissions returns the permissions of the token of the job, which are the default permissions of the repository
+// if the job doesn't set its permissions. The tokens of the jobs triggered by the pull requests from forks could only read.
+// The run of the job must be loaded.
+func GetEffectiveJobPermissions(ctx context.Context, job *ActionRunJob) (JobPermissions, error) {
+	p := job.Permissions
+	if !p.IsSet() {
+		if err := job.Run.LoadRepo(ctx); err != nil {
+			return nil, err
+		}
+		mode, err := GetRepoTokenPermissionMode(ctx, job.Run.Repo)
+		if err != nil {
+			return nil, err
+		}
+		p = mode.DefaultPermissions()
+	}
+	if job.IsForkPullRequest {
+		p = p.Restrict(NewJobPermissions(PermissionRead))
+	}
+	return p, nil
+}
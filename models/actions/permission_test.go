@@ -0,0 +1,62 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobPermissions_UnitAccessMode(t *testing.T) {
+	p := JobPermissions{"contents": PermissionWrite, "issues": PermissionRead}
+	assert.Equal(t, perm.AccessModeWrite, p.UnitAccessMode(unit.TypeCode))
+	assert.Equal(t, perm.AccessModeWrite, p.UnitAccessMode(unit.TypeReleases))
+	assert.Equal(t, perm.AccessModeRead, p.UnitAccessMode(unit.TypeIssues))
+	assert.Equal(t, perm.AccessModeNone, p.UnitAccessMode(unit.TypePullRequests))
+	assert.Equal(t, perm.AccessModeNone, p.UnitAccessMode(unit.TypePackages))
+	// the units not covered by any scope are readable
+	assert.Equal(t, perm.AccessModeRead, p.UnitAccessMode(unit.TypeExternalTracker))
+}
+
+func TestGetEffectiveJobPermissions(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4})
+	job := &ActionRunJob{RepoID: repo.ID, Run: &ActionRun{RepoID: repo.ID, Repo: repo}}
+
+	// the default mode is permissive
+	p, err := GetEffectiveJobPermissions(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, PermissionWrite, p["contents"])
+	assert.Equal(t, PermissionWrite, p["packages"])
+	assert.False(t, p.CanRequestIDToken())
+
+	require.NoError(t, user_model.SetUserSetting(ctx, repo.OwnerID, user_model.SettingsKeyActionsTokenPermissionMode, string(TokenPermissionModeRestricted)))
+	p, err = GetEffectiveJobPermissions(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, JobPermissions{"contents": PermissionRead, "packages": PermissionRead}, p)
+
+	// the permissions of the job override the default mode
+	job.Permissions = JobPermissions{"contents": PermissionWrite, "id-token": PermissionWrite}
+	p, err = GetEffectiveJobPermissions(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, job.Permissions, p)
+
+	// the tokens of the jobs triggered by the pull requests from forks could only read
+	job.IsForkPullRequest = true
+	p, err = GetEffectiveJobPermissions(ctx, job)
+	require.NoError(t, err)
+	assert.Equal(t, JobPermissions{"contents": PermissionRead, "id-token": PermissionRead}, p)
+	assert.False(t, p.CanRequestIDToken())
+}
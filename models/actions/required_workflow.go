@@ -0,0 +1,114 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(ActionRequiredWorkflow))
+}
+
+// ActionRequiredWorkflow is a workflow in a repository of an organization which runs for the other repositories of the organization,
+// the repositories cannot disable it and its jobs are required status checks of their protected branches.
+type ActionRequiredWorkflow struct {
+	ID           int64              `xorm:"pk autoincr"`
+	OwnerID      int64              `xorm:"index UNIQUE(repo_path)"`
+	RepoID       int64              `xorm:"UNIQUE(repo_path)"` // the repository containing the workflow, the workflow on its default branch is used
+	WorkflowPath string             `xorm:"VARCHAR(255) UNIQUE(repo_path) NOT NULL"`
+	Name         string             `xorm:"VARCHAR(255) NOT NULL"` // the name of the workflow, the status checks of its jobs start with it
+	RepoPatterns []string           `xorm:"JSON TEXT"`             // glob patterns of the repository names it runs for, all repositories if it's empty
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+// MatchRepo returns whether the workflow is required for the repository with the name
+func (rw *ActionRequiredWorkflow) MatchRepo(repoName string) bool {
+	if len(rw.RepoPatterns) == 0 {
+		return true
+	}
+	for _, pattern := range rw.RepoPatterns {
+		g, err := glob.Compile(pattern)
+		if err != nil {
+			log.Warn("Invalid repository pattern %q of required workflow %d: %v", pattern, rw.ID, err)
+			continue
+		}
+		if g.Match(repoName) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusCheckContext returns the pattern matching the commit statuses of all the jobs of the workflow,
+// see `createCommitStatus` for the format of the context.
+func (rw *ActionRequiredWorkflow) StatusCheckContext() string {
+	return rw.Name + " / *"
+}
+
+// GetRequiredWorkflowByID returns the required workflow by id
+func GetRequiredWorkflowByID(ctx context.Context, id int64) (*ActionRequiredWorkflow, error) {
+	var rw ActionRequiredWorkflow
+	has, err := db.GetEngine(ctx).ID(id).Get(&rw)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("required workflow with id %d: %w", id, util.ErrNotExist)
+	}
+	return &rw, nil
+}
+
+// CreateRequiredWorkflow creates a new required workflow, a workflow could only be required once
+func CreateRequiredWorkflow(ctx context.Context, rw *ActionRequiredWorkflow) error {
+	exist, err := db.GetEngine(ctx).Exist(&ActionRequiredWorkflow{OwnerID: rw.OwnerID, RepoID: rw.RepoID, WorkflowPath: rw.WorkflowPath})
+	if err != nil {
+		return err
+	} else if exist {
+		return fmt.Errorf("required workflow %q: %w", rw.WorkflowPath, util.ErrAlreadyExist)
+	}
+	return db.Insert(ctx, rw)
+}
+
+// UpdateRequiredWorkflow updates the name and the repository patterns of the required workflow
+func UpdateRequiredWorkflow(ctx context.Context, rw *ActionRequiredWorkflow) error {
+	_, err := db.GetEngine(ctx).ID(rw.ID).Cols("name", "repo_patterns").Update(rw)
+	return err
+}
+
+// DeleteRequiredWorkflow deletes the required workflow, the runs created by it are kept
+func DeleteRequiredWorkflow(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&ActionRequiredWorkflow{})
+	return err
+}
+
+type FindRequiredWorkflowsOptions struct {
+	db.ListOptions
+	OwnerID int64
+	RepoID  int64
+}
+
+func (opts FindRequiredWorkflowsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	return cond
+}
+
+func (opts FindRequiredWorkflowsOptions) ToOrders() string {
+	return "name ASC"
+}
@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionRequiredWorkflow_MatchRepo(t *testing.T) {
+	rw := &ActionRequiredWorkflow{Name: "Compliance"}
+	assert.True(t, rw.MatchRepo("any"))
+	assert.Equal(t, "Compliance / *", rw.StatusCheckContext())
+
+	rw.RepoPatterns = []string{"service-*", "gateway"}
+	assert.True(t, rw.MatchRepo("service-api"))
+	assert.True(t, rw.MatchRepo("gateway"))
+	assert.False(t, rw.MatchRepo("gateway-v2"))
+	assert.False(t, rw.MatchRepo("docs"))
+}
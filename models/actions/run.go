@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	api "code.gitea.io/gitea/modules/structs"
@@ -26,27 +27,30 @@ import (
 
 // ActionRun represents a run of a workflow file
 type ActionRun struct {
-	ID                int64
-	Title             string
-	RepoID            int64                  `xorm:"index unique(repo_index)"`
-	Repo              *repo_model.Repository `xorm:"-"`
-	OwnerID           int64                  `xorm:"index"`
-	WorkflowID        string                 `xorm:"index"`                    // the name of workflow file
-	Index             int64                  `xorm:"index unique(repo_index)"` // a unique number for each run of a repository
-	TriggerUserID     int64                  `xorm:"index"`
-	TriggerUser       *user_model.User       `xorm:"-"`
-	ScheduleID        int64
-	Ref               string `xorm:"index"` // the commit/tag/… that caused the run
-	IsRefDeleted      bool   `xorm:"-"`
-	CommitSHA         string
-	IsForkPullRequest bool                         // If this is triggered by a PR from a forked repository or an untrusted user, we need to check if it is approved and limit permissions when running the workflow.
-	NeedApproval      bool                         // may need approval if it's a fork pull request
-	ApprovedBy        int64                        `xorm:"index"` // who approved
-	Event             webhook_module.HookEventType // the webhook event that causes the workflow to run
-	EventPayload      string                       `xorm:"LONGTEXT"`
-	TriggerEvent      string                       // the trigger event defined in the `on` configuration of the triggered workflow
-	Status            Status                       `xorm:"index"`
-	Version           int                          `xorm:"version default 0"` // Status could be updated concomitantly, so an optimistic lock is needed
+	ID                 int64
+	Title              string
+	RepoID             int64                  `xorm:"index unique(repo_index)"`
+	Repo               *repo_model.Repository `xorm:"-"`
+	OwnerID            int64                  `xorm:"index"`
+	WorkflowID         string                 `xorm:"index"`                    // the name of workflow file
+	Index              int64                  `xorm:"index unique(repo_index)"` // a unique number for each run of a repository
+	TriggerUserID      int64                  `xorm:"index"`
+	TriggerUser        *user_model.User       `xorm:"-"`
+	ScheduleID         int64
+	RequiredWorkflowID int64  `xorm:"index"` // the id of the required workflow of the organization which created the run, 0 for the workflows of the repository
+	Ref                string `xorm:"index"` // the commit/tag/… that caused the run
+	IsRefDeleted       bool   `xorm:"-"`
+	CommitSHA          string
+	IsForkPullRequest  bool                         // If this is triggered by a PR from a forked repository or an untrusted user, we need to check if it is approved and limit permissions when running the workflow.
+	NeedApproval       bool                         // may need approval if it's a fork pull request
+	ApprovedBy         int64                        `xorm:"index"` // who approved
+	Event              webhook_module.HookEventType // the webhook event that causes the workflow to run
+	EventPayload       string                       `xorm:"LONGTEXT"`
+	TriggerEvent       string                       // the trigger event defined in the `on` configuration of the triggered workflow
+	Status             Status                       `xorm:"index"`
+	Version            int                          `xorm:"version default 0"` // Status could be updated concomitantly, so an optimistic lock is needed
+	ConcurrencyGroup   string                       `xorm:"index"`             // the evaluated `concurrency.group` of the workflow
+	ConcurrencyCancel  bool                         // the evaluated `concurrency.cancel-in-progress` of the workflow
 	// Started and Stopped is used for recording last run time, if rerun happened, they will be reset to 0
 	Started timeutil.TimeStamp
 	Stopped timeutil.TimeStamp
@@ -164,6 +168,17 @@ func (run *ActionRun) GetPullRequestEventPayload() (*api.PullRequestPayload, err
 	return nil, fmt.Errorf("event %s is not a pull request event", run.Event)
 }
 
+func (run *ActionRun) GetMergeGroupEventPayload() (*api.MergeGroupPayload, error) {
+	if run.Event == webhook_module.HookEventMergeGroup {
+		var payload api.MergeGroupPayload
+		if err := json.Unmarshal([]byte(run.EventPayload), &payload); err != nil {
+			return nil, err
+		}
+		return &payload, nil
+	}
+	return nil, fmt.Errorf("event %s is not a merge group event", run.Event)
+}
+
 func (run *ActionRun) IsSchedule() bool {
 	return run.ScheduleID > 0
 }
@@ -222,48 +237,58 @@ func CancelPreviousJobs(ctx context.Context, repoID int64, ref, workflowID strin
 			return err
 		}
 
-		// Iterate over each job and attempt to cancel it.
-		for _, job := range jobs {
-			// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
+		if err := CancelJobs(ctx, jobs); err != nil {
+			return err
+		}
+	}
 
-			// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
-			if job.TaskID == 0 {
-				job.Status = StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
+	// Return nil to indicate successful cancellation of all running and waiting jobs.
+	return nil
+}
 
-				// Update the job's status and stopped time in the database.
-				n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
+// CancelJobs cancels the given jobs which are not done yet
+func CancelJobs(ctx context.Context, jobs []*ActionRunJob) error {
+	// Iterate over each job and attempt to cancel it.
+	for _, job := range jobs {
+		// Skip jobs that are already in a terminal state (completed, cancelled, etc.).
+		status := job.Status
+		if status.IsDone() {
+			continue
+		}
 
-				// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
+		// If the job has no associated task (probably an error), set its status to 'Cancelled' and stop it.
+		if job.TaskID == 0 {
+			job.Status = StatusCancelled
+			job.Stopped = timeutil.TimeStampNow()
 
-				// Continue with the next job.
-				continue
+			// Update the job's status and stopped time in the database.
+			n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
+			if err != nil {
+				return err
 			}
 
-			// If the job has an associated task, try to stop the task, effectively cancelling the job.
-			if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
-				return err
+			// If the update affected 0 rows, it means the job has changed in the meantime, so we need to try again.
+			if n == 0 {
+				return fmt.Errorf("job has changed, try again")
 			}
+
+			// Continue with the next job.
+			continue
 		}
-	}
 
-	// Return nil to indicate successful cancellation of all running and waiting jobs.
+		// If the job has an associated task, try to stop the task, effectively cancelling the job.
+		if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // InsertRun inserts a run
 // The title will be cut off at 255 characters if it's longer than 255 characters.
-func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWorkflow) error {
+// jobConcurrencies contains the evaluated concurrency settings of the jobs, it could be nil or have nil elements,
+// so does jobCalls which contains the reusable workflow call settings of the jobs.
+func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWorkflow, jobConcurrencies []*JobConcurrency, jobEnvironments []string, jobCalls []*JobWorkflowCall, jobPermissions []JobPermissions) error {
 	ctx, committer, err := db.TxContext(ctx)
 	if err != nil {
 		return err
@@ -277,6 +302,17 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 	run.Index = index
 	run.Title, _ = util.SplitStringAtByteN(run.Title, 255)
 
+	if err := CancelConcurrentRuns(ctx, run); err != nil {
+		return err
+	}
+	blockedByConcurrency, err := ShouldBlockRunByConcurrency(ctx, run)
+	if err != nil {
+		return err
+	}
+	if blockedByConcurrency {
+		run.Status = StatusBlocked
+	}
+
 	if err := db.Insert(ctx, run); err != nil {
 		return err
 	}
@@ -294,22 +330,18 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 	}
 
 	runJobs := make([]*ActionRunJob, 0, len(jobs))
-	var hasWaiting bool
-	for _, v := range jobs {
+	// the concurrency groups which are held by the jobs of this run
+	heldGroups := make(container.Set[string])
+	var hasWaiting, hasEnvironment bool
+	for i, v := range jobs {
 		id, job := v.Job()
 		needs := job.Needs()
 		if err := v.SetJob(id, job.EraseNeeds()); err != nil {
 			return err
 		}
 		payload, _ := v.Marshal()
-		status := StatusWaiting
-		if len(needs) > 0 || run.NeedApproval {
-			status = StatusBlocked
-		} else {
-			hasWaiting = true
-		}
 		job.Name, _ = util.SplitStringAtByteN(job.Name, 255)
-		runJobs = append(runJobs, &ActionRunJob{
+		runJob := &ActionRunJob{
 			RunID:             run.ID,
 			RepoID:            run.RepoID,
 			OwnerID:           run.OwnerID,
@@ -320,12 +352,69 @@ func InsertRun(ctx context.Context, run *ActionRun, jobs []*jobparser.SingleWork
 			JobID:             id,
 			Needs:             needs,
 			RunsOn:            job.RunsOn(),
-			Status:            status,
-		})
+		}
+		if i < len(jobConcurrencies) && jobConcurrencies[i] != nil {
+			runJob.ConcurrencyGroup = jobConcurrencies[i].Group
+			runJob.ConcurrencyCancel = jobConcurrencies[i].Cancel
+		}
+
+		if i < len(jobCalls) && jobCalls[i] != nil {
+			runJob.CallPath = jobCalls[i].CallPath
+			runJob.CallerPath = jobCalls[i].CallerPath
+		}
+
+		if i < len(jobPermissions) {
+			runJob.Permissions = jobPermissions[i]
+		}
+
+		if i < len(jobEnvironments) && jobEnvironments[i] != "" {
+			env, err := GetOrCreateEnvironment(ctx, run.RepoID, jobEnvironments[i])
+			if err != nil {
+				return err
+			}
+			runJob.EnvironmentID = env.ID
+			hasEnvironment = true
+		}
+
+		runJob.Status = StatusWaiting
+		// the jobs deploying to an environment stay blocked until they pass the protection rules of the environment,
+		// and the jobs of reusable workflow calls stay blocked until the calls start
+		if len(needs) > 0 || run.NeedApproval || blockedByConcurrency || runJob.EnvironmentID > 0 || runJob.CallPath != "" || runJob.CallerPath != "" {
+			runJob.Status = StatusBlocked
+		} else if runJob.ConcurrencyGroup != "" {
+			if err := CancelConcurrentJobs(ctx, runJob); err != nil {
+				return err
+			}
+			blocked, err := ShouldBlockJobByConcurrency(ctx, runJob)
+			if err != nil {
+				return err
+			}
+			if blocked || heldGroups.Contains(runJob.ConcurrencyGroup) {
+				runJob.Status = StatusBlocked
+			} else {
+				heldGroups.Add(runJob.ConcurrencyGroup)
+			}
+		}
+		hasWaiting = hasWaiting || runJob.Status == StatusWaiting
+		runJobs = append(runJobs, runJob)
 	}
 	if err := db.Insert(ctx, runJobs); err != nil {
 		return err
 	}
+	if hasEnvironment {
+		// reload the jobs since the ids aren't filled by the batch insertion
+		insertedJobs, err := GetRunJobsByRunID(ctx, run.ID)
+		if err != nil {
+			return err
+		}
+		for _, runJob := range insertedJobs {
+			if runJob.EnvironmentID > 0 {
+				if _, err := CreateDeploymentForJob(ctx, run, runJob); err != nil {
+					return err
+				}
+			}
+		}
+	}
 
 	// if there is a job in the waiting status, increase tasks version.
 	if hasWaiting {
@@ -28,11 +28,17 @@ type ActionRunJob struct {
 	Name              string `xorm:"VARCHAR(255)"`
 	Attempt           int64
 	WorkflowPayload   []byte
-	JobID             string   `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
-	Needs             []string `xorm:"JSON TEXT"`
-	RunsOn            []string `xorm:"JSON TEXT"`
-	TaskID            int64    // the latest task of the job
-	Status            Status   `xorm:"index"`
+	JobID             string         `xorm:"VARCHAR(255)"` // job id in workflow, not job's id
+	Needs             []string       `xorm:"JSON TEXT"`
+	RunsOn            []string       `xorm:"JSON TEXT"`
+	TaskID            int64          // the latest task of the job
+	Status            Status         `xorm:"index"`
+	ConcurrencyGroup  string         `xorm:"index"` // the evaluated `concurrency.group` of the job
+	ConcurrencyCancel bool           // the evaluated `concurrency.cancel-in-progress` of the job
+	EnvironmentID     int64          `xorm:"index"`        // the environment the job deploys to
+	CallPath          string         `xorm:"VARCHAR(255)"` // the path of the reusable workflow call made by the job like `deploy/build`, empty if the job doesn't call a reusable workflow
+	CallerPath        string         `xorm:"VARCHAR(255)"` // the CallPath of the job calling the reusable workflow which the job belongs to, empty for the jobs of the run's workflow
+	Permissions       JobPermissions `xorm:"JSON TEXT"`    // the evaluated `permissions` of the job, nil if they are not set by the workflow
 	Started           timeutil.TimeStamp
 	Stopped           timeutil.TimeStamp
 	Created           timeutil.TimeStamp `xorm:"created"`
@@ -43,6 +49,18 @@ func init() {
 	db.RegisterModel(new(ActionRunJob))
 }
 
+// IsWorkflowCall returns whether the job calls a reusable workflow,
+// such a job isn't run by runners but finishes with the jobs of the called workflow.
+func (job *ActionRunJob) IsWorkflowCall() bool {
+	return job.CallPath != ""
+}
+
+// JobWorkflowCall contains the reusable workflow call settings of a job which is being inserted
+type JobWorkflowCall struct {
+	CallPath   string
+	CallerPath string
+}
+
 func (job *ActionRunJob) Duration() time.Duration {
 	return calculateDuration(job.Started, job.Stopped, job.Status)
 }
@@ -119,6 +137,10 @@ func UpdateRunJob(ctx context.Context, job *ActionRunJob, cond builder.Cond, col
 		}
 	}
 
+	if err := syncDeploymentStatus(ctx, job); err != nil {
+		return 0, fmt.Errorf("sync deployment status of job %d: %w", job.ID, err)
+	}
+
 	if job.RunID == 0 {
 		var err error
 		if job, err = GetRunJobByID(ctx, job.ID); err != nil {
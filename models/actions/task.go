@@ -41,6 +41,7 @@ type ActionTask struct {
 	OwnerID           int64  `xorm:"index"`
 	CommitSHA         string `xorm:"index"`
 	IsForkPullRequest bool
+	Permissions       JobPermissions `xorm:"JSON TEXT"` // the effective permissions of the token of the task
 
 	Token          string `xorm:"-"`
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
@@ -276,6 +277,9 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 	if err := task.GenerateToken(); err != nil {
 		return nil, false, err
 	}
+	if task.Permissions, err = GetEffectiveJobPermissions(ctx, job); err != nil {
+		return nil, false, err
+	}
 
 	var workflowJob *jobparser.Job
 	if gots, err := jobparser.Parse(job.WorkflowPayload); err != nil {
@@ -0,0 +1,110 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// MaxAnnotationsPerTask is the max number of the annotations kept for a task, the others are dropped like GitHub
+const MaxAnnotationsPerTask = 50
+
+// ActionTaskAnnotation is created by a `::notice`, `::warning` or `::error` workflow command in the logs of a task
+type ActionTaskAnnotation struct {
+	ID          int64
+	TaskID      int64  `xorm:"index"`
+	RunID       int64  `xorm:"index"`
+	RepoID      int64  `xorm:"index(repo_commit)"`
+	CommitSHA   string `xorm:"VARCHAR(64) index(repo_commit)"`
+	LogIndex    int64  // the index of the log row of the command
+	Level       string `xorm:"VARCHAR(16)"`
+	Title       string `xorm:"VARCHAR(255)"`
+	Message     string `xorm:"TEXT"`
+	Path        string `xorm:"VARCHAR(4000)"`
+	StartLine   int64
+	EndLine     int64
+	StartColumn int64
+	EndColumn   int64
+	Created     timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionTaskAnnotation))
+}
+
+// HasLocation returns whether the annotation points to lines of a file
+func (a *ActionTaskAnnotation) HasLocation() bool {
+	return a.Path != "" && a.StartLine > 0
+}
+
+// CoversLine returns whether the annotation points to the line of the file
+func (a *ActionTaskAnnotation) CoversLine(path string, line int64) bool {
+	return a.Path == path && a.StartLine <= line && line <= a.EndLine
+}
+
+type FindTaskAnnotationOptions struct {
+	db.ListOptions
+	TaskID    int64
+	RunID     int64
+	RepoID    int64
+	CommitSHA string
+	HasPath   bool
+}
+
+func (opts FindTaskAnnotationOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.TaskID > 0 {
+		cond = cond.And(builder.Eq{"task_id": opts.TaskID})
+	}
+	if opts.RunID > 0 {
+		cond = cond.And(builder.Eq{"run_id": opts.RunID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.CommitSHA != "" {
+		cond = cond.And(builder.Eq{"commit_sha": opts.CommitSHA})
+	}
+	if opts.HasPath {
+		cond = cond.And(builder.Neq{"path": ""}).And(builder.Gt{"start_line": 0})
+	}
+	return cond
+}
+
+func (opts FindTaskAnnotationOptions) ToOrders() string {
+	return "`task_id` ASC, `log_index` ASC"
+}
+
+// InsertTaskAnnotations inserts the annotations of the task, the annotations exceeding MaxAnnotationsPerTask are dropped
+func InsertTaskAnnotations(ctx context.Context, task *ActionTask, annotations []*ActionTaskAnnotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	if err := task.LoadJob(ctx); err != nil {
+		return err
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		count, err := db.GetEngine(ctx).Where("task_id=?", task.ID).Count(new(ActionTaskAnnotation))
+		if err != nil {
+			return err
+		}
+		if remaining := MaxAnnotationsPerTask - int(count); remaining <= 0 {
+			return nil
+		} else if len(annotations) > remaining {
+			annotations = annotations[:remaining]
+		}
+		for _, a := range annotations {
+			a.TaskID = task.ID
+			a.RunID = task.Job.RunID
+			a.RepoID = task.RepoID
+			a.CommitSHA = task.CommitSHA
+		}
+		return db.Insert(ctx, annotations)
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// MaxTaskSummarySize is the max size of the summary of a step, it's 1MiB like GitHub
+const MaxTaskSummarySize = 1024 * 1024
+
+// ActionTaskSummary is the markdown a step of a task writes to the file of `$GITHUB_STEP_SUMMARY`
+type ActionTaskSummary struct {
+	ID        int64
+	TaskID    int64              `xorm:"index unique(task_step)"`
+	StepIndex int64              `xorm:"unique(task_step)"`
+	RepoID    int64              `xorm:"index"`
+	Content   string             `xorm:"LONGTEXT"`
+	Created   timeutil.TimeStamp `xorm:"created"`
+	Updated   timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionTaskSummary))
+}
+
+// GetTaskSummaries returns the summaries of the steps of the task in order
+func GetTaskSummaries(ctx context.Context, taskID int64) ([]*ActionTaskSummary, error) {
+	var summaries []*ActionTaskSummary
+	return summaries, db.GetEngine(ctx).Where("task_id=?", taskID).OrderBy("step_index ASC").Find(&summaries)
+}
+
+// SetTaskSummary saves the summary of the step of the task, it replaces the summary uploaded before
+func SetTaskSummary(ctx context.Context, task *ActionTask, stepIndex int64, content string) error {
+	if len(content) > MaxTaskSummarySize {
+		return util.NewInvalidArgumentErrorf("the summary is larger than %d bytes", MaxTaskSummarySize)
+	}
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		summary := &ActionTaskSummary{}
+		has, err := db.GetEngine(ctx).Where("task_id=? AND step_index=?", task.ID, stepIndex).Get(summary)
+		if err != nil {
+			return err
+		}
+		if content == "" {
+			if has {
+				_, err = db.DeleteByID[ActionTaskSummary](ctx, summary.ID)
+			}
+			return err
+		}
+		summary.TaskID = task.ID
+		summary.StepIndex = stepIndex
+		summary.RepoID = task.RepoID
+		summary.Content = content
+		if has {
+			_, err = db.GetEngine(ctx).ID(summary.ID).Cols("content").Update(summary)
+			return err
+		}
+		return db.Insert(ctx, summary)
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTaskSummary(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+	task := &ActionTask{ID: 1000, RepoID: 4}
+
+	require.NoError(t, SetTaskSummary(ctx, task, 1, "## Tests\n\nfailed"))
+	require.NoError(t, SetTaskSummary(ctx, task, 0, "## Build"))
+	summaries, err := GetTaskSummaries(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, summaries, 2)
+	assert.Equal(t, "## Build", summaries[0].Content)
+
+	// uploading again replaces the summary, an empty one removes it
+	require.NoError(t, SetTaskSummary(ctx, task, 1, "## Tests\n\npassed"))
+	require.NoError(t, SetTaskSummary(ctx, task, 0, ""))
+	summaries, err = GetTaskSummaries(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "## Tests\n\npassed", summaries[0].Content)
+
+	err = SetTaskSummary(ctx, task, 2, strings.Repeat("a", MaxTaskSummarySize+1))
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+}
+
+func TestInsertTaskAnnotations(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+	task := &ActionTask{ID: 1000, RepoID: 4, CommitSHA: "c2d72f548424103f01ee1dc02889c1e2bff816b0", Job: &ActionRunJob{RunID: 10}}
+
+	newAnnotations := func(n int) []*ActionTaskAnnotation {
+		annotations := make([]*ActionTaskAnnotation, n)
+		for i := range annotations {
+			annotations[i] = &ActionTaskAnnotation{Level: "error", Message: "failed", LogIndex: int64(i)}
+		}
+		return annotations
+	}
+	require.NoError(t, InsertTaskAnnotations(ctx, task, newAnnotations(30)))
+	require.NoError(t, InsertTaskAnnotations(ctx, task, newAnnotations(30)))
+
+	annotations, err := db.Find[ActionTaskAnnotation](ctx, FindTaskAnnotationOptions{TaskID: task.ID})
+	require.NoError(t, err)
+	assert.Len(t, annotations, MaxAnnotationsPerTask)
+	assert.EqualValues(t, 10, annotations[0].RunID)
+	assert.Equal(t, task.CommitSHA, annotations[0].CommitSHA)
+}
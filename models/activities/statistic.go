@@ -34,6 +34,9 @@ type Statistic struct {
 		Branches, Tags, CommitStatus int64
 		IssueByLabel      []IssueByLabelCount
 		IssueByRepository []IssueByRepositoryCount
+
+		RepoHousekeepingByStatus     map[repo_model.HousekeepingStatus]int64
+		RepoHousekeepingByRepository []RepoHousekeepingByRepositoryCount
 	}
 }
 
@@ -50,6 +53,15 @@ type IssueByRepositoryCount struct {
 	Repository string
 }
 
+// RepoHousekeepingByRepositoryCount contains the housekeeping statistics of a repository
+type RepoHousekeepingByRepositoryCount struct {
+	OwnerName        string
+	Repository       string
+	LooseObjects     int64
+	Packs            int64
+	PushesSinceCheck int64
+}
+
 // GetStatistic returns the database statistics
 func GetStatistic(ctx context.Context) (stats Statistic) {
 	e := db.GetEngine(ctx)
@@ -89,6 +101,27 @@ func GetStatistic(ctx context.Context) (stats Statistic) {
 			Find(&stats.Counter.IssueByRepository)
 	}
 
+	type RepoHousekeepingCount struct {
+		Count  int64
+		Status repo_model.HousekeepingStatus
+	}
+
+	var housekeepingCounts []RepoHousekeepingCount
+	stats.Counter.RepoHousekeepingByStatus = make(map[repo_model.HousekeepingStatus]int64, len(repo_model.HousekeepingStatuses))
+	_ = e.Select("COUNT(*) AS count, status").Table("repo_housekeeping").GroupBy("status").Find(&housekeepingCounts)
+	for _, c := range housekeepingCounts {
+		stats.Counter.RepoHousekeepingByStatus[c.Status] = c.Count
+	}
+
+	if setting.Metrics.EnabledHousekeepingByRepository {
+		stats.Counter.RepoHousekeepingByRepository = []RepoHousekeepingByRepositoryCount{}
+
+		_ = e.Select("r.owner_name, r.name AS repository, h.loose_objects, h.packs, h.pushes_since_check").
+			Join("INNER", "repository r", "r.id=h.repo_id").
+			Table("repo_housekeeping h").
+			Find(&stats.Counter.RepoHousekeepingByRepository)
+	}
+
 	var issueCounts []IssueCount
 
 	_ = e.Select("COUNT(*) AS count, is_closed").Table("issue").GroupBy("is_closed").Find(&issueCounts)
@@ -0,0 +1,262 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package audit stores the security relevant actions of the instance.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/globallock"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// Action is the kind of a recorded action
+type Action string
+
+const (
+	ActionUserLogin       Action = "user_login"
+	ActionUserLoginFailed Action = "user_login_failed"
+	ActionUserCreate      Action = "user_create"
+	ActionUserUpdate      Action = "user_update"
+	ActionUserDelete      Action = "user_delete"
+
+	ActionTwoFactorEnable     Action = "two_factor_enable"
+	ActionTwoFactorDisable    Action = "two_factor_disable"
+	ActionTwoFactorRegenerate Action = "two_factor_regenerate_scratch"
+	ActionWebAuthnAdd         Action = "webauthn_add"
+	ActionWebAuthnRemove      Action = "webauthn_remove"
+
+	ActionAccessTokenCreate Action = "access_token_create"
+	ActionAccessTokenDelete Action = "access_token_delete"
+
+	ActionCollaboratorAdd    Action = "collaborator_add"
+	ActionCollaboratorUpdate Action = "collaborator_update"
+	ActionCollaboratorRemove Action = "collaborator_remove"
+
+	ActionTeamCreate       Action = "team_create"
+	ActionTeamUpdate       Action = "team_update"
+	ActionTeamDelete       Action = "team_delete"
+	ActionTeamMemberAdd    Action = "team_member_add"
+	ActionTeamMemberRemove Action = "team_member_remove"
+	ActionTeamRepoAdd      Action = "team_repo_add"
+	ActionTeamRepoRemove   Action = "team_repo_remove"
+
+	ActionBranchProtectionCreate Action = "branch_protection_create"
+	ActionBranchProtectionUpdate Action = "branch_protection_update"
+	ActionBranchProtectionDelete Action = "branch_protection_delete"
+
+	ActionRepoVisibility    Action = "repo_visibility"
+	ActionRepoTransferStart Action = "repo_transfer_start"
+	ActionRepoTransfer      Action = "repo_transfer"
+	ActionRepoDelete        Action = "repo_delete"
+
+	ActionSecretCreate Action = "secret_create"
+	ActionSecretUpdate Action = "secret_update"
+	ActionSecretDelete Action = "secret_delete"
+)
+
+// Actions are all the recorded actions, they are listed by the filter of the audit log pages
+var Actions = []Action{
+	ActionUserLogin, ActionUserLoginFailed, ActionUserCreate, ActionUserUpdate, ActionUserDelete,
+	ActionTwoFactorEnable, ActionTwoFactorDisable, ActionTwoFactorRegenerate, ActionWebAuthnAdd, ActionWebAuthnRemove,
+	ActionAccessTokenCreate, ActionAccessTokenDelete,
+	ActionCollaboratorAdd, ActionCollaboratorUpdate, ActionCollaboratorRemove,
+	ActionTeamCreate, ActionTeamUpdate, ActionTeamDelete, ActionTeamMemberAdd, ActionTeamMemberRemove, ActionTeamRepoAdd, ActionTeamRepoRemove,
+	ActionBranchProtectionCreate, ActionBranchProtectionUpdate, ActionBranchProtectionDelete,
+	ActionRepoVisibility, ActionRepoTransferStart, ActionRepoTransfer, ActionRepoDelete,
+	ActionSecretCreate, ActionSecretUpdate, ActionSecretDelete,
+}
+
+// TargetType is the type of the object an action is applied to
+type TargetType string
+
+const (
+	TargetUser             TargetType = "user"
+	TargetTwoFactor        TargetType = "two_factor"
+	TargetWebAuthn         TargetType = "webauthn_credential"
+	TargetAccessToken      TargetType = "access_token"
+	TargetRepository       TargetType = "repository"
+	TargetTeam             TargetType = "team"
+	TargetBranchProtection TargetType = "branch_protection"
+	TargetSecret           TargetType = "secret"
+)
+
+// TargetTypes are all the target types, they are listed by the filter of the audit log pages
+var TargetTypes = []TargetType{
+	TargetUser, TargetTwoFactor, TargetWebAuthn, TargetAccessToken,
+	TargetRepository, TargetTeam, TargetBranchProtection, TargetSecret,
+}
+
+// Event is a recorded action.
+//
+// OwnerID is the user or organization the action belongs to, it's the owner of the repository for the actions
+// of a repository, so the organization owners can browse the events of all the repositories of the organization.
+// RepoID is the repository of the action or 0. Both are 0 for the instance wide actions like the logins.
+//
+// The events are chained by their hashes: the hash of an event covers all of its fields and the hash of the previous
+// event, so a modified or deleted event breaks the chain and is reported by VerifyChain.
+type Event struct {
+	ID          int64
+	Action      Action     `xorm:"VARCHAR(64) INDEX NOT NULL"`
+	ActorID     int64      `xorm:"INDEX"`
+	ActorName   string     `xorm:"VARCHAR(255)"`
+	IPAddress   string     `xorm:"VARCHAR(64)"`
+	OwnerID     int64      `xorm:"INDEX"`
+	RepoID      int64      `xorm:"INDEX"`
+	TargetType  TargetType `xorm:"VARCHAR(32)"`
+	TargetID    int64
+	TargetName  string             `xorm:"VARCHAR(255)"`
+	Before      string             `xorm:"TEXT"` // the JSON of the target before the action
+	After       string             `xorm:"TEXT"` // the JSON of the target after the action
+	PrevHash    string             `xorm:"VARCHAR(64)"`
+	Hash        string             `xorm:"VARCHAR(64) UNIQUE"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+}
+
+// TableName returns the table name of the events
+func (*Event) TableName() string {
+	return "audit_event"
+}
+
+func init() {
+	db.RegisterModel(new(Event))
+}
+
+func writeHashField(h hash.Hash, value string) {
+	_, _ = fmt.Fprintf(h, "%d:%s\n", len(value), value)
+}
+
+// ComputeHash returns the hash of the fields of the event and the hash of the previous event
+func (e *Event) ComputeHash() string {
+	h := sha256.New()
+	for _, value := range []string{
+		string(e.Action),
+		strconv.FormatInt(e.ActorID, 10),
+		e.ActorName,
+		e.IPAddress,
+		strconv.FormatInt(e.OwnerID, 10),
+		strconv.FormatInt(e.RepoID, 10),
+		string(e.TargetType),
+		strconv.FormatInt(e.TargetID, 10),
+		e.TargetName,
+		e.Before,
+		e.After,
+		e.PrevHash,
+		strconv.FormatInt(int64(e.CreatedUnix), 10),
+	} {
+		writeHashField(h, value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+const lockKey = "audit_event"
+
+// InsertEvent chains the event to the last one and inserts it. The events must be inserted in their own transaction,
+// otherwise a rolled back transaction could leave the chain to a hash which doesn't exist.
+func InsertEvent(ctx context.Context, e *Event) error {
+	if db.InTransaction(ctx) {
+		return errors.New("audit events can't be inserted in a transaction")
+	}
+	return globallock.LockAndDo(ctx, lockKey, func(ctx context.Context) error {
+		last := &Event{}
+		has, err := db.GetEngine(ctx).Cols("hash").Desc("id").Limit(1).Get(last)
+		if err != nil {
+			return err
+		}
+		e.PrevHash = ""
+		if has {
+			e.PrevHash = last.Hash
+		}
+		if e.CreatedUnix == 0 {
+			e.CreatedUnix = timeutil.TimeStampNow()
+		}
+		e.Hash = e.ComputeHash()
+		return db.Insert(ctx, e)
+	})
+}
+
+// FindEventsOptions are the options to find the events, the newest events are listed first
+type FindEventsOptions struct {
+	db.ListOptions
+	OwnerID    int64
+	RepoID     int64
+	Action     Action
+	ActorName  string
+	TargetType TargetType
+	Since      timeutil.TimeStamp
+	Before     timeutil.TimeStamp
+}
+
+func (opts FindEventsOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID != 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Action != "" {
+		cond = cond.And(builder.Eq{"action": opts.Action})
+	}
+	if opts.ActorName != "" {
+		cond = cond.And(builder.Expr("LOWER(actor_name) = ?", strings.ToLower(opts.ActorName)))
+	}
+	if opts.TargetType != "" {
+		cond = cond.And(builder.Eq{"target_type": opts.TargetType})
+	}
+	if opts.Since != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+func (opts FindEventsOptions) ToOrders() string {
+	return "id DESC"
+}
+
+// ErrChainBroken is returned by VerifyChain if an event doesn't match its hash or the hash of the previous event
+type ErrChainBroken struct {
+	EventID int64
+}
+
+func (err ErrChainBroken) Error() string {
+	return fmt.Sprintf("audit event chain is broken at event %d", err.EventID)
+}
+
+// VerifyChain checks the hashes of all the events and returns ErrChainBroken for the first event which doesn't match
+func VerifyChain(ctx context.Context) (count int64, err error) {
+	var lastID int64
+	var prevHash string
+	for {
+		events := make([]*Event, 0, setting.Database.IterateBufferSize)
+		if err := db.GetEngine(ctx).Where("id > ?", lastID).Asc("id").Limit(setting.Database.IterateBufferSize).Find(&events); err != nil {
+			return count, err
+		}
+		if len(events) == 0 {
+			return count, nil
+		}
+		for _, e := range events {
+			if e.PrevHash != prevHash || e.Hash != e.ComputeHash() {
+				return count, ErrChainBroken{EventID: e.ID}
+			}
+			prevHash = e.Hash
+			lastID = e.ID
+			count++
+		}
+	}
+}
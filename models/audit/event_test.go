@@ -0,0 +1,121 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func insertTestEvents(t *testing.T) []*audit_model.Event {
+	events := []*audit_model.Event{
+		{Action: audit_model.ActionUserLogin, ActorID: 2, ActorName: "user2", IPAddress: "127.0.0.1", OwnerID: 2, TargetType: audit_model.TargetUser, TargetID: 2, TargetName: "user2", CreatedUnix: 1000},
+		{Action: audit_model.ActionCollaboratorAdd, ActorID: 2, ActorName: "user2", OwnerID: 2, RepoID: 1, TargetType: audit_model.TargetUser, TargetID: 4, TargetName: "user4", After: `{"access_mode":"write"}`, CreatedUnix: 2000},
+		{Action: audit_model.ActionRepoVisibility, ActorID: 1, ActorName: "user1", OwnerID: 3, RepoID: 3, TargetType: audit_model.TargetRepository, TargetID: 3, TargetName: "org3/repo3", Before: `{"private":true}`, After: `{"private":false}`, CreatedUnix: 3000},
+	}
+	for _, e := range events {
+		require.NoError(t, audit_model.InsertEvent(db.DefaultContext, e))
+	}
+	return events
+}
+
+func TestInsertEvent(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	events := insertTestEvents(t)
+	assert.Empty(t, events[0].PrevHash)
+	assert.Equal(t, events[0].Hash, events[1].PrevHash)
+	assert.Equal(t, events[1].Hash, events[2].PrevHash)
+	assert.Equal(t, events[2].ComputeHash(), events[2].Hash)
+
+	count, err := audit_model.VerifyChain(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	err = db.WithTx(db.DefaultContext, func(ctx context.Context) error {
+		return audit_model.InsertEvent(ctx, &audit_model.Event{Action: audit_model.ActionUserLogin})
+	})
+	assert.Error(t, err)
+}
+
+func TestFindEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	insertTestEvents(t)
+
+	cases := []struct {
+		opts     audit_model.FindEventsOptions
+		expected []audit_model.Action
+	}{
+		{
+			opts:     audit_model.FindEventsOptions{},
+			expected: []audit_model.Action{audit_model.ActionRepoVisibility, audit_model.ActionCollaboratorAdd, audit_model.ActionUserLogin},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{OwnerID: 2},
+			expected: []audit_model.Action{audit_model.ActionCollaboratorAdd, audit_model.ActionUserLogin},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{OwnerID: 2, RepoID: 1},
+			expected: []audit_model.Action{audit_model.ActionCollaboratorAdd},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{ActorName: "USER1"},
+			expected: []audit_model.Action{audit_model.ActionRepoVisibility},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{Action: audit_model.ActionUserLogin},
+			expected: []audit_model.Action{audit_model.ActionUserLogin},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{TargetType: audit_model.TargetUser},
+			expected: []audit_model.Action{audit_model.ActionCollaboratorAdd, audit_model.ActionUserLogin},
+		},
+		{
+			opts:     audit_model.FindEventsOptions{Since: 2000, Before: 3000},
+			expected: []audit_model.Action{audit_model.ActionCollaboratorAdd},
+		},
+	}
+	for _, c := range cases {
+		events, err := db.Find[audit_model.Event](db.DefaultContext, c.opts)
+		assert.NoError(t, err)
+		actions := make([]audit_model.Action, 0, len(events))
+		for _, e := range events {
+			actions = append(actions, e.Action)
+		}
+		assert.Equal(t, c.expected, actions, "%+v", c.opts)
+	}
+}
+
+func TestVerifyChainTampered(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	t.Run("Modified", func(t *testing.T) {
+		events := insertTestEvents(t)
+		_, err := db.GetEngine(db.DefaultContext).ID(events[1].ID).Cols("after").Update(&audit_model.Event{After: `{"access_mode":"read"}`})
+		require.NoError(t, err)
+
+		count, err := audit_model.VerifyChain(db.DefaultContext)
+		assert.Equal(t, audit_model.ErrChainBroken{EventID: events[1].ID}, err)
+		assert.EqualValues(t, 1, count)
+	})
+
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	t.Run("Deleted", func(t *testing.T) {
+		events := insertTestEvents(t)
+		_, err := db.DeleteByID[audit_model.Event](db.DefaultContext, events[1].ID)
+		require.NoError(t, err)
+
+		count, err := audit_model.VerifyChain(db.DefaultContext)
+		assert.Equal(t, audit_model.ErrChainBroken{EventID: events[2].ID}, err)
+		assert.EqualValues(t, 1, count)
+	})
+}
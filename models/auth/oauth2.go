@@ -39,6 +39,7 @@ type OAuth2Application struct {
 	// https://datatracker.ietf.org/doc/html/rfc8252#section-8.4
 	ConfidentialClient         bool               `xorm:"NOT NULL DEFAULT TRUE"`
 	SkipSecondaryAuthorization bool               `xorm:"NOT NULL DEFAULT FALSE"`
+	EnableDeviceFlow           bool               `xorm:"NOT NULL DEFAULT FALSE"`
 	RedirectURIs               []string           `xorm:"redirect_uris JSON TEXT"`
 	CreatedUnix                timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix                timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -256,6 +257,7 @@ type CreateOAuth2ApplicationOptions struct {
 	UserID                     int64
 	ConfidentialClient         bool
 	SkipSecondaryAuthorization bool
+	EnableDeviceFlow           bool
 	RedirectURIs               []string
 }
 
@@ -269,6 +271,7 @@ func CreateOAuth2Application(ctx context.Context, opts CreateOAuth2ApplicationOp
 		RedirectURIs:               opts.RedirectURIs,
 		ConfidentialClient:         opts.ConfidentialClient,
 		SkipSecondaryAuthorization: opts.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           opts.EnableDeviceFlow,
 	}
 	if err := db.Insert(ctx, app); err != nil {
 		return nil, err
@@ -283,6 +286,7 @@ type UpdateOAuth2ApplicationOptions struct {
 	UserID                     int64
 	ConfidentialClient         bool
 	SkipSecondaryAuthorization bool
+	EnableDeviceFlow           bool
 	RedirectURIs               []string
 }
 
@@ -310,6 +314,7 @@ func UpdateOAuth2Application(ctx context.Context, opts UpdateOAuth2ApplicationOp
 	app.RedirectURIs = opts.RedirectURIs
 	app.ConfidentialClient = opts.ConfidentialClient
 	app.SkipSecondaryAuthorization = opts.SkipSecondaryAuthorization
+	app.EnableDeviceFlow = opts.EnableDeviceFlow
 
 	if err = updateOAuth2Application(ctx, app); err != nil {
 		return nil, err
@@ -320,7 +325,7 @@ func UpdateOAuth2Application(ctx context.Context, opts UpdateOAuth2ApplicationOp
 }
 
 func updateOAuth2Application(ctx context.Context, app *OAuth2Application) error {
-	if _, err := db.GetEngine(ctx).ID(app.ID).UseBool("confidential_client", "skip_secondary_authorization").Update(app); err != nil {
+	if _, err := db.GetEngine(ctx).ID(app.ID).UseBool("confidential_client", "skip_secondary_authorization", "enable_device_flow").Update(app); err != nil {
 		return err
 	}
 	return nil
@@ -352,6 +357,10 @@ func deleteOAuth2Application(ctx context.Context, id, userid int64) error {
 	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2Grant)); err != nil {
 		return err
 	}
+
+	if _, err := sess.Where("application_id = ?", id).Delete(new(OAuth2DeviceAuthorization)); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -639,6 +648,12 @@ func DeleteOAuth2RelictsByUserID(ctx context.Context, userID int64) error {
 		return err
 	}
 
+	if _, err := db.GetEngine(ctx).Where(builder.In("grant_id", deleteCond).Or(builder.In("application_id",
+		builder.Select("id").From("oauth2_application").Where(builder.Eq{"uid": userID})))).
+		Delete(&OAuth2DeviceAuthorization{}); err != nil {
+		return err
+	}
+
 	if err := db.DeleteBeans(ctx,
 		&OAuth2Application{UID: userID},
 		&OAuth2Grant{UserID: userID},
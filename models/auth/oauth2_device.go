@@ -0,0 +1,206 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// OAuth2DeviceAuthorization is an authorization request of a device without a browser (RFC 8628).
+// The device polls the token endpoint with the device code while the user enters the user code on the verification page.
+// https://datatracker.ietf.org/doc/html/rfc8628
+type OAuth2DeviceAuthorization struct {
+	ID             int64  `xorm:"pk autoincr"`
+	ApplicationID  int64  `xorm:"INDEX"`
+	DeviceCode     string `xorm:"-"`
+	DeviceCodeHash string `xorm:"UNIQUE"`
+	UserCode       string `xorm:"VARCHAR(16) UNIQUE"`
+	Scope          string `xorm:"TEXT"`
+	// GrantID is the grant of the user who approved the device, it's 0 while the authorization is pending
+	GrantID int64
+	Denied  bool `xorm:"NOT NULL DEFAULT FALSE"`
+	// Interval is the minimum number of seconds between the polls of the device, it's increased if the device polls too fast
+	Interval       int64
+	LastPolledUnix timeutil.TimeStamp
+	ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(OAuth2DeviceAuthorization))
+}
+
+// TableName sets the table name to `oauth2_device_authorization`
+func (d *OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorization"
+}
+
+// userCodeChars are the characters of the user codes, the vowels are left out to avoid words and
+// the similar looking characters are left out to make it easy to type the code on another device.
+// https://datatracker.ietf.org/doc/html/rfc8628#section-6.1
+const userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+
+const userCodeLength = 8
+
+// slowDownInterval is the number of seconds the polling interval is increased by when the device polls too fast
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
+const slowDownInterval = 5
+
+func hashDeviceCode(deviceCode string) string {
+	h := sha256.Sum256([]byte(deviceCode))
+	return hex.EncodeToString(h[:])
+}
+
+// ErrOAuth2DeviceAuthorizationNotPending is returned if the authorization has already been approved or denied
+var ErrOAuth2DeviceAuthorizationNotPending = util.NewInvalidArgumentErrorf("device authorization is not pending")
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		// CryptoRandomInt is uniform, the modulo of a random byte would favor the first characters
+		n, err := util.CryptoRandomInt(int64(len(userCodeChars)))
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeChars[n]
+	}
+	return string(code), nil
+}
+
+// NormalizeUserCode returns the user code as it is stored, the users may type it in lower case and with separators
+func NormalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(userCode))
+}
+
+// FormattedUserCode returns the user code in the form the users are asked to enter, e.g. BCDF-GHJK
+func (d *OAuth2DeviceAuthorization) FormattedUserCode() string {
+	if len(d.UserCode) != userCodeLength {
+		return d.UserCode
+	}
+	return d.UserCode[:userCodeLength/2] + "-" + d.UserCode[userCodeLength/2:]
+}
+
+// IsExpired returns whether the device code and the user code can't be used anymore
+func (d *OAuth2DeviceAuthorization) IsExpired() bool {
+	return d.ValidUntil <= timeutil.TimeStampNow()
+}
+
+// IsPending returns whether the user has neither approved nor denied the device
+func (d *OAuth2DeviceAuthorization) IsPending() bool {
+	return d.GrantID == 0 && !d.Denied
+}
+
+// CreateOAuth2DeviceAuthorization creates a pending authorization of a device for the application,
+// the device code is only returned in the DeviceCode field and only its hash is stored.
+func CreateOAuth2DeviceAuthorization(ctx context.Context, app *OAuth2Application, scope string) (*OAuth2DeviceAuthorization, error) {
+	// the expired authorizations are never used again
+	if _, err := db.GetEngine(ctx).Where(builder.Lte{"valid_until": timeutil.TimeStampNow()}).Delete(new(OAuth2DeviceAuthorization)); err != nil {
+		return nil, err
+	}
+
+	rBytes, err := util.CryptoRandomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+	deviceCode := "gtd_" + base32Lower.EncodeToString(rBytes)
+	d := &OAuth2DeviceAuthorization{
+		ApplicationID:  app.ID,
+		DeviceCode:     deviceCode,
+		DeviceCodeHash: hashDeviceCode(deviceCode),
+		UserCode:       userCode,
+		Scope:          scope,
+		Interval:       setting.OAuth2.DeviceCodePollingInterval,
+		ValidUntil:     timeutil.TimeStampNow().Add(setting.OAuth2.DeviceCodeExpirationTime),
+	}
+	if err := db.Insert(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetOAuth2DeviceAuthorizationByUserCode returns the authorization of the user code, it returns nil if it doesn't exist
+func GetOAuth2DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*OAuth2DeviceAuthorization, error) {
+	d := new(OAuth2DeviceAuthorization)
+	if has, err := db.GetEngine(ctx).Where("user_code = ?", NormalizeUserCode(userCode)).Get(d); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// GetOAuth2DeviceAuthorizationByDeviceCode returns the authorization of the device code, it returns nil if it doesn't exist
+func GetOAuth2DeviceAuthorizationByDeviceCode(ctx context.Context, deviceCode string) (*OAuth2DeviceAuthorization, error) {
+	d := new(OAuth2DeviceAuthorization)
+	if has, err := db.GetEngine(ctx).Where("device_code_hash = ?", hashDeviceCode(deviceCode)).Get(d); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// Approve approves the pending authorization with the grant of the user, the device gets the tokens of the grant at its next poll.
+// It returns ErrOAuth2DeviceAuthorizationNotPending if the authorization has been approved or denied in the meantime.
+func (d *OAuth2DeviceAuthorization) Approve(ctx context.Context, grantID int64) error {
+	affected, err := db.GetEngine(ctx).ID(d.ID).Where(builder.Eq{"grant_id": 0, "denied": false}).Cols("grant_id").Update(&OAuth2DeviceAuthorization{GrantID: grantID})
+	if err != nil {
+		return err
+	} else if affected != 1 {
+		return ErrOAuth2DeviceAuthorizationNotPending
+	}
+	d.GrantID = grantID
+	return nil
+}
+
+// Deny denies the pending authorization, the device gets an access_denied error at its next poll.
+// It returns ErrOAuth2DeviceAuthorizationNotPending if the authorization has been approved or denied in the meantime.
+func (d *OAuth2DeviceAuthorization) Deny(ctx context.Context) error {
+	affected, err := db.GetEngine(ctx).ID(d.ID).Where(builder.Eq{"grant_id": 0, "denied": false}).Cols("denied").Update(&OAuth2DeviceAuthorization{Denied: true})
+	if err != nil {
+		return err
+	} else if affected != 1 {
+		return ErrOAuth2DeviceAuthorizationNotPending
+	}
+	d.Denied = true
+	return nil
+}
+
+// Poll records a poll of the device, it returns true if the device polled faster than the interval.
+// The interval is increased in that case and the device has to slow down.
+func (d *OAuth2DeviceAuthorization) Poll(ctx context.Context) (slowDown bool, err error) {
+	now := timeutil.TimeStampNow()
+	slowDown = d.LastPolledUnix != 0 && now < d.LastPolledUnix.Add(d.Interval)
+	if slowDown {
+		d.Interval += slowDownInterval
+	}
+	d.LastPolledUnix = now
+	_, err = db.GetEngine(ctx).ID(d.ID).Cols("interval", "last_polled_unix").Update(d)
+	return slowDown, err
+}
+
+// Invalidate deletes the authorization, the device code can't be used again
+func (d *OAuth2DeviceAuthorization) Invalidate(ctx context.Context) error {
+	_, err := db.GetEngine(ctx).ID(d.ID).NoAutoCondition().Delete(d)
+	return err
+}
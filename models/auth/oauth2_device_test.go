@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeUserCode(t *testing.T) {
+	assert.Equal(t, "BCDFGHJK", auth_model.NormalizeUserCode("bcdf-ghjk"))
+	assert.Equal(t, "BCDFGHJK", auth_model.NormalizeUserCode("BCDF GHJK"))
+}
+
+func TestOAuth2DeviceAuthorization(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	app := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Application{ID: 1})
+
+	d, err := auth_model.CreateOAuth2DeviceAuthorization(db.DefaultContext, app, "read:user")
+	require.NoError(t, err)
+	assert.NotEmpty(t, d.DeviceCode)
+	assert.Len(t, d.FormattedUserCode(), 9)
+	assert.Empty(t, strings.Trim(d.UserCode, "BCDFGHJKLMNPQRSTVWXZ"))
+	assert.True(t, d.IsPending())
+	assert.False(t, d.IsExpired())
+
+	byUserCode, err := auth_model.GetOAuth2DeviceAuthorizationByUserCode(db.DefaultContext, d.FormattedUserCode())
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, byUserCode.ID)
+	byDeviceCode, err := auth_model.GetOAuth2DeviceAuthorizationByDeviceCode(db.DefaultContext, d.DeviceCode)
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, byDeviceCode.ID)
+	notFound, err := auth_model.GetOAuth2DeviceAuthorizationByDeviceCode(db.DefaultContext, "invalid")
+	require.NoError(t, err)
+	assert.Nil(t, notFound)
+
+	// the first poll is never too fast, the second one right after it is
+	slowDown, err := byDeviceCode.Poll(db.DefaultContext)
+	require.NoError(t, err)
+	assert.False(t, slowDown)
+	interval := byDeviceCode.Interval
+	slowDown, err = byDeviceCode.Poll(db.DefaultContext)
+	require.NoError(t, err)
+	assert.True(t, slowDown)
+	assert.Equal(t, interval+5, byDeviceCode.Interval)
+
+	require.NoError(t, byDeviceCode.Approve(db.DefaultContext, 1))
+	d = unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2DeviceAuthorization{ID: d.ID})
+	assert.EqualValues(t, 1, d.GrantID)
+	assert.False(t, d.IsPending())
+
+	// an approved authorization can't be approved again or denied
+	assert.ErrorIs(t, byUserCode.Approve(db.DefaultContext, 2), auth_model.ErrOAuth2DeviceAuthorizationNotPending)
+	assert.ErrorIs(t, byUserCode.Deny(db.DefaultContext), auth_model.ErrOAuth2DeviceAuthorizationNotPending)
+	d = unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2DeviceAuthorization{ID: d.ID})
+	assert.EqualValues(t, 1, d.GrantID)
+	assert.False(t, d.Denied)
+
+	require.NoError(t, d.Invalidate(db.DefaultContext))
+	unittest.AssertNotExistsBean(t, &auth_model.OAuth2DeviceAuthorization{ID: d.ID})
+}
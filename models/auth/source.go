@@ -33,6 +33,7 @@ const (
 	DLDAP       // 5
 	OAuth2      // 6
 	SSPI        // 7
+	SAML        // 8
 )
 
 // String returns the string name of the LoginType
@@ -53,6 +54,7 @@ var Names = map[Type]string{
 	PAM:    "PAM",
 	OAuth2: "OAuth2",
 	SSPI:   "SPNEGO with SSPI",
+	SAML:   "SAML",
 }
 
 // Config represents login config as far as the db is concerned
@@ -181,6 +183,11 @@ func (source *Source) IsSSPI() bool {
 	return source.Type == SSPI
 }
 
+// IsSAML returns true of this source is of the SAML type.
+func (source *Source) IsSAML() bool {
+	return source.Type == SAML
+}
+
 // HasTLS returns true of this source supports TLS.
 func (source *Source) HasTLS() bool {
 	hasTLSer, ok := source.Cfg.(HasTLSer)
@@ -273,6 +280,30 @@ func IsSSPIEnabled(ctx context.Context) bool {
 	return exist
 }
 
+// GetActiveSAMLSourceByName returns an active SAML source by given name
+func GetActiveSAMLSourceByName(ctx context.Context, name string) (*Source, error) {
+	source := new(Source)
+	has, err := db.GetEngine(ctx).Where("name = ? and type = ? and is_active = ?", name, SAML, true).Get(source)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("saml source not found, name: %q", name)
+	}
+	return source, nil
+}
+
+// GetActiveSourceByName returns an active login source by given name
+func GetActiveSourceByName(ctx context.Context, name string) (*Source, error) {
+	source := new(Source)
+	has, err := db.GetEngine(ctx).Where("name = ? and is_active = ?", name, true).Get(source)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("active login source not found, name: %q: %w", name, util.ErrNotExist)
+	}
+	return source, nil
+}
+
 // GetSourceByID returns login source by given ID.
 func GetSourceByID(ctx context.Context, id int64) (*Source, error) {
 	source := new(Source)
@@ -296,8 +327,8 @@ func GetSourceByID(ctx context.Context, id int64) (*Source, error) {
 // UpdateSource updates a Source record in DB.
 func UpdateSource(ctx context.Context, source *Source) error {
 	var originalSource *Source
-	if source.IsOAuth2() {
-		// keep track of the original values so we can restore in case of errors while registering OAuth2 providers
+	if source.IsOAuth2() || source.IsSAML() {
+		// keep track of the original values so we can restore in case of errors while registering OAuth2 providers or SAML service providers
 		var err error
 		if originalSource, err = GetSourceByID(ctx, source.ID); err != nil {
 			return err
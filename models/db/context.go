@@ -29,10 +29,54 @@ var engineContextKey = engineContextKeyType{}
 type Context struct {
 	context.Context
 	engine Engine
+	// afterCommit holds the functions to run once the transaction of the context has been committed,
+	// it's shared by all contexts of the transaction
+	afterCommit *[]func()
 }
 
 func newContext(ctx context.Context, e Engine) *Context {
-	return &Context{Context: ctx, engine: e}
+	c := &Context{Context: ctx, engine: e}
+	if parent, ok := ctx.Value(engineContextKey).(*Context); ok {
+		c.afterCommit = parent.afterCommit
+	}
+	return c
+}
+
+// newTxContext returns the context of a new transaction
+func newTxContext(ctx context.Context, sess *xorm.Session) *Context {
+	c := newContext(ctx, sess)
+	c.afterCommit = new([]func())
+	return c
+}
+
+func runAfterCommit(c *Context) {
+	for _, f := range *c.afterCommit {
+		f()
+	}
+}
+
+// txCommitter commits the transaction and runs the functions registered by AfterTx
+type txCommitter struct {
+	*xorm.Session
+	ctx *Context
+}
+
+func (c *txCommitter) Commit() error {
+	if err := c.Session.Commit(); err != nil {
+		return err
+	}
+	runAfterCommit(c.ctx)
+	return nil
+}
+
+// AfterTx runs f once the transaction of the context has been committed, f is never run if the transaction is rolled back.
+// f is run immediately if the context has no transaction.
+func AfterTx(ctx context.Context, f func()) {
+	if c, ok := ctx.Value(engineContextKey).(*Context); ok && c.afterCommit != nil && InTransaction(ctx) {
+		*c.afterCommit = append(*c.afterCommit, f)
+		return
+	}
+	f()
 }
 
 // Value shadows Value for context.Context but allows us to get ourselves and an Engined object
@@ -161,7 +205,8 @@ func TxContext(parentCtx context.Context) (*Context, Committer, error) {
 		return nil, nil, err
 	}
 
-	return newContext(DefaultContext, sess), sess, nil
+	ctx := newTxContext(DefaultContext, sess)
+	return ctx, &txCommitter{Session: sess, ctx: ctx}, nil
 }
 
 // WithTx represents executing database operations on a transaction, if the transaction exist,
@@ -185,11 +230,16 @@ func txWithNoCheck(parentCtx context.Context, f func(ctx context.Context) error)
 		return err
 	}
 
-	if err := f(newContext(parentCtx, sess)); err != nil {
+	ctx := newTxContext(parentCtx, sess)
+	if err := f(ctx); err != nil {
 		return err
 	}
 
-	return sess.Commit()
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+	runAfterCommit(ctx)
+	return nil
 }
 
 // Insert inserts records into database
@@ -5,6 +5,7 @@ package db_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
@@ -85,6 +86,59 @@ func TestTxContext(t *testing.T) {
 	}
 }
 
+func TestAfterTx(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	{ // no transaction
+		called := false
+		db.AfterTx(db.DefaultContext, func() { called = true })
+		assert.True(t, called)
+	}
+
+	{ // committed by WithTx, including the reused transaction
+		called := 0
+		assert.NoError(t, db.WithTx(db.DefaultContext, func(ctx context.Context) error {
+			db.AfterTx(ctx, func() { called++ })
+			assert.NoError(t, db.WithTx(ctx, func(ctx context.Context) error {
+				db.AfterTx(ctx, func() { called++ })
+				return nil
+			}))
+			assert.Zero(t, called)
+			return nil
+		}))
+		assert.Equal(t, 2, called)
+	}
+
+	{ // rolled back by WithTx
+		called := false
+		assert.Error(t, db.WithTx(db.DefaultContext, func(ctx context.Context) error {
+			db.AfterTx(ctx, func() { called = true })
+			return errors.New("rollback")
+		}))
+		assert.False(t, called)
+	}
+
+	{ // committed by TxContext
+		called := false
+		ctx, committer, err := db.TxContext(db.DefaultContext)
+		assert.NoError(t, err)
+		db.AfterTx(ctx, func() { called = true })
+		assert.False(t, called)
+		assert.NoError(t, committer.Commit())
+		assert.NoError(t, committer.Close())
+		assert.True(t, called)
+	}
+
+	{ // closed by TxContext without commit
+		called := false
+		ctx, committer, err := db.TxContext(db.DefaultContext)
+		assert.NoError(t, err)
+		db.AfterTx(ctx, func() { called = true })
+		assert.NoError(t, committer.Close())
+		assert.False(t, called)
+	}
+}
+
 func TestContextSafety(t *testing.T) {
 	type TestModel1 struct {
 		ID int64
@@ -331,6 +331,15 @@ func GetRepoLFSSize(ctx context.Context, repoID int64) (int64, error) {
 	return lfsSize, nil
 }
 
+// GetOwnerLFSSize returns the size of the lfs files of all the repositories of the owner
+func GetOwnerLFSSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Table("lfs_meta_object").
+		Join("INNER", "repository", "repository.id = lfs_meta_object.repository_id").
+		Where("repository.owner_id = ?", ownerID).
+		SumInt(new(LFSMetaObject), "lfs_meta_object.size")
+}
+
 // IterateRepositoryIDsWithLFSMetaObjects iterates across the repositories that have LFSMetaObjects
 func IterateRepositoryIDsWithLFSMetaObjects(ctx context.Context, f func(ctx context.Context, repoID, count int64) error) error {
 	batchSize := setting.Database.IterateBufferSize
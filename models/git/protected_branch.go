@@ -65,6 +65,8 @@ type ProtectedBranch struct {
 	ProtectedFilePatterns         string   `xorm:"TEXT"`
 	UnprotectedFilePatterns       string   `xorm:"TEXT"`
 	BlockAdminMergeOverride       bool     `xorm:"NOT NULL DEFAULT false"`
+	EnableMergeQueue              bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireCodeOwnerApproval      bool     `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -442,6 +444,12 @@ func UpdateProtectBranch(ctx context.Context, repo *repo_model.Repository, prote
 	return nil
 }
 
+// UpdateProtectBranchStatusChecks saves the status check options of the protected branch rule
+func UpdateProtectBranchStatusChecks(ctx context.Context, protectBranch *ProtectedBranch) error {
+	_, err := db.GetEngine(ctx).ID(protectBranch.ID).Cols("enable_status_check", "status_check_contexts").Update(protectBranch)
+	return err
+}
+
 func UpdateProtectBranchPriorities(ctx context.Context, repo *repo_model.Repository, ids []int64) error {
 	prio := int64(1)
 	return db.WithTx(ctx, func(ctx context.Context) error {
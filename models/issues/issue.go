@@ -938,6 +938,16 @@ func insertIssue(ctx context.Context, issue *Issue) error {
 		}
 	}
 
+	for _, attachment := range issue.Attachments {
+		attachment.IssueID = issue.ID
+	}
+
+	if len(issue.Attachments) > 0 {
+		if _, err := sess.NoAutoTime().Insert(issue.Attachments); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -893,6 +893,18 @@ func GetGrantedApprovalsCount(ctx context.Context, protectBranch *git_model.Prot
 	return approvals
 }
 
+// GetApprovalReviewerIDs returns the ids of the users who approved the pull request, whether their approval is official or not
+func GetApprovalReviewerIDs(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) ([]int64, error) {
+	sess := db.GetEngine(ctx).Where("issue_id = ?", pr.IssueID).
+		And("type = ?", ReviewTypeApprove).
+		And("dismissed = ?", false)
+	if protectBranch.IgnoreStaleApprovals {
+		sess = sess.And("stale = ?", false)
+	}
+	reviewerIDs := make([]int64, 0, 2)
+	return reviewerIDs, sess.Table("review").Distinct("reviewer_id").Find(&reviewerIDs)
+}
+
 // MergeBlockedByRejectedReview returns true if merge is blocked by rejected reviews
 func MergeBlockedByRejectedReview(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) bool {
 	if !protectBranch.BlockOnRejectedReviews {
@@ -971,6 +983,7 @@ func GetCodeOwnersFromContent(ctx context.Context, data string) ([]*CodeOwnerRul
 }
 
 type CodeOwnerRule struct {
+	Pattern  string // the pattern as written in the CODEOWNERS file
 	Rule     *regexp.Regexp
 	Negative bool
 	Users    []*user_model.User
@@ -980,6 +993,7 @@ type CodeOwnerRule struct {
 func ParseCodeOwnersLine(ctx context.Context, tokens []string) (*CodeOwnerRule, []string) {
 	var err error
 	rule := &CodeOwnerRule{
+		Pattern:  tokens[0],
 		Users:    make([]*user_model.User, 0),
 		Teams:    make([]*org_model.Team, 0),
 		Negative: strings.HasPrefix(tokens[0], "!"),
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
@@ -321,6 +322,30 @@ func TestGetApprovers(t *testing.T) {
 	assert.EqualValues(t, expected, approvers)
 }
 
+func TestGetApprovalReviewerIDs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 5})
+	pb := &git_model.ProtectedBranch{}
+
+	// the approvals aren't official
+	reviewerIDs, err := issues_model.GetApprovalReviewerIDs(db.DefaultContext, pb, pr)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{5, 6}, reviewerIDs)
+
+	_, err = db.GetEngine(db.DefaultContext).In("id", 13, 14).Cols("stale").Update(&issues_model.Review{Stale: true})
+	assert.NoError(t, err)
+	pb.IgnoreStaleApprovals = true
+	reviewerIDs, err = issues_model.GetApprovalReviewerIDs(db.DefaultContext, pb, pr)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6}, reviewerIDs)
+
+	_, err = db.GetEngine(db.DefaultContext).ID(15).Cols("dismissed").Update(&issues_model.Review{Dismissed: true})
+	assert.NoError(t, err)
+	reviewerIDs, err = issues_model.GetApprovalReviewerIDs(db.DefaultContext, pb, pr)
+	assert.NoError(t, err)
+	assert.Empty(t, reviewerIDs)
+}
+
 func TestGetPullRequestByMergedCommit(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 	pr, err := issues_model.GetPullRequestByMergedCommit(db.DefaultContext, 1, "1a8823cd1a9549fde083f992f6b9b87a7ab74fb3")
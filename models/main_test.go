@@ -13,6 +13,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 
 	_ "code.gitea.io/gitea/models/actions"
+	_ "code.gitea.io/gitea/models/audit"
 	_ "code.gitea.io/gitea/models/system"
 
 	"github.com/stretchr/testify/assert"
@@ -369,6 +369,23 @@ func prepareMigrationTasks() []*migration {
 		newMigration(309, "Improve Notification table indices", v1_23.ImproveNotificationTableIndices),
 		newMigration(310, "Add Priority to ProtectedBranch", v1_23.AddPriorityToProtectedBranch),
 		newMigration(311, "Add TimeEstimate to Issue table", v1_23.AddTimeEstimateColumnToIssueTable),
+		newMigration(312, "Add concurrency to action run and job", v1_23.AddConcurrencyToActionRunAndJob),
+		newMigration(313, "Add merge queue", v1_23.AddMergeQueue),
+		newMigration(314, "Add require code owner approval to protected branch", v1_23.AddRequireCodeOwnerApprovalToProtectedBranch),
+		newMigration(315, "Add action cache table", v1_23.AddActionCacheTable),
+		newMigration(316, "Add action environment and deployment tables", v1_23.AddActionEnvironmentAndDeploymentTables),
+		newMigration(317, "Add quota tables", v1_23.AddQuotaTables),
+		newMigration(318, "Add workflow call to action run job", v1_23.AddWorkflowCallToActionRunJob),
+		newMigration(319, "Add action required workflow table", v1_23.AddActionRequiredWorkflowTable),
+		newMigration(320, "Add permissions to action run job", v1_23.AddPermissionsToActionRunJob),
+		newMigration(321, "Add permissions to action task", v1_23.AddPermissionsToActionTask),
+		newMigration(322, "Add action task annotation and summary tables", v1_23.AddActionTaskAnnotationAndSummaryTables),
+		newMigration(323, "Add audit event table", v1_23.AddAuditEventTable),
+		newMigration(324, "Add oauth2 device authorization table", v1_23.AddOAuth2DeviceAuthorization),
+		newMigration(325, "Add package remote tables", v1_23.AddPackageRemoteTables),
+		newMigration(326, "Add partial clone settings to repository", v1_23.AddPartialCloneSettingsToRepository),
+		newMigration(327, "Add repository housekeeping table", v1_23.AddRepoHousekeepingTable),
+		newMigration(328, "Add group updated time to merge queue", v1_23.AddGroupUpdatedToMergeQueue),
 	}
 	return preparedMigrations
 }
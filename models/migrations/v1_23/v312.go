@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddConcurrencyToActionRunAndJob(x *xorm.Engine) error {
+	type ActionRun struct {
+		ConcurrencyGroup  string `xorm:"index"`
+		ConcurrencyCancel bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	type ActionRunJob struct {
+		ConcurrencyGroup  string `xorm:"index"`
+		ConcurrencyCancel bool   `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	return x.Sync(new(ActionRun), new(ActionRunJob))
+}
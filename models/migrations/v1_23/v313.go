@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type MergeQueueEntry struct {
+	ID            int64              `xorm:"pk autoincr"`
+	RepoID        int64              `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch    string             `xorm:"INDEX(s) NOT NULL"`
+	PullID        int64              `xorm:"UNIQUE"`
+	DoerID        int64              `xorm:"INDEX NOT NULL"`
+	MergeStyle    string             `xorm:"varchar(30)"`
+	Message       string             `xorm:"LONGTEXT"`
+	HeadCommitID  string             `xorm:"VARCHAR(64)"`
+	BaseCommitID  string             `xorm:"VARCHAR(64)"`
+	GroupCommitID string             `xorm:"VARCHAR(64) INDEX"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+}
+
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func AddMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync(new(ProtectedBranch)); err != nil {
+		return err
+	}
+	return x.Sync(new(MergeQueueEntry))
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionEnvironmentAndDeploymentTables(x *xorm.Engine) error {
+	type ActionEnvironment struct {
+		ID                int64  `xorm:"pk autoincr"`
+		RepoID            int64  `xorm:"UNIQUE(repo_name)"`
+		Name              string `xorm:"VARCHAR(255) UNIQUE(repo_name) NOT NULL"`
+		WaitTimer         int64
+		BranchFilters     []string           `xorm:"JSON TEXT"`
+		RequiredReviewers []int64            `xorm:"JSON TEXT"`
+		CreatedUnix       timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix       timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionDeployment struct {
+		ID            int64  `xorm:"pk autoincr"`
+		RepoID        int64  `xorm:"index"`
+		RunID         int64  `xorm:"index"`
+		JobID         int64  `xorm:"index"`
+		EnvironmentID int64  `xorm:"index"`
+		Ref           string `xorm:"VARCHAR(255)"`
+		CommitSHA     string `xorm:"VARCHAR(64)"`
+		CreatorID     int64
+		Status        int `xorm:"index"`
+		ApprovedUnix  timeutil.TimeStamp
+		WaitUntil     timeutil.TimeStamp `xorm:"index"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionDeploymentReview struct {
+		ID           int64 `xorm:"pk autoincr"`
+		RepoID       int64 `xorm:"index"`
+		DeploymentID int64 `xorm:"index"`
+		ReviewerID   int64 `xorm:"index"`
+		Approved     bool
+		Comment      string             `xorm:"TEXT"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ActionRunJob struct {
+		EnvironmentID int64 `xorm:"index NOT NULL DEFAULT 0"`
+	}
+
+	// the environment is a part of the unique key of secrets and variables
+	type Secret struct {
+		ID            int64
+		OwnerID       int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL"`
+		RepoID        int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+		EnvironmentID int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+		Name          string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+		Data          string             `xorm:"LONGTEXT"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+	}
+
+	type ActionVariable struct {
+		ID            int64              `xorm:"pk autoincr"`
+		OwnerID       int64              `xorm:"UNIQUE(owner_repo_name)"`
+		RepoID        int64              `xorm:"INDEX UNIQUE(owner_repo_name)"`
+		EnvironmentID int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+		Name          string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+		Data          string             `xorm:"LONGTEXT NOT NULL"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(ActionEnvironment), new(ActionDeployment), new(ActionDeploymentReview), new(ActionRunJob), new(Secret), new(ActionVariable))
+}
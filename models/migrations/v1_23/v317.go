@@ -0,0 +1,36 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddQuotaTables(x *xorm.Engine) error {
+	type QuotaRule struct {
+		ID       int64    `xorm:"pk autoincr"`
+		Name     string   `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+		Limit    int64    `xorm:"NOT NULL DEFAULT -1"`
+		Subjects []string `xorm:"JSON TEXT"`
+	}
+
+	type QuotaGroup struct {
+		ID   int64  `xorm:"pk autoincr"`
+		Name string `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	}
+
+	type QuotaGroupRule struct {
+		ID      int64 `xorm:"pk autoincr"`
+		GroupID int64 `xorm:"UNIQUE(group_rule)"`
+		RuleID  int64 `xorm:"UNIQUE(group_rule) INDEX"`
+	}
+
+	type QuotaGroupUser struct {
+		ID      int64 `xorm:"pk autoincr"`
+		GroupID int64 `xorm:"UNIQUE(group_user)"`
+		UserID  int64 `xorm:"UNIQUE(group_user) INDEX"`
+	}
+
+	return x.Sync(new(QuotaRule), new(QuotaGroup), new(QuotaGroupRule), new(QuotaGroupUser))
+}
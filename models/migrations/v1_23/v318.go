@@ -0,0 +1,16 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddWorkflowCallToActionRunJob(x *xorm.Engine) error {
+	type ActionRunJob struct {
+		CallPath   string `xorm:"VARCHAR(255)"`
+		CallerPath string `xorm:"VARCHAR(255)"`
+	}
+	return x.Sync(new(ActionRunJob))
+}
@@ -0,0 +1,29 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionRequiredWorkflowTable(x *xorm.Engine) error {
+	type ActionRequiredWorkflow struct {
+		ID           int64              `xorm:"pk autoincr"`
+		OwnerID      int64              `xorm:"index UNIQUE(repo_path)"`
+		RepoID       int64              `xorm:"UNIQUE(repo_path)"`
+		WorkflowPath string             `xorm:"VARCHAR(255) UNIQUE(repo_path) NOT NULL"`
+		Name         string             `xorm:"VARCHAR(255) NOT NULL"`
+		RepoPatterns []string           `xorm:"JSON TEXT"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRun struct {
+		RequiredWorkflowID int64 `xorm:"index"`
+	}
+
+	return x.Sync(new(ActionRequiredWorkflow), new(ActionRun))
+}
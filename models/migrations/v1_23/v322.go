@@ -0,0 +1,42 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func AddActionTaskAnnotationAndSummaryTables(x *xorm.Engine) error {
+	type ActionTaskAnnotation struct {
+		ID          int64
+		TaskID      int64  `xorm:"index"`
+		RunID       int64  `xorm:"index"`
+		RepoID      int64  `xorm:"index(repo_commit)"`
+		CommitSHA   string `xorm:"VARCHAR(64) index(repo_commit)"`
+		LogIndex    int64
+		Level       string `xorm:"VARCHAR(16)"`
+		Title       string `xorm:"VARCHAR(255)"`
+		Message     string `xorm:"TEXT"`
+		Path        string `xorm:"VARCHAR(4000)"`
+		StartLine   int64
+		EndLine     int64
+		StartColumn int64
+		EndColumn   int64
+		Created     timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ActionTaskSummary struct {
+		ID        int64
+		TaskID    int64              `xorm:"index unique(task_step)"`
+		StepIndex int64              `xorm:"unique(task_step)"`
+		RepoID    int64              `xorm:"index"`
+		Content   string             `xorm:"LONGTEXT"`
+		Created   timeutil.TimeStamp `xorm:"created"`
+		Updated   timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(ActionTaskAnnotation), new(ActionTaskSummary))
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type auditEvent struct {
+	ID          int64
+	Action      string `xorm:"VARCHAR(64) INDEX NOT NULL"`
+	ActorID     int64  `xorm:"INDEX"`
+	ActorName   string `xorm:"VARCHAR(255)"`
+	IPAddress   string `xorm:"VARCHAR(64)"`
+	OwnerID     int64  `xorm:"INDEX"`
+	RepoID      int64  `xorm:"INDEX"`
+	TargetType  string `xorm:"VARCHAR(32)"`
+	TargetID    int64
+	TargetName  string             `xorm:"VARCHAR(255)"`
+	Before      string             `xorm:"TEXT"`
+	After       string             `xorm:"TEXT"`
+	PrevHash    string             `xorm:"VARCHAR(64)"`
+	Hash        string             `xorm:"VARCHAR(64) UNIQUE"`
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+}
+
+func (auditEvent) TableName() string {
+	return "audit_event"
+}
+
+func AddAuditEventTable(x *xorm.Engine) error {
+	return x.Sync(new(auditEvent))
+}
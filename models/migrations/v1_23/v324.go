@@ -0,0 +1,36 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type oauth2DeviceAuthorization struct {
+	ID             int64  `xorm:"pk autoincr"`
+	ApplicationID  int64  `xorm:"INDEX"`
+	DeviceCodeHash string `xorm:"UNIQUE"`
+	UserCode       string `xorm:"VARCHAR(16) UNIQUE"`
+	Scope          string `xorm:"TEXT"`
+	GrantID        int64
+	Denied         bool `xorm:"NOT NULL DEFAULT FALSE"`
+	Interval       int64
+	LastPolledUnix timeutil.TimeStamp
+	ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func (oauth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorization"
+}
+
+// AddOAuth2DeviceAuthorization adds the device flow option of the oauth2 applications and the table of the device authorizations
+func AddOAuth2DeviceAuthorization(x *xorm.Engine) error {
+	type oauth2Application struct {
+		EnableDeviceFlow bool `xorm:"NOT NULL DEFAULT FALSE"`
+	}
+	return x.Sync(new(oauth2Application), new(oauth2DeviceAuthorization))
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// AddPackageRemoteTables adds the tables of the remote package registries and their cached metadata
+func AddPackageRemoteTables(x *xorm.Engine) error {
+	type PackageRemote struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Type        string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		URL         string             `xorm:"TEXT NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	type PackageRemoteMetadata struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RemoteID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		MetadataKey string             `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+		Content     string             `xorm:"LONGTEXT"`
+		FetchedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(PackageRemote), new(PackageRemoteMetadata))
+}
@@ -0,0 +1,18 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+// AddPartialCloneSettingsToRepository adds the columns to restrict partial clones of a repository
+func AddPartialCloneSettingsToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		DisablePartialClone bool     `xorm:"NOT NULL DEFAULT false"`
+		PartialCloneFilters []string `xorm:"TEXT JSON"`
+	}
+
+	return x.Sync(new(Repository))
+}
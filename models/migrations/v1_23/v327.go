@@ -0,0 +1,39 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// AddRepoHousekeepingTable adds the table to track the housekeeping of repositories
+func AddRepoHousekeepingTable(x *xorm.Engine) error {
+	type RepoHousekeeping struct {
+		ID                    int64 `xorm:"pk autoincr"`
+		RepoID                int64 `xorm:"UNIQUE NOT NULL"`
+		Status                int   `xorm:"INDEX NOT NULL DEFAULT 0"`
+		PushesSinceCheck      int64 `xorm:"NOT NULL DEFAULT 0"`
+		PushesSinceFullRepack int64 `xorm:"NOT NULL DEFAULT 0"`
+
+		LooseObjects      int64 `xorm:"NOT NULL DEFAULT 0"`
+		LooseObjectsSize  int64 `xorm:"NOT NULL DEFAULT 0"`
+		Packs             int64 `xorm:"NOT NULL DEFAULT 0"`
+		PacksSize         int64 `xorm:"NOT NULL DEFAULT 0"`
+		LooseRefs         int64 `xorm:"NOT NULL DEFAULT 0"`
+		HasCommitGraph    bool  `xorm:"NOT NULL DEFAULT false"`
+		HasMultiPackIndex bool  `xorm:"NOT NULL DEFAULT false"`
+		HasBitmap         bool  `xorm:"NOT NULL DEFAULT false"`
+
+		LastTasks          string
+		LastError          string             `xorm:"TEXT"`
+		LastStartedUnix    timeutil.TimeStamp `xorm:"INDEX"`
+		LastFinishedUnix   timeutil.TimeStamp
+		LastFullRepackUnix timeutil.TimeStamp
+		UpdatedUnix        timeutil.TimeStamp `xorm:"INDEX UPDATED"`
+	}
+
+	return x.Sync(new(RepoHousekeeping))
+}
@@ -0,0 +1,23 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_23 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// AddGroupUpdatedToMergeQueue adds the time when the merge group of a merge queue entry has been built,
+// the existing merge groups are considered to be built when the pull requests were queued
+func AddGroupUpdatedToMergeQueue(x *xorm.Engine) error {
+	type PullMergeQueue struct {
+		GroupUpdatedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+	if err := x.Sync(new(PullMergeQueue)); err != nil {
+		return err
+	}
+	_, err := x.Exec("UPDATE `pull_merge_queue` SET group_updated_unix = created_unix")
+	return err
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package packages
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var (
+	ErrPackageRemoteNotExist         = util.NewNotExistErrorf("package remote does not exist")
+	ErrPackageRemoteMetadataNotExist = util.NewNotExistErrorf("package remote metadata does not exist")
+)
+
+func init() {
+	db.RegisterModel(new(PackageRemote))
+	db.RegisterModel(new(PackageRemoteMetadata))
+}
+
+// RemoteTypes are the package types which can be proxied from a remote registry
+var RemoteTypes = []Type{
+	TypeContainer,
+	TypeMaven,
+	TypeNpm,
+	TypePyPI,
+}
+
+// IsRemoteType returns whether packages of the type can be proxied from a remote registry
+func IsRemoteType(t Type) bool {
+	for _, rt := range RemoteTypes {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageRemote represents an upstream registry of an owner.
+// The packages which don't exist in the registry of the owner are fetched from the upstream registry and cached.
+type PackageRemote struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Type        Type               `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	URL         string             `xorm:"TEXT NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// PackageRemoteMetadata represents metadata fetched from a remote registry, e.g. the versions of a package.
+// It's fetched again once it's older than the metadata TTL, but it's still used if the remote registry is unavailable.
+type PackageRemoteMetadata struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RemoteID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	MetadataKey string             `xorm:"VARCHAR(255) UNIQUE(s) NOT NULL"`
+	Content     string             `xorm:"LONGTEXT"`
+	FetchedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+}
+
+// MaxRemoteMetadataKeyLength is the maximum length of the key of remote metadata, longer keys are not cached
+const MaxRemoteMetadataKeyLength = 255
+
+func InsertRemote(ctx context.Context, pr *PackageRemote) (*PackageRemote, error) {
+	return pr, db.Insert(ctx, pr)
+}
+
+func GetRemoteByID(ctx context.Context, id int64) (*PackageRemote, error) {
+	pr := &PackageRemote{}
+
+	has, err := db.GetEngine(ctx).ID(id).Get(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteNotExist
+	}
+	return pr, nil
+}
+
+// GetRemoteByOwnerAndType returns the remote registry the packages of the type are proxied from
+func GetRemoteByOwnerAndType(ctx context.Context, ownerID int64, packageType Type) (*PackageRemote, error) {
+	pr := &PackageRemote{}
+
+	has, err := db.GetEngine(ctx).Where("owner_id = ? AND type = ?", ownerID, packageType).Get(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteNotExist
+	}
+	return pr, nil
+}
+
+func GetRemotesByOwner(ctx context.Context, ownerID int64) ([]*PackageRemote, error) {
+	prs := make([]*PackageRemote, 0, len(RemoteTypes))
+	return prs, db.GetEngine(ctx).Where("owner_id = ?", ownerID).OrderBy("type").Find(&prs)
+}
+
+func HasOwnerRemoteForPackageType(ctx context.Context, ownerID int64, packageType Type) (bool, error) {
+	return db.GetEngine(ctx).
+		Where("owner_id = ? AND type = ?", ownerID, packageType).
+		Exist(&PackageRemote{})
+}
+
+// DeleteRemoteByID deletes the remote registry and its cached metadata, the cached packages are kept
+func DeleteRemoteByID(ctx context.Context, remoteID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("remote_id = ?", remoteID).Delete(&PackageRemoteMetadata{}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(remoteID).Delete(&PackageRemote{})
+		return err
+	})
+}
+
+func GetRemoteMetadata(ctx context.Context, remoteID int64, key string) (*PackageRemoteMetadata, error) {
+	prm := &PackageRemoteMetadata{}
+
+	has, err := db.GetEngine(ctx).Where("remote_id = ? AND metadata_key = ?", remoteID, key).Get(prm)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteMetadataNotExist
+	}
+	return prm, nil
+}
+
+// SetRemoteMetadata stores the metadata fetched from the remote registry now
+func SetRemoteMetadata(ctx context.Context, remoteID int64, key, content string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		prm, err := GetRemoteMetadata(ctx, remoteID, key)
+		if err != nil && err != ErrPackageRemoteMetadataNotExist {
+			return err
+		}
+		if prm == nil {
+			return db.Insert(ctx, &PackageRemoteMetadata{
+				RemoteID:    remoteID,
+				MetadataKey: key,
+				Content:     content,
+				FetchedUnix: timeutil.TimeStampNow(),
+			})
+		}
+		prm.Content = content
+		prm.FetchedUnix = timeutil.TimeStampNow()
+		_, err = db.GetEngine(ctx).ID(prm.ID).Cols("content", "fetched_unix").Update(prm)
+		return err
+	})
+}
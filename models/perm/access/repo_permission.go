@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"slices"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	perm_model "code.gitea.io/gitea/models/perm"
@@ -189,6 +190,43 @@ func applyEveryoneRepoPermission(user *user_model.User, perm *Permission) {
 	}
 }
 
+// GetActionsUserRepoPermission returns the permissions of the token of the Actions task to the repository,
+// the token could only access the repository of the task with the permissions granted to the job.
+func GetActionsUserRepoPermission(ctx context.Context, repo *repo_model.Repository, actionsUser *user_model.User, taskID int64) (perm Permission, err error) {
+	if !actionsUser.IsActions() {
+		return perm, fmt.Errorf("%s is not an Actions user", actionsUser.Name)
+	}
+
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return perm, err
+	}
+	if task.RepoID != repo.ID {
+		return perm, nil
+	}
+
+	p := task.Permissions
+	if !p.IsSet() {
+		// the task was created before its permissions were stored, it keeps the full access
+		p = actions_model.NewJobPermissions(actions_model.PermissionWrite)
+		if task.IsForkPullRequest {
+			p = actions_model.NewJobPermissions(actions_model.PermissionRead)
+		}
+	}
+
+	if err := repo.LoadUnits(ctx); err != nil {
+		return perm, err
+	}
+	perm.units = repo.Units
+	perm.unitsMode = make(map[unit.Type]perm_model.AccessMode, len(repo.Units))
+	for _, u := range repo.Units {
+		mode := p.UnitAccessMode(u.Type)
+		perm.unitsMode[u.Type] = mode
+		perm.AccessMode = max(perm.AccessMode, mode)
+	}
+	return perm, nil
+}
+
 // GetUserRepoPermission returns the user permissions to the repository
 func GetUserRepoPermission(ctx context.Context, repo *repo_model.Repository, user *user_model.User) (perm Permission, err error) {
 	defer func() {
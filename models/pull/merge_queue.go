@@ -0,0 +1,139 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch.
+// Every entry has a merge group commit which is the result of merging the pull request
+// onto the merge group commit of the previous entry (or the base branch for the first one).
+type MergeQueueEntry struct {
+	ID               int64                 `xorm:"pk autoincr"`
+	RepoID           int64                 `xorm:"INDEX(s) NOT NULL"`
+	BaseBranch       string                `xorm:"INDEX(s) NOT NULL"`
+	PullID           int64                 `xorm:"UNIQUE"`
+	DoerID           int64                 `xorm:"INDEX NOT NULL"`
+	Doer             *user_model.User      `xorm:"-"`
+	MergeStyle       repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message          string                `xorm:"LONGTEXT"`
+	HeadCommitID     string                `xorm:"VARCHAR(64)"`
+	BaseCommitID     string                `xorm:"VARCHAR(64)"` // the commit the merge group is based on
+	GroupCommitID    string                `xorm:"VARCHAR(64) INDEX"`
+	GroupUpdatedUnix timeutil.TimeStamp    `xorm:"NOT NULL DEFAULT 0"` // when the merge group has been built
+	CreatedUnix      timeutil.TimeStamp    `xorm:"created"`
+}
+
+// TableName return database table name for xorm
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func init() {
+	db.RegisterModel(new(MergeQueueEntry))
+}
+
+// LoadDoer loads the user who added the pull request to the merge queue
+func (e *MergeQueueEntry) LoadDoer(ctx context.Context) (err error) {
+	if e.Doer != nil {
+		return nil
+	}
+	e.Doer, err = user_model.GetPossibleUserByID(ctx, e.DoerID)
+	return err
+}
+
+// ErrAlreadyInMergeQueue represents a "AlreadyInMergeQueue"-error
+type ErrAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+func (err ErrAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// IsErrAlreadyInMergeQueue checks if an error is a ErrAlreadyInMergeQueue.
+func IsErrAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrAlreadyInMergeQueue)
+	return ok
+}
+
+// AddToMergeQueue appends a pull request to the end of the merge queue of the branch
+func AddToMergeQueue(ctx context.Context, entry *MergeQueueEntry) error {
+	if exists, _, err := GetMergeQueueEntryByPullID(ctx, entry.PullID); err != nil {
+		return err
+	} else if exists {
+		return ErrAlreadyInMergeQueue{PullID: entry.PullID}
+	}
+
+	_, err := db.GetEngine(ctx).Insert(entry)
+	return err
+}
+
+// GetMergeQueueEntryByPullID gets the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(ctx context.Context, pullID int64) (bool, *MergeQueueEntry, error) {
+	entry := &MergeQueueEntry{}
+	exists, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(entry)
+	if err != nil || !exists {
+		return false, nil, err
+	}
+	return true, entry, nil
+}
+
+// GetMergeQueueEntries returns the entries in the merge queue of the branch in queue order
+func GetMergeQueueEntries(ctx context.Context, repoID int64, branch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, branch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueuePosition returns the 1-based position of the entry in the merge queue of its branch
+func GetMergeQueuePosition(ctx context.Context, entry *MergeQueueEntry) (int64, error) {
+	return db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ? AND id <= ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(new(MergeQueueEntry))
+}
+
+// GetMergeQueueBranches returns one entry for every branch which has a non-empty merge queue,
+// only RepoID and BaseBranch of the entries are loaded
+func GetMergeQueueBranches(ctx context.Context) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 5)
+	return entries, db.GetEngine(ctx).Distinct("repo_id", "base_branch").Find(&entries)
+}
+
+// GetMergeQueueEntriesByGroupCommitID returns the entries whose merge group commit is the given commit
+func GetMergeQueueEntriesByGroupCommitID(ctx context.Context, repoID int64, commitID string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 1)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND group_commit_id = ?", repoID, commitID).
+		Find(&entries)
+}
+
+// UpdateMergeQueueEntryGroup stores the rebuilt merge group of an entry
+func UpdateMergeQueueEntryGroup(ctx context.Context, entry *MergeQueueEntry) error {
+	entry.GroupUpdatedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(entry.ID).Cols("base_commit_id", "group_commit_id", "group_updated_unix").Update(entry)
+	return err
+}
+
+// DeleteMergeQueueEntry removes a pull request from the merge queue
+func DeleteMergeQueueEntry(ctx context.Context, pullID int64) error {
+	exist, entry, err := GetMergeQueueEntryByPullID(ctx, pullID)
+	if err != nil {
+		return err
+	} else if !exist {
+		return db.ErrNotExist{Resource: "merge_queue", ID: pullID}
+	}
+
+	_, err = db.GetEngine(ctx).ID(entry.ID).Delete(&MergeQueueEntry{})
+	return err
+}
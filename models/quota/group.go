@@ -0,0 +1,237 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// Group is a set of rules applied to the users and organizations in it
+type Group struct {
+	ID    int64   `xorm:"pk autoincr"`
+	Name  string  `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	Rules []*Rule `xorm:"-"`
+}
+
+// TableName represents the real table name of Group
+func (Group) TableName() string {
+	return "quota_group"
+}
+
+// GroupRule maps a rule to a group
+type GroupRule struct {
+	ID      int64 `xorm:"pk autoincr"`
+	GroupID int64 `xorm:"UNIQUE(group_rule)"`
+	RuleID  int64 `xorm:"UNIQUE(group_rule) INDEX"`
+}
+
+// TableName represents the real table name of GroupRule
+func (GroupRule) TableName() string {
+	return "quota_group_rule"
+}
+
+// GroupUser maps a user or an organization to a group
+type GroupUser struct {
+	ID      int64 `xorm:"pk autoincr"`
+	GroupID int64 `xorm:"UNIQUE(group_user)"`
+	UserID  int64 `xorm:"UNIQUE(group_user) INDEX"`
+}
+
+// TableName represents the real table name of GroupUser
+func (GroupUser) TableName() string {
+	return "quota_group_user"
+}
+
+func init() {
+	db.RegisterModel(new(Group))
+	db.RegisterModel(new(GroupRule))
+	db.RegisterModel(new(GroupUser))
+}
+
+// LoadRules loads the rules of the group
+func (g *Group) LoadRules(ctx context.Context) error {
+	if g.Rules != nil {
+		return nil
+	}
+	g.Rules = make([]*Rule, 0, 5)
+	return db.GetEngine(ctx).
+		Join("INNER", "quota_group_rule", "quota_group_rule.rule_id = quota_rule.id").
+		Where("quota_group_rule.group_id = ?", g.ID).
+		OrderBy("quota_rule.name").
+		Find(&g.Rules)
+}
+
+// Acceptable returns whether more data of the subject could be stored without exceeding the rules of the group,
+// the second return value is false if no rule of the group limits the subject.
+func (g *Group) Acceptable(used *Used, subject LimitSubject) (acceptable, covered bool) {
+	acceptable = true
+	for _, rule := range g.Rules {
+		if !rule.Covers(subject) {
+			continue
+		}
+		covered = true
+		if !rule.Acceptable(used) {
+			acceptable = false
+		}
+	}
+	return acceptable, covered
+}
+
+// GroupList is a list of groups
+type GroupList []*Group
+
+// LoadRules loads the rules of the groups
+func (groups GroupList) LoadRules(ctx context.Context) error {
+	for _, g := range groups {
+		if err := g.LoadRules(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Acceptable returns whether more data of the subject could be stored.
+// Only the groups limiting the subject are considered, and the most permissive one wins:
+// being in a group with a larger quota grants the larger quota.
+func (groups GroupList) Acceptable(used *Used, subject LimitSubject) bool {
+	result := true
+	for _, g := range groups {
+		acceptable, covered := g.Acceptable(used, subject)
+		if !covered {
+			continue
+		}
+		if acceptable {
+			return true
+		}
+		result = false
+	}
+	return result
+}
+
+// GetGroupByName returns the group by name
+func GetGroupByName(ctx context.Context, name string) (*Group, error) {
+	var group Group
+	has, err := db.GetEngine(ctx).Where("name = ?", name).Get(&group)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("quota group %q: %w", name, util.ErrNotExist)
+	}
+	return &group, nil
+}
+
+// ListGroups returns all the groups ordered by name
+func ListGroups(ctx context.Context) (GroupList, error) {
+	groups := make(GroupList, 0, 10)
+	return groups, db.GetEngine(ctx).OrderBy("name").Find(&groups)
+}
+
+// CreateGroup creates a new group, the name must be unique
+func CreateGroup(ctx context.Context, group *Group) error {
+	exist, err := db.GetEngine(ctx).Exist(&Group{Name: group.Name})
+	if err != nil {
+		return err
+	} else if exist {
+		return fmt.Errorf("quota group %q: %w", group.Name, util.ErrAlreadyExist)
+	}
+	return db.Insert(ctx, group)
+}
+
+// DeleteGroup deletes the group with its mappings, the rules are kept
+func DeleteGroup(ctx context.Context, group *Group) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if _, err := e.Where("group_id = ?", group.ID).Delete(&GroupRule{}); err != nil {
+			return err
+		}
+		if _, err := e.Where("group_id = ?", group.ID).Delete(&GroupUser{}); err != nil {
+			return err
+		}
+		_, err := e.ID(group.ID).Delete(&Group{})
+		return err
+	})
+}
+
+// AddRuleToGroup adds the rule to the group, it does nothing if the rule is in the group already
+func AddRuleToGroup(ctx context.Context, group *Group, rule *Rule) error {
+	mapping := &GroupRule{GroupID: group.ID, RuleID: rule.ID}
+	exist, err := db.GetEngine(ctx).Exist(mapping)
+	if err != nil || exist {
+		return err
+	}
+	return db.Insert(ctx, mapping)
+}
+
+// RemoveRuleFromGroup removes the rule from the group
+func RemoveRuleFromGroup(ctx context.Context, group *Group, rule *Rule) error {
+	n, err := db.GetEngine(ctx).Delete(&GroupRule{GroupID: group.ID, RuleID: rule.ID})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("quota rule %q of group %q: %w", rule.Name, group.Name, util.ErrNotExist)
+	}
+	return nil
+}
+
+// AddUserToGroup adds the user or organization to the group, it does nothing if it's in the group already
+func AddUserToGroup(ctx context.Context, group *Group, userID int64) error {
+	mapping := &GroupUser{GroupID: group.ID, UserID: userID}
+	exist, err := db.GetEngine(ctx).Exist(mapping)
+	if err != nil || exist {
+		return err
+	}
+	return db.Insert(ctx, mapping)
+}
+
+// RemoveUserFromGroup removes the user or organization from the group
+func RemoveUserFromGroup(ctx context.Context, group *Group, userID int64) error {
+	n, err := db.GetEngine(ctx).Delete(&GroupUser{GroupID: group.ID, UserID: userID})
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("user %d of quota group %q: %w", userID, group.Name, util.ErrNotExist)
+	}
+	return nil
+}
+
+// ListUsersInGroup returns the users and organizations in the group
+func ListUsersInGroup(ctx context.Context, group *Group) ([]*user_model.User, error) {
+	users := make([]*user_model.User, 0, 10)
+	return users, db.GetEngine(ctx).
+		Where(builder.In("id", builder.Select("user_id").From("quota_group_user").Where(builder.Eq{"group_id": group.ID}))).
+		OrderBy("lower_name").
+		Find(&users)
+}
+
+// GetGroupsOfUser returns the groups applied to the user or organization with their rules,
+// the default groups of the settings are applied if it isn't in any group.
+func GetGroupsOfUser(ctx context.Context, userID int64) (GroupList, error) {
+	groups := make(GroupList, 0, 5)
+	if err := db.GetEngine(ctx).
+		Where(builder.In("id", builder.Select("group_id").From("quota_group_user").Where(builder.Eq{"user_id": userID}))).
+		OrderBy("name").
+		Find(&groups); err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 && len(setting.Quota.DefaultGroups) > 0 {
+		if err := db.GetEngine(ctx).In("name", setting.Quota.DefaultGroups).OrderBy("name").Find(&groups); err != nil {
+			return nil, err
+		}
+	}
+	return groups, groups.LoadRules(ctx)
+}
+
+// RemoveUserFromAllGroups removes the deleted user or organization from all the groups
+func RemoveUserFromAllGroups(ctx context.Context, userID int64) error {
+	_, err := db.GetEngine(ctx).Where("user_id = ?", userID).Delete(&GroupUser{})
+	return err
+}
@@ -0,0 +1,36 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package quota limits the storage used by users and organizations.
+//
+// Rules limit the total size of some subjects, they are bundled into groups,
+// and the groups are applied to users and organizations.
+package quota
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// EvaluateForUser returns whether the user or organization could store more data of the subject,
+// it's always true if the quota is disabled.
+func EvaluateForUser(ctx context.Context, userID int64, subject LimitSubject) (bool, error) {
+	if !setting.Quota.Enabled {
+		return true, nil
+	}
+
+	groups, err := GetGroupsOfUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(groups) == 0 {
+		return true, nil
+	}
+
+	used, err := GetUsedForUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return groups.Acceptable(used, subject), nil
+}
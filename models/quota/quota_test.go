@@ -0,0 +1,126 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package quota
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRule(t *testing.T) {
+	used := &Used{Git: 100, LFS: 200, Packages: 400}
+
+	rule := &Rule{Limit: 300, Subjects: []LimitSubject{LimitSubjectSizeGit, LimitSubjectSizeLFS}}
+	assert.True(t, rule.Covers(LimitSubjectSizeGit))
+	assert.False(t, rule.Covers(LimitSubjectSizePackages))
+	assert.EqualValues(t, 300, rule.Sum(used))
+	assert.False(t, rule.Acceptable(used))
+
+	rule = &Rule{Limit: 1000, Subjects: []LimitSubject{LimitSubjectSizeAll}}
+	assert.True(t, rule.Covers(LimitSubjectSizeAttachments))
+	assert.EqualValues(t, 700, rule.Sum(used))
+	assert.True(t, rule.Acceptable(used))
+
+	rule.Limit = -1
+	assert.True(t, rule.Acceptable(&Used{Git: 1 << 40}))
+
+	assert.NoError(t, ValidateSubjects([]LimitSubject{LimitSubjectSizeAll}))
+	assert.ErrorIs(t, ValidateSubjects(nil), util.ErrInvalidArgument)
+	assert.ErrorIs(t, ValidateSubjects([]LimitSubject{"size:unknown"}), util.ErrInvalidArgument)
+}
+
+func TestGroupList_Acceptable(t *testing.T) {
+	used := &Used{Git: 100, Packages: 100}
+	small := &Group{Rules: []*Rule{{Limit: 50, Subjects: []LimitSubject{LimitSubjectSizeGit}}}}
+	large := &Group{Rules: []*Rule{{Limit: 500, Subjects: []LimitSubject{LimitSubjectSizeAll}}}}
+
+	assert.False(t, GroupList{small}.Acceptable(used, LimitSubjectSizeGit))
+	// the groups not limiting the subject are ignored
+	assert.True(t, GroupList{small}.Acceptable(used, LimitSubjectSizePackages))
+	// the most permissive group wins
+	assert.True(t, GroupList{small, large}.Acceptable(used, LimitSubjectSizeGit))
+	assert.True(t, GroupList{}.Acceptable(used, LimitSubjectSizeGit))
+}
+
+func TestEvaluateForUser(t *testing.T) {
+	require.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	require.NoError(t, repo_model.UpdateRepoSize(ctx, repo.ID, 1000, 0))
+	used, err := GetUsedForUser(ctx, repo.OwnerID)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1000, used.Git)
+
+	rule := &Rule{Name: "git", Limit: 1000, Subjects: []LimitSubject{LimitSubjectSizeGit}}
+	require.NoError(t, CreateRule(ctx, rule))
+	assert.ErrorIs(t, CreateRule(ctx, &Rule{Name: "git", Subjects: []LimitSubject{LimitSubjectSizeGit}}), util.ErrAlreadyExist)
+	group := &Group{Name: "default"}
+	require.NoError(t, CreateGroup(ctx, group))
+	require.NoError(t, AddRuleToGroup(ctx, group, rule))
+	require.NoError(t, AddRuleToGroup(ctx, group, rule))
+
+	// the quota is disabled by default
+	acceptable, err := EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.True(t, acceptable)
+
+	defer test.MockVariableValue(&setting.Quota.Enabled, true)()
+
+	// the user isn't in any group
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.True(t, acceptable)
+
+	defer test.MockVariableValue(&setting.Quota.DefaultGroups, []string{"default"})()
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.False(t, acceptable)
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeLFS)
+	require.NoError(t, err)
+	assert.True(t, acceptable)
+
+	// the groups of the user replace the default groups
+	large := &Group{Name: "large"}
+	require.NoError(t, CreateGroup(ctx, large))
+	require.NoError(t, AddUserToGroup(ctx, large, repo.OwnerID))
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.True(t, acceptable)
+
+	require.NoError(t, AddUserToGroup(ctx, group, repo.OwnerID))
+	groups, err := GetGroupsOfUser(ctx, repo.OwnerID)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.False(t, acceptable)
+
+	rule.Limit = 2000
+	require.NoError(t, UpdateRule(ctx, rule))
+	acceptable, err = EvaluateForUser(ctx, repo.OwnerID, LimitSubjectSizeGit)
+	require.NoError(t, err)
+	assert.True(t, acceptable)
+
+	users, err := ListUsersInGroup(ctx, group)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, repo.OwnerID, users[0].ID)
+
+	require.NoError(t, DeleteRule(ctx, rule))
+	unittest.AssertNotExistsBean(t, &GroupRule{RuleID: rule.ID})
+	require.NoError(t, RemoveUserFromGroup(ctx, group, repo.OwnerID))
+	assert.ErrorIs(t, RemoveUserFromGroup(ctx, group, repo.OwnerID), util.ErrNotExist)
+	require.NoError(t, DeleteGroup(ctx, large))
+	unittest.AssertNotExistsBean(t, &GroupUser{GroupID: large.ID})
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// LimitSubject is the kind of storage a quota rule limits
+type LimitSubject string
+
+const (
+	LimitSubjectSizeAll         LimitSubject = "size:all"         // everything below
+	LimitSubjectSizeGit         LimitSubject = "size:git"         // git repositories
+	LimitSubjectSizeLFS         LimitSubject = "size:lfs"         // LFS objects
+	LimitSubjectSizePackages    LimitSubject = "size:packages"    // package files
+	LimitSubjectSizeArtifacts   LimitSubject = "size:artifacts"   // Actions artifacts
+	LimitSubjectSizeAttachments LimitSubject = "size:attachments" // issue and release attachments
+)
+
+// LimitSubjects returns all the subjects a rule could limit
+func LimitSubjects() []LimitSubject {
+	return []LimitSubject{
+		LimitSubjectSizeAll,
+		LimitSubjectSizeGit,
+		LimitSubjectSizeLFS,
+		LimitSubjectSizePackages,
+		LimitSubjectSizeArtifacts,
+		LimitSubjectSizeAttachments,
+	}
+}
+
+// Name returns the name of the subject without the kind prefix, like `git` for `size:git`
+func (s LimitSubject) Name() string {
+	return strings.TrimPrefix(string(s), "size:")
+}
+
+// IsValid returns whether the subject is known
+func (s LimitSubject) IsValid() bool {
+	return slices.Contains(LimitSubjects(), s)
+}
+
+// Rule limits the total size of the subjects, a negative limit means unlimited
+type Rule struct {
+	ID       int64          `xorm:"pk autoincr"`
+	Name     string         `xorm:"VARCHAR(255) UNIQUE NOT NULL"`
+	Limit    int64          `xorm:"NOT NULL DEFAULT -1"`
+	Subjects []LimitSubject `xorm:"JSON TEXT"`
+}
+
+// TableName represents the real table name of Rule
+func (Rule) TableName() string {
+	return "quota_rule"
+}
+
+func init() {
+	db.RegisterModel(new(Rule))
+}
+
+// Covers returns whether the rule limits the subject
+func (r *Rule) Covers(subject LimitSubject) bool {
+	return slices.Contains(r.Subjects, LimitSubjectSizeAll) || slices.Contains(r.Subjects, subject)
+}
+
+// Sum returns the used size counted against the rule
+func (r *Rule) Sum(used *Used) int64 {
+	if slices.Contains(r.Subjects, LimitSubjectSizeAll) {
+		return used.Total()
+	}
+	var sum int64
+	for _, subject := range r.Subjects {
+		sum += used.Get(subject)
+	}
+	return sum
+}
+
+// Acceptable returns whether more data could be stored without exceeding the rule
+func (r *Rule) Acceptable(used *Used) bool {
+	return r.Limit < 0 || r.Sum(used) < r.Limit
+}
+
+// ValidateSubjects checks whether all the subjects are known
+func ValidateSubjects(subjects []LimitSubject) error {
+	if len(subjects) == 0 {
+		return util.NewInvalidArgumentErrorf("a quota rule must limit at least one subject")
+	}
+	for _, subject := range subjects {
+		if !subject.IsValid() {
+			return util.NewInvalidArgumentErrorf("invalid quota subject %q", subject)
+		}
+	}
+	return nil
+}
+
+// GetRuleByName returns the rule by name
+func GetRuleByName(ctx context.Context, name string) (*Rule, error) {
+	var rule Rule
+	has, err := db.GetEngine(ctx).Where("name = ?", name).Get(&rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("quota rule %q: %w", name, util.ErrNotExist)
+	}
+	return &rule, nil
+}
+
+// ListRules returns all the rules ordered by name
+func ListRules(ctx context.Context) ([]*Rule, error) {
+	rules := make([]*Rule, 0, 10)
+	return rules, db.GetEngine(ctx).OrderBy("name").Find(&rules)
+}
+
+// CreateRule creates a new rule, the name must be unique
+func CreateRule(ctx context.Context, rule *Rule) error {
+	if err := ValidateSubjects(rule.Subjects); err != nil {
+		return err
+	}
+	exist, err := db.GetEngine(ctx).Exist(&Rule{Name: rule.Name})
+	if err != nil {
+		return err
+	} else if exist {
+		return fmt.Errorf("quota rule %q: %w", rule.Name, util.ErrAlreadyExist)
+	}
+	return db.Insert(ctx, rule)
+}
+
+// UpdateRule updates the limit and the subjects of the rule
+func UpdateRule(ctx context.Context, rule *Rule) error {
+	if err := ValidateSubjects(rule.Subjects); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(rule.ID).Cols("limit", "subjects").Update(rule)
+	return err
+}
+
+// DeleteRule deletes the rule and removes it from all the groups
+func DeleteRule(ctx context.Context, rule *Rule) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("rule_id = ?", rule.ID).Delete(&GroupRule{}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(rule.ID).Delete(&Rule{})
+		return err
+	})
+}
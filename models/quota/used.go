@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package quota
+
+import (
+	"context"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	git_model "code.gitea.io/gitea/models/git"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+)
+
+// Used is the storage used by a user or an organization in bytes
+type Used struct {
+	Git         int64
+	LFS         int64
+	Packages    int64
+	Artifacts   int64
+	Attachments int64
+}
+
+// Total returns the size of all the subjects
+func (u *Used) Total() int64 {
+	return u.Git + u.LFS + u.Packages + u.Artifacts + u.Attachments
+}
+
+// Get returns the size of the subject
+func (u *Used) Get(subject LimitSubject) int64 {
+	switch subject {
+	case LimitSubjectSizeAll:
+		return u.Total()
+	case LimitSubjectSizeGit:
+		return u.Git
+	case LimitSubjectSizeLFS:
+		return u.LFS
+	case LimitSubjectSizePackages:
+		return u.Packages
+	case LimitSubjectSizeArtifacts:
+		return u.Artifacts
+	case LimitSubjectSizeAttachments:
+		return u.Attachments
+	}
+	return 0
+}
+
+// GetUsedForUser calculates the storage used by the user or organization
+func GetUsedForUser(ctx context.Context, userID int64) (*Used, error) {
+	var (
+		used Used
+		err  error
+	)
+	if used.Git, err = repo_model.GetOwnerGitSize(ctx, userID); err != nil {
+		return nil, err
+	}
+	if used.LFS, err = git_model.GetOwnerLFSSize(ctx, userID); err != nil {
+		return nil, err
+	}
+	if used.Packages, err = packages_model.CalculateFileSize(ctx, &packages_model.PackageFileSearchOptions{OwnerID: userID}); err != nil {
+		return nil, err
+	}
+	if used.Artifacts, err = actions_model.GetOwnerArtifactsSize(ctx, userID); err != nil {
+		return nil, err
+	}
+	if used.Attachments, err = repo_model.GetOwnerAttachmentsSize(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &used, nil
+}
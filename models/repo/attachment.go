@@ -249,6 +249,15 @@ func DeleteAttachmentsByRelease(ctx context.Context, releaseID int64) error {
 	return err
 }
 
+// GetOwnerAttachmentsSize returns the size of the attachments of all the repositories of the owner
+func GetOwnerAttachmentsSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Table("attachment").
+		Join("INNER", "repository", "repository.id = attachment.repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		SumInt(new(Attachment), "attachment.size")
+}
+
 // CountOrphanedAttachments returns the number of bad attachments
 func CountOrphanedAttachments(ctx context.Context) (int64, error) {
 	return db.GetEngine(ctx).Where("(issue_id > 0 and issue_id not in (select id from issue)) or (release_id > 0 and release_id not in (select id from `release`))").
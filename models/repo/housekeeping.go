@@ -0,0 +1,199 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(RepoHousekeeping))
+}
+
+// HousekeepingStatus represents the housekeeping status of a repository
+type HousekeepingStatus int
+
+// enumerate all housekeeping statuses
+const (
+	HousekeepingStatusIdle    HousekeepingStatus = iota // 0 nothing is queued or running
+	HousekeepingStatusQueued                            // 1 waiting in the housekeeping queue
+	HousekeepingStatusRunning                           // 2 housekeeping tasks are running
+	HousekeepingStatusFailed                            // 3 the last housekeeping failed
+)
+
+// HousekeepingStatuses contains all housekeeping statuses
+var HousekeepingStatuses = []HousekeepingStatus{
+	HousekeepingStatusIdle,
+	HousekeepingStatusQueued,
+	HousekeepingStatusRunning,
+	HousekeepingStatusFailed,
+}
+
+// String returns the name of the status
+func (status HousekeepingStatus) String() string {
+	switch status {
+	case HousekeepingStatusQueued:
+		return "queued"
+	case HousekeepingStatusRunning:
+		return "running"
+	case HousekeepingStatusFailed:
+		return "failed"
+	default:
+		return "idle"
+	}
+}
+
+// RepoHousekeeping contains the push counts, the object statistics and the state of the last housekeeping of a repository
+type RepoHousekeeping struct { //revive:disable-line:exported
+	ID                    int64              `xorm:"pk autoincr"`
+	RepoID                int64              `xorm:"UNIQUE NOT NULL"`
+	Repo                  *Repository        `xorm:"-"`
+	Status                HousekeepingStatus `xorm:"INDEX NOT NULL DEFAULT 0"`
+	PushesSinceCheck      int64              `xorm:"NOT NULL DEFAULT 0"`
+	PushesSinceFullRepack int64              `xorm:"NOT NULL DEFAULT 0"`
+
+	LooseObjects      int64 `xorm:"NOT NULL DEFAULT 0"`
+	LooseObjectsSize  int64 `xorm:"NOT NULL DEFAULT 0"`
+	Packs             int64 `xorm:"NOT NULL DEFAULT 0"`
+	PacksSize         int64 `xorm:"NOT NULL DEFAULT 0"`
+	LooseRefs         int64 `xorm:"NOT NULL DEFAULT 0"`
+	HasCommitGraph    bool  `xorm:"NOT NULL DEFAULT false"`
+	HasMultiPackIndex bool  `xorm:"NOT NULL DEFAULT false"`
+	HasBitmap         bool  `xorm:"NOT NULL DEFAULT false"`
+
+	LastTasks          string             // comma separated names of the tasks of the last housekeeping
+	LastError          string             `xorm:"TEXT"`
+	LastStartedUnix    timeutil.TimeStamp `xorm:"INDEX"`
+	LastFinishedUnix   timeutil.TimeStamp
+	LastFullRepackUnix timeutil.TimeStamp
+	UpdatedUnix        timeutil.TimeStamp `xorm:"INDEX UPDATED"`
+}
+
+// Tasks returns the names of the tasks of the last housekeeping
+func (h *RepoHousekeeping) Tasks() []string {
+	if h.LastTasks == "" {
+		return nil
+	}
+	return strings.Split(h.LastTasks, ",")
+}
+
+// LastDuration returns the duration of the last housekeeping in seconds
+func (h *RepoHousekeeping) LastDuration() int64 {
+	if h.LastFinishedUnix < h.LastStartedUnix {
+		return 0
+	}
+	return int64(h.LastFinishedUnix - h.LastStartedUnix)
+}
+
+// GetRepoHousekeeping returns the housekeeping state of a repository.
+// If the repository has never been tracked, an unsaved state is returned.
+func GetRepoHousekeeping(ctx context.Context, repoID int64) (*RepoHousekeeping, error) {
+	h := &RepoHousekeeping{RepoID: repoID}
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SaveRepoHousekeeping inserts the housekeeping state of a repository or updates the given columns, all columns if none are given
+func SaveRepoHousekeeping(ctx context.Context, h *RepoHousekeeping, cols ...string) error {
+	if h.ID == 0 {
+		return db.Insert(ctx, h)
+	}
+	sess := db.GetEngine(ctx).ID(h.ID)
+	if len(cols) > 0 {
+		sess.Cols(cols...)
+	} else {
+		sess.AllCols()
+	}
+	_, err := sess.Update(h)
+	return err
+}
+
+func increaseRepoHousekeepingPushes(ctx context.Context, repoID int64) (bool, error) {
+	affected, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Incr("pushes_since_check").Incr("pushes_since_full_repack").NoAutoTime().Update(new(RepoHousekeeping))
+	return affected > 0, err
+}
+
+// IncreaseRepoHousekeepingPushes counts a push to a repository and returns the number of pushes since the last check
+func IncreaseRepoHousekeepingPushes(ctx context.Context, repoID int64) (int64, error) {
+	increased, err := increaseRepoHousekeepingPushes(ctx, repoID)
+	if err != nil {
+		return 0, err
+	}
+	if !increased {
+		insertErr := db.Insert(ctx, &RepoHousekeeping{RepoID: repoID, PushesSinceCheck: 1, PushesSinceFullRepack: 1})
+		if insertErr == nil {
+			return 1, nil
+		}
+		// the insert fails on the unique repo_id if a concurrent push has inserted the row first
+		if increased, err = increaseRepoHousekeepingPushes(ctx, repoID); err != nil {
+			return 0, err
+		} else if !increased {
+			return 0, insertErr
+		}
+	}
+
+	h, err := GetRepoHousekeeping(ctx, repoID)
+	if err != nil {
+		return 0, err
+	}
+	return h.PushesSinceCheck, nil
+}
+
+// SetRepoHousekeepingStatus sets the housekeeping status of a repository
+func SetRepoHousekeepingStatus(ctx context.Context, repoID int64, status HousekeepingStatus) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		h, err := GetRepoHousekeeping(ctx, repoID)
+		if err != nil {
+			return err
+		}
+		h.Status = status
+		return SaveRepoHousekeeping(ctx, h, "status")
+	})
+}
+
+// FindRepoHousekeepingOptions represents the options to search housekeeping states
+type FindRepoHousekeepingOptions struct {
+	db.ListOptions
+	Status optional.Option[HousekeepingStatus]
+}
+
+func (opts FindRepoHousekeepingOptions) ToConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Status.Has() {
+		cond = cond.And(builder.Eq{"status": opts.Status.Value()})
+	}
+	return cond
+}
+
+func (opts FindRepoHousekeepingOptions) ToOrders() string {
+	return "pushes_since_check DESC, loose_objects DESC, packs DESC, id ASC"
+}
+
+// RepoHousekeepingList is a list of housekeeping states
+type RepoHousekeepingList []*RepoHousekeeping //revive:disable-line:exported
+
+// LoadRepos loads the repositories of the housekeeping states
+func (list RepoHousekeepingList) LoadRepos(ctx context.Context) error {
+	repoIDs := make([]int64, 0, len(list))
+	for _, h := range list {
+		repoIDs = append(repoIDs, h.RepoID)
+	}
+	repos, err := GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		return err
+	}
+	for _, h := range list {
+		h.Repo = repos[h.RepoID]
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo_test
+
+import (
+	"sync"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncreaseRepoHousekeepingPushes(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	pushes, err := repo_model.IncreaseRepoHousekeepingPushes(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pushes)
+
+	pushes, err = repo_model.IncreaseRepoHousekeepingPushes(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, pushes)
+
+	h := unittest.AssertExistsAndLoadBean(t, &repo_model.RepoHousekeeping{RepoID: 1})
+	assert.EqualValues(t, 2, h.PushesSinceCheck)
+	assert.EqualValues(t, 2, h.PushesSinceFullRepack)
+}
+
+func TestIncreaseRepoHousekeepingPushesConcurrently(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo_model.IncreaseRepoHousekeepingPushes(db.DefaultContext, 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	h := unittest.AssertExistsAndLoadBean(t, &repo_model.RepoHousekeeping{RepoID: 1})
+	assert.EqualValues(t, 10, h.PushesSinceCheck)
+	assert.EqualValues(t, 10, h.PushesSinceFullRepack)
+}
@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -183,6 +184,8 @@ type Repository struct {
 	StatsIndexerStatus              *RepoIndexerStatus `xorm:"-"`
 	IsFsckEnabled                   bool               `xorm:"NOT NULL DEFAULT true"`
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
+	DisablePartialClone             bool               `xorm:"NOT NULL DEFAULT false"`
+	PartialCloneFilters             []string           `xorm:"TEXT JSON"`
 	Topics                          []string           `xorm:"TEXT JSON"`
 	ObjectFormatName                string             `xorm:"VARCHAR(6) NOT NULL DEFAULT 'sha1'"`
 
@@ -615,6 +618,25 @@ func (repo *Repository) CanEnableEditor() bool {
 	return !repo.IsMirror
 }
 
+// AllowedPartialCloneFilters returns the object filter kinds which can be used to partially clone the repository.
+// The repository can only restrict the filters allowed by the instance, nil means partial clones are disabled.
+func (repo *Repository) AllowedPartialCloneFilters() []string {
+	if repo.DisablePartialClone || setting.Git.DisablePartialClone {
+		return nil
+	}
+	if len(repo.PartialCloneFilters) == 0 {
+		return setting.Git.PartialCloneFilters
+	}
+
+	var filters []string
+	for _, filter := range repo.PartialCloneFilters {
+		if slices.Contains(setting.Git.PartialCloneFilters, filter) {
+			filters = append(filters, filter)
+		}
+	}
+	return filters
+}
+
 // DescriptionHTML does special handles to description and return HTML string.
 func (repo *Repository) DescriptionHTML(ctx context.Context) template.HTML {
 	desc, err := markup.PostProcessDescriptionHTML(markup.NewRenderContext(ctx), repo.Description)
@@ -217,3 +217,25 @@ func TestComposeSSHCloneURL(t *testing.T) {
 	setting.SSH.Port = 123
 	assert.Equal(t, "ssh://git@[::1]:123/user/repo.git", ComposeSSHCloneURL("user", "repo"))
 }
+
+func TestAllowedPartialCloneFilters(t *testing.T) {
+	defer test.MockVariableValue(&setting.Git.DisablePartialClone, false)()
+	defer test.MockVariableValue(&setting.Git.PartialCloneFilters, []string{"blob:none", "blob:limit", "tree"})()
+
+	repo := &Repository{}
+	assert.Equal(t, []string{"blob:none", "blob:limit", "tree"}, repo.AllowedPartialCloneFilters())
+
+	repo.PartialCloneFilters = []string{"tree", "sparse:oid"}
+	assert.Equal(t, []string{"tree"}, repo.AllowedPartialCloneFilters())
+
+	repo.PartialCloneFilters = []string{"sparse:oid"}
+	assert.Nil(t, repo.AllowedPartialCloneFilters())
+
+	repo.PartialCloneFilters = nil
+	repo.DisablePartialClone = true
+	assert.Nil(t, repo.AllowedPartialCloneFilters())
+
+	repo.DisablePartialClone = false
+	setting.Git.DisablePartialClone = true
+	assert.Nil(t, repo.AllowedPartialCloneFilters())
+}
@@ -170,6 +170,9 @@ func (cfg *PullRequestsConfig) GetDefaultMergeStyle() MergeStyle {
 
 type ActionsConfig struct {
 	DisabledWorkflows []string
+	// TokenPermissionMode is the default permissions of the tokens of the jobs without their own `permissions`,
+	// the mode of the owner is used if it's empty
+	TokenPermissionMode string
 }
 
 func (cfg *ActionsConfig) EnableWorkflow(file string) {
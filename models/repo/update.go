@@ -143,3 +143,8 @@ func UpdateRepoSize(ctx context.Context, repoID, gitSize, lfsSize int64) error {
 	})
 	return err
 }
+
+// GetOwnerGitSize returns the size of the git data of all the repositories of the owner
+func GetOwnerGitSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("owner_id = ?", ownerID).SumInt(new(Repository), "git_size")
+}
@@ -25,6 +25,7 @@ import (
 // It can be:
 //  1. org/user level secret, OwnerID is org/user ID and RepoID is 0
 //  2. repo level secret, OwnerID is 0 and RepoID is repo ID
+//  3. environment level secret, OwnerID is 0, RepoID is repo ID and EnvironmentID is the ID of an environment of the repo
 //
 // Please note that it's not acceptable to have both OwnerID and RepoID to be non-zero,
 // or it will be complicated to find secrets belonging to a specific owner.
@@ -35,12 +36,13 @@ import (
 // Please note that it's not acceptable to have both OwnerID and RepoID to zero, global secrets are not supported.
 // It's for security reasons, admin may be not aware of that the secrets could be stolen by any user when setting them as global.
 type Secret struct {
-	ID          int64
-	OwnerID     int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL"`
-	RepoID      int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
-	Name        string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
-	Data        string             `xorm:"LONGTEXT"` // encrypted data
-	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	ID            int64
+	OwnerID       int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL"`
+	RepoID        int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+	EnvironmentID int64              `xorm:"INDEX UNIQUE(owner_repo_name) NOT NULL DEFAULT 0"`
+	Name          string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+	Data          string             `xorm:"LONGTEXT"` // encrypted data
+	CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
 }
 
 // ErrSecretNotFound represents a "secret not found" error.
@@ -80,16 +82,38 @@ func InsertEncryptedSecret(ctx context.Context, ownerID, repoID int64, name, dat
 	return secret, db.Insert(ctx, secret)
 }
 
+// InsertEncryptedEnvironmentSecret creates and encrypts a new secret of the environment with yet unencrypted data and insert into database
+func InsertEncryptedEnvironmentSecret(ctx context.Context, env *actions_model.ActionEnvironment, name, data string) (*Secret, error) {
+	encrypted, err := secret_module.EncryptSecret(setting.SecretKey, data)
+	if err != nil {
+		return nil, err
+	}
+	secret := &Secret{
+		RepoID:        env.RepoID,
+		EnvironmentID: env.ID,
+		Name:          strings.ToUpper(name),
+		Data:          encrypted,
+	}
+	return secret, db.Insert(ctx, secret)
+}
+
+// DeleteSecretsOfEnvironment deletes all the secrets of the environment
+func DeleteSecretsOfEnvironment(ctx context.Context, environmentID int64) error {
+	_, err := db.GetEngine(ctx).Where("environment_id = ?", environmentID).Delete(&Secret{})
+	return err
+}
+
 func init() {
 	db.RegisterModel(new(Secret))
 }
 
 type FindSecretsOptions struct {
 	db.ListOptions
-	RepoID   int64
-	OwnerID  int64 // it will be ignored if RepoID is set
-	SecretID int64
-	Name     string
+	RepoID        int64
+	OwnerID       int64 // it will be ignored if RepoID is set
+	EnvironmentID int64 // the secrets of the environment are excluded if it's not set
+	SecretID      int64
+	Name          string
 }
 
 func (opts FindSecretsOptions) ToConds() builder.Cond {
@@ -102,6 +126,7 @@ func (opts FindSecretsOptions) ToConds() builder.Cond {
 	} else {
 		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
 	}
+	cond = cond.And(builder.Eq{"environment_id": opts.EnvironmentID})
 
 	if opts.SecretID != 0 {
 		cond = cond.And(builder.Eq{"id": opts.SecretID})
@@ -154,7 +179,17 @@ func GetSecretsOfTask(ctx context.Context, task *actions_model.ActionTask) (map[
 		return nil, err
 	}
 
-	for _, secret := range append(ownerSecrets, repoSecrets...) {
+	var envSecrets []*Secret
+	if task.Job.EnvironmentID > 0 {
+		envSecrets, err = db.Find[Secret](ctx, FindSecretsOptions{RepoID: task.Job.Run.RepoID, EnvironmentID: task.Job.EnvironmentID})
+		if err != nil {
+			log.Error("find secrets of environment %v: %v", task.Job.EnvironmentID, err)
+			return nil, err
+		}
+	}
+
+	// Level precedence: Environment > Repo > Org / User
+	for _, secret := range append(ownerSecrets, append(repoSecrets, envSecrets...)...) {
 		v, err := secret_module.DecryptSecret(setting.SecretKey, secret.Data)
 		if err != nil {
 			log.Error("decrypt secret %v %q: %v", secret.ID, secret.Name, err)
@@ -18,4 +18,6 @@ const (
 	SignupIP = "signup.ip"
 	// SignupUserAgent is the user agent that the user signed up with
 	SignupUserAgent = "signup.user_agent"
+	// SettingsKeyActionsTokenPermissionMode is the setting key for the default permissions of the Actions tokens in the repositories of the user or the organization
+	SettingsKeyActionsTokenPermissionMode = "actions.token_permission_mode"
 )
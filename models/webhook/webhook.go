@@ -299,6 +299,18 @@ func (w *Webhook) HasPackageEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Package)
 }
 
+// HasWorkflowRunEvent returns true if hook enabled workflow run event.
+func (w *Webhook) HasWorkflowRunEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.WorkflowRun)
+}
+
+// HasWorkflowJobEvent returns true if hook enabled workflow job event.
+func (w *Webhook) HasWorkflowJobEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.WorkflowJob)
+}
+
 // HasPullRequestReviewRequestEvent returns true if hook enabled pull request review request event.
 func (w *Webhook) HasPullRequestReviewRequestEvent() bool {
 	return w.SendEverything ||
@@ -337,6 +349,8 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasReleaseEvent, webhook_module.HookEventRelease},
 		{w.HasPackageEvent, webhook_module.HookEventPackage},
 		{w.HasPullRequestReviewRequestEvent, webhook_module.HookEventPullRequestReviewRequest},
+		{w.HasWorkflowRunEvent, webhook_module.HookEventWorkflowRun},
+		{w.HasWorkflowJobEvent, webhook_module.HookEventWorkflowJob},
 	}
 }
 
@@ -0,0 +1,96 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Concurrency represents the `concurrency` setting of a workflow or a job,
+// see https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#concurrency
+type Concurrency struct {
+	Group            string `yaml:"group"`
+	CancelInProgress string `yaml:"cancel-in-progress"`
+}
+
+// WorkflowConcurrency contains the unevaluated concurrency settings of a workflow file
+type WorkflowConcurrency struct {
+	Workflow *Concurrency
+	Jobs     map[string]*Concurrency
+}
+
+// ParseConcurrency parses a `concurrency` node, which could be either a group name or a mapping with group and cancel-in-progress.
+// It returns nil if the node is empty.
+func ParseConcurrency(node *yaml.Node) (*Concurrency, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		if node.Value == "" {
+			return nil, nil
+		}
+		return &Concurrency{Group: node.Value}, nil
+	case yaml.MappingNode:
+		c := &Concurrency{}
+		if err := node.Decode(c); err != nil {
+			return nil, err
+		}
+		if c.Group == "" {
+			return nil, fmt.Errorf("concurrency group is required")
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("invalid concurrency: line %d, column %d", node.Line, node.Column)
+	}
+}
+
+// GetConcurrencyFromContent reads the workflow-level and job-level concurrency settings from the content of a workflow file
+func GetConcurrencyFromContent(content []byte) (*WorkflowConcurrency, error) {
+	var raw struct {
+		Concurrency yaml.Node `yaml:"concurrency"`
+		Jobs        map[string]struct {
+			Concurrency yaml.Node `yaml:"concurrency"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	wc := &WorkflowConcurrency{Jobs: make(map[string]*Concurrency, len(raw.Jobs))}
+	var err error
+	if wc.Workflow, err = ParseConcurrency(&raw.Concurrency); err != nil {
+		return nil, fmt.Errorf("workflow: %w", err)
+	}
+	for id, job := range raw.Jobs {
+		c, err := ParseConcurrency(&job.Concurrency)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", id, err)
+		}
+		if c != nil {
+			wc.Jobs[id] = c
+		}
+	}
+	return wc, nil
+}
+
+// Evaluate evaluates the expressions in the concurrency setting,
+// only the github, inputs, vars and matrix (for jobs) contexts are available.
+// It returns the evaluated group and whether in-progress runs or jobs in the group should be cancelled.
+func (c *Concurrency) Evaluate(jobID string, matrix map[string]any, gitCtx *model.GithubContext, vars map[string]string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	// the interpreter always looks up the current job in the results
+	results := map[string]*jobparser.JobResult{jobID: {}}
+	evaluator := jobparser.NewExpressionEvaluator(jobparser.NewInterpeter(jobID, &model.Job{}, matrix, gitCtx, results, vars))
+
+	group := evaluator.Interpolate(c.Group)
+	cancel, _ := strconv.ParseBool(evaluator.Interpolate(c.CancelInProgress))
+	return group, cancel
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetConcurrencyFromContent(t *testing.T) {
+	content := []byte(`
+name: deploy
+on: push
+concurrency: deploy-${{ github.ref }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    runs-on: ubuntu-latest
+    concurrency:
+      group: production-${{ matrix.region }}
+      cancel-in-progress: ${{ vars.CANCEL == 'yes' }}
+    strategy:
+      matrix:
+        region: [eu, us]
+    steps:
+      - run: echo deploy
+`)
+	wc, err := GetConcurrencyFromContent(content)
+	require.NoError(t, err)
+	require.NotNil(t, wc.Workflow)
+	assert.Len(t, wc.Jobs, 1)
+	require.NotNil(t, wc.Jobs["deploy"])
+
+	gitCtx := &model.GithubContext{Ref: "refs/heads/main"}
+	group, cancel := wc.Workflow.Evaluate("", nil, gitCtx, nil)
+	assert.Equal(t, "deploy-refs/heads/main", group)
+	assert.False(t, cancel)
+
+	group, cancel = wc.Jobs["deploy"].Evaluate("deploy", map[string]any{"region": "eu"}, gitCtx, map[string]string{"CANCEL": "yes"})
+	assert.Equal(t, "production-eu", group)
+	assert.True(t, cancel)
+
+	group, cancel = wc.Jobs["deploy"].Evaluate("deploy", map[string]any{"region": "us"}, gitCtx, nil)
+	assert.Equal(t, "production-us", group)
+	assert.False(t, cancel)
+
+	_, err = GetConcurrencyFromContent([]byte("on: push\nconcurrency:\n  cancel-in-progress: true\n"))
+	assert.Error(t, err)
+
+	wc, err = GetConcurrencyFromContent([]byte("on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n"))
+	require.NoError(t, err)
+	assert.Nil(t, wc.Workflow)
+	assert.Empty(t, wc.Jobs)
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment represents the `environment` setting of a job,
+// see https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#jobsjob_idenvironment
+type Environment struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+}
+
+// ParseEnvironment parses an `environment` node, which could be either an environment name or a mapping with name and url.
+// It returns nil if the node is empty.
+func ParseEnvironment(node *yaml.Node) (*Environment, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		if node.Value == "" {
+			return nil, nil
+		}
+		return &Environment{Name: node.Value}, nil
+	case yaml.MappingNode:
+		e := &Environment{}
+		if err := node.Decode(e); err != nil {
+			return nil, err
+		}
+		if e.Name == "" {
+			return nil, fmt.Errorf("environment name is required")
+		}
+		return e, nil
+	default:
+		return nil, fmt.Errorf("invalid environment: line %d, column %d", node.Line, node.Column)
+	}
+}
+
+// GetEnvironmentsFromContent reads the environments of the jobs from the content of a workflow file
+func GetEnvironmentsFromContent(content []byte) (map[string]*Environment, error) {
+	var raw struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]*Environment, len(raw.Jobs))
+	for id, job := range raw.Jobs {
+		e, err := ParseEnvironment(&job.Environment)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", id, err)
+		}
+		if e != nil {
+			ret[id] = e
+		}
+	}
+	return ret, nil
+}
+
+// Evaluate evaluates the expressions in the environment name,
+// only the github, inputs, vars and matrix contexts are available.
+func (e *Environment) Evaluate(jobID string, matrix map[string]any, gitCtx *model.GithubContext, vars map[string]string) string {
+	if e == nil {
+		return ""
+	}
+	results := map[string]*jobparser.JobResult{jobID: {}}
+	evaluator := jobparser.NewExpressionEvaluator(jobparser.NewInterpeter(jobID, &model.Job{}, matrix, gitCtx, results, vars))
+	return evaluator.Interpolate(e.Name)
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEnvironmentsFromContent(t *testing.T) {
+	content := []byte(`
+name: deploy
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  staging:
+    runs-on: ubuntu-latest
+    environment: staging
+    steps:
+      - run: echo deploy
+  production:
+    runs-on: ubuntu-latest
+    environment:
+      name: production-${{ matrix.region }}
+      url: https://${{ matrix.region }}.example.com
+    strategy:
+      matrix:
+        region: [eu, us]
+    steps:
+      - run: echo deploy
+`)
+	envs, err := GetEnvironmentsFromContent(content)
+	require.NoError(t, err)
+	assert.Len(t, envs, 2)
+	require.NotNil(t, envs["staging"])
+	require.NotNil(t, envs["production"])
+	assert.Equal(t, "https://${{ matrix.region }}.example.com", envs["production"].URL)
+
+	gitCtx := &model.GithubContext{Ref: "refs/heads/main"}
+	assert.Equal(t, "staging", envs["staging"].Evaluate("staging", nil, gitCtx, nil))
+	assert.Equal(t, "production-eu", envs["production"].Evaluate("production", map[string]any{"region": "eu"}, gitCtx, nil))
+
+	_, err = GetEnvironmentsFromContent([]byte("on: push\njobs:\n  a:\n    environment:\n      url: https://example.com\n"))
+	assert.Error(t, err)
+}
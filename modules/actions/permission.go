@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"slices"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParsePermissions parses a `permissions` node, which could be either `read-all`, `write-all` or a mapping of scopes to `read`, `write` or `none`.
+// It returns nil if the node is empty.
+func ParsePermissions(node *yaml.Node) (actions_model.JobPermissions, error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil
+	case yaml.ScalarNode:
+		switch node.Value {
+		case "read-all":
+			return actions_model.NewJobPermissions(actions_model.PermissionRead), nil
+		case "write-all":
+			return actions_model.NewJobPermissions(actions_model.PermissionWrite), nil
+		default:
+			return nil, fmt.Errorf("invalid permissions %q: line %d, column %d", node.Value, node.Line, node.Column)
+		}
+	case yaml.MappingNode:
+		var scopes map[string]string
+		if err := node.Decode(&scopes); err != nil {
+			return nil, err
+		}
+		ret := make(actions_model.JobPermissions, len(scopes))
+		for scope, value := range scopes {
+			if !slices.Contains(actions_model.PermissionScopes, scope) {
+				return nil, fmt.Errorf("unknown permission scope %q", scope)
+			}
+			switch value {
+			case "none":
+				ret[scope] = actions_model.PermissionNone
+			case "read":
+				ret[scope] = actions_model.PermissionRead
+			case "write":
+				ret[scope] = actions_model.PermissionWrite
+			default:
+				return nil, fmt.Errorf("invalid permission %q of scope %q", value, scope)
+			}
+		}
+		if level, ok := ret[actions_model.PermissionScopeIDToken]; ok && level == actions_model.PermissionRead {
+			return nil, fmt.Errorf("permission of scope %q could only be %q or %q", actions_model.PermissionScopeIDToken, "write", "none")
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("invalid permissions: line %d, column %d", node.Line, node.Column)
+	}
+}
+
+// GetPermissionsFromContent reads the permissions of the jobs from the content of a workflow file,
+// the jobs without their own permissions use the permissions of the workflow.
+// The jobs whose permissions are set by neither are missing in the returned map.
+func GetPermissionsFromContent(content []byte) (map[string]actions_model.JobPermissions, error) {
+	var raw struct {
+		Permissions yaml.Node `yaml:"permissions"`
+		Jobs        map[string]struct {
+			Permissions yaml.Node `yaml:"permissions"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	workflowPermissions, err := ParsePermissions(&raw.Permissions)
+	if err != nil {
+		return nil, fmt.Errorf("workflow: %w", err)
+	}
+
+	ret := make(map[string]actions_model.JobPermissions, len(raw.Jobs))
+	for id, job := range raw.Jobs {
+		p, err := ParsePermissions(&job.Permissions)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", id, err)
+		}
+		if p == nil {
+			p = workflowPermissions
+		}
+		if p != nil {
+			ret[id] = p
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPermissionsFromContent(t *testing.T) {
+	content := []byte(`
+name: deploy
+on: push
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      id-token: write
+    steps:
+      - run: echo deploy
+  release:
+    runs-on: ubuntu-latest
+    permissions: write-all
+    steps:
+      - run: echo release
+  lint:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: echo lint
+`)
+	permissions, err := GetPermissionsFromContent(content)
+	require.NoError(t, err)
+	assert.Len(t, permissions, 4)
+
+	assert.Equal(t, actions_model.JobPermissions{"contents": actions_model.PermissionRead}, permissions["build"])
+	assert.False(t, permissions["build"].CanRequestIDToken())
+	assert.True(t, permissions["deploy"].CanRequestIDToken())
+	assert.Equal(t, actions_model.PermissionWrite, permissions["release"].Level("packages", actions_model.PermissionNone))
+	assert.True(t, permissions["release"].CanRequestIDToken())
+	assert.True(t, permissions["lint"].IsSet())
+	assert.Equal(t, actions_model.PermissionNone, permissions["lint"].Level("contents", actions_model.PermissionWrite))
+
+	permissions, err = GetPermissionsFromContent([]byte("on: push\njobs:\n  a:\n    runs-on: ubuntu-latest\n"))
+	require.NoError(t, err)
+	assert.Empty(t, permissions)
+	assert.False(t, permissions["a"].IsSet())
+	assert.Equal(t, actions_model.PermissionWrite, permissions["a"].Level("contents", actions_model.PermissionWrite))
+	assert.False(t, permissions["a"].CanRequestIDToken())
+
+	for _, invalid := range []string{
+		"on: push\npermissions: read\njobs:\n  a:\n    runs-on: ubuntu-latest\n",
+		"on: push\njobs:\n  a:\n    permissions:\n      code: read\n",
+		"on: push\njobs:\n  a:\n    permissions:\n      contents: admin\n",
+		"on: push\njobs:\n  a:\n    permissions:\n      id-token: read\n",
+	} {
+		_, err = GetPermissionsFromContent([]byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestJobPermissionsRestrict(t *testing.T) {
+	caller := actions_model.JobPermissions{"contents": actions_model.PermissionRead, "id-token": actions_model.PermissionWrite}
+	called := actions_model.NewJobPermissions(actions_model.PermissionWrite)
+
+	restricted := called.Restrict(caller)
+	assert.Equal(t, actions_model.PermissionRead, restricted["contents"])
+	assert.Equal(t, actions_model.PermissionNone, restricted["issues"])
+	assert.True(t, restricted.CanRequestIDToken())
+
+	var unset actions_model.JobPermissions
+	assert.Equal(t, caller, unset.Restrict(caller))
+	assert.Equal(t, called, called.Restrict(nil))
+}
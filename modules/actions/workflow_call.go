@@ -0,0 +1,236 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxWorkflowCallDepth is the max number of workflows connected by reusable workflow calls, including the workflow of the run,
+// see https://docs.github.com/en/actions/sharing-automations/reusing-workflows#nesting-reusable-workflows
+const MaxWorkflowCallDepth = 4
+
+// ReusableWorkflowRef is the `uses` of a job calling a reusable workflow,
+// it's either `./.gitea/workflows/file.yml` in the same repository and commit as the caller,
+// or `owner/repo/.gitea/workflows/file.yml@ref` in another repository.
+type ReusableWorkflowRef struct {
+	Owner string // empty for a local workflow
+	Repo  string // empty for a local workflow
+	Path  string
+	Ref   string // empty for a local workflow
+}
+
+// IsLocal returns whether the workflow is in the same repository and commit as the caller
+func (r *ReusableWorkflowRef) IsLocal() bool {
+	return r.Owner == ""
+}
+
+func (r *ReusableWorkflowRef) String() string {
+	if r.IsLocal() {
+		return "./" + r.Path
+	}
+	return fmt.Sprintf("%s/%s/%s@%s", r.Owner, r.Repo, r.Path, r.Ref)
+}
+
+// ParseReusableWorkflowRef parses the `uses` of a job
+func ParseReusableWorkflowRef(uses string) (*ReusableWorkflowRef, error) {
+	if p, ok := strings.CutPrefix(uses, "./"); ok {
+		if !IsWorkflow(p) {
+			return nil, fmt.Errorf("invalid reusable workflow %q: not a workflow file", uses)
+		}
+		return &ReusableWorkflowRef{Path: p}, nil
+	}
+
+	idx := strings.LastIndex(uses, "@")
+	if idx < 0 || idx == len(uses)-1 {
+		return nil, fmt.Errorf("invalid reusable workflow %q: missing ref", uses)
+	}
+	parts := strings.SplitN(uses[:idx], "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || !IsWorkflow(parts[2]) {
+		return nil, fmt.Errorf("invalid reusable workflow %q: expect owner/repo/path@ref", uses)
+	}
+	return &ReusableWorkflowRef{
+		Owner: parts[0],
+		Repo:  parts[1],
+		Path:  parts[2],
+		Ref:   uses[idx+1:],
+	}, nil
+}
+
+// WorkflowCallInput is an input of a reusable workflow
+type WorkflowCallInput struct {
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+	Default     string `yaml:"default"`
+	Type        string `yaml:"type"`
+}
+
+// WorkflowCallSecret is a secret of a reusable workflow
+type WorkflowCallSecret struct {
+	Description string `yaml:"description"`
+	Required    bool   `yaml:"required"`
+}
+
+// WorkflowCall represents the `on.workflow_call` setting of a reusable workflow,
+// see https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#onworkflow_call
+type WorkflowCall struct {
+	Inputs  map[string]*WorkflowCallInput        `yaml:"inputs"`
+	Secrets map[string]*WorkflowCallSecret       `yaml:"secrets"`
+	Outputs map[string]*model.WorkflowCallOutput `yaml:"outputs"`
+}
+
+// GetWorkflowCallFromContent reads the `on.workflow_call` setting from the content of a workflow file,
+// it returns an error if the workflow isn't triggered by `workflow_call`.
+func GetWorkflowCallFromContent(content []byte) (*WorkflowCall, error) {
+	var raw struct {
+		On yaml.Node `yaml:"on"`
+	}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	wc := &WorkflowCall{}
+	callable := false
+	switch raw.On.Kind {
+	case yaml.ScalarNode:
+		callable = raw.On.Value == GithubEventWorkflowCall
+	case yaml.SequenceNode:
+		var events []string
+		if err := raw.On.Decode(&events); err != nil {
+			return nil, err
+		}
+		callable = slices.Contains(events, GithubEventWorkflowCall)
+	case yaml.MappingNode:
+		var events map[string]yaml.Node
+		if err := raw.On.Decode(&events); err != nil {
+			return nil, err
+		}
+		var node yaml.Node
+		node, callable = events[GithubEventWorkflowCall]
+		if node.Kind == yaml.MappingNode {
+			if err := node.Decode(wc); err != nil {
+				return nil, fmt.Errorf("invalid workflow_call: %w", err)
+			}
+		}
+	}
+	if !callable {
+		return nil, fmt.Errorf("the workflow isn't triggered by %s", GithubEventWorkflowCall)
+	}
+	return wc, nil
+}
+
+// ResolveInputs checks the inputs passed by `with` and fills the default values of the missing ones
+func (wc *WorkflowCall) ResolveInputs(with map[string]any) (map[string]any, error) {
+	for name := range with {
+		if _, ok := wc.Inputs[name]; !ok {
+			return nil, fmt.Errorf("invalid input %q: not defined by the reusable workflow", name)
+		}
+	}
+	ret := make(map[string]any, len(wc.Inputs))
+	for name, input := range wc.Inputs {
+		if v, ok := with[name]; ok {
+			ret[name] = v
+		} else if input.Required {
+			return nil, fmt.Errorf("input %q is required by the reusable workflow", name)
+		} else if input.Default != "" {
+			ret[name] = input.Default
+		}
+	}
+	return ret, nil
+}
+
+// CheckSecrets checks the secrets passed by `secrets`, which is either `inherit` or a mapping of the secrets
+func (wc *WorkflowCall) CheckSecrets(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Value == "inherit" {
+		return nil
+	}
+	var secrets map[string]string
+	if node.Kind != 0 {
+		if err := node.Decode(&secrets); err != nil {
+			return fmt.Errorf("invalid secrets: %w", err)
+		}
+	}
+	for name := range secrets {
+		if _, ok := wc.Secrets[name]; !ok {
+			return fmt.Errorf("invalid secret %q: not defined by the reusable workflow", name)
+		}
+	}
+	for name, secret := range wc.Secrets {
+		if _, ok := secrets[name]; secret.Required && !ok {
+			return fmt.Errorf("secret %q is required by the reusable workflow", name)
+		}
+	}
+	return nil
+}
+
+// WorkflowCallContext contains the contexts available to evaluate the expressions of a reusable workflow call on the server side
+type WorkflowCallContext struct {
+	JobID   string
+	Github  *model.GithubContext
+	Vars    map[string]string
+	Matrix  map[string]any
+	Needs   map[string]exprparser.Needs
+	Inputs  map[string]any
+	Secrets map[string]string
+	Jobs    map[string]*model.WorkflowCallResult
+}
+
+func (c *WorkflowCallContext) interpreter() exprparser.Interpreter {
+	run := &model.Run{
+		Workflow: &model.Workflow{Jobs: map[string]*model.Job{}},
+		JobID:    c.JobID,
+	}
+	needs := make([]string, 0, len(c.Needs))
+	for id, need := range c.Needs {
+		run.Workflow.Jobs[id] = &model.Job{Result: need.Result, Outputs: need.Outputs}
+		needs = append(needs, id)
+	}
+	job := &model.Job{}
+	_ = job.RawNeeds.Encode(needs)
+	run.Workflow.Jobs[c.JobID] = job
+
+	var jobs *map[string]*model.WorkflowCallResult
+	if c.Jobs != nil {
+		jobs = &c.Jobs
+	}
+	return exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{
+		Github:  c.Github,
+		Job:     &model.JobContext{Status: "success"},
+		Jobs:    jobs,
+		Vars:    c.Vars,
+		Matrix:  c.Matrix,
+		Needs:   c.Needs,
+		Inputs:  c.Inputs,
+		Secrets: c.Secrets,
+	}, exprparser.Config{Run: run, Context: "job"})
+}
+
+// Interpolate evaluates the expressions in the string
+func (c *WorkflowCallContext) Interpolate(in string) string {
+	return jobparser.NewExpressionEvaluator(c.interpreter()).Interpolate(in)
+}
+
+// EvaluateIf evaluates the `if` condition of the job calling the reusable workflow,
+// the condition is met if it's empty.
+func (c *WorkflowCallContext) EvaluateIf(node *yaml.Node) (bool, error) {
+	expr := strings.TrimSpace(node.Value)
+	if expr == "" {
+		expr = "success()"
+	}
+	if strings.HasPrefix(expr, "${{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[3 : len(expr)-2])
+	}
+	v, err := c.interpreter().Evaluate(expr, exprparser.DefaultStatusCheckSuccess)
+	if err != nil {
+		return false, fmt.Errorf("evaluate if %q: %w", node.Value, err)
+	}
+	return exprparser.IsTruthy(v), nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseReusableWorkflowRef(t *testing.T) {
+	ref, err := ParseReusableWorkflowRef("./.gitea/workflows/build.yml")
+	require.NoError(t, err)
+	assert.True(t, ref.IsLocal())
+	assert.Equal(t, ".gitea/workflows/build.yml", ref.Path)
+
+	ref, err = ParseReusableWorkflowRef("org/ci/.github/workflows/deploy.yaml@v1")
+	require.NoError(t, err)
+	assert.False(t, ref.IsLocal())
+	assert.Equal(t, &ReusableWorkflowRef{Owner: "org", Repo: "ci", Path: ".github/workflows/deploy.yaml", Ref: "v1"}, ref)
+	assert.Equal(t, "org/ci/.github/workflows/deploy.yaml@v1", ref.String())
+
+	for _, uses := range []string{
+		"./build.yml",
+		"org/ci/.github/workflows/deploy.yaml",
+		"org/ci/.github/workflows/deploy.yaml@",
+		"org/.github/workflows/deploy.yaml@v1",
+		"org/ci/deploy.yaml@v1",
+		"actions/checkout@v4",
+	} {
+		_, err := ParseReusableWorkflowRef(uses)
+		assert.Error(t, err, uses)
+	}
+}
+
+func TestGetWorkflowCallFromContent(t *testing.T) {
+	wc, err := GetWorkflowCallFromContent([]byte(`
+on:
+  workflow_call:
+    inputs:
+      env:
+        type: string
+        required: true
+      debug:
+        type: boolean
+        default: false
+    secrets:
+      token:
+        required: true
+    outputs:
+      version:
+        value: ${{ jobs.build.outputs.version }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+`))
+	require.NoError(t, err)
+	assert.Len(t, wc.Inputs, 2)
+	assert.True(t, wc.Inputs["env"].Required)
+	assert.Equal(t, "false", wc.Inputs["debug"].Default)
+	assert.True(t, wc.Secrets["token"].Required)
+	assert.Equal(t, "${{ jobs.build.outputs.version }}", wc.Outputs["version"].Value)
+
+	inputs, err := wc.ResolveInputs(map[string]any{"env": "prod"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"env": "prod", "debug": "false"}, inputs)
+	_, err = wc.ResolveInputs(map[string]any{})
+	assert.Error(t, err)
+	_, err = wc.ResolveInputs(map[string]any{"env": "prod", "unknown": "x"})
+	assert.Error(t, err)
+
+	secrets := func(s string) *yaml.Node {
+		var node yaml.Node
+		require.NoError(t, yaml.Unmarshal([]byte(s), &node))
+		return node.Content[0]
+	}
+	assert.NoError(t, wc.CheckSecrets(secrets("inherit")))
+	assert.NoError(t, wc.CheckSecrets(secrets("token: ${{ secrets.TOKEN }}")))
+	assert.Error(t, wc.CheckSecrets(&yaml.Node{}))
+	assert.Error(t, wc.CheckSecrets(secrets("token: a\nother: b")))
+
+	wc, err = GetWorkflowCallFromContent([]byte("on: [push, workflow_call]\njobs: {}\n"))
+	require.NoError(t, err)
+	assert.Empty(t, wc.Inputs)
+
+	_, err = GetWorkflowCallFromContent([]byte("on: push\njobs: {}\n"))
+	assert.Error(t, err)
+}
+
+func TestWorkflowCallContext(t *testing.T) {
+	c := &WorkflowCallContext{
+		JobID:  "call",
+		Github: &model.GithubContext{Ref: "refs/heads/main"},
+		Vars:   map[string]string{"REGION": "eu"},
+		Needs: map[string]exprparser.Needs{
+			"setup": {Result: "success", Outputs: map[string]string{"version": "1.2.3"}},
+		},
+		Inputs:  map[string]any{"env": "prod"},
+		Secrets: map[string]string{"TOKEN": "secret"},
+		Jobs: map[string]*model.WorkflowCallResult{
+			"build": {Outputs: map[string]string{"artifact": "app.tar"}},
+		},
+	}
+	assert.Equal(t, "1.2.3-eu-prod", c.Interpolate("${{ needs.setup.outputs.version }}-${{ vars.REGION }}-${{ inputs.env }}"))
+	assert.Equal(t, "secret", c.Interpolate("${{ secrets.TOKEN }}"))
+	assert.Equal(t, "app.tar", c.Interpolate("${{ jobs.build.outputs.artifact }}"))
+
+	evaluateIf := func(expr string) bool {
+		ok, err := c.EvaluateIf(&yaml.Node{Kind: yaml.ScalarNode, Value: expr})
+		require.NoError(t, err)
+		return ok
+	}
+	assert.True(t, evaluateIf(""))
+	assert.True(t, evaluateIf("github.ref == 'refs/heads/main'"))
+	assert.False(t, evaluateIf("${{ inputs.env == 'dev' }}"))
+	assert.False(t, evaluateIf("failure()"))
+
+	c.Needs["setup"] = exprparser.Needs{Result: "failure"}
+	assert.False(t, evaluateIf(""))
+	assert.True(t, evaluateIf("always()"))
+	assert.True(t, evaluateIf("failure()"))
+}
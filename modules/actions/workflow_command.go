@@ -0,0 +1,103 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AnnotationLevel is the level of an annotation created by a workflow command
+type AnnotationLevel string
+
+const (
+	AnnotationLevelNotice  AnnotationLevel = "notice"
+	AnnotationLevelWarning AnnotationLevel = "warning"
+	AnnotationLevelError   AnnotationLevel = "error"
+)
+
+// Annotation is created by a `::notice`, `::warning` or `::error` workflow command,
+// see https://docs.github.com/en/actions/writing-workflows/choosing-what-your-workflow-does/workflow-commands-for-github-actions
+type Annotation struct {
+	Level       AnnotationLevel
+	Title       string
+	Message     string
+	Path        string
+	StartLine   int64
+	EndLine     int64
+	StartColumn int64
+	EndColumn   int64
+}
+
+// ParseAnnotation parses a log line containing an annotation workflow command like
+// `::error file=app.js,line=1,col=5,endColumn=7,title=Syntax::Missing semicolon`.
+// It returns nil if the line isn't such a command.
+func ParseAnnotation(line string) *Annotation {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "::") {
+		return nil
+	}
+	command, message, ok := strings.Cut(line[2:], "::")
+	if !ok {
+		return nil
+	}
+	name, properties, _ := strings.Cut(command, " ")
+
+	a := &Annotation{
+		Level:   AnnotationLevel(name),
+		Message: unescapeCommandData(message),
+	}
+	switch a.Level {
+	case AnnotationLevelNotice, AnnotationLevelWarning, AnnotationLevelError:
+	default:
+		return nil
+	}
+
+	for _, property := range strings.Split(properties, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(property), "=")
+		if !ok {
+			continue
+		}
+		value = unescapeCommandProperty(value)
+		switch key {
+		case "title":
+			a.Title = value
+		case "file":
+			a.Path = strings.TrimPrefix(value, "./")
+		case "line":
+			a.StartLine = parseCommandNumber(value)
+		case "endLine":
+			a.EndLine = parseCommandNumber(value)
+		case "col":
+			a.StartColumn = parseCommandNumber(value)
+		case "endColumn":
+			a.EndColumn = parseCommandNumber(value)
+		}
+	}
+	if a.EndLine < a.StartLine {
+		a.EndLine = a.StartLine
+	}
+	return a
+}
+
+func parseCommandNumber(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+var (
+	commandDataReplacer     = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%25", "%")
+	commandPropertyReplacer = strings.NewReplacer("%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",", "%25", "%")
+)
+
+func unescapeCommandData(s string) string {
+	return commandDataReplacer.Replace(s)
+}
+
+func unescapeCommandProperty(s string) string {
+	return commandPropertyReplacer.Replace(s)
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnnotation(t *testing.T) {
+	cases := []struct {
+		line string
+		want *Annotation
+	}{
+		{
+			line: "::error file=app.js,line=1,col=5,endColumn=7,title=Syntax error::Missing semicolon",
+			want: &Annotation{
+				Level:       AnnotationLevelError,
+				Title:       "Syntax error",
+				Message:     "Missing semicolon",
+				Path:        "app.js",
+				StartLine:   1,
+				EndLine:     1,
+				StartColumn: 5,
+				EndColumn:   7,
+			},
+		},
+		{
+			line: "  ::warning file=./pkg/a.go,line=3,endLine=5::unused%0Avariable",
+			want: &Annotation{
+				Level:     AnnotationLevelWarning,
+				Message:   "unused\nvariable",
+				Path:      "pkg/a.go",
+				StartLine: 3,
+				EndLine:   5,
+			},
+		},
+		{
+			line: "::notice title=a%2Cb%3A c::100%25 done",
+			want: &Annotation{
+				Level:   AnnotationLevelNotice,
+				Title:   "a,b: c",
+				Message: "100% done",
+			},
+		},
+		{line: "::debug::not an annotation"},
+		{line: "::set-output name=a::b"},
+		{line: "echo ::error::not at the beginning"},
+		{line: "::error without separator"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, ParseAnnotation(c.line), c.line)
+	}
+}
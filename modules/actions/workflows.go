@@ -21,9 +21,10 @@ import (
 )
 
 type DetectedWorkflow struct {
-	EntryName    string
-	TriggerEvent *jobparser.Event
-	Content      []byte
+	EntryName          string
+	TriggerEvent       *jobparser.Event
+	Content            []byte
+	RequiredWorkflowID int64 // the id of the required workflow of the organization, 0 for the workflows of the repository
 }
 
 func init() {
@@ -43,21 +44,24 @@ func IsWorkflow(path string) bool {
 	return strings.HasPrefix(path, ".gitea/workflows") || strings.HasPrefix(path, ".github/workflows")
 }
 
-func ListWorkflows(commit *git.Commit) (git.Entries, error) {
-	tree, err := commit.SubTree(".gitea/workflows")
+// ListWorkflows returns the directory of the workflows in the commit and the workflow files in it
+func ListWorkflows(commit *git.Commit) (string, git.Entries, error) {
+	workflowDir := ".gitea/workflows"
+	tree, err := commit.SubTree(workflowDir)
 	if _, ok := err.(git.ErrNotExist); ok {
-		tree, err = commit.SubTree(".github/workflows")
+		workflowDir = ".github/workflows"
+		tree, err = commit.SubTree(workflowDir)
 	}
 	if _, ok := err.(git.ErrNotExist); ok {
-		return nil, nil
+		return "", nil, nil
 	}
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	entries, err := tree.ListEntriesRecursiveFast()
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	ret := make(git.Entries, 0, len(entries))
@@ -66,7 +70,7 @@ func ListWorkflows(commit *git.Commit) (git.Entries, error) {
 			ret = append(ret, entry)
 		}
 	}
-	return ret, nil
+	return workflowDir, ret, nil
 }
 
 func GetContentFromEntry(entry *git.TreeEntry) ([]byte, error) {
@@ -102,7 +106,7 @@ func DetectWorkflows(
 	payload api.Payloader,
 	detectSchedule bool,
 ) ([]*DetectedWorkflow, []*DetectedWorkflow, error) {
-	entries, err := ListWorkflows(commit)
+	_, entries, err := ListWorkflows(commit)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -146,8 +150,36 @@ func DetectWorkflows(
 	return workflows, schedules, nil
 }
 
+// DetectWorkflowsFromContent detects the workflows for the events of the content matching the triggered event, schedules are ignored.
+// It's used for the workflows which aren't in the commit, like the required workflows of organizations,
+// the filters of the events are still matched against the commit.
+func DetectWorkflowsFromContent(
+	gitRepo *git.Repository,
+	commit *git.Commit,
+	triggedEvent webhook_module.HookEventType,
+	payload api.Payloader,
+	entryName string,
+	content []byte,
+) ([]*DetectedWorkflow, error) {
+	events, err := GetEventsFromContent(content)
+	if err != nil {
+		return nil, err
+	}
+	var workflows []*DetectedWorkflow
+	for _, evt := range events {
+		if !evt.IsSchedule() && detectMatched(gitRepo, commit, triggedEvent, payload, evt) {
+			workflows = append(workflows, &DetectedWorkflow{
+				EntryName:    entryName,
+				TriggerEvent: evt,
+				Content:      content,
+			})
+		}
+	}
+	return workflows, nil
+}
+
 func DetectScheduledWorkflows(gitRepo *git.Repository, commit *git.Commit) ([]*DetectedWorkflow, error) {
-	entries, err := ListWorkflows(commit)
+	_, entries, err := ListWorkflows(commit)
 	if err != nil {
 		return nil, err
 	}
@@ -243,6 +275,10 @@ func detectMatched(gitRepo *git.Repository, commit *git.Commit, triggedEvent web
 		webhook_module.HookEventPackage:
 		return matchPackageEvent(payload.(*api.PackagePayload), evt)
 
+	case // merge_group
+		webhook_module.HookEventMergeGroup:
+		return matchMergeGroupEvent(payload.(*api.MergeGroupPayload), evt)
+
 	default:
 		log.Warn("unsupported event %q", triggedEvent)
 		return false
@@ -698,3 +734,48 @@ func matchPackageEvent(payload *api.PackagePayload, evt *jobparser.Event) bool {
 	}
 	return matchTimes == len(evt.Acts())
 }
+
+func matchMergeGroupEvent(payload *api.MergeGroupPayload, evt *jobparser.Event) bool {
+	// with no special filter parameters
+	if len(evt.Acts()) == 0 {
+		return true
+	}
+
+	matchTimes := 0
+	// all acts conditions should be satisfied
+	for cond, vals := range evt.Acts() {
+		switch cond {
+		case "types":
+			// See https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows#merge_group
+			// Activity types with the same name:
+			// checks_requested
+			for _, val := range vals {
+				if glob.MustCompile(val, '/').Match(string(payload.Action)) {
+					matchTimes++
+					break
+				}
+			}
+		case "branches":
+			refName := git.RefName(payload.MergeGroup.BaseRef)
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Skip(patterns, []string{refName.ShortName()}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		case "branches-ignore":
+			refName := git.RefName(payload.MergeGroup.BaseRef)
+			patterns, err := workflowpattern.CompilePatterns(vals...)
+			if err != nil {
+				break
+			}
+			if !workflowpattern.Filter(patterns, []string{refName.ShortName()}, &workflowpattern.EmptyTraceWriter{}) {
+				matchTimes++
+			}
+		default:
+			log.Warn("merge group event unsupported condition %q", cond)
+		}
+	}
+	return matchTimes == len(evt.Acts())
+}
@@ -111,6 +111,20 @@ func TestDetectMatched(t *testing.T) {
 			yamlOn:       "on:\n  registry_package:\n    types: [updated]",
 			expected:     false,
 		},
+		{
+			desc:         "HookEventMergeGroup(merge_group) `checks_requested` action matches GithubEventMergeGroup(merge_group) with `checks_requested` activity type",
+			triggedEvent: webhook_module.HookEventMergeGroup,
+			payload:      &api.MergeGroupPayload{Action: api.HookMergeGroupChecksRequested, MergeGroup: &api.MergeGroup{BaseRef: "refs/heads/main"}},
+			yamlOn:       "on:\n  merge_group:\n    types: [checks_requested]",
+			expected:     true,
+		},
+		{
+			desc:         "HookEventMergeGroup(merge_group) doesn't match GithubEventMergeGroup(merge_group) with other branches",
+			triggedEvent: webhook_module.HookEventMergeGroup,
+			payload:      &api.MergeGroupPayload{Action: api.HookMergeGroupChecksRequested, MergeGroup: &api.MergeGroup{BaseRef: "refs/heads/main"}},
+			yamlOn:       "on:\n  merge_group:\n    branches: [release/*]",
+			expected:     false,
+		},
 		{
 			desc:         "HookEventWiki(wiki) matches GithubEventGollum(gollum)",
 			triggedEvent: webhook_module.HookEventWiki,
@@ -29,6 +29,8 @@ type Features struct {
 	UsingGogit             bool
 	SupportProcReceive     bool           // >= 2.29
 	SupportHashSha256      bool           // >= 2.42, SHA-256 repositories no longer an ‘experimental curiosity’
+	SupportGeometricRepack bool           // >= 2.34, "git repack --geometric" which writes a multi-pack-index with bitmap
+	SupportCruftPacks      bool           // >= 2.37, "git repack --cruft"
 	SupportedObjectFormats []ObjectFormat // sha1, sha256
 }
 
@@ -73,6 +75,8 @@ func loadGitVersionFeatures() (*Features, error) {
 	features := &Features{gitVersion: ver, UsingGogit: isGogit}
 	features.SupportProcReceive = features.CheckVersionAtLeast("2.29")
 	features.SupportHashSha256 = features.CheckVersionAtLeast("2.42") && !isGogit
+	features.SupportGeometricRepack = features.CheckVersionAtLeast("2.34")
+	features.SupportCruftPacks = features.CheckVersionAtLeast("2.37")
 	features.SupportedObjectFormats = []ObjectFormat{Sha1ObjectFormat}
 	if features.SupportHashSha256 {
 		features.SupportedObjectFormats = append(features.SupportedObjectFormats, Sha256ObjectFormat)
@@ -0,0 +1,78 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UploadPackFilterConfig returns the git config options which restrict the object filters git upload-pack
+// accepts for partial clones. If allowedFilters is nil, partial clones are disabled and the filters of clients
+// are ignored. The options are empty if there is nothing to restrict.
+func UploadPackFilterConfig(allowedFilters []string) map[string]string {
+	// partial clones are disabled globally, see syncGitConfig
+	if setting.Git.DisablePartialClone || !DefaultFeatures().CheckVersionAtLeast("2.22") {
+		return nil
+	}
+
+	if allowedFilters == nil {
+		return map[string]string{"uploadpack.allowfilter": "false"}
+	}
+
+	// the config options can only be passed to a single command since git v2.31
+	if !DefaultFeatures().CheckVersionAtLeast("2.31") {
+		return nil
+	}
+
+	config := make(map[string]string)
+
+	allowsAll := true
+	for _, kind := range setting.PartialCloneFilterKinds {
+		if !slices.Contains(allowedFilters, kind) {
+			allowsAll = false
+			break
+		}
+	}
+	if !allowsAll {
+		config["uploadpackfilter.allow"] = "false"
+		// combined filters are checked for each of the combined kinds
+		config["uploadpackfilter.combine.allow"] = "true"
+		for _, kind := range allowedFilters {
+			config["uploadpackfilter."+kind+".allow"] = "true"
+		}
+	}
+
+	if setting.Git.PartialCloneMaxTreeDepth >= 0 {
+		config["uploadpackfilter.tree.maxdepth"] = strconv.Itoa(setting.Git.PartialCloneMaxTreeDepth)
+	}
+
+	return config
+}
+
+// ConfigEnvs returns the environment variables to pass the config options to a single git command
+func ConfigEnvs(config map[string]string) []string {
+	if len(config) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	envs := make([]string, 0, len(keys)*2+1)
+	envs = append(envs, "GIT_CONFIG_COUNT="+strconv.Itoa(len(keys)))
+	for i, key := range keys {
+		envs = append(envs,
+			"GIT_CONFIG_KEY_"+strconv.Itoa(i)+"="+key,
+			"GIT_CONFIG_VALUE_"+strconv.Itoa(i)+"="+config[key],
+		)
+	}
+	return envs
+}
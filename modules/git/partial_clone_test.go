@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadPackFilterConfig(t *testing.T) {
+	assert.Empty(t, UploadPackFilterConfig(setting.PartialCloneFilterKinds))
+	assert.Equal(t, map[string]string{"uploadpack.allowfilter": "false"}, UploadPackFilterConfig(nil))
+
+	assert.Equal(t, map[string]string{
+		"uploadpackfilter.allow":           "false",
+		"uploadpackfilter.combine.allow":   "true",
+		"uploadpackfilter.blob:none.allow": "true",
+		"uploadpackfilter.tree.allow":      "true",
+	}, UploadPackFilterConfig([]string{"blob:none", "tree"}))
+
+	t.Run("MaxTreeDepth", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.Git.PartialCloneMaxTreeDepth, 0)()
+
+		assert.Equal(t, map[string]string{"uploadpackfilter.tree.maxdepth": "0"}, UploadPackFilterConfig(setting.PartialCloneFilterKinds))
+	})
+
+	t.Run("DisablePartialClone", func(t *testing.T) {
+		defer test.MockVariableValue(&setting.Git.DisablePartialClone, true)()
+
+		assert.Empty(t, UploadPackFilterConfig(nil))
+		assert.Empty(t, UploadPackFilterConfig([]string{"tree"}))
+	})
+}
+
+func TestConfigEnvs(t *testing.T) {
+	assert.Empty(t, ConfigEnvs(nil))
+
+	assert.Equal(t, []string{
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=a.b",
+		"GIT_CONFIG_VALUE_0=1",
+		"GIT_CONFIG_KEY_1=c.d",
+		"GIT_CONFIG_VALUE_1=2",
+	}, ConfigEnvs(map[string]string{"c.d": "2", "a.b": "1"}))
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ObjectsStats represents the statistics of the object database and the references of a repository
+type ObjectsStats struct {
+	LooseObjects     int64 // number of loose objects
+	LooseObjectsSize int64 // disk space consumed by loose objects, in bytes
+	PackedObjects    int64 // number of objects in packs
+	Packs            int64 // number of packs
+	PacksSize        int64 // disk space consumed by packs, in bytes
+	PrunePackable    int64 // number of loose objects which are also present in packs
+	Garbage          int64 // number of files in the object database which are neither valid loose objects nor packs
+	LooseRefs        int64 // number of references which are not in the packed-refs file
+
+	HasCommitGraph    bool
+	HasMultiPackIndex bool
+	HasBitmap         bool
+}
+
+// GetObjectsStats returns the statistics of the object database and the references of a repository
+func GetObjectsStats(ctx context.Context, repoPath string) (*ObjectsStats, error) {
+	stdout, _, runErr := NewCommand(ctx, "count-objects", "-v").RunStdString(&RunOpts{Dir: repoPath})
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	stats, err := parseCountObjects(stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.LooseRefs, err = countLooseRefs(filepath.Join(repoPath, "refs"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"objects/info/commit-graph", "objects/info/commit-graphs/commit-graph-chain"} {
+		if stats.HasCommitGraph, err = util.IsExist(filepath.Join(repoPath, name)); err != nil {
+			return nil, err
+		} else if stats.HasCommitGraph {
+			break
+		}
+	}
+	if stats.HasMultiPackIndex, err = util.IsExist(filepath.Join(repoPath, "objects/pack/multi-pack-index")); err != nil {
+		return nil, err
+	}
+	bitmaps, err := filepath.Glob(filepath.Join(repoPath, "objects/pack/*.bitmap"))
+	if err != nil {
+		return nil, err
+	}
+	stats.HasBitmap = len(bitmaps) > 0
+
+	return stats, nil
+}
+
+func parseCountObjects(stdout string) (*ObjectsStats, error) {
+	stats := &ObjectsStats{}
+
+	scanner := bufio.NewScanner(strings.NewReader(stdout))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count-objects line %q: %w", scanner.Text(), err)
+		}
+		switch key {
+		case "count":
+			stats.LooseObjects = n
+		case "size":
+			stats.LooseObjectsSize = n * 1024
+		case "in-pack":
+			stats.PackedObjects = n
+		case "packs":
+			stats.Packs = n
+		case "size-pack":
+			stats.PacksSize = n * 1024
+		case "prune-packable":
+			stats.PrunePackable = n
+		case "garbage":
+			stats.Garbage = n
+		}
+	}
+	return stats, scanner.Err()
+}
+
+func countLooseRefs(refsPath string) (int64, error) {
+	var count int64
+	err := filepath.WalkDir(refsPath, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// RepackOptions represents the options of a repack
+type RepackOptions struct {
+	// All packs all reachable objects into a single pack. Unreachable objects are kept in a cruft pack
+	// or as loose objects until they are older than UnreachableExpiration.
+	All                   bool
+	UnreachableExpiration time.Duration
+	// Geometric merges the packs so that each pack contains at least Geometric times as many objects
+	// as the next smaller one and writes a multi-pack-index. Requires git >= 2.34.
+	Geometric int
+	Timeout   time.Duration
+}
+
+// Repack packs the loose objects of a repository and combines existing packs
+func Repack(ctx context.Context, repoPath string, opts RepackOptions) error {
+	cmd := NewCommand(ctx, "repack", "-d", "-l")
+	switch {
+	case opts.All:
+		// write a bitmap to speed up clones and fetches
+		cmd.AddArguments("--write-bitmap-index")
+		expiration := time.Now().Add(-opts.UnreachableExpiration).Format(time.RFC3339)
+		if DefaultFeatures().SupportCruftPacks {
+			cmd.AddArguments("--cruft").AddOptionFormat("--cruft-expiration=%s", expiration)
+		} else {
+			cmd.AddArguments("-A").AddOptionFormat("--unpack-unreachable=%s", expiration)
+		}
+		if err := cmd.Run(&RunOpts{Timeout: opts.Timeout, Dir: repoPath}); err != nil {
+			return err
+		}
+		// like "git gc", remove the expired unreachable objects which have been left as loose objects
+		return NewCommand(ctx, "prune").AddOptionFormat("--expire=%s", expiration).
+			Run(&RunOpts{Timeout: opts.Timeout, Dir: repoPath})
+	case opts.Geometric > 1:
+		if !DefaultFeatures().SupportGeometricRepack {
+			return fmt.Errorf("geometric repacking requires git >= 2.34")
+		}
+		cmd.AddOptionFormat("--geometric=%d", opts.Geometric).AddArguments("--write-midx", "--write-bitmap-index")
+	}
+	return cmd.Run(&RunOpts{Timeout: opts.Timeout, Dir: repoPath})
+}
+
+// WriteMultiPackIndex writes a multi-pack-index for all packs of a repository
+func WriteMultiPackIndex(ctx context.Context, repoPath string, timeout time.Duration) error {
+	cmd := NewCommand(ctx, "multi-pack-index", "write")
+	if DefaultFeatures().SupportGeometricRepack {
+		cmd.AddArguments("--bitmap")
+	}
+	return cmd.Run(&RunOpts{Timeout: timeout, Dir: repoPath})
+}
+
+// WriteSplitCommitGraph writes an incremental commit-graph with the reachable commits of a repository
+func WriteSplitCommitGraph(ctx context.Context, repoPath string, timeout time.Duration) error {
+	if !DefaultFeatures().CheckVersionAtLeast("2.27") {
+		return WriteCommitGraph(ctx, repoPath)
+	}
+	return NewCommand(ctx, "commit-graph", "write", "--reachable", "--split", "--changed-paths").
+		Run(&RunOpts{Timeout: timeout, Dir: repoPath})
+}
+
+// PackRefs moves the loose references of a repository into the packed-refs file
+func PackRefs(ctx context.Context, repoPath string, timeout time.Duration) error {
+	return NewCommand(ctx, "pack-refs", "--all").Run(&RunOpts{Timeout: timeout, Dir: repoPath})
+}
@@ -0,0 +1,75 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	stats, err := parseCountObjects(`count: 12
+size: 48
+in-pack: 1034
+packs: 3
+size-pack: 2048
+prune-packable: 2
+garbage: 1
+size-garbage: 4
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, &ObjectsStats{
+		LooseObjects:     12,
+		LooseObjectsSize: 48 * 1024,
+		PackedObjects:    1034,
+		Packs:            3,
+		PacksSize:        2048 * 1024,
+		PrunePackable:    2,
+		Garbage:          1,
+	}, stats)
+
+	_, err = parseCountObjects("count: many\n")
+	assert.Error(t, err)
+}
+
+func TestRepositoryMaintenance(t *testing.T) {
+	repoPath := t.TempDir()
+	assert.NoError(t, Clone(DefaultContext, filepath.Join(testReposDir, "repo1_bare"), repoPath, CloneRepoOptions{Bare: true}))
+
+	stats, err := GetObjectsStats(DefaultContext, repoPath)
+	assert.NoError(t, err)
+	assert.Positive(t, stats.LooseObjects)
+	assert.False(t, stats.HasCommitGraph)
+	assert.False(t, stats.HasBitmap)
+
+	_, _, err = NewCommand(DefaultContext, "update-ref", "refs/heads/loose", "ce064814f4a0d337b333e646ece456cd39fab612").RunStdString(&RunOpts{Dir: repoPath})
+	assert.NoError(t, err)
+	stats, err = GetObjectsStats(DefaultContext, repoPath)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, stats.LooseRefs)
+
+	assert.NoError(t, Repack(DefaultContext, repoPath, RepackOptions{All: true}))
+	assert.NoError(t, WriteSplitCommitGraph(DefaultContext, repoPath, 0))
+	assert.NoError(t, PackRefs(DefaultContext, repoPath, 0))
+
+	stats, err = GetObjectsStats(DefaultContext, repoPath)
+	assert.NoError(t, err)
+	assert.Zero(t, stats.LooseObjects)
+	assert.EqualValues(t, 1, stats.Packs)
+	assert.Positive(t, stats.PackedObjects)
+	assert.Zero(t, stats.LooseRefs)
+	assert.True(t, stats.HasCommitGraph)
+	assert.True(t, stats.HasBitmap)
+
+	if DefaultFeatures().SupportGeometricRepack {
+		assert.NoError(t, Repack(DefaultContext, repoPath, RepackOptions{Geometric: 2}))
+		assert.NoError(t, WriteMultiPackIndex(DefaultContext, repoPath, 0))
+
+		stats, err = GetObjectsStats(DefaultContext, repoPath)
+		assert.NoError(t, err)
+		assert.True(t, stats.HasMultiPackIndex)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	_ "code.gitea.io/gitea/models"
 	_ "code.gitea.io/gitea/models/actions"
 	_ "code.gitea.io/gitea/models/activities"
+	_ "code.gitea.io/gitea/models/audit"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
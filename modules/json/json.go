@@ -40,6 +40,9 @@ var (
 	_ Interface = JSONiter{}
 )
 
+// RawMessage is a raw encoded JSON value, its decoding is delayed
+type RawMessage = json.RawMessage
+
 // StdJSON implements Interface via encoding/json
 type StdJSON struct{}
 
@@ -8,6 +8,7 @@ import (
 
 	activities_model "code.gitea.io/gitea/models/activities"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -40,6 +41,10 @@ type Collector struct {
 	PublicKeys         *prometheus.Desc
 	Releases           *prometheus.Desc
 	Repositories       *prometheus.Desc
+	RepoHousekeeping   *prometheus.Desc
+	RepoLooseObjects   *prometheus.Desc
+	RepoPacks          *prometheus.Desc
+	RepoPushes         *prometheus.Desc
 	Stars              *prometheus.Desc
 	Teams              *prometheus.Desc
 	UpdateTasks        *prometheus.Desc
@@ -166,6 +171,26 @@ func NewCollector() Collector {
 			"Number of Repositories",
 			nil, nil,
 		),
+		RepoHousekeeping: prometheus.NewDesc(
+			namespace+"repo_housekeeping",
+			"Number of Repositories by housekeeping status",
+			[]string{"status"}, nil,
+		),
+		RepoLooseObjects: prometheus.NewDesc(
+			namespace+"repo_housekeeping_loose_objects",
+			"Number of loose objects at the last housekeeping",
+			[]string{"repository"}, nil,
+		),
+		RepoPacks: prometheus.NewDesc(
+			namespace+"repo_housekeeping_packs",
+			"Number of packs at the last housekeeping",
+			[]string{"repository"}, nil,
+		),
+		RepoPushes: prometheus.NewDesc(
+			namespace+"repo_housekeeping_pushes",
+			"Number of pushes since the last housekeeping",
+			[]string{"repository"}, nil,
+		),
 		Stars: prometheus.NewDesc(
 			namespace+"stars",
 			"Number of Stars",
@@ -223,6 +248,10 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.PublicKeys
 	ch <- c.Releases
 	ch <- c.Repositories
+	ch <- c.RepoHousekeeping
+	ch <- c.RepoLooseObjects
+	ch <- c.RepoPacks
+	ch <- c.RepoPushes
 	ch <- c.Stars
 	ch <- c.Teams
 	ch <- c.UpdateTasks
@@ -355,6 +384,35 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Repo),
 	)
+	for _, status := range repo_model.HousekeepingStatuses {
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoHousekeeping,
+			prometheus.GaugeValue,
+			float64(stats.Counter.RepoHousekeepingByStatus[status]),
+			status.String(),
+		)
+	}
+	for _, rh := range stats.Counter.RepoHousekeepingByRepository {
+		repository := rh.OwnerName + "/" + rh.Repository
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoLooseObjects,
+			prometheus.GaugeValue,
+			float64(rh.LooseObjects),
+			repository,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoPacks,
+			prometheus.GaugeValue,
+			float64(rh.Packs),
+			repository,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.RepoPushes,
+			prometheus.GaugeValue,
+			float64(rh.PushesSinceCheck),
+			repository,
+		)
+	}
 	ch <- prometheus.MustNewConstMetric(
 		c.Stars,
 		prometheus.GaugeValue,
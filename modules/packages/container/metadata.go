@@ -21,6 +21,8 @@ const (
 	PropertyMediaType         = "container.mediatype"
 	PropertyManifestTagged    = "container.manifest.tagged"
 	PropertyManifestReference = "container.manifest.reference"
+	PropertyManifestSubject   = "container.manifest.subject"
+	PropertyArtifactType      = "container.artifacttype"
 
 	DefaultPlatform = "linux/amd64"
 
@@ -63,6 +65,44 @@ type Metadata struct {
 	Labels           map[string]string `json:"labels,omitempty"`
 	ImageLayers      []string          `json:"layer_creation,omitempty"`
 	Manifests        []*Manifest       `json:"manifests,omitempty"`
+	Subject          string            `json:"subject,omitempty"`
+	ArtifactType     string            `json:"artifact_type,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
+
+// ArtifactKind gets the kind of the artifact described by the artifact type
+func (m *Metadata) ArtifactKind() ArtifactKind {
+	return GetArtifactKind(m.ArtifactType)
+}
+
+type ArtifactKind string
+
+const (
+	ArtifactKindSignature   ArtifactKind = "signature"
+	ArtifactKindSBOM        ArtifactKind = "sbom"
+	ArtifactKindAttestation ArtifactKind = "attestation"
+	ArtifactKindOther       ArtifactKind = "other"
+)
+
+// GetArtifactKind gets the kind of the artifact of the artifact type.
+// Only the artifact types of the common signing and SBOM tools are recognized.
+func GetArtifactKind(artifactType string) ArtifactKind {
+	artifactType = strings.ToLower(artifactType)
+	switch {
+	case artifactType == "application/vnd.dev.cosign.artifact.sig.v1+json",
+		artifactType == "application/vnd.cncf.notary.signature",
+		strings.HasPrefix(artifactType, "application/vnd.dev.sigstore.bundle"):
+		return ArtifactKindSignature
+	case strings.Contains(artifactType, "spdx"),
+		strings.Contains(artifactType, "cyclonedx"),
+		strings.Contains(artifactType, "syft"):
+		return ArtifactKindSBOM
+	case strings.HasPrefix(artifactType, "application/vnd.in-toto"),
+		strings.HasPrefix(artifactType, "application/vnd.dsse.envelope"):
+		return ArtifactKindAttestation
+	default:
+		return ArtifactKindOther
+	}
 }
 
 type Manifest struct {
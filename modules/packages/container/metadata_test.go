@@ -59,3 +59,19 @@ func TestParseImageConfig(t *testing.T) {
 	assert.Equal(t, projectURL, metadata.ProjectURL)
 	assert.Equal(t, repositoryURL, metadata.RepositoryURL)
 }
+
+func TestGetArtifactKind(t *testing.T) {
+	cases := map[string]ArtifactKind{
+		"application/vnd.dev.cosign.artifact.sig.v1+json": ArtifactKindSignature,
+		"application/vnd.cncf.notary.signature":           ArtifactKindSignature,
+		"application/vnd.dev.sigstore.bundle.v0.3+json":   ArtifactKindSignature,
+		"application/spdx+json":                           ArtifactKindSBOM,
+		"application/vnd.cyclonedx+json":                  ArtifactKindSBOM,
+		"application/vnd.in-toto+json":                    ArtifactKindAttestation,
+		"application/vnd.oci.image.config.v1+json":        ArtifactKindOther,
+		"": ArtifactKindOther,
+	}
+	for artifactType, kind := range cases {
+		assert.Equal(t, kind, GetArtifactKind(artifactType), artifactType)
+	}
+}
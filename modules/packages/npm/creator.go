@@ -8,6 +8,7 @@ import (
 	"crypto/sha1"
 	"crypto/sha512"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"regexp"
@@ -165,6 +166,27 @@ type Repository struct {
 	URL  string `json:"url"`
 }
 
+// UnmarshalJSON is needed because Repository objects can be strings or objects
+func (r *Repository) UnmarshalJSON(data []byte) error {
+	switch data[0] {
+	case '"':
+		if err := json.Unmarshal(data, &r.URL); err != nil {
+			return err
+		}
+	case '{':
+		var tmp struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		}
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return err
+		}
+		r.Type = tmp.Type
+		r.URL = tmp.URL
+	}
+	return nil
+}
+
 // PackageAttachment https://github.com/npm/registry/blob/master/docs/REGISTRY-API.md#package
 type PackageAttachment struct {
 	ContentType string `json:"content_type"`
@@ -185,56 +207,15 @@ func ParsePackage(r io.Reader) (*Package, error) {
 	}
 
 	for _, meta := range upload.Versions {
-		if !validateName(meta.Name) {
-			return nil, ErrInvalidPackageName
-		}
-
-		v, err := version.NewSemver(meta.Version)
+		p, err := createPackage(meta)
 		if err != nil {
-			return nil, ErrInvalidPackageVersion
-		}
-
-		scope := ""
-		name := meta.Name
-		nameParts := strings.SplitN(meta.Name, "/", 2)
-		if len(nameParts) == 2 {
-			scope = nameParts[0]
-			name = nameParts[1]
-		}
-
-		if !validation.IsValidURL(meta.Homepage) {
-			meta.Homepage = ""
-		}
-
-		p := &Package{
-			Name:     meta.Name,
-			Version:  v.String(),
-			DistTags: make([]string, 0, 1),
-			Metadata: Metadata{
-				Scope:                   scope,
-				Name:                    name,
-				Description:             meta.Description,
-				Author:                  meta.Author.Name,
-				License:                 meta.License,
-				ProjectURL:              meta.Homepage,
-				Keywords:                meta.Keywords,
-				Dependencies:            meta.Dependencies,
-				BundleDependencies:      meta.BundleDependencies,
-				DevelopmentDependencies: meta.DevDependencies,
-				PeerDependencies:        meta.PeerDependencies,
-				OptionalDependencies:    meta.OptionalDependencies,
-				Bin:                     meta.Bin,
-				Readme:                  meta.Readme,
-				Repository:              meta.Repository,
-			},
+			return nil, err
 		}
 
 		for tag := range upload.DistTags {
 			p.DistTags = append(p.DistTags, tag)
 		}
 
-		p.Filename = strings.ToLower(fmt.Sprintf("%s-%s.tgz", name, p.Version))
-
 		attachment := func() *PackageAttachment {
 			for _, a := range upload.Attachments {
 				return a
@@ -251,31 +232,115 @@ func ParsePackage(r io.Reader) (*Package, error) {
 		}
 		p.Data = data
 
-		integrity := strings.SplitN(meta.Dist.Integrity, "-", 2)
-		if len(integrity) != 2 {
-			return nil, ErrInvalidIntegrity
+		if err := validateIntegrity(meta.Dist.Integrity, data); err != nil {
+			return nil, err
 		}
-		integrityHash, err := base64.StdEncoding.DecodeString(integrity[1])
-		if err != nil {
-			return nil, ErrInvalidIntegrity
-		}
-		var hash []byte
-		switch integrity[0] {
-		case "sha1":
-			tmp := sha1.Sum(data)
-			hash = tmp[:]
-		case "sha512":
-			tmp := sha512.Sum512(data)
-			hash = tmp[:]
+
+		return p, nil
+	}
+
+	return nil, ErrInvalidPackage
+}
+
+// ParseRemotePackageVersion parses the version metadata and the tarball fetched from a remote registry into a npm package
+func ParseRemotePackageVersion(meta *PackageMetadataVersion, data []byte) (*Package, error) {
+	p, err := createPackage(meta)
+	if err != nil {
+		return nil, err
+	}
+	p.Data = data
+
+	if meta.Dist.Integrity != "" {
+		if err := validateIntegrity(meta.Dist.Integrity, data); err != nil {
+			return nil, err
 		}
-		if !bytes.Equal(integrityHash, hash) {
+	} else {
+		hash := sha1.Sum(data)
+		if !strings.EqualFold(meta.Dist.Shasum, hex.EncodeToString(hash[:])) {
 			return nil, ErrInvalidIntegrity
 		}
+	}
 
-		return p, nil
+	return p, nil
+}
+
+// TarballFilename returns the name of the tarball of the package version
+func TarballFilename(packageName, packageVersion string) string {
+	if _, name, ok := strings.Cut(packageName, "/"); ok {
+		packageName = name
 	}
+	return strings.ToLower(fmt.Sprintf("%s-%s.tgz", packageName, packageVersion))
+}
 
-	return nil, ErrInvalidPackage
+func createPackage(meta *PackageMetadataVersion) (*Package, error) {
+	if !validateName(meta.Name) {
+		return nil, ErrInvalidPackageName
+	}
+
+	v, err := version.NewSemver(meta.Version)
+	if err != nil {
+		return nil, ErrInvalidPackageVersion
+	}
+
+	scope := ""
+	name := meta.Name
+	nameParts := strings.SplitN(meta.Name, "/", 2)
+	if len(nameParts) == 2 {
+		scope = nameParts[0]
+		name = nameParts[1]
+	}
+
+	if !validation.IsValidURL(meta.Homepage) {
+		meta.Homepage = ""
+	}
+
+	return &Package{
+		Name:     meta.Name,
+		Version:  v.String(),
+		DistTags: make([]string, 0, 1),
+		Metadata: Metadata{
+			Scope:                   scope,
+			Name:                    name,
+			Description:             meta.Description,
+			Author:                  meta.Author.Name,
+			License:                 meta.License,
+			ProjectURL:              meta.Homepage,
+			Keywords:                meta.Keywords,
+			Dependencies:            meta.Dependencies,
+			BundleDependencies:      meta.BundleDependencies,
+			DevelopmentDependencies: meta.DevDependencies,
+			PeerDependencies:        meta.PeerDependencies,
+			OptionalDependencies:    meta.OptionalDependencies,
+			Bin:                     meta.Bin,
+			Readme:                  meta.Readme,
+			Repository:              meta.Repository,
+		},
+		Filename: TarballFilename(meta.Name, v.String()),
+	}, nil
+}
+
+func validateIntegrity(integrity string, data []byte) error {
+	parts := strings.SplitN(integrity, "-", 2)
+	if len(parts) != 2 {
+		return ErrInvalidIntegrity
+	}
+	integrityHash, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrInvalidIntegrity
+	}
+	var hash []byte
+	switch parts[0] {
+	case "sha1":
+		tmp := sha1.Sum(data)
+		hash = tmp[:]
+	case "sha512":
+		tmp := sha512.Sum512(data)
+		hash = tmp[:]
+	}
+	if !bytes.Equal(integrityHash, hash) {
+		return ErrInvalidIntegrity
+	}
+	return nil
 }
 
 func validateName(name string) bool {
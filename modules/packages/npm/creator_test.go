@@ -299,3 +299,46 @@ func TestParsePackage(t *testing.T) {
 		assert.Equal(t, repository.URL, p.Metadata.Repository.URL)
 	})
 }
+
+func TestParseRemotePackageVersion(t *testing.T) {
+	data := []byte("test data")
+	shasum := "f48dd853820860816c75d54d0f584dc863327a7c"
+
+	t.Run("InvalidShasum", func(t *testing.T) {
+		p, err := ParseRemotePackageVersion(&PackageMetadataVersion{
+			Name:    "@scope/test-package",
+			Version: "1.0.0",
+			Dist:    PackageDistribution{Shasum: "0000"},
+		}, data)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidIntegrity)
+	})
+
+	t.Run("InvalidIntegrity", func(t *testing.T) {
+		p, err := ParseRemotePackageVersion(&PackageMetadataVersion{
+			Name:    "@scope/test-package",
+			Version: "1.0.0",
+			Dist:    PackageDistribution{Integrity: "sha512-test==", Shasum: shasum},
+		}, data)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidIntegrity)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParseRemotePackageVersion(&PackageMetadataVersion{
+			Name:    "@scope/test-package",
+			Version: "1.0.0",
+			Dist:    PackageDistribution{Shasum: shasum},
+		}, data)
+		assert.NoError(t, err)
+		assert.Equal(t, "@scope/test-package", p.Name)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, "test-package-1.0.0.tgz", p.Filename)
+		assert.Equal(t, data, p.Data)
+	})
+}
+
+func TestTarballFilename(t *testing.T) {
+	assert.Equal(t, "test-package-1.0.0.tgz", TarballFilename("test-package", "1.0.0"))
+	assert.Equal(t, "test-package-1.0.0-rc.1.tgz", TarballFilename("@Scope/Test-Package", "1.0.0-RC.1"))
+}
@@ -0,0 +1,368 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/hashicorp/go-version"
+	"github.com/keybase/go-crypto/openpgp"
+)
+
+const (
+	PropertyOS         = "terraform.os"
+	PropertyArch       = "terraform.arch"
+	PropertySigningKey = "terraform.signing_key"
+
+	// DefaultProtocol is the plugin protocol version used if the provider release has no manifest
+	DefaultProtocol = "5.0"
+
+	maxReadmeSize        = 1 * 1024 * 1024
+	maxConfigurationSize = 4 * 1024 * 1024
+)
+
+var (
+	ErrInvalidName             = util.NewInvalidArgumentErrorf("package name is invalid")
+	ErrInvalidVersion          = util.NewInvalidArgumentErrorf("package version is invalid")
+	ErrInvalidFilename         = util.NewInvalidArgumentErrorf("package filename is invalid")
+	ErrMissingConfiguration    = util.NewInvalidArgumentErrorf("module archive contains no Terraform configuration files")
+	ErrConfigurationTooLarge   = util.NewInvalidArgumentErrorf("Terraform configuration file is too large")
+	ErrInvalidChecksums        = util.NewInvalidArgumentErrorf("checksums file is invalid")
+	ErrInvalidProviderManifest = util.NewInvalidArgumentErrorf("provider manifest is invalid")
+	ErrInvalidSignature        = util.NewInvalidArgumentErrorf("signature of the checksums file is invalid")
+)
+
+var (
+	// https://developer.hashicorp.com/terraform/registry/modules/publish#requirements
+	moduleNamePattern   = regexp.MustCompile(`\A[0-9a-z](?:[0-9a-z_-]{0,62}[0-9a-z])?\z`)
+	moduleSystemPattern = regexp.MustCompile(`\A[0-9a-z]{1,64}\z`)
+	providerTypePattern = regexp.MustCompile(`\A[0-9a-z](?:[0-9a-z-]{0,62}[0-9a-z])?\z`)
+	platformPartPattern = regexp.MustCompile(`\A[0-9a-z]{1,32}\z`)
+	checksumPattern     = regexp.MustCompile(`\A[0-9a-f]{64}\z`)
+
+	blockPattern       = regexp.MustCompile(`(?m)^\s*(variable|output)\s+"([^"]+)"\s*\{`)
+	descriptionPattern = regexp.MustCompile(`(?m)^\s*description\s*=\s*("(?:[^"\\\n]|\\.)*")`)
+	defaultPattern     = regexp.MustCompile(`(?m)^\s*default\s*=`)
+)
+
+type Kind string
+
+const (
+	KindModule   Kind = "module"
+	KindProvider Kind = "provider"
+)
+
+// Metadata represents the metadata of a Terraform module or provider version
+type Metadata struct {
+	Kind      Kind        `json:"kind"`
+	Readme    string      `json:"readme,omitempty"`
+	Variables []*Variable `json:"variables,omitempty"`
+	Outputs   []*Output   `json:"outputs,omitempty"`
+	Protocols []string    `json:"protocols,omitempty"`
+}
+
+// Variable is an input variable of a module
+type Variable struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// Output is an output value of a module
+type Output struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsValidModuleName checks if the name and the target system of a module are valid
+func IsValidModuleName(name, system string) bool {
+	return moduleNamePattern.MatchString(name) && moduleSystemPattern.MatchString(system)
+}
+
+// IsValidProviderType checks if the type of a provider is valid
+func IsValidProviderType(providerType string) bool {
+	return providerTypePattern.MatchString(providerType)
+}
+
+// IsValidVersion checks if the version is a valid semantic version
+func IsValidVersion(v string) bool {
+	_, err := version.NewSemver(v)
+	return err == nil
+}
+
+// ModulePackageName gets the package name of a module
+func ModulePackageName(name, system string) string {
+	return name + "/" + system
+}
+
+// ModuleFilename gets the filename of the archive of a module version
+func ModuleFilename(name, system, version string) string {
+	return fmt.Sprintf("%s-%s-%s.tar.gz", name, system, version)
+}
+
+// ParseModuleArchive parses a gzipped tar archive of a module to retrieve its readme, variables and outputs
+func ParseModuleArchive(r io.Reader) (*Metadata, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	m := &Metadata{
+		Kind: KindModule,
+	}
+
+	hasConfiguration := false
+
+	tr := tar.NewReader(gzr)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// only the files of the root module are parsed
+		name := path.Clean(hd.Name)
+		if strings.Contains(name, "/") {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(name, "README.md"):
+			readme, err := io.ReadAll(io.LimitReader(tr, maxReadmeSize))
+			if err != nil {
+				return nil, err
+			}
+			m.Readme = string(readme)
+		case strings.HasSuffix(name, ".tf"):
+			hasConfiguration = true
+
+			if hd.Size > maxConfigurationSize {
+				return nil, ErrConfigurationTooLarge
+			}
+			content, err := io.ReadAll(io.LimitReader(tr, maxConfigurationSize))
+			if err != nil {
+				return nil, err
+			}
+			parseConfiguration(m, string(content))
+		case strings.HasSuffix(name, ".tf.json"):
+			hasConfiguration = true
+		}
+	}
+
+	if !hasConfiguration {
+		return nil, ErrMissingConfiguration
+	}
+
+	return m, nil
+}
+
+// parseConfiguration extracts the variable and output blocks of the configuration.
+// It's no complete HCL parser but sufficient for the common formatting of these blocks.
+func parseConfiguration(m *Metadata, content string) {
+	for _, match := range blockPattern.FindAllStringSubmatchIndex(content, -1) {
+		blockType := content[match[2]:match[3]]
+		name := content[match[4]:match[5]]
+		body := blockBody(content[match[1]:])
+
+		description := ""
+		if dm := descriptionPattern.FindStringSubmatch(body); dm != nil {
+			if s, err := strconv.Unquote(dm[1]); err == nil {
+				description = s
+			}
+		}
+
+		if blockType == "variable" {
+			m.Variables = append(m.Variables, &Variable{
+				Name:        name,
+				Description: description,
+				Required:    !defaultPattern.MatchString(body),
+			})
+		} else {
+			m.Outputs = append(m.Outputs, &Output{
+				Name:        name,
+				Description: description,
+			})
+		}
+	}
+}
+
+// blockBody returns the content until the brace which closes the block, braces in strings and comments are ignored
+func blockBody(content string) string {
+	depth := 1
+	inString := false
+	inComment := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case inComment:
+			if c == '\n' {
+				inComment = false
+			}
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '#' || (c == '/' && i+1 < len(content) && content[i+1] == '/'):
+			inComment = true
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return content[:i]
+			}
+		}
+	}
+	return content
+}
+
+type ProviderFileType string
+
+const (
+	ProviderFileArchive   ProviderFileType = "archive"
+	ProviderFileChecksums ProviderFileType = "checksums"
+	ProviderFileSignature ProviderFileType = "signature"
+	ProviderFileManifest  ProviderFileType = "manifest"
+)
+
+// ProviderFile describes a file of a provider release
+type ProviderFile struct {
+	Type ProviderFileType
+	OS   string
+	Arch string
+}
+
+// ProviderFilePrefix gets the common prefix of the files of a provider release
+func ProviderFilePrefix(providerType, version string) string {
+	return fmt.Sprintf("terraform-provider-%s_%s_", providerType, version)
+}
+
+// ParseProviderFilename parses the filename of a file of a provider release.
+// https://developer.hashicorp.com/terraform/registry/providers/publishing#manually-preparing-a-release
+func ParseProviderFilename(providerType, version, filename string) (*ProviderFile, error) {
+	suffix, ok := strings.CutPrefix(filename, ProviderFilePrefix(providerType, version))
+	if !ok {
+		return nil, ErrInvalidFilename
+	}
+
+	switch suffix {
+	case "SHA256SUMS":
+		return &ProviderFile{Type: ProviderFileChecksums}, nil
+	case "SHA256SUMS.sig":
+		return &ProviderFile{Type: ProviderFileSignature}, nil
+	case "manifest.json":
+		return &ProviderFile{Type: ProviderFileManifest}, nil
+	}
+
+	platform, ok := strings.CutSuffix(suffix, ".zip")
+	if !ok {
+		return nil, ErrInvalidFilename
+	}
+	os, arch, ok := strings.Cut(platform, "_")
+	if !ok || !platformPartPattern.MatchString(os) || !platformPartPattern.MatchString(arch) {
+		return nil, ErrInvalidFilename
+	}
+
+	return &ProviderFile{
+		Type: ProviderFileArchive,
+		OS:   os,
+		Arch: arch,
+	}, nil
+}
+
+// ParseChecksums parses a SHA256SUMS file and returns the checksums by filename
+func ParseChecksums(r io.Reader) (map[string]string, error) {
+	checksums := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !checksumPattern.MatchString(fields[0]) {
+			return nil, ErrInvalidChecksums
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil {
+			return nil, ErrInvalidChecksums
+		}
+
+		checksums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(checksums) == 0 {
+		return nil, ErrInvalidChecksums
+	}
+
+	return checksums, nil
+}
+
+// ParseProviderManifest parses the manifest of a provider release to retrieve the supported plugin protocol versions
+func ParseProviderManifest(r io.Reader) ([]string, error) {
+	var manifest struct {
+		Version  int `json:"version"`
+		Metadata struct {
+			ProtocolVersions []string `json:"protocol_versions"`
+		} `json:"metadata"`
+	}
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, ErrInvalidProviderManifest
+	}
+
+	if manifest.Version != 1 || len(manifest.Metadata.ProtocolVersions) == 0 {
+		return nil, ErrInvalidProviderManifest
+	}
+	for _, p := range manifest.Metadata.ProtocolVersions {
+		if _, err := version.NewVersion(p); err != nil {
+			return nil, ErrInvalidProviderManifest
+		}
+	}
+
+	return manifest.Metadata.ProtocolVersions, nil
+}
+
+// VerifyChecksumsSignature verifies the detached signature of the checksums file and returns the signing key.
+// The signature may be binary or ASCII armored.
+func VerifyChecksumsSignature(keyring openpgp.EntityList, checksums io.Reader, signature []byte) (*openpgp.Entity, error) {
+	var signer *openpgp.Entity
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(string(signature)), "-----BEGIN") {
+		signer, err = openpgp.CheckArmoredDetachedSignature(keyring, checksums, strings.NewReader(string(signature)))
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(keyring, checksums, strings.NewReader(string(signature)))
+	}
+	if err != nil || signer == nil {
+		return nil, ErrInvalidSignature
+	}
+	return signer, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidNames(t *testing.T) {
+	assert.True(t, IsValidModuleName("vpc", "aws"))
+	assert.True(t, IsValidModuleName("my_network-module", "azurerm"))
+	assert.False(t, IsValidModuleName("Vpc", "aws"))
+	assert.False(t, IsValidModuleName("-vpc", "aws"))
+	assert.False(t, IsValidModuleName("vpc", "aws-2"))
+	assert.False(t, IsValidModuleName("vpc", ""))
+
+	assert.True(t, IsValidProviderType("internal-dns"))
+	assert.False(t, IsValidProviderType("internal_dns"))
+	assert.False(t, IsValidProviderType("dns-"))
+
+	assert.True(t, IsValidVersion("1.2.3"))
+	assert.True(t, IsValidVersion("1.0.0-beta.1"))
+	assert.False(t, IsValidVersion("latest"))
+}
+
+func TestParseModuleArchive(t *testing.T) {
+	createArchive := func(files map[string]string) io.Reader {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for filename, content := range files {
+			hdr := &tar.Header{
+				Name: filename,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			}
+			tw.WriteHeader(hdr)
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		zw.Close()
+		return &buf
+	}
+
+	t.Run("MissingConfiguration", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			"README.md":         "# Module",
+			"modules/sub/a.tf":  `variable "a" {}`,
+			"examples/basic.md": "",
+		})
+
+		metadata, err := ParseModuleArchive(data)
+		assert.Nil(t, metadata)
+		assert.ErrorIs(t, err, ErrMissingConfiguration)
+	})
+
+	t.Run("ConfigurationTooLarge", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			"main.tf": strings.Repeat("#", maxConfigurationSize+1),
+		})
+
+		metadata, err := ParseModuleArchive(data)
+		assert.Nil(t, metadata)
+		assert.ErrorIs(t, err, ErrConfigurationTooLarge)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			"./README.md": "# VPC Module",
+			"variables.tf": `
+variable "name" {
+  description = "Name of the \"VPC\""
+  type        = string
+}
+
+# variable "commented" {}
+
+variable "cidr" {
+  type = string
+  default = "10.0.0.0/16"
+  validation {
+    condition     = can(cidrhost(var.cidr, 0))
+    error_message = "Must be a valid CIDR block like {a}."
+  }
+}
+`,
+			"outputs.tf": `output "vpc_id" {
+  description = "ID of the VPC"
+  value       = aws_vpc.this.id
+}`,
+			"modules/sub/variables.tf": `variable "ignored" {}`,
+		})
+
+		metadata, err := ParseModuleArchive(data)
+		assert.NoError(t, err)
+		assert.NotNil(t, metadata)
+
+		assert.Equal(t, KindModule, metadata.Kind)
+		assert.Equal(t, "# VPC Module", metadata.Readme)
+		assert.Len(t, metadata.Variables, 2)
+		assert.Equal(t, "name", metadata.Variables[0].Name)
+		assert.Equal(t, `Name of the "VPC"`, metadata.Variables[0].Description)
+		assert.True(t, metadata.Variables[0].Required)
+		assert.Equal(t, "cidr", metadata.Variables[1].Name)
+		assert.Empty(t, metadata.Variables[1].Description)
+		assert.False(t, metadata.Variables[1].Required)
+		assert.Len(t, metadata.Outputs, 1)
+		assert.Equal(t, "vpc_id", metadata.Outputs[0].Name)
+		assert.Equal(t, "ID of the VPC", metadata.Outputs[0].Description)
+	})
+}
+
+func TestParseProviderFilename(t *testing.T) {
+	cases := []struct {
+		Filename string
+		Expected *ProviderFile
+	}{
+		{"terraform-provider-dns_1.0.0_linux_amd64.zip", &ProviderFile{Type: ProviderFileArchive, OS: "linux", Arch: "amd64"}},
+		{"terraform-provider-dns_1.0.0_SHA256SUMS", &ProviderFile{Type: ProviderFileChecksums}},
+		{"terraform-provider-dns_1.0.0_SHA256SUMS.sig", &ProviderFile{Type: ProviderFileSignature}},
+		{"terraform-provider-dns_1.0.0_manifest.json", &ProviderFile{Type: ProviderFileManifest}},
+		{"terraform-provider-dns_1.0.1_linux_amd64.zip", nil},
+		{"terraform-provider-other_1.0.0_linux_amd64.zip", nil},
+		{"terraform-provider-dns_1.0.0_linux.zip", nil},
+		{"terraform-provider-dns_1.0.0_linux_amd64.tar.gz", nil},
+		{"terraform-provider-dns_1.0.0_Linux_amd64.zip", nil},
+	}
+
+	for _, c := range cases {
+		pf, err := ParseProviderFilename("dns", "1.0.0", c.Filename)
+		if c.Expected == nil {
+			assert.ErrorIs(t, err, ErrInvalidFilename, c.Filename)
+		} else {
+			assert.NoError(t, err, c.Filename)
+			assert.Equal(t, c.Expected, pf, c.Filename)
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksum := strings.Repeat("ab", 32)
+
+	checksums, err := ParseChecksums(strings.NewReader(checksum + "  terraform-provider-dns_1.0.0_linux_amd64.zip\n" + checksum + " *terraform-provider-dns_1.0.0_darwin_arm64.zip\n\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"terraform-provider-dns_1.0.0_linux_amd64.zip":  checksum,
+		"terraform-provider-dns_1.0.0_darwin_arm64.zip": checksum,
+	}, checksums)
+
+	for _, content := range []string{"", "invalid", "abc  file.zip", checksum + "  a b"} {
+		_, err := ParseChecksums(strings.NewReader(content))
+		assert.ErrorIs(t, err, ErrInvalidChecksums, content)
+	}
+}
+
+func TestParseProviderManifest(t *testing.T) {
+	protocols, err := ParseProviderManifest(strings.NewReader(`{"version":1,"metadata":{"protocol_versions":["5.0","6.0"]}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5.0", "6.0"}, protocols)
+
+	for _, content := range []string{"", `{"version":2,"metadata":{"protocol_versions":["6.0"]}}`, `{"version":1,"metadata":{}}`, `{"version":1,"metadata":{"protocol_versions":["x"]}}`} {
+		_, err := ParseProviderManifest(strings.NewReader(content))
+		assert.ErrorIs(t, err, ErrInvalidProviderManifest, content)
+	}
+}
+
+func TestVerifyChecksumsSignature(t *testing.T) {
+	entity, err := openpgp.NewEntity("Gitea", "", "gitea@example.com", nil)
+	assert.NoError(t, err)
+	other, err := openpgp.NewEntity("Other", "", "other@example.com", nil)
+	assert.NoError(t, err)
+
+	content := "checksums"
+
+	var signature bytes.Buffer
+	assert.NoError(t, openpgp.DetachSign(&signature, entity, strings.NewReader(content), nil))
+	var armoredSignature bytes.Buffer
+	assert.NoError(t, openpgp.ArmoredDetachSign(&armoredSignature, entity, strings.NewReader(content), nil))
+
+	for _, sig := range [][]byte{signature.Bytes(), armoredSignature.Bytes()} {
+		signer, err := VerifyChecksumsSignature(openpgp.EntityList{other, entity}, strings.NewReader(content), sig)
+		assert.NoError(t, err)
+		assert.Equal(t, entity.PrimaryKey.KeyId, signer.PrimaryKey.KeyId)
+
+		_, err = VerifyChecksumsSignature(openpgp.EntityList{other}, strings.NewReader(content), sig)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+
+		_, err = VerifyChecksumsSignature(openpgp.EntityList{entity}, strings.NewReader("modified"), sig)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	}
+}
@@ -43,6 +43,8 @@ type ServCommandResults struct {
 	OwnerName   string
 	RepoName    string
 	RepoID      int64
+
+	UploadPackConfig map[string]string // git config options for git-upload-pack
 }
 
 // ServCommand preps for a serv call
@@ -19,6 +19,9 @@ type Manager struct {
 
 	qidCounter int64
 	Queues     map[int64]ManagedWorkerPoolQueue
+
+	// resumeChan is closed when the paused queues are resumed, it's nil while the queues aren't paused
+	resumeChan chan struct{}
 }
 
 type ManagedWorkerPoolQueue interface {
@@ -76,6 +79,37 @@ func (m *Manager) ManagedQueues() map[int64]ManagedWorkerPoolQueue {
 	return queues
 }
 
+// Pause stops the workers of all managed queues from handling items. The items can still be pushed,
+// they are kept in the queues and handled once the queues are resumed.
+func (m *Manager) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resumeChan == nil {
+		m.resumeChan = make(chan struct{})
+	}
+}
+
+// Resume makes the workers of the paused queues handle the items again
+func (m *Manager) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resumeChan != nil {
+		close(m.resumeChan)
+		m.resumeChan = nil
+	}
+}
+
+// IsPaused returns whether the queues are paused
+func (m *Manager) IsPaused() bool {
+	return m.getResumeChan() != nil
+}
+
+func (m *Manager) getResumeChan() chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.resumeChan
+}
+
 // FlushAll tries to make all managed queues process all items synchronously, until timeout or the queue is empty.
 // It is for testing purpose only. It's not designed to be used in a cluster.
 // Negative timeout means discarding all items in the queue.
@@ -90,6 +90,18 @@ func (q *WorkerPoolQueue[T]) doDispatchBatchToWorker(wg *workerGroup[T], flushCh
 // doWorkerHandle calls the safeHandler to handle a batch of items, and it increases/decreases the active worker number.
 // If the context has been canceled, it should not be caller because the "Push" still needs the context, in such case, call q.safeHandler directly
 func (q *WorkerPoolQueue[T]) doWorkerHandle(batch []T) {
+	// the worker holds the batch while the queues are paused, flushing (for testing only) doesn't wait
+	if resumeChan := GetManager().getResumeChan(); resumeChan != nil && !q.isFlushing.Load() {
+		select {
+		case <-resumeChan:
+		case <-q.ctxRun.Done():
+			if !q.basePushForShutdown(batch...) {
+				q.safeHandler(batch...)
+			}
+			return
+		}
+	}
+
 	q.workerNumMu.Lock()
 	q.workerActiveNum++
 	q.workerNumMu.Unlock()
@@ -218,6 +218,32 @@ func TestWorkerPoolQueueActiveWorkers(t *testing.T) {
 	stop()
 }
 
+func TestWorkerPoolQueuePause(t *testing.T) {
+	var handled atomic.Int32
+	handler := func(items ...int) (unhandled []int) {
+		handled.Add(int32(len(items)))
+		return nil
+	}
+
+	GetManager().Pause()
+	defer GetManager().Resume()
+	assert.True(t, GetManager().IsPaused())
+
+	q, _ := newWorkerPoolQueueForTest("test-workpoolqueue", setting.QueueSettings{Type: "channel", BatchLength: 1, MaxWorkers: 1, Length: 100}, handler, false)
+	stop := runWorkerPoolQueue(q)
+	defer stop()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, q.Push(i))
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	assert.EqualValues(t, 0, handled.Load())
+
+	GetManager().Resume()
+	assert.False(t, GetManager().IsPaused())
+	assert.Eventually(t, func() bool { return handled.Load() == 5 }, 2*time.Second, 10*time.Millisecond)
+}
+
 func TestWorkerPoolQueueShutdown(t *testing.T) {
 	oldUnhandledItemRequeueDuration := unhandledItemRequeueDuration.Load()
 	unhandledItemRequeueDuration.Store(int64(100 * time.Millisecond))
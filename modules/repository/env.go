@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 
+	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
@@ -82,6 +83,10 @@ func FullPushingEnvironment(author, committer *user_model.User, repo *repo_model
 	if !committer.KeepEmailPrivate {
 		environ = append(environ, EnvPusherEmail+"="+committer.Email)
 	}
+	if committer.IsActions() {
+		// the permissions of the Actions token have been checked before pushing on behalf of it
+		environ = append(environ, fmt.Sprintf("%s=%d", EnvActionPerm, perm.AccessModeWrite))
+	}
 
 	return environ
 }
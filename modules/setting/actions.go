@@ -5,6 +5,7 @@ package setting
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -20,15 +21,24 @@ var (
 		LogCompression        logCompression    `ini:"LOG_COMPRESSION"`
 		ArtifactStorage       *Storage          // how the created artifacts should be stored
 		ArtifactRetentionDays int64             `ini:"ARTIFACT_RETENTION_DAYS"`
+		CacheStorage          *Storage          // how the dependency caches should be stored
+		CacheRetentionDays    int64             `ini:"CACHE_RETENTION_DAYS"`
+		CacheMaxSizePerRepo   int64             `ini:"-"`
 		DefaultActionsURL     defaultActionsURL `ini:"DEFAULT_ACTIONS_URL"`
 		ZombieTaskTimeout     time.Duration     `ini:"ZOMBIE_TASK_TIMEOUT"`
 		EndlessTaskTimeout    time.Duration     `ini:"ENDLESS_TASK_TIMEOUT"`
 		AbandonedJobTimeout   time.Duration     `ini:"ABANDONED_JOB_TIMEOUT"`
 		SkipWorkflowStrings   []string          `ìni:"SKIP_WORKFLOW_STRINGS"`
+
+		IDTokenSigningAlgorithm      string        `ini:"ID_TOKEN_SIGNING_ALGORITHM"`
+		IDTokenSigningPrivateKeyFile string        `ini:"ID_TOKEN_SIGNING_PRIVATE_KEY_FILE"`
+		IDTokenExpirationTime        time.Duration `ini:"ID_TOKEN_EXPIRATION_TIME"`
 	}{
-		Enabled:             true,
-		DefaultActionsURL:   defaultActionsURLGitHub,
-		SkipWorkflowStrings: []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]"},
+		Enabled:                      true,
+		DefaultActionsURL:            defaultActionsURLGitHub,
+		SkipWorkflowStrings:          []string{"[skip ci]", "[ci skip]", "[no ci]", "[skip actions]", "[actions skip]"},
+		IDTokenSigningAlgorithm:      "RS256",
+		IDTokenSigningPrivateKeyFile: "actions_id_token/private.pem",
 	}
 )
 
@@ -111,10 +121,38 @@ func loadActionsFrom(rootCfg ConfigProvider) error {
 		Actions.ArtifactRetentionDays = 90
 	}
 
+	cacheSec, _ := rootCfg.GetSection("actions.cache")
+
+	Actions.CacheStorage, err = getStorage(rootCfg, "actions_cache", "", cacheSec)
+	if err != nil {
+		return err
+	}
+
+	// default to 7 days and 10 GB per repository in Github Actions
+	if Actions.CacheRetentionDays <= 0 {
+		Actions.CacheRetentionDays = 7
+	}
+	Actions.CacheMaxSizePerRepo = 10 << 30
+	if sec.HasKey("CACHE_MAX_SIZE_PER_REPO") {
+		// -1 means no limit
+		Actions.CacheMaxSizePerRepo = mustBytes(sec, "CACHE_MAX_SIZE_PER_REPO")
+	}
+
 	Actions.ZombieTaskTimeout = sec.Key("ZOMBIE_TASK_TIMEOUT").MustDuration(10 * time.Minute)
 	Actions.EndlessTaskTimeout = sec.Key("ENDLESS_TASK_TIMEOUT").MustDuration(3 * time.Hour)
 	Actions.AbandonedJobTimeout = sec.Key("ABANDONED_JOB_TIMEOUT").MustDuration(24 * time.Hour)
 
+	// the OIDC ID tokens of jobs are verified by third parties with the public key of the issuer
+	switch Actions.IDTokenSigningAlgorithm {
+	case "RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA":
+	default:
+		return fmt.Errorf("unsupported [actions] ID_TOKEN_SIGNING_ALGORITHM: %q", Actions.IDTokenSigningAlgorithm)
+	}
+	if !filepath.IsAbs(Actions.IDTokenSigningPrivateKeyFile) {
+		Actions.IDTokenSigningPrivateKeyFile = filepath.Join(AppDataPath, Actions.IDTokenSigningPrivateKeyFile)
+	}
+	Actions.IDTokenExpirationTime = sec.Key("ID_TOKEN_EXPIRATION_TIME").MustDuration(10 * time.Minute)
+
 	if !Actions.LogCompression.IsValid() {
 		return fmt.Errorf("invalid [actions] LOG_COMPRESSION: %q", Actions.LogCompression)
 	}
@@ -24,6 +24,8 @@ func Test_getStorageInheritNameSectionTypeForActions(t *testing.T) {
 	assert.EqualValues(t, "actions_log/", Actions.LogStorage.MinioConfig.BasePath)
 	assert.EqualValues(t, "minio", Actions.ArtifactStorage.Type)
 	assert.EqualValues(t, "actions_artifacts/", Actions.ArtifactStorage.MinioConfig.BasePath)
+	assert.EqualValues(t, "minio", Actions.CacheStorage.Type)
+	assert.EqualValues(t, "actions_cache/", Actions.CacheStorage.MinioConfig.BasePath)
 
 	iniStr = `
 [storage.actions_log]
@@ -179,3 +181,29 @@ DEFAULT_ACTIONS_URL = gitea
 		})
 	}
 }
+
+func Test_loadActionsCacheSettings(t *testing.T) {
+	cfg, err := NewConfigProviderFromData(``)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.EqualValues(t, 7, Actions.CacheRetentionDays)
+	assert.EqualValues(t, 10<<30, Actions.CacheMaxSizePerRepo)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+CACHE_RETENTION_DAYS = 30
+CACHE_MAX_SIZE_PER_REPO = 512 MiB
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.EqualValues(t, 30, Actions.CacheRetentionDays)
+	assert.EqualValues(t, 512<<20, Actions.CacheMaxSizePerRepo)
+
+	cfg, err = NewConfigProviderFromData(`
+[actions]
+CACHE_MAX_SIZE_PER_REPO = -1
+`)
+	require.NoError(t, err)
+	require.NoError(t, loadActionsFrom(cfg))
+	assert.EqualValues(t, -1, Actions.CacheMaxSizePerRepo)
+}
@@ -51,9 +51,16 @@ type RepositoryStruct struct {
 	OpenWithEditorApps *config.Value[OpenWithEditorAppsType]
 }
 
+// MaintenanceStruct is the read-only maintenance mode of the instance, the message is shown to the users while it's enabled
+type MaintenanceStruct struct {
+	Enabled *config.Value[bool]
+	Message *config.Value[string]
+}
+
 type ConfigStruct struct {
-	Picture    *PictureStruct
-	Repository *RepositoryStruct
+	Picture     *PictureStruct
+	Repository  *RepositoryStruct
+	Maintenance *MaintenanceStruct
 }
 
 var (
@@ -71,6 +78,10 @@ func initDefaultConfig() {
 		Repository: &RepositoryStruct{
 			OpenWithEditorApps: config.ValueJSON[OpenWithEditorAppsType]("repository.open-with.editor-apps"),
 		},
+		Maintenance: &MaintenanceStruct{
+			Enabled: config.ValueJSON[bool]("maintenance.enabled").WithFileConfig(config.CfgSecKey{Sec: "maintenance", Key: "ENABLED"}),
+			Message: config.ValueJSON[string]("maintenance.message"),
+		},
 	}
 }
 
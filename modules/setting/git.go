@@ -5,6 +5,7 @@ package setting
 
 import (
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -30,6 +31,8 @@ var Git = struct {
 	LargeObjectThreshold      int64
 	DisableCoreProtectNTFS    bool
 	DisablePartialClone       bool
+	PartialCloneFilters       []string `ini:"PARTIAL_CLONE_FILTERS"`
+	PartialCloneMaxTreeDepth  int
 	Timeout                   struct {
 		Default int
 		Migrate int
@@ -52,6 +55,8 @@ var Git = struct {
 	PullRequestPushMessage:    true,
 	LargeObjectThreshold:      1024 * 1024,
 	DisablePartialClone:       false,
+	PartialCloneFilters:       PartialCloneFilterKinds,
+	PartialCloneMaxTreeDepth:  -1,
 	Timeout: struct {
 		Default int
 		Migrate int
@@ -69,6 +74,9 @@ var Git = struct {
 	},
 }
 
+// PartialCloneFilterKinds are the object filter kinds git upload-pack supports for partial clones
+var PartialCloneFilterKinds = []string{"blob:none", "blob:limit", "tree", "sparse:oid", "object:type"}
+
 type GitConfigType struct {
 	Options map[string]string // git config key is case-insensitive, always use lower-case
 }
@@ -91,6 +99,23 @@ func loadGitFrom(rootCfg ConfigProvider) {
 		log.Fatal("Failed to map Git settings: %v", err)
 	}
 
+	filters := make([]string, 0, len(Git.PartialCloneFilters))
+	for _, filter := range Git.PartialCloneFilters {
+		filter = strings.ToLower(strings.TrimSpace(filter))
+		if filter == "" {
+			continue
+		}
+		if !slices.Contains(PartialCloneFilterKinds, filter) {
+			log.Warn("Unknown partial clone filter kind %q in [git] PARTIAL_CLONE_FILTERS, valid kinds are: %s", filter, strings.Join(PartialCloneFilterKinds, ", "))
+			continue
+		}
+		filters = append(filters, filter)
+	}
+	if len(filters) == 0 {
+		filters = PartialCloneFilterKinds
+	}
+	Git.PartialCloneFilters = filters
+
 	secGitConfig := rootCfg.Section("git.config")
 	GitConfig.Options = make(map[string]string)
 	GitConfig.SetOption("diff.algorithm", "histogram")
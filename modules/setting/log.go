@@ -127,6 +127,11 @@ func loadLogModeByName(rootCfg ConfigProvider, loggerName, modeName string) (wri
 		writerName += ".access"
 		defaultFlags = "none"
 		defaultFilaName = "access.log"
+	} else if loggerName == "audit" {
+		// "audit" logger is special like the "access" logger, it only outputs the JSON of the audit events
+		writerName += ".audit"
+		defaultFlags = "none"
+		defaultFilaName = "audit.log"
 	}
 
 	writerMode.Level = log.LevelFromString(ConfigInheritedKeyString(sec, "LEVEL", Log.Level.String()))
@@ -209,6 +214,7 @@ func initManagedLoggers(manager *log.LoggerManager, cfg ConfigProvider) {
 
 	initLoggerByName(manager, cfg, log.DEFAULT) // default
 	initLoggerByName(manager, cfg, "access")
+	initLoggerByName(manager, cfg, "audit")
 	initLoggerByName(manager, cfg, "router")
 	initLoggerByName(manager, cfg, "xorm")
 }
@@ -264,6 +270,10 @@ func IsAccessLogEnabled() bool {
 	return log.IsLoggerEnabled("access")
 }
 
+func IsAuditLogEnabled() bool {
+	return log.IsLoggerEnabled("audit")
+}
+
 func IsRouteLogEnabled() bool {
 	return log.IsLoggerEnabled("router")
 }
@@ -9,11 +9,15 @@ var Metrics = struct {
 	Token                    string
 	EnabledIssueByLabel      bool
 	EnabledIssueByRepository bool
+
+	EnabledHousekeepingByRepository bool
 }{
 	Enabled:                  false,
 	Token:                    "",
 	EnabledIssueByLabel:      false,
 	EnabledIssueByRepository: false,
+
+	EnabledHousekeepingByRepository: false,
 }
 
 func loadMetricsFrom(rootCfg ConfigProvider) {
@@ -8,6 +8,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/dustin/go-humanize"
 )
@@ -42,12 +43,17 @@ var (
 		LimitSizeRpm         int64
 		LimitSizeRubyGems    int64
 		LimitSizeSwift       int64
+		LimitSizeTerraform   int64
 		LimitSizeVagrant     int64
 
 		DefaultRPMSignEnabled bool
+
+		RemoteAllowedHostList string
+		RemoteMetadataTTL     time.Duration
 	}{
 		Enabled:              true,
 		LimitTotalOwnerCount: -1,
+		RemoteMetadataTTL:    30 * time.Minute,
 	}
 )
 
@@ -100,8 +106,11 @@ func loadPackagesFrom(rootCfg ConfigProvider) (err error) {
 	Packages.LimitSizeRpm = mustBytes(sec, "LIMIT_SIZE_RPM")
 	Packages.LimitSizeRubyGems = mustBytes(sec, "LIMIT_SIZE_RUBYGEMS")
 	Packages.LimitSizeSwift = mustBytes(sec, "LIMIT_SIZE_SWIFT")
+	Packages.LimitSizeTerraform = mustBytes(sec, "LIMIT_SIZE_TERRAFORM")
 	Packages.LimitSizeVagrant = mustBytes(sec, "LIMIT_SIZE_VAGRANT")
 	Packages.DefaultRPMSignEnabled = sec.Key("DEFAULT_RPM_SIGN_ENABLED").MustBool(false)
+	Packages.RemoteAllowedHostList = sec.Key("REMOTE_ALLOWED_HOST_LIST").MustString("")
+	Packages.RemoteMetadataTTL = sec.Key("REMOTE_METADATA_TTL").MustDuration(30 * time.Minute)
 	return nil
 }
 
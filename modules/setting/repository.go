@@ -8,6 +8,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -112,6 +113,18 @@ var (
 			Wiki              []string
 			DefaultTrustModel string
 		} `ini:"repository.signing"`
+
+		Housekeeping struct {
+			Enabled               bool
+			PushesBeforeCheck     int64
+			MaxConcurrency        int
+			MaxLooseObjects       int64
+			MaxPacks              int64
+			GeometricFactor       int
+			FullRepackInterval    time.Duration
+			MaxLooseRefs          int64
+			UnreachableExpiration time.Duration
+		} `ini:"repository.housekeeping"`
 	}{
 		DetectedCharsetsOrder: []string{
 			"UTF-8",
@@ -270,6 +283,29 @@ var (
 			Wiki:              []string{"never"},
 			DefaultTrustModel: "collaborator",
 		},
+
+		// Housekeeping settings
+		Housekeeping: struct {
+			Enabled               bool
+			PushesBeforeCheck     int64
+			MaxConcurrency        int
+			MaxLooseObjects       int64
+			MaxPacks              int64
+			GeometricFactor       int
+			FullRepackInterval    time.Duration
+			MaxLooseRefs          int64
+			UnreachableExpiration time.Duration
+		}{
+			Enabled:               false,
+			PushesBeforeCheck:     10,
+			MaxConcurrency:        1,
+			MaxLooseObjects:       1024,
+			MaxPacks:              16,
+			GeometricFactor:       2,
+			FullRepackInterval:    7 * 24 * time.Hour,
+			MaxLooseRefs:          512,
+			UnreachableExpiration: 14 * 24 * time.Hour,
+		},
 	}
 	RepoRootPath string
 	ScriptType   = "bash"
@@ -329,6 +365,14 @@ func loadRepositoryFrom(rootCfg ConfigProvider) {
 		Repository.Signing.DefaultTrustModel = "collaborator"
 	}
 
+	if Repository.Housekeeping.MaxConcurrency < 1 {
+		Repository.Housekeeping.MaxConcurrency = 1
+	}
+	if Repository.Housekeeping.GeometricFactor < 2 {
+		log.Warn("[repository.housekeeping] GEOMETRIC_FACTOR must be at least 2")
+		Repository.Housekeeping.GeometricFactor = 2
+	}
+
 	// Handle preferred charset orders
 	preferred := make([]string, 0, len(Repository.DetectedCharsetsOrder))
 	for _, charset := range Repository.DetectedCharsetsOrder {
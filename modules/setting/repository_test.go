@@ -0,0 +1,37 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRepositoryHousekeeping(t *testing.T) {
+	oldRepository := Repository
+	defer func() {
+		Repository = oldRepository
+	}()
+
+	cfg, err := NewConfigProviderFromData(`
+[repository.housekeeping]
+ENABLED = true
+PUSHES_BEFORE_CHECK = 5
+MAX_CONCURRENCY = 0
+GEOMETRIC_FACTOR = 1
+FULL_REPACK_INTERVAL = 24h
+`)
+	assert.NoError(t, err)
+	loadRepositoryFrom(cfg)
+
+	assert.True(t, Repository.Housekeeping.Enabled)
+	assert.EqualValues(t, 5, Repository.Housekeeping.PushesBeforeCheck)
+	assert.Equal(t, 1, Repository.Housekeeping.MaxConcurrency)
+	assert.Equal(t, 2, Repository.Housekeeping.GeometricFactor)
+	assert.Equal(t, 24*time.Hour, Repository.Housekeeping.FullRepackInterval)
+	assert.Equal(t, 14*24*time.Hour, Repository.Housekeeping.UnreachableExpiration)
+	assert.EqualValues(t, 1024, Repository.Housekeeping.MaxLooseObjects)
+}
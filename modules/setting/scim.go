@@ -0,0 +1,22 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import "code.gitea.io/gitea/modules/log"
+
+// SCIM settings
+var SCIM = struct {
+	Enabled    bool
+	AuthSource string
+}{
+	Enabled:    false,
+	AuthSource: "",
+}
+
+func loadSCIMFrom(rootCfg ConfigProvider) {
+	mustMapSetting(rootCfg, "scim", &SCIM)
+	if SCIM.Enabled && SCIM.AuthSource == "" {
+		log.Fatal("[scim].AUTH_SOURCE is required when SCIM provisioning is enabled")
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import "time"
+
+// AuditEvent represents a recorded security relevant action
+// swagger:model
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// the kind of the action, e.g. user_login or collaborator_add
+	Action    string `json:"action"`
+	ActorID   int64  `json:"actor_id"`
+	Actor     string `json:"actor"`
+	IPAddress string `json:"ip_address"`
+	// the user or organization the action belongs to, 0 for the instance wide actions
+	OwnerID int64 `json:"owner_id"`
+	// the repository the action belongs to or 0
+	RepoID     int64  `json:"repo_id"`
+	TargetType string `json:"target_type"`
+	TargetID   int64  `json:"target_id"`
+	Target     string `json:"target"`
+	// the state of the target before the action
+	Before map[string]any `json:"before"`
+	// the state of the target after the action
+	After map[string]any `json:"after"`
+	// the hash chaining the event to the previous one
+	Hash string `json:"hash"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
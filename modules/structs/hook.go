@@ -114,6 +114,9 @@ var (
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
 	_ Payloader = &PackagePayload{}
+	_ Payloader = &MergeGroupPayload{}
+	_ Payloader = &WorkflowRunPayload{}
+	_ Payloader = &WorkflowJobPayload{}
 )
 
 // _________                        __
@@ -489,6 +492,36 @@ func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
 
+// HookMergeGroupAction an action that happens to a merge group
+type HookMergeGroupAction string
+
+const (
+	// HookMergeGroupChecksRequested checks requested
+	HookMergeGroupChecksRequested HookMergeGroupAction = "checks_requested"
+)
+
+// MergeGroup represents a temporary ref of the merge queue which contains the queued pull requests
+type MergeGroup struct {
+	HeadSHA    string `json:"head_sha"`
+	HeadRef    string `json:"head_ref"`
+	BaseSHA    string `json:"base_sha"`
+	BaseRef    string `json:"base_ref"`
+	PullNumber int64  `json:"pull_number"`
+}
+
+// MergeGroupPayload represents a merge group payload
+type MergeGroupPayload struct {
+	Action     HookMergeGroupAction `json:"action"`
+	MergeGroup *MergeGroup          `json:"merge_group"`
+	Repository *Repository          `json:"repository"`
+	Sender     *User                `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *MergeGroupPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
 // WorkflowDispatchPayload represents a workflow dispatch payload
 type WorkflowDispatchPayload struct {
 	Workflow   string         `json:"workflow"`
@@ -525,3 +558,55 @@ type CommitStatusPayload struct {
 func (p *CommitStatusPayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// HookWorkflowRunAction an action that happens to a workflow run
+type HookWorkflowRunAction string
+
+const (
+	// HookWorkflowRunRequested the workflow run has been created or rerun
+	HookWorkflowRunRequested HookWorkflowRunAction = "requested"
+	// HookWorkflowRunInProgress the first job of the workflow run has started
+	HookWorkflowRunInProgress HookWorkflowRunAction = "in_progress"
+	// HookWorkflowRunCompleted all jobs of the workflow run are done
+	HookWorkflowRunCompleted HookWorkflowRunAction = "completed"
+)
+
+// WorkflowRunPayload represents a payload information of workflow run event.
+type WorkflowRunPayload struct {
+	Action      HookWorkflowRunAction `json:"action"`
+	WorkflowRun *ActionWorkflowRun    `json:"workflow_run"`
+	Repo        *Repository           `json:"repository"`
+	Sender      *User                 `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WorkflowRunPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// HookWorkflowJobAction an action that happens to a workflow job
+type HookWorkflowJobAction string
+
+const (
+	// HookWorkflowJobQueued the job is waiting for a runner
+	HookWorkflowJobQueued HookWorkflowJobAction = "queued"
+	// HookWorkflowJobWaiting the job is blocked by the jobs it needs or by its concurrency group
+	HookWorkflowJobWaiting HookWorkflowJobAction = "waiting"
+	// HookWorkflowJobInProgress the job has been picked by a runner
+	HookWorkflowJobInProgress HookWorkflowJobAction = "in_progress"
+	// HookWorkflowJobCompleted the job is done
+	HookWorkflowJobCompleted HookWorkflowJobAction = "completed"
+)
+
+// WorkflowJobPayload represents a payload information of workflow job event.
+type WorkflowJobPayload struct {
+	Action      HookWorkflowJobAction `json:"action"`
+	WorkflowJob *ActionWorkflowJob    `json:"workflow_job"`
+	Repo        *Repository           `json:"repository"`
+	Sender      *User                 `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WorkflowJobPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
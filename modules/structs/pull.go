@@ -58,6 +58,18 @@ type PullRequest struct {
 	Closed *time.Time `json:"closed_at"`
 
 	PinOrder int `json:"pin_order"`
+
+	// the CODEOWNERS rules of the files changed by the pull request which haven't been approved by one of their owners,
+	// only returned when getting a single pull request whose base branch requires the approval of the code owners
+	UnapprovedCodeOwners []*PullRequestCodeOwnersRule `json:"unapproved_code_owners,omitempty"`
+}
+
+// PullRequestCodeOwnersRule represents a CODEOWNERS rule owning files changed by a pull request
+type PullRequestCodeOwnersRule struct {
+	// the pattern as written in the CODEOWNERS file
+	Pattern string `json:"pattern"`
+	// the names of the owners, the teams are named as "org/team"
+	Owners []string `json:"owners"`
 }
 
 // PRBranchInfo information about a branch
@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+// QuotaInfo represents the storage used by a user or an organization and the quota groups applied to it
+type QuotaInfo struct {
+	Used   *QuotaUsed    `json:"used"`
+	Groups []*QuotaGroup `json:"groups"`
+}
+
+// QuotaUsed represents the storage used by a user or an organization in bytes
+type QuotaUsed struct {
+	Git         int64 `json:"git"`
+	LFS         int64 `json:"lfs"`
+	Packages    int64 `json:"packages"`
+	Artifacts   int64 `json:"artifacts"`
+	Attachments int64 `json:"attachments"`
+	Total       int64 `json:"total"`
+}
+
+// QuotaGroup represents a set of quota rules applied to users and organizations
+type QuotaGroup struct {
+	Name  string       `json:"name"`
+	Rules []*QuotaRule `json:"rules"`
+}
+
+// QuotaRule represents a limit of the total size of some subjects
+type QuotaRule struct {
+	Name string `json:"name"`
+	// the limit in bytes, a negative limit means unlimited
+	Limit int64 `json:"limit"`
+	// the subjects limited by the rule, one of `size:all`, `size:git`, `size:lfs`, `size:packages`, `size:artifacts`, `size:attachments`
+	Subjects []string `json:"subjects"`
+}
+
+// CreateQuotaRuleOption options for creating a quota rule
+type CreateQuotaRuleOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// the limit in bytes, a negative limit means unlimited
+	Limit int64 `json:"limit"`
+	// required: true
+	Subjects []string `json:"subjects" binding:"Required"`
+}
+
+// EditQuotaRuleOption options for editing a quota rule
+type EditQuotaRuleOption struct {
+	// the limit in bytes, a negative limit means unlimited
+	Limit *int64 `json:"limit"`
+	// the subjects limited by the rule
+	Subjects []string `json:"subjects"`
+}
+
+// CreateQuotaGroupOption options for creating a quota group
+type CreateQuotaGroupOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// names of the rules in the group
+	Rules []string `json:"rules"`
+}
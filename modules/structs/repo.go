@@ -302,21 +302,30 @@ type GitServiceType int
 
 // enumerate all GitServiceType
 const (
-	NotMigrated       GitServiceType = iota // 0 not migrated from external sites
-	PlainGitService                         // 1 plain git service
-	GithubService                           // 2 github.com
-	GiteaService                            // 3 gitea service
-	GitlabService                           // 4 gitlab service
-	GogsService                             // 5 gogs service
-	OneDevService                           // 6 onedev service
-	GitBucketService                        // 7 gitbucket service
-	CodebaseService                         // 8 codebase service
-	CodeCommitService                       // 9 codecommit service
+	NotMigrated            GitServiceType = iota // 0 not migrated from external sites
+	PlainGitService                              // 1 plain git service
+	GithubService                                // 2 github.com
+	GiteaService                                 // 3 gitea service
+	GitlabService                                // 4 gitlab service
+	GogsService                                  // 5 gogs service
+	OneDevService                                // 6 onedev service
+	GitBucketService                             // 7 gitbucket service
+	CodebaseService                              // 8 codebase service
+	CodeCommitService                            // 9 codecommit service
+	BitbucketService                             // 10 bitbucket cloud service
+	BitbucketServerService                       // 11 bitbucket data center service
+	AzureDevOpsService                           // 12 azure devops service
 )
 
 // Name represents the service type's name
 // WARNNING: the name have to be equal to that on goth's library
 func (gt GitServiceType) Name() string {
+	switch gt {
+	case BitbucketServerService:
+		return "bitbucketserver"
+	case AzureDevOpsService:
+		return "azuredevops"
+	}
 	return strings.ToLower(gt.Title())
 }
 
@@ -339,6 +348,12 @@ func (gt GitServiceType) Title() string {
 		return "Codebase"
 	case CodeCommitService:
 		return "CodeCommit"
+	case BitbucketService:
+		return "Bitbucket"
+	case BitbucketServerService:
+		return "Bitbucket Data Center"
+	case AzureDevOpsService:
+		return "Azure DevOps"
 	case PlainGitService:
 		return "Git"
 	}
@@ -357,7 +372,7 @@ type MigrateRepoOptions struct {
 	// required: true
 	RepoName string `json:"repo_name" binding:"Required;AlphaDashDot;MaxSize(100)"`
 
-	// enum: git,github,gitea,gitlab,gogs,onedev,gitbucket,codebase
+	// enum: git,github,gitea,gitlab,gogs,onedev,gitbucket,codebase,bitbucket,bitbucketserver,azuredevops
 	Service      string `json:"service"`
 	AuthUsername string `json:"auth_username"`
 	AuthPassword string `json:"auth_password"`
@@ -400,6 +415,9 @@ var SupportedFullGitService = []GitServiceType{
 	GitBucketService,
 	CodebaseService,
 	CodeCommitService,
+	BitbucketService,
+	BitbucketServerService,
+	AzureDevOpsService,
 }
 
 // RepoTransfer represents a pending repo transfer
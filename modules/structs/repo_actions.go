@@ -19,6 +19,8 @@ type ActionTask struct {
 	Status       string `json:"status"`
 	WorkflowID   string `json:"workflow_id"`
 	URL          string `json:"url"`
+	// the concurrency group of the workflow run
+	ConcurrencyGroup string `json:"concurrency_group"`
 	// swagger:strfmt date-time
 	CreatedAt time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -32,3 +34,139 @@ type ActionTaskResponse struct {
 	Entries    []*ActionTask `json:"workflow_runs"`
 	TotalCount int64         `json:"total_count"`
 }
+
+// ActionWorkflowRun represents a run of a workflow
+type ActionWorkflowRun struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	DisplayTitle string `json:"display_title"`
+	RunNumber    int64  `json:"run_number"`
+	Event        string `json:"event"`
+	// the status of the run, one of "queued", "waiting", "in_progress" and "completed"
+	Status string `json:"status"`
+	// the conclusion of a completed run, one of "success", "failure", "cancelled" and "skipped"
+	Conclusion string `json:"conclusion,omitempty"`
+	WorkflowID string `json:"workflow_id"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+	HTMLURL    string `json:"html_url"`
+	Actor      *User  `json:"actor"`
+	// the concurrency group of the workflow run
+	ConcurrencyGroup string `json:"concurrency_group"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	RunStartedAt *time.Time `json:"run_started_at,omitempty"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ActionWorkflowJob represents a job of a workflow run
+type ActionWorkflowJob struct {
+	ID      int64  `json:"id"`
+	RunID   int64  `json:"run_id"`
+	RunURL  string `json:"run_url"`
+	Name    string `json:"name"`
+	HeadSHA string `json:"head_sha"`
+	// the status of the job, one of "queued", "waiting", "in_progress" and "completed"
+	Status string `json:"status"`
+	// the conclusion of a completed job, one of "success", "failure", "cancelled" and "skipped"
+	Conclusion string                `json:"conclusion,omitempty"`
+	HTMLURL    string                `json:"html_url"`
+	Labels     []string              `json:"labels"`
+	RunnerID   int64                 `json:"runner_id,omitempty"`
+	RunnerName string                `json:"runner_name,omitempty"`
+	Steps      []*ActionWorkflowStep `json:"steps"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ActionWorkflowStep represents a step of a workflow job
+type ActionWorkflowStep struct {
+	Name       string `json:"name"`
+	Number     int64  `json:"number"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion,omitempty"`
+	// swagger:strfmt date-time
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// swagger:strfmt date-time
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// ActionWorkflowRunsResponse returns the workflow runs
+type ActionWorkflowRunsResponse struct {
+	TotalCount   int64                `json:"total_count"`
+	WorkflowRuns []*ActionWorkflowRun `json:"workflow_runs"`
+}
+
+// ActionWorkflowJobsResponse returns the workflow jobs
+type ActionWorkflowJobsResponse struct {
+	TotalCount int64                `json:"total_count"`
+	Jobs       []*ActionWorkflowJob `json:"jobs"`
+}
+
+// ActionWorkflow represents a workflow file in the default branch of a repository
+type ActionWorkflow struct {
+	// the file name of the workflow
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// the state of the workflow, one of "active" and "disabled_manually"
+	State    string `json:"state"`
+	HTMLURL  string `json:"html_url"`
+	BadgeURL string `json:"badge_url"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ActionWorkflowsResponse returns the workflows
+type ActionWorkflowsResponse struct {
+	TotalCount int64             `json:"total_count"`
+	Workflows  []*ActionWorkflow `json:"workflows"`
+}
+
+// ActionArtifact represents an artifact uploaded by a workflow run
+type ActionArtifact struct {
+	ID                 int64                     `json:"id"`
+	Name               string                    `json:"name"`
+	SizeInBytes        int64                     `json:"size_in_bytes"`
+	URL                string                    `json:"url"`
+	ArchiveDownloadURL string                    `json:"archive_download_url"`
+	Expired            bool                      `json:"expired"`
+	WorkflowRun        *ActionWorkflowRunSummary `json:"workflow_run"`
+	// swagger:strfmt date-time
+	CreatedAt time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	UpdatedAt time.Time `json:"updated_at"`
+	// swagger:strfmt date-time
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ActionWorkflowRunSummary represents the workflow run which uploaded an artifact
+type ActionWorkflowRunSummary struct {
+	ID           int64  `json:"id"`
+	RepositoryID int64  `json:"repository_id"`
+	HeadBranch   string `json:"head_branch"`
+	HeadSHA      string `json:"head_sha"`
+}
+
+// ActionArtifactsResponse returns the artifacts
+type ActionArtifactsResponse struct {
+	TotalCount int64             `json:"total_count"`
+	Artifacts  []*ActionArtifact `json:"artifacts"`
+}
+
+// CreateActionWorkflowDispatch represents the options to run a workflow by the workflow_dispatch event
+type CreateActionWorkflowDispatch struct {
+	// the branch or tag to run the workflow on, could be a short name or a full ref name
+	// required: true
+	Ref string `json:"ref" binding:"Required"`
+	// the inputs of the workflow_dispatch event, the defaults in the workflow are used for the omitted inputs
+	Inputs map[string]string `json:"inputs"`
+}
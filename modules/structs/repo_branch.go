@@ -54,6 +54,8 @@ type BranchProtection struct {
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
 	BlockAdminMergeOverride       bool     `json:"block_admin_merge_override"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -94,6 +96,8 @@ type CreateBranchProtectionOption struct {
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
 	BlockAdminMergeOverride       bool     `json:"block_admin_merge_override"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -127,6 +131,8 @@ type EditBranchProtectionOption struct {
 	ProtectedFilePatterns         *string  `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       *string  `json:"unprotected_file_patterns"`
 	BlockAdminMergeOverride       *bool    `json:"block_admin_merge_override"`
+	EnableMergeQueue              *bool    `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      *bool    `json:"require_code_owner_approval"`
 }
 
 // UpdateBranchProtectionPriories a list to update the branch protection rule priorities
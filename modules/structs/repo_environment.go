@@ -0,0 +1,76 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+import (
+	"time"
+)
+
+// Environment represents a deployment environment of a repository
+// swagger:model
+type Environment struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// minutes to wait before the jobs deploying to the environment start
+	WaitTimer int64 `json:"wait_timer"`
+	// glob patterns of the branches and tags allowed to deploy to the environment, all refs are allowed if it's empty
+	BranchFilters []string `json:"branch_filters"`
+	// the users who could approve the deployments to the environment
+	Reviewers []*User `json:"reviewers"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateOrUpdateEnvironmentOption options when creating or updating an environment
+// swagger:model
+type CreateOrUpdateEnvironmentOption struct {
+	// minutes to wait before the jobs deploying to the environment start, at most 43200 (30 days)
+	WaitTimer int64 `json:"wait_timer"`
+	// glob patterns of the branches and tags allowed to deploy to the environment
+	BranchFilters []string `json:"branch_filters"`
+	// names of the users who could approve the deployments, one approval is required if it's not empty
+	Reviewers []string `json:"reviewers"`
+}
+
+// Deployment represents a job of a workflow run deploying to an environment
+// swagger:model
+type Deployment struct {
+	ID          int64  `json:"id"`
+	Environment string `json:"environment"`
+	// the status of the deployment, one of "waiting", "queued", "in_progress", "success", "failure", "cancelled" and "rejected"
+	Status  string `json:"status"`
+	Ref     string `json:"ref"`
+	SHA     string `json:"sha"`
+	RunID   int64  `json:"run_id"`
+	JobID   int64  `json:"job_id"`
+	Creator *User  `json:"creator"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// PendingDeployment represents a deployment of a workflow run waiting for the reviews
+// swagger:model
+type PendingDeployment struct {
+	Environment *Environment `json:"environment"`
+	// whether the current user could approve or reject the deployment
+	CurrentUserCanApprove bool `json:"current_user_can_approve"`
+}
+
+// ReviewPendingDeploymentsOption options when approving or rejecting the pending deployments of a workflow run
+// swagger:model
+type ReviewPendingDeploymentsOption struct {
+	// ids of the environments to approve or reject
+	//
+	// required: true
+	EnvironmentIDs []int64 `json:"environment_ids" binding:"Required"`
+	// "approved" or "rejected"
+	//
+	// required: true
+	State   string `json:"state" binding:"Required;In(approved,rejected)"`
+	Comment string `json:"comment"`
+}
@@ -34,6 +34,7 @@ type CreateOAuth2ApplicationOptions struct {
 	Name                       string   `json:"name" binding:"Required"`
 	ConfidentialClient         bool     `json:"confidential_client"`
 	SkipSecondaryAuthorization bool     `json:"skip_secondary_authorization"`
+	EnableDeviceFlow           bool     `json:"enable_device_flow"`
 	RedirectURIs               []string `json:"redirect_uris" binding:"Required"`
 }
 
@@ -46,6 +47,7 @@ type OAuth2Application struct {
 	ClientSecret               string    `json:"client_secret"`
 	ConfidentialClient         bool      `json:"confidential_client"`
 	SkipSecondaryAuthorization bool      `json:"skip_secondary_authorization"`
+	EnableDeviceFlow           bool      `json:"enable_device_flow"`
 	RedirectURIs               []string  `json:"redirect_uris"`
 	Created                    time.Time `json:"created"`
 }
@@ -131,6 +131,10 @@ func migrationIcon(hostname string) string {
 	switch hostname {
 	case "github.com":
 		return "octicon-mark-github"
+	case "bitbucket.org":
+		return "gitea-bitbucket"
+	case "dev.azure.com":
+		return "gitea-azuredevops"
 	default:
 		return "gitea-git"
 	}
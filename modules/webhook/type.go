@@ -33,6 +33,9 @@ const (
 	HookEventPackage                   HookEventType = "package"
 	HookEventSchedule                  HookEventType = "schedule"
 	HookEventStatus                    HookEventType = "status"
+	HookEventMergeGroup                HookEventType = "merge_group"
+	HookEventWorkflowRun               HookEventType = "workflow_run"
+	HookEventWorkflowJob               HookEventType = "workflow_job"
 )
 
 // Event returns the HookEventType as an event string
@@ -65,6 +68,10 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventWorkflowRun:
+		return "workflow_run"
+	case HookEventWorkflowJob:
+		return "workflow_job"
 	}
 	return ""
 }
@@ -20,5 +20,8 @@ func Routes(prefix string) *web.Router {
 	path, handler = runner.NewRunnerServiceHandler()
 	m.Post(path+"*", http.StripPrefix(prefix, handler).ServeHTTP)
 
+	oidcRoutes(m)
+	summaryRoutes(m)
+
 	return m
 }
@@ -0,0 +1,443 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+// GitHub Actions Cache Service V2 API Simple Description
+//
+// It's used by `actions/cache` (@actions/cache >= 4.0.0) when ACTIONS_CACHE_SERVICE_V2 is set by the runner,
+// the requests are sent to ACTIONS_RESULTS_URL like the artifacts V4 API.
+// The caches are scoped by the repository and the ref of the run, the metadata sent by the client is ignored.
+//
+// 1. Save cache
+// 1.1. CreateCacheEntry
+// Post: /twirp/github.actions.results.api.v1.CacheService/CreateCacheEntry
+// Request:
+// {
+//     "key": "npm-linux-x64-d41d8cd98f00b204",
+//     "version": "ed3e8a8d4e5ad8d8aa6c0b9c1a1d2a1bf5a0e6a3f3a4c2e0e9e0c3b2a0c7b9c1"
+// }
+// Response:
+// {
+//     "ok": true,
+//     "signedUploadUrl": "http://localhost:3000/twirp/github.actions.results.api.v1.CacheService/UploadCache?sig=...&expires=...&cacheID=5&taskID=75"
+// }
+// 1.2. Upload the archive to Blobstorage (unauthenticated request)
+// PUT: {signedUploadUrl} for a single-shot upload,
+// or PUT: {signedUploadUrl}&comp=block&blockid={blockID} repeatedly, then PUT: {signedUploadUrl}&comp=blocklist
+// with the BlockList XML payload to commit the blocks in order.
+// 1.3. FinalizeCacheEntryUpload
+// Post: /twirp/github.actions.results.api.v1.CacheService/FinalizeCacheEntryUpload
+// Request:
+// {
+//     "key": "npm-linux-x64-d41d8cd98f00b204",
+//     "size_bytes": "2097",
+//     "version": "ed3e8a8d..."
+// }
+// Response:
+// {
+//     "ok": true,
+//     "entryId": "5"
+// }
+// 2. Restore cache
+// 2.1. GetCacheEntryDownloadURL, the key is matched exactly first, then the key and the restore keys are matched as prefixes
+// Post: /twirp/github.actions.results.api.v1.CacheService/GetCacheEntryDownloadURL
+// Request:
+// {
+//     "key": "npm-linux-x64-d41d8cd98f00b204",
+//     "restore_keys": ["npm-linux-x64-", "npm-linux-"],
+//     "version": "ed3e8a8d..."
+// }
+// Response:
+// {
+//     "ok": true,
+//     "signedDownloadUrl": "http://localhost:3000/twirp/github.actions.results.api.v1.CacheService/DownloadCache?sig=...&expires=...&cacheID=5&taskID=76",
+//     "matchedKey": "npm-linux-x64-d41d8cd98f00b204"
+// }
+// 2.2. Download the archive from Blobstorage (unauthenticated request), HEAD and range requests are supported
+// GET: {signedDownloadUrl}
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	CacheRouteBase = "/twirp/github.actions.results.api.v1.CacheService"
+
+	// the limits of the key are the same as GitHub's
+	cacheKeyMaxLength = 512
+	// the signed URLs of the caches are valid for this period
+	cacheURLExpiration = 60 * time.Minute
+)
+
+type cacheRoutes struct {
+	prefix string
+	fs     storage.ObjectStorage
+}
+
+func CacheRoutes(prefix string) *web.Router {
+	m := web.NewRouter()
+
+	r := cacheRoutes{
+		prefix: prefix,
+		fs:     storage.ActionsCache,
+	}
+
+	m.Group("", func() {
+		m.Post("CreateCacheEntry", r.createCacheEntry)
+		m.Post("FinalizeCacheEntryUpload", r.finalizeCacheEntryUpload)
+		m.Post("GetCacheEntryDownloadURL", r.getCacheEntryDownloadURL)
+	}, ArtifactContexter())
+	m.Group("", func() {
+		m.Put("UploadCache", r.uploadCache)
+		m.Methods("GET,HEAD", "DownloadCache", r.downloadCache)
+	}, ArtifactV4Contexter())
+
+	return m
+}
+
+func (r cacheRoutes) buildSignature(endp, expires string, taskID, cacheID int64) []byte {
+	mac := hmac.New(sha256.New, setting.GetGeneralTokenSigningSecret())
+	mac.Write([]byte(endp))
+	mac.Write([]byte(expires))
+	mac.Write([]byte(fmt.Sprint(taskID)))
+	mac.Write([]byte(fmt.Sprint(cacheID)))
+	return mac.Sum(nil)
+}
+
+func (r cacheRoutes) buildCacheURL(ctx *ArtifactContext, endp string, taskID, cacheID int64) string {
+	expires := time.Now().Add(cacheURLExpiration).Format("2006-01-02 15:04:05.999999999 -0700 MST")
+	return strings.TrimSuffix(httplib.GuessCurrentAppURL(ctx), "/") + strings.TrimSuffix(r.prefix, "/") +
+		"/" + endp + "?sig=" + base64.URLEncoding.EncodeToString(r.buildSignature(endp, expires, taskID, cacheID)) +
+		"&expires=" + url.QueryEscape(expires) + "&taskID=" + fmt.Sprint(taskID) + "&cacheID=" + fmt.Sprint(cacheID)
+}
+
+func (r cacheRoutes) verifySignature(ctx *ArtifactContext, endp string) (*actions.ActionCache, bool) {
+	query := ctx.Req.URL.Query()
+	dsig, _ := base64.URLEncoding.DecodeString(query.Get("sig"))
+	expires := query.Get("expires")
+	taskID, _ := strconv.ParseInt(query.Get("taskID"), 10, 64)
+	cacheID, _ := strconv.ParseInt(query.Get("cacheID"), 10, 64)
+
+	if !hmac.Equal(dsig, r.buildSignature(endp, expires, taskID, cacheID)) {
+		log.Error("Error unauthorized")
+		ctx.Error(http.StatusUnauthorized, "Error unauthorized")
+		return nil, false
+	}
+	t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", expires)
+	if err != nil || t.Before(time.Now()) {
+		log.Error("Error link expired")
+		ctx.Error(http.StatusUnauthorized, "Error link expired")
+		return nil, false
+	}
+	task, err := actions.GetTaskByID(ctx, taskID)
+	if err != nil {
+		log.Error("Error runner api getting task by ID: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error runner api getting task by ID")
+		return nil, false
+	}
+	if task.Status != actions.StatusRunning {
+		log.Error("Error runner api getting task: task is not running")
+		ctx.Error(http.StatusInternalServerError, "Error runner api getting task: task is not running")
+		return nil, false
+	}
+	cache, err := actions.GetCacheByID(ctx, cacheID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "Error cache not found")
+			return nil, false
+		}
+		log.Error("Error getting cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error getting cache")
+		return nil, false
+	}
+	if cache.RepoID != task.RepoID {
+		log.Error("Error cache %d doesn't belong to the repository of task %d", cache.ID, task.ID)
+		ctx.Error(http.StatusUnauthorized, "Error unauthorized")
+		return nil, false
+	}
+	return cache, true
+}
+
+func (r *cacheRoutes) parseProtbufBody(ctx *ArtifactContext, req protoreflect.ProtoMessage) bool {
+	body, err := io.ReadAll(ctx.Req.Body)
+	if err != nil {
+		log.Error("Error decode request body: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error decode request body")
+		return false
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, req); err != nil {
+		log.Error("Error decode request body: %v", err)
+		ctx.Error(http.StatusBadRequest, "Error decode request body")
+		return false
+	}
+	return true
+}
+
+func (r *cacheRoutes) sendProtbufBody(ctx *ArtifactContext, resp protoreflect.ProtoMessage) {
+	data, err := protojson.Marshal(resp)
+	if err != nil {
+		log.Error("Error encode response body: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error encode response body")
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/json;charset=utf-8")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write(data)
+}
+
+// loadRun loads the run of the task with its repository, which decides the scopes of the caches
+func (r *cacheRoutes) loadRun(ctx *ArtifactContext) (*actions.ActionRun, bool) {
+	if err := ctx.ActionTask.Job.LoadAttributes(ctx); err != nil {
+		log.Error("Error loading run: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error loading run")
+		return nil, false
+	}
+	return ctx.ActionTask.Job.Run, true
+}
+
+func validateCacheKey(key string) error {
+	if key == "" || len(key) > cacheKeyMaxLength {
+		return fmt.Errorf("key must be between 1 and %d characters", cacheKeyMaxLength)
+	}
+	if strings.Contains(key, ",") {
+		return errors.New("key cannot contain commas")
+	}
+	return nil
+}
+
+func (r *cacheRoutes) createCacheEntry(ctx *ArtifactContext) {
+	var req CreateCacheEntryRequest
+	if ok := r.parseProtbufBody(ctx, &req); !ok {
+		return
+	}
+	if err := validateCacheKey(req.Key); err != nil || req.Version == "" {
+		r.sendProtbufBody(ctx, &CreateCacheEntryResponse{Ok: false, Message: fmt.Sprintf("invalid key or version: %v", err)})
+		return
+	}
+	run, ok := r.loadRun(ctx)
+	if !ok {
+		return
+	}
+	scope := actions_service.CacheWriteScope(run)
+
+	cache, err := actions.GetCacheByKey(ctx, run.RepoID, scope, req.Key, req.Version)
+	switch {
+	case errors.Is(err, util.ErrNotExist):
+		cache = &actions.ActionCache{
+			RepoID:   run.RepoID,
+			Ref:      scope,
+			CacheKey: req.Key,
+			Version:  req.Version,
+		}
+		err = actions.InsertCache(ctx, cache)
+	case err != nil:
+	case cache.Complete:
+		r.sendProtbufBody(ctx, &CreateCacheEntryResponse{Ok: false, Message: "cache entry with the same key, version and scope already exists"})
+		return
+	case time.Since(cache.UsedUnix.AsTime()) < cacheURLExpiration:
+		r.sendProtbufBody(ctx, &CreateCacheEntryResponse{Ok: false, Message: "cache entry is being created by another job"})
+		return
+	default:
+		// the previous upload has been abandoned, reuse the entry
+		err = actions.ResetCache(ctx, cache)
+	}
+	if err != nil {
+		log.Error("Error creating cache entry: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error creating cache entry")
+		return
+	}
+
+	r.sendProtbufBody(ctx, &CreateCacheEntryResponse{
+		Ok:              true,
+		SignedUploadUrl: r.buildCacheURL(ctx, "UploadCache", ctx.ActionTask.ID, cache.ID),
+	})
+}
+
+func (r *cacheRoutes) uploadCache(ctx *ArtifactContext) {
+	cache, ok := r.verifySignature(ctx, "UploadCache")
+	if !ok {
+		return
+	}
+	if cache.Complete {
+		ctx.Error(http.StatusConflict, "Error cache has been completed")
+		return
+	}
+	if limit := setting.Actions.CacheMaxSizePerRepo; limit > 0 && ctx.Req.ContentLength > limit {
+		ctx.Error(http.StatusRequestEntityTooLarge, "Error cache is too large")
+		return
+	}
+
+	switch comp := ctx.Req.URL.Query().Get("comp"); comp {
+	case "":
+		// single-shot upload
+		if _, err := r.fs.Save(cache.StoragePath(), ctx.Req.Body, ctx.Req.ContentLength); err != nil {
+			log.Error("Error saving cache %d: %v", cache.ID, err)
+			ctx.Error(http.StatusInternalServerError, "Error saving cache")
+			return
+		}
+	case "block", "appendBlock":
+		blockID := ctx.Req.URL.Query().Get("blockid")
+		if blockID == "" {
+			ctx.Error(http.StatusBadRequest, "Error block id is missing")
+			return
+		}
+		if _, err := r.fs.Save(actions_service.CacheBlockPath(cache, blockID), ctx.Req.Body, ctx.Req.ContentLength); err != nil {
+			log.Error("Error saving block of cache %d: %v", cache.ID, err)
+			ctx.Error(http.StatusInternalServerError, "Error saving block")
+			return
+		}
+	case "blocklist":
+		var blockList BlockList
+		if err := xml.NewDecoder(ctx.Req.Body).Decode(&blockList); err != nil {
+			ctx.Error(http.StatusBadRequest, "Error decode block list")
+			return
+		}
+		if _, err := actions_service.CommitCacheBlocks(cache, blockList.Latest); err != nil {
+			log.Error("Error committing blocks: %v", err)
+			ctx.Error(http.StatusInternalServerError, "Error committing blocks")
+			return
+		}
+	default:
+		ctx.Error(http.StatusBadRequest, fmt.Sprintf("Error unsupported comp %q", comp))
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
+
+func (r *cacheRoutes) finalizeCacheEntryUpload(ctx *ArtifactContext) {
+	var req FinalizeCacheEntryUploadRequest
+	if ok := r.parseProtbufBody(ctx, &req); !ok {
+		return
+	}
+	run, ok := r.loadRun(ctx)
+	if !ok {
+		return
+	}
+
+	cache, err := actions.GetCacheByKey(ctx, run.RepoID, actions_service.CacheWriteScope(run), req.Key, req.Version)
+	if errors.Is(err, util.ErrNotExist) || err == nil && cache.Complete {
+		r.sendProtbufBody(ctx, &FinalizeCacheEntryUploadResponse{Ok: false, Message: "cache entry is not reserved"})
+		return
+	} else if err != nil {
+		log.Error("Error getting cache entry: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error getting cache entry")
+		return
+	}
+
+	fi, err := r.fs.Stat(cache.StoragePath())
+	if err != nil {
+		log.Error("Error stat cache %d: %v", cache.ID, err)
+		r.sendProtbufBody(ctx, &FinalizeCacheEntryUploadResponse{Ok: false, Message: "cache archive is not uploaded"})
+		return
+	}
+	if limit := setting.Actions.CacheMaxSizePerRepo; limit > 0 && fi.Size() > limit {
+		if err := actions_service.DeleteCache(ctx, cache); err != nil {
+			log.Error("Error deleting cache %d: %v", cache.ID, err)
+		}
+		r.sendProtbufBody(ctx, &FinalizeCacheEntryUploadResponse{Ok: false, Message: fmt.Sprintf("cache size exceeds the limit of %d bytes", limit)})
+		return
+	}
+	if req.SizeBytes > 0 && req.SizeBytes != fi.Size() {
+		log.Warn("Size of cache %d is %d but %d is declared by the client", cache.ID, fi.Size(), req.SizeBytes)
+	}
+
+	if err := actions.CompleteCache(ctx, cache, fi.Size()); err != nil {
+		log.Error("Error completing cache %d: %v", cache.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Error completing cache")
+		return
+	}
+
+	r.sendProtbufBody(ctx, &FinalizeCacheEntryUploadResponse{
+		Ok:      true,
+		EntryId: cache.ID,
+	})
+}
+
+func (r *cacheRoutes) getCacheEntryDownloadURL(ctx *ArtifactContext) {
+	var req GetCacheEntryDownloadURLRequest
+	if ok := r.parseProtbufBody(ctx, &req); !ok {
+		return
+	}
+	run, ok := r.loadRun(ctx)
+	if !ok {
+		return
+	}
+
+	cache, err := actions.FindCacheToRestore(ctx, run.RepoID, actions_service.CacheReadScopes(run), req.Version, req.Key, req.RestoreKeys)
+	if errors.Is(err, util.ErrNotExist) {
+		r.sendProtbufBody(ctx, &GetCacheEntryDownloadURLResponse{Ok: false})
+		return
+	} else if err != nil {
+		log.Error("Error finding cache: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error finding cache")
+		return
+	}
+	if err := actions.UpdateCacheUsed(ctx, cache); err != nil {
+		log.Error("Error updating cache %d: %v", cache.ID, err)
+	}
+
+	resp := GetCacheEntryDownloadURLResponse{
+		Ok:         true,
+		MatchedKey: cache.CacheKey,
+	}
+	if setting.Actions.CacheStorage.ServeDirect() {
+		u, err := r.fs.URL(cache.StoragePath(), "cache.tzst", nil)
+		if u != nil && err == nil {
+			resp.SignedDownloadUrl = u.String()
+		}
+	}
+	if resp.SignedDownloadUrl == "" {
+		resp.SignedDownloadUrl = r.buildCacheURL(ctx, "DownloadCache", ctx.ActionTask.ID, cache.ID)
+	}
+	r.sendProtbufBody(ctx, &resp)
+}
+
+func (r *cacheRoutes) downloadCache(ctx *ArtifactContext) {
+	cache, ok := r.verifySignature(ctx, "DownloadCache")
+	if !ok {
+		return
+	}
+	if !cache.Complete {
+		ctx.Error(http.StatusNotFound, "Error cache not found")
+		return
+	}
+
+	f, err := r.fs.Open(cache.StoragePath())
+	if err != nil {
+		log.Error("Error opening cache %d: %v", cache.ID, err)
+		ctx.Error(http.StatusInternalServerError, "Error opening cache")
+		return
+	}
+	defer f.Close()
+
+	// the Azure SDK sends the range with its own header
+	if rng := ctx.Req.Header.Get("x-ms-range"); rng != "" {
+		ctx.Req.Header.Set("Range", rng)
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(ctx.Resp, ctx.Req, "", cache.UpdatedUnix.AsTime(), f)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+)
+
+// oidcRoutes serves the OIDC issuer of the ID tokens requested by the jobs with the `id-token: write` permission
+func oidcRoutes(m *web.Router) {
+	m.Group("/oidc", func() {
+		m.Get("/.well-known/openid-configuration", oidcConfiguration)
+		m.Get("/.well-known/jwks", oidcKeys)
+		m.Get("/token", oidcToken)
+	})
+}
+
+func oidcConfiguration(resp http.ResponseWriter, req *http.Request) {
+	ctx, cleanUp := context.NewBaseContext(resp, req)
+	defer cleanUp()
+
+	issuer := actions_service.IDTokenIssuer()
+	ctx.JSON(http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"jwks_uri":                              issuer + "/.well-known/jwks",
+		"subject_types_supported":               []string{"public"},
+		"response_types_supported":              []string{"id_token"},
+		"claims_supported":                      actions_service.IDTokenClaimNames,
+		"id_token_signing_alg_values_supported": []string{actions_service.IDTokenSigningAlgorithm()},
+		"scopes_supported":                      []string{"openid"},
+	})
+}
+
+func oidcKeys(resp http.ResponseWriter, req *http.Request) {
+	ctx, cleanUp := context.NewBaseContext(resp, req)
+	defer cleanUp()
+
+	keys, err := actions_service.IDTokenJWKs()
+	if err != nil {
+		log.Error("IDTokenJWKs: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error getting the keys")
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]any{"keys": keys})
+}
+
+// oidcToken issues an ID token to the task authenticated by the token created by CreateIDTokenRequestToken,
+// the response is in the format expected by actions/toolkit.
+func oidcToken(resp http.ResponseWriter, req *http.Request) {
+	ctx, cleanUp := context.NewBaseContext(resp, req)
+	defer cleanUp()
+
+	authHeader := req.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		ctx.Error(http.StatusUnauthorized, "Bad authorization header")
+		return
+	}
+	taskID, err := actions_service.IDTokenRequestTokenToTaskID(strings.TrimPrefix(authHeader, "Bearer "))
+	if err != nil {
+		ctx.Error(http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		log.Error("GetTaskByID: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error getting the task")
+		return
+	}
+
+	token, err := actions_service.CreateIDToken(ctx, task, req.URL.Query().Get("audience"))
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrPermissionDenied), errors.Is(err, util.ErrInvalidArgument):
+			ctx.Error(http.StatusForbidden, err.Error())
+		default:
+			log.Error("CreateIDToken: %v", err)
+			ctx.Error(http.StatusInternalServerError, "Error creating the token")
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]string{"value": token})
+}
@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/util"
 	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/maintenance"
 
 	runnerv1 "code.gitea.io/actions-proto-go/runner/v1"
 	"code.gitea.io/actions-proto-go/runner/v1/runnerv1connect"
@@ -152,7 +153,11 @@ func (s *Service) FetchTask(
 		latestVersion++
 	}
 
-	if tasksVersion != latestVersion {
+	if tasksVersion != latestVersion && maintenance.IsEnabled(ctx) {
+		// the task assignment is paused during the maintenance,
+		// the runner keeps its tasks version so it tries to pick the tasks again after the maintenance.
+		latestVersion = tasksVersion
+	} else if tasksVersion != latestVersion {
 		// if the task version in request is not equal to the version in db,
 		// it means there may still be some tasks not be assgined.
 		// try to pick a task for the runner that send the request.
@@ -220,6 +225,7 @@ func (s *Service) UpdateTask(
 	}
 
 	if req.Msg.State.Result != runnerv1.Result_RESULT_UNSPECIFIED {
+		actions_service.NotifyWorkflowJobsStatusUpdate(ctx, task.Job)
 		if err := actions_service.EmitJobsIfReady(task.Job.RunID); err != nil {
 			log.Error("Emit ready jobs of run %d: %v", task.Job.RunID, err)
 		}
@@ -265,6 +271,10 @@ func (s *Service) UpdateLog(
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "write logs: %v", err)
 	}
+	if err := actions_service.CollectAnnotations(ctx, task, rows, task.LogLength); err != nil {
+		// the annotations are not essential, don't fail the logs
+		log.Error("CollectAnnotations for task %d: %v", task.ID, err)
+	}
 	task.LogLength += int64(len(rows))
 	for _, n := range ns {
 		task.LogIndexes = append(task.LogIndexes, task.LogSize)
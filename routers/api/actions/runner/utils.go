@@ -8,10 +8,8 @@ import (
 	"fmt"
 
 	actions_model "code.gitea.io/gitea/models/actions"
-	"code.gitea.io/gitea/models/db"
 	secret_model "code.gitea.io/gitea/models/secret"
 	actions_module "code.gitea.io/gitea/modules/actions"
-	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
@@ -36,16 +34,28 @@ func pickTask(ctx context.Context, runner *actions_model.ActionRunner) (*runnerv
 		return nil, false, fmt.Errorf("GetSecretsOfTask: %w", err)
 	}
 
-	vars, err := actions_model.GetVariablesOfRun(ctx, t.Job.Run)
+	payload, secrets, err := actions.PrepareWorkflowCallTask(ctx, t, secrets)
 	if err != nil {
-		return nil, false, fmt.Errorf("GetVariablesOfRun: %w", err)
+		log.Error("Cannot prepare the reusable workflow call for task %v: %v", t.ID, err)
+		// Go on without the inputs and the secrets of the call, like the missing needs below.
+		payload = t.Job.WorkflowPayload
+		secrets = map[string]string{
+			"GITHUB_TOKEN": t.Token,
+			"GITEA_TOKEN":  t.Token,
+		}
+	}
+
+	vars, err := actions_model.GetVariablesOfJob(ctx, t.Job)
+	if err != nil {
+		return nil, false, fmt.Errorf("GetVariablesOfJob: %w", err)
 	}
 
 	actions.CreateCommitStatus(ctx, t.Job)
+	actions.NotifyWorkflowJobsStatusUpdate(ctx, t.Job)
 
 	task := &runnerv1.Task{
 		Id:              t.ID,
-		WorkflowPayload: t.Job.WorkflowPayload,
+		WorkflowPayload: payload,
 		Context:         generateTaskContext(t),
 		Secrets:         secrets,
 		Vars:            vars,
@@ -140,11 +150,23 @@ func generateTaskContext(t *actions_model.ActionTask) *structpb.Struct {
 		// additional contexts
 		"gitea_default_actions_url": setting.Actions.DefaultActionsURL.URL(),
 		"gitea_runtime_token":       giteaRuntimeToken,
+		"gitea_step_summary_url":    actions.StepSummaryUploadURL(),
 	})
 	if err != nil {
 		log.Error("structpb.NewStruct failed: %v", err)
 	}
 
+	// the job could request OIDC ID tokens only if it has the `id-token: write` permission
+	if t.Permissions.CanRequestIDToken() {
+		idTokenRequestToken, err := actions.CreateIDTokenRequestToken(t.ID, t.Job.RunID, t.JobID)
+		if err != nil {
+			log.Error("actions.CreateIDTokenRequestToken failed: %v", err)
+		} else if taskContext != nil {
+			taskContext.Fields["gitea_id_token_request_url"] = structpb.NewStringValue(actions.IDTokenRequestURL())
+			taskContext.Fields["gitea_id_token_request_token"] = structpb.NewStringValue(idTokenRequestToken)
+		}
+	}
+
 	return taskContext
 }
 
@@ -152,66 +174,17 @@ func findTaskNeeds(ctx context.Context, task *actions_model.ActionTask) (map[str
 	if err := task.LoadAttributes(ctx); err != nil {
 		return nil, fmt.Errorf("LoadAttributes: %w", err)
 	}
-	if len(task.Job.Needs) == 0 {
-		return nil, nil
-	}
-	needs := container.SetOf(task.Job.Needs...)
-
-	jobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: task.Job.RunID})
+	needs, err := actions.FindJobNeeds(ctx, task.Job)
 	if err != nil {
-		return nil, fmt.Errorf("FindRunJobs: %w", err)
-	}
-
-	jobIDJobs := make(map[string][]*actions_model.ActionRunJob)
-	for _, job := range jobs {
-		jobIDJobs[job.JobID] = append(jobIDJobs[job.JobID], job)
+		return nil, fmt.Errorf("FindJobNeeds: %w", err)
 	}
 
 	ret := make(map[string]*runnerv1.TaskNeed, len(needs))
-	for jobID, jobsWithSameID := range jobIDJobs {
-		if !needs.Contains(jobID) {
-			continue
-		}
-		var jobOutputs map[string]string
-		for _, job := range jobsWithSameID {
-			if job.TaskID == 0 || !job.Status.IsDone() {
-				// it shouldn't happen, or the job has been rerun
-				continue
-			}
-			got, err := actions_model.FindTaskOutputByTaskID(ctx, job.TaskID)
-			if err != nil {
-				return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
-			}
-			outputs := make(map[string]string, len(got))
-			for _, v := range got {
-				outputs[v.OutputKey] = v.OutputValue
-			}
-			if len(jobOutputs) == 0 {
-				jobOutputs = outputs
-			} else {
-				jobOutputs = mergeTwoOutputs(outputs, jobOutputs)
-			}
-		}
+	for jobID, need := range needs {
 		ret[jobID] = &runnerv1.TaskNeed{
-			Outputs: jobOutputs,
-			Result:  runnerv1.Result(actions_model.AggregateJobStatus(jobsWithSameID)),
+			Outputs: need.Outputs,
+			Result:  runnerv1.Result(need.Status),
 		}
 	}
-
 	return ret, nil
 }
-
-// mergeTwoOutputs merges two outputs from two different ActionRunJobs
-// Values with the same output name may be overridden. The user should ensure the output names are unique.
-// See https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#using-job-outputs-in-a-matrix-job
-func mergeTwoOutputs(o1, o2 map[string]string) map[string]string {
-	ret := make(map[string]string, len(o1))
-	for k1, v1 := range o1 {
-		if len(v1) > 0 {
-			ret[k1] = v1
-		} else {
-			ret[k1] = o2[k1]
-		}
-	}
-	return ret
-}
@@ -0,0 +1,66 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+)
+
+// summaryRoutes receives the content of `$GITHUB_STEP_SUMMARY` of the steps uploaded by the runners
+func summaryRoutes(m *web.Router) {
+	m.Put("/summaries/{step_index}", uploadStepSummary)
+}
+
+// uploadStepSummary saves the markdown in the body as the summary of the step,
+// the request is authenticated by the runtime token of the task.
+func uploadStepSummary(resp http.ResponseWriter, req *http.Request) {
+	ctx, cleanUp := context.NewBaseContext(resp, req)
+	defer cleanUp()
+
+	if !strings.HasPrefix(req.Header.Get("Authorization"), "Bearer ") {
+		ctx.Error(http.StatusUnauthorized, "Bad authorization header")
+		return
+	}
+	taskID, err := actions_service.ParseAuthorizationToken(req)
+	if err != nil || taskID == 0 {
+		ctx.Error(http.StatusUnauthorized, "Invalid token")
+		return
+	}
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, "Task not found")
+			return
+		}
+		log.Error("GetTaskByID: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error getting the task")
+		return
+	}
+
+	content, err := io.ReadAll(io.LimitReader(req.Body, actions_model.MaxTaskSummarySize+1))
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "Error reading the summary")
+		return
+	}
+	if err := actions_service.UploadStepSummary(ctx, task, ctx.PathParamInt64("step_index"), string(content)); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("UploadStepSummary: %v", err)
+		ctx.Error(http.StatusInternalServerError, "Error saving the summary")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -34,9 +34,11 @@ import (
 	"code.gitea.io/gitea/routers/api/packages/rpm"
 	"code.gitea.io/gitea/routers/api/packages/rubygems"
 	"code.gitea.io/gitea/routers/api/packages/swift"
+	"code.gitea.io/gitea/routers/api/packages/terraform"
 	"code.gitea.io/gitea/routers/api/packages/vagrant"
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/maintenance"
 )
 
 func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
@@ -89,6 +91,14 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 	}
 }
 
+// maintenanceMode rejects the uploads and deletions of packages while the instance is in the maintenance mode
+func maintenanceMode(ctx *context.Context) {
+	if maintenance.IsReadOnlyRequest(ctx.Req) || !maintenance.IsEnabled(ctx) {
+		return
+	}
+	ctx.Error(http.StatusServiceUnavailable, maintenance.Message(ctx))
+}
+
 func verifyAuth(r *web.Router, authMethods []auth.Method) {
 	if setting.Service.EnableReverseProxyAuth {
 		authMethods = append(authMethods, &auth.ReverseProxy{})
@@ -114,6 +124,8 @@ func CommonRoutes() *web.Router {
 
 	r.Use(context.PackageContexter())
 
+	r.Use(maintenanceMode)
+
 	verifyAuth(r, []auth.Method{
 		&auth.OAuth2{},
 		&auth.Basic{},
@@ -122,6 +134,18 @@ func CommonRoutes() *web.Router {
 		&chef.Auth{},
 	})
 
+	// Terraform registry protocols, the base urls are announced by the service discovery
+	r.Group("/-/terraform", func() {
+		r.Group("/modules/v1/{username}/{name}/{system}", func() {
+			r.Get("/versions", terraform.ListModuleVersions)
+			r.Get("/{version}/download", terraform.DownloadModule)
+		})
+		r.Group("/providers/v1/{username}/{provider}", func() {
+			r.Get("/versions", terraform.ListProviderVersions)
+			r.Get("/{version}/download/{os}/{arch}", terraform.GetProviderPackage)
+		})
+	}, context.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
+
 	r.Group("/{username}", func() {
 		r.Group("/alpine", func() {
 			r.Get("/key", alpine.GetRepositoryKey)
@@ -695,6 +719,20 @@ func CommonRoutes() *web.Router {
 				r.Get("/identifiers", swift.CheckAcceptMediaType(swift.AcceptJSON), swift.LookupPackageIdentifiers)
 			}, reqPackageAccess(perm.AccessModeRead))
 		})
+		r.Group("/terraform", func() {
+			r.Group("/modules/{name}/{system}/{version}", func() {
+				r.Put("", reqPackageAccess(perm.AccessModeWrite), terraform.UploadModule)
+				r.Delete("", reqPackageAccess(perm.AccessModeWrite), terraform.DeleteModule)
+				r.Get("/{filename}", terraform.DownloadModuleFile)
+			})
+			r.Group("/providers/{provider}/{version}", func() {
+				r.Delete("", reqPackageAccess(perm.AccessModeWrite), terraform.DeleteProvider)
+				r.Group("/{filename}", func() {
+					r.Get("", terraform.DownloadProviderFile)
+					r.Put("", reqPackageAccess(perm.AccessModeWrite), terraform.UploadProviderFile)
+				})
+			})
+		}, reqPackageAccess(perm.AccessModeRead))
 		r.Group("/vagrant", func() {
 			r.Group("/authenticate", func() {
 				r.Get("", vagrant.CheckAuthenticate)
@@ -721,6 +759,8 @@ func ContainerRoutes() *web.Router {
 
 	r.Use(context.PackageContexter())
 
+	r.Use(maintenanceMode)
+
 	verifyAuth(r, []auth.Method{
 		&auth.Basic{},
 		&container.Auth{},
@@ -755,12 +795,14 @@ func ContainerRoutes() *web.Router {
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteManifest)
 			})
 			r.Get("/tags/list", container.GetTagList)
+			r.Get("/referrers/{digest}", container.GetReferrers)
 		}, container.VerifyImageName)
 
 		var (
 			blobsUploadsPattern = regexp.MustCompile(`\A(.+)/blobs/uploads/([a-zA-Z0-9-_.=]+)\z`)
 			blobsPattern        = regexp.MustCompile(`\A(.+)/blobs/([^/]+)\z`)
 			manifestsPattern    = regexp.MustCompile(`\A(.+)/manifests/([^/]+)\z`)
+			referrersPattern    = regexp.MustCompile(`\A(.+)/referrers/([^/]+)\z`)
 		)
 
 		// Manual mapping of routes because {image} can contain slashes which chi does not support
@@ -875,6 +917,19 @@ func ContainerRoutes() *web.Router {
 				}
 				return
 			}
+			m = referrersPattern.FindStringSubmatch(path)
+			if len(m) == 3 && isGet {
+				ctx.SetPathParam("image", m[1])
+				container.VerifyImageName(ctx)
+				if ctx.Written() {
+					return
+				}
+
+				ctx.SetPathParam("digest", m[2])
+
+				container.GetReferrers(ctx)
+				return
+			}
 
 			ctx.Status(http.StatusNotFound)
 		})
@@ -120,19 +120,23 @@ func Authenticate(ctx *context.Context) {
 	}
 
 	packageScope := auth_service.GetAccessScope(ctx.Data)
-	if has, err := packageScope.HasAnyScope(
-		auth_model.AccessTokenScopeReadPackage,
-		auth_model.AccessTokenScopeWritePackage,
-		auth_model.AccessTokenScopeAll,
-	); !has {
-		if err != nil {
-			log.Error("Error checking access scope: %v", err)
+	actionsTaskID, _ := ctx.Data["ActionsTaskID"].(int64)
+	// the access of the Actions token is limited by the permissions of its task
+	if actionsTaskID == 0 {
+		if has, err := packageScope.HasAnyScope(
+			auth_model.AccessTokenScopeReadPackage,
+			auth_model.AccessTokenScopeWritePackage,
+			auth_model.AccessTokenScopeAll,
+		); !has {
+			if err != nil {
+				log.Error("Error checking access scope: %v", err)
+			}
+			apiError(ctx, http.StatusForbidden, nil)
+			return
 		}
-		apiError(ctx, http.StatusForbidden, nil)
-		return
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, packageScope)
+	token, err := packages_service.CreateAuthorizationToken(ctx.Doer, packageScope, actionsTaskID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -43,6 +43,10 @@ func (a *Auth) Verify(req *http.Request, w http.ResponseWriter, store auth.DataS
 		store.GetData()["IsApiToken"] = true
 		store.GetData()["ApiTokenScope"] = packageMeta.Scope
 	}
+	if u.IsActions() && packageMeta.ActionsTaskID > 0 {
+		store.GetData()["IsActionsToken"] = true
+		store.GetData()["ActionsTaskID"] = packageMeta.ActionsTaskID
+	}
 
 	return u, nil
 }
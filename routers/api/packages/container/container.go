@@ -32,6 +32,8 @@ import (
 	container_service "code.gitea.io/gitea/services/packages/container"
 
 	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // maximum size of a container manifest
@@ -44,13 +46,15 @@ var (
 )
 
 type containerHeaders struct {
-	Status        int
-	ContentDigest string
-	UploadUUID    string
-	Range         string
-	Location      string
-	ContentType   string
-	ContentLength int64
+	Status         int
+	ContentDigest  string
+	UploadUUID     string
+	Range          string
+	Location       string
+	ContentType    string
+	ContentLength  int64
+	Subject        string
+	FiltersApplied string
 }
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#legacy-docker-support-http-headers
@@ -74,6 +78,12 @@ func setResponseHeaders(resp http.ResponseWriter, h *containerHeaders) {
 		resp.Header().Set("Docker-Content-Digest", h.ContentDigest)
 		resp.Header().Set("ETag", fmt.Sprintf(`"%s"`, h.ContentDigest))
 	}
+	if h.Subject != "" {
+		resp.Header().Set("OCI-Subject", h.Subject)
+	}
+	if h.FiltersApplied != "" {
+		resp.Header().Set("OCI-Filters-Applied", h.FiltersApplied)
+	}
 	resp.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
 	resp.WriteHeader(h.Status)
 }
@@ -158,7 +168,7 @@ func Authenticate(ctx *context.Context) {
 		}
 
 		u = user_model.NewGhostUser()
-	} else {
+	} else if ctx.Data["IsActionsToken"] != true { // the access of the Actions token is limited by the permissions of its task
 		if has, err := packageScope.HasAnyScope(
 			auth_model.AccessTokenScopeReadPackage,
 			auth_model.AccessTokenScopeWritePackage,
@@ -172,7 +182,8 @@ func Authenticate(ctx *context.Context) {
 		}
 	}
 
-	token, err := packages_service.CreateAuthorizationToken(u, packageScope)
+	actionsTaskID, _ := ctx.Data["ActionsTaskID"].(int64)
+	token, err := packages_service.CreateAuthorizationToken(u, packageScope, actionsTaskID)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
@@ -499,11 +510,14 @@ func getBlobFromContext(ctx *context.Context) (*packages_model.PackageFileDescri
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#checking-if-content-exists-in-the-registry
 func HeadBlob(ctx *context.Context) {
-	blob, err := getBlobFromContext(ctx)
+	blob, err := getBlobWithRemote(ctx)
 	if err != nil {
-		if err == container_model.ErrContainerBlobNotExist {
+		switch err {
+		case container_model.ErrContainerBlobNotExist:
 			apiErrorDefined(ctx, errBlobUnknown)
-		} else {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -518,11 +532,14 @@ func HeadBlob(ctx *context.Context) {
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-blobs
 func GetBlob(ctx *context.Context) {
-	blob, err := getBlobFromContext(ctx)
+	blob, err := getBlobWithRemote(ctx)
 	if err != nil {
-		if err == container_model.ErrContainerBlobNotExist {
+		switch err {
+		case container_model.ErrContainerBlobNotExist:
 			apiErrorDefined(ctx, errBlobUnknown)
-		} else {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -602,6 +619,7 @@ func UploadManifest(ctx *context.Context) {
 	setResponseHeaders(ctx.Resp, &containerHeaders{
 		Location:      fmt.Sprintf("/v2/%s/%s/manifests/%s", ctx.Package.Owner.LowerName, mci.Image, reference),
 		ContentDigest: digest,
+		Subject:       mci.Subject,
 		Status:        http.StatusCreated,
 	})
 }
@@ -637,11 +655,14 @@ func getManifestFromContext(ctx *context.Context) (*packages_model.PackageFileDe
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#checking-if-content-exists-in-the-registry
 func HeadManifest(ctx *context.Context) {
-	manifest, err := getManifestFromContext(ctx)
+	manifest, err := getManifestWithRemote(ctx)
 	if err != nil {
-		if err == container_model.ErrContainerBlobNotExist {
+		switch err {
+		case container_model.ErrContainerBlobNotExist:
 			apiErrorDefined(ctx, errManifestUnknown)
-		} else {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -657,11 +678,14 @@ func HeadManifest(ctx *context.Context) {
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pulling-manifests
 func GetManifest(ctx *context.Context) {
-	manifest, err := getManifestFromContext(ctx)
+	manifest, err := getManifestWithRemote(ctx)
 	if err != nil {
-		if err == container_model.ErrContainerBlobNotExist {
+		switch err {
+		case container_model.ErrContainerBlobNotExist:
 			apiErrorDefined(ctx, errManifestUnknown)
-		} else {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -780,6 +804,77 @@ func GetTagList(ctx *context.Context) {
 	})
 }
 
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
+func GetReferrers(ctx *context.Context) {
+	subject := ctx.PathParam("digest")
+	if digest.Digest(subject).Validate() != nil {
+		apiErrorDefined(ctx, errDigestInvalid)
+		return
+	}
+
+	artifactType := ctx.FormTrim("artifactType")
+
+	pvs, err := container_model.GetReferrers(ctx, &container_model.ReferrerSearchOptions{
+		OwnerID:      ctx.Package.Owner.ID,
+		Image:        ctx.PathParam("image"),
+		Subject:      subject,
+		ArtifactType: artifactType,
+	})
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	index := oci.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: oci.MediaTypeImageIndex,
+		Manifests: make([]oci.Descriptor, 0, len(pds)),
+	}
+
+	// the same manifest may be stored for a tag and its digest
+	seen := make(map[string]bool, len(pds))
+	for _, pd := range pds {
+		pfd := container_model.GetManifestFileDescriptor(pd)
+		if pfd == nil {
+			continue
+		}
+
+		manifestDigest := pfd.Properties.GetByName(container_module.PropertyDigest)
+		if seen[manifestDigest] {
+			continue
+		}
+		seen[manifestDigest] = true
+
+		metadata := pd.Metadata.(*container_module.Metadata)
+
+		index.Manifests = append(index.Manifests, oci.Descriptor{
+			MediaType:    pfd.Properties.GetByName(container_module.PropertyMediaType),
+			Digest:       digest.Digest(manifestDigest),
+			Size:         pfd.Blob.Size,
+			ArtifactType: metadata.ArtifactType,
+			Annotations:  metadata.Annotations,
+		})
+	}
+
+	headers := &containerHeaders{
+		Status:      http.StatusOK,
+		ContentType: oci.MediaTypeImageIndex,
+	}
+	if artifactType != "" {
+		headers.FiltersApplied = "artifactType"
+	}
+	setResponseHeaders(ctx.Resp, headers)
+	if err := json.NewEncoder(ctx.Resp).Encode(index); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
+
 // FIXME: Workaround to be removed in v1.20
 // https://github.com/go-gitea/gitea/issues/19586
 func workaroundGetContainerBlob(ctx *context.Context, opts *container_model.BlobSearchOptions) (*packages_model.PackageFileDescriptor, error) {
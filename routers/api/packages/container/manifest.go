@@ -47,6 +47,7 @@ type manifestCreationInfo struct {
 	Image      string
 	Reference  string
 	IsTagged   bool
+	Subject    string
 	Properties map[string]string
 }
 
@@ -64,6 +65,13 @@ func processManifest(ctx context.Context, mci *manifestCreationInfo, buf *packag
 		return "", err
 	}
 
+	if index.Subject != nil {
+		if index.Subject.Digest.Validate() != nil {
+			return "", errManifestInvalid.WithMessage("Subject digest is invalid")
+		}
+		mci.Subject = string(index.Subject.Digest)
+	}
+
 	if !isValidMediaType(mci.MediaType) {
 		mci.MediaType = index.MediaType
 		if !isValidMediaType(mci.MediaType) {
@@ -79,6 +87,22 @@ func processManifest(ctx context.Context, mci *manifestCreationInfo, buf *packag
 	return "", errManifestInvalid
 }
 
+// isArtifactManifest checks if the manifest describes an artifact instead of an image
+func isArtifactManifest(manifest *oci.Manifest) bool {
+	return manifest.ArtifactType != "" || manifest.Config.MediaType == oci.MediaTypeEmptyJSON
+}
+
+// setReferrerMetadata stores the information needed to list the manifest as referrer of its subject
+func setReferrerMetadata(mci *manifestCreationInfo, metadata *container_module.Metadata, artifactType string, annotations map[string]string) {
+	if mci.Subject == "" {
+		return
+	}
+
+	metadata.Subject = mci.Subject
+	metadata.ArtifactType = artifactType
+	metadata.Annotations = annotations
+}
+
 func processImageManifest(ctx context.Context, mci *manifestCreationInfo, buf *packages_module.HashedBuffer) (string, error) {
 	manifestDigest := ""
 
@@ -107,16 +131,31 @@ func processImageManifest(ctx context.Context, mci *manifestCreationInfo, buf *p
 			return err
 		}
 
-		configReader, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(configDescriptor.Blob.HashSHA256))
-		if err != nil {
-			return err
+		var metadata *container_module.Metadata
+		if isArtifactManifest(&manifest) {
+			// the config of an artifact is no image config
+			metadata = &container_module.Metadata{
+				Type: container_module.TypeOCI,
+			}
+		} else {
+			configReader, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(configDescriptor.Blob.HashSHA256))
+			if err != nil {
+				return err
+			}
+			defer configReader.Close()
+
+			metadata, err = container_module.ParseImageConfig(manifest.Config.MediaType, configReader)
+			if err != nil {
+				return err
+			}
 		}
-		defer configReader.Close()
 
-		metadata, err := container_module.ParseImageConfig(manifest.Config.MediaType, configReader)
-		if err != nil {
-			return err
+		// https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidelines-for-artifact-usage
+		artifactType := manifest.ArtifactType
+		if artifactType == "" {
+			artifactType = manifest.Config.MediaType
 		}
+		setReferrerMetadata(mci, metadata, artifactType, manifest.Annotations)
 
 		blobReferences := make([]*blobReference, 0, 1+len(manifest.Layers))
 
@@ -219,6 +258,7 @@ func processImageManifestIndex(ctx context.Context, mci *manifestCreationInfo, b
 			Type:      container_module.TypeOCI,
 			Manifests: make([]*container_module.Manifest, 0, len(index.Manifests)),
 		}
+		setReferrerMetadata(mci, metadata, index.ArtifactType, index.Annotations)
 
 		for _, manifest := range index.Manifests {
 			if !isImageManifestMediaType(manifest.MediaType) {
@@ -386,6 +426,18 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 			return nil, err
 		}
 	}
+	if metadata.Subject != "" {
+		props := map[string]string{
+			container_module.PropertyManifestSubject: metadata.Subject,
+			container_module.PropertyArtifactType:    metadata.ArtifactType,
+		}
+		for name, value := range props {
+			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, name, value); err != nil {
+				log.Error("Error setting package version property: %v", err)
+				return nil, err
+			}
+		}
+	}
 
 	return pv, nil
 }
@@ -0,0 +1,296 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package container
+
+import (
+	std_ctx "context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	container_model "code.gitea.io/gitea/models/packages/container"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+	"code.gitea.io/gitea/services/context"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+
+	digest "github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// remoteManifestAccept lists the manifest media types which can be stored
+var remoteManifestAccept = http.Header{"Accept": []string{strings.Join([]string{
+	oci.MediaTypeImageManifest,
+	oci.MediaTypeImageIndex,
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}, ", ")}}
+
+func remoteTagMetadataKey(image, tag string) string {
+	return "manifest:" + image + ":" + tag
+}
+
+// getManifestWithRemote returns the manifest of the reference. It's fetched from the remote registry of the owner if it doesn't exist.
+// A tag fetched from the remote registry is updated once the metadata TTL expires, tags pushed to this registry are never replaced.
+func getManifestWithRemote(ctx *context.Context) (*packages_model.PackageFileDescriptor, error) {
+	manifest, err := getManifestFromContext(ctx)
+	if err != nil && err != container_model.ErrContainerBlobNotExist {
+		return nil, err
+	}
+
+	reference := ctx.PathParam("reference")
+	if manifest == nil && digest.Digest(reference).Validate() != nil && !referencePattern.MatchString(reference) {
+		return nil, err
+	}
+
+	c, cerr := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeContainer)
+	if cerr != nil {
+		return nil, cerr
+	}
+	if c == nil {
+		return manifest, err
+	}
+
+	updated, err := syncRemoteManifest(ctx, c, manifest)
+	if err != nil {
+		if manifest != nil {
+			log.Warn("Unable to update the manifest %s:%s from the remote registry %s, serving the cached manifest: %v", ctx.PathParam("image"), reference, c.Remote.URL, err)
+			return manifest, nil
+		}
+		if err == remote_service.ErrNotFound {
+			return nil, container_model.ErrContainerBlobNotExist
+		}
+		return nil, err
+	}
+	if !updated {
+		return manifest, nil
+	}
+	return getManifestFromContext(ctx)
+}
+
+// syncRemoteManifest fetches the manifest of the reference if it doesn't exist or if the tag has changed in the remote registry
+func syncRemoteManifest(ctx *context.Context, c *remote_service.Client, manifest *packages_model.PackageFileDescriptor) (bool, error) {
+	image := ctx.PathParam("image")
+	reference := ctx.PathParam("reference")
+
+	if digest.Digest(reference).Validate() == nil {
+		if manifest != nil {
+			return false, nil
+		}
+		_, err := cacheRemoteManifest(ctx, c, image, reference, false)
+		return err == nil, err
+	}
+
+	key := remoteTagMetadataKey(image, reference)
+
+	if manifest != nil {
+		// only tags which were fetched from the remote registry are updated
+		prm, err := packages_model.GetRemoteMetadata(ctx, c.Remote.ID, key)
+		if err != nil {
+			if err == packages_model.ErrPackageRemoteMetadataNotExist {
+				return false, nil
+			}
+			return false, err
+		}
+		if prm.Content != manifest.Properties.GetByName(container_module.PropertyDigest) {
+			return false, nil
+		}
+	}
+
+	remoteDigest, err := c.Metadata(ctx, key, func(ctx std_ctx.Context) ([]byte, error) {
+		d, err := getRemoteManifestDigest(ctx, c, image, reference)
+		return []byte(d), err
+	})
+	if err != nil {
+		return false, err
+	}
+	if manifest != nil && string(remoteDigest) == manifest.Properties.GetByName(container_module.PropertyDigest) {
+		return false, nil
+	}
+
+	cachedDigest, err := cacheRemoteManifest(ctx, c, image, reference, true)
+	if err != nil {
+		return false, err
+	}
+	// the tag may have changed since the digest was requested
+	return true, packages_model.SetRemoteMetadata(ctx, c.Remote.ID, key, cachedDigest)
+}
+
+func remoteManifestPath(image, reference string) string {
+	return fmt.Sprintf("v2/%s/manifests/%s", image, reference)
+}
+
+// getRemoteManifestDigest returns the digest of the manifest the tag references in the remote registry
+func getRemoteManifestDigest(ctx std_ctx.Context, c *remote_service.Client, image, tag string) (string, error) {
+	resp, err := c.Get(ctx, http.MethodHead, remoteManifestPath(image, tag), remoteManifestAccept)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	d := resp.Header.Get("Docker-Content-Digest")
+	if digest.Digest(d).Validate() == nil {
+		return d, nil
+	}
+
+	// the header is optional, so the digest has to be calculated
+	buf, _, err := downloadRemoteManifest(ctx, c, image, tag)
+	if err != nil {
+		return "", err
+	}
+	defer buf.Close()
+
+	return digestFromHashSummer(buf), nil
+}
+
+func downloadRemoteManifest(ctx std_ctx.Context, c *remote_service.Client, image, reference string) (*packages_module.HashedBuffer, string, error) {
+	resp, err := c.Get(ctx, http.MethodGet, remoteManifestPath(image, reference), remoteManifestAccept)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	maxSize := maxManifestSize + 1
+	buf, err := packages_module.CreateHashedBufferFromReaderWithSize(&io.LimitedReader{R: resp.Body, N: int64(maxSize)}, maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+	if buf.Size() > maxManifestSize {
+		buf.Close()
+		return nil, "", errManifestInvalid.WithMessage("Manifest exceeds maximum size")
+	}
+	return buf, resp.Header.Get("Content-Type"), nil
+}
+
+// cacheRemoteManifest fetches the manifest and the blobs it references from the remote registry and stores them.
+// The manifests referenced by an image index are fetched too.
+func cacheRemoteManifest(ctx *context.Context, c *remote_service.Client, image, reference string, isTagged bool) (string, error) {
+	var manifestDigest string
+	err := c.LockAndDo(ctx, image+"@"+reference, func(lockCtx std_ctx.Context) error {
+		buf, mediaType, err := downloadRemoteManifest(lockCtx, c, image, reference)
+		if err != nil {
+			return err
+		}
+		defer buf.Close()
+
+		if !isTagged && digestFromHashSummer(buf) != reference {
+			return errDigestInvalid
+		}
+
+		var manifest struct {
+			oci.Manifest
+			Manifests []oci.Descriptor `json:"manifests"`
+		}
+		if err := json.NewDecoder(buf).Decode(&manifest); err != nil {
+			return err
+		}
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		for _, m := range manifest.Manifests {
+			_, err := container_model.GetContainerBlob(lockCtx, &container_model.BlobSearchOptions{
+				OwnerID:    ctx.Package.Owner.ID,
+				Image:      image,
+				Digest:     string(m.Digest),
+				IsManifest: true,
+			})
+			if err == container_model.ErrContainerBlobNotExist {
+				_, err = cacheRemoteManifest(ctx, c, image, string(m.Digest), false)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		blobs := manifest.Layers
+		if manifest.Config.Digest != "" {
+			blobs = append(blobs, manifest.Config)
+		}
+		for _, b := range blobs {
+			if err := cacheRemoteBlob(ctx, c, image, string(b.Digest)); err != nil {
+				return err
+			}
+		}
+
+		manifestDigest, err = processManifest(lockCtx, &manifestCreationInfo{
+			MediaType: mediaType,
+			Owner:     ctx.Package.Owner,
+			Creator:   remote_service.Creator(ctx.Doer),
+			Image:     image,
+			Reference: reference,
+			IsTagged:  isTagged,
+		}, buf)
+		return err
+	})
+	return manifestDigest, err
+}
+
+// getBlobWithRemote returns the blob of the digest, it's fetched from the remote registry of the owner if it doesn't exist
+func getBlobWithRemote(ctx *context.Context) (*packages_model.PackageFileDescriptor, error) {
+	blob, err := getBlobFromContext(ctx)
+	if err != container_model.ErrContainerBlobNotExist || digest.Digest(ctx.PathParam("digest")).Validate() != nil {
+		return blob, err
+	}
+
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeContainer)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, container_model.ErrContainerBlobNotExist
+	}
+
+	if err := cacheRemoteBlob(ctx, c, ctx.PathParam("image"), ctx.PathParam("digest")); err != nil {
+		if err == remote_service.ErrNotFound {
+			return nil, container_model.ErrContainerBlobNotExist
+		}
+		return nil, err
+	}
+	return getBlobFromContext(ctx)
+}
+
+// cacheRemoteBlob fetches the blob from the remote registry and stores it if it doesn't exist
+func cacheRemoteBlob(ctx *context.Context, c *remote_service.Client, image, blobDigest string) error {
+	return c.LockAndDo(ctx, image+"@"+blobDigest, func(lockCtx std_ctx.Context) error {
+		_, err := container_model.GetContainerBlob(lockCtx, &container_model.BlobSearchOptions{
+			OwnerID: ctx.Package.Owner.ID,
+			Image:   image,
+			Digest:  blobDigest,
+		})
+		if err == nil {
+			return nil
+		} else if err != container_model.ErrContainerBlobNotExist {
+			return err
+		}
+
+		buf, err := c.Download(lockCtx, fmt.Sprintf("v2/%s/blobs/%s", image, blobDigest), nil)
+		if err != nil {
+			return err
+		}
+		defer buf.Close()
+
+		if digestFromHashSummer(buf) != blobDigest {
+			return errDigestInvalid
+		}
+
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		_, err = saveAsPackageBlob(lockCtx, buf, &packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner: ctx.Package.Owner,
+				Name:  image,
+			},
+			Creator: remote_service.Creator(ctx.Doer),
+		})
+		return err
+	})
+}
@@ -4,6 +4,7 @@
 package maven
 
 import (
+	std_ctx "context"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -25,9 +26,11 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	maven_module "code.gitea.io/gitea/modules/packages/maven"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	"code.gitea.io/gitea/services/context"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 )
 
 const (
@@ -91,10 +94,6 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
-		return
-	}
 
 	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
 	if err != nil {
@@ -107,17 +106,48 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 		return pds[i].Version.CreatedUnix < pds[j].Version.CreatedUnix
 	})
 
-	xmlMetadata, err := xml.Marshal(createMetadataResponse(pds))
+	var resp *MetadataResponse
+	if len(pds) != 0 {
+		resp = createMetadataResponse(pds)
+	}
+
+	// the versions of the remote registry of the owner are included, they are fetched when they are downloaded
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeMaven)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if c != nil {
+		remote, err := getRemoteMetadata(ctx, c, params)
+		if err == nil {
+			resp = mergeRemoteMetadataResponse(resp, remote)
+		} else if err != remote_service.ErrNotFound {
+			log.Error("Unable to get the metadata of the maven package %s from the remote registry %s: %v", packageName, c.Remote.URL, err)
+			if resp == nil {
+				apiError(ctx, http.StatusBadGateway, err)
+				return
+			}
+		}
+	}
+
+	if resp == nil {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	xmlMetadata, err := xml.Marshal(resp)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	xmlMetadataWithHeader := append([]byte(xml.Header), xmlMetadata...)
 
-	latest := pds[len(pds)-1]
-	// http.TimeFormat required a UTC time, refer to https://pkg.go.dev/net/http#TimeFormat
-	lastModifed := latest.Version.CreatedUnix.AsTime().UTC().Format(http.TimeFormat)
-	ctx.Resp.Header().Set("Last-Modified", lastModifed)
+	if len(pds) != 0 {
+		latest := pds[len(pds)-1]
+		// http.TimeFormat required a UTC time, refer to https://pkg.go.dev/net/http#TimeFormat
+		lastModifed := latest.Version.CreatedUnix.AsTime().UTC().Format(http.TimeFormat)
+		ctx.Resp.Header().Set("Last-Modified", lastModifed)
+	}
 
 	ext := strings.ToLower(filepath.Ext(params.Filename))
 	if isChecksumExtension(ext) {
@@ -149,16 +179,6 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 func servePackageFile(ctx *context.Context, params parameters, serveContent bool) {
 	packageName := params.GroupID + "-" + params.ArtifactID
 
-	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, params.Version)
-	if err != nil {
-		if err == packages_model.ErrPackageNotExist {
-			apiError(ctx, http.StatusNotFound, err)
-		} else {
-			apiError(ctx, http.StatusInternalServerError, err)
-		}
-		return
-	}
-
 	filename := params.Filename
 
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -166,11 +186,22 @@ func servePackageFile(ctx *context.Context, params parameters, serveContent bool
 		filename = filename[:len(filename)-len(ext)]
 	}
 
-	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	pf, err := getPackageFile(ctx, packageName, params.Version, filename)
+	// the metadata of snapshots changes, so it's never fetched from the remote registry
+	if errors.Is(err, util.ErrNotExist) && !params.IsMeta {
+		if err = cacheRemotePackageFile(ctx, params, filename); err == nil {
+			pf, err = getPackageFile(ctx, packageName, params.Version, filename)
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageFileNotExist {
+		if errors.Is(err, util.ErrNotExist) {
 			apiError(ctx, http.StatusNotFound, err)
-		} else {
+			return
+		}
+		switch err {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
@@ -226,6 +257,14 @@ func servePackageFile(ctx *context.Context, params parameters, serveContent bool
 	helper.ServePackageFile(ctx, s, u, pf, opts)
 }
 
+func getPackageFile(ctx *context.Context, packageName, packageVersion, filename string) (*packages_model.PackageFile, error) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, packageVersion)
+	if err != nil {
+		return nil, err
+	}
+	return packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+}
+
 func mavenPkgNameKey(packageName string) string {
 	return "pkg_maven_" + packageName
 }
@@ -342,23 +381,10 @@ func UploadPackageFile(ctx *context.Context) {
 		}
 
 		if pvci.Metadata != nil {
-			pv, err := packages_model.GetVersionByNameAndVersion(ctx, pvci.Owner.ID, pvci.PackageType, pvci.Name, pvci.Version)
-			if err != nil && err != packages_model.ErrPackageNotExist {
+			if err := updateVersionMetadata(ctx, pvci); err != nil {
 				apiError(ctx, http.StatusInternalServerError, err)
 				return
 			}
-			if pv != nil {
-				raw, err := json.Marshal(pvci.Metadata)
-				if err != nil {
-					apiError(ctx, http.StatusInternalServerError, err)
-					return
-				}
-				pv.MetadataJSON = string(raw)
-				if err := packages_model.UpdateVersion(ctx, pv); err != nil {
-					apiError(ctx, http.StatusInternalServerError, err)
-					return
-				}
-			}
 		}
 
 		if _, err := buf.Seek(0, io.SeekStart); err != nil {
@@ -387,6 +413,23 @@ func UploadPackageFile(ctx *context.Context) {
 	ctx.Status(http.StatusCreated)
 }
 
+// updateVersionMetadata replaces the metadata of the package version if it exists already
+func updateVersionMetadata(ctx std_ctx.Context, pvci *packages_service.PackageCreationInfo) error {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, pvci.Owner.ID, pvci.PackageType, pvci.Name, pvci.Version)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			return nil
+		}
+		return err
+	}
+	raw, err := json.Marshal(pvci.Metadata)
+	if err != nil {
+		return err
+	}
+	pv.MetadataJSON = string(raw)
+	return packages_model.UpdateVersion(ctx, pv)
+}
+
 func isChecksumExtension(ext string) bool {
 	return ext == extensionMD5 || ext == extensionSHA1 || ext == extensionSHA256 || ext == extensionSHA512
 }
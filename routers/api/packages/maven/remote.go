@@ -0,0 +1,172 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package maven
+
+import (
+	std_ctx "context"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/globallock"
+	maven_module "code.gitea.io/gitea/modules/packages/maven"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/context"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+// errInvalidChecksum indicates that the file fetched from the remote registry doesn't match its checksum
+var errInvalidChecksum = util.NewInvalidArgumentErrorf("checksum of the remote file is invalid")
+
+// remotePath returns the path of the file of the package in the remote registry, the version is optional
+func remotePath(params parameters, version, filename string) string {
+	parts := append(strings.Split(params.GroupID, "."), params.ArtifactID)
+	if version != "" {
+		parts = append(parts, version)
+	}
+	return strings.Join(append(parts, filename), "/")
+}
+
+// getRemoteMetadata returns the package index of the remote registry, it's cached until the metadata TTL expires
+func getRemoteMetadata(ctx std_ctx.Context, c *remote_service.Client, params parameters) (*MetadataResponse, error) {
+	content, err := c.Metadata(ctx, params.GroupID+":"+params.ArtifactID, func(ctx std_ctx.Context) ([]byte, error) {
+		return c.ReadAll(ctx, remotePath(params, "", mavenMetadataFile), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp MetadataResponse
+	if err := xml.Unmarshal(content, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// mergeRemoteMetadataResponse adds the versions which only exist locally to the package index of the remote registry.
+// If there are such versions, the latest and release version of the local package index are used.
+func mergeRemoteMetadataResponse(local, remote *MetadataResponse) *MetadataResponse {
+	if local == nil {
+		return remote
+	}
+
+	remoteVersions := make(map[string]bool, len(remote.Version))
+	for _, v := range remote.Version {
+		remoteVersions[v] = true
+	}
+
+	hasLocalVersions := false
+	for _, v := range local.Version {
+		if !remoteVersions[v] {
+			remote.Version = append(remote.Version, v)
+			hasLocalVersions = true
+		}
+	}
+	if hasLocalVersions {
+		remote.Latest = local.Latest
+		if local.Release != "" {
+			remote.Release = local.Release
+		}
+	}
+	return remote
+}
+
+// cacheRemotePackageFile fetches the package file from the remote registry and stores it in a package version of the owner.
+// packages_model.ErrPackageNotExist is returned if the owner has no remote registry or it doesn't have the package file.
+func cacheRemotePackageFile(ctx *context.Context, params parameters, filename string) error {
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeMaven)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return packages_model.ErrPackageNotExist
+	}
+
+	packageName := params.GroupID + "-" + params.ArtifactID
+
+	// the lock is shared with uploads of the package
+	return globallock.LockAndDo(ctx, mavenPkgNameKey(packageName), func(lockCtx std_ctx.Context) error {
+		if _, err := getPackageFile(ctx, packageName, params.Version, filename); err == nil {
+			return nil
+		} else if err != packages_model.ErrPackageNotExist && err != packages_model.ErrPackageFileNotExist {
+			return err
+		}
+
+		path := remotePath(params, params.Version, filename)
+
+		buf, err := c.Download(lockCtx, path, nil)
+		if err != nil {
+			if err == remote_service.ErrNotFound {
+				return packages_model.ErrPackageNotExist
+			}
+			return err
+		}
+		defer buf.Close()
+
+		// the checksum files are optional, the first field is the checksum
+		checksum, err := c.ReadAll(lockCtx, path+extensionSHA1, nil)
+		if err == nil {
+			fields := strings.Fields(string(checksum))
+			_, hashSHA1, _, _ := buf.Sums()
+			if len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(hashSHA1)) {
+				return errInvalidChecksum
+			}
+		} else if err != remote_service.ErrNotFound {
+			return err
+		}
+
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		creator := remote_service.Creator(ctx.Doer)
+
+		pvci := &packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeMaven,
+				Name:        packageName,
+				Version:     params.Version,
+			},
+			SemverCompatible: false,
+			Creator:          creator,
+		}
+		pfci := &packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+			Creator: creator,
+			Data:    buf,
+		}
+
+		if strings.ToLower(filepath.Ext(filename)) == extensionPom {
+			pfci.IsLead = true
+
+			pvci.Metadata, err = maven_module.ParsePackageMetaData(buf)
+			if err != nil {
+				return err
+			}
+
+			if pvci.Metadata != nil {
+				if err := updateVersionMetadata(lockCtx, pvci); err != nil {
+					return err
+				}
+			}
+
+			if _, err := buf.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		_, _, err = packages_service.CreatePackageOrAddFileToExisting(lockCtx, pvci, pfci)
+		if err == packages_model.ErrDuplicatePackageFile {
+			return nil
+		}
+		return err
+	})
+}
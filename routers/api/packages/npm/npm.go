@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
@@ -17,6 +18,7 @@ import (
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/optional"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	npm_module "code.gitea.io/gitea/modules/packages/npm"
@@ -25,6 +27,7 @@ import (
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	"code.gitea.io/gitea/services/context"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 
 	"github.com/hashicorp/go-version"
 )
@@ -52,29 +55,50 @@ func packageNameFromParams(ctx *context.Context) string {
 }
 
 // PackageMetadata returns the metadata for a single package
+// The versions of the remote registry of the owner are included, they are fetched when they are downloaded.
 func PackageMetadata(ctx *context.Context) {
 	packageName := packageNameFromParams(ctx)
+	registryURL := setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/npm"
 
 	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, err)
-		return
+
+	var resp *npm_module.PackageMetadata
+	if len(pvs) != 0 {
+		pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp = createPackageMetadataResponse(registryURL, pds)
 	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeNpm)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
+	if c != nil {
+		remote, err := getRemotePackageMetadata(ctx, c, packageName)
+		if err == nil {
+			resp = mergeRemotePackageMetadata(registryURL, packageName, resp, remote)
+		} else if err != remote_service.ErrNotFound {
+			log.Error("Unable to get the metadata of the npm package %s from the remote registry %s: %v", packageName, c.Remote.URL, err)
+			if resp == nil {
+				apiError(ctx, http.StatusBadGateway, err)
+				return
+			}
+		}
+	}
 
-	resp := createPackageMetadataResponse(
-		setting.AppURL+"api/packages/"+ctx.Package.Owner.Name+"/npm",
-		pds,
-	)
+	if resp == nil {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
 
 	ctx.JSON(http.StatusOK, resp)
 }
@@ -85,24 +109,38 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.PathParam("version")
 	filename := ctx.PathParam("filename")
 
-	s, u, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypeNpm,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	getFileStream := func() (io.ReadSeekCloser, *url.URL, *packages_model.PackageFile, error) {
+		return packages_service.GetFileStreamByPackageNameAndVersion(
+			ctx,
+			&packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeNpm,
+				Name:        packageName,
+				Version:     packageVersion,
+			},
+			&packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+		)
+	}
+
+	s, u, pf, err := getFileStream()
+	if err == packages_model.ErrPackageNotExist {
+		if err = cacheRemotePackageVersion(ctx, packageName, packageVersion, filename); err == nil {
+			s, u, pf, err = getFileStream()
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if errors.Is(err, util.ErrNotExist) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
-		apiError(ctx, http.StatusInternalServerError, err)
+		switch err {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
@@ -111,20 +149,38 @@ func DownloadPackageFile(ctx *context.Context) {
 
 // DownloadPackageFileByName finds the version and serves the contents of a package
 func DownloadPackageFileByName(ctx *context.Context) {
+	packageName := packageNameFromParams(ctx)
 	filename := ctx.PathParam("filename")
 
-	pvs, _, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
-		OwnerID: ctx.Package.Owner.ID,
-		Type:    packages_model.TypeNpm,
-		Name: packages_model.SearchValue{
-			ExactMatch: true,
-			Value:      packageNameFromParams(ctx),
-		},
-		HasFileWithName: filename,
-		IsInternal:      optional.Some(false),
-	})
+	searchVersions := func() ([]*packages_model.PackageVersion, error) {
+		pvs, _, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			OwnerID: ctx.Package.Owner.ID,
+			Type:    packages_model.TypeNpm,
+			Name: packages_model.SearchValue{
+				ExactMatch: true,
+				Value:      packageName,
+			},
+			HasFileWithName: filename,
+			IsInternal:      optional.Some(false),
+		})
+		return pvs, err
+	}
+
+	pvs, err := searchVersions()
+	if err == nil && len(pvs) == 0 {
+		if err = cacheRemotePackageVersion(ctx, packageName, "", filename); err == nil {
+			pvs, err = searchVersions()
+		} else if err == packages_model.ErrPackageNotExist {
+			err = nil
+		}
+	}
 	if err != nil {
-		apiError(ctx, http.StatusInternalServerError, err)
+		switch err {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 	if len(pvs) != 1 {
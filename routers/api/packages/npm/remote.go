@@ -0,0 +1,193 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package npm
+
+import (
+	std_ctx "context"
+	"io"
+	"net/http"
+	"net/url"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+	"code.gitea.io/gitea/services/context"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+// remotePackageMetadata is the package metadata of the remote registry.
+// The versions are parsed one by one because the metadata of old versions is often malformed.
+type remotePackageMetadata struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	DistTags    map[string]string          `json:"dist-tags"`
+	Versions    map[string]json.RawMessage `json:"versions"`
+	Readme      string                     `json:"readme"`
+	Homepage    string                     `json:"homepage"`
+}
+
+// getRemotePackageMetadata returns the package metadata of the remote registry, it's cached until the metadata TTL expires
+func getRemotePackageMetadata(ctx std_ctx.Context, c *remote_service.Client, packageName string) (*npm_module.PackageMetadata, error) {
+	content, err := c.Metadata(ctx, packageName, func(ctx std_ctx.Context) ([]byte, error) {
+		return c.ReadAll(ctx, url.PathEscape(packageName), http.Header{"Accept": []string{"application/json"}})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rpm remotePackageMetadata
+	if err := json.Unmarshal(content, &rpm); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]*npm_module.PackageMetadataVersion, len(rpm.Versions))
+	for v, raw := range rpm.Versions {
+		var pmv npm_module.PackageMetadataVersion
+		if err := json.Unmarshal(raw, &pmv); err != nil {
+			log.Debug("Skipping malformed version %s of the remote npm package %s: %v", v, packageName, err)
+			continue
+		}
+		versions[v] = &pmv
+	}
+
+	return &npm_module.PackageMetadata{
+		ID:          rpm.Name,
+		Name:        rpm.Name,
+		Description: rpm.Description,
+		DistTags:    rpm.DistTags,
+		Versions:    versions,
+		Readme:      rpm.Readme,
+		Homepage:    rpm.Homepage,
+	}, nil
+}
+
+// mergeRemotePackageMetadata adds the versions and tags of the remote registry which don't exist locally.
+// The tarballs of the remote versions are served by this registry, they are fetched when they are requested.
+func mergeRemotePackageMetadata(registryURL, packageName string, local, remote *npm_module.PackageMetadata) *npm_module.PackageMetadata {
+	if local == nil {
+		local = &npm_module.PackageMetadata{
+			ID:          packageName,
+			Name:        packageName,
+			Description: remote.Description,
+			DistTags:    make(map[string]string),
+			Versions:    make(map[string]*npm_module.PackageMetadataVersion),
+			Readme:      remote.Readme,
+			Homepage:    remote.Homepage,
+		}
+	}
+
+	for v, pmv := range remote.Versions {
+		if _, has := local.Versions[v]; has {
+			continue
+		}
+		pmv.Dist.Tarball = tarballURL(registryURL, packageName, v, npm_module.TarballFilename(packageName, v))
+		local.Versions[v] = pmv
+	}
+	for tag, v := range remote.DistTags {
+		if _, has := local.DistTags[tag]; has {
+			continue
+		}
+		if _, has := local.Versions[v]; has {
+			local.DistTags[tag] = v
+		}
+	}
+	return local
+}
+
+// cacheRemotePackageVersion fetches the package version from the remote registry and stores it as a package version of the owner.
+// If the version is empty, it's looked up by the filename of the tarball.
+// packages_model.ErrPackageNotExist is returned if the owner has no remote registry or it doesn't have the package version.
+func cacheRemotePackageVersion(ctx *context.Context, packageName, packageVersion, filename string) error {
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypeNpm)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return packages_model.ErrPackageNotExist
+	}
+
+	metadata, err := getRemotePackageMetadata(ctx, c, packageName)
+	if err != nil {
+		if err == remote_service.ErrNotFound {
+			return packages_model.ErrPackageNotExist
+		}
+		return err
+	}
+
+	pmv := metadata.Versions[packageVersion]
+	if packageVersion == "" {
+		for v, m := range metadata.Versions {
+			if npm_module.TarballFilename(packageName, v) == filename {
+				packageVersion, pmv = v, m
+				break
+			}
+		}
+	}
+	if pmv == nil {
+		return packages_model.ErrPackageNotExist
+	}
+
+	return c.LockAndDo(ctx, packageName+"@"+packageVersion, func(lockCtx std_ctx.Context) error {
+		_, err := packages_model.GetVersionByNameAndVersion(lockCtx, ctx.Package.Owner.ID, packages_model.TypeNpm, packageName, packageVersion)
+		if err == nil {
+			return nil
+		} else if err != packages_model.ErrPackageNotExist {
+			return err
+		}
+
+		buf, err := c.Download(lockCtx, pmv.Dist.Tarball, nil)
+		if err != nil {
+			if err == remote_service.ErrNotFound {
+				return packages_model.ErrPackageNotExist
+			}
+			return err
+		}
+		defer buf.Close()
+
+		data, err := io.ReadAll(buf)
+		if err != nil {
+			return err
+		}
+
+		npmPackage, err := npm_module.ParseRemotePackageVersion(pmv, data)
+		if err != nil {
+			return err
+		}
+
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		creator := remote_service.Creator(ctx.Doer)
+
+		_, _, err = packages_service.CreatePackageAndAddFile(
+			lockCtx,
+			&packages_service.PackageCreationInfo{
+				PackageInfo: packages_service.PackageInfo{
+					Owner:       ctx.Package.Owner,
+					PackageType: packages_model.TypeNpm,
+					Name:        npmPackage.Name,
+					Version:     npmPackage.Version,
+				},
+				SemverCompatible: true,
+				Creator:          creator,
+				Metadata:         npmPackage.Metadata,
+			},
+			&packages_service.PackageFileCreationInfo{
+				PackageFileInfo: packages_service.PackageFileInfo{
+					Filename: npmPackage.Filename,
+				},
+				Creator: creator,
+				Data:    buf,
+				IsLead:  true,
+			},
+		)
+		if err == packages_model.ErrDuplicatePackageVersion {
+			return nil
+		}
+		return err
+	})
+}
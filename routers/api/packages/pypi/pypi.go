@@ -5,20 +5,25 @@ package pypi
 
 import (
 	"encoding/hex"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	"code.gitea.io/gitea/services/context"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 )
 
 // https://peps.python.org/pep-0426/#name
@@ -44,6 +49,7 @@ func apiError(ctx *context.Context, status int, obj any) {
 }
 
 // PackageMetadata returns the metadata for a single package
+// The files of the remote registry of the owner are included, they are fetched when they are downloaded.
 func PackageMetadata(ctx *context.Context) {
 	packageName := normalizer.Replace(ctx.PathParam("id"))
 
@@ -52,16 +58,37 @@ func PackageMetadata(ctx *context.Context) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, err)
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	var remoteLinks []*remoteLink
+
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypePyPI)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
+	if c != nil {
+		rp, err := getRemotePackage(ctx, c, packageName)
+		if err == nil {
+			remoteLinks = createRemoteLinks(rp, pds)
+		} else if err != remote_service.ErrNotFound {
+			log.Error("Unable to get the PyPI package %s from the remote registry %s: %v", packageName, c.Remote.URL, err)
+			if len(pds) == 0 {
+				apiError(ctx, http.StatusBadGateway, err)
+				return
+			}
+		}
+	}
+
+	if len(pds) == 0 && len(remoteLinks) == 0 {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
 
 	// sort package descriptors by version to mimic PyPI format
 	sort.Slice(pds, func(i, j int) bool {
@@ -69,8 +96,10 @@ func PackageMetadata(ctx *context.Context) {
 	})
 
 	ctx.Data["RegistryURL"] = setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/pypi"
-	ctx.Data["PackageDescriptor"] = pds[0]
+	ctx.Data["PackageName"] = packageName
+	ctx.Data["PackageLowerName"] = strings.ToLower(packageName)
 	ctx.Data["PackageDescriptors"] = pds
+	ctx.Data["RemoteLinks"] = remoteLinks
 	ctx.HTML(http.StatusOK, "api/packages/pypi/simple")
 }
 
@@ -80,24 +109,38 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.PathParam("version")
 	filename := ctx.PathParam("filename")
 
-	s, u, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypePyPI,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	getFileStream := func() (io.ReadSeekCloser, *url.URL, *packages_model.PackageFile, error) {
+		return packages_service.GetFileStreamByPackageNameAndVersion(
+			ctx,
+			&packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypePyPI,
+				Name:        packageName,
+				Version:     packageVersion,
+			},
+			&packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+		)
+	}
+
+	s, u, pf, err := getFileStream()
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if err = cacheRemotePackageFile(ctx, packageName, packageVersion, filename); err == nil {
+			s, u, pf, err = getFileStream()
+		}
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		if errors.Is(err, util.ErrNotExist) {
 			apiError(ctx, http.StatusNotFound, err)
 			return
 		}
-		apiError(ctx, http.StatusInternalServerError, err)
+		switch err {
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
 		return
 	}
 
@@ -0,0 +1,206 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pypi
+
+import (
+	std_ctx "context"
+	"encoding/hex"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/json"
+	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/validation"
+	"code.gitea.io/gitea/services/context"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+// errInvalidDigest indicates that the file fetched from the remote registry doesn't match its digest
+var errInvalidDigest = util.NewInvalidArgumentErrorf("digest of the remote file is invalid")
+
+// remotePackage is the project of the PyPI JSON API https://warehouse.pypa.io/api-reference/json.html
+type remotePackage struct {
+	Info struct {
+		Author      string `json:"author"`
+		Description string `json:"description"`
+		Summary     string `json:"summary"`
+		HomePage    string `json:"home_page"`
+		License     string `json:"license"`
+	} `json:"info"`
+	Releases map[string][]*remoteFile `json:"releases"`
+}
+
+type remoteFile struct {
+	Filename string `json:"filename"`
+	URL      string `json:"url"`
+	Digests  struct {
+		SHA256 string `json:"sha256"`
+	} `json:"digests"`
+	RequiresPython string `json:"requires_python"`
+	Yanked         bool   `json:"yanked"`
+}
+
+// remoteLink is a file of the remote registry listed in the simple index
+type remoteLink struct {
+	Version        string
+	Filename       string
+	HashSHA256     string
+	RequiresPython string
+}
+
+// getRemotePackage returns the project of the remote registry, it's cached until the metadata TTL expires
+func getRemotePackage(ctx std_ctx.Context, c *remote_service.Client, packageName string) (*remotePackage, error) {
+	content, err := c.Metadata(ctx, packageName, func(ctx std_ctx.Context) ([]byte, error) {
+		return c.ReadAll(ctx, "pypi/"+url.PathEscape(packageName)+"/json", nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rp remotePackage
+	if err := json.Unmarshal(content, &rp); err != nil {
+		return nil, err
+	}
+	return &rp, nil
+}
+
+// createRemoteLinks returns the files of the remote registry which don't exist locally
+func createRemoteLinks(rp *remotePackage, pds []*packages_model.PackageDescriptor) []*remoteLink {
+	local := make(map[string]bool)
+	for _, pd := range pds {
+		for _, pf := range pd.Files {
+			local[pf.File.LowerName] = true
+		}
+	}
+
+	links := make([]*remoteLink, 0, len(rp.Releases))
+	for version, files := range rp.Releases {
+		if !versionMatcher.MatchString(version) {
+			continue
+		}
+		for _, rf := range files {
+			if rf.Yanked || rf.Digests.SHA256 == "" || local[strings.ToLower(rf.Filename)] {
+				continue
+			}
+			links = append(links, &remoteLink{
+				Version:        version,
+				Filename:       rf.Filename,
+				HashSHA256:     rf.Digests.SHA256,
+				RequiresPython: rf.RequiresPython,
+			})
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Filename < links[j].Filename
+	})
+	return links
+}
+
+// cacheRemotePackageFile fetches the package file from the remote registry and stores it in a package version of the owner.
+// packages_model.ErrPackageNotExist is returned if the owner has no remote registry or it doesn't have the package file.
+func cacheRemotePackageFile(ctx *context.Context, packageName, packageVersion, filename string) error {
+	c, err := remote_service.GetClient(ctx, ctx.Package.Owner, packages_model.TypePyPI)
+	if err != nil {
+		return err
+	}
+	if c == nil || !isValidNameAndVersion(packageName, packageVersion) {
+		return packages_model.ErrPackageNotExist
+	}
+
+	rp, err := getRemotePackage(ctx, c, packageName)
+	if err != nil {
+		if err == remote_service.ErrNotFound {
+			return packages_model.ErrPackageNotExist
+		}
+		return err
+	}
+
+	var file *remoteFile
+	for _, rf := range rp.Releases[packageVersion] {
+		if strings.EqualFold(rf.Filename, filename) {
+			file = rf
+			break
+		}
+	}
+	if file == nil || file.Digests.SHA256 == "" {
+		return packages_model.ErrPackageNotExist
+	}
+
+	return c.LockAndDo(ctx, packageName+"/"+packageVersion+"/"+file.Filename, func(lockCtx std_ctx.Context) error {
+		pv, err := packages_model.GetVersionByNameAndVersion(lockCtx, ctx.Package.Owner.ID, packages_model.TypePyPI, packageName, packageVersion)
+		if err == nil {
+			_, err = packages_model.GetFileForVersionByName(lockCtx, pv.ID, file.Filename, packages_model.EmptyFileKey)
+		}
+		if err == nil {
+			return nil
+		} else if err != packages_model.ErrPackageNotExist && err != packages_model.ErrPackageFileNotExist {
+			return err
+		}
+
+		buf, err := c.Download(lockCtx, file.URL, nil)
+		if err != nil {
+			if err == remote_service.ErrNotFound {
+				return packages_model.ErrPackageNotExist
+			}
+			return err
+		}
+		defer buf.Close()
+
+		_, _, hashSHA256, _ := buf.Sums()
+		if !strings.EqualFold(file.Digests.SHA256, hex.EncodeToString(hashSHA256)) {
+			return errInvalidDigest
+		}
+
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		projectURL := rp.Info.HomePage
+		if !validation.IsValidURL(projectURL) {
+			projectURL = ""
+		}
+
+		creator := remote_service.Creator(ctx.Doer)
+
+		_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+			lockCtx,
+			&packages_service.PackageCreationInfo{
+				PackageInfo: packages_service.PackageInfo{
+					Owner:       ctx.Package.Owner,
+					PackageType: packages_model.TypePyPI,
+					Name:        packageName,
+					Version:     packageVersion,
+				},
+				SemverCompatible: false,
+				Creator:          creator,
+				Metadata: &pypi_module.Metadata{
+					Author:          rp.Info.Author,
+					LongDescription: rp.Info.Description,
+					Summary:         rp.Info.Summary,
+					ProjectURL:      projectURL,
+					License:         rp.Info.License,
+					RequiresPython:  file.RequiresPython,
+				},
+			},
+			&packages_service.PackageFileCreationInfo{
+				PackageFileInfo: packages_service.PackageFileInfo{
+					Filename: file.Filename,
+				},
+				Creator: creator,
+				Data:    buf,
+				IsLead:  true,
+			},
+		)
+		if err == packages_model.ErrDuplicatePackageFile {
+			return nil
+		}
+		return err
+	})
+}
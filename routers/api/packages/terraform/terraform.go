@@ -0,0 +1,706 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	std_ctx "context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	"code.gitea.io/gitea/services/context"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/keybase/go-crypto/openpgp"
+)
+
+var (
+	errChecksumMismatch    = util.NewInvalidArgumentErrorf("checksum of the archive does not match the checksums file")
+	errMissingChecksums    = util.NewInvalidArgumentErrorf("the checksums file must be uploaded before its signature")
+	errMissingSigningKeys  = util.NewInvalidArgumentErrorf("the uploader has no GPG keys to verify the signature")
+	errIncompleteProvider  = errors.New("provider release is not signed yet")
+	errPlatformUnavailable = errors.New("provider is not available for this platform")
+)
+
+func apiError(ctx *context.Context, status int, obj any) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, struct {
+			Errors []string `json:"errors"`
+		}{
+			Errors: []string{
+				message,
+			},
+		})
+	})
+}
+
+func baseURL(ctx *context.Context) string {
+	return fmt.Sprintf("%sapi/packages/%s/terraform", setting.AppURL, url.PathEscape(ctx.Package.Owner.Name))
+}
+
+func moduleParams(ctx *context.Context) (name, system string) {
+	return strings.ToLower(ctx.PathParam("name")), strings.ToLower(ctx.PathParam("system"))
+}
+
+type moduleVersion struct {
+	Version string `json:"version"`
+}
+
+type moduleVersions struct {
+	Versions []*moduleVersion `json:"versions"`
+}
+
+// ListModuleVersions lists the available versions of a module
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#list-available-versions-for-a-specific-module
+func ListModuleVersions(ctx *context.Context) {
+	name, system := moduleParams(ctx)
+
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, terraform_module.ModulePackageName(name, system))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	versions := make([]*moduleVersion, 0, len(pvs))
+	for _, pv := range pvs {
+		versions = append(versions, &moduleVersion{Version: pv.Version})
+	}
+
+	ctx.JSON(http.StatusOK, struct {
+		Modules []*moduleVersions `json:"modules"`
+	}{
+		Modules: []*moduleVersions{
+			{Versions: versions},
+		},
+	})
+}
+
+// DownloadModule redirects the client to the archive of a module version
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#download-source-code-for-a-specific-module-version
+func DownloadModule(ctx *context.Context) {
+	name, system := moduleParams(ctx)
+	moduleVersion := ctx.PathParam("version")
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, terraform_module.ModulePackageName(name, system), moduleVersion)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Resp.Header().Set("X-Terraform-Get", fmt.Sprintf("%s/modules/%s/%s/%s/%s", baseURL(ctx), name, system, url.PathEscape(pv.Version), terraform_module.ModuleFilename(name, system, pv.Version)))
+	ctx.Status(http.StatusNoContent)
+}
+
+// UploadModule creates a new module version from a gzipped tar archive
+func UploadModule(ctx *context.Context) {
+	name, system := moduleParams(ctx)
+	if !terraform_module.IsValidModuleName(name, system) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidName)
+		return
+	}
+	moduleVersion := ctx.PathParam("version")
+	if !terraform_module.IsValidVersion(moduleVersion) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidVersion)
+		return
+	}
+
+	upload, needsClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needsClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	metadata, err := terraform_module.ParseModuleArchive(buf)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		ctx,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeTerraform,
+				Name:        terraform_module.ModulePackageName(name, system),
+				Version:     moduleVersion,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: terraform_module.ModuleFilename(name, system, moduleVersion),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadModuleFile serves the archive of a module version
+func DownloadModuleFile(ctx *context.Context) {
+	name, system := moduleParams(ctx)
+
+	downloadFile(ctx, terraform_module.ModulePackageName(name, system), ctx.PathParam("version"), ctx.PathParam("filename"))
+}
+
+// DeleteModule deletes a module version
+func DeleteModule(ctx *context.Context) {
+	name, system := moduleParams(ctx)
+
+	deleteVersion(ctx, terraform_module.ModulePackageName(name, system), ctx.PathParam("version"))
+}
+
+type providerPlatform struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+}
+
+type providerVersion struct {
+	Version   string              `json:"version"`
+	Protocols []string            `json:"protocols"`
+	Platforms []*providerPlatform `json:"platforms"`
+}
+
+func protocols(pd *packages_model.PackageDescriptor) []string {
+	if p := pd.Metadata.(*terraform_module.Metadata).Protocols; len(p) > 0 {
+		return p
+	}
+	return []string{terraform_module.DefaultProtocol}
+}
+
+// hasSignedChecksums checks if the provider release is complete and can be installed
+func hasSignedChecksums(pd *packages_model.PackageDescriptor) bool {
+	prefix := terraform_module.ProviderFilePrefix(pd.Package.Name, pd.Version.Version)
+	hasChecksums, hasSignature := false, false
+	for _, pfd := range pd.Files {
+		switch pfd.File.Name {
+		case prefix + "SHA256SUMS":
+			hasChecksums = true
+		case prefix + "SHA256SUMS.sig":
+			hasSignature = true
+		}
+	}
+	return hasChecksums && hasSignature
+}
+
+// ListProviderVersions lists the installable versions of a provider
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#list-available-versions
+func ListProviderVersions(ctx *context.Context) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, strings.ToLower(ctx.PathParam("provider")))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	sort.Slice(pds, func(i, j int) bool {
+		return pds[i].SemVer.LessThan(pds[j].SemVer)
+	})
+
+	versions := make([]*providerVersion, 0, len(pds))
+	for _, pd := range pds {
+		if pd.Metadata.(*terraform_module.Metadata).Kind != terraform_module.KindProvider || !hasSignedChecksums(pd) {
+			continue
+		}
+
+		platforms := make([]*providerPlatform, 0, len(pd.Files))
+		for _, pfd := range pd.Files {
+			if os := pfd.Properties.GetByName(terraform_module.PropertyOS); os != "" {
+				platforms = append(platforms, &providerPlatform{
+					OS:   os,
+					Arch: pfd.Properties.GetByName(terraform_module.PropertyArch),
+				})
+			}
+		}
+		if len(platforms) == 0 {
+			continue
+		}
+
+		versions = append(versions, &providerVersion{
+			Version:   pd.Version.Version,
+			Protocols: protocols(pd),
+			Platforms: platforms,
+		})
+	}
+
+	if len(versions) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, struct {
+		Versions []*providerVersion `json:"versions"`
+	}{
+		Versions: versions,
+	})
+}
+
+type gpgPublicKey struct {
+	KeyID      string `json:"key_id"`
+	ASCIIArmor string `json:"ascii_armor"`
+}
+
+type providerPackage struct {
+	Protocols           []string `json:"protocols"`
+	OS                  string   `json:"os"`
+	Arch                string   `json:"arch"`
+	Filename            string   `json:"filename"`
+	DownloadURL         string   `json:"download_url"`
+	SHASumsURL          string   `json:"shasums_url"`
+	SHASumsSignatureURL string   `json:"shasums_signature_url"`
+	SHASum              string   `json:"shasum"`
+	SigningKeys         struct {
+		GPGPublicKeys []*gpgPublicKey `json:"gpg_public_keys"`
+	} `json:"signing_keys"`
+}
+
+// GetProviderPackage describes the archive of a provider version for the platform
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#find-a-provider-package
+func GetProviderPackage(ctx *context.Context) {
+	providerType := strings.ToLower(ctx.PathParam("provider"))
+
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, providerType, ctx.PathParam("version"))
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if pd.Metadata.(*terraform_module.Metadata).Kind != terraform_module.KindProvider {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+	if !hasSignedChecksums(pd) {
+		apiError(ctx, http.StatusNotFound, errIncompleteProvider)
+		return
+	}
+
+	os, arch := ctx.PathParam("os"), ctx.PathParam("arch")
+	prefix := terraform_module.ProviderFilePrefix(pd.Package.Name, pd.Version.Version)
+
+	var archive, signature *packages_model.PackageFileDescriptor
+	for _, pfd := range pd.Files {
+		if pfd.Properties.GetByName(terraform_module.PropertyOS) == os && pfd.Properties.GetByName(terraform_module.PropertyArch) == arch {
+			archive = pfd
+		}
+		if pfd.File.Name == prefix+"SHA256SUMS.sig" {
+			signature = pfd
+		}
+	}
+	if archive == nil {
+		apiError(ctx, http.StatusNotFound, errPlatformUnavailable)
+		return
+	}
+
+	key, err := asymkey_model.GetGPGImportByKeyID(ctx, signature.Properties.GetByName(terraform_module.PropertySigningKey))
+	if err != nil {
+		if asymkey_model.IsErrGPGKeyImportNotExist(err) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	versionURL := fmt.Sprintf("%s/providers/%s/%s/", baseURL(ctx), pd.Package.Name, url.PathEscape(pd.Version.Version))
+
+	p := &providerPackage{
+		Protocols:           protocols(pd),
+		OS:                  os,
+		Arch:                arch,
+		Filename:            prefix + os + "_" + arch + ".zip",
+		DownloadURL:         versionURL + url.PathEscape(archive.File.Name),
+		SHASumsURL:          versionURL + url.PathEscape(prefix+"SHA256SUMS"),
+		SHASumsSignatureURL: versionURL + url.PathEscape(prefix+"SHA256SUMS.sig"),
+		SHASum:              archive.Blob.HashSHA256,
+	}
+	p.SigningKeys.GPGPublicKeys = []*gpgPublicKey{
+		{
+			KeyID:      key.KeyID,
+			ASCIIArmor: key.Content,
+		},
+	}
+
+	ctx.JSON(http.StatusOK, p)
+}
+
+// UploadProviderFile adds a file of a provider release. The archives and the checksums file
+// are validated against each other and the signature must be made by a GPG key of the uploader.
+func UploadProviderFile(ctx *context.Context) {
+	providerType := ctx.PathParam("provider")
+	if !terraform_module.IsValidProviderType(providerType) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidName)
+		return
+	}
+	providerVersion := ctx.PathParam("version")
+	if !terraform_module.IsValidVersion(providerVersion) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidVersion)
+		return
+	}
+	filename := ctx.PathParam("filename")
+	providerFile, err := terraform_module.ParseProviderFilename(providerType, providerVersion, filename)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	upload, needsClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needsClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pvi := packages_service.PackageInfo{
+		Owner:       ctx.Package.Owner,
+		PackageType: packages_model.TypeTerraform,
+		Name:        providerType,
+		Version:     providerVersion,
+	}
+
+	pd, err := getProviderDescriptor(ctx, &pvi)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	metadata := &terraform_module.Metadata{
+		Kind: terraform_module.KindProvider,
+	}
+	if pd != nil {
+		metadata = pd.Metadata.(*terraform_module.Metadata)
+		if metadata.Kind != terraform_module.KindProvider {
+			apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidName)
+			return
+		}
+	}
+
+	properties := map[string]string{}
+
+	switch providerFile.Type {
+	case terraform_module.ProviderFileArchive:
+		properties[terraform_module.PropertyOS] = providerFile.OS
+		properties[terraform_module.PropertyArch] = providerFile.Arch
+
+		checksums, err := readChecksums(pd)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if checksums != nil {
+			_, _, hashSHA256, _ := buf.Sums()
+			if checksums[filename] != hex.EncodeToString(hashSHA256) {
+				apiError(ctx, http.StatusBadRequest, errChecksumMismatch)
+				return
+			}
+		}
+	case terraform_module.ProviderFileChecksums:
+		checksums, err := terraform_module.ParseChecksums(buf)
+		if err != nil {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if pd != nil {
+			for _, pfd := range pd.Files {
+				if pfd.Properties.GetByName(terraform_module.PropertyOS) == "" {
+					continue
+				}
+				if checksums[terraform_module.ProviderFilePrefix(providerType, providerVersion)+pfd.Properties.GetByName(terraform_module.PropertyOS)+"_"+pfd.Properties.GetByName(terraform_module.PropertyArch)+".zip"] != pfd.Blob.HashSHA256 {
+					apiError(ctx, http.StatusBadRequest, errChecksumMismatch)
+					return
+				}
+			}
+		}
+	case terraform_module.ProviderFileSignature:
+		signer, err := verifySignature(ctx, pd, buf)
+		if err != nil {
+			if errors.Is(err, util.ErrInvalidArgument) {
+				apiError(ctx, http.StatusBadRequest, err)
+			} else {
+				apiError(ctx, http.StatusInternalServerError, err)
+			}
+			return
+		}
+		properties[terraform_module.PropertySigningKey] = signer.PrimaryKey.KeyIdString()
+	case terraform_module.ProviderFileManifest:
+		p, err := terraform_module.ParseProviderManifest(buf)
+		if err != nil {
+			apiError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		metadata.Protocols = p
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		ctx,
+		&packages_service.PackageCreationInfo{
+			PackageInfo:      pvi,
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: filename,
+			},
+			Creator:    ctx.Doer,
+			Data:       buf,
+			IsLead:     providerFile.Type == terraform_module.ProviderFileArchive,
+			Properties: properties,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageFile:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	// the metadata of an existing version is only changed once the manifest has been added
+	if pd != nil && providerFile.Type == terraform_module.ProviderFileManifest {
+		if err := updateVersionMetadata(ctx, pd.Version, metadata); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// getProviderDescriptor gets the descriptor of the provider version or nil if it doesn't exist yet
+func getProviderDescriptor(ctx std_ctx.Context, pvi *packages_service.PackageInfo) (*packages_model.PackageDescriptor, error) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, pvi.Owner.ID, pvi.PackageType, pvi.Name, pvi.Version)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return packages_model.GetPackageDescriptor(ctx, pv)
+}
+
+func getChecksumsFile(pd *packages_model.PackageDescriptor) *packages_model.PackageFileDescriptor {
+	if pd == nil {
+		return nil
+	}
+	name := terraform_module.ProviderFilePrefix(pd.Package.Name, pd.Version.Version) + "SHA256SUMS"
+	for _, pfd := range pd.Files {
+		if pfd.File.Name == name {
+			return pfd
+		}
+	}
+	return nil
+}
+
+// readChecksums reads the checksums file of the provider version or returns nil if it doesn't exist yet
+func readChecksums(pd *packages_model.PackageDescriptor) (map[string]string, error) {
+	pfd := getChecksumsFile(pd)
+	if pfd == nil {
+		return nil, nil
+	}
+
+	s, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(pfd.Blob.HashSHA256))
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	return terraform_module.ParseChecksums(s)
+}
+
+// verifySignature verifies the signature of the checksums file with the GPG keys of the uploader
+func verifySignature(ctx *context.Context, pd *packages_model.PackageDescriptor, signature io.Reader) (*openpgp.Entity, error) {
+	pfd := getChecksumsFile(pd)
+	if pfd == nil {
+		return nil, errMissingChecksums
+	}
+
+	keys, err := db.Find[asymkey_model.GPGKey](ctx, asymkey_model.FindGPGKeyOptions{OwnerID: ctx.Doer.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	keyring := make(openpgp.EntityList, 0, len(keys))
+	for _, key := range keys {
+		e, err := asymkey_model.GPGKeyToEntity(ctx, key)
+		if err != nil {
+			if asymkey_model.IsErrGPGKeyImportNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		keyring = append(keyring, e)
+	}
+	if len(keyring) == 0 {
+		return nil, errMissingSigningKeys
+	}
+
+	sig, err := io.ReadAll(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := packages_module.NewContentStore().Get(packages_module.BlobHash256Key(pfd.Blob.HashSHA256))
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	return terraform_module.VerifyChecksumsSignature(keyring, s, sig)
+}
+
+// updateVersionMetadata replaces the metadata of the existing provider version
+func updateVersionMetadata(ctx std_ctx.Context, pv *packages_model.PackageVersion, metadata *terraform_module.Metadata) error {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	pv.MetadataJSON = string(raw)
+	return packages_model.UpdateVersion(ctx, pv)
+}
+
+// DownloadProviderFile serves a file of a provider release
+func DownloadProviderFile(ctx *context.Context) {
+	downloadFile(ctx, strings.ToLower(ctx.PathParam("provider")), ctx.PathParam("version"), ctx.PathParam("filename"))
+}
+
+// DeleteProvider deletes a provider version
+func DeleteProvider(ctx *context.Context) {
+	deleteVersion(ctx, strings.ToLower(ctx.PathParam("provider")), ctx.PathParam("version"))
+}
+
+func downloadFile(ctx *context.Context, packageName, packageVersion, filename string) {
+	s, u, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        packageName,
+			Version:     packageVersion,
+		},
+		&packages_service.PackageFileInfo{
+			Filename: filename,
+		},
+	)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) || errors.Is(err, packages_model.ErrPackageFileNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	helper.ServePackageFile(ctx, s, u, pf)
+}
+
+func deleteVersion(ctx *context.Context, packageName, packageVersion string) {
+	err := packages_service.RemovePackageVersionByNameAndVersion(
+		ctx,
+		ctx.Doer,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        packageName,
+			Version:     packageVersion,
+		},
+	)
+	if err != nil {
+		if errors.Is(err, packages_model.ErrPackageNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,242 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package scim implements the SCIM 2.0 (RFC 7643 and RFC 7644) provisioning endpoints,
+// the users are bound to the authentication source configured by `[scim].AUTH_SOURCE`
+// and the groups are the teams of the organizations.
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/modules/web/middleware"
+	web_types "code.gitea.io/gitea/modules/web/types"
+	"code.gitea.io/gitea/services/context"
+)
+
+type scimContextKeyType struct{}
+
+var scimContextKey = scimContextKeyType{}
+
+// Context is the context of the SCIM requests
+type Context struct {
+	*context.Base
+
+	Doer   *user_model.User
+	Source *auth_model.Source
+}
+
+func init() {
+	web.RegisterResponseStatusProvider[*Context](func(req *http.Request) web_types.ResponseStatusProvider {
+		return req.Context().Value(scimContextKey).(*Context)
+	})
+}
+
+// Routes provides the SCIM endpoints, they are mounted on `/scim/v2`
+func Routes() *web.Router {
+	m := web.NewRouter()
+	m.Use(Contexter())
+
+	m.Get("/ServiceProviderConfig", serviceProviderConfig)
+	m.Get("/ResourceTypes", resourceTypes)
+	m.Group("/Users", func() {
+		m.Combo("").Get(listUsers).Post(createUser)
+		m.Combo("/{id}").Get(getUser).Put(replaceUser).Patch(patchUser).Delete(deleteUser)
+	})
+	m.Group("/Groups", func() {
+		m.Combo("").Get(listGroups).Post(createGroup)
+		m.Combo("/{id}").Get(getGroup).Put(replaceGroup).Patch(patchGroup).Delete(deleteGroup)
+	})
+
+	return m
+}
+
+// Contexter authenticates the identity provider by the personal access token of a site administrator
+// with the admin write scope and loads the authentication source the provisioned users are bound to.
+func Contexter() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			base, baseCleanUp := context.NewBaseContext(resp, req)
+			defer baseCleanUp()
+
+			ctx := &Context{Base: base}
+			ctx.AppendContextValue(scimContextKey, ctx)
+
+			fields := strings.Fields(req.Header.Get("Authorization"))
+			if len(fields) != 2 || (!strings.EqualFold(fields[0], "bearer") && fields[0] != "token") {
+				ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="Gitea SCIM"`)
+				ctx.scimError(http.StatusUnauthorized, "", "a bearer token is required")
+				return
+			}
+			token, err := auth_model.GetAccessTokenBySHA(ctx, fields[1])
+			if err != nil {
+				if auth_model.IsErrAccessTokenNotExist(err) || auth_model.IsErrAccessTokenEmpty(err) {
+					ctx.scimError(http.StatusUnauthorized, "", "invalid token")
+				} else {
+					ctx.serverError("GetAccessTokenBySHA", err)
+				}
+				return
+			}
+			hasScope, err := token.Scope.HasScope(auth_model.AccessTokenScopeWriteAdmin)
+			if err != nil {
+				ctx.serverError("HasScope", err)
+				return
+			}
+			if !hasScope {
+				ctx.scimError(http.StatusForbidden, "", "the token requires the write:admin scope")
+				return
+			}
+			doer, err := user_model.GetUserByID(ctx, token.UID)
+			if err != nil {
+				if user_model.IsErrUserNotExist(err) {
+					ctx.scimError(http.StatusUnauthorized, "", "invalid token")
+				} else {
+					ctx.serverError("GetUserByID", err)
+				}
+				return
+			}
+			if !doer.IsAdmin || !doer.IsActive || doer.ProhibitLogin {
+				ctx.scimError(http.StatusForbidden, "", "the token must belong to an active site administrator")
+				return
+			}
+			token.UpdatedUnix = timeutil.TimeStampNow()
+			if err := auth_model.UpdateAccessToken(ctx, token); err != nil {
+				log.Error("UpdateAccessToken: %v", err)
+			}
+			ctx.Doer = doer
+			ctx.Data[middleware.ContextDataKeySignedUser] = doer
+
+			ctx.Source, err = auth_model.GetActiveSourceByName(ctx, setting.SCIM.AuthSource)
+			if err != nil {
+				ctx.serverError("GetActiveSourceByName", err)
+				return
+			}
+
+			next.ServeHTTP(ctx.Resp, ctx.Req)
+		})
+	}
+}
+
+// scimJSON writes the SCIM resource with the SCIM media type
+func (ctx *Context) scimJSON(status int, obj any) {
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("Render SCIM JSON failed: %v", err)
+	}
+}
+
+// scimError writes the error response defined by RFC 7644 section 3.12
+func (ctx *Context) scimError(status int, scimType, detail string) {
+	ctx.scimJSON(status, &errorResponse{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func (ctx *Context) serverError(name string, err error) {
+	log.Error("SCIM %s: %v", name, err)
+	ctx.scimError(http.StatusInternalServerError, "", "internal server error")
+}
+
+// decodeBody decodes the request body, it writes the error response and returns false if the body is invalid
+func (ctx *Context) decodeBody(v any) bool {
+	if err := json.NewDecoder(ctx.Req.Body).Decode(v); err != nil {
+		ctx.scimError(http.StatusBadRequest, scimTypeInvalidSyntax, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// pathID returns the numeric resource id of the request path, it returns 0 if the id is invalid
+func (ctx *Context) pathID() int64 {
+	id, err := strconv.ParseInt(ctx.PathParam("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// listRange returns the page size and the page of the startIndex and count parameters,
+// the start index is rounded down to the first resource of the page
+func (ctx *Context) listRange() (pageSize, page int) {
+	pageSize = setting.API.DefaultPagingNum
+	if count, err := strconv.Atoi(ctx.FormString("count")); err == nil && count >= 0 {
+		pageSize = count
+	}
+	pageSize = min(pageSize, setting.API.MaxResponseItems)
+	startIndex, err := strconv.Atoi(ctx.FormString("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	if pageSize == 0 {
+		return 0, 1
+	}
+	return pageSize, (startIndex-1)/pageSize + 1
+}
+
+func resourceLocation(resourceType string, id int64) string {
+	return setting.AppURL + "scim/v2/" + resourceType + "/" + strconv.FormatInt(id, 10)
+}
+
+func serviceProviderConfig(ctx *Context) {
+	ctx.scimJSON(http.StatusOK, map[string]any{
+		"schemas":          []string{schemaServiceProviderConfig},
+		"documentationUri": "https://tools.ietf.org/html/rfc7644",
+		"patch":            map[string]any{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": setting.API.MaxResponseItems},
+		"changePassword":   map[string]any{"supported": false},
+		"sort":             map[string]any{"supported": false},
+		"etag":             map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Personal Access Token",
+			"description": "A personal access token of a site administrator with the write:admin scope",
+			"primary":     true,
+		}},
+		"meta": map[string]any{
+			"resourceType": "ServiceProviderConfig",
+			"location":     setting.AppURL + "scim/v2/ServiceProviderConfig",
+		},
+	})
+}
+
+func resourceTypes(ctx *Context) {
+	types := []any{
+		map[string]any{
+			"schemas":  []string{schemaResourceType},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   schemaUser,
+			"meta":     map[string]any{"resourceType": "ResourceType", "location": setting.AppURL + "scim/v2/ResourceTypes/User"},
+		},
+		map[string]any{
+			"schemas":  []string{schemaResourceType},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   schemaGroup,
+			"meta":     map[string]any{"resourceType": "ResourceType", "location": setting.AppURL + "scim/v2/ResourceTypes/Group"},
+		},
+	}
+	ctx.scimJSON(http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: int64(len(types)),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+}
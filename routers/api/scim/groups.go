@@ -0,0 +1,383 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	org_service "code.gitea.io/gitea/services/org"
+)
+
+// The groups are the teams of the organizations, the display name of a group is `org/team`.
+
+// groupDisplayName returns the display name of the team, the organizations are cached in orgs
+func (ctx *Context) groupDisplayName(team *organization.Team, orgs map[int64]*organization.Organization) (string, error) {
+	org, ok := orgs[team.OrgID]
+	if !ok {
+		var err error
+		if org, err = organization.GetOrgByID(ctx, team.OrgID); err != nil {
+			return "", err
+		}
+		orgs[team.OrgID] = org
+	}
+	return org.Name + "/" + team.Name, nil
+}
+
+// parseGroupDisplayName splits the display name of a group into the organization and the team name
+func parseGroupDisplayName(displayName string) (orgName, teamName string, err error) {
+	orgName, teamName, ok := strings.Cut(displayName, "/")
+	if !ok || orgName == "" || teamName == "" || strings.Contains(teamName, "/") {
+		return "", "", newRequestError(scimTypeInvalidValue, "the displayName of a group must be in the form of organization/team, got %q", displayName)
+	}
+	return orgName, teamName, nil
+}
+
+// groupTeam returns the team of the request path, it writes the error response and returns nil if not found
+func (ctx *Context) groupTeam() *organization.Team {
+	id := ctx.pathID()
+	if id == 0 {
+		ctx.scimError(http.StatusNotFound, "", "group not found")
+		return nil
+	}
+	team, err := organization.GetTeamByID(ctx, id)
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			ctx.scimError(http.StatusNotFound, "", "group not found")
+		} else {
+			ctx.serverError("GetTeamByID", err)
+		}
+		return nil
+	}
+	return team
+}
+
+func (ctx *Context) toGroupResource(team *organization.Team, orgs map[int64]*organization.Organization, withMembers bool) (*groupResource, error) {
+	displayName, err := ctx.groupDisplayName(team, orgs)
+	if err != nil {
+		return nil, err
+	}
+	res := &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.FormatInt(team.ID, 10),
+		DisplayName: displayName,
+		Members:     []reference{},
+		Meta: &meta{
+			ResourceType: "Group",
+			Location:     resourceLocation("Groups", team.ID),
+		},
+	}
+	if !withMembers {
+		return res, nil
+	}
+	members, err := organization.GetTeamMembers(ctx, &organization.SearchMembersOptions{TeamID: team.ID})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range members {
+		res.Members = append(res.Members, reference{
+			Value:   strconv.FormatInt(u.ID, 10),
+			Ref:     resourceLocation("Users", u.ID),
+			Display: u.Name,
+		})
+	}
+	return res, nil
+}
+
+// excludesMembers returns whether the members are excluded from the response by the request,
+// the identity providers exclude them to list the groups of a big organization quickly
+func (ctx *Context) excludesMembers() bool {
+	for _, attribute := range strings.Split(ctx.FormString("excludedAttributes"), ",") {
+		if trimSchema(strings.TrimSpace(attribute)) == "members" {
+			return true
+		}
+	}
+	return false
+}
+
+func listGroups(ctx *Context) {
+	pageSize, page := ctx.listRange()
+
+	var teams []*organization.Team
+	var total int64
+	if filter := ctx.FormString("filter"); filter != "" {
+		attribute, value, err := parseFilter(filter)
+		if err != nil {
+			ctx.handleError("parseFilter", err)
+			return
+		}
+		if attribute != "displayname" {
+			ctx.scimError(http.StatusBadRequest, scimTypeInvalidFilter, "unsupported filter attribute "+attribute)
+			return
+		}
+		if orgName, teamName, err := parseGroupDisplayName(value); err == nil {
+			team, err := getTeamByNames(ctx, orgName, teamName)
+			if err != nil {
+				ctx.serverError("getTeamByNames", err)
+				return
+			}
+			if team != nil {
+				teams, total = []*organization.Team{team}, 1
+			}
+		}
+	} else {
+		var err error
+		teams, total, err = organization.SearchTeam(ctx, &organization.SearchTeamOptions{
+			ListOptions: db.ListOptions{Page: page, PageSize: max(pageSize, 1)},
+		})
+		if err != nil {
+			ctx.serverError("SearchTeam", err)
+			return
+		}
+	}
+
+	resp := &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   (page-1)*pageSize + 1,
+		Resources:    []any{},
+	}
+	if pageSize > 0 {
+		orgs := make(map[int64]*organization.Organization)
+		withMembers := !ctx.excludesMembers()
+		for _, team := range teams[:min(len(teams), pageSize)] {
+			res, err := ctx.toGroupResource(team, orgs, withMembers)
+			if err != nil {
+				ctx.serverError("toGroupResource", err)
+				return
+			}
+			resp.Resources = append(resp.Resources, res)
+		}
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+	ctx.scimJSON(http.StatusOK, resp)
+}
+
+// getTeamByNames returns the team, or nil if the organization or the team doesn't exist
+func getTeamByNames(ctx *Context, orgName, teamName string) (*organization.Team, error) {
+	org, err := organization.GetOrgByName(ctx, orgName)
+	if err != nil {
+		if organization.IsErrOrgNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	team, err := org.GetTeam(ctx, teamName)
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return team, nil
+}
+
+func getGroup(ctx *Context) {
+	team := ctx.groupTeam()
+	if team == nil {
+		return
+	}
+	ctx.writeGroup(http.StatusOK, team)
+}
+
+func (ctx *Context) writeGroup(status int, team *organization.Team) {
+	res, err := ctx.toGroupResource(team, make(map[int64]*organization.Organization), !ctx.excludesMembers())
+	if err != nil {
+		ctx.serverError("toGroupResource", err)
+		return
+	}
+	if status == http.StatusCreated {
+		ctx.Resp.Header().Set("Location", res.Meta.Location)
+	}
+	ctx.scimJSON(status, res)
+}
+
+// createGroup creates a team in an existing organization, the new team has read access to the units
+// of the repositories added to it by the organization owners
+func createGroup(ctx *Context) {
+	res := &groupResource{}
+	if !ctx.decodeBody(res) {
+		return
+	}
+	orgName, teamName, err := parseGroupDisplayName(res.DisplayName)
+	if err != nil {
+		ctx.handleError("parseGroupDisplayName", err)
+		return
+	}
+	org, err := organization.GetOrgByName(ctx, orgName)
+	if err != nil {
+		if organization.IsErrOrgNotExist(err) {
+			ctx.scimError(http.StatusBadRequest, scimTypeInvalidValue, "organization "+orgName+" does not exist")
+		} else {
+			ctx.serverError("GetOrgByName", err)
+		}
+		return
+	}
+
+	team := &organization.Team{
+		OrgID:      org.ID,
+		Name:       teamName,
+		AccessMode: perm.AccessModeRead,
+		Units:      make([]*organization.TeamUnit, 0, len(unit_model.AllRepoUnitTypes)),
+	}
+	for _, tp := range unit_model.AllRepoUnitTypes {
+		team.Units = append(team.Units, &organization.TeamUnit{
+			OrgID:      org.ID,
+			Type:       tp,
+			AccessMode: perm.AccessModeRead,
+		})
+	}
+	if err := org_service.NewTeam(ctx, team); err != nil {
+		ctx.handleError("NewTeam", err)
+		return
+	}
+	log.Trace("SCIM: team %s of %s created by %s", team.Name, org.Name, ctx.Doer.Name)
+
+	if err := ctx.syncTeamMembers(team, res.Members); err != nil {
+		ctx.handleError("syncTeamMembers", err)
+		return
+	}
+	ctx.writeGroup(http.StatusCreated, team)
+}
+
+// updateGroup renames the team and changes its members to match the resource
+func (ctx *Context) updateGroup(team *organization.Team, res *groupResource) error {
+	orgName, teamName, err := parseGroupDisplayName(res.DisplayName)
+	if err != nil {
+		return err
+	}
+	org, err := organization.GetOrgByID(ctx, team.OrgID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(orgName, org.Name) {
+		return newRequestError(scimTypeMutability, "a group can not be moved to another organization")
+	}
+	if teamName != team.Name {
+		if team.IsOwnerTeam() {
+			return newRequestError(scimTypeMutability, "the owners team can not be renamed")
+		}
+		team.Name = teamName
+		if err := org_service.UpdateTeam(ctx, team, false, false); err != nil {
+			return err
+		}
+	}
+	return ctx.syncTeamMembers(team, res.Members)
+}
+
+// syncTeamMembers adds the members to the team and removes the other provisioned users from it,
+// the users not bound to the authentication source are never removed
+func (ctx *Context) syncTeamMembers(team *organization.Team, members []reference) error {
+	current, err := organization.GetTeamMembers(ctx, &organization.SearchMembersOptions{TeamID: team.ID})
+	if err != nil {
+		return err
+	}
+	currentIDs := make(container.Set[int64], len(current))
+	for _, u := range current {
+		currentIDs.Add(u.ID)
+	}
+
+	wantedIDs := make(container.Set[int64], len(members))
+	var added []*user_model.User
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return newRequestError(scimTypeInvalidValue, "invalid member %q", m.Value)
+		}
+		if !wantedIDs.Add(id) || currentIDs.Contains(id) {
+			continue
+		}
+		u, err := user_model.GetUserByID(ctx, id)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				return newRequestError(scimTypeInvalidValue, "member %d does not exist", id)
+			}
+			return err
+		}
+		if !ctx.isProvisioned(u) {
+			return newRequestError(scimTypeInvalidValue, "member %d is not a provisioned user", id)
+		}
+		added = append(added, u)
+	}
+
+	for _, u := range current {
+		if !wantedIDs.Contains(u.ID) && ctx.isProvisioned(u) {
+			if err := org_service.RemoveTeamMember(ctx, team, u); err != nil {
+				return err
+			}
+		}
+	}
+	for _, u := range added {
+		if err := org_service.AddTeamMember(ctx, team, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaceGroup(ctx *Context) {
+	team := ctx.groupTeam()
+	if team == nil {
+		return
+	}
+	res := &groupResource{}
+	if !ctx.decodeBody(res) {
+		return
+	}
+	if err := ctx.updateGroup(team, res); err != nil {
+		ctx.handleError("updateGroup", err)
+		return
+	}
+	ctx.writeGroup(http.StatusOK, team)
+}
+
+func patchGroup(ctx *Context) {
+	team := ctx.groupTeam()
+	if team == nil {
+		return
+	}
+	req := &patchRequest{}
+	if !ctx.decodeBody(req) {
+		return
+	}
+	res, err := ctx.toGroupResource(team, make(map[int64]*organization.Organization), true)
+	if err != nil {
+		ctx.serverError("toGroupResource", err)
+		return
+	}
+	if err := applyGroupPatch(res, req.Operations); err != nil {
+		ctx.handleError("applyGroupPatch", err)
+		return
+	}
+	if err := ctx.updateGroup(team, res); err != nil {
+		ctx.handleError("updateGroup", err)
+		return
+	}
+	ctx.writeGroup(http.StatusOK, team)
+}
+
+func deleteGroup(ctx *Context) {
+	team := ctx.groupTeam()
+	if team == nil {
+		return
+	}
+	if team.IsOwnerTeam() {
+		ctx.scimError(http.StatusBadRequest, scimTypeMutability, "the owners team can not be deleted")
+		return
+	}
+	if err := org_service.DeleteTeam(ctx, team); err != nil {
+		ctx.serverError("DeleteTeam", err)
+		return
+	}
+	log.Trace("SCIM: team %s deleted by %s", team.Name, ctx.Doer.Name)
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,369 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// requestError is an error of the request which is reported to the identity provider with status 400
+type requestError struct {
+	ScimType string
+	Detail   string
+}
+
+func (err *requestError) Error() string {
+	return err.Detail
+}
+
+func newRequestError(scimType, format string, args ...any) *requestError {
+	return &requestError{ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// filterPattern matches the only filter supported by the endpoints: `attribute eq "value"`
+var filterPattern = regexp.MustCompile(`(?i)^\s*([a-z][\w.:$-]*)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseFilter parses an equality filter, the attribute is returned in lower case without the schema prefix
+func parseFilter(filter string) (attribute, value string, err error) {
+	m := filterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", newRequestError(scimTypeInvalidFilter, "unsupported filter %q, only the eq operator is supported", filter)
+	}
+	if err := json.Unmarshal([]byte(m[2]), &value); err != nil {
+		return "", "", newRequestError(scimTypeInvalidFilter, "invalid value in filter %q", filter)
+	}
+	return trimSchema(m[1]), value, nil
+}
+
+// trimSchema removes the schema URN of a fully qualified attribute and lowers the case of the attribute
+func trimSchema(attribute string) string {
+	for _, schema := range []string{schemaUser, schemaGroup} {
+		if len(attribute) > len(schema) && strings.EqualFold(attribute[:len(schema)+1], schema+":") {
+			attribute = attribute[len(schema)+1:]
+			break
+		}
+	}
+	return strings.ToLower(attribute)
+}
+
+// attributePath is a parsed path of a patch operation, like `emails[type eq "work"].value`
+type attributePath struct {
+	Attribute      string
+	FilterAttr     string
+	FilterValue    string
+	SubAttribute   string
+	HasValueFilter bool
+}
+
+func parsePath(path string) (*attributePath, error) {
+	p := &attributePath{}
+	if i := strings.IndexByte(path, '['); i >= 0 {
+		j := strings.LastIndexByte(path, ']')
+		if j < i {
+			return nil, newRequestError(scimTypeInvalidPath, "invalid path %q", path)
+		}
+		var err error
+		p.FilterAttr, p.FilterValue, err = parseFilter(path[i+1 : j])
+		if err != nil {
+			return nil, newRequestError(scimTypeInvalidPath, "invalid filter in path %q", path)
+		}
+		p.HasValueFilter = true
+		p.Attribute = trimSchema(path[:i])
+		if rest := path[j+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") {
+				return nil, newRequestError(scimTypeInvalidPath, "invalid path %q", path)
+			}
+			p.SubAttribute = strings.ToLower(rest[1:])
+		}
+		return p, nil
+	}
+
+	p.Attribute = trimSchema(path)
+	if attribute, sub, ok := strings.Cut(p.Attribute, "."); ok {
+		p.Attribute, p.SubAttribute = attribute, sub
+	}
+	if p.Attribute == "" {
+		return nil, newRequestError(scimTypeInvalidPath, "invalid path %q", path)
+	}
+	return p, nil
+}
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// forEachTarget calls fn with the path and the value of each attribute changed by the operation,
+// an operation without a path changes all the attributes of its value
+func (op *patchOperation) forEachTarget(fn func(op string, path *attributePath, value json.RawMessage) error) error {
+	opName := strings.ToLower(op.Op)
+	switch opName {
+	case "add", "replace", "remove":
+	default:
+		return newRequestError(scimTypeInvalidSyntax, "unsupported operation %q", op.Op)
+	}
+
+	if op.Path != "" {
+		path, err := parsePath(op.Path)
+		if err != nil {
+			return err
+		}
+		return fn(opName, path, op.Value)
+	}
+
+	if opName == "remove" {
+		return newRequestError(scimTypeInvalidPath, "the path is required by the remove operation")
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &values); err != nil {
+		return newRequestError(scimTypeInvalidValue, "the value of an operation without path must be an object")
+	}
+	for key, value := range values {
+		path, err := parsePath(key)
+		if err != nil {
+			return err
+		}
+		if err := fn(opName, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeString(value json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return "", newRequestError(scimTypeInvalidValue, "invalid string value %s", value)
+	}
+	return s, nil
+}
+
+// decodeBool decodes a boolean value, some identity providers send it as a string like "False"
+func decodeBool(value json.RawMessage) (bool, error) {
+	var v any
+	if err := json.Unmarshal(value, &v); err == nil {
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(strings.ToLower(b)); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return false, newRequestError(scimTypeInvalidValue, "invalid boolean value %s", value)
+}
+
+// applyUserPatch applies the operations to the user resource, the changes of unsupported attributes are ignored
+func applyUserPatch(res *userResource, operations []patchOperation) error {
+	for i := range operations {
+		if err := operations[i].forEachTarget(func(op string, path *attributePath, value json.RawMessage) error {
+			return patchUserAttribute(res, op, path, value)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchUserAttribute(res *userResource, op string, path *attributePath, value json.RawMessage) (err error) {
+	remove := op == "remove"
+	switch path.Attribute {
+	case "username":
+		if remove {
+			return newRequestError(scimTypeMutability, "userName is required")
+		}
+		res.UserName, err = decodeString(value)
+	case "externalid":
+		if remove {
+			res.ExternalID = ""
+			return nil
+		}
+		res.ExternalID, err = decodeString(value)
+	case "displayname":
+		if remove {
+			res.DisplayName = ""
+			return nil
+		}
+		res.DisplayName, err = decodeString(value)
+	case "active":
+		if remove {
+			return newRequestError(scimTypeMutability, "active can not be removed")
+		}
+		var active bool
+		active, err = decodeBool(value)
+		res.Active = &active
+	case "name":
+		if res.Name == nil {
+			res.Name = &name{}
+		}
+		if path.SubAttribute == "" {
+			res.Name = &name{}
+			if !remove {
+				if err := json.Unmarshal(value, res.Name); err != nil {
+					return newRequestError(scimTypeInvalidValue, "invalid name value")
+				}
+			}
+			return nil
+		}
+		var s string
+		if !remove {
+			if s, err = decodeString(value); err != nil {
+				return err
+			}
+		}
+		switch path.SubAttribute {
+		case "formatted":
+			res.Name.Formatted = s
+		case "givenname":
+			res.Name.GivenName = s
+		case "familyname":
+			res.Name.FamilyName = s
+		}
+	case "emails":
+		return patchUserEmails(res, op, path, value)
+	}
+	return err
+}
+
+func patchUserEmails(res *userResource, op string, path *attributePath, value json.RawMessage) error {
+	if !path.HasValueFilter {
+		var emails []email
+		if op != "remove" {
+			if err := json.Unmarshal(value, &emails); err != nil {
+				return newRequestError(scimTypeInvalidValue, "invalid emails value")
+			}
+		}
+		if op == "add" {
+			res.Emails = append(res.Emails, emails...)
+		} else {
+			res.Emails = emails
+		}
+		return nil
+	}
+
+	matches := func(e *email) bool {
+		switch path.FilterAttr {
+		case "type":
+			return strings.EqualFold(e.Type, path.FilterValue)
+		case "value":
+			return strings.EqualFold(e.Value, path.FilterValue)
+		case "primary":
+			primary, _ := strconv.ParseBool(path.FilterValue)
+			return e.Primary == primary
+		}
+		return false
+	}
+
+	if op == "remove" {
+		emails := res.Emails[:0]
+		for _, e := range res.Emails {
+			if !matches(&e) {
+				emails = append(emails, e)
+			}
+		}
+		res.Emails = emails
+		return nil
+	}
+
+	if path.SubAttribute != "value" {
+		// only the address is stored, the type and the primary flag are ignored
+		return nil
+	}
+	address, err := decodeString(value)
+	if err != nil {
+		return err
+	}
+	for i := range res.Emails {
+		if matches(&res.Emails[i]) {
+			res.Emails[i].Value = address
+			return nil
+		}
+	}
+	e := email{Value: address}
+	if path.FilterAttr == "type" {
+		e.Type = path.FilterValue
+	}
+	res.Emails = append(res.Emails, e)
+	return nil
+}
+
+// applyGroupPatch applies the operations to the group resource
+func applyGroupPatch(res *groupResource, operations []patchOperation) error {
+	for i := range operations {
+		if err := operations[i].forEachTarget(func(op string, path *attributePath, value json.RawMessage) error {
+			return patchGroupAttribute(res, op, path, value)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchGroupAttribute(res *groupResource, op string, path *attributePath, value json.RawMessage) (err error) {
+	switch path.Attribute {
+	case "displayname":
+		if op == "remove" {
+			return newRequestError(scimTypeMutability, "displayName is required")
+		}
+		res.DisplayName, err = decodeString(value)
+	case "externalid":
+		if op == "remove" {
+			res.ExternalID = ""
+			return nil
+		}
+		res.ExternalID, err = decodeString(value)
+	case "id", "schemas", "meta":
+		// some identity providers repeat the read-only attributes in the value
+		return nil
+	case "members":
+		var members []reference
+		if len(value) > 0 && string(value) != "null" {
+			if err := json.Unmarshal(value, &members); err != nil {
+				return newRequestError(scimTypeInvalidValue, "invalid members value")
+			}
+		}
+		switch op {
+		case "add":
+			res.Members = append(res.Members, members...)
+		case "replace":
+			res.Members = members
+		case "remove":
+			if path.HasValueFilter {
+				if path.FilterAttr != "value" {
+					return newRequestError(scimTypeInvalidPath, "the members can only be filtered by value")
+				}
+				members = append(members, reference{Value: path.FilterValue})
+			} else if len(members) == 0 {
+				res.Members = nil
+				return nil
+			}
+			removed := make(map[string]bool, len(members))
+			for _, m := range members {
+				removed[m.Value] = true
+			}
+			kept := res.Members[:0]
+			for _, m := range res.Members {
+				if !removed[m.Value] {
+					kept = append(kept, m)
+				}
+			}
+			res.Members = kept
+		}
+	default:
+		return newRequestError(scimTypeInvalidPath, "unsupported attribute %q", path.Attribute)
+	}
+	return err
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	attribute, value, err := parseFilter(`userName eq "alice"`)
+	require.NoError(t, err)
+	assert.Equal(t, "username", attribute)
+	assert.Equal(t, "alice", value)
+
+	attribute, value, err = parseFilter(`urn:ietf:params:scim:schemas:core:2.0:Group:displayName EQ "org/a \"b\""`)
+	require.NoError(t, err)
+	assert.Equal(t, "displayname", attribute)
+	assert.Equal(t, `org/a "b"`, value)
+
+	for _, filter := range []string{`userName sw "a"`, `userName eq alice`, `userName eq "a" and active eq "true"`} {
+		_, _, err = parseFilter(filter)
+		assert.Error(t, err, filter)
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	path, err := parsePath(`emails[type eq "work"].value`)
+	require.NoError(t, err)
+	assert.Equal(t, &attributePath{Attribute: "emails", FilterAttr: "type", FilterValue: "work", SubAttribute: "value", HasValueFilter: true}, path)
+
+	path, err = parsePath("name.givenName")
+	require.NoError(t, err)
+	assert.Equal(t, &attributePath{Attribute: "name", SubAttribute: "givenname"}, path)
+
+	path, err = parsePath(`members[value eq "2"]`)
+	require.NoError(t, err)
+	assert.Equal(t, &attributePath{Attribute: "members", FilterAttr: "value", FilterValue: "2", HasValueFilter: true}, path)
+
+	_, err = parsePath(`members[value eq 2]`)
+	assert.Error(t, err)
+}
+
+func TestApplyUserPatch(t *testing.T) {
+	var req patchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations":[
+		{"op":"Replace","path":"active","value":"False"},
+		{"op":"Add","path":"externalId","value":"e1"},
+		{"op":"Replace","path":"emails[type eq \"work\"].value","value":"new@example.com"},
+		{"op":"replace","value":{"displayName":"Alice","name.familyName":"L"}},
+		{"op":"replace","path":"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department","value":"R&D"}
+	]}`), &req))
+
+	active := true
+	res := &userResource{UserName: "alice", Active: &active, Emails: []email{{Value: "old@example.com", Type: "work", Primary: true}}}
+	require.NoError(t, applyUserPatch(res, req.Operations))
+	assert.False(t, *res.Active)
+	assert.Equal(t, "e1", res.ExternalID)
+	assert.Equal(t, "new@example.com", res.primaryEmail())
+	assert.Equal(t, "Alice", res.fullName())
+	assert.Equal(t, "L", res.Name.FamilyName)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations":[{"op":"remove","path":"userName"}]}`), &req))
+	assert.Error(t, applyUserPatch(res, req.Operations))
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	res := &groupResource{DisplayName: "org/team", Members: []reference{{Value: "1"}, {Value: "2"}}}
+
+	var req patchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations":[
+		{"op":"add","path":"members","value":[{"value":"3"}]},
+		{"op":"remove","path":"members[value eq \"1\"]"},
+		{"op":"replace","value":{"id":"5","displayName":"org/renamed"}}
+	]}`), &req))
+	require.NoError(t, applyGroupPatch(res, req.Operations))
+	assert.Equal(t, "org/renamed", res.DisplayName)
+	assert.Equal(t, []reference{{Value: "2"}, {Value: "3"}}, res.Members)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations":[{"op":"remove","path":"members","value":[{"value":"2"}]}]}`), &req))
+	require.NoError(t, applyGroupPatch(res, req.Operations))
+	assert.Equal(t, []reference{{Value: "3"}}, res.Members)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"Operations":[{"op":"replace","path":"members","value":[]}]}`), &req))
+	require.NoError(t, applyGroupPatch(res, req.Operations))
+	assert.Empty(t, res.Members)
+}
@@ -0,0 +1,388 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/audit"
+	user_service "code.gitea.io/gitea/services/user"
+)
+
+// isProvisioned returns whether the user is bound to the authentication source of the endpoints,
+// the other users can't be read or changed by the identity provider
+func (ctx *Context) isProvisioned(u *user_model.User) bool {
+	return u.Type == user_model.UserTypeIndividual && u.LoginSource == ctx.Source.ID
+}
+
+// provisionedUser returns the user of the request path, it writes the error response and returns nil if not found
+func (ctx *Context) provisionedUser() *user_model.User {
+	id := ctx.pathID()
+	if id == 0 {
+		ctx.scimError(http.StatusNotFound, "", "user not found")
+		return nil
+	}
+	u, err := user_model.GetUserByID(ctx, id)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.scimError(http.StatusNotFound, "", "user not found")
+		} else {
+			ctx.serverError("GetUserByID", err)
+		}
+		return nil
+	}
+	if !ctx.isProvisioned(u) {
+		ctx.scimError(http.StatusNotFound, "", "user not found")
+		return nil
+	}
+	return u
+}
+
+// handleError writes the response of the errors returned by the user and team services
+func (ctx *Context) handleError(name string, err error) {
+	var reqErr *requestError
+	switch {
+	case errors.As(err, &reqErr):
+		ctx.scimError(http.StatusBadRequest, reqErr.ScimType, reqErr.Detail)
+	case user_model.IsErrUserAlreadyExist(err),
+		user_model.IsErrEmailAlreadyUsed(err),
+		organization.IsErrTeamAlreadyExist(err):
+		ctx.scimError(http.StatusConflict, scimTypeUniqueness, err.Error())
+	case db.IsErrNameReserved(err),
+		db.IsErrNamePatternNotAllowed(err),
+		db.IsErrNameCharsNotAllowed(err),
+		user_model.IsErrEmailCharIsNotSupported(err),
+		user_model.IsErrEmailInvalid(err):
+		ctx.scimError(http.StatusBadRequest, scimTypeInvalidValue, err.Error())
+	case models.IsErrUserOwnRepos(err),
+		models.IsErrUserHasOrgs(err),
+		models.IsErrUserOwnPackages(err),
+		models.IsErrDeleteLastAdminUser(err),
+		organization.IsErrLastOrgOwner(err):
+		ctx.scimError(http.StatusConflict, "", err.Error())
+	default:
+		ctx.serverError(name, err)
+	}
+}
+
+func (ctx *Context) toUserResource(u *user_model.User) (*userResource, error) {
+	active := u.IsActive && !u.ProhibitLogin
+	created, updated := u.CreatedUnix.AsTime(), u.UpdatedUnix.AsTime()
+	res := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.FullName,
+		Emails:      []email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     resourceLocation("Users", u.ID),
+		},
+	}
+	if u.LoginName != u.Name {
+		res.ExternalID = u.LoginName
+	}
+	if u.FullName != "" {
+		res.Name = &name{Formatted: u.FullName}
+	}
+
+	orgs := make(map[int64]*organization.Organization)
+	opts := &organization.SearchTeamOptions{
+		ListOptions: db.ListOptions{Page: 1, PageSize: setting.API.MaxResponseItems},
+		UserID:      u.ID,
+	}
+	for {
+		teams, _, err := organization.SearchTeam(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, team := range teams {
+			displayName, err := ctx.groupDisplayName(team, orgs)
+			if err != nil {
+				return nil, err
+			}
+			res.Groups = append(res.Groups, reference{
+				Value:   strconv.FormatInt(team.ID, 10),
+				Ref:     resourceLocation("Groups", team.ID),
+				Display: displayName,
+			})
+		}
+		if len(teams) < opts.PageSize {
+			return res, nil
+		}
+		opts.Page++
+	}
+}
+
+// fullName returns the full name of the user resource, the display name is preferred
+func (res *userResource) fullName() string {
+	if res.DisplayName != "" {
+		return res.DisplayName
+	}
+	if res.Name == nil {
+		return ""
+	}
+	if res.Name.Formatted != "" {
+		return res.Name.Formatted
+	}
+	return strings.TrimSpace(res.Name.GivenName + " " + res.Name.FamilyName)
+}
+
+// primaryEmail returns the primary email address of the user resource, or the first one if none is primary
+func (res *userResource) primaryEmail() string {
+	for _, e := range res.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(res.Emails) > 0 {
+		return res.Emails[0].Value
+	}
+	return ""
+}
+
+// loginName returns the login name of the user in the authentication source
+func (res *userResource) loginName() string {
+	if res.ExternalID != "" {
+		return res.ExternalID
+	}
+	return res.UserName
+}
+
+func listUsers(ctx *Context) {
+	pageSize, page := ctx.listRange()
+
+	var users []*user_model.User
+	var total int64
+	if filter := ctx.FormString("filter"); filter != "" {
+		attribute, value, err := parseFilter(filter)
+		if err != nil {
+			ctx.handleError("parseFilter", err)
+			return
+		}
+		var u *user_model.User
+		switch attribute {
+		case "username":
+			u, err = user_model.GetUserByName(ctx, value)
+		case "emails", "emails.value":
+			u, err = user_model.GetUserByEmail(ctx, value)
+		case "externalid":
+			users, total, err = user_model.SearchUsers(ctx, &user_model.SearchUserOptions{
+				Actor:       ctx.Doer,
+				Type:        user_model.UserTypeIndividual,
+				SourceID:    ctx.Source.ID,
+				LoginName:   value,
+				OrderBy:     db.SearchOrderByID,
+				ListOptions: db.ListOptions{Page: page, PageSize: max(pageSize, 1)},
+			})
+		default:
+			ctx.scimError(http.StatusBadRequest, scimTypeInvalidFilter, "unsupported filter attribute "+attribute)
+			return
+		}
+		if err != nil && !user_model.IsErrUserNotExist(err) {
+			ctx.serverError("filter users", err)
+			return
+		}
+		if u != nil && ctx.isProvisioned(u) {
+			users, total = []*user_model.User{u}, 1
+		}
+	} else {
+		var err error
+		users, total, err = user_model.SearchUsers(ctx, &user_model.SearchUserOptions{
+			Actor:       ctx.Doer,
+			Type:        user_model.UserTypeIndividual,
+			SourceID:    ctx.Source.ID,
+			OrderBy:     db.SearchOrderByID,
+			ListOptions: db.ListOptions{Page: page, PageSize: max(pageSize, 1)},
+		})
+		if err != nil {
+			ctx.serverError("SearchUsers", err)
+			return
+		}
+	}
+
+	resp := &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   (page-1)*pageSize + 1,
+		Resources:    []any{},
+	}
+	if pageSize > 0 {
+		for _, u := range users[:min(len(users), pageSize)] {
+			res, err := ctx.toUserResource(u)
+			if err != nil {
+				ctx.serverError("toUserResource", err)
+				return
+			}
+			resp.Resources = append(resp.Resources, res)
+		}
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+	ctx.scimJSON(http.StatusOK, resp)
+}
+
+func getUser(ctx *Context) {
+	u := ctx.provisionedUser()
+	if u == nil {
+		return
+	}
+	res, err := ctx.toUserResource(u)
+	if err != nil {
+		ctx.serverError("toUserResource", err)
+		return
+	}
+	ctx.scimJSON(http.StatusOK, res)
+}
+
+func createUser(ctx *Context) {
+	res := &userResource{}
+	if !ctx.decodeBody(res) {
+		return
+	}
+	if res.UserName == "" {
+		ctx.scimError(http.StatusBadRequest, scimTypeInvalidValue, "userName is required")
+		return
+	}
+	emailAddress := res.primaryEmail()
+	if emailAddress == "" {
+		ctx.scimError(http.StatusBadRequest, scimTypeInvalidValue, "an email address is required")
+		return
+	}
+	active := res.Active == nil || *res.Active
+
+	u := &user_model.User{
+		Name:          res.UserName,
+		FullName:      res.fullName(),
+		Email:         emailAddress,
+		LoginType:     ctx.Source.Type,
+		LoginSource:   ctx.Source.ID,
+		LoginName:     res.loginName(),
+		ProhibitLogin: !active,
+	}
+	if err := user_model.AdminCreateUser(ctx, u, &user_model.Meta{}, &user_model.CreateUserOverwriteOptions{
+		IsActive: optional.Some(active),
+	}); err != nil {
+		ctx.handleError("AdminCreateUser", err)
+		return
+	}
+	log.Trace("SCIM: user %s provisioned by %s", u.Name, ctx.Doer.Name)
+	audit.Record(ctx, audit_model.ActionUserCreate, ctx.Doer, audit.OwnerScope(u.ID), audit.UserTarget(u), nil, audit.UserState(u))
+
+	created, err := ctx.toUserResource(u)
+	if err != nil {
+		ctx.serverError("toUserResource", err)
+		return
+	}
+	ctx.Resp.Header().Set("Location", created.Meta.Location)
+	ctx.scimJSON(http.StatusCreated, created)
+}
+
+// updateUser changes the user to match the resource, the active flag is kept if the resource doesn't have it
+func (ctx *Context) updateUser(u *user_model.User, res *userResource) error {
+	if res.UserName != u.Name {
+		// the users of an authentication source can't be renamed, see user_service.RenameUser
+		return newRequestError(scimTypeMutability, "userName of a provisioned user can not be changed")
+	}
+	emailAddress := res.primaryEmail()
+	if emailAddress == "" {
+		return newRequestError(scimTypeInvalidValue, "an email address is required")
+	}
+	if err := user_service.AdminAddOrSetPrimaryEmailAddress(ctx, u, emailAddress); err != nil {
+		return err
+	}
+
+	active := u.IsActive && !u.ProhibitLogin
+	if res.Active != nil {
+		active = *res.Active
+	}
+	if err := user_service.UpdateUser(ctx, u, &user_service.UpdateOptions{
+		FullName: optional.Some(res.fullName()),
+		IsActive: optional.Some(active),
+	}); err != nil {
+		return err
+	}
+	return user_service.UpdateAuth(ctx, u, &user_service.UpdateAuthOptions{
+		LoginName:     optional.Some(res.loginName()),
+		ProhibitLogin: optional.Some(!active),
+	})
+}
+
+func (ctx *Context) writeUpdatedUser(u *user_model.User, res *userResource) {
+	before := audit.UserState(u)
+	if err := ctx.updateUser(u, res); err != nil {
+		ctx.handleError("updateUser", err)
+		return
+	}
+	log.Trace("SCIM: user %s updated by %s", u.Name, ctx.Doer.Name)
+	audit.Record(ctx, audit_model.ActionUserUpdate, ctx.Doer, audit.OwnerScope(u.ID), audit.UserTarget(u), before, audit.UserState(u))
+
+	updated, err := ctx.toUserResource(u)
+	if err != nil {
+		ctx.serverError("toUserResource", err)
+		return
+	}
+	ctx.scimJSON(http.StatusOK, updated)
+}
+
+func replaceUser(ctx *Context) {
+	u := ctx.provisionedUser()
+	if u == nil {
+		return
+	}
+	res := &userResource{}
+	if !ctx.decodeBody(res) {
+		return
+	}
+	ctx.writeUpdatedUser(u, res)
+}
+
+func patchUser(ctx *Context) {
+	u := ctx.provisionedUser()
+	if u == nil {
+		return
+	}
+	req := &patchRequest{}
+	if !ctx.decodeBody(req) {
+		return
+	}
+	res, err := ctx.toUserResource(u)
+	if err != nil {
+		ctx.serverError("toUserResource", err)
+		return
+	}
+	if err := applyUserPatch(res, req.Operations); err != nil {
+		ctx.handleError("applyUserPatch", err)
+		return
+	}
+	ctx.writeUpdatedUser(u, res)
+}
+
+// deleteUser deletes the user, it fails if the user still owns repositories, organizations or packages,
+// the identity provider could deactivate the user instead
+func deleteUser(ctx *Context) {
+	u := ctx.provisionedUser()
+	if u == nil {
+		return
+	}
+	if err := user_service.DeleteUser(ctx, u, false); err != nil {
+		ctx.handleError("DeleteUser", err)
+		return
+	}
+	log.Trace("SCIM: user %s deleted by %s", u.Name, ctx.Doer.Name)
+	ctx.Status(http.StatusNoContent)
+}
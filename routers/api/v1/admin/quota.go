@@ -0,0 +1,620 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+)
+
+func toLimitSubjects(subjects []string) []quota_model.LimitSubject {
+	ret := make([]quota_model.LimitSubject, len(subjects))
+	for i, subject := range subjects {
+		ret[i] = quota_model.LimitSubject(subject)
+	}
+	return ret
+}
+
+// getQuotaRuleByPath returns the quota rule with the name in the path, it responds 404 if it doesn't exist
+func getQuotaRuleByPath(ctx *context.APIContext) *quota_model.Rule {
+	rule, err := quota_model.GetRuleByName(ctx, ctx.PathParam("rule"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRuleByName", err)
+		}
+		return nil
+	}
+	return rule
+}
+
+// getQuotaGroupByPath returns the quota group with the name in the path, it responds 404 if it doesn't exist
+func getQuotaGroupByPath(ctx *context.APIContext) *quota_model.Group {
+	group, err := quota_model.GetGroupByName(ctx, ctx.PathParam("group"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetGroupByName", err)
+		}
+		return nil
+	}
+	return group
+}
+
+// ListQuotaRules lists the quota rules
+func ListQuotaRules(ctx *context.APIContext) {
+	// swagger:operation GET /admin/quota/rules admin adminListQuotaRules
+	// ---
+	// summary: List the quota rules
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaRuleList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	rules, err := quota_model.ListRules(ctx)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListRules", err)
+		return
+	}
+
+	apiRules := make([]*api.QuotaRule, len(rules))
+	for i, rule := range rules {
+		apiRules[i] = convert.ToQuotaRule(rule)
+	}
+	ctx.JSON(http.StatusOK, apiRules)
+}
+
+// CreateQuotaRule creates a quota rule
+func CreateQuotaRule(ctx *context.APIContext) {
+	// swagger:operation POST /admin/quota/rules admin adminCreateQuotaRule
+	// ---
+	// summary: Create a quota rule
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateQuotaRuleOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/QuotaRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateQuotaRuleOption)
+
+	rule := &quota_model.Rule{
+		Name:     form.Name,
+		Limit:    form.Limit,
+		Subjects: toLimitSubjects(form.Subjects),
+	}
+	if err := quota_model.CreateRule(ctx, rule); err != nil {
+		switch {
+		case errors.Is(err, util.ErrAlreadyExist):
+			ctx.Error(http.StatusConflict, "CreateRule", err)
+		case errors.Is(err, util.ErrInvalidArgument):
+			ctx.Error(http.StatusUnprocessableEntity, "CreateRule", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateRule", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToQuotaRule(rule))
+}
+
+// GetQuotaRule gets a quota rule
+func GetQuotaRule(ctx *context.APIContext) {
+	// swagger:operation GET /admin/quota/rules/{rule} admin adminGetQuotaRule
+	// ---
+	// summary: Get a quota rule
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: rule
+	//   in: path
+	//   description: name of the rule
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getQuotaRuleByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToQuotaRule(rule))
+}
+
+// EditQuotaRule edits a quota rule
+func EditQuotaRule(ctx *context.APIContext) {
+	// swagger:operation PATCH /admin/quota/rules/{rule} admin adminEditQuotaRule
+	// ---
+	// summary: Edit a quota rule
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: rule
+	//   in: path
+	//   description: name of the rule
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditQuotaRuleOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaRule"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	rule := getQuotaRuleByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	form := web.GetForm(ctx).(*api.EditQuotaRuleOption)
+
+	if form.Limit != nil {
+		rule.Limit = *form.Limit
+	}
+	if form.Subjects != nil {
+		rule.Subjects = toLimitSubjects(form.Subjects)
+	}
+	if err := quota_model.UpdateRule(ctx, rule); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "UpdateRule", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateRule", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToQuotaRule(rule))
+}
+
+// DeleteQuotaRule deletes a quota rule
+func DeleteQuotaRule(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/quota/rules/{rule} admin adminDeleteQuotaRule
+	// ---
+	// summary: Delete a quota rule, it's removed from all the groups
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: rule
+	//   in: path
+	//   description: name of the rule
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getQuotaRuleByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.DeleteRule(ctx, rule); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteRule", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListQuotaGroups lists the quota groups
+func ListQuotaGroups(ctx *context.APIContext) {
+	// swagger:operation GET /admin/quota/groups admin adminListQuotaGroups
+	// ---
+	// summary: List the quota groups
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaGroupList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	groups, err := quota_model.ListGroups(ctx)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListGroups", err)
+		return
+	}
+	if err := groups.LoadRules(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRules", err)
+		return
+	}
+
+	apiGroups := make([]*api.QuotaGroup, len(groups))
+	for i, group := range groups {
+		apiGroups[i] = convert.ToQuotaGroup(group)
+	}
+	ctx.JSON(http.StatusOK, apiGroups)
+}
+
+// CreateQuotaGroup creates a quota group
+func CreateQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation POST /admin/quota/groups admin adminCreateQuotaGroup
+	// ---
+	// summary: Create a quota group
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateQuotaGroupOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/QuotaGroup"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateQuotaGroupOption)
+
+	rules := make([]*quota_model.Rule, 0, len(form.Rules))
+	for _, name := range form.Rules {
+		rule, err := quota_model.GetRuleByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, util.ErrNotExist) {
+				ctx.Error(http.StatusUnprocessableEntity, "GetRuleByName", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetRuleByName", err)
+			}
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	group := &quota_model.Group{Name: form.Name}
+	if err := quota_model.CreateGroup(ctx, group); err != nil {
+		if errors.Is(err, util.ErrAlreadyExist) {
+			ctx.Error(http.StatusConflict, "CreateGroup", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateGroup", err)
+		}
+		return
+	}
+	for _, rule := range rules {
+		if err := quota_model.AddRuleToGroup(ctx, group, rule); err != nil {
+			ctx.Error(http.StatusInternalServerError, "AddRuleToGroup", err)
+			return
+		}
+	}
+	if err := group.LoadRules(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRules", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToQuotaGroup(group))
+}
+
+// GetQuotaGroup gets a quota group
+func GetQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation GET /admin/quota/groups/{group} admin adminGetQuotaGroup
+	// ---
+	// summary: Get a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaGroup"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := group.LoadRules(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRules", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToQuotaGroup(group))
+}
+
+// DeleteQuotaGroup deletes a quota group
+func DeleteQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/quota/groups/{group} admin adminDeleteQuotaGroup
+	// ---
+	// summary: Delete a quota group, its rules are kept
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.DeleteGroup(ctx, group); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteGroup", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// AddRuleToQuotaGroup adds a rule to a quota group
+func AddRuleToQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation PUT /admin/quota/groups/{group}/rules/{rule} admin adminAddRuleToQuotaGroup
+	// ---
+	// summary: Add a rule to a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// - name: rule
+	//   in: path
+	//   description: name of the rule
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	rule := getQuotaRuleByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.AddRuleToGroup(ctx, group, rule); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddRuleToGroup", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveRuleFromQuotaGroup removes a rule from a quota group
+func RemoveRuleFromQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/quota/groups/{group}/rules/{rule} admin adminRemoveRuleFromQuotaGroup
+	// ---
+	// summary: Remove a rule from a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// - name: rule
+	//   in: path
+	//   description: name of the rule
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	rule := getQuotaRuleByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.RemoveRuleFromGroup(ctx, group, rule); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RemoveRuleFromGroup", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListUsersInQuotaGroup lists the users and organizations in a quota group
+func ListUsersInQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation GET /admin/quota/groups/{group}/users admin adminListUsersInQuotaGroup
+	// ---
+	// summary: List the users and organizations in a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	users, err := quota_model.ListUsersInGroup(ctx, group)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListUsersInGroup", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToUsers(ctx, ctx.Doer, users))
+}
+
+// AddUserToQuotaGroup adds a user or an organization to a quota group
+func AddUserToQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation PUT /admin/quota/groups/{group}/users/{username} admin adminAddUserToQuotaGroup
+	// ---
+	// summary: Add a user or an organization to a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the user or the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.AddUserToGroup(ctx, group, ctx.ContextUser.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "AddUserToGroup", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// RemoveUserFromQuotaGroup removes a user or an organization from a quota group
+func RemoveUserFromQuotaGroup(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/quota/groups/{group}/users/{username} admin adminRemoveUserFromQuotaGroup
+	// ---
+	// summary: Remove a user or an organization from a quota group
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: group
+	//   in: path
+	//   description: name of the group
+	//   type: string
+	//   required: true
+	// - name: username
+	//   in: path
+	//   description: username of the user or the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	group := getQuotaGroupByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := quota_model.RemoveUserFromGroup(ctx, group, ctx.ContextUser.ID); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RemoveUserFromGroup", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetUserQuota returns the storage quota of a user or an organization
+func GetUserQuota(ctx *context.APIContext) {
+	// swagger:operation GET /admin/users/{username}/quota admin adminGetUserQuota
+	// ---
+	// summary: Get the storage used by a user or an organization and its quota
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of the user or the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QuotaInfo"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	info, err := convert.ToQuotaInfo(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToQuotaInfo", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, info)
+}
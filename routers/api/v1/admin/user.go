@@ -11,6 +11,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -24,6 +25,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/convert"
 	"code.gitea.io/gitea/services/mailer"
@@ -153,6 +155,7 @@ func CreateUser(ctx *context.APIContext) {
 	}
 
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit.Record(ctx, audit_model.ActionUserCreate, ctx.Doer, audit.OwnerScope(u.ID), audit.UserTarget(u), nil, audit.UserState(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -191,6 +194,7 @@ func EditUser(ctx *context.APIContext) {
 	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.EditUserOption)
+	before := audit.UserState(ctx.ContextUser)
 
 	authOpts := &user_service.UpdateAuthOptions{
 		LoginSource:        optional.FromNonDefault(form.SourceID),
@@ -256,6 +260,11 @@ func EditUser(ctx *context.APIContext) {
 	}
 
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	after := audit.UserState(ctx.ContextUser)
+	if form.Password != "" {
+		after["password_changed"] = true
+	}
+	audit.Record(ctx, audit_model.ActionUserUpdate, ctx.Doer, audit.OwnerScope(ctx.ContextUser.ID), audit.UserTarget(ctx.ContextUser), before, after)
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx, ctx.ContextUser, ctx.Doer))
 }
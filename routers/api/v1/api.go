@@ -70,7 +70,6 @@ import (
 	"net/http"
 	"strings"
 
-	actions_model "code.gitea.io/gitea/models/actions"
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
@@ -97,6 +96,7 @@ import (
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/maintenance"
 
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
 
@@ -190,27 +190,11 @@ func repoAssignment() func(ctx *context.APIContext) {
 
 		if ctx.Doer != nil && ctx.Doer.ID == user_model.ActionsUserID {
 			taskID := ctx.Data["ActionsTaskID"].(int64)
-			task, err := actions_model.GetTaskByID(ctx, taskID)
+			ctx.Repo.Permission, err = access_model.GetActionsUserRepoPermission(ctx, repo, ctx.Doer, taskID)
 			if err != nil {
-				ctx.Error(http.StatusInternalServerError, "actions_model.GetTaskByID", err)
+				ctx.Error(http.StatusInternalServerError, "GetActionsUserRepoPermission", err)
 				return
 			}
-			if task.RepoID != repo.ID {
-				ctx.NotFound()
-				return
-			}
-
-			if task.IsForkPullRequest {
-				ctx.Repo.Permission.AccessMode = perm.AccessModeRead
-			} else {
-				ctx.Repo.Permission.AccessMode = perm.AccessModeWrite
-			}
-
-			if err := ctx.Repo.Repository.LoadUnits(ctx); err != nil {
-				ctx.Error(http.StatusInternalServerError, "LoadUnits", err)
-				return
-			}
-			ctx.Repo.Permission.SetUnitsWithDefaultAccessMode(ctx.Repo.Repository.Units, ctx.Repo.Permission.AccessMode)
 		} else {
 			ctx.Repo.Permission, err = access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
 			if err != nil {
@@ -852,6 +836,14 @@ func checkDeprecatedAuthMethods(ctx *context.APIContext) {
 	}
 }
 
+// maintenanceMode rejects the requests that change data while the instance is in the maintenance mode
+func maintenanceMode(ctx *context.APIContext) {
+	if maintenance.IsReadOnlyRequest(ctx.Req) || !maintenance.IsEnabled(ctx) {
+		return
+	}
+	ctx.Error(http.StatusServiceUnavailable, "MaintenanceMode", maintenance.Message(ctx))
+}
+
 // Routes registers all v1 APIs routes to web application.
 func Routes() *web.Router {
 	m := web.NewRouter()
@@ -877,6 +869,8 @@ func Routes() *web.Router {
 		SignInRequired: setting.Service.RequireSignInView,
 	}))
 
+	m.Use(maintenanceMode)
+
 	addActionsRoutes := func(
 		m *web.Router,
 		reqChecker func(ctx *context.APIContext),
@@ -1008,6 +1002,7 @@ func Routes() *web.Router {
 				m.Get("", user.GetUserSettings)
 				m.Patch("", bind(api.UserSettingsOptions{}), user.UpdateUserSettings)
 			}, reqToken())
+			m.Get("/quota", reqToken(), user.GetQuota)
 			m.Combo("/emails").
 				Get(user.ListEmails).
 				Post(bind(api.CreateEmailOption{}), user.AddEmail).
@@ -1177,6 +1172,7 @@ func Routes() *web.Router {
 						m.Get("/permission", repo.GetRepoPermissions)
 					})
 				}, reqToken())
+				m.Get("/audit", reqToken(), reqAdmin(), repo.ListAuditEvents)
 				m.Get("/assignees", reqToken(), reqAnyRepoReader(), repo.GetAssignees)
 				m.Get("/reviewers", reqToken(), reqAnyRepoReader(), repo.GetReviewers)
 				m.Group("/teams", func() {
@@ -1224,7 +1220,67 @@ func Routes() *web.Router {
 				}, reqToken(), reqAdmin())
 				m.Group("/actions", func() {
 					m.Get("/tasks", repo.ListActionTasks)
+					m.Group("/runs", func() {
+						m.Get("", repo.ListWorkflowRuns)
+						m.Group("/{run}", func() {
+							m.Get("", repo.GetWorkflowRun)
+							m.Get("/jobs", repo.ListWorkflowRunJobs)
+							m.Get("/artifacts", repo.ListWorkflowRunArtifacts)
+							m.Post("/cancel", reqToken(), reqRepoWriter(unit.TypeActions), repo.CancelWorkflowRun)
+							m.Post("/rerun", reqToken(), reqRepoWriter(unit.TypeActions), repo.RerunWorkflowRun)
+							m.Post("/approve", reqToken(), reqRepoWriter(unit.TypeActions), repo.ApproveWorkflowRun)
+							m.Combo("/pending_deployments").
+								Get(repo.ListPendingDeployments).
+								Post(reqToken(), bind(api.ReviewPendingDeploymentsOption{}), repo.ReviewPendingDeployments)
+						})
+					})
+					m.Group("/jobs/{job_id}", func() {
+						m.Get("", repo.GetWorkflowJob)
+						m.Get("/logs", repo.DownloadWorkflowJobLogs)
+						m.Post("/rerun", reqToken(), reqRepoWriter(unit.TypeActions), repo.RerunWorkflowJob)
+					})
+					m.Group("/artifacts", func() {
+						m.Get("", repo.ListArtifacts)
+						m.Get("/{artifact_id}", repo.GetArtifact)
+						m.Get("/{artifact_id}/zip", repo.DownloadArtifact)
+					})
+					m.Group("/workflows", func() {
+						m.Get("", repo.ListWorkflows)
+						m.Group("/{workflow_id}", func() {
+							m.Get("", repo.GetWorkflow)
+							m.Get("/runs", repo.ListWorkflowRunsOfWorkflow)
+							m.Put("/enable", reqToken(), reqAdmin(), repo.EnableWorkflow)
+							m.Put("/disable", reqToken(), reqAdmin(), repo.DisableWorkflow)
+							m.Post("/dispatches", reqToken(), reqRepoWriter(unit.TypeActions), bind(api.CreateActionWorkflowDispatch{}), repo.DispatchWorkflow)
+						})
+					})
 				}, reqRepoReader(unit.TypeActions), context.ReferencesGitRepo(true))
+				m.Group("/environments", func() {
+					m.Get("", repo.ListEnvironments)
+					m.Group("/{environment_name}", func() {
+						m.Combo("").Get(repo.GetEnvironment).
+							Put(reqToken(), reqAdmin(), bind(api.CreateOrUpdateEnvironmentOption{}), repo.CreateOrUpdateEnvironment).
+							Delete(reqToken(), reqAdmin(), repo.DeleteEnvironment)
+						m.Group("/secrets", func() {
+							m.Get("", repo.ListEnvironmentSecrets)
+							m.Combo("/{secretname}").
+								Put(bind(api.CreateOrUpdateSecretOption{}), repo.CreateOrUpdateEnvironmentSecret).
+								Delete(repo.DeleteEnvironmentSecret)
+						}, reqToken(), reqAdmin())
+						m.Group("/variables", func() {
+							m.Get("", repo.ListEnvironmentVariables)
+							m.Combo("/{variablename}").
+								Get(repo.GetEnvironmentVariable).
+								Delete(repo.DeleteEnvironmentVariable).
+								Post(bind(api.CreateVariableOption{}), repo.CreateEnvironmentVariable).
+								Put(bind(api.UpdateVariableOption{}), repo.UpdateEnvironmentVariable)
+						}, reqToken(), reqAdmin())
+					})
+				}, reqRepoReader(unit.TypeActions))
+				m.Group("/deployments", func() {
+					m.Get("", repo.ListDeployments)
+					m.Get("/{id}", repo.GetDeployment)
+				}, reqRepoReader(unit.TypeActions))
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
 						Post(bind(api.CreateKeyOption{}), repo.CreateDeployKey)
@@ -1571,6 +1627,8 @@ func Routes() *web.Router {
 				m.Delete("", org.DeleteAvatar)
 			}, reqToken(), reqOrgOwnership())
 			m.Get("/activities/feeds", org.ListOrgActivityFeeds)
+			m.Get("/quota", reqToken(), reqOrgOwnership(), org.GetQuota)
+			m.Get("/audit", reqToken(), reqOrgOwnership(), org.ListAuditEvents)
 
 			m.Group("/blocks", func() {
 				m.Get("", org.ListBlocks)
@@ -1607,6 +1665,7 @@ func Routes() *web.Router {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			m.Get("/audit", admin.ListAuditEvents)
 			m.Get("/orgs", admin.GetAllOrgs)
 			m.Group("/users", func() {
 				m.Get("", admin.SearchUsers)
@@ -1625,6 +1684,7 @@ func Routes() *web.Router {
 					m.Get("/badges", admin.ListUserBadges)
 					m.Post("/badges", bind(api.UserBadgeOption{}), admin.AddUserBadges)
 					m.Delete("/badges", bind(api.UserBadgeOption{}), admin.DeleteUserBadges)
+					m.Get("/quota", admin.GetUserQuota)
 				}, context.UserAssignmentAPI())
 			})
 			m.Group("/emails", func() {
@@ -1646,6 +1706,28 @@ func Routes() *web.Router {
 			m.Group("/runners", func() {
 				m.Get("/registration-token", admin.GetRegistrationToken)
 			})
+			m.Group("/quota", func() {
+				m.Group("/rules", func() {
+					m.Combo("").Get(admin.ListQuotaRules).
+						Post(bind(api.CreateQuotaRuleOption{}), admin.CreateQuotaRule)
+					m.Combo("/{rule}").Get(admin.GetQuotaRule).
+						Patch(bind(api.EditQuotaRuleOption{}), admin.EditQuotaRule).
+						Delete(admin.DeleteQuotaRule)
+				})
+				m.Group("/groups", func() {
+					m.Combo("").Get(admin.ListQuotaGroups).
+						Post(bind(api.CreateQuotaGroupOption{}), admin.CreateQuotaGroup)
+					m.Group("/{group}", func() {
+						m.Combo("").Get(admin.GetQuotaGroup).
+							Delete(admin.DeleteQuotaGroup)
+						m.Combo("/rules/{rule}").Put(admin.AddRuleToQuotaGroup).
+							Delete(admin.RemoveRuleFromQuotaGroup)
+						m.Get("/users", admin.ListUsersInQuotaGroup)
+						m.Combo("/users/{username}", context.UserAssignmentAPI()).Put(admin.AddUserToQuotaGroup).
+							Delete(admin.RemoveUserFromQuotaGroup)
+					})
+				})
+			})
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryAdmin), reqToken(), reqSiteAdmin())
 
 		m.Group("/topics", func() {
@@ -0,0 +1,847 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/routers/common"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// actionsStatusFromString returns the statuses matching the GitHub compatible status or conclusion, see convert.ToActionsStatus
+func actionsStatusFromString(s string) ([]actions_model.Status, bool) {
+	switch s {
+	case "queued":
+		return []actions_model.Status{actions_model.StatusWaiting}, true
+	case "waiting":
+		return []actions_model.Status{actions_model.StatusBlocked}, true
+	case "in_progress":
+		return []actions_model.Status{actions_model.StatusRunning}, true
+	case "completed":
+		return []actions_model.Status{actions_model.StatusSuccess, actions_model.StatusFailure, actions_model.StatusCancelled, actions_model.StatusSkipped}, true
+	case "success":
+		return []actions_model.Status{actions_model.StatusSuccess}, true
+	case "failure":
+		return []actions_model.Status{actions_model.StatusFailure}, true
+	case "cancelled":
+		return []actions_model.Status{actions_model.StatusCancelled}, true
+	case "skipped":
+		return []actions_model.Status{actions_model.StatusSkipped}, true
+	}
+	return nil, false
+}
+
+// ListWorkflowRuns list the workflow runs of a repository
+func ListWorkflowRuns(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs repository repoListWorkflowRuns
+	// ---
+	// summary: List a repository's workflow runs
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: actor
+	//   in: query
+	//   description: username of the user who triggered the runs
+	//   type: string
+	// - name: branch
+	//   in: query
+	//   description: branch the runs ran on
+	//   type: string
+	// - name: event
+	//   in: query
+	//   description: event which triggered the runs
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: status or conclusion of the runs
+	//   type: string
+	//   enum: [queued, waiting, in_progress, completed, success, failure, cancelled, skipped]
+	// - name: head_sha
+	//   in: query
+	//   description: commit the runs ran on
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowRunList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	listWorkflowRuns(ctx, "")
+}
+
+func listWorkflowRuns(ctx *context.APIContext, workflowID string) {
+	opts := actions_model.FindRunOptions{
+		ListOptions:  utils.GetListOptions(ctx),
+		RepoID:       ctx.Repo.Repository.ID,
+		WorkflowID:   workflowID,
+		CommitSHA:    ctx.FormTrim("head_sha"),
+		TriggerEvent: webhook_module.HookEventType(ctx.FormTrim("event")),
+	}
+	if branch := ctx.FormTrim("branch"); branch != "" {
+		opts.Ref = git.RefNameFromBranch(branch).String()
+	}
+	if s := ctx.FormTrim("status"); s != "" {
+		status, ok := actionsStatusFromString(s)
+		if !ok {
+			ctx.Error(http.StatusUnprocessableEntity, "actionsStatusFromString", util.NewInvalidArgumentErrorf("invalid status %q", s))
+			return
+		}
+		opts.Status = status
+	}
+	if actor := ctx.FormTrim("actor"); actor != "" {
+		user, err := user_model.GetUserByName(ctx, actor)
+		if user_model.IsErrUserNotExist(err) {
+			ctx.JSON(http.StatusOK, &api.ActionWorkflowRunsResponse{WorkflowRuns: []*api.ActionWorkflowRun{}})
+			return
+		} else if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			return
+		}
+		opts.TriggerUserID = user.ID
+	}
+
+	runs, total, err := db.FindAndCount[actions_model.ActionRun](ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRuns", err)
+		return
+	}
+
+	res := &api.ActionWorkflowRunsResponse{
+		TotalCount:   total,
+		WorkflowRuns: make([]*api.ActionWorkflowRun, 0, len(runs)),
+	}
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		apiRun, err := convert.ToActionWorkflowRun(ctx, run)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToActionWorkflowRun", err)
+			return
+		}
+		res.WorkflowRuns = append(res.WorkflowRuns, apiRun)
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetWorkflowRun get a workflow run of a repository
+func GetWorkflowRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run} repository repoGetWorkflowRun
+	// ---
+	// summary: Get a workflow run of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiRun, err := convert.ToActionWorkflowRun(ctx, run)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToActionWorkflowRun", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiRun)
+}
+
+// CancelWorkflowRun cancel a workflow run
+func CancelWorkflowRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run}/cancel repository repoCancelWorkflowRun
+	// ---
+	// summary: Cancel the jobs of a workflow run which are not completed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/conflict"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if run.Status.IsDone() {
+		ctx.Error(http.StatusConflict, "", "cannot cancel a completed workflow run")
+		return
+	}
+
+	if err := actions_service.CancelRun(ctx, run); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelRun", err)
+		return
+	}
+	ctx.Status(http.StatusAccepted)
+}
+
+// RerunWorkflowRun rerun a workflow run
+func RerunWorkflowRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run}/rerun repository repoRerunWorkflowRun
+	// ---
+	// summary: Rerun the completed jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.RerunRun(ctx, run, nil); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RerunRun", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
+
+// ApproveWorkflowRun approve a workflow run
+func ApproveWorkflowRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run}/approve repository repoApproveWorkflowRun
+	// ---
+	// summary: Approve a workflow run of a fork pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.ApproveRun(ctx, ctx.Doer, run); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ApproveRun", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
+
+// ListWorkflowRunJobs list the jobs of a workflow run
+func ListWorkflowRunJobs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/jobs repository repoListWorkflowRunJobs
+	// ---
+	// summary: List the jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowJobList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	jobs, total, err := db.FindAndCount[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		RunID:       run.ID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRunJobs", err)
+		return
+	}
+
+	res := &api.ActionWorkflowJobsResponse{
+		TotalCount: total,
+		Jobs:       make([]*api.ActionWorkflowJob, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		job.Run = run
+		apiJob := toActionWorkflowJob(ctx, job)
+		if ctx.Written() {
+			return
+		}
+		res.Jobs = append(res.Jobs, apiJob)
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func toActionWorkflowJob(ctx *context.APIContext, job *actions_model.ActionRunJob) *api.ActionWorkflowJob {
+	var task *actions_model.ActionTask
+	if job.TaskID > 0 {
+		var err error
+		if task, err = actions_model.GetTaskByID(ctx, job.TaskID); err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetTaskByID", err)
+			return nil
+		}
+	}
+	apiJob, err := convert.ToActionWorkflowJob(ctx, job, task)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToActionWorkflowJob", err)
+		return nil
+	}
+	return apiJob
+}
+
+// getRunJobByPath returns the workflow job of the repository with the id in the path, it responds 404 if it doesn't exist
+func getRunJobByPath(ctx *context.APIContext) *actions_model.ActionRunJob {
+	job, err := actions_model.GetRunJobByID(ctx, ctx.PathParamInt64("job_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunJobByID", err)
+		}
+		return nil
+	}
+	if job.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	if err := job.LoadRun(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadRun", err)
+		return nil
+	}
+	job.Run.Repo = ctx.Repo.Repository
+	return job
+}
+
+// GetWorkflowJob get a workflow job of a repository
+func GetWorkflowJob(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/jobs/{job_id} repository repoGetWorkflowJob
+	// ---
+	// summary: Get a workflow job of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the workflow job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowJob"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	job := getRunJobByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiJob := toActionWorkflowJob(ctx, job)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, apiJob)
+}
+
+// DownloadWorkflowJobLogs download the logs of a workflow job
+func DownloadWorkflowJobLogs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/jobs/{job_id}/logs repository repoDownloadWorkflowJobLogs
+	// ---
+	// summary: Download the logs of a workflow job
+	// produces:
+	// - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the workflow job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: the logs of the job
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	job := getRunJobByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := common.DownloadActionsRunJobLogs(ctx.Base, job); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DownloadActionsRunJobLogs", err)
+		}
+	}
+}
+
+// RerunWorkflowJob rerun a workflow job
+func RerunWorkflowJob(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/jobs/{job_id}/rerun repository repoRerunWorkflowJob
+	// ---
+	// summary: Rerun a completed workflow job and the jobs depending on it
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: job_id
+	//   in: path
+	//   description: id of the workflow job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	job := getRunJobByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !job.Status.IsDone() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "cannot rerun a workflow job which is not completed")
+		return
+	}
+
+	if err := actions_service.RerunRun(ctx, job.Run, job); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RerunRun", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
+
+// ListArtifacts list the artifacts of a repository
+func ListArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts repository repoListArtifacts
+	// ---
+	// summary: List a repository's artifacts
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: name of the artifacts
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ArtifactList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	listArtifacts(ctx, nil)
+}
+
+// ListWorkflowRunArtifacts list the artifacts of a workflow run
+func ListWorkflowRunArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/artifacts repository repoListWorkflowRunArtifacts
+	// ---
+	// summary: List the artifacts of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: name of the artifacts
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ArtifactList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	listArtifacts(ctx, run)
+}
+
+func listArtifacts(ctx *context.APIContext, run *actions_model.ActionRun) {
+	opts := actions_model.FindArtifactsOptions{
+		ListOptions:  utils.GetListOptions(ctx),
+		RepoID:       ctx.Repo.Repository.ID,
+		ArtifactName: ctx.FormTrim("name"),
+	}
+	if run != nil {
+		opts.RunID = run.ID
+	}
+	artifacts, total, err := actions_model.FindUploadedArtifactsMeta(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindUploadedArtifactsMeta", err)
+		return
+	}
+
+	runs := make(map[int64]*actions_model.ActionRun)
+	if run != nil {
+		runs[run.ID] = run
+	}
+	res := &api.ActionArtifactsResponse{
+		TotalCount: total,
+		Artifacts:  make([]*api.ActionArtifact, 0, len(artifacts)),
+	}
+	for _, art := range artifacts {
+		if runs[art.RunID] == nil {
+			if runs[art.RunID], err = actions_model.GetRunByID(ctx, art.RunID); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+				return
+			}
+		}
+		res.Artifacts = append(res.Artifacts, convert.ToActionArtifact(ctx.Repo.Repository, art, runs[art.RunID]))
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getArtifactByPath returns the artifact of the repository with the id in the path and its run, it responds 404 if it doesn't exist
+func getArtifactByPath(ctx *context.APIContext) (*actions_model.ActionArtifactMeta, *actions_model.ActionRun) {
+	file, exist, err := db.GetByID[actions_model.ActionArtifact](ctx, ctx.PathParamInt64("artifact_id"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetArtifactByID", err)
+		return nil, nil
+	}
+	if !exist || file.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil, nil
+	}
+
+	// the artifact contains all the files with the same name uploaded by the run
+	artifacts, _, err := actions_model.FindUploadedArtifactsMeta(ctx, actions_model.FindArtifactsOptions{
+		RunID:        file.RunID,
+		ArtifactName: file.ArtifactName,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindUploadedArtifactsMeta", err)
+		return nil, nil
+	}
+	if len(artifacts) == 0 {
+		ctx.NotFound()
+		return nil, nil
+	}
+	run, err := actions_model.GetRunByID(ctx, file.RunID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		return nil, nil
+	}
+	return artifacts[0], run
+}
+
+// GetArtifact get an artifact of a repository
+func GetArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts/{artifact_id} repository repoGetArtifact
+	// ---
+	// summary: Get an artifact of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: artifact_id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Artifact"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	art, run := getArtifactByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionArtifact(ctx.Repo.Repository, art, run))
+}
+
+// DownloadArtifact download an artifact as a zip file
+func DownloadArtifact(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/artifacts/{artifact_id}/zip repository repoDownloadArtifact
+	// ---
+	// summary: Download an artifact of a repository as a zip file
+	// produces:
+	// - application/zip
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: artifact_id
+	//   in: path
+	//   description: id of the artifact
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: the zip file of the artifact
+	//   "302":
+	//     description: redirect to the zip file of the artifact in the storage
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "410":
+	//     description: the artifact has expired
+
+	art, run := getArtifactByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	if art.Status == actions_model.ArtifactStatusExpired {
+		ctx.Error(http.StatusGone, "", "artifact has expired")
+		return
+	}
+
+	if err := common.DownloadActionsRunArtifacts(ctx.Base, run.ID, art.ArtifactName); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DownloadActionsRunArtifacts", err)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// ListWorkflows list the workflows of a repository
+func ListWorkflows(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/workflows repository repoListWorkflows
+	// ---
+	// summary: List the workflows in the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflowList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	workflows, err := actions_service.ListDefaultBranchWorkflows(ctx.Repo.Repository, ctx.Repo.GitRepo)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListDefaultBranchWorkflows", err)
+		return
+	}
+
+	res := &api.ActionWorkflowsResponse{
+		TotalCount: int64(len(workflows)),
+		Workflows:  make([]*api.ActionWorkflow, 0, len(workflows)),
+	}
+	for _, workflow := range workflows {
+		apiWorkflow, err := convert.ToActionWorkflow(ctx, ctx.Repo.Repository, workflow.Commit, workflow.Dir, workflow.Entry)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToActionWorkflow", err)
+			return
+		}
+		res.Workflows = append(res.Workflows, apiWorkflow)
+	}
+
+	ctx.SetTotalCountHeader(res.TotalCount)
+	ctx.JSON(http.StatusOK, res)
+}
+
+// getWorkflowByPath returns the workflow of the default branch with the file name in the path, it responds 404 if it doesn't exist
+func getWorkflowByPath(ctx *context.APIContext) *actions_service.DefaultBranchWorkflow {
+	workflow, err := actions_service.GetDefaultBranchWorkflow(ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.PathParam("workflow_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDefaultBranchWorkflow", err)
+		}
+		return nil
+	}
+	return workflow
+}
+
+// GetWorkflow get a workflow of a repository
+func GetWorkflow(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/workflows/{workflow_id} repository repoGetWorkflow
+	// ---
+	// summary: Get a workflow in the default branch of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionWorkflow"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	workflow := getWorkflowByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	apiWorkflow, err := convert.ToActionWorkflow(ctx, ctx.Repo.Repository, workflow.Commit, workflow.Dir, workflow.Entry)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToActionWorkflow", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiWorkflow)
+}
+
+// EnableWorkflow enable a workflow of a repository
+func EnableWorkflow(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/workflows/{workflow_id}/enable repository repoEnableWorkflow
+	// ---
+	// summary: Enable a workflow of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	enableOrDisableWorkflow(ctx, true)
+}
+
+// DisableWorkflow disable a workflow of a repository
+func DisableWorkflow(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/actions/workflows/{workflow_id}/disable repository repoDisableWorkflow
+	// ---
+	// summary: Disable a workflow of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	enableOrDisableWorkflow(ctx, false)
+}
+
+func enableOrDisableWorkflow(ctx *context.APIContext, isEnable bool) {
+	workflow := getWorkflowByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.EnableOrDisableWorkflow(ctx, ctx.Repo.Repository, workflow.Entry.Name(), isEnable); err != nil {
+		ctx.Error(http.StatusInternalServerError, "EnableOrDisableWorkflow", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// DispatchWorkflow run a workflow by the workflow_dispatch event
+func DispatchWorkflow(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/workflows/{workflow_id}/dispatches repository repoDispatchWorkflow
+	// ---
+	// summary: Run a workflow in the default branch of a repository by the workflow_dispatch event
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateActionWorkflowDispatch"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateActionWorkflowDispatch)
+	workflowID := ctx.PathParam("workflow_id")
+
+	_, err := actions_service.DispatchWorkflow(ctx, ctx.Doer, ctx.Repo.Repository, ctx.Repo.GitRepo, workflowID, form.Ref, func(workflowDispatch *model.WorkflowDispatch, inputs map[string]any) error {
+		for name := range form.Inputs {
+			if _, ok := workflowDispatch.Inputs[name]; !ok {
+				return util.NewInvalidArgumentErrorf("unexpected input %q", name)
+			}
+		}
+		for name, config := range workflowDispatch.Inputs {
+			value, ok := form.Inputs[name]
+			if !ok || value == "" {
+				if config.Required && config.Default == "" {
+					return util.NewInvalidArgumentErrorf("input %q is required", name)
+				}
+				value = config.Default
+			}
+			switch config.Type {
+			case "boolean":
+				if value == "" {
+					value = "false"
+				}
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return util.NewInvalidArgumentErrorf("input %q must be a boolean", name)
+				}
+				value = strconv.FormatBool(b)
+			case "choice":
+				if value != "" && !slices.Contains(config.Options, value) {
+					return util.NewInvalidArgumentErrorf("input %q must be one of %v", name, config.Options)
+				}
+			}
+			inputs[name] = value
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, actions_service.ErrWorkflowNotFound):
+			ctx.NotFound(err)
+		case errors.Is(err, util.ErrInvalidArgument), errors.Is(err, util.ErrNotExist):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "DispatchWorkflow", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListWorkflowRunsOfWorkflow list the runs of a workflow
+func ListWorkflowRunsOfWorkflow(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/workflows/{workflow_id}/runs repository repoListWorkflowRunsOfWorkflow
+	// ---
+	// summary: List the runs of a workflow
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow_id
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// - name: actor
+	//   in: query
+	//   description: username of the user who triggered the runs
+	//   type: string
+	// - name: branch
+	//   in: query
+	//   description: branch the runs ran on
+	//   type: string
+	// - name: event
+	//   in: query
+	//   description: event which triggered the runs
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: status or conclusion of the runs
+	//   type: string
+	//   enum: [queued, waiting, in_progress, completed, success, failure, cancelled, skipped]
+	// - name: head_sha
+	//   in: query
+	//   description: commit the runs ran on
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WorkflowRunList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	listWorkflowRuns(ctx, ctx.PathParam("workflow_id"))
+}
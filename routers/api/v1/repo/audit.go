@@ -0,0 +1,70 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"code.gitea.io/gitea/routers/api/v1/shared"
+	"code.gitea.io/gitea/services/context"
+)
+
+// ListAuditEvents lists the audit events of the repository
+func ListAuditEvents(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/audit repository repoListAuditEvents
+	// ---
+	// summary: List the audit events of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: action
+	//   in: query
+	//   description: the kind of the action, e.g. user_login
+	//   type: string
+	// - name: actor
+	//   in: query
+	//   description: the name of the user who did the action
+	//   type: string
+	// - name: target_type
+	//   in: query
+	//   description: the type of the object the action is applied to, e.g. repository
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show events recorded at or after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show events recorded before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AuditEventList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	shared.ListAuditEvents(ctx, ctx.Repo.Repository.OwnerID, ctx.Repo.Repository.ID)
+}
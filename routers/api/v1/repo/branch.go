@@ -21,6 +21,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/convert"
 	pull_service "code.gitea.io/gitea/services/pull"
@@ -705,9 +706,17 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		UnprotectedFilePatterns:       form.UnprotectedFilePatterns,
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
 		BlockAdminMergeOverride:       form.BlockAdminMergeOverride,
+		EnableMergeQueue:              form.EnableMergeQueue,
+		RequireCodeOwnerApproval:      form.RequireCodeOwnerApproval,
 	}
 
-	if err := git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
+	// the status checks required by the organization could not be removed
+	if err := actions_service.ApplyRequiredStatusChecks(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ApplyRequiredStatusChecks", err)
+		return
+	}
+
+	if err := repo_service.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
 		UserIDs:          whitelistUsers,
 		TeamIDs:          whitelistTeams,
 		ForcePushUserIDs: forcePushAllowlistUsers,
@@ -922,6 +931,14 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockAdminMergeOverride = *form.BlockAdminMergeOverride
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
+	if form.RequireCodeOwnerApproval != nil {
+		protectBranch.RequireCodeOwnerApproval = *form.RequireCodeOwnerApproval
+	}
+
 	var whitelistUsers, forcePushAllowlistUsers, mergeWhitelistUsers, approvalsWhitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = user_model.GetUserIDsByNames(ctx, form.PushWhitelistUsernames, false)
@@ -1032,7 +1049,13 @@ func EditBranchProtection(ctx *context.APIContext) {
 		}
 	}
 
-	err = git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
+	// the status checks required by the organization could not be removed
+	if err := actions_service.ApplyRequiredStatusChecks(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ApplyRequiredStatusChecks", err)
+		return
+	}
+
+	err = repo_service.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
 		UserIDs:          whitelistUsers,
 		TeamIDs:          whitelistTeams,
 		ForcePushUserIDs: forcePushAllowlistUsers,
@@ -1128,6 +1151,8 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 
@@ -1143,7 +1168,15 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
-	if err := git_model.DeleteProtectedBranch(ctx, ctx.Repo.Repository, bp.ID); err != nil {
+	if hasRequired, err := actions_service.HasRequiredStatusChecks(ctx, repo, bp); err != nil {
+		ctx.Error(http.StatusInternalServerError, "HasRequiredStatusChecks", err)
+		return
+	} else if hasRequired {
+		ctx.Error(http.StatusForbidden, "", "the branch protection requires status checks of the organization")
+		return
+	}
+
+	if err := repo_service.DeleteProtectedBranch(ctx, ctx.Repo.Repository, bp); err != nil {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
@@ -0,0 +1,291 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListDeployments list the deployments of a repository
+func ListDeployments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deployments repository repoListDeployments
+	// ---
+	// summary: List a repository's deployments
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: environment
+	//   in: query
+	//   description: name of the environment the deployments deploy to
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: status of the deployments
+	//   type: string
+	//   enum: [waiting, queued, in_progress, success, failure, cancelled, rejected]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeploymentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := actions_model.FindDeploymentsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		RepoID:      ctx.Repo.Repository.ID,
+	}
+	if name := ctx.FormString("environment"); name != "" {
+		env, err := actions_model.GetEnvironmentByName(ctx, ctx.Repo.Repository.ID, name)
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.SetTotalCountHeader(0)
+			ctx.JSON(http.StatusOK, []*api.Deployment{})
+			return
+		} else if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetEnvironmentByName", err)
+			return
+		}
+		opts.EnvironmentID = env.ID
+	}
+	if s := ctx.FormString("status"); s != "" {
+		status, ok := actions_model.DeploymentStatusFromString(s)
+		if !ok || status == actions_model.DeploymentStatusUnknown {
+			ctx.Error(http.StatusUnprocessableEntity, "DeploymentStatusFromString", util.NewInvalidArgumentErrorf("invalid status %q", s))
+			return
+		}
+		opts.Status = []actions_model.DeploymentStatus{status}
+	}
+
+	deployments, count, err := db.FindAndCount[actions_model.ActionDeployment](ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDeployments", err)
+		return
+	}
+	if err := actions_model.DeploymentList(deployments).LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+
+	apiDeployments := make([]*api.Deployment, len(deployments))
+	for i, d := range deployments {
+		apiDeployments[i] = convert.ToDeployment(ctx, d, ctx.Doer)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiDeployments)
+}
+
+// GetDeployment get a deployment of a repository
+func GetDeployment(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deployments/{id} repository repoGetDeployment
+	// ---
+	// summary: Get a deployment of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deployment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Deployment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d, err := actions_model.GetDeploymentByID(ctx, ctx.PathParamInt64("id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDeploymentByID", err)
+		}
+		return
+	}
+	if d.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+	if err := d.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToDeployment(ctx, d, ctx.Doer))
+}
+
+// getRunByPath returns the workflow run of the repository with the id in the path, it responds 404 if it doesn't exist
+func getRunByPath(ctx *context.APIContext) *actions_model.ActionRun {
+	run, err := actions_model.GetRunByID(ctx, ctx.PathParamInt64("run"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByID", err)
+		}
+		return nil
+	}
+	if run.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return run
+}
+
+// ListPendingDeployments list the deployments of a workflow run waiting for the reviews
+func ListPendingDeployments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{run}/pending_deployments repository repoListPendingDeployments
+	// ---
+	// summary: List the deployments of a workflow run waiting for the reviews
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PendingDeploymentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pending, err := actions_service.GetPendingDeployments(ctx, run)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPendingDeployments", err)
+		return
+	}
+
+	ret := make([]*api.PendingDeployment, len(pending))
+	for i, d := range pending {
+		env, err := convert.ToEnvironment(ctx, d.Environment, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToEnvironment", err)
+			return
+		}
+		ret[i] = &api.PendingDeployment{
+			Environment:           env,
+			CurrentUserCanApprove: ctx.Doer != nil && d.Environment.IsReviewer(ctx.Doer.ID),
+		}
+	}
+	ctx.JSON(http.StatusOK, ret)
+}
+
+// ReviewPendingDeployments approve or reject the pending deployments of a workflow run
+func ReviewPendingDeployments(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{run}/pending_deployments repository repoReviewPendingDeployments
+	// ---
+	// summary: Approve or reject the pending deployments of a workflow run
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: run
+	//   in: path
+	//   description: id of the workflow run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/ReviewPendingDeploymentsOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	run := getRunByPath(ctx)
+	if ctx.Written() {
+		return
+	}
+	form := web.GetForm(ctx).(*api.ReviewPendingDeploymentsOption)
+
+	if err := actions_service.ReviewPendingDeployments(ctx, ctx.Doer, run, form.EnvironmentIDs, form.State == "approved", form.Comment); err != nil {
+		switch {
+		case errors.Is(err, util.ErrPermissionDenied):
+			ctx.Error(http.StatusForbidden, "ReviewPendingDeployments", err)
+		case errors.Is(err, util.ErrNotExist):
+			ctx.Error(http.StatusUnprocessableEntity, "ReviewPendingDeployments", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "ReviewPendingDeployments", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
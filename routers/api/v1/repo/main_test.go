@@ -6,6 +6,7 @@ package repo
 import (
 	"testing"
 
+	_ "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
 	webhook_service "code.gitea.io/gitea/services/webhook"
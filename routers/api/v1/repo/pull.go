@@ -198,7 +198,35 @@ func GetPullRequest(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "LoadHeadRepo", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, convert.ToAPIPullRequest(ctx, pr, ctx.Doer))
+	apiPR := convert.ToAPIPullRequest(ctx, pr, ctx.Doer)
+	if apiPR.UnapprovedCodeOwners, err = getUnapprovedCodeOwners(ctx, pr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUnapprovedCodeOwnersRules", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiPR)
+}
+
+// getUnapprovedCodeOwners returns the CODEOWNERS rules blocking the merge of an open pull request
+func getUnapprovedCodeOwners(ctx *context.APIContext, pr *issues_model.PullRequest) ([]*api.PullRequestCodeOwnersRule, error) {
+	if err := pr.LoadIssue(ctx); err != nil {
+		return nil, err
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		return nil, nil
+	}
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := pull_service.GetUnapprovedCodeOwnersRules(ctx, pb, pr)
+	if err != nil {
+		return nil, err
+	}
+	apiRules := make([]*api.PullRequestCodeOwnersRule, 0, len(rules))
+	for _, rule := range rules {
+		apiRules = append(apiRules, &api.PullRequestCodeOwnersRule{Pattern: rule.Pattern, Owners: rule.Owners})
+	}
+	return apiRules, nil
 }
 
 // GetPullRequest returns a single PR based on index
@@ -283,7 +311,12 @@ func GetPullRequestByBaseHead(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "LoadHeadRepo", err)
 		return
 	}
-	ctx.JSON(http.StatusOK, convert.ToAPIPullRequest(ctx, pr, ctx.Doer))
+	apiPR := convert.ToAPIPullRequest(ctx, pr, ctx.Doer)
+	if apiPR.UnapprovedCodeOwners, err = getUnapprovedCodeOwners(ctx, pr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUnapprovedCodeOwnersRules", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiPR)
 }
 
 // DownloadPullDiffOrPatch render a pull's raw diff or patch
@@ -1003,6 +1036,29 @@ func MergePullRequest(ctx *context.APIContext) {
 		}
 	}
 
+	if !form.ForceMerge {
+		if enabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsMergeQueueEnabled", err)
+			return
+		} else if enabled {
+			if err := pull_service.AddToMergeQueue(ctx, pr, ctx.Doer, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+				if models.IsErrInvalidMergeStyle(err) {
+					ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
+				} else if models.IsErrSHADoesNotMatch(err) {
+					ctx.Error(http.StatusConflict, "AddToMergeQueue", "head out of date")
+				} else if pull_model.IsErrAlreadyInMergeQueue(err) {
+					ctx.Error(http.StatusConflict, "AddToMergeQueue", err)
+				} else {
+					ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+				}
+				return
+			}
+			// the pull request will be merged by the merge queue
+			ctx.Status(http.StatusCreated)
+			return
+		}
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message, false); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
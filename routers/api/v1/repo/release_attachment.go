@@ -4,6 +4,7 @@
 package repo
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -191,6 +192,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//     "$ref": "#/responses/error"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
+	//   "413":
+	//     "$ref": "#/responses/quotaExceeded"
 
 	// Check if attachments are enabled
 	if !setting.Attachment.Enabled {
@@ -245,6 +248,10 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 			ctx.Error(http.StatusBadRequest, "DetectContentType", err)
 			return
 		}
+		if errors.Is(err, attachment_service.ErrQuotaExceeded) {
+			ctx.Error(http.StatusRequestEntityTooLarge, "NewAttachment", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
 		return
 	}
@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package shared
+
+import (
+	"net/http"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+)
+
+// ListAuditEvents lists the audit events of the owner and the repository, both are 0 for all the events
+func ListAuditEvents(ctx *context.APIContext, ownerID, repoID int64) {
+	before, since, err := context.GetQueryBeforeSince(ctx.Base)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	events, total, err := db.FindAndCount[audit_model.Event](ctx, audit_model.FindEventsOptions{
+		ListOptions: listOptions,
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Action:      audit_model.Action(ctx.FormTrim("action")),
+		ActorName:   ctx.FormTrim("actor"),
+		TargetType:  audit_model.TargetType(ctx.FormTrim("target_type")),
+		Since:       timeutil.TimeStamp(since),
+		Before:      timeutil.TimeStamp(before),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindAuditEvents", err)
+		return
+	}
+
+	apiEvents := make([]*api.AuditEvent, 0, len(events))
+	for _, e := range events {
+		apiEvents = append(apiEvents, convert.ToAuditEvent(e))
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, &apiEvents)
+}
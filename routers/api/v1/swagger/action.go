@@ -32,3 +32,94 @@ type swaggerResponseVariableList struct {
 	// in:body
 	Body []api.ActionVariable `json:"body"`
 }
+
+// Environment
+// swagger:response Environment
+type swaggerResponseEnvironment struct {
+	// in:body
+	Body api.Environment `json:"body"`
+}
+
+// EnvironmentList
+// swagger:response EnvironmentList
+type swaggerResponseEnvironmentList struct {
+	// in:body
+	Body []api.Environment `json:"body"`
+}
+
+// Deployment
+// swagger:response Deployment
+type swaggerResponseDeployment struct {
+	// in:body
+	Body api.Deployment `json:"body"`
+}
+
+// DeploymentList
+// swagger:response DeploymentList
+type swaggerResponseDeploymentList struct {
+	// in:body
+	Body []api.Deployment `json:"body"`
+}
+
+// PendingDeploymentList
+// swagger:response PendingDeploymentList
+type swaggerResponsePendingDeploymentList struct {
+	// in:body
+	Body []api.PendingDeployment `json:"body"`
+}
+
+// WorkflowRun
+// swagger:response WorkflowRun
+type swaggerResponseWorkflowRun struct {
+	// in:body
+	Body api.ActionWorkflowRun `json:"body"`
+}
+
+// WorkflowRunList
+// swagger:response WorkflowRunList
+type swaggerResponseWorkflowRunList struct {
+	// in:body
+	Body api.ActionWorkflowRunsResponse `json:"body"`
+}
+
+// WorkflowJob
+// swagger:response WorkflowJob
+type swaggerResponseWorkflowJob struct {
+	// in:body
+	Body api.ActionWorkflowJob `json:"body"`
+}
+
+// WorkflowJobList
+// swagger:response WorkflowJobList
+type swaggerResponseWorkflowJobList struct {
+	// in:body
+	Body api.ActionWorkflowJobsResponse `json:"body"`
+}
+
+// ActionWorkflow
+// swagger:response ActionWorkflow
+type swaggerResponseActionWorkflow struct {
+	// in:body
+	Body api.ActionWorkflow `json:"body"`
+}
+
+// ActionWorkflowList
+// swagger:response ActionWorkflowList
+type swaggerResponseActionWorkflowList struct {
+	// in:body
+	Body api.ActionWorkflowsResponse `json:"body"`
+}
+
+// Artifact
+// swagger:response Artifact
+type swaggerResponseArtifact struct {
+	// in:body
+	Body api.ActionArtifact `json:"body"`
+}
+
+// ArtifactList
+// swagger:response ArtifactList
+type swaggerResponseArtifactList struct {
+	// in:body
+	Body api.ActionArtifactsResponse `json:"body"`
+}
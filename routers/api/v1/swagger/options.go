@@ -210,4 +210,22 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	UpdateVariableOption api.UpdateVariableOption
+
+	// in:body
+	CreateOrUpdateEnvironmentOption api.CreateOrUpdateEnvironmentOption
+
+	// in:body
+	ReviewPendingDeploymentsOption api.ReviewPendingDeploymentsOption
+
+	// in:body
+	CreateActionWorkflowDispatch api.CreateActionWorkflowDispatch
+
+	// in:body
+	CreateQuotaRuleOption api.CreateQuotaRuleOption
+
+	// in:body
+	EditQuotaRuleOption api.EditQuotaRuleOption
+
+	// in:body
+	CreateQuotaGroupOption api.CreateQuotaGroupOption
 }
@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// QuotaInfo
+// swagger:response QuotaInfo
+type swaggerResponseQuotaInfo struct {
+	// in:body
+	Body api.QuotaInfo `json:"body"`
+}
+
+// QuotaRule
+// swagger:response QuotaRule
+type swaggerResponseQuotaRule struct {
+	// in:body
+	Body api.QuotaRule `json:"body"`
+}
+
+// QuotaRuleList
+// swagger:response QuotaRuleList
+type swaggerResponseQuotaRuleList struct {
+	// in:body
+	Body []api.QuotaRule `json:"body"`
+}
+
+// QuotaGroup
+// swagger:response QuotaGroup
+type swaggerResponseQuotaGroup struct {
+	// in:body
+	Body api.QuotaGroup `json:"body"`
+}
+
+// QuotaGroupList
+// swagger:response QuotaGroupList
+type swaggerResponseQuotaGroupList struct {
+	// in:body
+	Body []api.QuotaGroup `json:"body"`
+}
@@ -11,13 +11,17 @@ import (
 	"strconv"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/convert"
+
+	"xorm.io/builder"
 )
 
 // ListAccessTokens list all the access tokens
@@ -128,6 +132,8 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
+	audit.Record(ctx, audit_model.ActionAccessTokenCreate, ctx.Doer, audit.OwnerScope(ctx.ContextUser.ID), audit.AccessTokenTarget(t), nil, audit.AccessTokenState(t))
+
 	ctx.JSON(http.StatusCreated, &api.AccessToken{
 		Name:           t.Name,
 		Token:          t.Token,
@@ -194,6 +200,15 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		return
 	}
 
+	t, exist, err := db.Get[auth_model.AccessToken](ctx, builder.Eq{"id": tokenID, "uid": ctx.ContextUser.ID})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetAccessToken", err)
+		return
+	} else if !exist {
+		ctx.NotFound()
+		return
+	}
+
 	if err := auth_model.DeleteAccessTokenByID(ctx, tokenID, ctx.ContextUser.ID); err != nil {
 		if auth_model.IsErrAccessTokenNotExist(err) {
 			ctx.NotFound()
@@ -202,6 +217,7 @@ func DeleteAccessToken(ctx *context.APIContext) {
 		}
 		return
 	}
+	audit.Record(ctx, audit_model.ActionAccessTokenDelete, ctx.Doer, audit.OwnerScope(ctx.ContextUser.ID), audit.AccessTokenTarget(t), audit.AccessTokenState(t), nil)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -233,6 +249,7 @@ func CreateOauth2Application(ctx *context.APIContext) {
 		RedirectURIs:               data.RedirectURIs,
 		ConfidentialClient:         data.ConfidentialClient,
 		SkipSecondaryAuthorization: data.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           data.EnableDeviceFlow,
 	})
 	if err != nil {
 		ctx.Error(http.StatusBadRequest, "", "error creating oauth2 application")
@@ -393,6 +410,7 @@ func UpdateOauth2Application(ctx *context.APIContext) {
 		RedirectURIs:               data.RedirectURIs,
 		ConfidentialClient:         data.ConfidentialClient,
 		SkipSecondaryAuthorization: data.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           data.EnableDeviceFlow,
 	})
 	if err != nil {
 		if auth_model.IsErrOauthClientIDInvalid(err) || auth_model.IsErrOAuthApplicationNotFound(err) {
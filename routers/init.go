@@ -30,6 +30,7 @@ import (
 	"code.gitea.io/gitea/modules/web/routing"
 	actions_router "code.gitea.io/gitea/routers/api/actions"
 	packages_router "code.gitea.io/gitea/routers/api/packages"
+	scim_router "code.gitea.io/gitea/routers/api/scim"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/private"
@@ -44,6 +45,7 @@ import (
 	indexer_service "code.gitea.io/gitea/services/indexer"
 	"code.gitea.io/gitea/services/mailer"
 	mailer_incoming "code.gitea.io/gitea/services/mailer/incoming"
+	"code.gitea.io/gitea/services/maintenance"
 	markup_service "code.gitea.io/gitea/services/markup"
 	repo_migrations "code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
@@ -52,6 +54,7 @@ import (
 	release_service "code.gitea.io/gitea/services/release"
 	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/repository/archiver"
+	"code.gitea.io/gitea/services/repository/housekeeping"
 	"code.gitea.io/gitea/services/task"
 	"code.gitea.io/gitea/services/uinotification"
 	"code.gitea.io/gitea/services/webhook"
@@ -144,6 +147,7 @@ func InitWebInstalled(ctx context.Context) {
 	mustInitCtx(ctx, common.InitDBEngine)
 	log.Info("ORM engine initialization successful!")
 	mustInit(system.Init)
+	mustInit(maintenance.Init)
 	mustInitCtx(ctx, oauth2.Init)
 	mustInitCtx(ctx, oauth2_provider.Init)
 	mustInit(release_service.Init)
@@ -151,6 +155,7 @@ func InitWebInstalled(ctx context.Context) {
 	mustInitCtx(ctx, models.Init)
 	mustInitCtx(ctx, authmodel.Init)
 	mustInitCtx(ctx, repo_service.Init)
+	mustInitCtx(ctx, housekeeping.Init)
 
 	// Booting long running goroutines.
 	mustInit(indexer_service.Init)
@@ -199,6 +204,11 @@ func NormalRoutes() *web.Router {
 		r.Mount("/v2", packages_router.ContainerRoutes())
 	}
 
+	if setting.SCIM.Enabled {
+		// The SCIM endpoints are provided for the identity providers to provision the users and the teams
+		r.Mount("/scim/v2", scim_router.Routes())
+	}
+
 	if setting.Actions.Enabled {
 		prefix := "/api/actions"
 		r.Mount(prefix, actions_router.Routes(prefix))
@@ -210,6 +220,8 @@ func NormalRoutes() *web.Router {
 		r.Mount(prefix, actions_router.ArtifactsRoutes(prefix))
 		prefix = actions_router.ArtifactV4RouteBase
 		r.Mount(prefix, actions_router.ArtifactsV4Routes(prefix))
+		prefix = actions_router.CacheRouteBase
+		r.Mount(prefix, actions_router.CacheRoutes(prefix))
 	}
 
 	r.NotFound(func(w http.ResponseWriter, req *http.Request) {
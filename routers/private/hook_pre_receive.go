@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
@@ -14,6 +16,7 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	quota_model "code.gitea.io/gitea/models/quota"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
@@ -21,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/web"
 	gitea_context "code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/maintenance"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
 
@@ -111,6 +115,16 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		opts:           opts,
 	}
 
+	preReceiveMaintenance(ourCtx)
+	if ctx.Written() {
+		return
+	}
+
+	preReceiveQuota(ourCtx)
+	if ctx.Written() {
+		return
+	}
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
@@ -135,6 +149,42 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// preReceiveMaintenance rejects all pushes while the instance is in read-only maintenance mode
+func preReceiveMaintenance(ctx *preReceiveContext) {
+	if !maintenance.IsEnabled(ctx) {
+		return
+	}
+	log.Warn("Forbidden: the instance is in maintenance mode, rejecting the push to %-v", ctx.Repo.Repository)
+	ctx.JSON(http.StatusServiceUnavailable, private.Response{
+		UserMsg: maintenance.Message(ctx),
+	})
+}
+
+// preReceiveQuota rejects the pushes adding data to the repository once its owner has used up the git quota,
+// deleting refs is always allowed so the owner could free the space.
+func preReceiveQuota(ctx *preReceiveContext) {
+	emptyObjectID := ctx.Repo.GetObjectFormat().EmptyObjectID().String()
+	if !slices.ContainsFunc(ctx.opts.NewCommitIDs, func(id string) bool { return id != emptyObjectID }) {
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	acceptable, err := quota_model.EvaluateForUser(ctx, repo.OwnerID, quota_model.LimitSubjectSizeGit)
+	if err != nil {
+		log.Error("Unable to evaluate the quota of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return
+	}
+	if !acceptable {
+		log.Warn("Forbidden: the owner of %-v has exceeded the storage quota", repo)
+		ctx.JSON(http.StatusRequestEntityTooLarge, private.Response{
+			UserMsg: "the owner of the repository has exceeded the storage quota",
+		})
+	}
+}
+
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
 	branchName := refFullName.BranchName()
 	ctx.branchName = branchName
@@ -155,6 +205,15 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, r
 		return
 	}
 
+	// the merge group branches are managed by the merge queue only
+	if strings.HasPrefix(branchName, pull_service.MergeQueueBranchPrefix) {
+		log.Warn("Forbidden: Branch: %s in %-v is a merge queue branch and cannot be pushed to", branchName, repo)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			UserMsg: fmt.Sprintf("branch %s is managed by the merge queue and cannot be pushed to", branchName),
+		})
+		return
+	}
+
 	protectBranch, err := git_model.GetFirstMatchProtectedBranchRule(ctx, repo.ID, branchName)
 	if err != nil {
 		log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
@@ -380,6 +380,11 @@ func ServCommand(ctx *context.PrivateContext) {
 			return
 		}
 	}
+
+	if repo != nil {
+		results.UploadPackConfig = git.UploadPackFilterConfig(repo.AllowedPartialCloneFilters())
+	}
+
 	log.Debug("Serv Results:\nIsWiki: %t\nDeployKeyID: %d\nKeyID: %d\tKeyName: %s\nUserName: %s\nUserID: %d\nOwnerName: %s\nRepoName: %s\nRepoID: %d",
 		results.IsWiki,
 		results.DeployKeyID,
@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/services/auth/source/ldap"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	pam_service "code.gitea.io/gitea/services/auth/source/pam"
+	"code.gitea.io/gitea/services/auth/source/saml"
 	"code.gitea.io/gitea/services/auth/source/smtp"
 	"code.gitea.io/gitea/services/auth/source/sspi"
 	"code.gitea.io/gitea/services/context"
@@ -71,6 +72,7 @@ var (
 			{auth.SMTP.String(), auth.SMTP},
 			{auth.OAuth2.String(), auth.OAuth2},
 			{auth.SSPI.String(), auth.SSPI},
+			{auth.SAML.String(), auth.SAML},
 		}
 		if pam.Supported {
 			items = append(items, dropdownItem{auth.Names[auth.PAM], auth.PAM})
@@ -78,6 +80,8 @@ var (
 		return items
 	}()
 
+	samlNameIDFormats = []string{"persistent", "email", "unspecified", "transient"}
+
 	securityProtocols = []dropdownItem{
 		{ldap.SecurityProtocolNames[ldap.SecurityProtocolUnencrypted], ldap.SecurityProtocolUnencrypted},
 		{ldap.SecurityProtocolNames[ldap.SecurityProtocolLDAPS], ldap.SecurityProtocolLDAPS},
@@ -108,6 +112,10 @@ func NewAuthSource(ctx *context.Context) {
 	ctx.Data["SSPISeparatorReplacement"] = "_"
 	ctx.Data["SSPIDefaultLanguage"] = ""
 
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
+	ctx.Data["saml_name_id_format"] = "persistent"
+	ctx.Data["saml_auto_create_users"] = true
+
 	// only the first as default
 	ctx.Data["oauth2_provider"] = oauth2providers[0].Name()
 
@@ -231,6 +239,41 @@ func parseSSPIConfig(ctx *context.Context, form forms.AuthenticationForm) (*sspi
 	}, nil
 }
 
+// parseSAMLConfig parses the SAML config from the form, the service provider key pair is kept from
+// the existing config if it's left empty, or generated if there isn't an existing one.
+func parseSAMLConfig(form forms.AuthenticationForm, existing *saml.Source) (*saml.Source, error) {
+	cert, key := form.SAMLServiceProviderCertificate, form.SAMLServiceProviderPrivateKey
+	if util.IsEmptyString(key) {
+		if existing != nil {
+			cert, key = existing.ServiceProviderCertificate, existing.ServiceProviderPrivateKey
+		} else {
+			var err error
+			if cert, key, err = saml.GenerateServiceProviderKeyPair(setting.Domain); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &saml.Source{
+		IdentityProviderMetadataURL: strings.TrimSpace(form.SAMLIdentityProviderMetadataURL),
+		IdentityProviderMetadata:    strings.TrimSpace(form.SAMLIdentityProviderMetadata),
+		ServiceProviderCertificate:  cert,
+		ServiceProviderPrivateKey:   key,
+		SignRequests:                form.SAMLSignRequests,
+		NameIDFormat:                form.SAMLNameIDFormat,
+		AttributeUsername:           form.SAMLAttributeUsername,
+		AttributeEmail:              form.SAMLAttributeEmail,
+		AttributeFullName:           form.SAMLAttributeFullName,
+		AttributeGroups:             form.SAMLAttributeGroups,
+		AutoCreateUsers:             form.SAMLAutoCreateUsers,
+		AdminGroup:                  form.SAMLAdminGroup,
+		RestrictedGroup:             form.SAMLRestrictedGroup,
+		GroupTeamMap:                form.SAMLGroupTeamMap,
+		GroupTeamMapRemoval:         form.SAMLGroupTeamMapRemoval,
+		SkipLocalTwoFA:              form.SkipLocalTwoFA,
+	}, nil
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*forms.AuthenticationForm)
@@ -250,6 +293,7 @@ func NewAuthSourcePost(ctx *context.Context) {
 	ctx.Data["SSPIStripDomainNames"] = true
 	ctx.Data["SSPISeparatorReplacement"] = "_"
 	ctx.Data["SSPIDefaultLanguage"] = ""
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	hasTLS := false
 	var config convert.Conversion
@@ -290,6 +334,13 @@ func NewAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(ctx.Tr("admin.auths.login_source_of_type_exist"), tplAuthNew, form)
 			return
 		}
+	case auth.SAML:
+		var err error
+		config, err = parseSAMLConfig(form, nil)
+		if err != nil {
+			ctx.ServerError("parseSAMLConfig", err)
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
@@ -315,6 +366,8 @@ func NewAuthSourcePost(ctx *context.Context) {
 			ctx.Data["Err_DiscoveryURL"] = true
 			unwrapped := err.(oauth2.ErrOpenIDConnectInitialize).Unwrap()
 			ctx.RenderWithErr(ctx.Tr("admin.auths.unable_to_initialize_openid", unwrapped), tplAuthNew, form)
+		} else if saml.IsErrInvalidConfiguration(err) {
+			ctx.RenderWithErr(ctx.Tr("admin.auths.invalid_saml_configuration", err.(saml.ErrInvalidConfiguration).Unwrap()), tplAuthNew, form)
 		} else {
 			ctx.ServerError("auth.CreateSource", err)
 		}
@@ -344,6 +397,11 @@ func EditAuthSource(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
+	if source.IsSAML() {
+		ctx.Data["SAMLMetadataURL"] = saml.MetadataURL(source.Name)
+		ctx.Data["SAMLAssertionConsumerServiceURL"] = saml.AssertionConsumerServiceURL(source.Name)
+	}
 
 	if source.IsOAuth2() {
 		type Named interface {
@@ -378,6 +436,7 @@ func EditAuthSourcePost(ctx *context.Context) {
 	}
 	ctx.Data["Source"] = source
 	ctx.Data["HasTLS"] = source.HasTLS()
+	ctx.Data["SAMLNameIDFormats"] = samlNameIDFormats
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplAuthEdit)
@@ -412,6 +471,13 @@ func EditAuthSourcePost(ctx *context.Context) {
 			ctx.RenderWithErr(err.Error(), tplAuthEdit, form)
 			return
 		}
+	case auth.SAML:
+		existing, _ := source.Cfg.(*saml.Source)
+		config, err = parseSAMLConfig(form, existing)
+		if err != nil {
+			ctx.ServerError("parseSAMLConfig", err)
+			return
+		}
 	default:
 		ctx.Error(http.StatusBadRequest)
 		return
@@ -429,6 +495,8 @@ func EditAuthSourcePost(ctx *context.Context) {
 			ctx.Flash.Error(err.Error(), true)
 			ctx.Data["Err_DiscoveryURL"] = true
 			ctx.HTML(http.StatusOK, tplAuthEdit)
+		} else if saml.IsErrInvalidConfiguration(err) {
+			ctx.RenderWithErr(ctx.Tr("admin.auths.invalid_saml_configuration", err.(saml.ErrInvalidConfiguration).Unwrap()), tplAuthEdit, form)
 		} else {
 			ctx.ServerError("UpdateSource", err)
 		}
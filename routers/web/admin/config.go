@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/mailer"
+	"code.gitea.io/gitea/services/maintenance"
 
 	"gitea.com/go-chi/session"
 )
@@ -230,10 +231,19 @@ func ChangeConfig(ctx *context.Context) {
 		}
 		return string(b), nil
 	}
+	marshalString := func(v string) (string, error) {
+		b, err := json.Marshal(strings.TrimSpace(v))
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
 	marshallers := map[string]func(string) (string, error){
 		cfg.Picture.DisableGravatar.DynKey():       marshalBool,
 		cfg.Picture.EnableFederatedAvatar.DynKey(): marshalBool,
 		cfg.Repository.OpenWithEditorApps.DynKey(): marshalOpenWithApps,
+		cfg.Maintenance.Enabled.DynKey():           marshalBool,
+		cfg.Maintenance.Message.DynKey():           marshalString,
 	}
 	marshaller, hasMarshaller := marshallers[key]
 	if !hasMarshaller {
@@ -251,5 +261,8 @@ func ChangeConfig(ctx *context.Context) {
 	}
 
 	config.GetDynGetter().InvalidateCache()
+	if key == cfg.Maintenance.Enabled.DynKey() {
+		maintenance.SyncQueues(ctx)
+	}
 	ctx.JSONOK()
 }
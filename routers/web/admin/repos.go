@@ -14,16 +14,19 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/optional"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/web/explore"
 	"code.gitea.io/gitea/services/context"
 	repo_service "code.gitea.io/gitea/services/repository"
+	"code.gitea.io/gitea/services/repository/housekeeping"
 )
 
 const (
 	tplRepos          base.TplName = "admin/repo/list"
 	tplUnadoptedRepos base.TplName = "admin/repo/unadopted"
+	tplHousekeeping   base.TplName = "admin/repo/housekeeping"
 )
 
 // Repos show all the repositories
@@ -162,3 +165,73 @@ func AdoptOrDeleteRepository(ctx *context.Context) {
 	}
 	ctx.Redirect(setting.AppSubURL + "/-/admin/repos/unadopted?search=true&q=" + url.QueryEscape(q) + "&page=" + url.QueryEscape(page))
 }
+
+// RepoHousekeeping lists the housekeeping states of the repositories
+func RepoHousekeeping(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repos.housekeeping")
+	ctx.Data["PageIsAdminRepositories"] = true
+	ctx.Data["HousekeepingEnabled"] = setting.Repository.Housekeeping.Enabled
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	opts := repo_model.FindRepoHousekeepingOptions{
+		ListOptions: db.ListOptions{
+			PageSize: setting.UI.Admin.RepoPagingNum,
+			Page:     page,
+		},
+	}
+
+	status := ctx.FormString("status")
+	for _, s := range repo_model.HousekeepingStatuses {
+		if s.String() == status {
+			opts.Status = optional.Some(s)
+		}
+	}
+	if !opts.Status.Has() {
+		status = ""
+	}
+
+	housekeepings, count, err := db.FindAndCount[repo_model.RepoHousekeeping](ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindAndCount", err)
+		return
+	}
+	if err := repo_model.RepoHousekeepingList(housekeepings).LoadRepos(ctx); err != nil {
+		ctx.ServerError("LoadRepos", err)
+		return
+	}
+
+	ctx.Data["Housekeepings"] = housekeepings
+	ctx.Data["Total"] = count
+	ctx.Data["Status"] = status
+	ctx.Data["Statuses"] = repo_model.HousekeepingStatuses
+
+	pager := context.NewPagination(int(count), opts.PageSize, opts.Page, 5)
+	pager.AddParamString("status", status)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplHousekeeping)
+}
+
+// RunRepoHousekeeping queues the housekeeping of a repository
+func RunRepoHousekeeping(ctx *context.Context) {
+	repo, err := repo_model.GetRepositoryByID(ctx, ctx.FormInt64("id"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return
+	}
+
+	if err := housekeeping.AddRepoToQueue(ctx, repo); err != nil {
+		ctx.ServerError("AddRepoToQueue", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.repos.housekeeping.queued", repo.FullName()))
+	ctx.Redirect(setting.AppSubURL + "/-/admin/repos/housekeeping?page=" + url.QueryEscape(ctx.FormString("page")) + "&status=" + url.QueryEscape(ctx.FormString("status")))
+}
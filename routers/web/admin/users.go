@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	org_model "code.gitea.io/gitea/models/organization"
@@ -26,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
@@ -208,6 +210,7 @@ func NewUserPost(ctx *context.Context) {
 	}
 
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit.Record(ctx, audit_model.ActionUserCreate, ctx.Doer, audit.OwnerScope(u.ID), audit.UserTarget(u), nil, audit.UserState(u))
 
 	// Send email notification.
 	if form.SendNotify {
@@ -346,6 +349,7 @@ func EditUserPost(ctx *context.Context) {
 		ctx.HTML(http.StatusOK, tplUserEdit)
 		return
 	}
+	before := audit.UserState(u)
 
 	if form.UserName != "" {
 		if err := user_service.RenameUser(ctx, u, form.UserName); err != nil {
@@ -480,6 +484,15 @@ func EditUserPost(ctx *context.Context) {
 		}
 	}
 
+	after := audit.UserState(u)
+	if form.Password != "" {
+		after["password_changed"] = true
+	}
+	if form.Reset2FA {
+		after["two_factor_reset"] = true
+	}
+	audit.Record(ctx, audit_model.ActionUserUpdate, ctx.Doer, audit.OwnerScope(u.ID), audit.UserTarget(u), before, after)
+
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/-/admin/users/" + url.PathEscape(ctx.PathParam(":userid")))
 }
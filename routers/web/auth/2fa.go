@@ -91,6 +91,7 @@ func TwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	recordSignInFailure(ctx, id, "", "totp")
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_passcode_incorrect"), tplTwofa, forms.TwoFactorAuthForm{})
 }
 
@@ -159,5 +160,6 @@ func TwoFactorScratchPost(ctx *context.Context) {
 		return
 	}
 
+	recordSignInFailure(ctx, id, "", "scratch_token")
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, forms.TwoFactorScratchAuthForm{})
 }
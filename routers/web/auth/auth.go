@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -26,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/audit"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/context"
@@ -168,6 +170,7 @@ func prepareSignInPageData(ctx *context.Context) {
 	ctx.Data["PageIsSignIn"] = true
 	ctx.Data["PageIsLogin"] = true
 	ctx.Data["EnableSSPI"] = auth.IsSSPIEnabled(ctx)
+	ctx.Data["SAMLSources"], _ = db.Find[auth.Source](ctx, auth.FindSourcesOptions{IsActive: optional.Some(true), LoginType: auth.SAML})
 	ctx.Data["EnablePasswordSignInForm"] = setting.Service.EnablePasswordSignInForm
 
 	if setting.Service.EnableCaptcha && setting.Service.RequireCaptchaForLogin {
@@ -215,14 +218,18 @@ func SignInPost(ctx *context.Context) {
 		if errors.Is(err, util.ErrNotExist) || errors.Is(err, util.ErrInvalidArgument) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
 			log.Warn("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			recordSignInFailure(ctx, 0, form.UserName, "password")
 		} else if user_model.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 			log.Warn("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			recordSignInFailure(ctx, 0, form.UserName, "password")
 		} else if user_model.IsErrUserProhibitLogin(err) {
 			log.Warn("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			recordSignInFailure(ctx, 0, form.UserName, "prohibit_login")
 			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
 			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
 		} else if user_model.IsErrUserInactive(err) {
+			recordSignInFailure(ctx, 0, form.UserName, "inactive")
 			if setting.Service.RegisterEmailConfirm {
 				ctx.Data["Title"] = ctx.Tr("auth.active_your_account")
 				ctx.HTML(http.StatusOK, TplActivate)
@@ -353,6 +360,7 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 		ctx.ServerError("UpdateUser", err)
 		return setting.AppSubURL + "/"
 	}
+	audit.Record(ctx, audit_model.ActionUserLogin, u, audit.OwnerScope(u.ID), audit.UserTarget(u), nil, nil)
 
 	if redirectTo := ctx.GetSiteCookie("redirect_to"); redirectTo != "" && httplib.IsCurrentGiteaSiteURL(ctx, redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
@@ -368,6 +376,17 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 	return setting.AppSubURL + "/"
 }
 
+// recordSignInFailure records the failed sign-in attempt in the audit log, the reason is the failed step of the sign-in
+func recordSignInFailure(ctx *context.Context, userID int64, userName, reason string) {
+	if userName == "" && userID > 0 {
+		if u, err := user_model.GetUserByID(ctx, userID); err == nil {
+			userName = u.Name
+		}
+	}
+	target := audit.Target{Type: audit_model.TargetUser, ID: userID, Name: userName}
+	audit.Record(ctx, audit_model.ActionUserLoginFailed, nil, audit.OwnerScope(userID), target, nil, map[string]any{"reason": reason})
+}
+
 // extractUserNameFromOAuth2 tries to extract a normalized username from the given OAuth2 user.
 // It returns ("", nil) if the required field doesn't exist.
 func extractUserNameFromOAuth2(gothUser *goth.User) (string, error) {
@@ -0,0 +1,272 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/oauth2_provider"
+)
+
+const tplDeviceVerification base.TplName = "user/auth/device"
+
+// deviceCodeGrantType is the grant type the devices poll the token endpoint with
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.4
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// loadDeviceFlowApplication loads the application of a device authorization grant request and authenticates the client
+func loadDeviceFlowApplication(ctx *context.Context, clientID, clientSecret string) (*auth.OAuth2Application, *oauth2_provider.AccessTokenError) {
+	app, err := auth.GetOAuth2ApplicationByClientID(ctx, clientID)
+	if err != nil {
+		return nil, &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: "cannot load client with client id: " + clientID,
+		}
+	}
+	if app.ConfidentialClient && !app.ValidateClientSecret([]byte(clientSecret)) {
+		errorDescription := "invalid client secret"
+		if clientSecret == "" {
+			errorDescription = "invalid empty client secret"
+		}
+		return nil, &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: errorDescription,
+		}
+	}
+	if !app.EnableDeviceFlow {
+		return nil, &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "the device authorization grant is not enabled for the client",
+		}
+	}
+	return app, nil
+}
+
+// DeviceAuthorizationOAuth issues a device code and a user code to a device
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.1
+func DeviceAuthorizationOAuth(ctx *context.Context) {
+	form := *web.GetForm(ctx).(*forms.DeviceAuthorizationForm)
+	if acErr := fillClientCredentials(ctx, &form.ClientID, &form.ClientSecret); acErr != nil {
+		handleAccessTokenError(ctx, *acErr)
+		return
+	}
+
+	app, acErr := loadDeviceFlowApplication(ctx, form.ClientID, form.ClientSecret)
+	if acErr != nil {
+		handleAccessTokenError(ctx, *acErr)
+		return
+	}
+
+	d, err := auth.CreateOAuth2DeviceAuthorization(ctx, app, form.Scope)
+	if err != nil {
+		log.Error("CreateOAuth2DeviceAuthorization: %v", err)
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot create device authorization",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, oauth2_provider.NewDeviceAuthorizationResponse(d))
+}
+
+// loadDeviceAuthorization loads the pending device authorization of the user code and its application,
+// it renders the verification page with an error if the code is invalid
+func loadDeviceAuthorization(ctx *context.Context, userCode string) (*auth.OAuth2DeviceAuthorization, *auth.OAuth2Application) {
+	ctx.Data["UserCode"] = userCode
+	d, err := auth.GetOAuth2DeviceAuthorizationByUserCode(ctx, userCode)
+	if err != nil {
+		ctx.ServerError("GetOAuth2DeviceAuthorizationByUserCode", err)
+		return nil, nil
+	}
+	if d == nil || d.IsExpired() || !d.IsPending() {
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceVerification, nil)
+		return nil, nil
+	}
+	app, err := auth.GetOAuth2ApplicationByID(ctx, d.ApplicationID)
+	if err != nil {
+		if auth.IsErrOAuthApplicationNotFound(err) {
+			ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceVerification, nil)
+		} else {
+			ctx.ServerError("GetOAuth2ApplicationByID", err)
+		}
+		return nil, nil
+	}
+	return d, app
+}
+
+// DeviceVerification shows the page to enter the user code of a device, and the page to approve the device once the code is entered
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.3
+func DeviceVerification(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("auth.device_title")
+	userCode := ctx.FormTrim("user_code")
+	if userCode == "" {
+		ctx.HTML(http.StatusOK, tplDeviceVerification)
+		return
+	}
+
+	d, app := loadDeviceAuthorization(ctx, userCode)
+	if ctx.Written() {
+		return
+	}
+
+	var user *user_model.User
+	if app.UID != 0 {
+		var err error
+		user, err = user_model.GetUserByID(ctx, app.UID)
+		if err != nil {
+			ctx.ServerError("GetUserByID", err)
+			return
+		}
+	}
+
+	ctx.Data["Authorization"] = d
+	ctx.Data["Application"] = app
+	ctx.Data["AdditionalScopes"] = oauth2_provider.GrantAdditionalScopes(d.Scope) != auth.AccessTokenScopeAll
+	ctx.Data["ApplicationCreatorLinkHTML"] = applicationCreatorLinkHTML(user)
+	ctx.HTML(http.StatusOK, tplDeviceVerification)
+}
+
+// DeviceVerificationPost approves or denies the device of the user code
+func DeviceVerificationPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DeviceVerificationForm)
+	ctx.Data["Title"] = ctx.Tr("auth.device_title")
+
+	d, app := loadDeviceAuthorization(ctx, form.UserCode)
+	if ctx.Written() {
+		return
+	}
+
+	if !form.Granted {
+		if err := d.Deny(ctx); err != nil {
+			if errors.Is(err, auth.ErrOAuth2DeviceAuthorizationNotPending) {
+				ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceVerification, nil)
+			} else {
+				ctx.ServerError("Deny", err)
+			}
+			return
+		}
+		ctx.Flash.Info(ctx.Tr("auth.device_denied", app.Name))
+		ctx.Redirect(setting.AppSubURL + "/login/oauth/device")
+		return
+	}
+
+	grant, err := app.GetGrantByUserID(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("GetGrantByUserID", err)
+		return
+	}
+	if grant == nil {
+		grant, err = app.CreateGrant(ctx, ctx.Doer.ID, d.Scope)
+		if err != nil {
+			ctx.ServerError("CreateGrant", err)
+			return
+		}
+	} else if grant.Scope != d.Scope {
+		ctx.Data["UserCode"] = ""
+		ctx.RenderWithErr(ctx.Tr("auth.device_grant_scope_mismatch", app.Name), tplDeviceVerification, nil)
+		return
+	}
+
+	if err := d.Approve(ctx, grant.ID); err != nil {
+		if errors.Is(err, auth.ErrOAuth2DeviceAuthorizationNotPending) {
+			ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceVerification, nil)
+		} else {
+			ctx.ServerError("Approve", err)
+		}
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("auth.device_approved", app.Name))
+	ctx.Redirect(setting.AppSubURL + "/login/oauth/device")
+}
+
+// handleDeviceCode issues the tokens to a device once the user has approved it, the device polls until then
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.4
+func handleDeviceCode(ctx *context.Context, form forms.AccessTokenForm, serverKey, clientKey oauth2_provider.JWTSigningKey) {
+	app, acErr := loadDeviceFlowApplication(ctx, form.ClientID, form.ClientSecret)
+	if acErr != nil {
+		handleAccessTokenError(ctx, *acErr)
+		return
+	}
+
+	d, err := auth.GetOAuth2DeviceAuthorizationByDeviceCode(ctx, form.DeviceCode)
+	if err != nil || d == nil || d.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid device code",
+		})
+		return
+	}
+
+	// the expired authorizations are kept until the next device authorization request deletes them,
+	// so the device gets the same error if it polls again
+	if d.IsExpired() {
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeExpiredToken,
+			ErrorDescription: "the device code has expired",
+		})
+		return
+	}
+
+	if d.Denied {
+		if err := d.Invalidate(ctx); err != nil {
+			log.Error("Unable to invalidate denied device authorization: %v", err)
+		}
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeAccessDenied,
+			ErrorDescription: "the authorization request is denied",
+		})
+		return
+	}
+
+	if d.IsPending() {
+		slowDown, err := d.Poll(ctx)
+		if err != nil {
+			log.Error("Unable to record the poll of device authorization: %v", err)
+		}
+		if slowDown {
+			handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+				ErrorCode:        oauth2_provider.AccessTokenErrorCodeSlowDown,
+				ErrorDescription: "the device polls too fast",
+			})
+			return
+		}
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeAuthorizationPending,
+			ErrorDescription: "the authorization request is still pending",
+		})
+		return
+	}
+
+	grant, err := auth.GetOAuth2GrantByID(ctx, d.GrantID)
+	if err != nil || grant == nil || grant.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "grant does not exist",
+		})
+		return
+	}
+	// remove the device authorization from database to deny duplicate usage
+	if err := d.Invalidate(ctx); err != nil {
+		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+	resp, tokenErr := oauth2_provider.NewAccessTokenResponse(ctx, grant, serverKey, clientKey)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -324,11 +324,7 @@ func AuthorizeOAuth(ctx *context.Context) {
 	ctx.Data["State"] = form.State
 	ctx.Data["Scope"] = form.Scope
 	ctx.Data["Nonce"] = form.Nonce
-	if user != nil {
-		ctx.Data["ApplicationCreatorLinkHTML"] = template.HTML(fmt.Sprintf(`<a href="%s">@%s</a>`, html.EscapeString(user.HomeLink()), html.EscapeString(user.Name)))
-	} else {
-		ctx.Data["ApplicationCreatorLinkHTML"] = template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(setting.AppSubURL+"/"), html.EscapeString(setting.AppName)))
-	}
+	ctx.Data["ApplicationCreatorLinkHTML"] = applicationCreatorLinkHTML(user)
 	ctx.Data["ApplicationRedirectDomainHTML"] = template.HTML("<strong>" + html.EscapeString(form.RedirectURI) + "</strong>")
 	// TODO document SESSION <=> FORM
 	err = ctx.Session.Set("client_id", app.ClientID)
@@ -357,6 +353,14 @@ func AuthorizeOAuth(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplGrantAccess)
 }
 
+// applicationCreatorLinkHTML returns the link to the creator of an application, the instance-wide applications have no creator
+func applicationCreatorLinkHTML(user *user_model.User) template.HTML {
+	if user != nil {
+		return template.HTML(fmt.Sprintf(`<a href="%s">@%s</a>`, html.EscapeString(user.HomeLink()), html.EscapeString(user.Name)))
+	}
+	return template.HTML(fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(setting.AppSubURL+"/"), html.EscapeString(setting.AppName)))
+}
+
 // GrantApplicationOAuth manages the post request submitted when a user grants access to an application
 func GrantApplicationOAuth(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.GrantApplicationForm)
@@ -461,36 +465,9 @@ func OIDCKeys(ctx *context.Context) {
 // AccessTokenOAuth manages all access token requests by the client
 func AccessTokenOAuth(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*forms.AccessTokenForm)
-	// if there is no ClientID or ClientSecret in the request body, fill these fields by the Authorization header and ensure the provided field matches the Authorization header
-	if form.ClientID == "" || form.ClientSecret == "" {
-		authHeader := ctx.Req.Header.Get("Authorization")
-		if authType, authData, ok := strings.Cut(authHeader, " "); ok && strings.EqualFold(authType, "Basic") {
-			clientID, clientSecret, err := base.BasicAuthDecode(authData)
-			if err != nil {
-				handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
-					ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "cannot parse basic auth header",
-				})
-				return
-			}
-			// validate that any fields present in the form match the Basic auth header
-			if form.ClientID != "" && form.ClientID != clientID {
-				handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
-					ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "client_id in request body inconsistent with Authorization header",
-				})
-				return
-			}
-			form.ClientID = clientID
-			if form.ClientSecret != "" && form.ClientSecret != clientSecret {
-				handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
-					ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
-					ErrorDescription: "client_secret in request body inconsistent with Authorization header",
-				})
-				return
-			}
-			form.ClientSecret = clientSecret
-		}
+	if acErr := fillClientCredentials(ctx, &form.ClientID, &form.ClientSecret); acErr != nil {
+		handleAccessTokenError(ctx, *acErr)
+		return
 	}
 
 	serverKey := oauth2_provider.DefaultSigningKey
@@ -512,14 +489,52 @@ func AccessTokenOAuth(ctx *context.Context) {
 		handleRefreshToken(ctx, form, serverKey, clientKey)
 	case "authorization_code":
 		handleAuthorizationCode(ctx, form, serverKey, clientKey)
+	case deviceCodeGrantType:
+		handleDeviceCode(ctx, form, serverKey, clientKey)
 	default:
 		handleAccessTokenError(ctx, oauth2_provider.AccessTokenError{
 			ErrorCode:        oauth2_provider.AccessTokenErrorCodeUnsupportedGrantType,
-			ErrorDescription: "Only refresh_token or authorization_code grant type is supported",
+			ErrorDescription: "Only refresh_token, authorization_code or device_code grant type is supported",
 		})
 	}
 }
 
+// fillClientCredentials fills the client id and the client secret by the Authorization header if they aren't in the request body,
+// and ensures the provided fields match the Authorization header
+func fillClientCredentials(ctx *context.Context, clientID, clientSecret *string) *oauth2_provider.AccessTokenError {
+	if *clientID != "" && *clientSecret != "" {
+		return nil
+	}
+	authHeader := ctx.Req.Header.Get("Authorization")
+	authType, authData, ok := strings.Cut(authHeader, " ")
+	if !ok || !strings.EqualFold(authType, "Basic") {
+		return nil
+	}
+	headerClientID, headerClientSecret, err := base.BasicAuthDecode(authData)
+	if err != nil {
+		return &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot parse basic auth header",
+		}
+	}
+	// validate that any fields present in the form match the Basic auth header
+	if *clientID != "" && *clientID != headerClientID {
+		return &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "client_id in request body inconsistent with Authorization header",
+		}
+	}
+	*clientID = headerClientID
+	if *clientSecret != "" && *clientSecret != headerClientSecret {
+		return &oauth2_provider.AccessTokenError{
+			ErrorCode:        oauth2_provider.AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "client_secret in request body inconsistent with Authorization header",
+		}
+	}
+	*clientSecret = headerClientSecret
+	return nil
+}
+
 func handleRefreshToken(ctx *context.Context, form forms.AccessTokenForm, serverKey, clientKey oauth2_provider.JWTSigningKey) {
 	app, err := auth.GetOAuth2ApplicationByClientID(ctx, form.ClientID)
 	if err != nil {
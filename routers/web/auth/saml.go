@@ -0,0 +1,252 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package auth
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	auth_module "code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/setting"
+	source_service "code.gitea.io/gitea/services/auth/source"
+	"code.gitea.io/gitea/services/auth/source/saml"
+	"code.gitea.io/gitea/services/context"
+	user_service "code.gitea.io/gitea/services/user"
+)
+
+func getActiveSAMLSource(ctx *context.Context) (*auth.Source, *saml.Source) {
+	authSource, err := auth.GetActiveSAMLSourceByName(ctx, ctx.PathParam(":provider"))
+	if err != nil {
+		ctx.NotFound("GetActiveSAMLSourceByName", err)
+		return nil, nil
+	}
+	return authSource, authSource.Cfg.(*saml.Source)
+}
+
+// SAMLMetadata serves the service provider metadata of a SAML source
+func SAMLMetadata(ctx *context.Context) {
+	_, source := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	data, err := source.ServiceProviderMetadata()
+	if err != nil {
+		ctx.ServerError("ServiceProviderMetadata", err)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", "application/samlmetadata+xml")
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write(data)
+}
+
+// SignInSAML redirects the user to the identity provider of a SAML source
+func SignInSAML(ctx *context.Context) {
+	_, source := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	redirectURL, err := source.Callout(ctx)
+	if err != nil {
+		ctx.ServerError("SignInSAML", err)
+		return
+	}
+	ctx.Redirect(redirectURL)
+}
+
+// SignInSAMLCallback handles the response posted by the identity provider to the assertion consumer service
+func SignInSAMLCallback(ctx *context.Context) {
+	authSource, source := getActiveSAMLSource(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	info, err := source.Callback(ctx.Req)
+	if err != nil {
+		if saml.IsErrInvalidResponse(err) {
+			log.Info("Failed SAML authentication attempt with source %s from %s: %v", authSource.Name, ctx.RemoteAddr(), err)
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.error"))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+			return
+		}
+		ctx.ServerError("SignInSAMLCallback", err)
+		return
+	}
+
+	u := &user_model.User{
+		LoginName:   info.NameID,
+		LoginType:   auth.SAML,
+		LoginSource: authSource.ID,
+	}
+	hasUser, err := user_model.GetUser(ctx, u)
+	if err != nil {
+		ctx.ServerError("GetUser", err)
+		return
+	}
+
+	if !hasUser {
+		if !source.AutoCreateUsers || setting.Service.AllowOnlyInternalRegistration {
+			ctx.Flash.Error(ctx.Tr("auth.saml.signin.no_account", authSource.Name))
+			ctx.Redirect(setting.AppSubURL + "/user/login")
+			return
+		}
+		if u = createSAMLUser(ctx, authSource, source, info); u == nil {
+			return
+		}
+	}
+
+	handleSAMLSignIn(ctx, source, u, info)
+}
+
+func getUserAdminAndRestrictedFromSAMLGroups(source *saml.Source, info *saml.UserInfo) (isAdmin, isRestricted optional.Option[bool]) {
+	if source.AdminGroup != "" {
+		isAdmin = optional.Some(info.Groups.Contains(source.AdminGroup))
+	}
+	if source.RestrictedGroup != "" {
+		isRestricted = optional.Some(info.Groups.Contains(source.RestrictedGroup))
+	}
+	return isAdmin, isRestricted
+}
+
+func createSAMLUser(ctx *context.Context, authSource *auth.Source, source *saml.Source, info *saml.UserInfo) *user_model.User {
+	uname, err := user_model.NormalizeUserName(info.Username)
+	if err != nil || info.Email == "" {
+		log.Error("SAML source %s asserted an unusable username %q or an empty email for %q", authSource.Name, info.Username, info.NameID)
+		ctx.Flash.Error(ctx.Tr("auth.saml.signin.missing_attributes", authSource.Name))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return nil
+	}
+
+	u := &user_model.User{
+		Name:        uname,
+		FullName:    info.FullName,
+		Email:       info.Email,
+		LoginType:   auth.SAML,
+		LoginSource: authSource.ID,
+		LoginName:   info.NameID,
+	}
+	isAdmin, isRestricted := getUserAdminAndRestrictedFromSAMLGroups(source, info)
+	u.IsAdmin = isAdmin.ValueOrDefault(false)
+	u.IsRestricted = isRestricted.ValueOrDefault(false)
+
+	meta := &user_model.Meta{
+		InitialIP:        ctx.RemoteAddr(),
+		InitialUserAgent: ctx.Req.UserAgent(),
+	}
+	// the identity has been verified by the identity provider, so the user is always activated
+	if err := user_model.CreateUser(ctx, u, meta, &user_model.CreateUserOverwriteOptions{IsActive: optional.Some(true)}); err != nil {
+		switch {
+		case user_model.IsErrUserAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("form.username_been_taken"))
+		case user_model.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
+		case user_model.IsErrEmailCharIsNotSupported(err), user_model.IsErrEmailInvalid(err):
+			ctx.Flash.Error(ctx.Tr("form.email_invalid"))
+		case db.IsErrNameReserved(err), db.IsErrNamePatternNotAllowed(err), db.IsErrNameCharsNotAllowed(err):
+			ctx.Flash.Error(ctx.Tr("user.form.name_reserved", u.Name))
+		default:
+			ctx.ServerError("CreateUser", err)
+			return nil
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return nil
+	}
+	log.Trace("Account created by SAML source %s: %s", authSource.Name, u.Name)
+
+	if !handleUserCreated(ctx, u, nil) {
+		return nil
+	}
+	return u
+}
+
+func handleSAMLSignIn(ctx *context.Context, source *saml.Source, u *user_model.User, info *saml.UserInfo) {
+	needs2FA := false
+	if !source.SkipLocalTwoFA {
+		_, err := auth.GetTwoFactorByUID(ctx, u.ID)
+		if err != nil && !auth.IsErrTwoFactorNotEnrolled(err) {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+		needs2FA = err == nil
+	}
+
+	opts := &user_service.UpdateOptions{}
+
+	// Reactivate user if they are deactivated
+	if !u.IsActive {
+		opts.IsActive = optional.Some(true)
+	}
+
+	// Update the user with the asserted groups
+	opts.IsAdmin, opts.IsRestricted = getUserAdminAndRestrictedFromSAMLGroups(source, info)
+
+	if source.GroupTeamMap != "" || source.GroupTeamMapRemoval {
+		groupTeamMapping, err := auth_module.UnmarshalGroupTeamMapping(source.GroupTeamMap)
+		if err != nil {
+			ctx.ServerError("UnmarshalGroupTeamMapping", err)
+			return
+		}
+		if err := source_service.SyncGroupsToTeams(ctx, u, info.Groups, groupTeamMapping, source.GroupTeamMapRemoval); err != nil {
+			ctx.ServerError("SyncGroupsToTeams", err)
+			return
+		}
+	}
+
+	if !needs2FA {
+		opts.SetLastLogin = true
+		if err := user_service.UpdateUser(ctx, u, opts); err != nil {
+			ctx.ServerError("UpdateUser", err)
+			return
+		}
+
+		if err := updateSession(ctx, nil, map[string]any{
+			"uid":   u.ID,
+			"uname": u.Name,
+		}); err != nil {
+			ctx.ServerError("updateSession", err)
+			return
+		}
+
+		// force to generate a new CSRF token
+		ctx.Csrf.PrepareForSessionUser(ctx)
+
+		if err := resetLocale(ctx, u); err != nil {
+			ctx.ServerError("resetLocale", err)
+			return
+		}
+
+		ctx.Redirect(setting.AppSubURL + "/")
+		return
+	}
+
+	if opts.IsActive.Has() || opts.IsAdmin.Has() || opts.IsRestricted.Has() {
+		if err := user_service.UpdateUser(ctx, u, opts); err != nil {
+			ctx.ServerError("UpdateUser", err)
+			return
+		}
+	}
+
+	if err := updateSession(ctx, nil, map[string]any{
+		// User needs to use 2FA, save data and redirect to 2FA page.
+		"twofaUid":      u.ID,
+		"twofaRemember": false,
+	}); err != nil {
+		ctx.ServerError("updateSession", err)
+		return
+	}
+
+	// If WebAuthn is enrolled -> Redirect to WebAuthn instead
+	regs, err := auth.GetWebAuthnCredentialsByUID(ctx, u.ID)
+	if err == nil && len(regs) > 0 {
+		ctx.Redirect(setting.AppSubURL + "/user/webauthn")
+		return
+	}
+
+	ctx.Redirect(setting.AppSubURL + "/user/two_factor")
+}
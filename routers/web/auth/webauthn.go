@@ -103,6 +103,9 @@ func WebAuthnPasskeyLogin(ctx *context.Context) {
 	if err != nil {
 		// Failed authentication attempt.
 		log.Info("Failed authentication attempt for passkey from %s: %v", ctx.RemoteAddr(), err)
+		if user != nil {
+			recordSignInFailure(ctx, user.ID, user.Name, "passkey")
+		}
 		ctx.Status(http.StatusForbidden)
 		return
 	}
@@ -121,6 +124,7 @@ func WebAuthnPasskeyLogin(ctx *context.Context) {
 	// (This is set if the sign counter is less than the one we have stored.)
 	if cred.Authenticator.CloneWarning {
 		log.Info("Failed authentication attempt for %s from %s: cloned credential", user.Name, ctx.RemoteAddr())
+		recordSignInFailure(ctx, user.ID, user.Name, "passkey")
 		ctx.Status(http.StatusForbidden)
 		return
 	}
@@ -221,6 +225,7 @@ func WebAuthnLoginAssertionPost(ctx *context.Context) {
 	if err != nil {
 		// Failed authentication attempt.
 		log.Info("Failed authentication attempt for %s from %s: %v", user.Name, ctx.RemoteAddr(), err)
+		recordSignInFailure(ctx, user.ID, user.Name, "webauthn")
 		ctx.Status(http.StatusForbidden)
 		return
 	}
@@ -231,6 +236,7 @@ func WebAuthnLoginAssertionPost(ctx *context.Context) {
 	if err != nil {
 		// Failed authentication attempt.
 		log.Info("Failed authentication attempt for %s from %s: %v", user.Name, ctx.RemoteAddr(), err)
+		recordSignInFailure(ctx, user.ID, user.Name, "webauthn")
 		ctx.Status(http.StatusForbidden)
 		return
 	}
@@ -239,6 +245,7 @@ func WebAuthnLoginAssertionPost(ctx *context.Context) {
 	// (This is set if the sign counter is less than the one we have stored.)
 	if cred.Authenticator.CloneWarning {
 		log.Info("Failed authentication attempt for %s from %s: cloned credential", user.Name, ctx.RemoteAddr())
+		recordSignInFailure(ctx, user.ID, user.Name, "webauthn")
 		ctx.Status(http.StatusForbidden)
 		return
 	}
@@ -0,0 +1,74 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/maintenance"
+)
+
+const tplStatus503 base.TplName = "status/503"
+
+// maintenanceAllowedPaths are the paths which change data but are still served during the maintenance,
+// the users must be able to sign in and out, and the OAuth2 applications must be able to get tokens to read.
+var maintenanceAllowedPaths = []string{
+	"/user/login",
+	"/user/logout",
+	"/user/two_factor",
+	"/user/webauthn",
+	"/login/oauth/access_token",
+}
+
+// maintenanceAllowedSuffixes are the git smart HTTP and LFS paths, fetching needs them to be POSTed.
+// The pushes are rejected by the pre-receive hook with the maintenance message.
+var maintenanceAllowedSuffixes = []string{
+	"/git-upload-pack",
+	"/git-receive-pack",
+	"/info/lfs/objects/batch",
+}
+
+func isMaintenanceAllowed(ctx *context.Context) bool {
+	if maintenance.IsReadOnlyRequest(ctx.Req) {
+		return true
+	}
+	path := ctx.Req.URL.Path
+	// the administrators must be able to disable the maintenance mode
+	if ctx.IsSigned && ctx.Doer.IsAdmin && strings.HasPrefix(path, "/-/admin/") {
+		return true
+	}
+	for _, p := range maintenanceAllowedPaths {
+		if path == p || strings.HasPrefix(path, p+"/") {
+			return true
+		}
+	}
+	for _, s := range maintenanceAllowedSuffixes {
+		if strings.HasSuffix(path, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// maintenanceMode shows the maintenance banner and rejects the requests that change data while the instance is in the maintenance mode
+func maintenanceMode(ctx *context.Context) {
+	if !maintenance.IsEnabled(ctx) {
+		return
+	}
+	msg := maintenance.Message(ctx)
+	ctx.Data["MaintenanceMessage"] = msg
+	if isMaintenanceAllowed(ctx) {
+		return
+	}
+
+	if !strings.Contains(ctx.Req.Header.Get("Accept"), "text/html") {
+		ctx.JSON(http.StatusServiceUnavailable, map[string]any{"errorMessage": msg, "renderFormat": "text"})
+		return
+	}
+	ctx.Data["Title"] = "Service Unavailable"
+	ctx.HTML(http.StatusServiceUnavailable, tplStatus503)
+}
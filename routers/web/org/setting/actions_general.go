@@ -0,0 +1,52 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplSettingsActionsGeneral base.TplName = "org/settings/actions"
+
+// ActionsGeneral renders the general Actions settings of the organization
+func ActionsGeneral(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.general")
+	ctx.Data["PageType"] = "general"
+	ctx.Data["PageIsSharedSettingsGeneral"] = true
+
+	mode, err := actions_model.GetOwnerTokenPermissionMode(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnerTokenPermissionMode", err)
+		return
+	}
+	ctx.Data["TokenPermissionMode"] = string(mode)
+
+	ctx.HTML(http.StatusOK, tplSettingsActionsGeneral)
+}
+
+// ActionsGeneralPost updates the general Actions settings of the organization
+func ActionsGeneralPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ActionsTokenPermissionForm)
+
+	if err := actions_service.SetOwnerTokenPermissionMode(ctx, ctx.ContextUser.ID, actions_model.TokenPermissionMode(form.Mode)); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("actions.general.token_permissions.invalid"))
+		} else {
+			ctx.ServerError("SetOwnerTokenPermissionMode", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.general.token_permissions.success"))
+	}
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/actions/general")
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSettingsRequiredWorkflows base.TplName = "org/settings/actions"
+)
+
+// RequiredWorkflows renders the workflows required for the repositories of the organization
+func RequiredWorkflows(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.required_workflows")
+	ctx.Data["PageType"] = "required_workflows"
+	ctx.Data["PageIsSharedSettingsRequiredWorkflows"] = true
+
+	rws, repos, err := actions_service.GetRequiredWorkflowsOfOwner(ctx, ctx.ContextUser)
+	if err != nil {
+		ctx.ServerError("GetRequiredWorkflowsOfOwner", err)
+		return
+	}
+	ctx.Data["RequiredWorkflows"] = rws
+	ctx.Data["RequiredWorkflowRepos"] = repos
+
+	orgRepos, _, err := repo_model.GetUserRepositories(ctx, &repo_model.SearchRepoOptions{
+		Actor:       ctx.ContextUser,
+		Private:     true,
+		OrderBy:     db.SearchOrderByAlphabetically,
+		ListOptions: db.ListOptionsAll,
+	})
+	if err != nil {
+		ctx.ServerError("GetUserRepositories", err)
+		return
+	}
+	ctx.Data["OrgRepos"] = orgRepos
+
+	ctx.HTML(http.StatusOK, tplSettingsRequiredWorkflows)
+}
+
+func splitRepoPatterns(s string) []string {
+	var patterns []string
+	for _, pattern := range strings.Split(strings.ReplaceAll(s, "\r", "\n"), "\n") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns
+}
+
+func requiredWorkflowErrorMessage(ctx *context.Context, err error) string {
+	switch {
+	case errors.Is(err, util.ErrAlreadyExist):
+		return ctx.Locale.TrString("actions.required_workflows.already_exists")
+	case errors.Is(err, util.ErrInvalidArgument), errors.Is(err, util.ErrNotExist):
+		return ctx.Locale.TrString("actions.required_workflows.invalid", err.Error())
+	default:
+		log.Error("save required workflow: %v", err)
+		return ctx.Locale.TrString("actions.required_workflows.failed")
+	}
+}
+
+// RequiredWorkflowCreate requires a workflow of a repository of the organization
+func RequiredWorkflowCreate(ctx *context.Context) {
+	if ctx.HasError() {
+		ctx.JSONError(ctx.GetErrMsg())
+		return
+	}
+	form := web.GetForm(ctx).(*forms.RequiredWorkflowForm)
+
+	repo, err := repo_model.GetRepositoryByID(ctx, form.RepoID)
+	if err != nil || repo.OwnerID != ctx.ContextUser.ID {
+		ctx.JSONError(ctx.Tr("actions.required_workflows.invalid_repo"))
+		return
+	}
+	rw, err := actions_service.CreateRequiredWorkflow(ctx, repo, strings.TrimSpace(form.WorkflowPath), splitRepoPatterns(form.RepoPatterns))
+	if err != nil {
+		ctx.JSONError(requiredWorkflowErrorMessage(ctx, err))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.required_workflows.creation.success", rw.Name))
+	ctx.JSONRedirect(ctx.Org.OrgLink + "/settings/actions/required_workflows")
+}
+
+func getRequiredWorkflow(ctx *context.Context) *actions_model.ActionRequiredWorkflow {
+	rw, err := actions_model.GetRequiredWorkflowByID(ctx, ctx.PathParamInt64(":id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("GetRequiredWorkflowByID", err)
+		} else {
+			ctx.ServerError("GetRequiredWorkflowByID", err)
+		}
+		return nil
+	}
+	if rw.OwnerID != ctx.ContextUser.ID {
+		ctx.NotFound("GetRequiredWorkflowByID", nil)
+		return nil
+	}
+	return rw
+}
+
+// RequiredWorkflowEdit updates the repositories the workflow is required for
+func RequiredWorkflowEdit(ctx *context.Context) {
+	rw := getRequiredWorkflow(ctx)
+	if ctx.Written() {
+		return
+	}
+	form := web.GetForm(ctx).(*forms.RequiredWorkflowForm)
+
+	if err := actions_service.UpdateRequiredWorkflow(ctx, rw, splitRepoPatterns(form.RepoPatterns)); err != nil {
+		ctx.JSONError(requiredWorkflowErrorMessage(ctx, err))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.required_workflows.update.success", rw.Name))
+	ctx.JSONRedirect(ctx.Org.OrgLink + "/settings/actions/required_workflows")
+}
+
+// RequiredWorkflowDelete stops requiring the workflow
+func RequiredWorkflowDelete(ctx *context.Context) {
+	rw := getRequiredWorkflow(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.DeleteRequiredWorkflow(ctx, rw); err != nil {
+		log.Error("DeleteRequiredWorkflow: %v", err)
+		ctx.JSONError(ctx.Tr("actions.required_workflows.deletion.failed"))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.required_workflows.deletion.success", rw.Name))
+	ctx.JSONRedirect(ctx.Org.OrgLink + "/settings/actions/required_workflows")
+}
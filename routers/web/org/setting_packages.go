@@ -129,3 +129,11 @@ func RebuildCargoIndex(ctx *context.Context) {
 
 	ctx.Redirect(fmt.Sprintf("%s/org/%s/settings/packages", setting.AppSubURL, ctx.ContextUser.Name))
 }
+
+func PackagesRemoteAddPost(ctx *context.Context) {
+	shared.AddRemote(ctx, ctx.ContextUser, fmt.Sprintf("%s/org/%s/settings/packages", setting.AppSubURL, ctx.ContextUser.Name))
+}
+
+func PackagesRemoteDeletePost(ctx *context.Context) {
+	shared.DeleteRemote(ctx, ctx.ContextUser, fmt.Sprintf("%s/org/%s/settings/packages", setting.AppSubURL, ctx.ContextUser.Name))
+}
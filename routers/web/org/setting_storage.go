@@ -0,0 +1,30 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	shared_user "code.gitea.io/gitea/routers/web/shared/user"
+	"code.gitea.io/gitea/services/context"
+)
+
+const (
+	tplSettingsStorage base.TplName = "org/settings/storage"
+)
+
+// Storage renders the storage used by the organization and its quota
+func Storage(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.storage")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsStorage"] = true
+
+	shared_user.StorageQuota(ctx, ctx.ContextUser)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsStorage)
+}
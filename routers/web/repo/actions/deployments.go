@@ -0,0 +1,123 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/convert"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplDeployments base.TplName = "repo/actions/deployments"
+
+// Deployments renders the deployments of the repository
+func Deployments(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.deployments")
+	ctx.Data["PageIsActions"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	envs, err := db.Find[actions_model.ActionEnvironment](ctx, actions_model.FindEnvironmentsOptions{RepoID: ctx.Repo.Repository.ID})
+	if err != nil {
+		ctx.ServerError("FindEnvironments", err)
+		return
+	}
+	ctx.Data["Environments"] = envs
+	ctx.Data["StatusList"] = actions_model.DeploymentStatusList()
+
+	opts := actions_model.FindDeploymentsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: convert.ToCorrectPageSize(ctx.FormInt("limit")),
+		},
+		RepoID:        ctx.Repo.Repository.ID,
+		EnvironmentID: ctx.FormInt64("environment"),
+	}
+	curStatus := ctx.FormString("status")
+	if status, ok := actions_model.DeploymentStatusFromString(curStatus); ok && status != actions_model.DeploymentStatusUnknown {
+		opts.Status = []actions_model.DeploymentStatus{status}
+	} else {
+		curStatus = ""
+	}
+	ctx.Data["CurEnvironment"] = opts.EnvironmentID
+	ctx.Data["CurStatus"] = curStatus
+	ctx.Data["IsFiltered"] = opts.EnvironmentID > 0 || curStatus != ""
+
+	deployments, total, err := db.FindAndCount[actions_model.ActionDeployment](ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindDeployments", err)
+		return
+	}
+	if err := actions_model.DeploymentList(deployments).LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return
+	}
+	ctx.Data["Deployments"] = deployments
+
+	pager := context.NewPagination(int(total), opts.PageSize, opts.Page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParamString("environment", fmt.Sprint(opts.EnvironmentID))
+	pager.AddParamString("status", curStatus)
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplDeployments)
+}
+
+// ReviewDeployment approves or rejects a deployment waiting for the reviews
+func ReviewDeployment(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ReviewDeploymentForm)
+
+	d, err := actions_model.GetDeploymentByID(ctx, ctx.PathParamInt64(":deployment_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("GetDeploymentByID", err)
+		} else {
+			ctx.ServerError("GetDeploymentByID", err)
+		}
+		return
+	}
+	if d.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("GetDeploymentByID", nil)
+		return
+	}
+	run, err := actions_model.GetRunByID(ctx, d.RunID)
+	if err != nil {
+		ctx.ServerError("GetRunByID", err)
+		return
+	}
+
+	if err := actions_service.ReviewPendingDeployments(ctx, ctx.Doer, run, []int64{d.EnvironmentID}, form.Approve, form.Comment); err != nil {
+		switch {
+		case errors.Is(err, util.ErrPermissionDenied):
+			ctx.JSONError(ctx.Tr("actions.deployments.review.not_reviewer"))
+		case errors.Is(err, util.ErrNotExist):
+			ctx.JSONError(ctx.Tr("actions.deployments.review.not_pending"))
+		default:
+			log.Error("ReviewPendingDeployments: %v", err)
+			ctx.JSONError(ctx.Tr("actions.deployments.review.failed"))
+		}
+		return
+	}
+
+	if form.Approve {
+		ctx.Flash.Success(ctx.Tr("actions.deployments.review.approved"))
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.deployments.review.rejected"))
+	}
+	ctx.JSONRedirect(ctx.Repo.RepoLink + "/actions/deployments")
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/renderhelper"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/util"
+	context_module "code.gitea.io/gitea/services/context"
+)
+
+// renderTaskSummary renders the summaries of the steps of the task as one markdown document like GitHub
+func renderTaskSummary(ctx *context_module.Context, task *actions_model.ActionTask) (template.HTML, error) {
+	summaries, err := actions_model.GetTaskSummaries(ctx, task.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(summaries) == 0 {
+		return "", nil
+	}
+	contents := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		contents = append(contents, summary.Content)
+	}
+	return markdown.RenderString(renderhelper.NewRenderContextRepoComment(ctx, ctx.Repo.Repository), strings.Join(contents, "\n\n"))
+}
+
+func getTaskViewAnnotations(ctx *context_module.Context, task *actions_model.ActionTask) ([]*ViewAnnotation, error) {
+	annotations, err := db.Find[actions_model.ActionTaskAnnotation](ctx, actions_model.FindTaskAnnotationOptions{TaskID: task.ID})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ViewAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		v := &ViewAnnotation{
+			Level:   a.Level,
+			Title:   a.Title,
+			Message: a.Message,
+		}
+		if a.HasLocation() {
+			lines := fmt.Sprintf("L%d", a.StartLine)
+			if a.EndLine > a.StartLine {
+				lines += fmt.Sprintf("-L%d", a.EndLine)
+			}
+			v.Location = fmt.Sprintf("%s#%s", a.Path, lines)
+			v.Link = fmt.Sprintf("%s/src/commit/%s/%s#%s", ctx.Repo.RepoLink, a.CommitSHA, util.PathEscapeSegments(a.Path), lines)
+		} else if a.Path != "" {
+			v.Location = a.Path
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
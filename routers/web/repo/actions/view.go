@@ -4,44 +4,32 @@
 package actions
 
 import (
-	"archive/zip"
-	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"html/template"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"time"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
-	"code.gitea.io/gitea/models/perm"
-	access_model "code.gitea.io/gitea/models/perm/access"
-	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
-	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/modules/storage"
-	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/templates"
-	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/common"
 	actions_service "code.gitea.io/gitea/services/actions"
 	context_module "code.gitea.io/gitea/services/context"
-	"code.gitea.io/gitea/services/convert"
 
-	"github.com/nektos/act/pkg/jobparser"
 	"github.com/nektos/act/pkg/model"
-	"xorm.io/builder"
 )
 
 func getRunIndex(ctx *context_module.Context) int64 {
@@ -102,13 +90,16 @@ type ViewResponse struct {
 			WorkflowID        string        `json:"workflowID"`
 			WorkflowLink      string        `json:"workflowLink"`
 			IsSchedule        bool          `json:"isSchedule"`
+			ConcurrencyGroup  string        `json:"concurrencyGroup"`
 			Jobs              []*ViewJob    `json:"jobs"`
 			Commit            ViewCommit    `json:"commit"`
 		} `json:"run"`
 		CurrentJob struct {
-			Title  string         `json:"title"`
-			Detail string         `json:"detail"`
-			Steps  []*ViewJobStep `json:"steps"`
+			Title       string            `json:"title"`
+			Detail      string            `json:"detail"`
+			Steps       []*ViewJobStep    `json:"steps"`
+			SummaryHTML template.HTML     `json:"summaryHTML"`
+			Annotations []*ViewAnnotation `json:"annotations"`
 		} `json:"currentJob"`
 	} `json:"state"`
 	Logs struct {
@@ -117,11 +108,13 @@ type ViewResponse struct {
 }
 
 type ViewJob struct {
-	ID       int64  `json:"id"`
-	Name     string `json:"name"`
-	Status   string `json:"status"`
-	CanRerun bool   `json:"canRerun"`
-	Duration string `json:"duration"`
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	Status           string `json:"status"`
+	CanRerun         bool   `json:"canRerun"`
+	Duration         string `json:"duration"`
+	ConcurrencyGroup string `json:"concurrencyGroup"`
+	Environment      string `json:"environment"`
 }
 
 type ViewCommit struct {
@@ -148,6 +141,14 @@ type ViewJobStep struct {
 	Status   string `json:"status"`
 }
 
+type ViewAnnotation struct {
+	Level    string `json:"level"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Location string `json:"location"`
+	Link     string `json:"link"`
+}
+
 type ViewStepLog struct {
 	Step    int                `json:"step"`
 	Cursor  int64              `json:"cursor"`
@@ -221,16 +222,27 @@ func ViewPost(ctx *context_module.Context) {
 	resp.State.Run.WorkflowID = run.WorkflowID
 	resp.State.Run.WorkflowLink = run.WorkflowLink()
 	resp.State.Run.IsSchedule = run.IsSchedule()
+	resp.State.Run.ConcurrencyGroup = run.ConcurrencyGroup
 	resp.State.Run.Jobs = make([]*ViewJob, 0, len(jobs)) // marshal to '[]' instead fo 'null' in json
 	resp.State.Run.Status = run.Status.String()
+	envs, err := getJobEnvironments(ctx, jobs)
+	if err != nil {
+		ctx.ServerError("getJobEnvironments", err)
+		return
+	}
 	for _, v := range jobs {
-		resp.State.Run.Jobs = append(resp.State.Run.Jobs, &ViewJob{
-			ID:       v.ID,
-			Name:     v.Name,
-			Status:   v.Status.String(),
-			CanRerun: v.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeActions),
-			Duration: v.Duration().String(),
-		})
+		viewJob := &ViewJob{
+			ID:               v.ID,
+			Name:             v.Name,
+			Status:           v.Status.String(),
+			CanRerun:         v.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeActions),
+			Duration:         v.Duration().String(),
+			ConcurrencyGroup: v.ConcurrencyGroup,
+		}
+		if env := envs[v.EnvironmentID]; env != nil {
+			viewJob.Environment = env.Name
+		}
+		resp.State.Run.Jobs = append(resp.State.Run.Jobs, viewJob)
 	}
 
 	pusher := ViewUser{
@@ -277,10 +289,36 @@ func ViewPost(ctx *context_module.Context) {
 	resp.State.CurrentJob.Detail = current.Status.LocaleString(ctx.Locale)
 	if run.NeedApproval {
 		resp.State.CurrentJob.Detail = ctx.Locale.TrString("actions.need_approval_desc")
+	} else if current.Status.IsBlocked() {
+		if group, err := getBlockingConcurrencyGroup(ctx, run, current); err != nil {
+			ctx.ServerError("getBlockingConcurrencyGroup", err)
+			return
+		} else if group != "" {
+			resp.State.CurrentJob.Detail = ctx.Locale.TrString("actions.runs.waiting_for_concurrency_group", group)
+		} else if env := envs[current.EnvironmentID]; env != nil {
+			if detail, err := getEnvironmentBlockingDetail(ctx, current, env); err != nil {
+				ctx.ServerError("getEnvironmentBlockingDetail", err)
+				return
+			} else if detail != "" {
+				resp.State.CurrentJob.Detail = detail
+			}
+		}
 	}
-	resp.State.CurrentJob.Steps = make([]*ViewJobStep, 0) // marshal to '[]' instead fo 'null' in json
-	resp.Logs.StepsLog = make([]*ViewStepLog, 0)          // marshal to '[]' instead fo 'null' in json
+	resp.State.CurrentJob.Steps = make([]*ViewJobStep, 0)          // marshal to '[]' instead fo 'null' in json
+	resp.State.CurrentJob.Annotations = make([]*ViewAnnotation, 0) // marshal to '[]' instead fo 'null' in json
+	resp.Logs.StepsLog = make([]*ViewStepLog, 0)                   // marshal to '[]' instead fo 'null' in json
 	if task != nil {
+		resp.State.CurrentJob.SummaryHTML, err = renderTaskSummary(ctx, task)
+		if err != nil {
+			ctx.ServerError("renderTaskSummary", err)
+			return
+		}
+		resp.State.CurrentJob.Annotations, err = getTaskViewAnnotations(ctx, task)
+		if err != nil {
+			ctx.ServerError("getTaskViewAnnotations", err)
+			return
+		}
+
 		steps := actions.FullSteps(task)
 
 		for _, v := range steps {
@@ -371,88 +409,27 @@ func Rerun(ctx *context_module.Context) {
 		jobIndex, _ = strconv.ParseInt(jobIndexStr, 10, 64)
 	}
 
-	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, runIndex)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// can not rerun job when workflow is disabled
-	cfgUnit := ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions)
-	cfg := cfgUnit.ActionsConfig()
-	if cfg.IsWorkflowDisabled(run.WorkflowID) {
-		ctx.JSONError(ctx.Locale.Tr("actions.workflow.disabled"))
-		return
-	}
-
-	// reset run's start and stop time when it is done
-	if run.Status.IsDone() {
-		run.PreviousDuration = run.Duration()
-		run.Started = 0
-		run.Stopped = 0
-		if err := actions_model.UpdateRun(ctx, run, "started", "stopped", "previous_duration"); err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
-			return
-		}
-	}
-
-	job, jobs := getRunJobs(ctx, runIndex, jobIndex)
+	job, _ := getRunJobs(ctx, runIndex, jobIndex)
 	if ctx.Written() {
 		return
 	}
-
+	run := job.Run
 	if jobIndexStr == "" { // rerun all jobs
-		for _, j := range jobs {
-			// if the job has needs, it should be set to "blocked" status to wait for other jobs
-			shouldBlock := len(j.Needs) > 0
-			if err := rerunJob(ctx, j, shouldBlock); err != nil {
-				ctx.Error(http.StatusInternalServerError, err.Error())
-				return
-			}
-		}
-		ctx.JSON(http.StatusOK, struct{}{})
-		return
+		job = nil
 	}
 
-	rerunJobs := actions_service.GetAllRerunJobs(job, jobs)
-
-	for _, j := range rerunJobs {
-		// jobs other than the specified one should be set to "blocked" status
-		shouldBlock := j.JobID != job.JobID
-		if err := rerunJob(ctx, j, shouldBlock); err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
+	if err := actions_service.RerunRun(ctx, run, job); err != nil {
+		if errors.Is(err, actions_service.ErrWorkflowDisabled) {
+			ctx.JSONError(ctx.Locale.Tr("actions.workflow.disabled"))
 			return
 		}
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
-func rerunJob(ctx *context_module.Context, job *actions_model.ActionRunJob, shouldBlock bool) error {
-	status := job.Status
-	if !status.IsDone() {
-		return nil
-	}
-
-	job.TaskID = 0
-	job.Status = actions_model.StatusWaiting
-	if shouldBlock {
-		job.Status = actions_model.StatusBlocked
-	}
-	job.Started = 0
-	job.Stopped = 0
-
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		_, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "task_id", "status", "started", "stopped")
-		return err
-	}); err != nil {
-		return err
-	}
-
-	actions_service.CreateCommitStatus(ctx, job)
-	return nil
-}
-
 func Logs(ctx *context_module.Context) {
 	runIndex := getRunIndex(ctx)
 	jobIndex := ctx.PathParamInt64("job")
@@ -461,124 +438,109 @@ func Logs(ctx *context_module.Context) {
 	if ctx.Written() {
 		return
 	}
-	if job.TaskID == 0 {
-		ctx.Error(http.StatusNotFound, "job is not started")
-		return
-	}
-
-	err := job.LoadRun(ctx)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	task, err := actions_model.GetTaskByID(ctx, job.TaskID)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-	if task.LogExpired {
-		ctx.Error(http.StatusNotFound, "logs have been cleaned up")
-		return
-	}
 
-	reader, err := actions.OpenLogs(ctx, task.LogInStorage, task.LogFilename)
-	if err != nil {
+	if err := common.DownloadActionsRunJobLogs(ctx.Base, job); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, err.Error())
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
 	}
-	defer reader.Close()
-
-	workflowName := job.Run.WorkflowID
-	if p := strings.Index(workflowName, "."); p > 0 {
-		workflowName = workflowName[0:p]
-	}
-	ctx.ServeContent(reader, &context_module.ServeHeaderOptions{
-		Filename:           fmt.Sprintf("%v-%v-%v.log", workflowName, job.Name, task.ID),
-		ContentLength:      &task.LogSize,
-		ContentType:        "text/plain",
-		ContentTypeCharset: "utf-8",
-		Disposition:        "attachment",
-	})
 }
 
 func Cancel(ctx *context_module.Context) {
 	runIndex := getRunIndex(ctx)
 
-	_, jobs := getRunJobs(ctx, runIndex, -1)
+	current, _ := getRunJobs(ctx, runIndex, -1)
 	if ctx.Written() {
 		return
 	}
 
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		for _, job := range jobs {
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
-			if job.TaskID == 0 {
-				job.Status = actions_model.StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
-				n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
-				continue
-			}
-			if err := actions_model.StopTask(ctx, job.TaskID, actions_model.StatusCancelled); err != nil {
-				return err
-			}
-		}
-		return nil
-	}); err != nil {
+	if err := actions_service.CancelRun(ctx, current.Run); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	actions_service.CreateCommitStatus(ctx, jobs...)
-
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
 func Approve(ctx *context_module.Context) {
 	runIndex := getRunIndex(ctx)
 
-	current, jobs := getRunJobs(ctx, runIndex, -1)
+	current, _ := getRunJobs(ctx, runIndex, -1)
 	if ctx.Written() {
 		return
 	}
-	run := current.Run
-	doer := ctx.Doer
 
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		run.NeedApproval = false
-		run.ApprovedBy = doer.ID
-		if err := actions_model.UpdateRun(ctx, run, "need_approval", "approved_by"); err != nil {
-			return err
-		}
-		for _, job := range jobs {
-			if len(job.Needs) == 0 && job.Status.IsBlocked() {
-				job.Status = actions_model.StatusWaiting
-				_, err := actions_model.UpdateRunJob(ctx, job, nil, "status")
-				if err != nil {
-					return err
-				}
-			}
+	if err := actions_service.ApproveRun(ctx, ctx.Doer, current.Run); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.JSONError(err.Error())
+			return
 		}
-		return nil
-	}); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	actions_service.CreateCommitStatus(ctx, jobs...)
-
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
+// getJobEnvironments returns the environments the jobs deploy to, indexed by id
+func getJobEnvironments(ctx context.Context, jobs []*actions_model.ActionRunJob) (map[int64]*actions_model.ActionEnvironment, error) {
+	ids := make(container.Set[int64])
+	for _, job := range jobs {
+		if job.EnvironmentID > 0 {
+			ids.Add(job.EnvironmentID)
+		}
+	}
+	ret := make(map[int64]*actions_model.ActionEnvironment, len(ids))
+	if len(ids) == 0 {
+		return ret, nil
+	}
+	envs, err := db.Find[actions_model.ActionEnvironment](ctx, actions_model.FindEnvironmentsOptions{IDs: ids.Values()})
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		ret[env.ID] = env
+	}
+	return ret, nil
+}
+
+// getEnvironmentBlockingDetail returns the description of the protection rule which the blocked job is waiting for, or empty if it's not waiting for the environment
+func getEnvironmentBlockingDetail(ctx *context_module.Context, job *actions_model.ActionRunJob, env *actions_model.ActionEnvironment) (string, error) {
+	deployment, err := actions_model.GetLatestDeploymentOfJob(ctx, job.ID)
+	if errors.Is(err, util.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if deployment.Status != actions_model.DeploymentStatusWaiting {
+		return "", nil
+	}
+	if env.RequiresReview() && deployment.ApprovedUnix.IsZero() {
+		return ctx.Locale.TrString("actions.runs.waiting_for_environment_review", env.Name), nil
+	}
+	if !deployment.WaitUntil.IsZero() {
+		return ctx.Locale.TrString("actions.runs.waiting_for_environment_timer", env.Name, deployment.WaitUntil.AsLocalTime().Format(time.DateTime)), nil
+	}
+	return "", nil
+}
+
+// getBlockingConcurrencyGroup returns the concurrency group which the blocked job is waiting for, or empty if it's not blocked by concurrency
+func getBlockingConcurrencyGroup(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) (string, error) {
+	if blocked, err := actions_model.ShouldBlockRunByConcurrency(ctx, run); err != nil {
+		return "", err
+	} else if blocked {
+		return run.ConcurrencyGroup, nil
+	}
+	if blocked, err := actions_model.ShouldBlockJobByConcurrency(ctx, job); err != nil {
+		return "", err
+	} else if blocked {
+		return job.ConcurrencyGroup, nil
+	}
+	return "", nil
+}
+
 // getRunJobs gets the jobs of runIndex, and returns jobs[jobIndex], jobs.
 // Any error will be written to the ctx.
 // It never returns a nil job of an empty jobs, if the jobIndex is out of range, it will be treated as 0.
@@ -650,81 +612,12 @@ func ArtifactsDownloadView(ctx *context_module.Context) {
 		return
 	}
 
-	artifacts, err := db.Find[actions_model.ActionArtifact](ctx, actions_model.FindArtifactsOptions{
-		RunID:        run.ID,
-		ArtifactName: artifactName,
-	})
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-	if len(artifacts) == 0 {
-		ctx.Error(http.StatusNotFound, "artifact not found")
-		return
-	}
-
-	// if artifacts status is not uploaded-confirmed, treat it as not found
-	for _, art := range artifacts {
-		if art.Status != int64(actions_model.ArtifactStatusUploadConfirmed) {
-			ctx.Error(http.StatusNotFound, "artifact not found")
-			return
-		}
-	}
-
-	ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip; filename*=UTF-8''%s.zip", url.PathEscape(artifactName), artifactName))
-
-	// Artifacts using the v4 backend are stored as a single combined zip file per artifact on the backend
-	// The v4 backend enshures ContentEncoding is set to "application/zip", which is not the case for the old backend
-	if len(artifacts) == 1 && artifacts[0].ArtifactName+".zip" == artifacts[0].ArtifactPath && artifacts[0].ContentEncoding == "application/zip" {
-		art := artifacts[0]
-		if setting.Actions.ArtifactStorage.ServeDirect() {
-			u, err := storage.ActionsArtifacts.URL(art.StoragePath, art.ArtifactPath, nil)
-			if u != nil && err == nil {
-				ctx.Redirect(u.String())
-				return
-			}
-		}
-		f, err := storage.ActionsArtifacts.Open(art.StoragePath)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
-			return
-		}
-		_, _ = io.Copy(ctx.Resp, f)
-		return
-	}
-
-	// Artifacts using the v1-v3 backend are stored as multiple individual files per artifact on the backend
-	// Those need to be zipped for download
-	writer := zip.NewWriter(ctx.Resp)
-	defer writer.Close()
-	for _, art := range artifacts {
-		f, err := storage.ActionsArtifacts.Open(art.StoragePath)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
-			return
-		}
-
-		var r io.ReadCloser
-		if art.ContentEncoding == "gzip" {
-			r, err = gzip.NewReader(f)
-			if err != nil {
-				ctx.Error(http.StatusInternalServerError, err.Error())
-				return
-			}
-		} else {
-			r = f
-		}
-		defer r.Close()
-
-		w, err := writer.Create(art.ArtifactPath)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
-			return
-		}
-		if _, err := io.Copy(w, r); err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
+	if err := common.DownloadActionsRunArtifacts(ctx.Base, run.ID, artifactName); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.Error(http.StatusNotFound, err.Error())
 			return
 		}
+		ctx.Error(http.StatusInternalServerError, err.Error())
 	}
 }
 
@@ -743,17 +636,8 @@ func disableOrEnableWorkflowFile(ctx *context_module.Context, isEnable bool) {
 		return
 	}
 
-	cfgUnit := ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions)
-	cfg := cfgUnit.ActionsConfig()
-
-	if isEnable {
-		cfg.EnableWorkflow(workflow)
-	} else {
-		cfg.DisableWorkflow(workflow)
-	}
-
-	if err := repo_model.UpdateRepoUnit(ctx, cfgUnit); err != nil {
-		ctx.ServerError("UpdateRepoUnit", err)
+	if err := actions_service.EnableOrDisableWorkflow(ctx, ctx.Repo.Repository, workflow, isEnable); err != nil {
+		ctx.ServerError("EnableOrDisableWorkflow", err)
 		return
 	}
 
@@ -784,76 +668,8 @@ func Run(ctx *context_module.Context) {
 		return
 	}
 
-	// can not rerun job when workflow is disabled
-	cfgUnit := ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions)
-	cfg := cfgUnit.ActionsConfig()
-	if cfg.IsWorkflowDisabled(workflowID) {
-		ctx.Flash.Error(ctx.Tr("actions.workflow.disabled"))
-		ctx.Redirect(redirectURL)
-		return
-	}
-
-	// get target commit of run from specified ref
-	refName := git.RefName(ref)
-	var runTargetCommit *git.Commit
-	var err error
-	if refName.IsTag() {
-		runTargetCommit, err = ctx.Repo.GitRepo.GetTagCommit(refName.TagName())
-	} else if refName.IsBranch() {
-		runTargetCommit, err = ctx.Repo.GitRepo.GetBranchCommit(refName.BranchName())
-	} else {
-		ctx.Flash.Error(ctx.Tr("form.git_ref_name_error", ref))
-		ctx.Redirect(redirectURL)
-		return
-	}
-	if err != nil {
-		ctx.Flash.Error(ctx.Tr("form.target_ref_not_exist", ref))
-		ctx.Redirect(redirectURL)
-		return
-	}
-
-	// get workflow entry from default branch commit
-	defaultBranchCommit, err := ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-	entries, err := actions.ListWorkflows(defaultBranchCommit)
-	if err != nil {
-		ctx.Error(http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// find workflow from commit
-	var workflows []*jobparser.SingleWorkflow
-	for _, entry := range entries {
-		if entry.Name() == workflowID {
-			content, err := actions.GetContentFromEntry(entry)
-			if err != nil {
-				ctx.Error(http.StatusInternalServerError, err.Error())
-				return
-			}
-			workflows, err = jobparser.Parse(content)
-			if err != nil {
-				ctx.ServerError("workflow", err)
-				return
-			}
-			break
-		}
-	}
-
-	if len(workflows) == 0 {
-		ctx.Flash.Error(ctx.Tr("actions.workflow.not_found", workflowID))
-		ctx.Redirect(redirectURL)
-		return
-	}
-
-	// get inputs from post
-	workflow := &model.Workflow{
-		RawOn: workflows[0].RawOn,
-	}
-	inputs := make(map[string]any)
-	if workflowDispatch := workflow.WorkflowDispatchConfig(); workflowDispatch != nil {
+	_, err := actions_service.DispatchWorkflow(ctx, ctx.Doer, ctx.Repo.Repository, ctx.Repo.GitRepo, workflowID, ref, func(workflowDispatch *model.WorkflowDispatch, inputs map[string]any) error {
+		// get inputs from post
 		for name, config := range workflowDispatch.Inputs {
 			value := ctx.Req.PostForm.Get(name)
 			if config.Type == "boolean" {
@@ -869,61 +685,25 @@ func Run(ctx *context_module.Context) {
 				inputs[name] = config.Default
 			}
 		}
-	}
-
-	// ctx.Req.PostForm -> WorkflowDispatchPayload.Inputs -> ActionRun.EventPayload -> runner: ghc.Event
-	// https://docs.github.com/en/actions/learn-github-actions/contexts#github-context
-	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_dispatch
-	workflowDispatchPayload := &api.WorkflowDispatchPayload{
-		Workflow:   workflowID,
-		Ref:        ref,
-		Repository: convert.ToRepo(ctx, ctx.Repo.Repository, access_model.Permission{AccessMode: perm.AccessModeNone}),
-		Inputs:     inputs,
-		Sender:     convert.ToUserWithAccessMode(ctx, ctx.Doer, perm.AccessModeNone),
-	}
-	var eventPayload []byte
-	if eventPayload, err = workflowDispatchPayload.JSONPayload(); err != nil {
-		ctx.ServerError("JSONPayload", err)
-		return
-	}
-
-	run := &actions_model.ActionRun{
-		Title:             strings.SplitN(runTargetCommit.CommitMessage, "\n", 2)[0],
-		RepoID:            ctx.Repo.Repository.ID,
-		OwnerID:           ctx.Repo.Repository.OwnerID,
-		WorkflowID:        workflowID,
-		TriggerUserID:     ctx.Doer.ID,
-		Ref:               ref,
-		CommitSHA:         runTargetCommit.ID.String(),
-		IsForkPullRequest: false,
-		Event:             "workflow_dispatch",
-		TriggerEvent:      "workflow_dispatch",
-		EventPayload:      string(eventPayload),
-		Status:            actions_model.StatusWaiting,
-	}
-
-	// cancel running jobs of the same workflow
-	if err := actions_model.CancelPreviousJobs(
-		ctx,
-		run.RepoID,
-		run.Ref,
-		run.WorkflowID,
-		run.Event,
-	); err != nil {
-		log.Error("CancelRunningJobs: %v", err)
-	}
-
-	// Insert the action run and its associated jobs into the database
-	if err := actions_model.InsertRun(ctx, run, workflows); err != nil {
-		ctx.ServerError("workflow", err)
-		return
-	}
-
-	alljobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+		return nil
+	})
 	if err != nil {
-		log.Error("FindRunJobs: %v", err)
+		switch {
+		case errors.Is(err, actions_service.ErrWorkflowDisabled):
+			ctx.Flash.Error(ctx.Tr("actions.workflow.disabled"))
+		case errors.Is(err, actions_service.ErrInvalidWorkflowRef):
+			ctx.Flash.Error(ctx.Tr("form.git_ref_name_error", ref))
+		case errors.Is(err, actions_service.ErrWorkflowRefNotExist):
+			ctx.Flash.Error(ctx.Tr("form.target_ref_not_exist", ref))
+		case errors.Is(err, actions_service.ErrWorkflowNotFound), errors.Is(err, actions_service.ErrWorkflowNotDispatchable):
+			ctx.Flash.Error(ctx.Tr("actions.workflow.not_found", workflowID))
+		default:
+			ctx.ServerError("DispatchWorkflow", err)
+			return
+		}
+		ctx.Redirect(redirectURL)
+		return
 	}
-	actions_service.CreateCommitStatus(ctx, alljobs...)
 
 	ctx.Flash.Success(ctx.Tr("actions.workflow.run_success", workflowID))
 	ctx.Redirect(redirectURL)
@@ -18,7 +18,6 @@ import (
 	"sync"
 	"time"
 
-	actions_model "code.gitea.io/gitea/models/actions"
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -189,29 +188,18 @@ func httpBase(ctx *context.Context) *serviceHandler {
 
 			if ctx.Data["IsActionsToken"] == true {
 				taskID := ctx.Data["ActionsTaskID"].(int64)
-				task, err := actions_model.GetTaskByID(ctx, taskID)
+				p, err := access_model.GetActionsUserRepoPermission(ctx, repo, ctx.Doer, taskID)
 				if err != nil {
-					ctx.ServerError("GetTaskByID", err)
+					ctx.ServerError("GetActionsUserRepoPermission", err)
 					return nil
 				}
-				if task.RepoID != repo.ID {
+				// the token of the task could only push if the job has the write permission of contents
+				actionPerm := p.UnitAccessMode(unit.TypeCode)
+				if actionPerm < accessMode || actionPerm < perm.AccessModeRead {
 					ctx.PlainText(http.StatusForbidden, "User permission denied")
 					return nil
 				}
-
-				if task.IsForkPullRequest {
-					if accessMode > perm.AccessModeRead {
-						ctx.PlainText(http.StatusForbidden, "User permission denied")
-						return nil
-					}
-					environ = append(environ, fmt.Sprintf("%s=%d", repo_module.EnvActionPerm, perm.AccessModeRead))
-				} else {
-					if accessMode > perm.AccessModeWrite {
-						ctx.PlainText(http.StatusForbidden, "User permission denied")
-						return nil
-					}
-					environ = append(environ, fmt.Sprintf("%s=%d", repo_module.EnvActionPerm, perm.AccessModeWrite))
-				}
+				environ = append(environ, fmt.Sprintf("%s=%d", repo_module.EnvActionPerm, actionPerm))
 			} else {
 				p, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
 				if err != nil {
@@ -344,6 +332,13 @@ type serviceHandler struct {
 	environ []string
 }
 
+// setUploadPackConfig restricts the object filters git upload-pack accepts for partial clones of the repository
+func (h *serviceHandler) setUploadPackConfig(service string) {
+	if service == "upload-pack" {
+		h.environ = append(h.environ, git.ConfigEnvs(git.UploadPackFilterConfig(h.repo.AllowedPartialCloneFilters()))...)
+	}
+}
+
 func (h *serviceHandler) getRepoDir() string {
 	if h.isWiki {
 		return h.repo.WikiPath()
@@ -455,6 +450,7 @@ func serviceRPC(ctx *context.Context, h *serviceHandler, service string) {
 	if protocol := ctx.Req.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
 		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 	}
+	h.setUploadPackConfig(service)
 
 	var stderr bytes.Buffer
 	cmd.AddArguments("--stateless-rpc").AddDynamicArguments(h.getRepoDir())
@@ -527,6 +523,7 @@ func GetInfoRefs(ctx *context.Context) {
 		if protocol := ctx.Req.Header.Get("Git-Protocol"); protocol != "" && safeGitProtocolHeader.MatchString(protocol) {
 			h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 		}
+		h.setUploadPackConfig(service)
 		h.environ = append(os.Environ(), h.environ...)
 
 		refs, _, err := cmd.AddArguments("--stateless-rpc", "--advertise-refs", ".").RunStdBytes(&git.RunOpts{Env: h.environ, Dir: h.getRepoDir()})
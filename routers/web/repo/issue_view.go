@@ -896,6 +896,15 @@ func preparePullViewReviewAndMerge(ctx *context.Context, issue *issues_model.Iss
 		ctx.Data["IsBlockedByChangedProtectedFiles"] = len(pull.ChangedProtectedFiles) != 0
 		ctx.Data["ChangedProtectedFilesNum"] = len(pull.ChangedProtectedFiles)
 		ctx.Data["RequireApprovalsWhitelist"] = pb.EnableApprovalsWhitelist
+
+		if !pull.HasMerged && !issue.IsClosed {
+			unapprovedCodeOwnersRules, err := pull_service.GetUnapprovedCodeOwnersRules(ctx, pb, pull)
+			if err != nil {
+				log.Error("GetUnapprovedCodeOwnersRules: %v", err)
+			}
+			ctx.Data["UnapprovedCodeOwnersRules"] = unapprovedCodeOwnersRules
+			ctx.Data["IsBlockedByCodeOwners"] = len(unapprovedCodeOwnersRules) != 0
+		}
 	}
 
 	preparePullViewSigning(ctx, issue)
@@ -930,6 +939,23 @@ func preparePullViewReviewAndMerge(ctx *context.Context, issue *issues_model.Iss
 		ctx.ServerError("GetScheduledMergeByPullID", err)
 		return
 	}
+
+	// Check if the pull request is waiting in the merge queue
+	ctx.Data["IsMergeQueueEnabled"] = pb != nil && pb.EnableMergeQueue
+	exist, mergeQueueEntry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID)
+	if err != nil {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if exist {
+		position, err := pull_model.GetMergeQueuePosition(ctx, mergeQueueEntry)
+		if err != nil {
+			ctx.ServerError("GetMergeQueuePosition", err)
+			return
+		}
+		ctx.Data["MergeQueueEntry"] = mergeQueueEntry
+		ctx.Data["MergeQueuePosition"] = position
+	}
 }
 
 func prepareIssueViewContent(ctx *context.Context, issue *issues_model.Issue) {
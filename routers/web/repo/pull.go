@@ -788,6 +788,11 @@ func viewPullFiles(ctx *context.Context, specifiedStartCommit, specifiedEndCommi
 		return
 	}
 
+	if err = diff.LoadAnnotations(ctx, ctx.Repo.Repository.ID, endCommitID); err != nil {
+		ctx.ServerError("LoadAnnotations", err)
+		return
+	}
+
 	for _, file := range diff.Files {
 		for _, section := range file.Sections {
 			for _, line := range section.Lines {
@@ -1113,6 +1118,31 @@ func MergePullRequest(ctx *context.Context) {
 		}
 	}
 
+	// admins who force the merge bypass the merge queue like the other branch protections
+	if !form.ForceMerge {
+		if enabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+			ctx.ServerError("IsMergeQueueEnabled", err)
+			return
+		} else if enabled {
+			if err := pull_service.AddToMergeQueue(ctx, pr, ctx.Doer, repo_model.MergeStyle(form.Do), form.HeadCommitID, message); err != nil {
+				switch {
+				case models.IsErrInvalidMergeStyle(err):
+					ctx.JSONError(ctx.Tr("repo.pulls.invalid_merge_option"))
+				case models.IsErrSHADoesNotMatch(err):
+					ctx.JSONError(ctx.Tr("repo.pulls.head_out_of_date"))
+				case pull_model.IsErrAlreadyInMergeQueue(err):
+					ctx.JSONError(ctx.Tr("repo.pulls.merge_queue_already_queued"))
+				default:
+					ctx.ServerError("AddToMergeQueue", err)
+				}
+				return
+			}
+			ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_added"))
+			ctx.JSONRedirect(issue.Link())
+			return
+		}
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message, false); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.JSONError(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -1238,6 +1268,43 @@ func CancelAutoMergePullRequest(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
 }
 
+// CancelMergeQueuePullRequest removes a pull request from the merge queue
+func CancelMergeQueuePullRequest(ctx *context.Context) {
+	issue, ok := getPullInfo(ctx)
+	if !ok {
+		return
+	}
+	pr := issue.PullRequest
+
+	exist, entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	} else if !exist {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue_not_queued"))
+		ctx.JSONRedirect(issue.Link())
+		return
+	}
+
+	if entry.DoerID != ctx.Doer.ID {
+		allowed, err := pull_service.IsUserAllowedToMerge(ctx, pr, ctx.Repo.Permission, ctx.Doer)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToMerge", err)
+			return
+		} else if !allowed {
+			ctx.NotFound("CancelMergeQueuePullRequest", nil)
+			return
+		}
+	}
+
+	if err := pull_service.RemoveFromMergeQueue(ctx, pr, ctx.Doer, pull_service.MergeQueueRemovedCanceled); err != nil {
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue_removed"))
+	ctx.JSONRedirect(issue.Link())
+}
+
 func stopTimerIfAvailable(ctx *context.Context, user *user_model.User, issue *issues_model.Issue) error {
 	if issues_model.StopwatchExists(ctx, user.ID, issue.ID) {
 		if err := issues_model.CreateOrStopIssueStopwatch(ctx, user, issue); err != nil {
@@ -0,0 +1,54 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplRepoActionsGeneral base.TplName = "repo/settings/actions"
+
+// ActionsGeneral renders the general Actions settings of the repository
+func ActionsGeneral(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.general")
+	ctx.Data["PageType"] = "general"
+	ctx.Data["PageIsSharedSettingsGeneral"] = true
+
+	ownerMode, err := actions_model.GetOwnerTokenPermissionMode(ctx, ctx.Repo.Repository.OwnerID)
+	if err != nil {
+		ctx.ServerError("GetOwnerTokenPermissionMode", err)
+		return
+	}
+	ctx.Data["OwnerTokenPermissionMode"] = string(ownerMode)
+	ctx.Data["TokenPermissionMode"] = ctx.Repo.Repository.MustGetUnit(ctx, unit.TypeActions).ActionsConfig().TokenPermissionMode
+
+	ctx.HTML(http.StatusOK, tplRepoActionsGeneral)
+}
+
+// ActionsGeneralPost updates the general Actions settings of the repository
+func ActionsGeneralPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ActionsTokenPermissionForm)
+
+	if err := actions_service.SetRepoTokenPermissionMode(ctx, ctx.Repo.Repository, actions_model.TokenPermissionMode(form.Mode)); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("actions.general.token_permissions.invalid"))
+		} else {
+			ctx.ServerError("SetRepoTokenPermissionMode", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.general.token_permissions.success"))
+	}
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/actions/general")
+}
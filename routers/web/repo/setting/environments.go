@@ -0,0 +1,333 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	secret_model "code.gitea.io/gitea/models/secret"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/forms"
+	secret_service "code.gitea.io/gitea/services/secrets"
+)
+
+const (
+	tplRepoEnvironments base.TplName = "repo/settings/actions"
+	tplRepoEnvironment  base.TplName = "repo/settings/environment"
+)
+
+// Environments render the deployment environments of the repository
+func Environments(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("actions.environments")
+	ctx.Data["PageType"] = "environments"
+	ctx.Data["PageIsSharedSettingsEnvironments"] = true
+
+	envs, err := db.Find[actions_model.ActionEnvironment](ctx, actions_model.FindEnvironmentsOptions{RepoID: ctx.Repo.Repository.ID})
+	if err != nil {
+		ctx.ServerError("FindEnvironments", err)
+		return
+	}
+	ctx.Data["Environments"] = envs
+
+	ctx.HTML(http.StatusOK, tplRepoEnvironments)
+}
+
+// EnvironmentCreate creates a new environment without protection rules
+func EnvironmentCreate(ctx *context.Context) {
+	if ctx.HasError() {
+		ctx.JSONError(ctx.GetErrMsg())
+		return
+	}
+	form := web.GetForm(ctx).(*forms.CreateEnvironmentForm)
+
+	env, err := actions_service.CreateEnvironment(ctx, ctx.Repo.Repository, form.Name, &actions_service.EnvironmentOptions{})
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrAlreadyExist):
+			ctx.JSONError(ctx.Tr("actions.environments.creation.already_exists", form.Name))
+		case errors.Is(err, util.ErrInvalidArgument):
+			ctx.JSONError(ctx.Tr("actions.environments.creation.invalid_name"))
+		default:
+			log.Error("CreateEnvironment: %v", err)
+			ctx.JSONError(ctx.Tr("actions.environments.creation.failed"))
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.environments.creation.success", env.Name))
+	ctx.JSONRedirect(environmentLink(ctx, env))
+}
+
+func environmentLink(ctx *context.Context, env *actions_model.ActionEnvironment) string {
+	return fmt.Sprintf("%s/settings/actions/environments/%d", ctx.Repo.RepoLink, env.ID)
+}
+
+// getEnvironment loads the environment from the path and prepares the common data of the environment pages
+func getEnvironment(ctx *context.Context) *actions_model.ActionEnvironment {
+	env, err := actions_model.GetEnvironmentByID(ctx, ctx.PathParamInt64(":environment_id"))
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound("GetEnvironmentByID", err)
+		} else {
+			ctx.ServerError("GetEnvironmentByID", err)
+		}
+		return nil
+	}
+	if env.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound("GetEnvironmentByID", nil)
+		return nil
+	}
+
+	ctx.Data["Title"] = ctx.Tr("actions.environments")
+	ctx.Data["PageIsSharedSettingsEnvironments"] = true
+	ctx.Data["Environment"] = env
+	ctx.Data["EnvironmentLink"] = environmentLink(ctx, env)
+	return env
+}
+
+// EnvironmentEdit renders the protection rules of the environment
+func EnvironmentEdit(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageType"] = "environment"
+
+	users, err := access_model.GetRepoReaders(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("Repo.Repository.GetReaders", err)
+		return
+	}
+	ctx.Data["Users"] = users
+	ctx.Data["reviewers"] = strings.Join(base.Int64sToStrings(env.RequiredReviewers), ",")
+	ctx.Data["branch_filters"] = strings.Join(env.BranchFilters, "\n")
+	ctx.Data["MaxWaitTimer"] = actions_model.MaxEnvironmentWaitTimer
+
+	ctx.HTML(http.StatusOK, tplRepoEnvironment)
+}
+
+// EnvironmentEditPost updates the protection rules of the environment
+func EnvironmentEditPost(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	form := web.GetForm(ctx).(*forms.EditEnvironmentForm)
+
+	var reviewers []*user_model.User
+	if strings.TrimSpace(form.Reviewers) != "" {
+		reviewerIDs, _ := base.StringsToInt64s(strings.Split(form.Reviewers, ","))
+		var err error
+		if reviewers, err = user_model.GetUsersByIDs(ctx, reviewerIDs); err != nil {
+			ctx.ServerError("GetUsersByIDs", err)
+			return
+		}
+	}
+
+	if err := actions_service.UpdateEnvironment(ctx, ctx.Repo.Repository, env, &actions_service.EnvironmentOptions{
+		WaitTimer:         form.WaitTimer,
+		BranchFilters:     strings.Split(form.BranchFilters, "\n"),
+		RequiredReviewers: reviewers,
+	}); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("actions.environments.update.invalid", err.Error()))
+			ctx.Redirect(environmentLink(ctx, env))
+			return
+		}
+		ctx.ServerError("UpdateEnvironment", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.environments.update.success"))
+	ctx.Redirect(environmentLink(ctx, env))
+}
+
+// EnvironmentDelete deletes the environment with its secrets, variables and deployments
+func EnvironmentDelete(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.DeleteEnvironment(ctx, env); err != nil {
+		log.Error("DeleteEnvironment: %v", err)
+		ctx.JSONError(ctx.Tr("actions.environments.deletion.failed"))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.environments.deletion.success"))
+	ctx.JSONRedirect(ctx.Repo.RepoLink + "/settings/actions/environments")
+}
+
+// EnvironmentSecrets renders the secrets of the environment
+func EnvironmentSecrets(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageType"] = "secrets"
+
+	secrets, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{RepoID: env.RepoID, EnvironmentID: env.ID})
+	if err != nil {
+		ctx.ServerError("FindSecrets", err)
+		return
+	}
+	ctx.Data["Secrets"] = secrets
+
+	ctx.HTML(http.StatusOK, tplRepoEnvironment)
+}
+
+// EnvironmentSecretsPost creates or updates a secret of the environment
+func EnvironmentSecretsPost(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.JSONError(ctx.GetErrMsg())
+		return
+	}
+	form := web.GetForm(ctx).(*forms.AddSecretForm)
+
+	s, _, err := secret_service.CreateOrUpdateEnvironmentSecret(ctx, env, form.Name, util.ReserveLineBreakForTextarea(form.Data))
+	if err != nil {
+		log.Error("CreateOrUpdateEnvironmentSecret failed: %v", err)
+		ctx.JSONError(ctx.Tr("secrets.creation.failed"))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("secrets.creation.success", s.Name))
+	ctx.JSONRedirect(environmentLink(ctx, env) + "/secrets")
+}
+
+// EnvironmentSecretsDelete deletes a secret of the environment
+func EnvironmentSecretsDelete(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	id := ctx.FormInt64("id")
+
+	if err := secret_service.DeleteEnvironmentSecret(ctx, env, id, ""); err != nil {
+		log.Error("DeleteEnvironmentSecret(%d) failed: %v", id, err)
+		ctx.JSONError(ctx.Tr("secrets.deletion.failed"))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("secrets.deletion.success"))
+	ctx.JSONRedirect(environmentLink(ctx, env) + "/secrets")
+}
+
+// EnvironmentVariables renders the variables of the environment
+func EnvironmentVariables(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageType"] = "variables"
+
+	variables, err := db.Find[actions_model.ActionVariable](ctx, actions_model.FindVariablesOpts{RepoID: env.RepoID, EnvironmentID: env.ID})
+	if err != nil {
+		ctx.ServerError("FindVariables", err)
+		return
+	}
+	ctx.Data["Variables"] = variables
+
+	ctx.HTML(http.StatusOK, tplRepoEnvironment)
+}
+
+// EnvironmentVariableCreate creates a variable of the environment
+func EnvironmentVariableCreate(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() { // form binding validation error
+		ctx.JSONError(ctx.GetErrMsg())
+		return
+	}
+	form := web.GetForm(ctx).(*forms.EditVariableForm)
+
+	v, err := actions_service.CreateEnvironmentVariable(ctx, env, form.Name, form.Data)
+	if err != nil {
+		log.Error("CreateEnvironmentVariable: %v", err)
+		ctx.JSONError(ctx.Tr("actions.variables.creation.failed"))
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.variables.creation.success", v.Name))
+	ctx.JSONRedirect(environmentLink(ctx, env) + "/variables")
+}
+
+// getEnvironmentVariable checks whether the variable in the path belongs to the environment
+func getEnvironmentVariable(ctx *context.Context, env *actions_model.ActionEnvironment) *actions_model.ActionVariable {
+	id := ctx.PathParamInt64(":variable_id")
+	v, exist, err := db.GetByID[actions_model.ActionVariable](ctx, id)
+	if err != nil {
+		ctx.ServerError("GetVariableByID", err)
+		return nil
+	}
+	if !exist || v.RepoID != env.RepoID || v.EnvironmentID != env.ID {
+		ctx.JSONError(ctx.Tr("actions.variables.id_not_exist", id))
+		return nil
+	}
+	return v
+}
+
+// EnvironmentVariableUpdate updates a variable of the environment
+func EnvironmentVariableUpdate(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() { // form binding validation error
+		ctx.JSONError(ctx.GetErrMsg())
+		return
+	}
+	v := getEnvironmentVariable(ctx, env)
+	if ctx.Written() {
+		return
+	}
+	form := web.GetForm(ctx).(*forms.EditVariableForm)
+
+	if ok, err := actions_service.UpdateVariable(ctx, v.ID, form.Name, form.Data); err != nil || !ok {
+		log.Error("UpdateVariable: %v", err)
+		ctx.JSONError(ctx.Tr("actions.variables.update.failed"))
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("actions.variables.update.success"))
+	ctx.JSONRedirect(environmentLink(ctx, env) + "/variables")
+}
+
+// EnvironmentVariableDelete deletes a variable of the environment
+func EnvironmentVariableDelete(ctx *context.Context) {
+	env := getEnvironment(ctx)
+	if ctx.Written() {
+		return
+	}
+	v := getEnvironmentVariable(ctx, env)
+	if ctx.Written() {
+		return
+	}
+
+	if err := actions_service.DeleteVariableByID(ctx, v.ID); err != nil {
+		log.Error("Delete variable [%d] failed: %v", v.ID, err)
+		ctx.JSONError(ctx.Tr("actions.variables.deletion.failed"))
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("actions.variables.deletion.success"))
+	ctx.JSONRedirect(environmentLink(ctx, env) + "/variables")
+}
@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/repo"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
@@ -83,6 +84,12 @@ func SettingsProtectedBranch(c *context.Context) {
 	c.Data["status_check_contexts"] = strings.Join(rule.StatusCheckContexts, "\n")
 	contexts, _ := git_model.FindRepoRecentCommitStatusContexts(c, c.Repo.Repository.ID, 7*24*time.Hour) // Find last week status check contexts
 	c.Data["recent_status_checks"] = contexts
+	requiredContexts, err := actions_service.RequiredStatusCheckContexts(c, c.Repo.Repository)
+	if err != nil {
+		c.ServerError("RequiredStatusCheckContexts", err)
+		return
+	}
+	c.Data["RequiredStatusChecks"] = requiredContexts
 
 	if c.Repo.Owner.IsOrganization() {
 		teams, err := organization.OrgFromUser(c.Repo.Owner).TeamsWithAccessToRepo(c, c.Repo.Repository.ID, perm.AccessModeRead)
@@ -257,8 +264,16 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 	protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
 	protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 	protectBranch.BlockAdminMergeOverride = f.BlockAdminMergeOverride
+	protectBranch.EnableMergeQueue = f.EnableMergeQueue
+	protectBranch.RequireCodeOwnerApproval = f.RequireCodeOwnerApproval
+
+	// the status checks required by the organization could not be removed
+	if err := actions_service.ApplyRequiredStatusChecks(ctx, ctx.Repo.Repository, protectBranch); err != nil {
+		ctx.ServerError("ApplyRequiredStatusChecks", err)
+		return
+	}
 
-	err = git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
+	err = repository.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
 		UserIDs:          whitelistUsers,
 		TeamIDs:          whitelistTeams,
 		ForcePushUserIDs: forcePushAllowlistUsers,
@@ -312,7 +327,16 @@ func DeleteProtectedBranchRulePost(ctx *context.Context) {
 		return
 	}
 
-	if err := git_model.DeleteProtectedBranch(ctx, ctx.Repo.Repository, ruleID); err != nil {
+	if hasRequired, err := actions_service.HasRequiredStatusChecks(ctx, ctx.Repo.Repository, rule); err != nil {
+		ctx.ServerError("HasRequiredStatusChecks", err)
+		return
+	} else if hasRequired {
+		ctx.Flash.Error(ctx.Tr("repo.settings.remove_protected_branch_required_status_checks", rule.RuleName))
+		ctx.JSONRedirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
+		return
+	}
+
+	if err := repository.DeleteProtectedBranch(ctx, ctx.Repo.Repository, rule); err != nil {
 		ctx.Flash.Error(ctx.Tr("repo.settings.remove_protected_branch_failed", rule.RuleName))
 		ctx.JSONRedirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
 		return
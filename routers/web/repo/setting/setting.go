@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -66,6 +67,8 @@ func SettingsCtxData(ctx *context.Context) {
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["PartialCloneDisabled"] = setting.Git.DisablePartialClone
+	ctx.Data["PartialCloneFilterKinds"] = setting.Git.PartialCloneFilters
 
 	if ctx.Doer.IsAdmin {
 		if setting.Indexer.RepoIndexerEnabled {
@@ -111,6 +114,8 @@ func SettingsPost(ctx *context.Context) {
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
 	ctx.Data["SigningSettings"] = setting.Repository.Signing
 	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["PartialCloneDisabled"] = setting.Git.DisablePartialClone
+	ctx.Data["PartialCloneFilterKinds"] = setting.Git.PartialCloneFilters
 
 	repo := ctx.Repo.Repository
 
@@ -638,6 +643,42 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "partial_clone":
+		if setting.Git.DisablePartialClone {
+			ctx.NotFound("", nil)
+			return
+		}
+
+		var filters []string
+		for _, filter := range ctx.FormStrings("partial_clone_filters") {
+			if slices.Contains(setting.Git.PartialCloneFilters, filter) && !slices.Contains(filters, filter) {
+				filters = append(filters, filter)
+			}
+		}
+		if form.EnablePartialClone && len(filters) == 0 {
+			ctx.Flash.Error(ctx.Tr("repo.settings.partial_clone.filters_empty"))
+			ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+			return
+		}
+		// the repository follows the instance configuration if all its filters are allowed
+		if len(filters) == len(setting.Git.PartialCloneFilters) {
+			filters = nil
+		}
+
+		repo.DisablePartialClone = !form.EnablePartialClone
+		if form.EnablePartialClone {
+			repo.PartialCloneFilters = filters
+		}
+
+		if err := repo_model.UpdateRepositoryCols(ctx, repo, "disable_partial_clone", "partial_clone_filters"); err != nil {
+			ctx.ServerError("UpdateRepositoryCols", err)
+			return
+		}
+		log.Trace("Repository partial clone settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
 	case "admin":
 		if !ctx.Doer.IsAdmin {
 			ctx.Error(http.StatusForbidden)
@@ -0,0 +1,68 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package audit
+
+import (
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/context"
+)
+
+const pageSize = 50
+
+// parseDate returns the start of the day of the date in the UI location, or 0 if it's empty or invalid
+func parseDate(value string) timeutil.TimeStamp {
+	t, err := time.ParseInLocation(time.DateOnly, value, setting.DefaultUILocation)
+	if err != nil {
+		return 0
+	}
+	return timeutil.TimeStamp(t.Unix())
+}
+
+// Events prepares the data of the audit log pages, the events of the owner and the repository
+// are filtered by the query of the request, both are 0 for all the events
+func Events(ctx *context.Context, ownerID, repoID int64) {
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	opts := audit_model.FindEventsOptions{
+		ListOptions: db.ListOptions{Page: page, PageSize: pageSize},
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+		Action:      audit_model.Action(ctx.FormTrim("action")),
+		ActorName:   ctx.FormTrim("actor"),
+		TargetType:  audit_model.TargetType(ctx.FormTrim("target_type")),
+		Since:       parseDate(ctx.FormTrim("since")),
+	}
+	// the events of the "until" day are included
+	if until := parseDate(ctx.FormTrim("until")); until != 0 {
+		opts.Before = until.AddDuration(24 * time.Hour)
+	}
+
+	events, count, err := db.FindAndCount[audit_model.Event](ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+
+	ctx.Data["AuditEvents"] = events
+	ctx.Data["Total"] = count
+	ctx.Data["AuditActions"] = audit_model.Actions
+	ctx.Data["AuditTargetTypes"] = audit_model.TargetTypes
+	ctx.Data["FilterAction"] = opts.Action
+	ctx.Data["FilterActor"] = opts.ActorName
+	ctx.Data["FilterTargetType"] = opts.TargetType
+	ctx.Data["FilterSince"] = ctx.FormTrim("since")
+	ctx.Data["FilterUntil"] = ctx.FormTrim("until")
+
+	pager := context.NewPagination(int(count), pageSize, page, 5)
+	pager.AddParamFromRequest(ctx.Req)
+	ctx.Data["Page"] = pager
+}
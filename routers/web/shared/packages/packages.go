@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/services/forms"
 	cargo_service "code.gitea.io/gitea/services/packages/cargo"
 	container_service "code.gitea.io/gitea/services/packages/container"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 )
 
 func SetPackagesContext(ctx *context.Context, owner *user_model.User) {
@@ -29,6 +30,15 @@ func SetPackagesContext(ctx *context.Context, owner *user_model.User) {
 	}
 
 	ctx.Data["CleanupRules"] = pcrs
+
+	prs, err := packages_model.GetRemotesByOwner(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetRemotesByOwner", err)
+		return
+	}
+
+	ctx.Data["PackageRemotes"] = prs
+	ctx.Data["RemoteTypes"] = packages_model.RemoteTypes
 }
 
 func SetRuleAddContext(ctx *context.Context) {
@@ -245,3 +255,67 @@ func RebuildCargoIndex(ctx *context.Context, owner *user_model.User) {
 		ctx.Flash.Success(ctx.Tr("packages.owner.settings.cargo.rebuild.success"))
 	}
 }
+
+func AddRemote(ctx *context.Context, owner *user_model.User, redirectURL string) {
+	form := web.GetForm(ctx).(*forms.PackageRemoteForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	if err := remote_service.ValidateURL(form.URL); err != nil {
+		ctx.Flash.Error(ctx.Tr("packages.owner.settings.remotes.error.url"))
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	packageType := packages_model.Type(form.Type)
+
+	has, err := packages_model.HasOwnerRemoteForPackageType(ctx, owner.ID, packageType)
+	if err != nil {
+		ctx.ServerError("HasOwnerRemoteForPackageType", err)
+		return
+	}
+	if has {
+		ctx.Flash.Error(ctx.Tr("packages.owner.settings.remotes.error.exists"))
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	if _, err := packages_model.InsertRemote(ctx, &packages_model.PackageRemote{
+		OwnerID: owner.ID,
+		Type:    packageType,
+		URL:     form.URL,
+	}); err != nil {
+		ctx.ServerError("InsertRemote", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("packages.owner.settings.remotes.success.add"))
+	ctx.Redirect(redirectURL)
+}
+
+func DeleteRemote(ctx *context.Context, owner *user_model.User, redirectURL string) {
+	pr, err := packages_model.GetRemoteByID(ctx, ctx.PathParamInt64("id"))
+	if err != nil {
+		if err == packages_model.ErrPackageRemoteNotExist {
+			ctx.NotFound("", err)
+		} else {
+			ctx.ServerError("GetRemoteByID", err)
+		}
+		return
+	}
+	if pr.OwnerID != owner.ID {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	if err := packages_model.DeleteRemoteByID(ctx, pr.ID); err != nil {
+		ctx.ServerError("DeleteRemoteByID", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("packages.owner.settings.remotes.success.delete"))
+	ctx.Redirect(redirectURL)
+}
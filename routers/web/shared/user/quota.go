@@ -0,0 +1,60 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user
+
+import (
+	quota_model "code.gitea.io/gitea/models/quota"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/context"
+)
+
+type quotaRuleUsage struct {
+	*quota_model.Rule
+	Used     int64
+	Percent  int64
+	Exceeded bool
+}
+
+type quotaGroupUsage struct {
+	Name  string
+	Rules []*quotaRuleUsage
+}
+
+// StorageQuota prepares the storage used by the owner and its quota groups
+func StorageQuota(ctx *context.Context, owner *user_model.User) {
+	used, err := quota_model.GetUsedForUser(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetUsedForUser", err)
+		return
+	}
+	groups, err := quota_model.GetGroupsOfUser(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetGroupsOfUser", err)
+		return
+	}
+
+	groupUsages := make([]*quotaGroupUsage, 0, len(groups))
+	for _, group := range groups {
+		groupUsage := &quotaGroupUsage{Name: group.Name}
+		for _, rule := range group.Rules {
+			ruleUsage := &quotaRuleUsage{
+				Rule:     rule,
+				Used:     rule.Sum(used),
+				Exceeded: !rule.Acceptable(used),
+			}
+			if rule.Limit > 0 {
+				ruleUsage.Percent = min(ruleUsage.Used*100/rule.Limit, 100)
+			} else if rule.Limit == 0 {
+				ruleUsage.Percent = 100
+			}
+			groupUsage.Rules = append(groupUsage.Rules, ruleUsage)
+		}
+		groupUsages = append(groupUsages, groupUsage)
+	}
+
+	ctx.Data["QuotaEnabled"] = setting.Quota.Enabled
+	ctx.Data["QuotaUsed"] = used
+	ctx.Data["QuotaGroups"] = groupUsages
+}
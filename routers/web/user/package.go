@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/optional"
 	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
 	arch_module "code.gitea.io/gitea/modules/packages/arch"
+	container_module "code.gitea.io/gitea/modules/packages/container"
 	debian_module "code.gitea.io/gitea/modules/packages/debian"
 	rpm_module "code.gitea.io/gitea/modules/packages/rpm"
 	"code.gitea.io/gitea/modules/setting"
@@ -262,6 +263,24 @@ func ViewPackageVersion(ctx *context.Context) {
 
 		ctx.Data["Groups"] = util.Sorted(groups.Values())
 		ctx.Data["Architectures"] = util.Sorted(architectures.Values())
+	case packages_model.TypeContainer:
+		if pfd := container_model.GetManifestFileDescriptor(pd); pfd != nil {
+			pvs, err := container_model.GetReferrers(ctx, &container_model.ReferrerSearchOptions{
+				OwnerID: pd.Owner.ID,
+				Image:   pd.Package.LowerName,
+				Subject: pfd.Properties.GetByName(container_module.PropertyDigest),
+			})
+			if err != nil {
+				ctx.ServerError("GetReferrers", err)
+				return
+			}
+			referrers, err := packages_model.GetPackageDescriptors(ctx, pvs)
+			if err != nil {
+				ctx.ServerError("GetPackageDescriptors", err)
+				return
+			}
+			ctx.Data["Referrers"] = referrers
+		}
 	}
 
 	var (
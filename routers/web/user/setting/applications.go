@@ -7,14 +7,18 @@ package setting
 import (
 	"net/http"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
+
+	"xorm.io/builder"
 )
 
 const (
@@ -73,6 +77,8 @@ func ApplicationsPost(ctx *context.Context) {
 		return
 	}
 
+	audit.Record(ctx, audit_model.ActionAccessTokenCreate, ctx.Doer, audit.OwnerScope(ctx.Doer.ID), audit.AccessTokenTarget(t), nil, audit.AccessTokenState(t))
+
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
 
@@ -81,9 +87,16 @@ func ApplicationsPost(ctx *context.Context) {
 
 // DeleteApplication response for delete user access token
 func DeleteApplication(ctx *context.Context) {
+	t, _, err := db.Get[auth_model.AccessToken](ctx, builder.Eq{"id": ctx.FormInt64("id"), "uid": ctx.Doer.ID})
+	if err != nil {
+		ctx.ServerError("GetAccessToken", err)
+		return
+	}
+
 	if err := auth_model.DeleteAccessTokenByID(ctx, ctx.FormInt64("id"), ctx.Doer.ID); err != nil {
 		ctx.Flash.Error("DeleteAccessTokenByID: " + err.Error())
 	} else {
+		audit.Record(ctx, audit_model.ActionAccessTokenDelete, ctx.Doer, audit.OwnerScope(ctx.Doer.ID), audit.AccessTokenTarget(t), audit.AccessTokenState(t), nil)
 		ctx.Flash.Success(ctx.Tr("settings.delete_token_success"))
 	}
 
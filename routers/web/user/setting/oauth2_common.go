@@ -53,6 +53,7 @@ func (oa *OAuth2CommonHandlers) AddApp(ctx *context.Context) {
 		UserID:                     oa.OwnerID,
 		ConfidentialClient:         form.ConfidentialClient,
 		SkipSecondaryAuthorization: form.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           form.EnableDeviceFlow,
 	})
 	if err != nil {
 		ctx.ServerError("CreateOAuth2Application", err)
@@ -122,6 +123,7 @@ func (oa *OAuth2CommonHandlers) EditSave(ctx *context.Context) {
 		UserID:                     oa.OwnerID,
 		ConfidentialClient:         form.ConfidentialClient,
 		SkipSecondaryAuthorization: form.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           form.EnableDeviceFlow,
 	}); err != nil {
 		ctx.ServerError("UpdateOAuth2Application", err)
 		return
@@ -106,6 +106,14 @@ func RebuildCargoIndex(ctx *context.Context) {
 	ctx.Redirect(setting.AppSubURL + "/user/settings/packages")
 }
 
+func PackagesRemoteAddPost(ctx *context.Context) {
+	shared.AddRemote(ctx, ctx.Doer, setting.AppSubURL+"/user/settings/packages")
+}
+
+func PackagesRemoteDeletePost(ctx *context.Context) {
+	shared.DeleteRemote(ctx, ctx.Doer, setting.AppSubURL+"/user/settings/packages")
+}
+
 func RegenerateChefKeyPair(ctx *context.Context) {
 	priv, pub, err := util.GenerateKeyPair(chef_module.KeyBits)
 	if err != nil {
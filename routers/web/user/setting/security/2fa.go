@@ -12,11 +12,13 @@ import (
 	"net/http"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
 
@@ -55,11 +57,17 @@ func RegenerateScratchTwoFactor(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to UpdateTwoFactor", err)
 		return
 	}
+	recordTwoFactorEvent(ctx, audit_model.ActionTwoFactorRegenerate, t.ID)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_scratch_token_regenerated", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
 
+func recordTwoFactorEvent(ctx *context.Context, action audit_model.Action, twoFactorID int64) {
+	target := audit.Target{Type: audit_model.TargetTwoFactor, ID: twoFactorID, Name: ctx.Doer.Name}
+	audit.Record(ctx, action, ctx.Doer, audit.OwnerScope(ctx.Doer.ID), target, nil, nil)
+}
+
 // DisableTwoFactor deletes the user's 2FA settings.
 func DisableTwoFactor(ctx *context.Context) {
 	if user_model.IsFeatureDisabledWithLoginType(ctx.Doer, setting.UserFeatureManageMFA) {
@@ -91,6 +99,7 @@ func DisableTwoFactor(ctx *context.Context) {
 		}
 		return
 	}
+	recordTwoFactorEvent(ctx, audit_model.ActionTwoFactorDisable, t.ID)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_disabled"))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
@@ -267,6 +276,7 @@ func EnrollTwoFactorPost(ctx *context.Context) {
 		ctx.ServerError("SettingsTwoFactor: Failed to save two factor", err)
 		return
 	}
+	recordTwoFactorEvent(ctx, audit_model.ActionTwoFactorEnable, t.ID)
 
 	ctx.Flash.Success(ctx.Tr("settings.twofa_enrolled", token))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
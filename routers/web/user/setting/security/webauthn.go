@@ -9,12 +9,14 @@ import (
 	"strconv"
 	"time"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
 	wa "code.gitea.io/gitea/modules/auth/webauthn"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
 
@@ -114,11 +116,13 @@ func WebauthnRegisterPost(ctx *context.Context) {
 	}
 
 	// Create the credential
-	_, err = auth.CreateCredential(ctx, ctx.Doer.ID, name, cred)
+	dbCred, err = auth.CreateCredential(ctx, ctx.Doer.ID, name, cred)
 	if err != nil {
 		ctx.ServerError("CreateCredential", err)
 		return
 	}
+	target := audit.Target{Type: audit_model.TargetWebAuthn, ID: dbCred.ID, Name: dbCred.Name}
+	audit.Record(ctx, audit_model.ActionWebAuthnAdd, ctx.Doer, audit.OwnerScope(ctx.Doer.ID), target, nil, nil)
 	_ = ctx.Session.Delete("webauthnName")
 
 	ctx.JSON(http.StatusCreated, cred)
@@ -132,9 +136,14 @@ func WebauthnDelete(ctx *context.Context) {
 	}
 
 	form := web.GetForm(ctx).(*forms.WebauthnDeleteForm)
-	if _, err := auth.DeleteCredential(ctx, form.ID, ctx.Doer.ID); err != nil {
+	deleted, err := auth.DeleteCredential(ctx, form.ID, ctx.Doer.ID)
+	if err != nil {
 		ctx.ServerError("GetWebAuthnCredentialByID", err)
 		return
 	}
+	if deleted {
+		target := audit.Target{Type: audit_model.TargetWebAuthn, ID: form.ID}
+		audit.Record(ctx, audit_model.ActionWebAuthnRemove, ctx.Doer, audit.OwnerScope(ctx.Doer.ID), target, nil, nil)
+	}
 	ctx.JSONRedirect(setting.AppSubURL + "/user/settings/security")
 }
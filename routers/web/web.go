@@ -284,6 +284,9 @@ func Routes() *web.Router {
 	mid = append(mid, repo.GetActiveStopwatch)
 	mid = append(mid, goGet)
 
+	// Reject the changes and show the banner while the instance is in the maintenance mode
+	mid = append(mid, maintenanceMode)
+
 	others := web.NewRouter()
 	others.Use(mid...)
 	registerRoutes(others)
@@ -457,6 +460,29 @@ func registerRoutes(m *web.Router) {
 		})
 	}
 
+	addSettingsEnvironmentsRoutes := func() {
+		m.Group("/environments", func() {
+			m.Get("", repo_setting.Environments)
+			m.Post("/new", web.Bind(forms.CreateEnvironmentForm{}), repo_setting.EnvironmentCreate)
+			m.Group("/{environment_id}", func() {
+				m.Combo("").Get(repo_setting.EnvironmentEdit).
+					Post(web.Bind(forms.EditEnvironmentForm{}), repo_setting.EnvironmentEditPost)
+				m.Post("/delete", repo_setting.EnvironmentDelete)
+				m.Group("/secrets", func() {
+					m.Get("", repo_setting.EnvironmentSecrets)
+					m.Post("", web.Bind(forms.AddSecretForm{}), repo_setting.EnvironmentSecretsPost)
+					m.Post("/delete", repo_setting.EnvironmentSecretsDelete)
+				})
+				m.Group("/variables", func() {
+					m.Get("", repo_setting.EnvironmentVariables)
+					m.Post("/new", web.Bind(forms.EditVariableForm{}), repo_setting.EnvironmentVariableCreate)
+					m.Post("/{variable_id}/edit", web.Bind(forms.EditVariableForm{}), repo_setting.EnvironmentVariableUpdate)
+					m.Post("/{variable_id}/delete", repo_setting.EnvironmentVariableDelete)
+				})
+			})
+		})
+	}
+
 	addSettingsRunnersRoutes := func() {
 		m.Group("/runners", func() {
 			m.Get("", repo_setting.Runners)
@@ -482,6 +508,7 @@ func registerRoutes(m *web.Router) {
 			ctx.Redirect(setting.AppSubURL + "/user/settings/account")
 		})
 		m.Get("/passkey-endpoints", passkeyEndpoints)
+		m.Get("/terraform.json", packagesEnabled, terraformServices)
 		m.Methods("GET, HEAD", "/*", public.FileHandlerFunc())
 	}, optionsCorsHandler())
 
@@ -563,8 +590,12 @@ func registerRoutes(m *web.Router) {
 			m.Post("/authorize", web.Bind(forms.AuthorizationForm{}), auth.AuthorizeOAuth)
 		}, optSignInIgnoreCsrf, reqSignIn)
 
+		m.Get("/device", reqSignIn, auth.DeviceVerification)
+		m.Post("/device", reqSignIn, web.Bind(forms.DeviceVerificationForm{}), auth.DeviceVerificationPost)
+
 		m.Methods("GET, POST, OPTIONS", "/userinfo", optionsCorsHandler(), optSignInIgnoreCsrf, auth.InfoOAuth)
 		m.Methods("POST, OPTIONS", "/access_token", optionsCorsHandler(), web.Bind(forms.AccessTokenForm{}), optSignInIgnoreCsrf, auth.AccessTokenOAuth)
+		m.Methods("POST, OPTIONS", "/device_authorization", optionsCorsHandler(), web.Bind(forms.DeviceAuthorizationForm{}), optSignInIgnoreCsrf, auth.DeviceAuthorizationOAuth)
 		m.Methods("GET, OPTIONS", "/keys", optionsCorsHandler(), optSignInIgnoreCsrf, auth.OIDCKeys)
 		m.Methods("POST, OPTIONS", "/introspect", optionsCorsHandler(), web.Bind(forms.IntrospectTokenForm{}), optSignInIgnoreCsrf, auth.IntrospectOAuth)
 	}, oauth2Enabled)
@@ -646,6 +677,10 @@ func registerRoutes(m *web.Router) {
 				m.Post("/initialize", user_setting.InitializeCargoIndex)
 				m.Post("/rebuild", user_setting.RebuildCargoIndex)
 			})
+			m.Group("/remotes", func() {
+				m.Post("/add", web.Bind(forms.PackageRemoteForm{}), user_setting.PackagesRemoteAddPost)
+				m.Post("/{id}/delete", user_setting.PackagesRemoteDeletePost)
+			})
 			m.Post("/chef/regenerate_keypair", user_setting.RegenerateChefKeyPair)
 		}, packagesEnabled)
 
@@ -675,6 +710,8 @@ func registerRoutes(m *web.Router) {
 			m.Get("", user_setting.BlockedUsers)
 			m.Post("", web.Bind(forms.BlockUserForm{}), user_setting.BlockedUsersPost)
 		})
+
+		m.Get("/storage", user_setting.Storage)
 	}, reqSignIn, ctxDataSet("PageIsUserSettings", true, "EnablePackages", setting.Packages.Enabled))
 
 	m.Group("/user", func() {
@@ -693,6 +730,12 @@ func registerRoutes(m *web.Router) {
 			m.Get("/{provider}", auth.SignInOAuth)
 			m.Get("/{provider}/callback", auth.SignInOAuthCallback)
 		})
+		m.Group("/saml", func() {
+			m.Get("/{provider}", auth.SignInSAML)
+			m.Get("/{provider}/metadata", auth.SAMLMetadata)
+			// the identity provider posts the response cross-site, so it can't carry a CSRF token
+			m.Post("/{provider}/acs", optSignInIgnoreCsrf, auth.SignInSAMLCallback)
+		})
 	})
 	// ***** END: User *****
 
@@ -754,6 +797,7 @@ func registerRoutes(m *web.Router) {
 		m.Group("/repos", func() {
 			m.Get("", admin.Repos)
 			m.Combo("/unadopted").Get(admin.UnadoptedRepos).Post(admin.AdoptOrDeleteRepository)
+			m.Combo("/housekeeping").Get(admin.RepoHousekeeping).Post(admin.RunRepoHousekeeping)
 			m.Post("/delete", admin.DeleteRepo)
 		})
 
@@ -791,6 +835,8 @@ func registerRoutes(m *web.Router) {
 			m.Post("/empty", admin.EmptyNotices)
 		})
 
+		m.Get("/audit", admin.Audit)
+
 		m.Group("/applications", func() {
 			m.Get("", admin.Applications)
 			m.Post("/oauth2", web.Bind(forms.EditOAuth2ApplicationForm{}), admin.ApplicationsPost)
@@ -946,9 +992,19 @@ func registerRoutes(m *web.Router) {
 
 				m.Group("/actions", func() {
 					m.Get("", org_setting.RedirectToDefaultSetting)
+					m.Combo("/general").Get(org_setting.ActionsGeneral).
+						Post(web.Bind(forms.ActionsTokenPermissionForm{}), org_setting.ActionsGeneralPost)
 					addSettingsRunnersRoutes()
 					addSettingsSecretsRoutes()
 					addSettingsVariablesRoutes()
+					m.Group("/required_workflows", func() {
+						m.Get("", org_setting.RequiredWorkflows)
+						m.Post("/new", web.Bind(forms.RequiredWorkflowForm{}), org_setting.RequiredWorkflowCreate)
+						m.Group("/{id}", func() {
+							m.Post("/edit", web.Bind(forms.RequiredWorkflowForm{}), org_setting.RequiredWorkflowEdit)
+							m.Post("/delete", org_setting.RequiredWorkflowDelete)
+						})
+					})
 				}, actions.MustEnableActions)
 
 				m.Methods("GET,POST", "/delete", org.SettingsDelete)
@@ -970,12 +1026,19 @@ func registerRoutes(m *web.Router) {
 						m.Post("/initialize", org.InitializeCargoIndex)
 						m.Post("/rebuild", org.RebuildCargoIndex)
 					})
+					m.Group("/remotes", func() {
+						m.Post("/add", web.Bind(forms.PackageRemoteForm{}), org.PackagesRemoteAddPost)
+						m.Post("/{id}/delete", org.PackagesRemoteDeletePost)
+					})
 				}, packagesEnabled)
 
 				m.Group("/blocked_users", func() {
 					m.Get("", org.BlockedUsers)
 					m.Post("", web.Bind(forms.BlockUserForm{}), org.BlockedUsersPost)
 				})
+
+				m.Get("/storage", org.Storage)
+				m.Get("/audit", org.Audit)
 			}, ctxDataSet("EnableOAuth2", setting.OAuth2.Enabled, "EnablePackages", setting.Packages.Enabled, "PageIsOrgSettings", true))
 		}, context.OrgAssignment(true, true))
 	}, reqSignIn)
@@ -1074,6 +1137,8 @@ func registerRoutes(m *web.Router) {
 			})
 		})
 
+		m.Get("/audit", repo_setting.Audit)
+
 		m.Group("/branches", func() {
 			m.Post("/", repo_setting.SetDefaultBranchPost)
 		}, repo.MustBeNotEmpty)
@@ -1133,9 +1198,12 @@ func registerRoutes(m *web.Router) {
 		})
 		m.Group("/actions", func() {
 			m.Get("", repo_setting.RedirectToDefaultSetting)
+			m.Combo("/general").Get(repo_setting.ActionsGeneral).
+				Post(web.Bind(forms.ActionsTokenPermissionForm{}), repo_setting.ActionsGeneralPost)
 			addSettingsRunnersRoutes()
 			addSettingsSecretsRoutes()
 			addSettingsVariablesRoutes()
+			addSettingsEnvironmentsRoutes()
 		}, actions.MustEnableActions)
 		// the follow handler must be under "settings", otherwise this incomplete repo can't be accessed
 		m.Group("/migrate", func() {
@@ -1432,6 +1500,10 @@ func registerRoutes(m *web.Router) {
 		m.Group("/workflows/{workflow_name}", func() {
 			m.Get("/badge.svg", actions.GetWorkflowBadge)
 		})
+		m.Group("/deployments", func() {
+			m.Get("", actions.Deployments)
+			m.Post("/{deployment_id}/review", reqSignIn, web.Bind(forms.ReviewDeploymentForm{}), actions.ReviewDeployment)
+		})
 	}, optSignIn, context.RepoAssignment, reqRepoActionsReader, actions.MustEnableActions)
 	// end "/{username}/{reponame}/actions"
 
@@ -1484,6 +1556,7 @@ func registerRoutes(m *web.Router) {
 			})
 			m.Post("/merge", context.RepoMustNotBeArchived(), web.Bind(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
+			m.Post("/cancel_merge_queue", context.RepoMustNotBeArchived(), repo.CancelMergeQueuePullRequest)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/set_allow_maintainer_edit", web.Bind(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
@@ -25,6 +25,8 @@ type actionsClaims struct {
 	Ac     string `json:"ac"`
 }
 
+const idTokenRequestScope = "Actions.IDToken"
+
 type actionsCacheScope struct {
 	Scope      string
 	Permission actionsCachePermission
@@ -71,6 +73,26 @@ func CreateAuthorizationToken(taskID, runID, jobID int64) (string, error) {
 	return tokenString, nil
 }
 
+// CreateIDTokenRequestToken creates the token which the task uses to request OIDC ID tokens,
+// it's only issued to the tasks of the jobs with the `id-token: write` permission.
+func CreateIDTokenRequestToken(taskID, runID, jobID int64) (string, error) {
+	now := time.Now()
+
+	claims := actionsClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+		Scp:    fmt.Sprintf("%s:%d:%d", idTokenRequestScope, runID, jobID),
+		TaskID: taskID,
+		RunID:  runID,
+		JobID:  jobID,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString(setting.GetGeneralTokenSigningSecret())
+}
+
 func ParseAuthorizationToken(req *http.Request) (int64, error) {
 	h := req.Header.Get("Authorization")
 	if h == "" {
@@ -88,6 +110,26 @@ func ParseAuthorizationToken(req *http.Request) (int64, error) {
 
 // TokenToTaskID returns the TaskID associated with the provided JWT token
 func TokenToTaskID(token string) (int64, error) {
+	c, err := parseActionsClaims(token)
+	if err != nil {
+		return 0, err
+	}
+	return c.TaskID, nil
+}
+
+// IDTokenRequestTokenToTaskID returns the TaskID associated with the provided token created by CreateIDTokenRequestToken
+func IDTokenRequestTokenToTaskID(token string) (int64, error) {
+	c, err := parseActionsClaims(token)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(c.Scp, idTokenRequestScope+":") {
+		return 0, fmt.Errorf("invalid token scope")
+	}
+	return c.TaskID, nil
+}
+
+func parseActionsClaims(token string) (*actionsClaims, error) {
 	parsedToken, err := jwt.ParseWithClaims(token, &actionsClaims{}, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
@@ -95,13 +137,13 @@ func TokenToTaskID(token string) (int64, error) {
 		return setting.GetGeneralTokenSigningSecret(), nil
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	c, ok := parsedToken.Claims.(*actionsClaims)
 	if !parsedToken.Valid || !ok {
-		return 0, fmt.Errorf("invalid token claim")
+		return nil, fmt.Errorf("invalid token claim")
 	}
 
-	return c.TaskID, nil
+	return c, nil
 }
@@ -62,3 +62,18 @@ func TestParseAuthorizationTokenNoAuthHeader(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(0), rTaskID)
 }
+
+func TestIDTokenRequestTokenToTaskID(t *testing.T) {
+	var taskID int64 = 23
+	token, err := CreateIDTokenRequestToken(taskID, 1, 2)
+	assert.NoError(t, err)
+	rTaskID, err := IDTokenRequestTokenToTaskID(token)
+	assert.NoError(t, err)
+	assert.Equal(t, taskID, rTaskID)
+
+	// the runtime token isn't allowed to request ID tokens
+	token, err = CreateAuthorizationToken(taskID, 1, 2)
+	assert.NoError(t, err)
+	_, err = IDTokenRequestTokenToTaskID(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CacheWriteScope returns the scope of the caches created by the run, it's the ref of the run.
+func CacheWriteScope(run *actions_model.ActionRun) string {
+	return run.Ref
+}
+
+// CacheReadScopes returns the scopes of the caches which can be restored by the run, in order of precedence:
+// the ref of the run, the base branch if it's triggered by a pull request, and the default branch.
+// The run must have its repository loaded.
+func CacheReadScopes(run *actions_model.ActionRun) []string {
+	scopes := []string{run.Ref}
+	if payload, err := run.GetPullRequestEventPayload(); err == nil && payload.PullRequest != nil && payload.PullRequest.Base != nil {
+		scopes = append(scopes, git.BranchPrefix+payload.PullRequest.Base.Ref)
+	}
+	scopes = append(scopes, git.BranchPrefix+run.Repo.DefaultBranch)
+
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	return result
+}
+
+func cacheBlocksDir(c *actions_model.ActionCache) string {
+	return c.StoragePath() + ".blocks"
+}
+
+// CacheBlockPath returns the path of a block staged for the cache in the storage
+func CacheBlockPath(c *actions_model.ActionCache, blockID string) string {
+	return cacheBlocksDir(c) + "/" + base64.URLEncoding.EncodeToString([]byte(blockID))
+}
+
+// blocksReader reads the staged blocks one by one, so that only one of them is opened at a time
+type blocksReader struct {
+	paths   []string
+	current io.ReadCloser
+}
+
+func (r *blocksReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.paths) == 0 {
+				return 0, io.EOF
+			}
+			f, err := storage.ActionsCache.Open(r.paths[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.paths = f, r.paths[1:]
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			_ = r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *blocksReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
+
+// CommitCacheBlocks concatenates the staged blocks in order as the content of the cache, and removes the blocks
+func CommitCacheBlocks(c *actions_model.ActionCache, blockIDs []string) (int64, error) {
+	paths := make([]string, 0, len(blockIDs))
+	for _, id := range blockIDs {
+		paths = append(paths, CacheBlockPath(c, id))
+	}
+	r := &blocksReader{paths: paths}
+	defer r.Close()
+
+	size, err := storage.ActionsCache.Save(c.StoragePath(), r, -1)
+	if err != nil {
+		return 0, fmt.Errorf("commit blocks of cache %d: %w", c.ID, err)
+	}
+	deleteCacheBlocks(c)
+	return size, nil
+}
+
+func deleteCacheBlocks(c *actions_model.ActionCache) {
+	err := storage.ActionsCache.IterateObjects(cacheBlocksDir(c), func(path string, _ storage.Object) error {
+		return storage.ActionsCache.Delete(path)
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn("Failed to delete blocks of cache %d: %v", c.ID, err)
+	}
+}
+
+// DeleteCache deletes the cache entry and its content
+func DeleteCache(ctx context.Context, c *actions_model.ActionCache) error {
+	deleteCacheBlocks(c)
+	if err := storage.ActionsCache.Delete(c.StoragePath()); err != nil {
+		return fmt.Errorf("delete cache %d from storage: %w", c.ID, err)
+	}
+	return actions_model.DeleteCacheByID(ctx, c.ID)
+}
+
+// pendingCacheTimeout is the time after which an uncompleted cache is considered to be abandoned
+const pendingCacheTimeout = 24 * time.Hour
+
+// CleanupCaches evicts the caches which are abandoned or haven't been used for the retention time,
+// then the least recently used caches of the repositories exceeding the size limit.
+func CleanupCaches(ctx context.Context) error {
+	now := timeutil.TimeStampNow()
+
+	unused, err := db.Find[actions_model.ActionCache](ctx, actions_model.FindCachesOptions{
+		UsedBefore: now.AddDuration(-time.Duration(setting.Actions.CacheRetentionDays) * 24 * time.Hour),
+	})
+	if err != nil {
+		return fmt.Errorf("find unused caches: %w", err)
+	}
+	abandoned, err := db.Find[actions_model.ActionCache](ctx, actions_model.FindCachesOptions{
+		Complete:   optional.Some(false),
+		UsedBefore: now.AddDuration(-pendingCacheTimeout),
+	})
+	if err != nil {
+		return fmt.Errorf("find abandoned caches: %w", err)
+	}
+
+	count := 0
+	deleted := make(container.Set[int64])
+	for _, c := range append(unused, abandoned...) {
+		if !deleted.Add(c.ID) {
+			continue
+		}
+		if err := DeleteCache(ctx, c); err != nil {
+			log.Error("Failed to delete cache %d: %v", c.ID, err)
+			continue
+		}
+		count++
+	}
+
+	if setting.Actions.CacheMaxSizePerRepo > 0 {
+		usages, err := actions_model.GetCacheRepoUsagesExceeding(ctx, setting.Actions.CacheMaxSizePerRepo)
+		if err != nil {
+			return fmt.Errorf("get cache usages: %w", err)
+		}
+		for _, usage := range usages {
+			n, err := evictCaches(ctx, usage.RepoID, usage.Size-setting.Actions.CacheMaxSizePerRepo)
+			if err != nil {
+				log.Error("Failed to evict caches of repo %d: %v", usage.RepoID, err)
+			}
+			count += n
+		}
+	}
+
+	log.Info("Removed %d actions caches", count)
+	return nil
+}
+
+// evictCaches deletes the least recently used caches of the repository until the size to free is reached
+func evictCaches(ctx context.Context, repoID, toFree int64) (int, error) {
+	caches, err := db.Find[actions_model.ActionCache](ctx, actions_model.FindCachesOptions{
+		RepoID:   repoID,
+		Complete: optional.Some(true),
+	})
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, c := range caches {
+		if toFree <= 0 {
+			break
+		}
+		if err := DeleteCache(ctx, c); err != nil {
+			return count, err
+		}
+		toFree -= c.Size
+		count++
+	}
+	return count, nil
+}
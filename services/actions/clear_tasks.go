@@ -11,6 +11,7 @@ import (
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -68,6 +69,8 @@ func stopTasks(ctx context.Context, opts actions_model.FindTaskOptions) error {
 	}
 
 	CreateCommitStatus(ctx, jobs...)
+	NotifyWorkflowJobsStatusUpdate(ctx, jobs...)
+	emitJobsOfRuns(jobs)
 
 	return nil
 }
@@ -95,7 +98,23 @@ func CancelAbandonedJobs(ctx context.Context) error {
 			// go on
 		}
 		CreateCommitStatus(ctx, job)
+		NotifyWorkflowJobsStatusUpdate(ctx, job)
 	}
+	emitJobsOfRuns(jobs)
 
 	return nil
 }
+
+// emitJobsOfRuns emits the jobs of the runs which the stopped or cancelled jobs belong to,
+// so that the runs waiting for their concurrency groups could be woken up.
+func emitJobsOfRuns(jobs []*actions_model.ActionRunJob) {
+	runIDs := make(container.Set[int64], len(jobs))
+	for _, job := range jobs {
+		runIDs.Add(job.RunID)
+	}
+	for runID := range runIDs {
+		if err := EmitJobsIfReady(runID); err != nil {
+			log.Error("Emit ready jobs of run %d: %v", runID, err)
+		}
+	}
+}
@@ -70,6 +70,17 @@ func createCommitStatus(ctx context.Context, job *actions_model.ActionRunJob) er
 			return fmt.Errorf("head of pull request is missing in event payload")
 		}
 		sha = payload.PullRequest.Head.Sha
+	case webhook_module.HookEventMergeGroup:
+		event = string(run.Event)
+		payload, err := run.GetMergeGroupEventPayload()
+		if err != nil {
+			return fmt.Errorf("GetMergeGroupEventPayload: %w", err)
+		}
+		if payload.MergeGroup == nil {
+			return fmt.Errorf("merge group is missing in event payload")
+		}
+		// the status of the merge group commit decides whether the merge queue merges it
+		sha = payload.MergeGroup.HeadSHA
 	case webhook_module.HookEventRelease:
 		event = string(run.Event)
 		sha = run.CommitSHA
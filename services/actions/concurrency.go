@@ -0,0 +1,226 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+// generateGiteaContext generates the github context of a run which hasn't been inserted yet,
+// it's used to evaluate expressions on the server side, so only the fields known before the run starts are filled.
+func generateGiteaContext(run *actions_model.ActionRun) *model.GithubContext {
+	event := map[string]any{}
+	_ = json.Unmarshal([]byte(run.EventPayload), &event)
+
+	baseRef := ""
+	headRef := ""
+	ref := run.Ref
+	sha := run.CommitSHA
+	if pullPayload, err := run.GetPullRequestEventPayload(); err == nil && pullPayload.PullRequest != nil && pullPayload.PullRequest.Base != nil && pullPayload.PullRequest.Head != nil {
+		baseRef = pullPayload.PullRequest.Base.Ref
+		headRef = pullPayload.PullRequest.Head.Ref
+		if run.TriggerEvent == actions_module.GithubEventPullRequestTarget {
+			ref = git.BranchPrefix + pullPayload.PullRequest.Base.Name
+			sha = pullPayload.PullRequest.Base.Sha
+		}
+	}
+	refName := git.RefName(ref)
+
+	gitCtx := &model.GithubContext{
+		Event:     event,
+		EventName: run.TriggerEvent,
+		Workflow:  run.WorkflowID,
+		Sha:       sha,
+		Ref:       ref,
+		RefName:   refName.ShortName(),
+		RefType:   refName.RefType(),
+		HeadRef:   headRef,
+		BaseRef:   baseRef,
+		ServerURL: setting.AppURL,
+		APIURL:    setting.AppURL + "api/v1",
+	}
+	if run.TriggerUser != nil {
+		gitCtx.Actor = run.TriggerUser.Name
+	}
+	if run.Repo != nil {
+		gitCtx.Repository = run.Repo.OwnerName + "/" + run.Repo.Name
+		gitCtx.RepositoryOwner = run.Repo.OwnerName
+	}
+	return gitCtx
+}
+
+// jobMatrix returns the matrix of a job which has been expanded by the jobparser, every dimension has exactly one value
+func jobMatrix(job *jobparser.Job) map[string]any {
+	matrix := map[string]any{}
+	var rawMatrix map[string][]any
+	if err := job.Strategy.RawMatrix.Decode(&rawMatrix); err == nil {
+		for k, v := range rawMatrix {
+			if len(v) > 0 {
+				matrix[k] = v[0]
+			}
+		}
+	}
+	return matrix
+}
+
+// evaluateConcurrency evaluates the workflow-level concurrency of the run and the job-level concurrency of the jobs.
+// The run must have its attributes loaded, the returned slice is aligned with jobs.
+func evaluateConcurrency(run *actions_model.ActionRun, content []byte, jobs []*jobparser.SingleWorkflow, vars map[string]string) ([]*actions_model.JobConcurrency, error) {
+	wc, err := actions_module.GetConcurrencyFromContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("GetConcurrencyFromContent: %w", err)
+	}
+	gitCtx := generateGiteaContext(run)
+
+	run.ConcurrencyGroup, run.ConcurrencyCancel = wc.Workflow.Evaluate("", nil, gitCtx, vars)
+	return evaluateConcurrencyOfJobs(wc, jobs, gitCtx, vars), nil
+}
+
+// evaluateJobConcurrencies evaluates the job-level concurrency of the jobs only,
+// it's used for the jobs of the reusable workflows which don't affect the concurrency of the run.
+func evaluateJobConcurrencies(run *actions_model.ActionRun, content []byte, jobs []*jobparser.SingleWorkflow, vars map[string]string) ([]*actions_model.JobConcurrency, error) {
+	wc, err := actions_module.GetConcurrencyFromContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("GetConcurrencyFromContent: %w", err)
+	}
+	return evaluateConcurrencyOfJobs(wc, jobs, generateGiteaContext(run), vars), nil
+}
+
+func evaluateConcurrencyOfJobs(wc *actions_module.WorkflowConcurrency, jobs []*jobparser.SingleWorkflow, gitCtx *model.GithubContext, vars map[string]string) []*actions_model.JobConcurrency {
+	if len(wc.Jobs) == 0 {
+		return nil
+	}
+
+	ret := make([]*actions_model.JobConcurrency, len(jobs))
+	for i, swf := range jobs {
+		id, job := swf.Job()
+		c, ok := wc.Jobs[id]
+		if !ok || job == nil {
+			continue
+		}
+		jc := &actions_model.JobConcurrency{}
+		jc.Group, jc.Cancel = c.Evaluate(id, jobMatrix(job), gitCtx, vars)
+		if jc.Group != "" {
+			ret[i] = jc
+		}
+	}
+	return ret
+}
+
+// InsertRun evaluates the concurrency and environment settings of the workflow and inserts the run with its jobs,
+// the jobs calling reusable workflows are followed by the jobs of the called workflows.
+func InsertRun(ctx context.Context, run *actions_model.ActionRun, content []byte, jobs []*jobparser.SingleWorkflow, vars map[string]string) error {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	jobConcurrencies, err := evaluateConcurrency(run, content, jobs, vars)
+	if err != nil {
+		return err
+	}
+	jobEnvironments, err := evaluateEnvironments(run, content, jobs, vars)
+	if err != nil {
+		return err
+	}
+	jobPermissions, err := evaluatePermissions(content, jobs)
+	if err != nil {
+		return err
+	}
+	expanded, err := expandWorkflowCalls(ctx, run, jobs, jobConcurrencies, jobEnvironments, jobPermissions, vars)
+	if err != nil {
+		return err
+	}
+	if err := actions_model.InsertRun(ctx, run, expanded.jobs, expanded.concurrencies, expanded.environments, expanded.calls, expanded.permissions); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(expanded.environments, func(name string) bool { return name != "" }) ||
+		slices.ContainsFunc(expanded.calls, func(call *actions_model.JobWorkflowCall) bool { return call != nil }) {
+		// let the jobs deploying to environments without protection rules and the reusable workflow calls start
+		return EmitJobsIfReady(run.ID)
+	}
+	return nil
+}
+
+// ShouldBlockJobByConcurrency returns whether the job should stay blocked because of the concurrency group of its run or itself
+func ShouldBlockJobByConcurrency(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) (bool, error) {
+	if blocked, err := actions_model.ShouldBlockRunByConcurrency(ctx, run); err != nil || blocked {
+		return blocked, err
+	}
+	if job.ConcurrencyGroup == "" {
+		return false, nil
+	}
+	return actions_model.ShouldBlockJobByConcurrency(ctx, job)
+}
+
+// cancelConcurrentJobs cancels the other jobs in the concurrency group of a job which is about to leave the blocked status,
+// all of them if the job has cancel-in-progress set. It has to be called before ShouldBlockJobByConcurrency,
+// so the jobs cancelled in favor of the job don't block it.
+func cancelConcurrentJobs(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) error {
+	if job.ConcurrencyGroup == "" {
+		return nil
+	}
+	if blocked, err := actions_model.ShouldBlockRunByConcurrency(ctx, run); err != nil || blocked {
+		return err
+	}
+	return actions_model.CancelConcurrentJobs(ctx, job)
+}
+
+// emitConcurrentRuns emits the blocked runs which are waiting for the concurrency groups held by the given run and jobs
+func emitConcurrentRuns(ctx context.Context, run *actions_model.ActionRun, jobs []*actions_model.ActionRunJob) error {
+	runIDs := make(container.Set[int64])
+	if run.ConcurrencyGroup != "" && run.Status.IsDone() {
+		runs, err := db.Find[actions_model.ActionRun](ctx, actions_model.FindRunOptions{
+			RepoID:           run.RepoID,
+			ConcurrencyGroup: run.ConcurrencyGroup,
+			Status:           []actions_model.Status{actions_model.StatusBlocked},
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range runs {
+			runIDs.Add(r.ID)
+		}
+	}
+
+	groups := make(container.Set[string])
+	for _, job := range jobs {
+		if job.ConcurrencyGroup != "" && job.Status.IsDone() {
+			groups.Add(job.ConcurrencyGroup)
+		}
+	}
+	for group := range groups {
+		blockedJobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{
+			RepoID:           run.RepoID,
+			ConcurrencyGroup: group,
+			Statuses:         []actions_model.Status{actions_model.StatusBlocked},
+		})
+		if err != nil {
+			return err
+		}
+		for _, job := range blockedJobs {
+			runIDs.Add(job.RunID)
+		}
+	}
+
+	runIDs.Remove(run.ID)
+	for runID := range runIDs {
+		if err := pushJobUpdate(&jobUpdate{RunID: runID, Woken: true}); err != nil {
+			log.Error("EmitJobsIfReady for run %d: %v", runID, err)
+		}
+	}
+	return nil
+}
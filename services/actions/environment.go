@@ -0,0 +1,294 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+// evaluateEnvironments evaluates the names of the environments the jobs deploy to.
+// The run must have its attributes loaded, the returned slice is aligned with jobs.
+func evaluateEnvironments(run *actions_model.ActionRun, content []byte, jobs []*jobparser.SingleWorkflow, vars map[string]string) ([]string, error) {
+	envs, err := actions_module.GetEnvironmentsFromContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("GetEnvironmentsFromContent: %w", err)
+	}
+	if len(envs) == 0 {
+		return nil, nil
+	}
+	gitCtx := generateGiteaContext(run)
+
+	ret := make([]string, len(jobs))
+	for i, swf := range jobs {
+		id, job := swf.Job()
+		e, ok := envs[id]
+		if !ok || job == nil {
+			continue
+		}
+		name := strings.TrimSpace(e.Evaluate(id, jobMatrix(job), gitCtx, vars))
+		name, _ = util.SplitStringAtByteN(name, 255)
+		ret[i] = name
+	}
+	return ret, nil
+}
+
+// checkJobEnvironment checks the protection rules of the environment the job deploys to, when the job is ready to leave the blocked status.
+// It returns StatusWaiting if the job could start, StatusBlocked if it's waiting for the reviews or the wait timer,
+// or StatusFailure if the deployment is rejected or the ref isn't allowed to deploy to the environment.
+func checkJobEnvironment(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) (actions_model.Status, error) {
+	if job.EnvironmentID == 0 {
+		return actions_model.StatusWaiting, nil
+	}
+	deployment, err := actions_model.GetLatestDeploymentOfJob(ctx, job.ID)
+	if errors.Is(err, util.ErrNotExist) {
+		// the environment has been deleted with its deployments
+		return actions_model.StatusWaiting, nil
+	} else if err != nil {
+		return 0, err
+	}
+	switch deployment.Status {
+	case actions_model.DeploymentStatusWaiting:
+	case actions_model.DeploymentStatusRejected:
+		return actions_model.StatusFailure, nil
+	default:
+		return actions_model.StatusWaiting, nil
+	}
+
+	env, err := actions_model.GetEnvironmentByID(ctx, deployment.EnvironmentID)
+	if errors.Is(err, util.ErrNotExist) {
+		return actions_model.StatusWaiting, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if !env.IsRefAllowed(run.Ref) {
+		log.Trace("Ref %s of run %d isn't allowed to deploy to environment %q", run.Ref, run.ID, env.Name)
+		return actions_model.StatusFailure, nil
+	}
+	if env.RequiresReview() && deployment.ApprovedUnix.IsZero() {
+		return actions_model.StatusBlocked, nil
+	}
+	if env.WaitTimer > 0 {
+		if deployment.WaitUntil.IsZero() {
+			deployment.WaitUntil = timeutil.TimeStampNow().AddDuration(time.Duration(env.WaitTimer) * time.Minute)
+			if err := actions_model.UpdateDeployment(ctx, deployment, "wait_until"); err != nil {
+				return 0, err
+			}
+			return actions_model.StatusBlocked, nil
+		}
+		if timeutil.TimeStampNow() < deployment.WaitUntil {
+			return actions_model.StatusBlocked, nil
+		}
+	}
+	return actions_model.StatusWaiting, nil
+}
+
+// ReleaseWaitingDeployments emits the jobs of which the wait timers of the deployments have ended
+func ReleaseWaitingDeployments(ctx context.Context) error {
+	deployments, err := db.Find[actions_model.ActionDeployment](ctx, actions_model.FindDeploymentsOptions{
+		Status:      []actions_model.DeploymentStatus{actions_model.DeploymentStatusWaiting},
+		WaitUntilLE: timeutil.TimeStampNow(),
+	})
+	if err != nil {
+		return fmt.Errorf("find waiting deployments: %w", err)
+	}
+	runIDs := make(container.Set[int64])
+	for _, d := range deployments {
+		runIDs.Add(d.RunID)
+	}
+	for runID := range runIDs {
+		if err := EmitJobsIfReady(runID); err != nil {
+			log.Error("EmitJobsIfReady for run %d: %v", runID, err)
+		}
+	}
+	return nil
+}
+
+// GetPendingDeployments returns the deployments of the run which are waiting for the reviews
+func GetPendingDeployments(ctx context.Context, run *actions_model.ActionRun) (actions_model.DeploymentList, error) {
+	deployments, err := db.Find[actions_model.ActionDeployment](ctx, actions_model.FindDeploymentsOptions{
+		RunID:  run.ID,
+		Status: []actions_model.DeploymentStatus{actions_model.DeploymentStatusWaiting},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := actions_model.DeploymentList(deployments).LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	pending := make(actions_model.DeploymentList, 0, len(deployments))
+	for _, d := range deployments {
+		if d.Environment.RequiresReview() && d.ApprovedUnix.IsZero() {
+			pending = append(pending, d)
+		}
+	}
+	return pending, nil
+}
+
+// ReviewPendingDeployments approves or rejects the pending deployments of the run to the given environments,
+// the doer must be a required reviewer of all the environments.
+func ReviewPendingDeployments(ctx context.Context, doer *user_model.User, run *actions_model.ActionRun, environmentIDs []int64, approved bool, comment string) error {
+	pending, err := GetPendingDeployments(ctx, run)
+	if err != nil {
+		return err
+	}
+	envIDs := container.SetOf(environmentIDs...)
+	reviewed := 0
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		for _, d := range pending {
+			if !envIDs.Contains(d.EnvironmentID) {
+				continue
+			}
+			if !d.Environment.IsReviewer(doer.ID) {
+				return util.NewPermissionDeniedErrorf("user %s is not a reviewer of environment %q", doer.Name, d.Environment.Name)
+			}
+			if err := db.Insert(ctx, &actions_model.ActionDeploymentReview{
+				RepoID:       d.RepoID,
+				DeploymentID: d.ID,
+				ReviewerID:   doer.ID,
+				Approved:     approved,
+				Comment:      comment,
+			}); err != nil {
+				return err
+			}
+			if approved {
+				d.ApprovedUnix = timeutil.TimeStampNow()
+				err = actions_model.UpdateDeployment(ctx, d, "approved_unix")
+			} else {
+				d.Status = actions_model.DeploymentStatusRejected
+				err = actions_model.UpdateDeployment(ctx, d, "status")
+			}
+			if err != nil {
+				return err
+			}
+			reviewed++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if reviewed == 0 {
+		return util.NewNotExistErrorf("no pending deployments to the environments")
+	}
+	return EmitJobsIfReady(run.ID)
+}
+
+// EnvironmentOptions contains the protection rules of an environment
+type EnvironmentOptions struct {
+	WaitTimer         int64
+	BranchFilters     []string
+	RequiredReviewers []*user_model.User
+}
+
+// ValidateEnvironmentName checks whether the name could be used by an environment
+func ValidateEnvironmentName(name string) error {
+	if name == "" || name != strings.TrimSpace(name) || len(name) > 255 || strings.ContainsAny(name, "/\\\r\n\t") {
+		return util.NewInvalidArgumentErrorf("invalid environment name %q", name)
+	}
+	return nil
+}
+
+func applyEnvironmentOptions(ctx context.Context, repo *repo_model.Repository, env *actions_model.ActionEnvironment, opts *EnvironmentOptions) error {
+	if opts.WaitTimer < 0 || opts.WaitTimer > actions_model.MaxEnvironmentWaitTimer {
+		return util.NewInvalidArgumentErrorf("wait timer must be between 0 and %d minutes", actions_model.MaxEnvironmentWaitTimer)
+	}
+	filters := make([]string, 0, len(opts.BranchFilters))
+	for _, filter := range opts.BranchFilters {
+		if filter = strings.TrimSpace(filter); filter == "" {
+			continue
+		}
+		if _, err := glob.Compile(filter, '/'); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid branch filter %q: %v", filter, err)
+		}
+		filters = append(filters, filter)
+	}
+	reviewers := make([]int64, 0, len(opts.RequiredReviewers))
+	for _, u := range opts.RequiredReviewers {
+		perm, err := access_model.GetUserRepoPermission(ctx, repo, u)
+		if err != nil {
+			return err
+		}
+		if !perm.CanRead(unit.TypeActions) {
+			return util.NewInvalidArgumentErrorf("reviewer %s has no access to the actions of the repository", u.Name)
+		}
+		reviewers = append(reviewers, u.ID)
+	}
+
+	env.WaitTimer = opts.WaitTimer
+	env.BranchFilters = filters
+	env.RequiredReviewers = reviewers
+	return nil
+}
+
+// CreateEnvironment creates a new environment of the repository
+func CreateEnvironment(ctx context.Context, repo *repo_model.Repository, name string, opts *EnvironmentOptions) (*actions_model.ActionEnvironment, error) {
+	if err := ValidateEnvironmentName(name); err != nil {
+		return nil, err
+	}
+	env := &actions_model.ActionEnvironment{RepoID: repo.ID, Name: name}
+	if err := applyEnvironmentOptions(ctx, repo, env, opts); err != nil {
+		return nil, err
+	}
+	return env, actions_model.CreateEnvironment(ctx, env)
+}
+
+// UpdateEnvironment updates the protection rules of the environment
+func UpdateEnvironment(ctx context.Context, repo *repo_model.Repository, env *actions_model.ActionEnvironment, opts *EnvironmentOptions) error {
+	if err := applyEnvironmentOptions(ctx, repo, env, opts); err != nil {
+		return err
+	}
+	return actions_model.UpdateEnvironment(ctx, env)
+}
+
+// DeleteEnvironment deletes the environment with its secrets, variables and deployments
+func DeleteEnvironment(ctx context.Context, env *actions_model.ActionEnvironment) error {
+	blockedJobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{
+		RepoID:        env.RepoID,
+		EnvironmentID: env.ID,
+		Statuses:      []actions_model.Status{actions_model.StatusBlocked},
+	})
+	if err != nil {
+		return err
+	}
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := secret_model.DeleteSecretsOfEnvironment(ctx, env.ID); err != nil {
+			return err
+		}
+		return actions_model.DeleteEnvironment(ctx, env)
+	}); err != nil {
+		return err
+	}
+
+	// the jobs waiting for the protection rules of the environment are not blocked by it anymore
+	runIDs := make(container.Set[int64])
+	for _, job := range blockedJobs {
+		runIDs.Add(job.RunID)
+	}
+	for runID := range runIDs {
+		if err := EmitJobsIfReady(runID); err != nil {
+			log.Error("EmitJobsIfReady for run %d: %v", runID, err)
+		}
+	}
+	return nil
+}
@@ -10,8 +10,11 @@ import (
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/nektos/act/pkg/jobparser"
 	"xorm.io/builder"
@@ -21,12 +24,17 @@ var jobEmitterQueue *queue.WorkerPoolQueue[*jobUpdate]
 
 type jobUpdate struct {
 	RunID int64
+	// Woken means the update is caused by a released concurrency group,
+	// such an update won't wake up other runs again, or blocked runs would wake up each other endlessly.
+	Woken bool
 }
 
 func EmitJobsIfReady(runID int64) error {
-	err := jobEmitterQueue.Push(&jobUpdate{
-		RunID: runID,
-	})
+	return pushJobUpdate(&jobUpdate{RunID: runID})
+}
+
+func pushJobUpdate(update *jobUpdate) error {
+	err := jobEmitterQueue.Push(update)
 	if errors.Is(err, queue.ErrAlreadyInQueue) {
 		return nil
 	}
@@ -37,40 +45,100 @@ func jobEmitterQueueHandler(items ...*jobUpdate) []*jobUpdate {
 	ctx := graceful.GetManager().ShutdownContext()
 	var ret []*jobUpdate
 	for _, update := range items {
-		if err := checkJobsOfRun(ctx, update.RunID); err != nil {
+		if err := checkJobsOfRun(ctx, update); err != nil {
 			ret = append(ret, update)
 		}
 	}
 	return ret
 }
 
-func checkJobsOfRun(ctx context.Context, runID int64) error {
-	jobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: runID})
+func checkJobsOfRun(ctx context.Context, update *jobUpdate) error {
+	run, err := actions_model.GetRunByID(ctx, update.RunID)
 	if err != nil {
 		return err
 	}
-	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
-		for _, job := range jobs {
-			idToJobs[job.JobID] = append(idToJobs[job.JobID], job)
-		}
-
-		updates := newJobStatusResolver(jobs).Resolve()
-		for _, job := range jobs {
-			if status, ok := updates[job.ID]; ok {
+	jobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+	if err != nil {
+		return err
+	}
+	if !run.NeedApproval {
+		var updatedJobs []*actions_model.ActionRunJob
+		var failedByEnvironment, startedCalls bool
+		if err := db.WithTx(ctx, func(ctx context.Context) error {
+			updates := newJobStatusResolver(jobs).Resolve()
+			for _, job := range jobs {
+				status, ok := updates[job.ID]
+				if !ok {
+					continue
+				}
+				oldStatus := job.Status
+				cols := []string{"status"}
+				if status == actions_model.StatusRunning && oldStatus == actions_model.StatusBlocked {
+					// the reusable workflow call starts
+					if status, err = startWorkflowCall(ctx, job, jobs); err != nil {
+						log.Error("Start reusable workflow call of job %d: %v", job.ID, err)
+						status = actions_model.StatusFailure
+					} else if status == actions_model.StatusRunning {
+						job.Started = timeutil.TimeStampNow()
+						cols = append(cols, "started")
+						startedCalls = true
+					}
+				}
+				if status == actions_model.StatusWaiting {
+					if status, err = checkJobEnvironment(ctx, run, job); err != nil {
+						return err
+					} else if status == actions_model.StatusBlocked {
+						continue
+					} else if status == actions_model.StatusFailure {
+						// the jobs which need it should be resolved again
+						failedByEnvironment = true
+					}
+				}
+				if status == actions_model.StatusWaiting {
+					if err := cancelConcurrentJobs(ctx, run, job); err != nil {
+						return err
+					}
+					if blocked, err := ShouldBlockJobByConcurrency(ctx, run, job); err != nil {
+						return err
+					} else if blocked {
+						continue
+					}
+				}
 				job.Status = status
-				if n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": actions_model.StatusBlocked}, "status"); err != nil {
+				if status.IsDone() {
+					job.Stopped = timeutil.TimeStampNow()
+					cols = append(cols, "stopped")
+				}
+				if n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": oldStatus}, cols...); err != nil {
 					return err
 				} else if n != 1 {
-					return fmt.Errorf("no affected for updating blocked job %v", job.ID)
+					return fmt.Errorf("no affected for updating %s job %v", oldStatus, job.ID)
 				}
+				updatedJobs = append(updatedJobs, job)
 			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		CreateCommitStatus(ctx, jobs...)
+		NotifyWorkflowJobsStatusUpdate(ctx, updatedJobs...)
+		if failedByEnvironment || startedCalls {
+			// the jobs which need it, or the jobs of the started reusable workflow calls should be resolved again
+			if err := EmitJobsIfReady(run.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !update.Woken {
+		// reload the run since its status could have been changed by the updated jobs
+		if run, err = actions_model.GetRunByID(ctx, run.ID); err != nil {
+			return err
+		}
+		if err := emitConcurrentRuns(ctx, run, jobs); err != nil {
+			return err
 		}
-		return nil
-	}); err != nil {
-		return err
 	}
-	CreateCommitStatus(ctx, jobs...)
 	return nil
 }
 
@@ -78,30 +146,60 @@ type jobStatusResolver struct {
 	statuses map[int64]actions_model.Status
 	needs    map[int64][]int64
 	jobMap   map[int64]*actions_model.ActionRunJob
+	// callers maps the jobs of the reusable workflow calls to the jobs calling the workflows,
+	// and calledJobs is the reverse
+	callers    map[int64]int64
+	calledJobs map[int64][]int64
+	// startedCalls contains the reusable workflow calls which had started before resolving,
+	// the called jobs wait until the calls have been started with their `if` conditions evaluated
+	startedCalls container.Set[int64]
 }
 
 func newJobStatusResolver(jobs actions_model.ActionJobList) *jobStatusResolver {
-	idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
+	// the needs of a job are the jobs of the same workflow
+	type workflowJobID struct {
+		callerPath string
+		jobID      string
+	}
+	idToJobs := make(map[workflowJobID][]*actions_model.ActionRunJob, len(jobs))
 	jobMap := make(map[int64]*actions_model.ActionRunJob)
+	calls := make(map[string]int64)
 	for _, job := range jobs {
-		idToJobs[job.JobID] = append(idToJobs[job.JobID], job)
+		key := workflowJobID{job.CallerPath, job.JobID}
+		idToJobs[key] = append(idToJobs[key], job)
 		jobMap[job.ID] = job
+		if job.IsWorkflowCall() {
+			calls[job.CallPath] = job.ID
+		}
 	}
 
 	statuses := make(map[int64]actions_model.Status, len(jobs))
 	needs := make(map[int64][]int64, len(jobs))
+	callers := make(map[int64]int64)
+	calledJobs := make(map[int64][]int64)
+	startedCalls := make(container.Set[int64])
 	for _, job := range jobs {
 		statuses[job.ID] = job.Status
 		for _, need := range job.Needs {
-			for _, v := range idToJobs[need] {
+			for _, v := range idToJobs[workflowJobID{job.CallerPath, need}] {
 				needs[job.ID] = append(needs[job.ID], v.ID)
 			}
 		}
+		if callID, ok := calls[job.CallerPath]; ok && job.CallerPath != "" {
+			callers[job.ID] = callID
+			calledJobs[callID] = append(calledJobs[callID], job.ID)
+		}
+		if job.IsWorkflowCall() && job.Status == actions_model.StatusRunning {
+			startedCalls.Add(job.ID)
+		}
 	}
 	return &jobStatusResolver{
-		statuses: statuses,
-		needs:    needs,
-		jobMap:   jobMap,
+		statuses:     statuses,
+		needs:        needs,
+		jobMap:       jobMap,
+		callers:      callers,
+		calledJobs:   calledJobs,
+		startedCalls: startedCalls,
 	}
 }
 
@@ -123,9 +221,25 @@ func (r *jobStatusResolver) Resolve() map[int64]actions_model.Status {
 func (r *jobStatusResolver) resolve() map[int64]actions_model.Status {
 	ret := map[int64]actions_model.Status{}
 	for id, status := range r.statuses {
+		if status == actions_model.StatusRunning && r.jobMap[id].IsWorkflowCall() {
+			if callStatus, ok := r.resolveWorkflowCall(id); ok {
+				ret[id] = callStatus
+			}
+			continue
+		}
 		if status != actions_model.StatusBlocked {
 			continue
 		}
+		if callID, ok := r.callers[id]; ok {
+			if r.statuses[callID].IsDone() {
+				// the reusable workflow call has been skipped or cancelled
+				ret[id] = actions_model.StatusSkipped
+				continue
+			}
+			if !r.startedCalls.Contains(callID) {
+				continue
+			}
+		}
 		allDone, allSucceed := true, true
 		for _, need := range r.needs[id] {
 			needStatus := r.statuses[need]
@@ -156,7 +270,26 @@ func (r *jobStatusResolver) resolve() map[int64]actions_model.Status {
 					ret[id] = actions_model.StatusSkipped
 				}
 			}
+			if ret[id] == actions_model.StatusWaiting && r.jobMap[id].IsWorkflowCall() {
+				// the job calling a reusable workflow isn't run by runners
+				ret[id] = actions_model.StatusRunning
+			}
 		}
 	}
 	return ret
 }
+
+// resolveWorkflowCall returns the status of a running reusable workflow call if all the called jobs are done
+func (r *jobStatusResolver) resolveWorkflowCall(id int64) (actions_model.Status, bool) {
+	called := make(actions_model.ActionJobList, 0, len(r.calledJobs[id]))
+	for _, calledID := range r.calledJobs[id] {
+		if !r.statuses[calledID].IsDone() {
+			return 0, false
+		}
+		called = append(called, &actions_model.ActionRunJob{Status: r.statuses[calledID]})
+	}
+	if len(called) == 0 {
+		return actions_model.StatusSuccess, true
+	}
+	return actions_model.AggregateJobStatus(called), true
+}
@@ -126,6 +126,51 @@ jobs:
 			},
 			want: map[int64]actions_model.Status{2: actions_model.StatusSkipped},
 		},
+		{
+			name: "reusable workflow call starts when its needs are done",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "setup", Status: actions_model.StatusSuccess, Needs: []string{}},
+				{ID: 2, JobID: "call", Status: actions_model.StatusBlocked, Needs: []string{"setup"}, CallPath: "call"},
+				{ID: 3, JobID: "build", Status: actions_model.StatusBlocked, Needs: []string{}, CallerPath: "call"},
+				{ID: 4, JobID: "setup", Status: actions_model.StatusBlocked, Needs: []string{}, CallerPath: "call"},
+			},
+			want: map[int64]actions_model.Status{2: actions_model.StatusRunning},
+		},
+		{
+			name: "jobs of a started reusable workflow call",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "setup", Status: actions_model.StatusSuccess, Needs: []string{}},
+				{ID: 2, JobID: "call", Status: actions_model.StatusRunning, Needs: []string{"setup"}, CallPath: "call"},
+				{ID: 3, JobID: "setup", Status: actions_model.StatusBlocked, Needs: []string{}, CallerPath: "call"},
+				{ID: 4, JobID: "build", Status: actions_model.StatusBlocked, Needs: []string{"setup"}, CallerPath: "call"},
+				{ID: 5, JobID: "deploy", Status: actions_model.StatusBlocked, Needs: []string{"call"}},
+			},
+			want: map[int64]actions_model.Status{3: actions_model.StatusWaiting},
+		},
+		{
+			name: "reusable workflow call finishes with its jobs",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "call", Status: actions_model.StatusRunning, Needs: []string{}, CallPath: "call"},
+				{ID: 2, JobID: "setup", Status: actions_model.StatusSuccess, Needs: []string{}, CallerPath: "call"},
+				{ID: 3, JobID: "build", Status: actions_model.StatusFailure, Needs: []string{"setup"}, CallerPath: "call"},
+				{ID: 4, JobID: "deploy", Status: actions_model.StatusBlocked, Needs: []string{"call"}},
+			},
+			want: map[int64]actions_model.Status{1: actions_model.StatusFailure, 4: actions_model.StatusSkipped},
+		},
+		{
+			name: "jobs of a skipped reusable workflow call",
+			jobs: actions_model.ActionJobList{
+				{ID: 1, JobID: "setup", Status: actions_model.StatusFailure, Needs: []string{}},
+				{ID: 2, JobID: "call", Status: actions_model.StatusBlocked, Needs: []string{"setup"}, CallPath: "call"},
+				{ID: 3, JobID: "inner", Status: actions_model.StatusBlocked, Needs: []string{}, CallPath: "call/inner", CallerPath: "call"},
+				{ID: 4, JobID: "build", Status: actions_model.StatusBlocked, Needs: []string{}, CallerPath: "call/inner"},
+			},
+			want: map[int64]actions_model.Status{
+				2: actions_model.StatusSkipped,
+				3: actions_model.StatusSkipped,
+				4: actions_model.StatusSkipped,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
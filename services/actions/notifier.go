@@ -677,6 +677,31 @@ func (n *actionsNotifier) PullRequestSynchronized(ctx context.Context, doer *use
 		Notify(ctx)
 }
 
+func (n *actionsNotifier) MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, groupRef git.RefName, groupCommitID, baseCommitID string) {
+	ctx = withMethod(ctx, "MergeGroupChecksRequested")
+
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		log.Error("pr.LoadBaseRepo: %v", err)
+		return
+	}
+
+	newNotifyInput(pr.BaseRepo, doer, webhook_module.HookEventMergeGroup).
+		WithRef(groupRef.String()).
+		WithPayload(&api.MergeGroupPayload{
+			Action: api.HookMergeGroupChecksRequested,
+			MergeGroup: &api.MergeGroup{
+				HeadSHA:    groupCommitID,
+				HeadRef:    groupRef.String(),
+				BaseSHA:    baseCommitID,
+				BaseRef:    git.BranchPrefix + pr.BaseBranch,
+				PullNumber: pr.Index,
+			},
+			Repository: convert.ToRepo(ctx, pr.BaseRepo, access_model.Permission{AccessMode: perm_model.AccessModeNone}),
+			Sender:     convert.ToUser(ctx, doer, nil),
+		}).
+		Notify(ctx)
+}
+
 func (n *actionsNotifier) PullRequestChangeTargetBranch(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, oldBranch string) {
 	ctx = withMethod(ctx, "PullRequestChangeTargetBranch")
 
@@ -211,6 +211,13 @@ func notify(ctx context.Context, input *notifyInput) error {
 		}
 	}
 
+	// the required workflows of the organization could not be disabled by the repository
+	requiredWorkflows, err := detectRequiredWorkflows(ctx, input, gitRepo, commit)
+	if err != nil {
+		return fmt.Errorf("detectRequiredWorkflows: %w", err)
+	}
+	detectedWorkflows = append(detectedWorkflows, requiredWorkflows...)
+
 	if input.PullRequest != nil {
 		// detect pull_request_target workflows
 		baseRef := git.BranchPrefix + input.PullRequest.BaseBranch
@@ -299,18 +306,19 @@ func handleWorkflows(
 
 	for _, dwf := range detectedWorkflows {
 		run := &actions_model.ActionRun{
-			Title:             strings.SplitN(commit.CommitMessage, "\n", 2)[0],
-			RepoID:            input.Repo.ID,
-			OwnerID:           input.Repo.OwnerID,
-			WorkflowID:        dwf.EntryName,
-			TriggerUserID:     input.Doer.ID,
-			Ref:               ref,
-			CommitSHA:         commit.ID.String(),
-			IsForkPullRequest: isForkPullRequest,
-			Event:             input.Event,
-			EventPayload:      string(p),
-			TriggerEvent:      dwf.TriggerEvent.Name,
-			Status:            actions_model.StatusWaiting,
+			Title:              strings.SplitN(commit.CommitMessage, "\n", 2)[0],
+			RepoID:             input.Repo.ID,
+			OwnerID:            input.Repo.OwnerID,
+			WorkflowID:         dwf.EntryName,
+			RequiredWorkflowID: dwf.RequiredWorkflowID,
+			TriggerUserID:      input.Doer.ID,
+			Ref:                ref,
+			CommitSHA:          commit.ID.String(),
+			IsForkPullRequest:  isForkPullRequest,
+			Event:              input.Event,
+			EventPayload:       string(p),
+			TriggerEvent:       dwf.TriggerEvent.Name,
+			Status:             actions_model.StatusWaiting,
 		}
 
 		need, err := ifNeedApproval(ctx, run, input.Repo, input.Doer)
@@ -352,7 +360,7 @@ func handleWorkflows(
 			}
 		}
 
-		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
+		if err := InsertRun(ctx, run, dwf.Content, jobs, vars); err != nil {
 			log.Error("InsertRun: %v", err)
 			continue
 		}
@@ -363,6 +371,8 @@ func handleWorkflows(
 			continue
 		}
 		CreateCommitStatus(ctx, alljobs...)
+		NotifyWorkflowRunRequested(ctx, run.ID)
+		NotifyWorkflowJobsStatusUpdate(ctx, alljobs...)
 	}
 	return nil
 }
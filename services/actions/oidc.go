@@ -0,0 +1,192 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/oauth2_provider"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// idTokenSigningKey signs the OIDC ID tokens of the jobs, it's loaded by Init
+var idTokenSigningKey oauth2_provider.JWTSigningKey
+
+// IDTokenClaims are the claims of the OIDC ID tokens issued to the jobs,
+// they are named like the claims of the tokens issued by GitHub so that the trust policies of cloud providers could be reused.
+// See https://docs.github.com/en/actions/security-for-github-actions/security-hardening-your-deployments/about-security-hardening-with-openid-connect
+type IDTokenClaims struct {
+	jwt.RegisteredClaims
+	Ref                  string `json:"ref"`
+	RefType              string `json:"ref_type"`
+	SHA                  string `json:"sha"`
+	Repository           string `json:"repository"`
+	RepositoryID         string `json:"repository_id"`
+	RepositoryOwner      string `json:"repository_owner"`
+	RepositoryOwnerID    string `json:"repository_owner_id"`
+	RepositoryVisibility string `json:"repository_visibility"`
+	Workflow             string `json:"workflow"`
+	Job                  string `json:"job"`
+	RunID                string `json:"run_id"`
+	RunNumber            string `json:"run_number"`
+	RunAttempt           string `json:"run_attempt"`
+	Actor                string `json:"actor"`
+	ActorID              string `json:"actor_id"`
+	EventName            string `json:"event_name"`
+	Environment          string `json:"environment,omitempty"`
+	HeadRef              string `json:"head_ref,omitempty"`
+	BaseRef              string `json:"base_ref,omitempty"`
+}
+
+// IDTokenClaimNames are the names of the claims in IDTokenClaims, which are listed in the discovery document
+var IDTokenClaimNames = []string{
+	"iss", "sub", "aud", "exp", "iat", "nbf", "jti",
+	"ref", "ref_type", "sha",
+	"repository", "repository_id", "repository_owner", "repository_owner_id", "repository_visibility",
+	"workflow", "job", "run_id", "run_number", "run_attempt",
+	"actor", "actor_id", "event_name", "environment", "head_ref", "base_ref",
+}
+
+func initIDTokenSigningKey() error {
+	key, err := oauth2_provider.LoadOrCreateAsymmetricKey(setting.Actions.IDTokenSigningPrivateKeyFile, setting.Actions.IDTokenSigningAlgorithm)
+	if err != nil {
+		return fmt.Errorf("LoadOrCreateAsymmetricKey: %w", err)
+	}
+	idTokenSigningKey, err = oauth2_provider.CreateJWTSigningKey(setting.Actions.IDTokenSigningAlgorithm, key)
+	return err
+}
+
+// IDTokenIssuer returns the issuer of the OIDC ID tokens, the discovery document is served under it
+func IDTokenIssuer() string {
+	return setting.AppURL + "api/actions/oidc"
+}
+
+// IDTokenRequestURL returns the URL the jobs request OIDC ID tokens from.
+// It has a query string already since the clients like actions/toolkit append the audience with `&audience=`.
+func IDTokenRequestURL() string {
+	return IDTokenIssuer() + "/token?api-version=2.0"
+}
+
+// IDTokenSigningAlgorithm returns the algorithm signing the OIDC ID tokens
+func IDTokenSigningAlgorithm() string {
+	return idTokenSigningKey.SigningMethod().Alg()
+}
+
+// IDTokenJWKs returns the JSON web keys verifying the OIDC ID tokens
+func IDTokenJWKs() ([]map[string]string, error) {
+	jwk, err := idTokenSigningKey.ToJWK()
+	if err != nil {
+		return nil, err
+	}
+	jwk["use"] = "sig"
+	return []map[string]string{jwk}, nil
+}
+
+// CreateIDToken creates an OIDC ID token for the running task with the audience,
+// the default audience is the URL of the owner of the repository.
+func CreateIDToken(ctx context.Context, task *actions_model.ActionTask, audience string) (string, error) {
+	if err := task.LoadAttributes(ctx); err != nil {
+		return "", err
+	}
+	if task.Status != actions_model.StatusRunning {
+		return "", util.NewInvalidArgumentErrorf("task %d is not running", task.ID)
+	}
+	job := task.Job
+	run := job.Run
+	if !task.Permissions.CanRequestIDToken() {
+		return "", util.NewPermissionDeniedErrorf("task %d doesn't have the %q permission", task.ID, actions_model.PermissionScopeIDToken)
+	}
+
+	environment := ""
+	if job.EnvironmentID > 0 {
+		env, err := actions_model.GetEnvironmentByID(ctx, job.EnvironmentID)
+		if err != nil && !errors.Is(err, util.ErrNotExist) {
+			return "", err
+		}
+		if env != nil {
+			environment = env.Name
+		}
+	}
+
+	eventName := run.TriggerEvent
+	if eventName == "" {
+		eventName = run.Event.Event()
+	}
+	ref, sha := run.Ref, run.CommitSHA
+	var headRef, baseRef string
+	if pullPayload, err := run.GetPullRequestEventPayload(); err == nil && pullPayload.PullRequest != nil && pullPayload.PullRequest.Base != nil && pullPayload.PullRequest.Head != nil {
+		headRef = pullPayload.PullRequest.Head.Ref
+		baseRef = pullPayload.PullRequest.Base.Ref
+		// the same as the ref and the sha in the github context of the task
+		if run.TriggerEvent == actions_module.GithubEventPullRequestTarget {
+			ref = git.BranchPrefix + pullPayload.PullRequest.Base.Name
+			sha = pullPayload.PullRequest.Base.Sha
+		}
+	}
+
+	repository := run.Repo.FullName()
+	subject := fmt.Sprintf("repo:%s:ref:%s", repository, ref)
+	if environment != "" {
+		subject = fmt.Sprintf("repo:%s:environment:%s", repository, environment)
+	} else if eventName == actions_module.GithubEventPullRequest {
+		subject = fmt.Sprintf("repo:%s:pull_request", repository)
+	}
+
+	if audience == "" {
+		audience = setting.AppURL + url.PathEscape(run.Repo.OwnerName)
+	}
+
+	visibility := "public"
+	if run.Repo.IsPrivate {
+		visibility = "private"
+	}
+
+	now := time.Now()
+	claims := &IDTokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    IDTokenIssuer(),
+			Subject:   subject,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(setting.Actions.IDTokenExpirationTime)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Ref:                  ref,
+		RefType:              git.RefName(ref).RefType(),
+		SHA:                  sha,
+		Repository:           repository,
+		RepositoryID:         strconv.FormatInt(run.RepoID, 10),
+		RepositoryOwner:      run.Repo.OwnerName,
+		RepositoryOwnerID:    strconv.FormatInt(run.Repo.OwnerID, 10),
+		RepositoryVisibility: visibility,
+		Workflow:             run.WorkflowID,
+		Job:                  job.JobID,
+		RunID:                strconv.FormatInt(run.ID, 10),
+		RunNumber:            strconv.FormatInt(run.Index, 10),
+		RunAttempt:           strconv.FormatInt(task.Attempt, 10),
+		Actor:                run.TriggerUser.Name,
+		ActorID:              strconv.FormatInt(run.TriggerUserID, 10),
+		EventName:            eventName,
+		Environment:          environment,
+		HeadRef:              headRef,
+		BaseRef:              baseRef,
+	}
+
+	token := jwt.NewWithClaims(idTokenSigningKey.SigningMethod(), claims)
+	idTokenSigningKey.PreProcessToken(token)
+	return token.SignedString(idTokenSigningKey.SignKey())
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+// evaluatePermissions reads the permissions of the jobs, the returned slice is aligned with jobs.
+func evaluatePermissions(content []byte, jobs []*jobparser.SingleWorkflow) ([]actions_model.JobPermissions, error) {
+	permissions, err := actions_module.GetPermissionsFromContent(content)
+	if err != nil {
+		return nil, fmt.Errorf("GetPermissionsFromContent: %w", err)
+	}
+	if len(permissions) == 0 {
+		return nil, nil
+	}
+
+	ret := make([]actions_model.JobPermissions, len(jobs))
+	for i, swf := range jobs {
+		id, _ := swf.Job()
+		ret[i] = permissions[id]
+	}
+	return ret, nil
+}
+
+// SetOwnerTokenPermissionMode sets the default token permission mode of the repositories of the owner
+func SetOwnerTokenPermissionMode(ctx context.Context, ownerID int64, mode actions_model.TokenPermissionMode) error {
+	if !mode.IsValid() {
+		return util.NewInvalidArgumentErrorf("invalid token permission mode %q", mode)
+	}
+	return user_model.SetUserSetting(ctx, ownerID, user_model.SettingsKeyActionsTokenPermissionMode, string(mode))
+}
+
+// SetRepoTokenPermissionMode sets the default token permission mode of the repository,
+// an empty mode makes the repository follow the mode of its owner.
+func SetRepoTokenPermissionMode(ctx context.Context, repo *repo_model.Repository, mode actions_model.TokenPermissionMode) error {
+	if mode != "" && !mode.IsValid() {
+		return util.NewInvalidArgumentErrorf("invalid token permission mode %q", mode)
+	}
+	cfgUnit := repo.MustGetUnit(ctx, unit.TypeActions)
+	cfgUnit.ActionsConfig().TokenPermissionMode = string(mode)
+	return repo_model.UpdateRepoUnit(ctx, cfgUnit)
+}
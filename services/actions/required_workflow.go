@@ -0,0 +1,338 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/gitrepo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	"github.com/nektos/act/pkg/model"
+	"xorm.io/builder"
+)
+
+// readRequiredWorkflow reads the content of the required workflow on the default branch of its repository
+func readRequiredWorkflow(ctx context.Context, repo *repo_model.Repository, workflowPath string) ([]byte, error) {
+	gitRepo, err := gitrepo.OpenRepository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := commit.GetTreeEntryByPath(workflowPath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, util.NewNotExistErrorf("workflow %q doesn't exist on the default branch of %s", workflowPath, repo.FullName())
+		}
+		return nil, err
+	}
+	return actions_module.GetContentFromEntry(entry)
+}
+
+// requiredWorkflowName returns the name of the workflow, the required workflows must be named
+// because the status checks of their jobs are matched by the name.
+func requiredWorkflowName(content []byte) (string, error) {
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return "", util.NewInvalidArgumentErrorf("invalid workflow: %v", err)
+	}
+	name := strings.TrimSpace(workflow.Name)
+	if name == "" {
+		return "", util.NewInvalidArgumentErrorf("the required workflow must have a name")
+	}
+	return name, nil
+}
+
+// ValidateRepoPatterns checks whether the glob patterns of the repository names are valid
+func ValidateRepoPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := glob.Compile(pattern); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid repository pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// CreateRequiredWorkflow requires the workflow of the repository for the repositories of its owner matching the patterns,
+// the status checks of its jobs are added to the protected branch rules of these repositories.
+func CreateRequiredWorkflow(ctx context.Context, repo *repo_model.Repository, workflowPath string, repoPatterns []string) (*actions_model.ActionRequiredWorkflow, error) {
+	if !actions_module.IsWorkflow(workflowPath) {
+		return nil, util.NewInvalidArgumentErrorf("%q isn't a workflow file", workflowPath)
+	}
+	if err := ValidateRepoPatterns(repoPatterns); err != nil {
+		return nil, err
+	}
+	if err := repo.LoadOwner(ctx); err != nil {
+		return nil, err
+	}
+	if !repo.Owner.IsOrganization() {
+		return nil, util.NewInvalidArgumentErrorf("only the workflows of organizations could be required")
+	}
+	content, err := readRequiredWorkflow(ctx, repo, workflowPath)
+	if err != nil {
+		return nil, err
+	}
+	name, err := requiredWorkflowName(content)
+	if err != nil {
+		return nil, err
+	}
+
+	rw := &actions_model.ActionRequiredWorkflow{
+		OwnerID:      repo.OwnerID,
+		RepoID:       repo.ID,
+		WorkflowPath: workflowPath,
+		Name:         name,
+		RepoPatterns: repoPatterns,
+	}
+	return rw, db.WithTx(ctx, func(ctx context.Context) error {
+		if err := actions_model.CreateRequiredWorkflow(ctx, rw); err != nil {
+			return err
+		}
+		return syncRequiredStatusChecks(ctx, rw.OwnerID, "", rw)
+	})
+}
+
+// UpdateRequiredWorkflow updates the repositories the workflow is required for,
+// the name of the workflow is read again in case it has been changed.
+func UpdateRequiredWorkflow(ctx context.Context, rw *actions_model.ActionRequiredWorkflow, repoPatterns []string) error {
+	if err := ValidateRepoPatterns(repoPatterns); err != nil {
+		return err
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, rw.RepoID)
+	if err != nil {
+		return err
+	}
+	content, err := readRequiredWorkflow(ctx, repo, rw.WorkflowPath)
+	if err != nil {
+		return err
+	}
+	name, err := requiredWorkflowName(content)
+	if err != nil {
+		return err
+	}
+
+	oldContext := rw.StatusCheckContext()
+	rw.Name = name
+	rw.RepoPatterns = repoPatterns
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := actions_model.UpdateRequiredWorkflow(ctx, rw); err != nil {
+			return err
+		}
+		return syncRequiredStatusChecks(ctx, rw.OwnerID, oldContext, rw)
+	})
+}
+
+// DeleteRequiredWorkflow deletes the required workflow and removes the status checks of its jobs from the protected branch rules
+func DeleteRequiredWorkflow(ctx context.Context, rw *actions_model.ActionRequiredWorkflow) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := actions_model.DeleteRequiredWorkflow(ctx, rw.ID); err != nil {
+			return err
+		}
+		return syncRequiredStatusChecks(ctx, rw.OwnerID, rw.StatusCheckContext(), nil)
+	})
+}
+
+// syncRequiredStatusChecks updates the protected branch rules of the repositories of the owner,
+// oldContext is removed from all of them and the status checks of the workflows required for them are added.
+func syncRequiredStatusChecks(ctx context.Context, ownerID int64, oldContext string, rw *actions_model.ActionRequiredWorkflow) error {
+	var removed []string
+	if oldContext != "" {
+		removed = []string{oldContext}
+	}
+	return db.Iterate(ctx, builder.Eq{"owner_id": ownerID}, func(ctx context.Context, repo *repo_model.Repository) error {
+		return syncRequiredStatusChecksOfRepo(ctx, repo, removed)
+	})
+}
+
+// syncRequiredStatusChecksOfRepo updates the protected branch rules of the repository, the removed status checks
+// are removed from them unless they are still required and the status checks required by the organization are added.
+func syncRequiredStatusChecksOfRepo(ctx context.Context, repo *repo_model.Repository, removed []string) error {
+	required, err := RequiredStatusCheckContexts(ctx, repo)
+	if err != nil {
+		return err
+	}
+	rules, err := git_model.FindRepoProtectedBranchRules(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		contexts := slices.DeleteFunc(slices.Clone(rule.StatusCheckContexts), func(c string) bool {
+			return slices.Contains(removed, c) && !slices.Contains(required, c)
+		})
+		for _, c := range required {
+			if !slices.Contains(contexts, c) {
+				contexts = append(contexts, c)
+			}
+		}
+		enable := rule.EnableStatusCheck || len(required) > 0
+		if enable == rule.EnableStatusCheck && slices.Equal(contexts, rule.StatusCheckContexts) {
+			continue
+		}
+		rule.EnableStatusCheck = enable
+		rule.StatusCheckContexts = contexts
+		if err := git_model.UpdateProtectBranchStatusChecks(ctx, rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncRequiredStatusChecksOfMovedRepo updates the protected branch rules of the repository once it has been transferred or renamed,
+// the status checks required by the workflows of its previous owner or for its previous name are replaced by the ones required now.
+func SyncRequiredStatusChecksOfMovedRepo(ctx context.Context, repo *repo_model.Repository, oldOwnerID int64, oldName string) error {
+	oldRepo := *repo
+	oldRepo.OwnerID = oldOwnerID
+	oldRepo.Name = oldName
+	removed, err := RequiredStatusCheckContexts(ctx, &oldRepo)
+	if err != nil {
+		return err
+	}
+	return syncRequiredStatusChecksOfRepo(ctx, repo, removed)
+}
+
+// HasRequiredStatusChecks returns true if the protected branch rule requires status checks which are required by the organization,
+// the administrators of the repository can't delete such a rule.
+func HasRequiredStatusChecks(ctx context.Context, repo *repo_model.Repository, rule *git_model.ProtectedBranch) (bool, error) {
+	if !rule.EnableStatusCheck {
+		return false, nil
+	}
+	required, err := RequiredStatusCheckContexts(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(required, func(c string) bool {
+		return slices.Contains(rule.StatusCheckContexts, c)
+	}), nil
+}
+
+// findRequiredWorkflowsOfRepo returns the required workflows of the owner of the repository which run for it
+func findRequiredWorkflowsOfRepo(ctx context.Context, repo *repo_model.Repository) ([]*actions_model.ActionRequiredWorkflow, error) {
+	rws, err := db.Find[actions_model.ActionRequiredWorkflow](ctx, actions_model.FindRequiredWorkflowsOptions{OwnerID: repo.OwnerID})
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(rws, func(rw *actions_model.ActionRequiredWorkflow) bool {
+		// the workflow runs as a workflow of the repository containing it
+		return rw.RepoID == repo.ID || !rw.MatchRepo(repo.Name)
+	}), nil
+}
+
+// RequiredStatusCheckContexts returns the status checks which are required by the organization for the protected branches of the repository
+func RequiredStatusCheckContexts(ctx context.Context, repo *repo_model.Repository) ([]string, error) {
+	rws, err := findRequiredWorkflowsOfRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	contexts := make([]string, 0, len(rws))
+	for _, rw := range rws {
+		contexts = append(contexts, rw.StatusCheckContext())
+	}
+	return contexts, nil
+}
+
+// ApplyRequiredStatusChecks adds the status checks required by the organization to the protected branch rule before it's saved,
+// so they could not be removed by the administrators of the repository.
+func ApplyRequiredStatusChecks(ctx context.Context, repo *repo_model.Repository, rule *git_model.ProtectedBranch) error {
+	required, err := RequiredStatusCheckContexts(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	rule.EnableStatusCheck = true
+	for _, c := range required {
+		if !slices.Contains(rule.StatusCheckContexts, c) {
+			rule.StatusCheckContexts = append(rule.StatusCheckContexts, c)
+		}
+	}
+	return nil
+}
+
+// detectRequiredWorkflows detects the required workflows of the organization which are triggered by the event of the repository
+func detectRequiredWorkflows(ctx context.Context, input *notifyInput, gitRepo *git.Repository, commit *git.Commit) ([]*actions_module.DetectedWorkflow, error) {
+	if err := input.Repo.LoadOwner(ctx); err != nil {
+		return nil, err
+	}
+	if !input.Repo.Owner.IsOrganization() {
+		return nil, nil
+	}
+	rws, err := findRequiredWorkflowsOfRepo(ctx, input.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var workflows []*actions_module.DetectedWorkflow
+	for _, rw := range rws {
+		repo, err := repo_model.GetRepositoryByID(ctx, rw.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		content, err := readRequiredWorkflow(ctx, repo, rw.WorkflowPath)
+		if err != nil {
+			log.Warn("ignore required workflow %s of %s: %v", rw.WorkflowPath, repo.FullName(), err)
+			continue
+		}
+		entryName := path.Join(repo.Name, path.Base(rw.WorkflowPath))
+		detected, err := actions_module.DetectWorkflowsFromContent(gitRepo, commit, input.Event, input.Payload, entryName, content)
+		if err != nil {
+			log.Warn("ignore invalid required workflow %s of %s: %v", rw.WorkflowPath, repo.FullName(), err)
+			continue
+		}
+		for _, dwf := range detected {
+			dwf.RequiredWorkflowID = rw.ID
+		}
+		workflows = append(workflows, detected...)
+	}
+	return workflows, nil
+}
+
+// requiredWorkflowSource returns where the required workflow of the run is read from,
+// the local reusable workflows it calls are read from the default branch of its repository.
+func requiredWorkflowSource(ctx context.Context, run *actions_model.ActionRun) (*workflowSource, error) {
+	rw, err := actions_model.GetRequiredWorkflowByID(ctx, run.RequiredWorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRequiredWorkflowByID: %w", err)
+	}
+	repo, err := repo_model.GetRepositoryByID(ctx, rw.RepoID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRepositoryByID: %w", err)
+	}
+	return &workflowSource{repo: repo, commitID: git.BranchPrefix + repo.DefaultBranch}, nil
+}
+
+// GetRequiredWorkflowsOfOwner returns the required workflows of the organization with their repositories loaded
+func GetRequiredWorkflowsOfOwner(ctx context.Context, owner *user_model.User) ([]*actions_model.ActionRequiredWorkflow, map[int64]*repo_model.Repository, error) {
+	rws, err := db.Find[actions_model.ActionRequiredWorkflow](ctx, actions_model.FindRequiredWorkflowsOptions{OwnerID: owner.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	repoIDs := make([]int64, 0, len(rws))
+	for _, rw := range rws {
+		repoIDs = append(repoIDs, rw.RepoID)
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(ctx, repoIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rws, repos, nil
+}
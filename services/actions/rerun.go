@@ -4,27 +4,46 @@
 package actions
 
 import (
+	"context"
+	"slices"
+
 	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+
+	"xorm.io/builder"
 )
 
-// GetAllRerunJobs get all jobs that need to be rerun when job should be rerun
+// GetAllRerunJobs get all jobs that need to be rerun when job should be rerun,
+// rerunning a job of a reusable workflow call reruns the whole call.
 func GetAllRerunJobs(job *actions_model.ActionRunJob, allJobs []*actions_model.ActionRunJob) []*actions_model.ActionRunJob {
+	for job.CallerPath != "" {
+		idx := slices.IndexFunc(allJobs, func(j *actions_model.ActionRunJob) bool { return j.CallPath == job.CallerPath })
+		if idx < 0 {
+			break
+		}
+		job = allJobs[idx]
+	}
+
 	rerunJobs := []*actions_model.ActionRunJob{job}
-	rerunJobsIDSet := make(container.Set[string])
-	rerunJobsIDSet.Add(job.JobID)
+	rerunJobsSet := make(container.Set[*actions_model.ActionRunJob])
+	rerunJobsSet.Add(job)
 
 	for {
 		found := false
 		for _, j := range allJobs {
-			if rerunJobsIDSet.Contains(j.JobID) {
+			if rerunJobsSet.Contains(j) {
 				continue
 			}
-			for _, need := range j.Needs {
-				if rerunJobsIDSet.Contains(need) {
+			for _, r := range rerunJobs {
+				needed := j.CallerPath == r.CallerPath && slices.Contains(j.Needs, r.JobID)
+				called := r.IsWorkflowCall() && j.CallerPath == r.CallPath
+				if needed || called {
 					found = true
 					rerunJobs = append(rerunJobs, j)
-					rerunJobsIDSet.Add(j.JobID)
+					rerunJobsSet.Add(j)
 					break
 				}
 			}
@@ -36,3 +55,96 @@ func GetAllRerunJobs(job *actions_model.ActionRunJob, allJobs []*actions_model.A
 
 	return rerunJobs
 }
+
+// RerunRun reruns the done jobs of the run, if job isn't nil, only the job and the jobs depending on it are rerun
+func RerunRun(ctx context.Context, run *actions_model.ActionRun, job *actions_model.ActionRunJob) error {
+	if err := run.LoadRepo(ctx); err != nil {
+		return err
+	}
+	// can not rerun job when workflow is disabled
+	cfg := run.Repo.MustGetUnit(ctx, unit.TypeActions).ActionsConfig()
+	if cfg.IsWorkflowDisabled(run.WorkflowID) {
+		return ErrWorkflowDisabled
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	// reset run's start and stop time when it is done
+	if run.Status.IsDone() {
+		run.PreviousDuration = run.Duration()
+		run.Started = 0
+		run.Stopped = 0
+		if err := actions_model.UpdateRun(ctx, run, "started", "stopped", "previous_duration"); err != nil {
+			return err
+		}
+	}
+
+	var rerunJobs []*actions_model.ActionRunJob
+	if job == nil {
+		rerunJobs = jobs
+	} else {
+		rerunJobs = GetAllRerunJobs(job, jobs)
+	}
+	for _, j := range rerunJobs {
+		// if the job has needs, it should be set to "blocked" status to wait for other jobs,
+		// and the jobs other than the specified one should be set to "blocked" status
+		shouldBlock := len(j.Needs) > 0
+		if job != nil {
+			shouldBlock = j.ID != job.ID
+		}
+		if err := rerunJob(ctx, j, shouldBlock); err != nil {
+			return err
+		}
+	}
+
+	// let the rerun jobs deploying to environments check the protection rules,
+	// and the rerun reusable workflow calls start.
+	if slices.ContainsFunc(rerunJobs, func(job *actions_model.ActionRunJob) bool { return job.EnvironmentID > 0 || job.IsWorkflowCall() }) {
+		if err := EmitJobsIfReady(run.ID); err != nil {
+			log.Error("EmitJobsIfReady: %v", err)
+		}
+	}
+	NotifyWorkflowRunRequested(ctx, run.ID)
+	return nil
+}
+
+func rerunJob(ctx context.Context, job *actions_model.ActionRunJob, shouldBlock bool) error {
+	status := job.Status
+	if !status.IsDone() {
+		return nil
+	}
+
+	job.TaskID = 0
+	job.Status = actions_model.StatusWaiting
+	// the job deploying to an environment has to pass the protection rules of the environment again,
+	// and the reusable workflow calls start again with their jobs
+	if shouldBlock || job.EnvironmentID > 0 || job.IsWorkflowCall() || job.CallerPath != "" {
+		job.Status = actions_model.StatusBlocked
+	}
+	job.Started = 0
+	job.Stopped = 0
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"status": status}, "task_id", "status", "started", "stopped"); err != nil {
+			return err
+		}
+		if job.EnvironmentID > 0 {
+			if err := job.LoadRun(ctx); err != nil {
+				return err
+			}
+			if _, err := actions_model.CreateDeploymentForJob(ctx, job.Run, job); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, job)
+	NotifyWorkflowJobsStatusUpdate(ctx, job)
+	return nil
+}
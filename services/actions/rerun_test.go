@@ -46,3 +46,18 @@ func TestGetAllRerunJobs(t *testing.T) {
 		assert.ElementsMatch(t, tc.rerunJobs, rerunJobs)
 	}
 }
+
+func TestGetAllRerunJobsOfWorkflowCall(t *testing.T) {
+	setup := &actions_model.ActionRunJob{JobID: "setup"}
+	call := &actions_model.ActionRunJob{JobID: "call", Needs: []string{"setup"}, CallPath: "call"}
+	callSetup := &actions_model.ActionRunJob{JobID: "setup", CallerPath: "call"}
+	callBuild := &actions_model.ActionRunJob{JobID: "build", Needs: []string{"setup"}, CallerPath: "call"}
+	deploy := &actions_model.ActionRunJob{JobID: "deploy", Needs: []string{"call"}}
+
+	jobs := []*actions_model.ActionRunJob{setup, call, callSetup, callBuild, deploy}
+
+	assert.ElementsMatch(t, jobs, GetAllRerunJobs(setup, jobs))
+	assert.ElementsMatch(t, []*actions_model.ActionRunJob{call, callSetup, callBuild, deploy}, GetAllRerunJobs(call, jobs))
+	assert.ElementsMatch(t, []*actions_model.ActionRunJob{call, callSetup, callBuild, deploy}, GetAllRerunJobs(callBuild, jobs))
+	assert.ElementsMatch(t, []*actions_model.ActionRunJob{deploy}, GetAllRerunJobs(deploy, jobs))
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// CancelRun cancels all the jobs of the run which are not done
+func CancelRun(ctx context.Context, run *actions_model.ActionRun) error {
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		for _, job := range jobs {
+			status := job.Status
+			if status.IsDone() {
+				continue
+			}
+			if job.TaskID == 0 {
+				job.Status = actions_model.StatusCancelled
+				job.Stopped = timeutil.TimeStampNow()
+				n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
+				if err != nil {
+					return err
+				}
+				if n == 0 {
+					return fmt.Errorf("job has changed, try again")
+				}
+				continue
+			}
+			if err := actions_model.StopTask(ctx, job.TaskID, actions_model.StatusCancelled); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, jobs...)
+	NotifyWorkflowJobsStatusUpdate(ctx, jobs...)
+
+	// wake up the runs waiting for the concurrency groups held by the cancelled run
+	if err := EmitJobsIfReady(run.ID); err != nil {
+		log.Error("Emit ready jobs of run %d: %v", run.ID, err)
+	}
+	return nil
+}
+
+// ApproveRun approves the run created by a fork pull request of a user who needs approval, and starts its jobs
+func ApproveRun(ctx context.Context, doer *user_model.User, run *actions_model.ActionRun) error {
+	if !run.NeedApproval {
+		return util.NewInvalidArgumentErrorf("workflow run %d doesn't need approval", run.ID)
+	}
+
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, run.ID)
+	if err != nil {
+		return err
+	}
+
+	var shouldEmit bool
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		run.NeedApproval = false
+		run.ApprovedBy = doer.ID
+		if err := actions_model.UpdateRun(ctx, run, "need_approval", "approved_by"); err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if len(job.Needs) == 0 && job.Status.IsBlocked() {
+				// the job will be emitted when it passes the protection rules of its environment,
+				// and the reusable workflow calls will be expanded by the job emitter
+				if job.EnvironmentID > 0 || job.IsWorkflowCall() || job.CallerPath != "" {
+					shouldEmit = true
+					continue
+				}
+				// the job will be emitted when its concurrency group is released
+				if err := cancelConcurrentJobs(ctx, run, job); err != nil {
+					return err
+				}
+				if blocked, err := ShouldBlockJobByConcurrency(ctx, run, job); err != nil {
+					return err
+				} else if blocked {
+					continue
+				}
+				job.Status = actions_model.StatusWaiting
+				if _, err := actions_model.UpdateRunJob(ctx, job, nil, "status"); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, jobs...)
+	NotifyWorkflowJobsStatusUpdate(ctx, jobs...)
+	if shouldEmit {
+		if err := EmitJobsIfReady(run.ID); err != nil {
+			log.Error("EmitJobsIfReady: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	runnerv1 "code.gitea.io/actions-proto-go/runner/v1"
+)
+
+// StepSummaryUploadURL returns the URL the runners upload the summaries of the steps to,
+// the summary of a step is put to `<url>/<step index>` with the runtime token of the task.
+func StepSummaryUploadURL() string {
+	return setting.AppURL + "api/actions/summaries"
+}
+
+// UploadStepSummary saves the content of `$GITHUB_STEP_SUMMARY` of the step of the running task
+func UploadStepSummary(ctx context.Context, task *actions_model.ActionTask, stepIndex int64, content string) error {
+	if task.Status != actions_model.StatusRunning {
+		return util.NewInvalidArgumentErrorf("task %d is not running", task.ID)
+	}
+	if task.Steps == nil {
+		steps, err := actions_model.GetTaskStepsByTaskID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		task.Steps = steps
+	}
+	if stepIndex < 0 || stepIndex >= int64(len(task.Steps)) {
+		return util.NewInvalidArgumentErrorf("invalid step index %d", stepIndex)
+	}
+	return actions_model.SetTaskSummary(ctx, task, stepIndex, strings.TrimSpace(content))
+}
+
+// CollectAnnotations persists the annotations created by the workflow commands in the log rows of the task,
+// startIndex is the index of the first row in the logs of the task.
+func CollectAnnotations(ctx context.Context, task *actions_model.ActionTask, rows []*runnerv1.LogRow, startIndex int64) error {
+	var annotations []*actions_model.ActionTaskAnnotation
+	for i, row := range rows {
+		a := actions_module.ParseAnnotation(row.Content)
+		if a == nil {
+			continue
+		}
+		annotations = append(annotations, &actions_model.ActionTaskAnnotation{
+			LogIndex:    startIndex + int64(i),
+			Level:       string(a.Level),
+			Title:       base.EllipsisString(a.Title, 255),
+			Message:     a.Message,
+			Path:        a.Path,
+			StartLine:   a.StartLine,
+			EndLine:     a.EndLine,
+			StartColumn: a.StartColumn,
+			EndColumn:   a.EndColumn,
+		})
+	}
+	return actions_model.InsertTaskAnnotations(ctx, task, annotations)
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/convert"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+var (
+	// ErrWorkflowDisabled is returned when running a workflow which has been disabled in the repository
+	ErrWorkflowDisabled = util.NewInvalidArgumentErrorf("workflow is disabled")
+	// ErrWorkflowNotFound is returned when the workflow file doesn't exist in the default branch
+	ErrWorkflowNotFound = util.NewNotExistErrorf("workflow not found")
+	// ErrWorkflowNotDispatchable is returned when dispatching a workflow without the workflow_dispatch trigger
+	ErrWorkflowNotDispatchable = util.NewInvalidArgumentErrorf("workflow doesn't have a workflow_dispatch trigger")
+	// ErrInvalidWorkflowRef is returned when the ref to run a workflow on is neither a branch nor a tag
+	ErrInvalidWorkflowRef = util.NewInvalidArgumentErrorf("ref is neither a branch nor a tag")
+	// ErrWorkflowRefNotExist is returned when the ref to run a workflow on doesn't exist
+	ErrWorkflowRefNotExist = util.NewNotExistErrorf("ref does not exist")
+)
+
+// DefaultBranchWorkflow is a workflow file in the default branch of a repository
+type DefaultBranchWorkflow struct {
+	Commit *git.Commit // the head commit of the default branch
+	Dir    string      // the directory of the workflow files
+	Entry  *git.TreeEntry
+}
+
+// ListDefaultBranchWorkflows returns the workflow files in the default branch of the repository
+func ListDefaultBranchWorkflows(repo *repo_model.Repository, gitRepo *git.Repository) ([]*DefaultBranchWorkflow, error) {
+	if repo.IsEmpty {
+		return nil, nil
+	}
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	dir, entries, err := actions.ListWorkflows(commit)
+	if err != nil {
+		return nil, err
+	}
+	workflows := make([]*DefaultBranchWorkflow, 0, len(entries))
+	for _, entry := range entries {
+		workflows = append(workflows, &DefaultBranchWorkflow{Commit: commit, Dir: dir, Entry: entry})
+	}
+	return workflows, nil
+}
+
+// GetDefaultBranchWorkflow returns the workflow file with the name in the default branch of the repository
+func GetDefaultBranchWorkflow(repo *repo_model.Repository, gitRepo *git.Repository, workflowID string) (*DefaultBranchWorkflow, error) {
+	workflows, err := ListDefaultBranchWorkflows(repo, gitRepo)
+	if err != nil {
+		return nil, err
+	}
+	for _, workflow := range workflows {
+		if workflow.Entry.Name() == workflowID {
+			return workflow, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrWorkflowNotFound, workflowID)
+}
+
+// EnableOrDisableWorkflow enables or disables the workflow of the repository
+func EnableOrDisableWorkflow(ctx context.Context, repo *repo_model.Repository, workflowID string, isEnable bool) error {
+	cfgUnit := repo.MustGetUnit(ctx, unit.TypeActions)
+	cfg := cfgUnit.ActionsConfig()
+
+	if isEnable {
+		cfg.EnableWorkflow(workflowID)
+	} else {
+		cfg.DisableWorkflow(workflowID)
+	}
+
+	return repo_model.UpdateRepoUnit(ctx, cfgUnit)
+}
+
+// resolveWorkflowRef returns the full name and the commit of the ref to run a workflow on,
+// the ref could be a full ref name of a branch or a tag, or a short name of a branch or a tag
+func resolveWorkflowRef(gitRepo *git.Repository, ref string) (git.RefName, *git.Commit, error) {
+	refName := git.RefName(ref)
+	if !strings.HasPrefix(ref, "refs/") {
+		if gitRepo.IsBranchExist(ref) {
+			refName = git.RefNameFromBranch(ref)
+		} else if gitRepo.IsTagExist(ref) {
+			refName = git.RefNameFromTag(ref)
+		} else {
+			return "", nil, fmt.Errorf("%w: %s", ErrWorkflowRefNotExist, ref)
+		}
+	}
+
+	var commit *git.Commit
+	var err error
+	if refName.IsTag() {
+		commit, err = gitRepo.GetTagCommit(refName.TagName())
+	} else if refName.IsBranch() {
+		commit, err = gitRepo.GetBranchCommit(refName.BranchName())
+	} else {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidWorkflowRef, ref)
+	}
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return "", nil, fmt.Errorf("%w: %s", ErrWorkflowRefNotExist, ref)
+		}
+		return "", nil, err
+	}
+	return refName, commit, nil
+}
+
+// DispatchWorkflow runs the workflow of the default branch on the ref by the workflow_dispatch event,
+// processInputs fills the inputs of the event by the inputs defined in the workflow.
+func DispatchWorkflow(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, gitRepo *git.Repository, workflowID, ref string,
+	processInputs func(workflowDispatch *model.WorkflowDispatch, inputs map[string]any) error,
+) (*actions_model.ActionRun, error) {
+	cfg := repo.MustGetUnit(ctx, unit.TypeActions).ActionsConfig()
+	if cfg.IsWorkflowDisabled(workflowID) {
+		return nil, ErrWorkflowDisabled
+	}
+
+	refName, runTargetCommit, err := resolveWorkflowRef(gitRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	// the workflow is read from the default branch
+	workflowFile, err := GetDefaultBranchWorkflow(repo, gitRepo, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := actions.GetContentFromEntry(workflowFile.Entry)
+	if err != nil {
+		return nil, err
+	}
+	workflows, err := jobparser.Parse(content)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid workflow %s: %v", workflowID, err)
+	}
+	if len(workflows) == 0 {
+		return nil, util.NewInvalidArgumentErrorf("workflow %s doesn't have any jobs", workflowID)
+	}
+
+	workflow := &model.Workflow{
+		RawOn: workflows[0].RawOn,
+	}
+	workflowDispatch := workflow.WorkflowDispatchConfig()
+	if workflowDispatch == nil {
+		return nil, fmt.Errorf("%w: %s", ErrWorkflowNotDispatchable, workflowID)
+	}
+	inputs := make(map[string]any)
+	if err := processInputs(workflowDispatch, inputs); err != nil {
+		return nil, err
+	}
+
+	// inputs -> WorkflowDispatchPayload.Inputs -> ActionRun.EventPayload -> runner: ghc.Event
+	// https://docs.github.com/en/actions/learn-github-actions/contexts#github-context
+	// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_dispatch
+	workflowDispatchPayload := &api.WorkflowDispatchPayload{
+		Workflow:   workflowID,
+		Ref:        refName.String(),
+		Repository: convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeNone}),
+		Inputs:     inputs,
+		Sender:     convert.ToUserWithAccessMode(ctx, doer, perm.AccessModeNone),
+	}
+	eventPayload, err := workflowDispatchPayload.JSONPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &actions_model.ActionRun{
+		Title:             strings.SplitN(runTargetCommit.CommitMessage, "\n", 2)[0],
+		RepoID:            repo.ID,
+		OwnerID:           repo.OwnerID,
+		WorkflowID:        workflowID,
+		TriggerUserID:     doer.ID,
+		Ref:               refName.String(),
+		CommitSHA:         runTargetCommit.ID.String(),
+		IsForkPullRequest: false,
+		Event:             "workflow_dispatch",
+		TriggerEvent:      "workflow_dispatch",
+		EventPayload:      string(eventPayload),
+		Status:            actions_model.StatusWaiting,
+	}
+
+	// cancel running jobs of the same workflow
+	if err := actions_model.CancelPreviousJobs(
+		ctx,
+		run.RepoID,
+		run.Ref,
+		run.WorkflowID,
+		run.Event,
+	); err != nil {
+		log.Error("CancelRunningJobs: %v", err)
+	}
+
+	vars, err := actions_model.GetVariablesOfRun(ctx, run)
+	if err != nil {
+		return nil, err
+	}
+
+	// Insert the action run and its associated jobs into the database
+	if err := InsertRun(ctx, run, content, workflows, vars); err != nil {
+		return nil, err
+	}
+
+	alljobs, err := db.Find[actions_model.ActionRunJob](ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+	if err != nil {
+		log.Error("FindRunJobs: %v", err)
+	}
+	CreateCommitStatus(ctx, alljobs...)
+	NotifyWorkflowRunRequested(ctx, run.ID)
+	NotifyWorkflowJobsStatusUpdate(ctx, alljobs...)
+
+	return run, nil
+}
@@ -0,0 +1,533 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/gitrepo"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowSource is where a workflow is read from, the local reusable workflows it calls are read from the same commit
+type workflowSource struct {
+	repo     *repo_model.Repository
+	commitID string
+}
+
+// workflowCallExpander expands the jobs calling reusable workflows into the jobs of the called workflows,
+// the jobs and their settings are collected in the order they will be inserted.
+type workflowCallExpander struct {
+	ctx  context.Context
+	run  *actions_model.ActionRun
+	vars map[string]string
+
+	jobs          []*jobparser.SingleWorkflow
+	concurrencies []*actions_model.JobConcurrency
+	environments  []string
+	calls         []*actions_model.JobWorkflowCall
+	permissions   []actions_model.JobPermissions
+}
+
+// expandWorkflowCalls appends the jobs of the reusable workflows called by the jobs of the run, the slices of settings are kept aligned with the jobs.
+// The run must have its attributes loaded.
+func expandWorkflowCalls(ctx context.Context, run *actions_model.ActionRun, jobs []*jobparser.SingleWorkflow, jobConcurrencies []*actions_model.JobConcurrency, jobEnvironments []string, jobPermissions []actions_model.JobPermissions, vars map[string]string) (*workflowCallExpander, error) {
+	e := &workflowCallExpander{ctx: ctx, run: run, vars: vars}
+	src := &workflowSource{repo: run.Repo, commitID: run.CommitSHA}
+	if run.RequiredWorkflowID > 0 {
+		var err error
+		if src, err = requiredWorkflowSource(ctx, run); err != nil {
+			return nil, err
+		}
+	}
+	if err := e.add(src, jobs, jobConcurrencies, jobEnvironments, jobPermissions, "", 1); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *workflowCallExpander) add(src *workflowSource, jobs []*jobparser.SingleWorkflow, concurrencies []*actions_model.JobConcurrency, environments []string, permissions []actions_model.JobPermissions, callerPath string, depth int) error {
+	callIDs := make(map[string]int)
+	for i, swf := range jobs {
+		var concurrency *actions_model.JobConcurrency
+		if i < len(concurrencies) {
+			concurrency = concurrencies[i]
+		}
+		environment := ""
+		if i < len(environments) {
+			environment = environments[i]
+		}
+		var jobPermissions actions_model.JobPermissions
+		if i < len(permissions) {
+			jobPermissions = permissions[i]
+		}
+		var call *actions_model.JobWorkflowCall
+		if callerPath != "" {
+			call = &actions_model.JobWorkflowCall{CallerPath: callerPath}
+		}
+
+		id, job := swf.Job()
+		if job == nil || job.Uses == "" {
+			e.append(swf, concurrency, environment, call, jobPermissions)
+			continue
+		}
+
+		if depth >= actions_module.MaxWorkflowCallDepth {
+			return util.NewInvalidArgumentErrorf("job %q: reusable workflows can't be nested more than %d levels", id, actions_module.MaxWorkflowCallDepth)
+		}
+		calledSrc, content, err := e.readReusableWorkflow(src, job.Uses)
+		if err != nil {
+			return fmt.Errorf("job %q: %w", id, err)
+		}
+		wc, err := actions_module.GetWorkflowCallFromContent(content)
+		if err != nil {
+			return fmt.Errorf("job %q: reusable workflow %s: %w", id, job.Uses, err)
+		}
+		if job.With, err = wc.ResolveInputs(job.With); err != nil {
+			return fmt.Errorf("job %q: %w", id, err)
+		}
+		if err := wc.CheckSecrets(&job.RawSecrets); err != nil {
+			return fmt.Errorf("job %q: %w", id, err)
+		}
+		// the outputs of the call are evaluated from the outputs of the called jobs
+		job.Outputs = make(map[string]string, len(wc.Outputs))
+		for name, output := range wc.Outputs {
+			job.Outputs[name] = output.Value
+		}
+		if err := swf.SetJob(id, job); err != nil {
+			return err
+		}
+
+		// the jobs expanded from a matrix call the workflow separately
+		callPath := id
+		if n := callIDs[id]; n > 0 {
+			callPath = fmt.Sprintf("%s.%d", id, n)
+		}
+		callIDs[id]++
+		if callerPath != "" {
+			callPath = callerPath + "/" + callPath
+		}
+		e.append(swf, concurrency, "", &actions_model.JobWorkflowCall{CallPath: callPath, CallerPath: callerPath}, jobPermissions)
+
+		calledJobs, err := jobparser.Parse(content, jobparser.WithVars(e.vars))
+		if err != nil {
+			return fmt.Errorf("job %q: reusable workflow %s: %w", id, job.Uses, err)
+		}
+		for _, calledSwf := range calledJobs {
+			calledID, calledJob := calledSwf.Job()
+			calledJob.Name = job.Name + " / " + calledJob.Name
+			if err := calledSwf.SetJob(calledID, calledJob); err != nil {
+				return err
+			}
+		}
+		calledConcurrencies, err := evaluateJobConcurrencies(e.run, content, calledJobs, e.vars)
+		if err != nil {
+			return err
+		}
+		calledEnvironments, err := evaluateEnvironments(e.run, content, calledJobs, e.vars)
+		if err != nil {
+			return err
+		}
+		calledPermissions, err := evaluatePermissions(content, calledJobs)
+		if err != nil {
+			return err
+		}
+		// the called workflow could only keep or downgrade the permissions of the job calling it
+		if jobPermissions.IsSet() {
+			if calledPermissions == nil {
+				calledPermissions = make([]actions_model.JobPermissions, len(calledJobs))
+			}
+			for j := range calledPermissions {
+				calledPermissions[j] = calledPermissions[j].Restrict(jobPermissions)
+			}
+		}
+		if err := e.add(calledSrc, calledJobs, calledConcurrencies, calledEnvironments, calledPermissions, callPath, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *workflowCallExpander) append(swf *jobparser.SingleWorkflow, concurrency *actions_model.JobConcurrency, environment string, call *actions_model.JobWorkflowCall, permissions actions_model.JobPermissions) {
+	e.jobs = append(e.jobs, swf)
+	e.concurrencies = append(e.concurrencies, concurrency)
+	e.environments = append(e.environments, environment)
+	e.calls = append(e.calls, call)
+	e.permissions = append(e.permissions, permissions)
+}
+
+// readReusableWorkflow reads the content of the reusable workflow used by a job of the workflow read from src,
+// the workflows of other repositories could be used only if the user triggering the run can read them.
+func (e *workflowCallExpander) readReusableWorkflow(src *workflowSource, uses string) (*workflowSource, []byte, error) {
+	ref, err := actions_module.ParseReusableWorkflowRef(uses)
+	if err != nil {
+		return nil, nil, util.NewInvalidArgumentErrorf("%v", err)
+	}
+
+	target := src
+	if !ref.IsLocal() {
+		repo, err := repo_model.GetRepositoryByOwnerAndName(e.ctx, ref.Owner, ref.Repo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reusable workflow %s: %w", ref, err)
+		}
+		if repo.ID != e.run.RepoID {
+			perm, err := access_model.GetUserRepoPermission(e.ctx, repo, e.run.TriggerUser)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !perm.CanRead(unit.TypeCode) {
+				return nil, nil, util.NewPermissionDeniedErrorf("reusable workflow %s: no permission to read the repository", ref)
+			}
+		}
+		target = &workflowSource{repo: repo, commitID: ref.Ref}
+	}
+
+	gitRepo, err := gitrepo.OpenRepository(e.ctx, target.repo)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gitRepo.Close()
+	commit, err := gitRepo.GetCommit(target.commitID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reusable workflow %s: %w", ref, err)
+	}
+	entry, err := commit.GetTreeEntryByPath(ref.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reusable workflow %s: %w", ref, err)
+	}
+	content, err := actions_module.GetContentFromEntry(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &workflowSource{repo: target.repo, commitID: commit.ID.String()}, content, nil
+}
+
+// JobNeed is the result of a job needed by another job
+type JobNeed struct {
+	Outputs map[string]string
+	Status  actions_model.Status
+}
+
+// FindJobNeeds returns the results of the jobs needed by the job, keyed by the job ids in the workflow.
+// The needs of the jobs belonging to a reusable workflow call are the jobs of the same call.
+func FindJobNeeds(ctx context.Context, job *actions_model.ActionRunJob) (map[string]*JobNeed, error) {
+	if len(job.Needs) == 0 {
+		return nil, nil
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, job.RunID)
+	if err != nil {
+		return nil, fmt.Errorf("GetRunJobsByRunID: %w", err)
+	}
+	return findJobNeeds(ctx, job, jobs)
+}
+
+func findJobNeeds(ctx context.Context, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (map[string]*JobNeed, error) {
+	needs := container.SetOf(job.Needs...)
+	jobIDJobs := make(map[string][]*actions_model.ActionRunJob)
+	for _, j := range jobs {
+		if j.CallerPath == job.CallerPath && needs.Contains(j.JobID) {
+			jobIDJobs[j.JobID] = append(jobIDJobs[j.JobID], j)
+		}
+	}
+
+	ret := make(map[string]*JobNeed, len(jobIDJobs))
+	for jobID, jobsWithSameID := range jobIDJobs {
+		outputs, err := findJobsOutputs(ctx, jobsWithSameID, jobs)
+		if err != nil {
+			return nil, err
+		}
+		ret[jobID] = &JobNeed{
+			Outputs: outputs,
+			Status:  actions_model.AggregateJobStatus(jobsWithSameID),
+		}
+	}
+	return ret, nil
+}
+
+// findJobsOutputs returns the merged outputs of the jobs with the same job id, like the jobs expanded from a matrix
+func findJobsOutputs(ctx context.Context, jobsWithSameID, allJobs []*actions_model.ActionRunJob) (map[string]string, error) {
+	var jobOutputs map[string]string
+	for _, job := range jobsWithSameID {
+		var outputs map[string]string
+		if job.IsWorkflowCall() {
+			if !job.Status.IsDone() {
+				continue
+			}
+			var err error
+			if outputs, err = findWorkflowCallOutputs(ctx, job, allJobs); err != nil {
+				return nil, err
+			}
+		} else {
+			if job.TaskID == 0 || !job.Status.IsDone() {
+				// it shouldn't happen, or the job has been rerun
+				continue
+			}
+			got, err := actions_model.FindTaskOutputByTaskID(ctx, job.TaskID)
+			if err != nil {
+				return nil, fmt.Errorf("FindTaskOutputByTaskID: %w", err)
+			}
+			outputs = make(map[string]string, len(got))
+			for _, v := range got {
+				outputs[v.OutputKey] = v.OutputValue
+			}
+		}
+		if len(jobOutputs) == 0 {
+			jobOutputs = outputs
+		} else {
+			jobOutputs = mergeTwoOutputs(outputs, jobOutputs)
+		}
+	}
+	return jobOutputs, nil
+}
+
+// mergeTwoOutputs merges two outputs from two different ActionRunJobs
+// Values with the same output name may be overridden. The user should ensure the output names are unique.
+// See https://docs.github.com/en/actions/writing-workflows/workflow-syntax-for-github-actions#using-job-outputs-in-a-matrix-job
+func mergeTwoOutputs(o1, o2 map[string]string) map[string]string {
+	ret := make(map[string]string, len(o1))
+	for k1, v1 := range o1 {
+		if len(v1) > 0 {
+			ret[k1] = v1
+		} else {
+			ret[k1] = o2[k1]
+		}
+	}
+	return ret
+}
+
+// findWorkflowCallOutputs evaluates the outputs of the reusable workflow call with the outputs of the called jobs
+func findWorkflowCallOutputs(ctx context.Context, call *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (map[string]string, error) {
+	_, job, err := parseRunJob(call)
+	if err != nil {
+		return nil, err
+	}
+	if len(job.Outputs) == 0 {
+		return nil, nil
+	}
+
+	jobIDJobs := make(map[string][]*actions_model.ActionRunJob)
+	for _, j := range jobs {
+		if j.CallerPath == call.CallPath {
+			jobIDJobs[j.JobID] = append(jobIDJobs[j.JobID], j)
+		}
+	}
+	results := make(map[string]*model.WorkflowCallResult, len(jobIDJobs))
+	for jobID, jobsWithSameID := range jobIDJobs {
+		outputs, err := findJobsOutputs(ctx, jobsWithSameID, jobs)
+		if err != nil {
+			return nil, err
+		}
+		results[jobID] = &model.WorkflowCallResult{Outputs: outputs}
+	}
+
+	c, err := newWorkflowCallContext(ctx, call, job, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.Jobs = results
+	ret := make(map[string]string, len(job.Outputs))
+	for name, value := range job.Outputs {
+		ret[name] = c.Interpolate(value)
+	}
+	return ret, nil
+}
+
+// parseRunJob parses the workflow payload of a run job which contains exactly one job
+func parseRunJob(runJob *actions_model.ActionRunJob) (*jobparser.SingleWorkflow, *jobparser.Job, error) {
+	swf := &jobparser.SingleWorkflow{}
+	if err := yaml.Unmarshal(runJob.WorkflowPayload, swf); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal workflow payload of job %d: %w", runJob.ID, err)
+	}
+	_, job := swf.Job()
+	if job == nil {
+		return nil, nil, fmt.Errorf("no job in the workflow payload of job %d", runJob.ID)
+	}
+	return swf, job, nil
+}
+
+// newWorkflowCallContext returns the contexts to evaluate the expressions of the job calling a reusable workflow,
+// the results of the needed jobs are filled if needs isn't nil.
+func newWorkflowCallContext(ctx context.Context, call *actions_model.ActionRunJob, job *jobparser.Job, needs map[string]*JobNeed) (*actions_module.WorkflowCallContext, error) {
+	if err := call.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+	vars, err := actions_model.GetVariablesOfJob(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	c := &actions_module.WorkflowCallContext{
+		JobID:  call.JobID,
+		Github: generateGiteaContext(call.Run),
+		Vars:   vars,
+		Matrix: jobMatrix(job),
+		Needs:  make(map[string]exprparser.Needs, len(needs)),
+	}
+	for id, need := range needs {
+		c.Needs[id] = exprparser.Needs{Outputs: need.Outputs, Result: need.Status.String()}
+	}
+	return c, nil
+}
+
+func findCaller(jobs []*actions_model.ActionRunJob, job *actions_model.ActionRunJob) (*actions_model.ActionRunJob, error) {
+	for _, j := range jobs {
+		if j.CallPath == job.CallerPath {
+			return j, nil
+		}
+	}
+	return nil, fmt.Errorf("the job calling the reusable workflow of job %d: %w", job.ID, util.ErrNotExist)
+}
+
+// startWorkflowCall evaluates the `if` condition of a job calling a reusable workflow when its needs are done,
+// it returns StatusRunning if the called jobs could start, or StatusSkipped.
+func startWorkflowCall(ctx context.Context, call *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (actions_model.Status, error) {
+	_, job, err := parseRunJob(call)
+	if err != nil {
+		return 0, err
+	}
+	needs, err := findJobNeeds(ctx, call, jobs)
+	if err != nil {
+		return 0, err
+	}
+	c, err := newWorkflowCallContext(ctx, call, job, needs)
+	if err != nil {
+		return 0, err
+	}
+	if call.CallerPath != "" {
+		if c.Inputs, err = findWorkflowCallInputs(ctx, call, jobs); err != nil {
+			return 0, err
+		}
+	}
+	ok, err := c.EvaluateIf(&job.If)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return actions_model.StatusSkipped, nil
+	}
+	return actions_model.StatusRunning, nil
+}
+
+// findWorkflowCallInputs evaluates the inputs passed to the reusable workflow which the job belongs to
+func findWorkflowCallInputs(ctx context.Context, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob) (map[string]any, error) {
+	call, err := findCaller(jobs, job)
+	if err != nil {
+		return nil, err
+	}
+	_, callJob, err := parseRunJob(call)
+	if err != nil {
+		return nil, err
+	}
+	needs, err := findJobNeeds(ctx, call, jobs)
+	if err != nil {
+		return nil, err
+	}
+	c, err := newWorkflowCallContext(ctx, call, callJob, needs)
+	if err != nil {
+		return nil, err
+	}
+	if call.CallerPath != "" {
+		if c.Inputs, err = findWorkflowCallInputs(ctx, call, jobs); err != nil {
+			return nil, err
+		}
+	}
+
+	inputs := make(map[string]any, len(callJob.With))
+	for name, value := range callJob.With {
+		if s, ok := value.(string); ok {
+			value = c.Interpolate(s)
+		}
+		inputs[name] = value
+	}
+	return inputs, nil
+}
+
+// findWorkflowCallSecrets returns the secrets passed to the reusable workflow which the job belongs to,
+// the secrets are either inherited or mapped from the secrets of the caller.
+func findWorkflowCallSecrets(ctx context.Context, job *actions_model.ActionRunJob, jobs []*actions_model.ActionRunJob, secrets map[string]string) (map[string]string, error) {
+	call, err := findCaller(jobs, job)
+	if err != nil {
+		return nil, err
+	}
+	if call.CallerPath != "" {
+		if secrets, err = findWorkflowCallSecrets(ctx, call, jobs, secrets); err != nil {
+			return nil, err
+		}
+	}
+	_, callJob, err := parseRunJob(call)
+	if err != nil {
+		return nil, err
+	}
+	if callJob.RawSecrets.Kind == yaml.ScalarNode && callJob.RawSecrets.Value == "inherit" {
+		return secrets, nil
+	}
+
+	var mapping map[string]string
+	if callJob.RawSecrets.Kind != 0 {
+		if err := callJob.RawSecrets.Decode(&mapping); err != nil {
+			return nil, fmt.Errorf("invalid secrets of job %d: %w", call.ID, err)
+		}
+	}
+	c, err := newWorkflowCallContext(ctx, call, callJob, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.Secrets = secrets
+	ret := map[string]string{
+		"GITHUB_TOKEN": secrets["GITHUB_TOKEN"],
+		"GITEA_TOKEN":  secrets["GITEA_TOKEN"],
+	}
+	for name, value := range mapping {
+		ret[strings.ToUpper(name)] = c.Interpolate(value)
+	}
+	return ret, nil
+}
+
+// PrepareWorkflowCallTask returns the workflow payload and the secrets sent to the runner for a task,
+// if the job of the task belongs to a reusable workflow call, the inputs of the call are passed by the `INPUT_` environment variables
+// and only the secrets passed to the call are available.
+func PrepareWorkflowCallTask(ctx context.Context, task *actions_model.ActionTask, secrets map[string]string) ([]byte, map[string]string, error) {
+	if task.Job.CallerPath == "" {
+		return task.Job.WorkflowPayload, secrets, nil
+	}
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, task.Job.RunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	inputs, err := findWorkflowCallInputs(ctx, task.Job, jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secrets, err = findWorkflowCallSecrets(ctx, task.Job, jobs, secrets); err != nil {
+		return nil, nil, err
+	}
+
+	swf, _, err := parseRunJob(task.Job)
+	if err != nil {
+		return nil, nil, err
+	}
+	if swf.Env == nil {
+		swf.Env = make(map[string]string, len(inputs))
+	}
+	for name, value := range inputs {
+		swf.Env["INPUT_"+strings.ToUpper(name)] = fmt.Sprint(value)
+	}
+	payload, err := swf.Marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, secrets, nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"slices"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/log"
+	notify_service "code.gitea.io/gitea/services/notify"
+)
+
+// NotifyWorkflowRunRequested notifies that a run has been created or rerun.
+func NotifyWorkflowRunRequested(ctx context.Context, runID int64) {
+	// the run is reloaded since its status may have been changed by its jobs
+	run, err := actions_model.GetRunByID(ctx, runID)
+	if err != nil {
+		log.Error("Failed to get run %d: %v", runID, err)
+		return
+	}
+	if err := run.LoadAttributes(ctx); err != nil {
+		log.Error("Failed to load attributes of run %d: %v", run.ID, err)
+		return
+	}
+	notify_service.WorkflowRunStatusUpdate(ctx, run.Repo, run.TriggerUser, run)
+}
+
+// NotifyWorkflowJobsStatusUpdate notifies the new status of the given jobs,
+// and the status of their runs if the runs have been started or completed by the changes.
+// Like CreateCommitStatus, it won't return an error but will log it.
+func NotifyWorkflowJobsStatusUpdate(ctx context.Context, jobs ...*actions_model.ActionRunJob) {
+	started := make(container.Set[int64])
+	runIDs := make([]int64, 0, len(jobs))
+	for _, job := range jobs {
+		if err := job.LoadAttributes(ctx); err != nil {
+			log.Error("Failed to load attributes of job %d: %v", job.ID, err)
+			continue
+		}
+
+		var task *actions_model.ActionTask
+		if job.TaskID != 0 {
+			t, err := actions_model.GetTaskByID(ctx, job.TaskID)
+			if err != nil {
+				log.Error("Failed to get task %d of job %d: %v", job.TaskID, job.ID, err)
+				continue
+			}
+			task = t
+		}
+		notify_service.WorkflowJobStatusUpdate(ctx, job.Run.Repo, job.Run.TriggerUser, job, task)
+
+		if !slices.Contains(runIDs, job.RunID) {
+			runIDs = append(runIDs, job.RunID)
+		}
+		if job.Status.IsRunning() {
+			started.Add(job.ID)
+		}
+	}
+
+	for _, runID := range runIDs {
+		if err := notifyWorkflowRunStatusUpdate(ctx, runID, started); err != nil {
+			log.Error("Failed to notify status of run %d: %v", runID, err)
+		}
+	}
+}
+
+func notifyWorkflowRunStatusUpdate(ctx context.Context, runID int64, started container.Set[int64]) error {
+	// reload the run since its status could have been changed by the updated jobs
+	run, err := actions_model.GetRunByID(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case run.Status.IsDone():
+	case run.Status.IsRunning():
+		// only the first started job of the run starts the run
+		jobs, err := actions_model.GetRunJobsByRunID(ctx, runID)
+		if err != nil {
+			return err
+		}
+		first := false
+		for _, job := range jobs {
+			if job.Started.IsZero() || job.Started < run.Started {
+				continue
+			}
+			if !started.Contains(job.ID) {
+				return nil
+			}
+			first = true
+		}
+		if !first {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	if err := run.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	notify_service.WorkflowRunStatusUpdate(ctx, run.Repo, run.TriggerUser, run)
+	return nil
+}
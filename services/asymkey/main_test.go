@@ -10,6 +10,7 @@ import (
 
 	_ "code.gitea.io/gitea/models/actions"
 	_ "code.gitea.io/gitea/models/activities"
+	_ "code.gitea.io/gitea/models/audit"
 )
 
 func TestMain(m *testing.M) {
@@ -6,10 +6,12 @@ package attachment
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
 	"code.gitea.io/gitea/models/db"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
@@ -18,6 +20,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrQuotaExceeded is returned if the owner of the repository has used up the attachment quota
+var ErrQuotaExceeded = errors.New("maximum allowed attachment storage quota exceeded")
+
 // NewAttachment creates a new attachment object, but do not verify.
 func NewAttachment(ctx context.Context, attach *repo_model.Attachment, file io.Reader, size int64) (*repo_model.Attachment, error) {
 	if attach.RepoID == 0 {
@@ -48,6 +53,17 @@ func UploadAttachment(ctx context.Context, file io.Reader, allowedTypes string,
 		return nil, err
 	}
 
+	repo, err := repo_model.GetRepositoryByID(ctx, attach.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	acceptable, err := quota_model.EvaluateForUser(ctx, repo.OwnerID, quota_model.LimitSubjectSizeAttachments)
+	if err != nil {
+		return nil, err
+	} else if !acceptable {
+		return nil, ErrQuotaExceeded
+	}
+
 	return NewAttachment(ctx, attach, io.MultiReader(bytes.NewReader(buf), file), fileSize)
 }
 
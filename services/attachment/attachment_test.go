@@ -14,6 +14,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 
 	_ "code.gitea.io/gitea/models/actions"
+	_ "code.gitea.io/gitea/models/audit"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -0,0 +1,197 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package audit records the security relevant actions to the audit log and streams them to the "audit" logger.
+package audit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/httplib"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web/middleware"
+)
+
+// Scope is the owner and the repository an event belongs to, both are 0 for the instance wide events
+type Scope struct {
+	OwnerID int64
+	RepoID  int64
+}
+
+// OwnerScope returns the scope of the events of a user or an organization
+func OwnerScope(ownerID int64) Scope {
+	return Scope{OwnerID: ownerID}
+}
+
+// RepoScope returns the scope of the events of a repository, they are also the events of its owner
+func RepoScope(repo *repo_model.Repository) Scope {
+	return Scope{OwnerID: repo.OwnerID, RepoID: repo.ID}
+}
+
+// Target is the object an action is applied to
+type Target struct {
+	Type audit_model.TargetType
+	ID   int64
+	Name string
+}
+
+// UserTarget returns the target of the actions applied to a user
+func UserTarget(u *user_model.User) Target {
+	return Target{Type: audit_model.TargetUser, ID: u.ID, Name: u.Name}
+}
+
+// TeamTarget returns the target of the actions applied to a team, the organization is the owner of the scope
+func TeamTarget(t *organization.Team) Target {
+	return Target{Type: audit_model.TargetTeam, ID: t.ID, Name: t.Name}
+}
+
+// RepoTarget returns the target of the actions applied to a repository
+func RepoTarget(repo *repo_model.Repository) Target {
+	return Target{Type: audit_model.TargetRepository, ID: repo.ID, Name: repo.FullName()}
+}
+
+// AccessTokenTarget returns the target of the actions applied to an access token, the user is the owner of the scope
+func AccessTokenTarget(t *auth_model.AccessToken) Target {
+	return Target{Type: audit_model.TargetAccessToken, ID: t.ID, Name: t.Name}
+}
+
+// AccessTokenState returns the state of an access token, the token itself is never recorded
+func AccessTokenState(t *auth_model.AccessToken) map[string]any {
+	return map[string]any{
+		"name":             t.Name,
+		"scope":            string(t.Scope),
+		"token_last_eight": t.TokenLastEight,
+	}
+}
+
+// UserState returns the state of the account settings of a user which are changed by the administrators
+func UserState(u *user_model.User) map[string]any {
+	return map[string]any{
+		"name":                      u.Name,
+		"email":                     u.Email,
+		"login_type":                u.LoginType.String(),
+		"login_source":              u.LoginSource,
+		"login_name":                u.LoginName,
+		"is_admin":                  u.IsAdmin,
+		"is_active":                 u.IsActive,
+		"prohibit_login":            u.ProhibitLogin,
+		"restricted":                u.IsRestricted,
+		"visibility":                u.Visibility.String(),
+		"max_repo_creation":         u.MaxRepoCreation,
+		"allow_git_hook":            u.AllowGitHook,
+		"allow_import_local":        u.AllowImportLocal,
+		"allow_create_organization": u.AllowCreateOrganization,
+	}
+}
+
+// Record records the action in the audit log, before and after are the states of the target which are stored as JSON.
+// The doer is the signed-in user of the request of the context if it's nil. The errors are logged,
+// recording the event never fails the action.
+//
+// The events of the actions done in a transaction are recorded once the transaction has been committed,
+// they are dropped if it's rolled back.
+func Record(ctx context.Context, action audit_model.Action, doer *user_model.User, scope Scope, target Target, before, after any) {
+	if !setting.Audit.Enabled {
+		return
+	}
+	if doer == nil {
+		doer, _ = middleware.GetContextData(ctx)[middleware.ContextDataKeySignedUser].(*user_model.User)
+	}
+	e := &audit_model.Event{
+		Action:     action,
+		IPAddress:  remoteIP(ctx),
+		OwnerID:    scope.OwnerID,
+		RepoID:     scope.RepoID,
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		Before:     marshalState(before),
+		After:      marshalState(after),
+	}
+	if doer != nil {
+		e.ActorID = doer.ID
+		e.ActorName = doer.Name
+	}
+
+	// the session of the transaction mustn't be reused once it has been committed
+	insertCtx := ctx
+	if db.InTransaction(ctx) {
+		insertCtx = db.DefaultContext
+	}
+	db.AfterTx(ctx, func() {
+		if err := audit_model.InsertEvent(insertCtx, e); err != nil {
+			log.Error("Unable to record audit event %s of %s %d: %v", action, target.Type, target.ID, err)
+		}
+		if setting.IsAuditLogEnabled() {
+			logEvent(e)
+		}
+	})
+}
+
+// remoteIP returns the IP address of the client of the request the context belongs to
+func remoteIP(ctx context.Context) string {
+	req, ok := ctx.Value(httplib.RequestContextKey).(*http.Request)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func marshalState(state any) string {
+	if state == nil {
+		return ""
+	}
+	bs, err := json.Marshal(state)
+	if err != nil {
+		log.Error("Unable to marshal audit state: %v", err)
+		return ""
+	}
+	return string(bs)
+}
+
+// logEvent streams the event to the "audit" logger as a JSON line
+func logEvent(e *audit_model.Event) {
+	line, err := json.Marshal(map[string]any{
+		"id":          e.ID,
+		"time":        e.CreatedUnix.AsTime().UTC().Format(time.RFC3339),
+		"action":      e.Action,
+		"actor_id":    e.ActorID,
+		"actor":       e.ActorName,
+		"ip_address":  e.IPAddress,
+		"owner_id":    e.OwnerID,
+		"repo_id":     e.RepoID,
+		"target_type": e.TargetType,
+		"target_id":   e.TargetID,
+		"target":      e.TargetName,
+		"before":      json.RawMessage(orNull(e.Before)),
+		"after":       json.RawMessage(orNull(e.After)),
+		"hash":        e.Hash,
+	})
+	if err != nil {
+		log.Error("Unable to marshal audit event: %v", err)
+		return
+	}
+	log.GetLogger("audit").Info("%s", line)
+}
+
+func orNull(s string) string {
+	if s == "" {
+		return "null"
+	}
+	return s
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/crewjam/saml"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// NameIDFormats maps the supported name of NameID formats to their URN
+var NameIDFormats = map[string]saml.NameIDFormat{
+	"persistent":  saml.PersistentNameIDFormat,
+	"transient":   saml.TransientNameIDFormat,
+	"email":       saml.EmailAddressNameIDFormat,
+	"unspecified": saml.UnspecifiedNameIDFormat,
+}
+
+// MetadataURL returns the URL of the service provider metadata of the named source
+func MetadataURL(name string) string {
+	return setting.AppURL + "user/saml/" + url.PathEscape(name) + "/metadata"
+}
+
+// AssertionConsumerServiceURL returns the URL which the identity provider posts the responses to
+func AssertionConsumerServiceURL(name string) string {
+	return setting.AppURL + "user/saml/" + url.PathEscape(name) + "/acs"
+}
+
+// GenerateServiceProviderKeyPair generates a self-signed certificate and its private key in PEM format
+func GenerateServiceProviderKeyPair(commonName string) (certPEM, keyPEM string, err error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return "", "", err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return "", "", err
+	}
+	certPEM = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	return certPEM, keyPEM, nil
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &http.Transport{Proxy: proxy.Proxy()},
+	}
+}
+
+func (source *Source) loadKeyPair() (*x509.Certificate, *rsa.PrivateKey, error) {
+	keyPair, err := tls.X509KeyPair([]byte(source.ServiceProviderCertificate), []byte(source.ServiceProviderPrivateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid service provider certificate or private key: %w", err)
+	}
+	key, ok := keyPair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("service provider private key must be an RSA key")
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// parseMetadata parses the metadata of an identity provider, which may be wrapped in an <EntitiesDescriptor>
+func parseMetadata(data []byte) (*saml.EntityDescriptor, error) {
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	entity := &saml.EntityDescriptor{}
+	err := xml.Unmarshal(data, entity)
+	if err == nil {
+		return entity, nil
+	}
+
+	entities := &saml.EntitiesDescriptor{}
+	if err := xml.Unmarshal(data, entities); err != nil {
+		return nil, err
+	}
+	for i, e := range entities.EntityDescriptors {
+		if len(e.IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+	return nil, errors.New("no entity found with IDPSSODescriptor")
+}
+
+// identityProviderMetadataCache caches the metadata fetched from the identity providers by source ID and metadata URL,
+// so signing in doesn't fetch them on every request
+var identityProviderMetadataCache = expirable.NewLRU[string, *saml.EntityDescriptor](100, nil, time.Hour)
+
+func (source *Source) loadIdentityProviderMetadata(ctx context.Context) (*saml.EntityDescriptor, error) {
+	if source.IdentityProviderMetadata != "" {
+		return parseMetadata([]byte(source.IdentityProviderMetadata))
+	}
+
+	cacheKey := strconv.FormatInt(source.authSource.ID, 10) + ":" + source.IdentityProviderMetadataURL
+	if entity, ok := identityProviderMetadataCache.Get(cacheKey); ok {
+		return entity, nil
+	}
+	entity, err := source.fetchIdentityProviderMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identityProviderMetadataCache.Add(cacheKey, entity)
+	return entity, nil
+}
+
+func (source *Source) fetchIdentityProviderMetadata(ctx context.Context) (*saml.EntityDescriptor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.IdentityProviderMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch identity provider metadata: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch identity provider metadata: unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadata(data)
+}
+
+func (source *Source) newServiceProvider(idpMetadata *saml.EntityDescriptor) (*saml.ServiceProvider, error) {
+	cert, key, err := source.loadKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	metadataURL, err := url.Parse(MetadataURL(source.authSource.Name))
+	if err != nil {
+		return nil, err
+	}
+	acsURL, err := url.Parse(AssertionConsumerServiceURL(source.authSource.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityID:          metadataURL.String(),
+		Key:               key,
+		Certificate:       cert,
+		MetadataURL:       *metadataURL,
+		AcsURL:            *acsURL,
+		IDPMetadata:       idpMetadata,
+		AuthnNameIDFormat: saml.UnspecifiedNameIDFormat,
+		HTTPClient:        newHTTPClient(),
+	}
+	if format, ok := NameIDFormats[source.NameIDFormat]; ok {
+		sp.AuthnNameIDFormat = format
+	}
+	if source.SignRequests {
+		sp.SignatureMethod = dsig.RSASHA256SignatureMethod
+	}
+	return sp, nil
+}
+
+// ServiceProvider returns the service provider of this source, the metadata of the identity provider is loaded too
+func (source *Source) ServiceProvider(ctx context.Context) (*saml.ServiceProvider, error) {
+	idpMetadata, err := source.loadIdentityProviderMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return source.newServiceProvider(idpMetadata)
+}
+
+// ServiceProviderMetadata returns the metadata XML of the service provider of this source
+func (source *Source) ServiceProviderMetadata() ([]byte, error) {
+	sp, err := source.newServiceProvider(&saml.EntityDescriptor{})
+	if err != nil {
+		return nil, err
+	}
+	return xml.MarshalIndent(sp.Metadata(), "", "  ")
+}
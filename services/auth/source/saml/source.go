@@ -0,0 +1,59 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/json"
+)
+
+// Source holds configuration for the SAML 2.0 login source.
+type Source struct {
+	// IdentityProviderMetadataURL is used to fetch the metadata of the identity provider,
+	// it is ignored if the metadata XML is provided directly in IdentityProviderMetadata
+	IdentityProviderMetadataURL string
+	IdentityProviderMetadata    string
+
+	// ServiceProviderCertificate and ServiceProviderPrivateKey are PEM encoded,
+	// they are used to sign the AuthnRequests and to decrypt the encrypted assertions
+	ServiceProviderCertificate string
+	ServiceProviderPrivateKey  string
+	SignRequests               bool
+	NameIDFormat               string
+
+	// Attribute* are the names (or friendly names) of the assertion attributes
+	AttributeUsername string
+	AttributeEmail    string
+	AttributeFullName string
+	AttributeGroups   string
+
+	AutoCreateUsers     bool
+	AdminGroup          string
+	RestrictedGroup     string
+	GroupTeamMap        string
+	GroupTeamMapRemoval bool
+	SkipLocalTwoFA      bool `json:",omitempty"`
+
+	// reference to the authSource
+	authSource *auth.Source
+}
+
+// FromDB fills up a SAMLConfig from serialized format.
+func (source *Source) FromDB(bs []byte) error {
+	return json.UnmarshalHandleDoubleEncode(bs, &source)
+}
+
+// ToDB exports a SAMLConfig to a serialized format.
+func (source *Source) ToDB() ([]byte, error) {
+	return json.Marshal(source)
+}
+
+// SetAuthSource sets the related AuthSource
+func (source *Source) SetAuthSource(authSource *auth.Source) {
+	source.authSource = authSource
+}
+
+func init() {
+	auth.RegisterTypeConfig(auth.SAML, &Source{})
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/globallock"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/crewjam/saml"
+)
+
+// UserInfo contains the user information asserted by the identity provider
+type UserInfo struct {
+	NameID   string
+	Username string
+	Email    string
+	FullName string
+	Groups   container.Set[string]
+}
+
+// the relay state is limited to 80 bytes by the specification
+const relayStateSignatureLength = 16
+
+// signRelayState binds the request ID to the relay state, the response posted by the identity provider
+// can't carry the session cookie (it's a cross-site POST), so the relay state is used to track the request
+func signRelayState(requestID string) string {
+	mac := hmac.New(sha256.New, []byte(setting.SecretKey))
+	_, _ = mac.Write([]byte(requestID))
+	return requestID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))[:relayStateSignatureLength]
+}
+
+func verifyRelayState(relayState string) (string, bool) {
+	requestID, _, ok := strings.Cut(relayState, ".")
+	if !ok {
+		return "", false
+	}
+	return requestID, hmac.Equal([]byte(signRelayState(requestID)), []byte(relayState))
+}
+
+// authnRequestLifetime is how long the identity provider has to post the response to an authentication request
+const authnRequestLifetime = 10 * time.Minute
+
+func authnRequestCacheKey(requestID string) string {
+	return "saml_authn_request_" + requestID
+}
+
+// rememberAuthnRequest stores the ID of an issued authentication request until a response to it is accepted
+func rememberAuthnRequest(requestID string) error {
+	c := cache.GetCache()
+	if c == nil {
+		return errors.New("cache isn't initialized")
+	}
+	return c.Put(authnRequestCacheKey(requestID), "1", int64(authnRequestLifetime.Seconds()))
+}
+
+// consumeAuthnRequest checks that the request has been issued and forgets it, so a response can only be accepted once
+func consumeAuthnRequest(ctx context.Context, requestID string) (bool, error) {
+	c := cache.GetCache()
+	if c == nil {
+		return false, errors.New("cache isn't initialized")
+	}
+	key := authnRequestCacheKey(requestID)
+	release, err := globallock.Lock(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	if !c.IsExist(key) {
+		return false, nil
+	}
+	return true, c.Delete(key)
+}
+
+// Callout returns the URL of the identity provider that the user should be redirected to for signing in
+func (source *Source) Callout(ctx context.Context) (string, error) {
+	sp, err := source.ServiceProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	location := sp.GetSSOBindingLocation(saml.HTTPRedirectBinding)
+	if location == "" {
+		return "", errors.New("identity provider doesn't support the HTTP-Redirect binding")
+	}
+	req, err := sp.MakeAuthenticationRequest(location, saml.HTTPRedirectBinding, saml.HTTPPostBinding)
+	if err != nil {
+		return "", err
+	}
+	u, err := req.Redirect(signRelayState(req.ID), sp)
+	if err != nil {
+		return "", err
+	}
+	if err := rememberAuthnRequest(req.ID); err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Callback validates the response posted by the identity provider to the assertion consumer service,
+// and returns the asserted user information. The response to a request is only accepted once.
+func (source *Source) Callback(req *http.Request) (*UserInfo, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, ErrInvalidResponse{Cause: err}
+	}
+	requestID, ok := verifyRelayState(req.Form.Get("RelayState"))
+	if !ok {
+		return nil, ErrInvalidResponse{Cause: errors.New("invalid relay state")}
+	}
+	ok, err := consumeAuthnRequest(req.Context(), requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidResponse{Cause: errors.New("unknown, expired or already used request")}
+	}
+
+	sp, err := source.ServiceProvider(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	assertion, err := sp.ParseResponse(req, []string{requestID})
+	if err != nil {
+		// the error message of InvalidResponseError is always "Authentication failed", the cause is private
+		var invalidResponseErr *saml.InvalidResponseError
+		if errors.As(err, &invalidResponseErr) {
+			return nil, ErrInvalidResponse{Cause: invalidResponseErr.PrivateErr}
+		}
+		return nil, ErrInvalidResponse{Cause: err}
+	}
+	return source.userInfoFromAssertion(assertion)
+}
+
+func (source *Source) userInfoFromAssertion(assertion *saml.Assertion) (*UserInfo, error) {
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return nil, ErrInvalidResponse{Cause: errors.New("the assertion has no NameID")}
+	}
+
+	attributes := make(map[string][]string)
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, v := range attr.Values {
+				values = append(values, v.Value)
+			}
+			attributes[attr.Name] = append(attributes[attr.Name], values...)
+			if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+				attributes[attr.FriendlyName] = append(attributes[attr.FriendlyName], values...)
+			}
+		}
+	}
+	first := func(name string) string {
+		if name == "" || len(attributes[name]) == 0 {
+			return ""
+		}
+		return strings.TrimSpace(attributes[name][0])
+	}
+
+	info := &UserInfo{
+		NameID:   assertion.Subject.NameID.Value,
+		Username: first(source.AttributeUsername),
+		Email:    first(source.AttributeEmail),
+		FullName: first(source.AttributeFullName),
+		Groups:   make(container.Set[string]),
+	}
+	if info.Username == "" {
+		info.Username = info.NameID
+	}
+	if info.Email == "" && strings.Contains(info.NameID, "@") {
+		info.Email = info.NameID
+	}
+	if source.AttributeGroups != "" {
+		for _, group := range attributes[source.AttributeGroups] {
+			if group = strings.TrimSpace(group); group != "" {
+				info.Groups.Add(group)
+			}
+		}
+	}
+	return info, nil
+}
+
+// ErrInvalidResponse represents an invalid response of the identity provider
+type ErrInvalidResponse struct {
+	Cause error
+}
+
+// IsErrInvalidResponse checks if an error is a ErrInvalidResponse.
+func IsErrInvalidResponse(err error) bool {
+	_, ok := err.(ErrInvalidResponse)
+	return ok
+}
+
+func (err ErrInvalidResponse) Error() string {
+	return fmt.Sprintf("invalid SAML response: %v", err.Cause)
+}
+
+func (err ErrInvalidResponse) Unwrap() error {
+	return err.Cause
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// RegisterSource validates the SAML configuration, nothing needs to be registered
+// since the service provider is built on demand
+func (source *Source) RegisterSource() error {
+	if err := source.validate(); err != nil {
+		return ErrInvalidConfiguration{Cause: err}
+	}
+	return nil
+}
+
+// UnregisterSource does nothing for SAML sources
+func (source *Source) UnregisterSource() error {
+	return nil
+}
+
+func (source *Source) validate() error {
+	if _, _, err := source.loadKeyPair(); err != nil {
+		return err
+	}
+	if source.NameIDFormat != "" {
+		if _, ok := NameIDFormats[source.NameIDFormat]; !ok {
+			return fmt.Errorf("unsupported NameID format: %s", source.NameIDFormat)
+		}
+	}
+
+	if source.IdentityProviderMetadata != "" {
+		if _, err := parseMetadata([]byte(source.IdentityProviderMetadata)); err != nil {
+			return fmt.Errorf("invalid identity provider metadata: %w", err)
+		}
+		return nil
+	}
+	if source.IdentityProviderMetadataURL == "" {
+		return errors.New("either the identity provider metadata or its URL is required")
+	}
+	u, err := url.Parse(source.IdentityProviderMetadataURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("invalid identity provider metadata URL: %s", source.IdentityProviderMetadataURL)
+	}
+	return nil
+}
+
+// ErrInvalidConfiguration represents an invalid SAML source configuration
+type ErrInvalidConfiguration struct {
+	Cause error
+}
+
+// IsErrInvalidConfiguration checks if an error is a ErrInvalidConfiguration.
+func IsErrInvalidConfiguration(err error) bool {
+	_, ok := err.(ErrInvalidConfiguration)
+	return ok
+}
+
+func (err ErrInvalidConfiguration) Error() string {
+	return fmt.Sprintf("invalid SAML configuration: %v", err.Cause)
+}
+
+func (err ErrInvalidConfiguration) Unwrap() error {
+	return err.Cause
+}
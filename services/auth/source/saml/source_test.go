@@ -0,0 +1,247 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package saml
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+
+	"github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIdentityProvider is a stand-in identity provider which trusts a single service provider
+type testIdentityProvider struct {
+	*saml.IdentityProvider
+	sp *saml.EntityDescriptor
+}
+
+func (p *testIdentityProvider) GetServiceProvider(_ *http.Request, serviceProviderID string) (*saml.EntityDescriptor, error) {
+	if p.sp == nil || p.sp.EntityID != serviceProviderID {
+		return nil, os.ErrNotExist
+	}
+	return p.sp, nil
+}
+
+// signIn handles the AuthnRequest redirected to the identity provider, and returns the response form posted to the ACS
+func (p *testIdentityProvider) signIn(t *testing.T, authnRequestURL string, session *saml.Session) url.Values {
+	req, err := saml.NewIdpAuthnRequest(p.IdentityProvider, httptest.NewRequest(http.MethodGet, authnRequestURL, nil))
+	require.NoError(t, err)
+	require.NoError(t, req.Validate())
+	require.NoError(t, saml.DefaultAssertionMaker{}.MakeAssertion(req, session))
+	form, err := req.PostBinding()
+	require.NoError(t, err)
+	return url.Values{"SAMLResponse": {form.SAMLResponse}, "RelayState": {form.RelayState}}
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	certPEM, keyPEM, err := GenerateServiceProviderKeyPair("idp.example.com")
+	require.NoError(t, err)
+	keyPair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	require.NoError(t, err)
+
+	idp := &testIdentityProvider{
+		IdentityProvider: &saml.IdentityProvider{
+			Key:         keyPair.PrivateKey,
+			Certificate: cert,
+			MetadataURL: url.URL{Scheme: "https", Host: "idp.example.com", Path: "/metadata"},
+			SSOURL:      url.URL{Scheme: "https", Host: "idp.example.com", Path: "/sso"},
+		},
+	}
+	idp.ServiceProviderProvider = idp
+	return idp
+}
+
+func newTestSource(t *testing.T, idp *testIdentityProvider) *Source {
+	idpMetadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	certPEM, keyPEM, err := GenerateServiceProviderKeyPair("gitea.example.com")
+	require.NoError(t, err)
+
+	source := &Source{
+		IdentityProviderMetadata:   string(idpMetadata),
+		ServiceProviderCertificate: certPEM,
+		ServiceProviderPrivateKey:  keyPEM,
+		NameIDFormat:               "persistent",
+		AttributeUsername:          "uid",
+		AttributeEmail:             "eduPersonPrincipalName",
+		AttributeFullName:          "cn",
+		AttributeGroups:            "eduPersonAffiliation",
+	}
+	source.SetAuthSource(&auth.Source{Name: "test-idp", Type: auth.SAML})
+	require.NoError(t, source.RegisterSource())
+
+	spMetadata, err := source.ServiceProviderMetadata()
+	require.NoError(t, err)
+	idp.sp = &saml.EntityDescriptor{}
+	require.NoError(t, xml.Unmarshal(spMetadata, idp.sp))
+	return source
+}
+
+func postToACS(values url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, AssertionConsumerServiceURL("test-idp"), strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestSignIn(t *testing.T) {
+	defer test.MockVariableValue(&setting.AppURL, "https://gitea.example.com/")()
+	defer test.MockVariableValue(&setting.SecretKey, "saml-test-secret")()
+	require.NoError(t, cache.Init())
+
+	idp := newTestIdentityProvider(t)
+	source := newTestSource(t, idp)
+	session := &saml.Session{
+		ID:             "session-1",
+		CreateTime:     time.Now(),
+		ExpireTime:     time.Now().Add(time.Hour),
+		Index:          "1",
+		NameID:         "a3f5c0d2",
+		NameIDFormat:   string(saml.PersistentNameIDFormat),
+		UserName:       "alice",
+		UserEmail:      "alice@example.com",
+		UserCommonName: "Alice Liddell",
+		Groups:         []string{"developers", "admins"},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(authnRequestURL, "https://idp.example.com/sso?SAMLRequest="))
+
+		values := idp.signIn(t, authnRequestURL, session)
+		info, err := source.Callback(postToACS(values))
+		require.NoError(t, err)
+		assert.Equal(t, "a3f5c0d2", info.NameID)
+		assert.Equal(t, "alice", info.Username)
+		assert.Equal(t, "alice@example.com", info.Email)
+		assert.Equal(t, "Alice Liddell", info.FullName)
+		assert.ElementsMatch(t, []string{"developers", "admins"}, info.Groups.Values())
+	})
+
+	t.Run("SignedRequest", func(t *testing.T) {
+		source.SignRequests = true
+		defer func() { source.SignRequests = false }()
+
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+		u, err := url.Parse(authnRequestURL)
+		require.NoError(t, err)
+		assert.NotEmpty(t, u.Query().Get("Signature"))
+
+		values := idp.signIn(t, authnRequestURL, session)
+		_, err = source.Callback(postToACS(values))
+		require.NoError(t, err)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+
+		values := idp.signIn(t, authnRequestURL, session)
+		_, err = source.Callback(postToACS(values))
+		require.NoError(t, err)
+		_, err = source.Callback(postToACS(values))
+		assert.True(t, IsErrInvalidResponse(err))
+	})
+
+	t.Run("TamperedRelayState", func(t *testing.T) {
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+
+		values := idp.signIn(t, authnRequestURL, session)
+		requestID, _, _ := strings.Cut(values.Get("RelayState"), ".")
+		values.Set("RelayState", requestID+".AAAAAAAAAAAAAAAA")
+		_, err = source.Callback(postToACS(values))
+		assert.True(t, IsErrInvalidResponse(err))
+	})
+
+	t.Run("UnknownRequest", func(t *testing.T) {
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+
+		values := idp.signIn(t, authnRequestURL, session)
+		values.Set("RelayState", signRelayState("id-unknown"))
+		_, err = source.Callback(postToACS(values))
+		assert.True(t, IsErrInvalidResponse(err))
+	})
+
+	t.Run("UntrustedIdentityProvider", func(t *testing.T) {
+		authnRequestURL, err := source.Callout(context.Background())
+		require.NoError(t, err)
+
+		// another identity provider with the same URLs but a different key
+		other := newTestIdentityProvider(t)
+		other.sp = idp.sp
+		values := other.signIn(t, authnRequestURL, session)
+		_, err = source.Callback(postToACS(values))
+		assert.True(t, IsErrInvalidResponse(err))
+	})
+}
+
+func TestRegisterSource(t *testing.T) {
+	defer test.MockVariableValue(&setting.AppURL, "https://gitea.example.com/")()
+
+	idp := newTestIdentityProvider(t)
+	source := newTestSource(t, idp)
+
+	invalid := *source
+	invalid.ServiceProviderPrivateKey = ""
+	assert.True(t, IsErrInvalidConfiguration(invalid.RegisterSource()))
+
+	invalid = *source
+	invalid.NameIDFormat = "unknown"
+	assert.True(t, IsErrInvalidConfiguration(invalid.RegisterSource()))
+
+	invalid = *source
+	invalid.IdentityProviderMetadata = ""
+	assert.True(t, IsErrInvalidConfiguration(invalid.RegisterSource()))
+
+	invalid.IdentityProviderMetadataURL = "https://idp.example.com/metadata"
+	assert.NoError(t, invalid.RegisterSource())
+}
+
+func TestIdentityProviderMetadataURL(t *testing.T) {
+	defer test.MockVariableValue(&setting.AppURL, "https://gitea.example.com/")()
+
+	idp := newTestIdentityProvider(t)
+	idpMetadata, err := xml.Marshal(idp.Metadata())
+	require.NoError(t, err)
+	fetched := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched++
+		_, _ = w.Write(idpMetadata)
+	}))
+	defer server.Close()
+
+	source := newTestSource(t, idp)
+	source.authSource.ID = 1
+	source.IdentityProviderMetadata = ""
+	source.IdentityProviderMetadataURL = server.URL
+
+	// the metadata is only fetched once
+	for i := 0; i < 2; i++ {
+		sp, err := source.ServiceProvider(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "https://idp.example.com/sso", sp.GetSSOBindingLocation(saml.HTTPRedirectBinding))
+	}
+	assert.Equal(t, 1, fetched)
+}
@@ -296,6 +296,21 @@ func handlePullRequestAutoMerge(pullID int64, sha string) {
 		return
 	}
 
+	if enabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+		log.Error("%-v IsMergeQueueEnabled: %v", pr, err)
+		return
+	} else if enabled {
+		// the merge queue takes over the scheduled merge
+		if err := pull_service.AddToMergeQueue(ctx, pr, doer, scheduledPRM.MergeStyle, sha, scheduledPRM.Message); err != nil && !pull_model.IsErrAlreadyInMergeQueue(err) {
+			log.Error("%-v AddToMergeQueue: %v", pr, err)
+			return
+		}
+		if err := pull_model.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil && !db.IsErrNotExist(err) {
+			log.Error("%-v DeleteScheduledAutoMerge: %v", pr, err)
+		}
+		return
+	}
+
 	if err := pull_service.Merge(ctx, pr, doer, baseGitRepo, scheduledPRM.MergeStyle, "", scheduledPRM.Message, true); err != nil {
 		log.Error("pull_service.Merge: %v", err)
 		// FIXME: if merge failed, we should display some error message to the pull request page.
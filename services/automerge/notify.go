@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/repository"
 	notify_service "code.gitea.io/gitea/services/notify"
+	pull_service "code.gitea.io/gitea/services/pull"
 )
 
 type automergeNotifier struct {
@@ -54,4 +55,10 @@ func (n *automergeNotifier) CreateCommitStatus(ctx context.Context, repo *repo_m
 			log.Error("MergeScheduledPullRequest[repo_id: %d, user_id: %d, sha: %s]: %w", repo.ID, sender.ID, commit.Sha1, err)
 		}
 	}
+	if !status.State.IsPending() {
+		// the commit could be a merge group of the merge queue which is waiting for its checks
+		if err := pull_service.StartMergeQueueByGroupCommitID(ctx, repo, commit.Sha1); err != nil {
+			log.Error("StartMergeQueueByGroupCommitID[repo_id: %d, sha: %s]: %v", repo.ID, commit.Sha1, err)
+		}
+	}
 }
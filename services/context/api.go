@@ -82,6 +82,12 @@ type APIForbiddenError struct {
 	APIError
 }
 
+// APIQuotaExceeded is the error response when the storage quota is exceeded
+// swagger:response quotaExceeded
+type APIQuotaExceeded struct {
+	APIError
+}
+
 // APINotFound is a not found empty response
 // swagger:response notFound
 type APINotFound struct{}
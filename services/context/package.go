@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
@@ -101,7 +102,6 @@ func determineAccessMode(ctx *Base, pkg *Package, doer *user_model.User) (perm.A
 		return perm.AccessModeNone, nil
 	}
 
-	// TODO: ActionUser permission check
 	accessMode := perm.AccessModeNone
 	if pkg.Owner.IsOrganization() {
 		org := organization.OrgFromUser(pkg.Owner)
@@ -145,9 +145,34 @@ func determineAccessMode(ctx *Base, pkg *Package, doer *user_model.User) (perm.A
 		}
 	}
 
+	if doer.IsActions() {
+		// the token of a job could access the packages of the owner of its repository as its permissions allow
+		actionsMode, err := determineActionsAccessMode(ctx, pkg)
+		if err != nil {
+			return accessMode, err
+		}
+		accessMode = max(accessMode, actionsMode)
+	}
+
 	return accessMode, nil
 }
 
+// determineActionsAccessMode returns the access mode granted by the `packages` permission of the task authenticated by the Actions token
+func determineActionsAccessMode(ctx *Base, pkg *Package) (perm.AccessMode, error) {
+	taskID, ok := ctx.Data["ActionsTaskID"].(int64)
+	if !ok || taskID <= 0 {
+		return perm.AccessModeNone, nil
+	}
+	task, err := actions_model.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return perm.AccessModeNone, err
+	}
+	if task.Status != actions_model.StatusRunning || task.OwnerID != pkg.Owner.ID || !task.Permissions.IsSet() {
+		return perm.AccessModeNone, nil
+	}
+	return task.Permissions.UnitAccessMode(unit.TypePackages), nil
+}
+
 // PackageContexter initializes a package context for a request.
 func PackageContexter() func(next http.Handler) http.Handler {
 	renderer := templates.HTMLRenderer()
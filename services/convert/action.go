@@ -0,0 +1,191 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/nektos/act/pkg/model"
+)
+
+// ToActionsStatus converts an actions status to the GitHub compatible status and conclusion
+func ToActionsStatus(status actions_model.Status) (string, string) {
+	switch status {
+	case actions_model.StatusWaiting:
+		return "queued", ""
+	case actions_model.StatusBlocked:
+		return "waiting", ""
+	case actions_model.StatusRunning:
+		return "in_progress", ""
+	case actions_model.StatusSuccess, actions_model.StatusFailure, actions_model.StatusCancelled, actions_model.StatusSkipped:
+		return "completed", status.String()
+	}
+	return "pending", ""
+}
+
+func timeStampToAPI(ts timeutil.TimeStamp) *time.Time {
+	if ts.IsZero() {
+		return nil
+	}
+	t := ts.AsLocalTime()
+	return &t
+}
+
+// ToActionWorkflowRun convert a actions_model.ActionRun to an api.ActionWorkflowRun
+func ToActionWorkflowRun(ctx context.Context, run *actions_model.ActionRun) (*api.ActionWorkflowRun, error) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+
+	status, conclusion := ToActionsStatus(run.Status)
+	return &api.ActionWorkflowRun{
+		ID:               run.ID,
+		Name:             run.WorkflowID,
+		DisplayTitle:     run.Title,
+		RunNumber:        run.Index,
+		Event:            run.TriggerEvent,
+		Status:           status,
+		Conclusion:       conclusion,
+		WorkflowID:       run.WorkflowID,
+		HeadBranch:       run.PrettyRef(),
+		HeadSHA:          run.CommitSHA,
+		HTMLURL:          run.HTMLURL(),
+		Actor:            ToUser(ctx, run.TriggerUser, nil),
+		ConcurrencyGroup: run.ConcurrencyGroup,
+		CreatedAt:        run.Created.AsLocalTime(),
+		UpdatedAt:        run.Updated.AsLocalTime(),
+		RunStartedAt:     timeStampToAPI(run.Started),
+		CompletedAt:      timeStampToAPI(run.Stopped),
+	}, nil
+}
+
+// ToActionWorkflowJob convert a actions_model.ActionRunJob to an api.ActionWorkflowJob,
+// the task could be nil if the job hasn't been picked by a runner yet
+func ToActionWorkflowJob(ctx context.Context, job *actions_model.ActionRunJob, task *actions_model.ActionTask) (*api.ActionWorkflowJob, error) {
+	if err := job.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+
+	// the link of a job in the UI is the index of the job in its run
+	jobs, err := actions_model.GetRunJobsByRunID(ctx, job.RunID)
+	if err != nil {
+		return nil, err
+	}
+	jobIndex := 0
+	for i, v := range jobs {
+		if v.ID == job.ID {
+			jobIndex = i
+			break
+		}
+	}
+
+	status, conclusion := ToActionsStatus(job.Status)
+	apiJob := &api.ActionWorkflowJob{
+		ID:          job.ID,
+		RunID:       job.RunID,
+		RunURL:      job.Run.HTMLURL(),
+		Name:        job.Name,
+		HeadSHA:     job.CommitSHA,
+		Status:      status,
+		Conclusion:  conclusion,
+		HTMLURL:     fmt.Sprintf("%s/jobs/%d", job.Run.HTMLURL(), jobIndex),
+		Labels:      job.RunsOn,
+		Steps:       make([]*api.ActionWorkflowStep, 0),
+		CreatedAt:   job.Created.AsLocalTime(),
+		StartedAt:   timeStampToAPI(job.Started),
+		CompletedAt: timeStampToAPI(job.Stopped),
+	}
+
+	if task == nil {
+		return apiJob, nil
+	}
+	if err := task.LoadAttributes(ctx); err != nil {
+		return nil, err
+	}
+
+	apiJob.RunnerID = task.RunnerID
+	if runner, err := actions_model.GetRunnerByID(ctx, task.RunnerID); err == nil {
+		apiJob.RunnerName = runner.Name
+	}
+	for _, step := range task.Steps {
+		stepStatus, stepConclusion := ToActionsStatus(step.Status)
+		apiJob.Steps = append(apiJob.Steps, &api.ActionWorkflowStep{
+			Name:        step.Name,
+			Number:      step.Index + 1,
+			Status:      stepStatus,
+			Conclusion:  stepConclusion,
+			StartedAt:   timeStampToAPI(step.Started),
+			CompletedAt: timeStampToAPI(step.Stopped),
+		})
+	}
+	return apiJob, nil
+}
+
+// ToActionWorkflow convert a workflow file in the directory of the commit to an api.ActionWorkflow
+func ToActionWorkflow(ctx context.Context, repo *repo_model.Repository, commit *git.Commit, dir string, entry *git.TreeEntry) (*api.ActionWorkflow, error) {
+	content, err := actions.GetContentFromEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	name := entry.Name()
+	if wf, err := model.ReadWorkflow(bytes.NewReader(content)); err == nil && wf.Name != "" {
+		name = wf.Name
+	}
+
+	state := "active"
+	if repo.MustGetUnit(ctx, unit.TypeActions).ActionsConfig().IsWorkflowDisabled(entry.Name()) {
+		state = "disabled_manually"
+	}
+
+	workflowPath := path.Join(dir, entry.Name())
+	updated := commit.Committer.When
+	if last, err := commit.GetCommitByPath(workflowPath); err == nil {
+		updated = last.Committer.When
+	}
+
+	return &api.ActionWorkflow{
+		ID:        entry.Name(),
+		Name:      name,
+		Path:      workflowPath,
+		State:     state,
+		HTMLURL:   fmt.Sprintf("%s/actions?workflow=%s", repo.HTMLURL(), url.QueryEscape(entry.Name())),
+		BadgeURL:  fmt.Sprintf("%s/actions/workflows/%s/badge.svg", repo.HTMLURL(), url.PathEscape(entry.Name())),
+		UpdatedAt: updated,
+	}, nil
+}
+
+// ToActionArtifact convert an artifact of the run to an api.ActionArtifact
+func ToActionArtifact(repo *repo_model.Repository, art *actions_model.ActionArtifactMeta, run *actions_model.ActionRun) *api.ActionArtifact {
+	artifactURL := fmt.Sprintf("%s/actions/artifacts/%d", repo.APIURL(), art.ID)
+	return &api.ActionArtifact{
+		ID:                 art.ID,
+		Name:               art.ArtifactName,
+		SizeInBytes:        art.FileSize,
+		URL:                artifactURL,
+		ArchiveDownloadURL: artifactURL + "/zip",
+		Expired:            art.Status == actions_model.ArtifactStatusExpired,
+		WorkflowRun: &api.ActionWorkflowRunSummary{
+			ID:           run.ID,
+			RepositoryID: run.RepoID,
+			HeadBranch:   run.PrettyRef(),
+			HeadSHA:      run.CommitSHA,
+		},
+		CreatedAt: art.CreatedUnix.AsLocalTime(),
+		UpdatedAt: art.UpdatedUnix.AsLocalTime(),
+		ExpiresAt: art.ExpiredUnix.AsLocalTime(),
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAuditEvent converts an audit event to API format
+func ToAuditEvent(e *audit_model.Event) *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:         e.ID,
+		Action:     string(e.Action),
+		ActorID:    e.ActorID,
+		Actor:      e.ActorName,
+		IPAddress:  e.IPAddress,
+		OwnerID:    e.OwnerID,
+		RepoID:     e.RepoID,
+		TargetType: string(e.TargetType),
+		TargetID:   e.TargetID,
+		Target:     e.TargetName,
+		Before:     toAuditState(e.Before),
+		After:      toAuditState(e.After),
+		Hash:       e.Hash,
+		Created:    e.CreatedUnix.AsTime(),
+	}
+}
+
+func toAuditState(state string) map[string]any {
+	if state == "" {
+		return nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(state), &m); err != nil {
+		log.Error("Unable to unmarshal audit state: %v", err)
+		return nil
+	}
+	return m
+}
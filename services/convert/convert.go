@@ -187,6 +187,8 @@ func ToBranchProtection(ctx context.Context, bp *git_model.ProtectedBranch, repo
 		ProtectedFilePatterns:         bp.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       bp.UnprotectedFilePatterns,
 		BlockAdminMergeOverride:       bp.BlockAdminMergeOverride,
+		EnableMergeQueue:              bp.EnableMergeQueue,
+		RequireCodeOwnerApproval:      bp.RequireCodeOwnerApproval,
 		Created:                       bp.CreatedUnix.AsTime(),
 		Updated:                       bp.UpdatedUnix.AsTime(),
 	}
@@ -213,19 +215,20 @@ func ToActionTask(ctx context.Context, t *actions_model.ActionTask) (*api.Action
 	url := strings.TrimSuffix(setting.AppURL, "/") + t.GetRunLink()
 
 	return &api.ActionTask{
-		ID:           t.ID,
-		Name:         t.Job.Name,
-		HeadBranch:   t.Job.Run.PrettyRef(),
-		HeadSHA:      t.Job.CommitSHA,
-		RunNumber:    t.Job.Run.Index,
-		Event:        t.Job.Run.TriggerEvent,
-		DisplayTitle: t.Job.Run.Title,
-		Status:       t.Status.String(),
-		WorkflowID:   t.Job.Run.WorkflowID,
-		URL:          url,
-		CreatedAt:    t.Created.AsLocalTime(),
-		UpdatedAt:    t.Updated.AsLocalTime(),
-		RunStartedAt: t.Started.AsLocalTime(),
+		ID:               t.ID,
+		Name:             t.Job.Name,
+		HeadBranch:       t.Job.Run.PrettyRef(),
+		HeadSHA:          t.Job.CommitSHA,
+		RunNumber:        t.Job.Run.Index,
+		Event:            t.Job.Run.TriggerEvent,
+		DisplayTitle:     t.Job.Run.Title,
+		Status:           t.Status.String(),
+		WorkflowID:       t.Job.Run.WorkflowID,
+		URL:              url,
+		CreatedAt:        t.Created.AsLocalTime(),
+		UpdatedAt:        t.Updated.AsLocalTime(),
+		RunStartedAt:     t.Started.AsLocalTime(),
+		ConcurrencyGroup: t.Job.Run.ConcurrencyGroup,
 	}, nil
 }
 
@@ -463,6 +466,7 @@ func ToOAuth2Application(app *auth.OAuth2Application) *api.OAuth2Application {
 		ClientSecret:               app.ClientSecret,
 		ConfidentialClient:         app.ConfidentialClient,
 		SkipSecondaryAuthorization: app.SkipSecondaryAuthorization,
+		EnableDeviceFlow:           app.EnableDeviceFlow,
 		RedirectURIs:               app.RedirectURIs,
 		Created:                    app.CreatedUnix.AsTime(),
 	}
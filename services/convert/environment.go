@@ -0,0 +1,50 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"context"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToEnvironment converts an actions_model.ActionEnvironment to an api.Environment
+func ToEnvironment(ctx context.Context, env *actions_model.ActionEnvironment, doer *user_model.User) (*api.Environment, error) {
+	reviewers, err := user_model.GetUsersByIDs(ctx, env.RequiredReviewers)
+	if err != nil {
+		return nil, err
+	}
+	branchFilters := env.BranchFilters
+	if branchFilters == nil {
+		branchFilters = []string{}
+	}
+	return &api.Environment{
+		ID:            env.ID,
+		Name:          env.Name,
+		WaitTimer:     env.WaitTimer,
+		BranchFilters: branchFilters,
+		Reviewers:     ToUsers(ctx, doer, reviewers),
+		Created:       env.CreatedUnix.AsTime(),
+		Updated:       env.UpdatedUnix.AsTime(),
+	}, nil
+}
+
+// ToDeployment converts an actions_model.ActionDeployment to an api.Deployment,
+// the attributes of the deployment must have been loaded
+func ToDeployment(ctx context.Context, d *actions_model.ActionDeployment, doer *user_model.User) *api.Deployment {
+	return &api.Deployment{
+		ID:          d.ID,
+		Environment: d.Environment.Name,
+		Status:      d.Status.String(),
+		Ref:         d.Ref,
+		SHA:         d.CommitSHA,
+		RunID:       d.RunID,
+		JobID:       d.JobID,
+		Creator:     ToUser(ctx, d.Creator, doer),
+		Created:     d.CreatedUnix.AsTime(),
+		Updated:     d.UpdatedUnix.AsTime(),
+	}
+}
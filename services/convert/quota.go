@@ -0,0 +1,64 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package convert
+
+import (
+	"context"
+
+	quota_model "code.gitea.io/gitea/models/quota"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToQuotaRule converts a quota rule to API format
+func ToQuotaRule(rule *quota_model.Rule) *api.QuotaRule {
+	subjects := make([]string, len(rule.Subjects))
+	for i, subject := range rule.Subjects {
+		subjects[i] = string(subject)
+	}
+	return &api.QuotaRule{
+		Name:     rule.Name,
+		Limit:    rule.Limit,
+		Subjects: subjects,
+	}
+}
+
+// ToQuotaGroup converts a quota group with its loaded rules to API format
+func ToQuotaGroup(group *quota_model.Group) *api.QuotaGroup {
+	rules := make([]*api.QuotaRule, len(group.Rules))
+	for i, rule := range group.Rules {
+		rules[i] = ToQuotaRule(rule)
+	}
+	return &api.QuotaGroup{
+		Name:  group.Name,
+		Rules: rules,
+	}
+}
+
+// ToQuotaInfo returns the used storage and the quota groups of a user or an organization in API format
+func ToQuotaInfo(ctx context.Context, userID int64) (*api.QuotaInfo, error) {
+	used, err := quota_model.GetUsedForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := quota_model.GetGroupsOfUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiGroups := make([]*api.QuotaGroup, len(groups))
+	for i, group := range groups {
+		apiGroups[i] = ToQuotaGroup(group)
+	}
+	return &api.QuotaInfo{
+		Used: &api.QuotaUsed{
+			Git:         used.Git,
+			LFS:         used.LFS,
+			Packages:    used.Packages,
+			Artifacts:   used.Artifacts,
+			Attachments: used.Attachments,
+			Total:       used.Total(),
+		},
+		Groups: apiGroups,
+	}, nil
+}
@@ -38,6 +38,12 @@ func ToGitServiceType(value string) structs.GitServiceType {
 		return structs.GitBucketService
 	case "codecommit":
 		return structs.CodeCommitService
+	case "bitbucket":
+		return structs.BitbucketService
+	case "bitbucketserver":
+		return structs.BitbucketServerService
+	case "azuredevops":
+		return structs.AzureDevOpsService
 	default:
 		return structs.PlainGitService
 	}
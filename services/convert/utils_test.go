@@ -28,6 +28,12 @@ func TestToGitServiceType(t *testing.T) {
 		typ: "gitlab", enum: 4,
 	}, {
 		typ: "gogs", enum: 5,
+	}, {
+		typ: "bitbucket", enum: 10,
+	}, {
+		typ: "bitbucketserver", enum: 11,
+	}, {
+		typ: "azuredevops", enum: 12,
 	}, {
 		typ: "trash", enum: 1,
 	}}
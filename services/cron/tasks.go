@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/translation"
+	"code.gitea.io/gitea/services/maintenance"
 )
 
 var (
@@ -62,8 +63,13 @@ func (t *Task) GetConfig() Config {
 	return reflect.New(reflect.TypeOf(t.config)).Elem().Interface().(Config)
 }
 
-// Run will run the task incrementing the cron counter with no user defined
+// Run will run the task incrementing the cron counter with no user defined,
+// the scheduled runs are skipped while the instance is in the maintenance mode
 func (t *Task) Run() {
+	if maintenance.IsEnabled(graceful.GetManager().ShutdownContext()) {
+		log.Debug("Skipping task %s: the instance is in maintenance mode", t.Name)
+		return
+	}
 	t.RunWithUser(&user_model.User{
 		ID:        -1,
 		Name:      "(Cron)",
@@ -17,8 +17,10 @@ import (
 	"code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	packages_cleanup_service "code.gitea.io/gitea/services/packages/cleanup"
+	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	"code.gitea.io/gitea/services/repository/housekeeping"
 )
 
 func registerUpdateMirrorTask() {
@@ -166,6 +168,30 @@ func registerSyncRepoLicenses() {
 	})
 }
 
+func registerRepoHousekeeping() {
+	RegisterTaskFatal("repo_housekeeping", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return housekeeping.QueueAllRepositories(ctx)
+	})
+}
+
+func registerMergeQueueChecksTimeout() {
+	RegisterTaskFatal("merge_queue_checks_timeout", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 10m",
+		},
+		OlderThan: time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		realConfig := config.(*OlderThanConfig)
+		return pull_service.RemoveTimedOutFromMergeQueues(ctx, realConfig.OlderThan)
+	})
+}
+
 func initBasicTasks() {
 	if setting.Mirror.Enabled {
 		registerUpdateMirrorTask()
@@ -183,4 +209,8 @@ func initBasicTasks() {
 		registerCleanupPackages()
 	}
 	registerSyncRepoLicenses()
+	if setting.Repository.Housekeeping.Enabled {
+		registerRepoHousekeeping()
+	}
+	registerMergeQueueChecksTimeout()
 }
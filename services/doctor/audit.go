@@ -0,0 +1,38 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package doctor
+
+import (
+	"context"
+	"errors"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	"code.gitea.io/gitea/modules/log"
+)
+
+func checkAuditLog(ctx context.Context, logger log.Logger, autofix bool) error {
+	count, err := audit_model.VerifyChain(ctx)
+	if err != nil {
+		var errBroken audit_model.ErrChainBroken
+		if errors.As(err, &errBroken) {
+			// the events are evidence, a broken chain can't be fixed and must be investigated
+			logger.Critical("Audit log has been tampered with: event %d or the event before it was modified or deleted after %d verified events", errBroken.EventID, count)
+		} else {
+			logger.Critical("Error: %v whilst verifying the audit log", err)
+		}
+		return err
+	}
+	logger.Info("%d audit events verified", count)
+	return nil
+}
+
+func init() {
+	Register(&Check{
+		Title:     "Check the hash chain of the audit log",
+		Name:      "check-audit-log",
+		IsDefault: false,
+		Run:       checkAuditLog,
+		Priority:  3,
+	})
+}
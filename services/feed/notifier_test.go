@@ -15,6 +15,7 @@ import (
 
 	_ "code.gitea.io/gitea/models"
 	_ "code.gitea.io/gitea/models/actions"
+	_ "code.gitea.io/gitea/models/audit"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forms
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/context"
+
+	"gitea.com/go-chi/binding"
+)
+
+// CreateEnvironmentForm form for creating a deployment environment
+type CreateEnvironmentForm struct {
+	Name string `binding:"Required;MaxSize(255)"`
+}
+
+// Validate validates form fields
+func (f *CreateEnvironmentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EditEnvironmentForm form for editing the protection rules of a deployment environment
+type EditEnvironmentForm struct {
+	WaitTimer     int64
+	BranchFilters string // one glob pattern per line
+	Reviewers     string // comma separated user ids
+}
+
+// Validate validates form fields
+func (f *EditEnvironmentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// ReviewDeploymentForm form for approving or rejecting a pending deployment
+type ReviewDeploymentForm struct {
+	Approve bool
+	Comment string
+}
+
+// Validate validates form fields
+func (f *ReviewDeploymentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -172,6 +172,9 @@ type RepoSettingForm struct {
 	// Signing Settings
 	TrustModel string
 
+	// Partial Clone Settings
+	EnablePartialClone bool
+
 	// Admin settings
 	EnableHealthCheck  bool
 	RequestReindexType string
@@ -220,6 +223,8 @@ type ProtectBranchForm struct {
 	ProtectedFilePatterns         string
 	UnprotectedFilePatterns       string
 	BlockAdminMergeOverride       bool
+	EnableMergeQueue              bool
+	RequireCodeOwnerApproval      bool
 }
 
 // Validate validates the fields
@@ -263,6 +268,8 @@ type WebhookForm struct {
 	Wiki                     bool
 	Repository               bool
 	Package                  bool
+	WorkflowRun              bool
+	WorkflowJob              bool
 	Active                   bool
 	BranchFilter             string `binding:"GlobPattern"`
 	AuthorizationHeader      string
@@ -0,0 +1,26 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package forms
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/web/middleware"
+	"code.gitea.io/gitea/services/context"
+
+	"gitea.com/go-chi/binding"
+)
+
+// RequiredWorkflowForm form for requiring a workflow for the repositories of an organization
+type RequiredWorkflowForm struct {
+	RepoID       int64
+	WorkflowPath string `binding:"MaxSize(255)"`
+	RepoPatterns string // one glob pattern per line
+}
+
+// Validate validates form fields
+func (f *RequiredWorkflowForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
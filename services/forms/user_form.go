@@ -185,6 +185,9 @@ type AccessTokenForm struct {
 
 	// PKCE support
 	CodeVerifier string `json:"code_verifier"`
+
+	// device authorization grant support
+	DeviceCode string `json:"device_code"`
 }
 
 // Validate validates the fields
@@ -193,6 +196,32 @@ func (f *AccessTokenForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// DeviceAuthorizationForm for starting the device authorization grant of a device
+type DeviceAuthorizationForm struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Scope        string `json:"scope"`
+}
+
+// Validate validates the fields
+func (f *DeviceAuthorizationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// DeviceVerificationForm form for approving or denying a device by its user code
+type DeviceVerificationForm struct {
+	UserCode string `binding:"Required"`
+	Confirm  bool
+	Granted  bool
+}
+
+// Validate validates the fields
+func (f *DeviceVerificationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // IntrospectTokenForm for introspecting tokens
 type IntrospectTokenForm struct {
 	Token string `json:"token"`
@@ -369,6 +398,7 @@ type EditOAuth2ApplicationForm struct {
 	RedirectURIs               string `binding:"Required;ValidUrlList" form:"redirect_uris"`
 	ConfidentialClient         bool   `form:"confidential_client"`
 	SkipSecondaryAuthorization bool   `form:"skip_secondary_authorization"`
+	EnableDeviceFlow           bool   `form:"enable_device_flow"`
 }
 
 // Validate validates the fields
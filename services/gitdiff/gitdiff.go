@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -81,6 +82,7 @@ type DiffLine struct {
 	Type        DiffLineType
 	Content     string
 	Comments    []*issues_model.Comment
+	Annotations []*actions_model.ActionTaskAnnotation
 	SectionInfo *DiffLineSectionInfo
 }
 
@@ -484,6 +486,39 @@ func (diff *Diff) LoadComments(ctx context.Context, issue *issues_model.Issue, c
 	return nil
 }
 
+// LoadAnnotations attaches the annotations of the Actions tasks which ran on the commit to the new lines of the diff,
+// an annotation covering several lines is attached to the first of them in the diff.
+func (diff *Diff) LoadAnnotations(ctx context.Context, repoID int64, commitSHA string) error {
+	annotations, err := db.Find[actions_model.ActionTaskAnnotation](ctx, actions_model.FindTaskAnnotationOptions{
+		RepoID:    repoID,
+		CommitSHA: commitSHA,
+		HasPath:   true,
+	})
+	if err != nil {
+		return err
+	}
+	for _, a := range annotations {
+		diff.attachAnnotation(a)
+	}
+	return nil
+}
+
+func (diff *Diff) attachAnnotation(a *actions_model.ActionTaskAnnotation) {
+	for _, file := range diff.Files {
+		if file.Name != a.Path {
+			continue
+		}
+		for _, section := range file.Sections {
+			for _, line := range section.Lines {
+				if line.Type != DiffLineDel && line.Type != DiffLineSection && a.CoversLine(file.Name, int64(line.RightIdx)) {
+					line.Annotations = append(line.Annotations, a)
+					return
+				}
+			}
+		}
+	}
+}
+
 const cmdDiffHead = "diff --git "
 
 // ParsePatch builds a Diff object from a io.Reader and some parameters.
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/unittest"
@@ -613,6 +614,27 @@ func TestDiff_LoadCommentsWithOutdated(t *testing.T) {
 	assert.Len(t, diff.Files[0].Sections[0].Lines[0].Comments, 3)
 }
 
+func TestDiff_LoadAnnotations(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	task := &actions_model.ActionTask{ID: 1, RepoID: 1, CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Job: &actions_model.ActionRunJob{RunID: 1}}
+	assert.NoError(t, actions_model.InsertTaskAnnotations(db.DefaultContext, task, []*actions_model.ActionTaskAnnotation{
+		{Level: "error", Message: "covers the line", Path: "README.md", StartLine: 3, EndLine: 5},
+		{Level: "warning", Message: "another file", Path: "main.go", StartLine: 4, EndLine: 4},
+		{Level: "notice", Message: "no location"},
+	}))
+
+	diff := setupDefaultDiff()
+	assert.NoError(t, diff.LoadAnnotations(db.DefaultContext, 1, task.CommitSHA))
+	if assert.Len(t, diff.Files[0].Sections[0].Lines[0].Annotations, 1) {
+		assert.Equal(t, "covers the line", diff.Files[0].Sections[0].Lines[0].Annotations[0].Message)
+	}
+
+	diff = setupDefaultDiff()
+	assert.NoError(t, diff.LoadAnnotations(db.DefaultContext, 1, "0000000000000000000000000000000000000000"))
+	assert.Empty(t, diff.Files[0].Sections[0].Lines[0].Annotations)
+}
+
 func TestDiffLine_CanComment(t *testing.T) {
 	assert.False(t, (&DiffLine{Type: DiffLineSection}).CanComment())
 	assert.False(t, (&DiffLine{Type: DiffLineAdd, Comments: []*issues_model.Comment{{Content: "bla"}}}).CanComment())
@@ -11,6 +11,7 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	org_model "code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/gitrepo"
 	"code.gitea.io/gitea/modules/log"
@@ -40,9 +41,74 @@ type ReviewRequestNotifier struct {
 	ReviewTeam *org_model.Team
 }
 
-func PullRequestCodeOwnersReview(ctx context.Context, issue *issues_model.Issue, pr *issues_model.PullRequest) ([]*ReviewRequestNotifier, error) {
-	files := []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+// codeOwnersFiles are the paths where the CODEOWNERS file is looked up, the first one found is used
+var codeOwnersFiles = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+
+// GetCodeOwnerRules returns the code owner rules of the CODEOWNERS file in the given commit
+func GetCodeOwnerRules(ctx context.Context, commit *git.Commit) []*issues_model.CodeOwnerRule {
+	var data string
+	for _, file := range codeOwnersFiles {
+		if blob, err := commit.GetBlobByPath(file); err == nil {
+			data, err = blob.GetBlobContent(setting.UI.MaxDisplayFileSize)
+			if err == nil {
+				break
+			}
+		}
+	}
+
+	rules, _ := issues_model.GetCodeOwnersFromContent(ctx, data)
+	return rules
+}
+
+// GetPullRequestChangedFiles returns the files changed by the pull request
+func GetPullRequestChangedFiles(repo *git.Repository, pr *issues_model.PullRequest) ([]string, error) {
+	// get the mergebase
+	mergeBase, err := getMergeBase(repo, pr, git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+	if err != nil {
+		return nil, err
+	}
+
+	// https://github.com/go-gitea/gitea/issues/29763, we need to get the files changed
+	// between the merge base and the head commit but not the base branch and the head commit
+	return repo.GetFilesChangedBetween(mergeBase, pr.GetGitRefName())
+}
+
+// MatchCodeOwnerRules returns the rules which match at least one of the files
+func MatchCodeOwnerRules(rules []*issues_model.CodeOwnerRule, files []string) []*issues_model.CodeOwnerRule {
+	matched := make([]*issues_model.CodeOwnerRule, 0, len(rules))
+	for _, rule := range rules {
+		for _, f := range files {
+			if (rule.Rule.MatchString(f) && !rule.Negative) || (!rule.Rule.MatchString(f) && rule.Negative) {
+				matched = append(matched, rule)
+				break
+			}
+		}
+	}
+	return matched
+}
 
+// MatchLastCodeOwnerRules returns the rules which own at least one of the files, the owners of a file
+// are the ones of the last rule matching it as the later rules take precedence
+func MatchLastCodeOwnerRules(rules []*issues_model.CodeOwnerRule, files []string) []*issues_model.CodeOwnerRule {
+	owning := make(container.Set[*issues_model.CodeOwnerRule])
+	for _, f := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if rules[i].Rule.MatchString(f) != rules[i].Negative {
+				owning.Add(rules[i])
+				break
+			}
+		}
+	}
+	matched := make([]*issues_model.CodeOwnerRule, 0, len(owning))
+	for _, rule := range rules {
+		if owning.Contains(rule) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+func PullRequestCodeOwnersReview(ctx context.Context, issue *issues_model.Issue, pr *issues_model.PullRequest) ([]*ReviewRequestNotifier, error) {
 	if pr.IsWorkInProgress(ctx) {
 		return nil, nil
 	}
@@ -70,43 +136,21 @@ func PullRequestCodeOwnersReview(ctx context.Context, issue *issues_model.Issue,
 		return nil, err
 	}
 
-	var data string
-	for _, file := range files {
-		if blob, err := commit.GetBlobByPath(file); err == nil {
-			data, err = blob.GetBlobContent(setting.UI.MaxDisplayFileSize)
-			if err == nil {
-				break
-			}
-		}
-	}
-
-	rules, _ := issues_model.GetCodeOwnersFromContent(ctx, data)
+	rules := GetCodeOwnerRules(ctx, commit)
 
-	// get the mergebase
-	mergeBase, err := getMergeBase(repo, pr, git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
-	if err != nil {
-		return nil, err
-	}
-
-	// https://github.com/go-gitea/gitea/issues/29763, we need to get the files changed
-	// between the merge base and the head commit but not the base branch and the head commit
-	changedFiles, err := repo.GetFilesChangedBetween(mergeBase, pr.GetGitRefName())
+	changedFiles, err := GetPullRequestChangedFiles(repo, pr)
 	if err != nil {
 		return nil, err
 	}
 
 	uniqUsers := make(map[int64]*user_model.User)
 	uniqTeams := make(map[string]*org_model.Team)
-	for _, rule := range rules {
-		for _, f := range changedFiles {
-			if (rule.Rule.MatchString(f) && !rule.Negative) || (!rule.Rule.MatchString(f) && rule.Negative) {
-				for _, u := range rule.Users {
-					uniqUsers[u.ID] = u
-				}
-				for _, t := range rule.Teams {
-					uniqTeams[fmt.Sprintf("%d/%d", t.OrgID, t.ID)] = t
-				}
-			}
+	for _, rule := range MatchCodeOwnerRules(rules, changedFiles) {
+		for _, u := range rule.Users {
+			uniqUsers[u.ID] = u
+		}
+		for _, t := range rule.Teams {
+			uniqTeams[fmt.Sprintf("%d/%d", t.OrgID, t.ID)] = t
 		}
 	}
 
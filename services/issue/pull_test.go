@@ -0,0 +1,31 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issue
+
+import (
+	"regexp"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchLastCodeOwnerRules(t *testing.T) {
+	newRule := func(pattern string, negative bool) *issues_model.CodeOwnerRule {
+		return &issues_model.CodeOwnerRule{Pattern: pattern, Rule: regexp.MustCompile("^" + pattern + "$"), Negative: negative}
+	}
+	all := newRule(".*", false)
+	docs := newRule("docs/.*", false)
+	markdown := newRule(".*\\.md", false)
+	notGo := newRule(".*\\.go", true)
+	rules := []*issues_model.CodeOwnerRule{all, docs, markdown, notGo}
+
+	// the files are owned by the last rule matching them
+	assert.Equal(t, []*issues_model.CodeOwnerRule{notGo}, MatchLastCodeOwnerRules(rules, []string{"docs/index.md"}))
+	assert.Equal(t, []*issues_model.CodeOwnerRule{all}, MatchLastCodeOwnerRules(rules, []string{"main.go"}))
+	assert.Equal(t, []*issues_model.CodeOwnerRule{docs}, MatchLastCodeOwnerRules(rules[:3], []string{"docs/index.go"}))
+	assert.Equal(t, []*issues_model.CodeOwnerRule{docs, markdown}, MatchLastCodeOwnerRules(rules[:3], []string{"README.md", "docs/index.go", "docs/api.go"}))
+	assert.Empty(t, MatchLastCodeOwnerRules(rules[1:3], []string{"main.go"}))
+}
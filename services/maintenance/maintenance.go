@@ -0,0 +1,79 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package maintenance implements the read-only maintenance mode of the instance: the changes of data are rejected,
+// the background work is paused and the reads keep working.
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// DefaultMessage is shown to the users while the maintenance mode is enabled if the administrators don't set a message
+const DefaultMessage = "This instance is in read-only maintenance mode, changes are not accepted at the moment."
+
+// checkInterval is how often the mode is checked to pause or resume the queues, the administrators may change it
+// on any instance of a cluster
+const checkInterval = 10 * time.Second
+
+// IsEnabled returns whether the instance is in the maintenance mode
+func IsEnabled(ctx context.Context) bool {
+	return setting.Config().Maintenance.Enabled.Value(ctx)
+}
+
+// Message returns the message shown to the users while the maintenance mode is enabled
+func Message(ctx context.Context) string {
+	if msg := setting.Config().Maintenance.Message.Value(ctx); msg != "" {
+		return msg
+	}
+	return DefaultMessage
+}
+
+// IsReadOnlyRequest returns whether the request method doesn't change data, these requests are served during the maintenance
+func IsReadOnlyRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// SyncQueues pauses the queues if the maintenance mode is enabled and resumes them otherwise
+func SyncQueues(ctx context.Context) {
+	enabled := IsEnabled(ctx)
+	if enabled == queue.GetManager().IsPaused() {
+		return
+	}
+	if enabled {
+		log.Info("Maintenance mode is enabled, pausing the queues")
+		queue.GetManager().Pause()
+	} else {
+		log.Info("Maintenance mode is disabled, resuming the queues")
+		queue.GetManager().Resume()
+	}
+}
+
+// Init pauses the queues while the maintenance mode is enabled
+func Init() error {
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		SyncQueues(ctx)
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				SyncQueues(ctx)
+			}
+		}
+	})
+	return nil
+}
@@ -0,0 +1,902 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &AzureDevOpsDownloader{}
+	_ base.DownloaderFactory = &AzureDevOpsDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&AzureDevOpsDownloaderFactory{})
+}
+
+// azureDevOpsAPIVersion is the version of the REST API, it is supported since Azure DevOps Server 2020
+const azureDevOpsAPIVersion = "6.0"
+
+// AzureDevOpsDownloaderFactory defines an Azure DevOps downloader factory
+type AzureDevOpsDownloaderFactory struct{}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *AzureDevOpsDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, projectName, repoName, err := parseAzureDevOpsURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("Create Azure DevOps downloader. BaseURL: %s Project: %s RepoName: %s", baseURL, projectName, repoName)
+
+	return NewAzureDevOpsDownloader(ctx, baseURL, projectName, repoName, opts.AuthToken), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *AzureDevOpsDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.AzureDevOpsService
+}
+
+// parseAzureDevOpsURL parses the clone URL `<collection>/<project>/_git/<repo>` of a repository,
+// the collection is `https://dev.azure.com/<organization>` or `https://<organization>.visualstudio.com`
+// for Azure DevOps Services and `https://<host>/<path>/<collection>` for Azure DevOps Server.
+func parseAzureDevOpsURL(u *url.URL) (baseURL, projectName, repoName string, err error) {
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	i := slices.Index(fields, "_git")
+	if i < 0 || i+1 >= len(fields) {
+		return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+	}
+	repoName = strings.TrimSuffix(fields[i+1], ".git")
+	collection := fields[:i]
+
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "dev.azure.com" && len(collection) == 1, strings.HasSuffix(host, ".visualstudio.com") && len(collection) == 0:
+		// the project is omitted if the repository has the same name as the project
+		projectName = repoName
+	case len(collection) > 0:
+		projectName, collection = collection[len(collection)-1], collection[:len(collection)-1]
+	default:
+		return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+	}
+
+	baseURL = u.Scheme + "://" + u.Host
+	for _, field := range collection {
+		baseURL += "/" + url.PathEscape(field)
+	}
+	return baseURL, projectName, repoName, nil
+}
+
+type azureDevOpsIdentity struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+// Name returns the display name of the identity, the unique names are the emails or the domain accounts
+func (i *azureDevOpsIdentity) Name() string {
+	if i == nil {
+		return ""
+	}
+	if i.DisplayName != "" {
+		return i.DisplayName
+	}
+	return i.UniqueName
+}
+
+func (i *azureDevOpsIdentity) Email() string {
+	if i == nil || !strings.Contains(i.UniqueName, "@") {
+		return ""
+	}
+	return i.UniqueName
+}
+
+type azureDevOpsIssueContext struct {
+	IsPullRequest bool
+}
+
+// AzureDevOpsDownloader implements a Downloader interface to get repository information
+// from Azure DevOps Services or Azure DevOps Server via the REST API.
+// The work items of the project are migrated as the issues, the iterations as the milestones and the tags as the labels.
+type AzureDevOpsDownloader struct {
+	base.NullDownloader
+	ctx             context.Context
+	client          *http.Client
+	baseURL         string
+	projectName     string
+	repoName        string
+	token           string
+	lastWorkItemID  int64
+	maxIssueIndex   int64
+	stateCategories map[string]map[string]string
+}
+
+// NewAzureDevOpsDownloader creates an Azure DevOps downloader, the token is a personal access token
+func NewAzureDevOpsDownloader(ctx context.Context, baseURL, projectName, repoName, token string) *AzureDevOpsDownloader {
+	return &AzureDevOpsDownloader{
+		ctx:             ctx,
+		client:          NewMigrationHTTPClient(),
+		baseURL:         strings.TrimSuffix(baseURL, "/"),
+		projectName:     projectName,
+		repoName:        repoName,
+		token:           token,
+		stateCategories: make(map[string]map[string]string),
+	}
+}
+
+// SetContext set context
+func (d *AzureDevOpsDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *AzureDevOpsDownloader) String() string {
+	return fmt.Sprintf("migration from azure devops %s %s/%s", d.baseURL, d.projectName, d.repoName)
+}
+
+func (d *AzureDevOpsDownloader) LogString() string {
+	if d == nil {
+		return "<AzureDevOpsDownloader nil>"
+	}
+	return fmt.Sprintf("<AzureDevOpsDownloader %s %s/%s>", d.baseURL, d.projectName, d.repoName)
+}
+
+func (d *AzureDevOpsDownloader) projectPath() string {
+	return "/" + url.PathEscape(d.projectName)
+}
+
+func (d *AzureDevOpsDownloader) repoPath() string {
+	return d.projectPath() + "/_apis/git/repositories/" + url.PathEscape(d.repoName)
+}
+
+func (d *AzureDevOpsDownloader) newRequest(method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(d.ctx, method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if d.token != "" {
+		// the personal access tokens are used as the password of the basic authentication with any user name
+		req.SetBasicAuth("", d.token)
+	}
+	return req, nil
+}
+
+// callAPI requests the endpoint with the default api-version if it is not in the parameter,
+// the body is sent as JSON if it isn't nil
+func (d *AzureDevOpsDownloader) callAPI(method, endpoint string, parameter url.Values, body, result any) error {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	if !parameter.Has("api-version") {
+		parameter.Set("api-version", azureDevOpsAPIVersion)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(bs)
+	}
+	req, err := d.newRequest(method, d.baseURL+endpoint+"?"+parameter.Encode(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s of %s", resp.Status, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type azureDevOpsList[T any] struct {
+	Count int `json:"count"`
+	Value []T `json:"value"`
+}
+
+// sanitizeAzureDevOpsRemoteURL removes the organization name in the user info of the remote URL
+func sanitizeAzureDevOpsRemoteURL(remoteURL string) string {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return remoteURL
+	}
+	u.User = nil
+	return u.String()
+}
+
+// GetRepoInfo returns repository information
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/repositories/get-repository
+func (d *AzureDevOpsDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		Name          string `json:"name"`
+		DefaultBranch string `json:"defaultBranch"`
+		RemoteURL     string `json:"remoteUrl"`
+		WebURL        string `json:"webUrl"`
+		Project       struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Visibility  string `json:"visibility"`
+		} `json:"project"`
+	}
+	if err := d.callAPI("GET", d.repoPath(), nil, nil, &rawRepo); err != nil {
+		return nil, err
+	}
+
+	return &base.Repository{
+		Name:          rawRepo.Name,
+		Owner:         rawRepo.Project.Name,
+		IsPrivate:     rawRepo.Project.Visibility != "public",
+		Description:   rawRepo.Project.Description,
+		CloneURL:      sanitizeAzureDevOpsRemoteURL(rawRepo.RemoteURL),
+		OriginalURL:   rawRepo.WebURL,
+		DefaultBranch: strings.TrimPrefix(rawRepo.DefaultBranch, "refs/heads/"),
+	}, nil
+}
+
+type azureDevOpsIteration struct {
+	Name       string `json:"name"`
+	Attributes struct {
+		StartDate  *time.Time `json:"startDate"`
+		FinishDate *time.Time `json:"finishDate"`
+	} `json:"attributes"`
+	Children []*azureDevOpsIteration `json:"children"`
+}
+
+// azureDevOpsIterationTitle returns the milestone title of the iteration path of a work item,
+// the path starts with the name of the project which is the root iteration
+func azureDevOpsIterationTitle(iterationPath string) string {
+	_, title, _ := strings.Cut(iterationPath, `\`)
+	return strings.ReplaceAll(title, `\`, "/")
+}
+
+// GetMilestones returns the iterations of the project
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/classification-nodes/get
+func (d *AzureDevOpsDownloader) GetMilestones() ([]*base.Milestone, error) {
+	var root azureDevOpsIteration
+	if err := d.callAPI("GET", d.projectPath()+"/_apis/wit/classificationnodes/Iterations", url.Values{
+		"$depth": {"10"},
+	}, nil, &root); err != nil {
+		return nil, err
+	}
+
+	var milestones []*base.Milestone
+	var walk func(prefix string, iterations []*azureDevOpsIteration)
+	walk = func(prefix string, iterations []*azureDevOpsIteration) {
+		for _, iteration := range iterations {
+			title := prefix + iteration.Name
+			milestone := &base.Milestone{
+				Title:    title,
+				Deadline: iteration.Attributes.FinishDate,
+				State:    "open",
+			}
+			if iteration.Attributes.StartDate != nil {
+				milestone.Created = *iteration.Attributes.StartDate
+			}
+			if finish := iteration.Attributes.FinishDate; finish != nil && finish.Before(time.Now()) {
+				milestone.State = "closed"
+				milestone.Closed = finish
+			}
+			milestones = append(milestones, milestone)
+			walk(title+"/", iteration.Children)
+		}
+	}
+	walk("", root.Children)
+	return milestones, nil
+}
+
+// GetLabels returns the work item tags of the project
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/tags/list
+func (d *AzureDevOpsDownloader) GetLabels() ([]*base.Label, error) {
+	var tags azureDevOpsList[struct {
+		Name string `json:"name"`
+	}]
+	if err := d.callAPI("GET", d.projectPath()+"/_apis/wit/tags", url.Values{
+		"api-version": {azureDevOpsAPIVersion + "-preview.1"},
+	}, nil, &tags); err != nil {
+		return nil, err
+	}
+
+	labels := make([]*base.Label, 0, len(tags.Value))
+	for _, tag := range tags.Value {
+		labels = append(labels, &base.Label{
+			Name:  tag.Name,
+			Color: "ffffff",
+		})
+	}
+	return labels, nil
+}
+
+type azureDevOpsWorkItem struct {
+	ID     int64 `json:"id"`
+	Fields struct {
+		Title         string               `json:"System.Title"`
+		State         string               `json:"System.State"`
+		WorkItemType  string               `json:"System.WorkItemType"`
+		IterationPath string               `json:"System.IterationPath"`
+		Tags          string               `json:"System.Tags"`
+		Description   string               `json:"System.Description"`
+		ReproSteps    string               `json:"Microsoft.VSTS.TCM.ReproSteps"`
+		CreatedBy     *azureDevOpsIdentity `json:"System.CreatedBy"`
+		AssignedTo    *azureDevOpsIdentity `json:"System.AssignedTo"`
+		CreatedDate   time.Time            `json:"System.CreatedDate"`
+		ChangedDate   time.Time            `json:"System.ChangedDate"`
+		ClosedDate    *time.Time           `json:"Microsoft.VSTS.Common.ClosedDate"`
+	} `json:"fields"`
+	Relations []struct {
+		Rel        string `json:"rel"`
+		URL        string `json:"url"`
+		Attributes struct {
+			Name                string    `json:"name"`
+			ResourceSize        int       `json:"resourceSize"`
+			ResourceCreatedDate time.Time `json:"resourceCreatedDate"`
+		} `json:"attributes"`
+	} `json:"relations"`
+}
+
+// isClosedState returns whether the state of the work item type is in the Completed or Removed category,
+// the states are customizable by the process of the project
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/work-item-type-states/list
+func (d *AzureDevOpsDownloader) isClosedState(workItemType, state string) (bool, error) {
+	categories, ok := d.stateCategories[workItemType]
+	if !ok {
+		var states azureDevOpsList[struct {
+			Name     string `json:"name"`
+			Category string `json:"category"`
+		}]
+		if err := d.callAPI("GET", d.projectPath()+"/_apis/wit/workitemtypes/"+url.PathEscape(workItemType)+"/states", nil, nil, &states); err != nil {
+			return false, err
+		}
+		categories = make(map[string]string, len(states.Value))
+		for _, s := range states.Value {
+			categories[s.Name] = s.Category
+		}
+		d.stateCategories[workItemType] = categories
+	}
+	category := categories[state]
+	return category == "Completed" || category == "Removed", nil
+}
+
+// GetIssues returns the work items of the project ordered by ID, the pages have to be requested in order
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/wiql/query-by-wiql
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/work-items/list
+func (d *AzureDevOpsDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	// the work items could be requested 200 at most at once
+	perPage = min(perPage, 200)
+
+	var result struct {
+		WorkItems []struct {
+			ID int64 `json:"id"`
+		} `json:"workItems"`
+	}
+	err := d.callAPI("POST", d.projectPath()+"/_apis/wit/wiql", url.Values{
+		"$top": {strconv.Itoa(perPage)},
+	}, map[string]string{
+		"query": fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [System.Id] > %d ORDER BY [System.Id]", d.lastWorkItemID),
+	}, &result)
+	if err != nil {
+		return nil, false, err
+	}
+	isEnd := len(result.WorkItems) < perPage
+	if len(result.WorkItems) == 0 {
+		return nil, isEnd, nil
+	}
+
+	ids := make([]string, 0, len(result.WorkItems))
+	for _, workItem := range result.WorkItems {
+		ids = append(ids, strconv.FormatInt(workItem.ID, 10))
+	}
+	var workItems azureDevOpsList[*azureDevOpsWorkItem]
+	if err := d.callAPI("GET", d.projectPath()+"/_apis/wit/workitems", url.Values{
+		"ids":     {strings.Join(ids, ",")},
+		"$expand": {"relations"},
+	}, nil, &workItems); err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(workItems.Value))
+	for _, workItem := range workItems.Value {
+		fields := &workItem.Fields
+		closed, err := d.isClosedState(fields.WorkItemType, fields.State)
+		if err != nil {
+			return nil, false, err
+		}
+		state := "open"
+		var closedTime *time.Time
+		if closed {
+			state = "closed"
+			closedTime = fields.ClosedDate
+			if closedTime == nil {
+				closedTime = &fields.ChangedDate
+			}
+		}
+
+		content := fields.Description
+		if content == "" {
+			// the bugs have the repro steps instead of the description
+			content = fields.ReproSteps
+		}
+
+		var labels []*base.Label
+		for _, tag := range strings.Split(fields.Tags, ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				labels = append(labels, &base.Label{Name: tag})
+			}
+		}
+
+		var assignees []string
+		if fields.AssignedTo != nil {
+			assignees = append(assignees, fields.AssignedTo.Name())
+		}
+
+		var assets []*base.ReleaseAsset
+		for _, relation := range workItem.Relations {
+			if relation.Rel != "AttachedFile" {
+				continue
+			}
+			assets = append(assets, d.convertAttachment(relation.URL, relation.Attributes.Name, relation.Attributes.ResourceSize, relation.Attributes.ResourceCreatedDate))
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:       workItem.ID,
+			PosterName:   fields.CreatedBy.Name(),
+			PosterEmail:  fields.CreatedBy.Email(),
+			Title:        fields.Title,
+			Content:      content,
+			Milestone:    azureDevOpsIterationTitle(fields.IterationPath),
+			State:        state,
+			Created:      fields.CreatedDate,
+			Updated:      fields.ChangedDate,
+			Closed:       closedTime,
+			Labels:       labels,
+			Assignees:    assignees,
+			Assets:       assets,
+			ForeignIndex: workItem.ID,
+			Context:      azureDevOpsIssueContext{},
+		})
+		d.maxIssueIndex = max(d.maxIssueIndex, workItem.ID)
+	}
+	d.lastWorkItemID = result.WorkItems[len(result.WorkItems)-1].ID
+
+	return issues, isEnd, nil
+}
+
+// convertAttachment converts the attachment of a work item, the attachment is downloaded from the collection
+// of the downloader by its ID instead of the URL in the response
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/attachments/get
+func (d *AzureDevOpsDownloader) convertAttachment(attachmentURL, name string, size int, created time.Time) *base.ReleaseAsset {
+	id := attachmentURL
+	if u, err := url.Parse(attachmentURL); err == nil {
+		id = path.Base(u.Path)
+	}
+	return &base.ReleaseAsset{
+		Name:    name,
+		Size:    &size,
+		Created: created,
+		DownloadFunc: func() (io.ReadCloser, error) {
+			req, err := d.newRequest("GET", d.baseURL+"/_apis/wit/attachments/"+url.PathEscape(id)+"?"+url.Values{
+				"fileName":    {name},
+				"download":    {"true"},
+				"api-version": {azureDevOpsAPIVersion},
+			}.Encode(), nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := d.client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			if resp.StatusCode != http.StatusOK {
+				resp.Body.Close()
+				return nil, fmt.Errorf("unexpected status %s of the attachment %s", resp.Status, name)
+			}
+			return resp.Body, nil
+		},
+	}
+}
+
+// GetComments returns the comments of the work item or the general comments of the pull request
+func (d *AzureDevOpsDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	if issueContext, ok := commentable.GetContext().(azureDevOpsIssueContext); ok && issueContext.IsPullRequest {
+		return d.getPullRequestComments(commentable)
+	}
+	return d.getWorkItemComments(commentable)
+}
+
+// getWorkItemComments returns the comments of the work item
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/comments/get-comments
+func (d *AzureDevOpsDownloader) getWorkItemComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	var comments []*base.Comment
+	parameter := url.Values{
+		"api-version": {azureDevOpsAPIVersion + "-preview.3"},
+		"order":       {"asc"},
+		"$top":        {"200"},
+	}
+	for {
+		var result struct {
+			Comments []struct {
+				ID           int64                `json:"id"`
+				Text         string               `json:"text"`
+				CreatedBy    *azureDevOpsIdentity `json:"createdBy"`
+				CreatedDate  time.Time            `json:"createdDate"`
+				ModifiedDate time.Time            `json:"modifiedDate"`
+				IsDeleted    bool                 `json:"isDeleted"`
+			} `json:"comments"`
+			ContinuationToken string `json:"continuationToken"`
+		}
+		if err := d.callAPI("GET", fmt.Sprintf("%s/_apis/wit/workItems/%d/comments", d.projectPath(), commentable.GetForeignIndex()), parameter, nil, &result); err != nil {
+			return nil, false, err
+		}
+		for _, comment := range result.Comments {
+			if comment.IsDeleted {
+				continue
+			}
+			comments = append(comments, &base.Comment{
+				IssueIndex:  commentable.GetLocalIndex(),
+				Index:       comment.ID,
+				PosterName:  comment.CreatedBy.Name(),
+				PosterEmail: comment.CreatedBy.Email(),
+				Content:     comment.Text,
+				Created:     comment.CreatedDate,
+				Updated:     comment.ModifiedDate,
+			})
+		}
+		if result.ContinuationToken == "" {
+			return comments, true, nil
+		}
+		parameter.Set("continuationToken", result.ContinuationToken)
+	}
+}
+
+type azureDevOpsGitRepository struct {
+	Name    string `json:"name"`
+	Project struct {
+		Name string `json:"name"`
+	} `json:"project"`
+}
+
+type azureDevOpsCommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+// GetPullRequests returns pull requests, the numbers of the pull requests follow the work items
+// since they are numbered separately
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-requests/get-pull-requests
+func (d *AzureDevOpsDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	var rawPullRequests azureDevOpsList[struct {
+		PullRequestID         int64                    `json:"pullRequestId"`
+		Status                string                   `json:"status"`
+		CreatedBy             *azureDevOpsIdentity     `json:"createdBy"`
+		CreationDate          time.Time                `json:"creationDate"`
+		ClosedDate            *time.Time               `json:"closedDate"`
+		Title                 string                   `json:"title"`
+		Description           string                   `json:"description"`
+		SourceRefName         string                   `json:"sourceRefName"`
+		TargetRefName         string                   `json:"targetRefName"`
+		IsDraft               bool                     `json:"isDraft"`
+		LastMergeSourceCommit *azureDevOpsCommitRef    `json:"lastMergeSourceCommit"`
+		LastMergeTargetCommit *azureDevOpsCommitRef    `json:"lastMergeTargetCommit"`
+		LastMergeCommit       *azureDevOpsCommitRef    `json:"lastMergeCommit"`
+		Repository            azureDevOpsGitRepository `json:"repository"`
+		ForkSource            *struct {
+			Repository azureDevOpsGitRepository `json:"repository"`
+		} `json:"forkSource"`
+		Labels []struct {
+			Name   string `json:"name"`
+			Active bool   `json:"active"`
+		} `json:"labels"`
+	}]
+	err := d.callAPI("GET", d.repoPath()+"/pullrequests", url.Values{
+		"searchCriteria.status": {"all"},
+		"$skip":                 {strconv.Itoa((page - 1) * perPage)},
+		"$top":                  {strconv.Itoa(perPage)},
+	}, nil, &rawPullRequests)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests.Value))
+	for _, pr := range rawPullRequests.Value {
+		state := "open"
+		var closed, mergedTime *time.Time
+		var mergeCommitSHA string
+		updated := pr.CreationDate
+		merged := pr.Status == "completed"
+		if pr.Status != "active" {
+			state = "closed"
+			if pr.ClosedDate != nil {
+				closed = pr.ClosedDate
+				updated = *pr.ClosedDate
+			}
+			if merged {
+				mergedTime = closed
+				if pr.LastMergeCommit != nil {
+					mergeCommitSHA = pr.LastMergeCommit.CommitID
+				}
+			}
+		}
+
+		var labels []*base.Label
+		for _, label := range pr.Labels {
+			if label.Active {
+				labels = append(labels, &base.Label{Name: label.Name})
+			}
+		}
+
+		head := d.convertBranch(&pr.Repository, pr.SourceRefName, pr.LastMergeSourceCommit)
+		if pr.ForkSource != nil {
+			head = d.convertBranch(&pr.ForkSource.Repository, pr.SourceRefName, pr.LastMergeSourceCommit)
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         d.maxIssueIndex + pr.PullRequestID,
+			Title:          pr.Title,
+			Content:        pr.Description,
+			PosterName:     pr.CreatedBy.Name(),
+			PosterEmail:    pr.CreatedBy.Email(),
+			State:          state,
+			Created:        pr.CreationDate,
+			Updated:        updated,
+			Closed:         closed,
+			Labels:         labels,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           head,
+			Base:           d.convertBranch(&pr.Repository, pr.TargetRefName, pr.LastMergeTargetCommit),
+			IsDraft:        pr.IsDraft,
+			ForeignIndex:   pr.PullRequestID,
+			Context:        azureDevOpsIssueContext{IsPullRequest: true},
+		})
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.baseURL, d)
+	}
+	return pullRequests, len(rawPullRequests.Value) < perPage, nil
+}
+
+// convertBranch converts the branch of the pull request, the clone URL is built from the collection
+// of the downloader so the forks are cloned from the same server
+func (d *AzureDevOpsDownloader) convertBranch(repo *azureDevOpsGitRepository, refName string, commit *azureDevOpsCommitRef) base.PullRequestBranch {
+	branch := base.PullRequestBranch{
+		CloneURL:  fmt.Sprintf("%s/%s/_git/%s", d.baseURL, url.PathEscape(repo.Project.Name), url.PathEscape(repo.Name)),
+		Ref:       strings.TrimPrefix(refName, "refs/heads/"),
+		RepoName:  repo.Name,
+		OwnerName: repo.Project.Name,
+	}
+	if commit != nil {
+		branch.SHA = commit.CommitID
+	}
+	return branch
+}
+
+type azureDevOpsThreadComment struct {
+	ID              int64                `json:"id"`
+	ParentCommentID int64                `json:"parentCommentId"`
+	Author          *azureDevOpsIdentity `json:"author"`
+	Content         string               `json:"content"`
+	PublishedDate   time.Time            `json:"publishedDate"`
+	LastUpdatedDate time.Time            `json:"lastUpdatedDate"`
+	CommentType     string               `json:"commentType"`
+	IsDeleted       bool                 `json:"isDeleted"`
+}
+
+type azureDevOpsFilePosition struct {
+	Line int `json:"line"`
+}
+
+type azureDevOpsThread struct {
+	ID            int64                       `json:"id"`
+	IsDeleted     bool                        `json:"isDeleted"`
+	Comments      []*azureDevOpsThreadComment `json:"comments"`
+	ThreadContext *struct {
+		FilePath       string                   `json:"filePath"`
+		LeftFileStart  *azureDevOpsFilePosition `json:"leftFileStart"`
+		LeftFileEnd    *azureDevOpsFilePosition `json:"leftFileEnd"`
+		RightFileStart *azureDevOpsFilePosition `json:"rightFileStart"`
+		RightFileEnd   *azureDevOpsFilePosition `json:"rightFileEnd"`
+	} `json:"threadContext"`
+	PullRequestThreadContext *struct {
+		IterationContext *struct {
+			SecondComparingIteration int `json:"secondComparingIteration"`
+		} `json:"iterationContext"`
+	} `json:"pullRequestThreadContext"`
+	Properties map[string]struct {
+		Value any `json:"$value"`
+	} `json:"properties"`
+	Identities map[string]*azureDevOpsIdentity `json:"identities"`
+}
+
+func (t *azureDevOpsThread) property(name string) string {
+	p, ok := t.Properties[name]
+	if !ok || p.Value == nil {
+		return ""
+	}
+	return fmt.Sprint(p.Value)
+}
+
+// getThreads returns the threads of the pull request
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-threads/list
+func (d *AzureDevOpsDownloader) getThreads(pullRequestID int64) ([]*azureDevOpsThread, error) {
+	var threads azureDevOpsList[*azureDevOpsThread]
+	if err := d.callAPI("GET", fmt.Sprintf("%s/pullRequests/%d/threads", d.repoPath(), pullRequestID), nil, nil, &threads); err != nil {
+		return nil, err
+	}
+	return threads.Value, nil
+}
+
+// getPullRequestComments returns the comments of the threads which aren't on the files
+func (d *AzureDevOpsDownloader) getPullRequestComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	threads, err := d.getThreads(commentable.GetForeignIndex())
+	if err != nil {
+		return nil, false, err
+	}
+
+	var comments []*base.Comment
+	for _, thread := range threads {
+		if thread.IsDeleted || thread.ThreadContext != nil {
+			continue
+		}
+		for _, comment := range thread.Comments {
+			if comment.IsDeleted || comment.CommentType != "text" {
+				continue
+			}
+			comments = append(comments, &base.Comment{
+				IssueIndex:  commentable.GetLocalIndex(),
+				PosterName:  comment.Author.Name(),
+				PosterEmail: comment.Author.Email(),
+				Content:     comment.Content,
+				Created:     comment.PublishedDate,
+				Updated:     comment.LastUpdatedDate,
+			})
+		}
+	}
+	return comments, true, nil
+}
+
+// getIterationCommits returns the source commits of the iterations of the pull request
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-request-iterations/list
+func (d *AzureDevOpsDownloader) getIterationCommits(pullRequestID int64) (map[int]string, error) {
+	var iterations azureDevOpsList[struct {
+		ID              int                   `json:"id"`
+		SourceRefCommit *azureDevOpsCommitRef `json:"sourceRefCommit"`
+	}]
+	if err := d.callAPI("GET", fmt.Sprintf("%s/pullRequests/%d/iterations", d.repoPath(), pullRequestID), nil, nil, &iterations); err != nil {
+		return nil, err
+	}
+	commits := make(map[int]string, len(iterations.Value))
+	for _, iteration := range iterations.Value {
+		if iteration.SourceRefCommit != nil {
+			commits[iteration.ID] = iteration.SourceRefCommit.CommitID
+		}
+	}
+	return commits, nil
+}
+
+// GetReviews returns the votes and the comments on the files of the pull request,
+// the votes are recorded by the system threads of the pull request
+func (d *AzureDevOpsDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	threads, err := d.getThreads(reviewable.GetForeignIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*base.Review
+	var iterationCommits map[int]string
+	for _, thread := range threads {
+		if thread.IsDeleted || len(thread.Comments) == 0 {
+			continue
+		}
+
+		if thread.property("CodeReviewThreadType") == "VoteUpdate" {
+			// 10 approved, 5 approved with suggestions, 0 reset, -5 waiting for author, -10 rejected
+			vote, _ := strconv.Atoi(thread.property("CodeReviewVoteResult"))
+			if vote == 0 {
+				continue
+			}
+			state := base.ReviewStateApproved
+			if vote < 0 {
+				state = base.ReviewStateChangesRequested
+			}
+			voter := thread.Comments[0].Author
+			if identity, ok := thread.Identities[thread.property("CodeReviewVotedByIdentity")]; ok {
+				voter = identity
+			}
+			reviews = append(reviews, &base.Review{
+				ID:           thread.ID,
+				IssueIndex:   reviewable.GetLocalIndex(),
+				ReviewerName: voter.Name(),
+				CreatedAt:    thread.Comments[0].PublishedDate,
+				State:        state,
+			})
+			continue
+		}
+
+		threadContext := thread.ThreadContext
+		if threadContext == nil || threadContext.FilePath == "" {
+			continue
+		}
+		// the lines of the new file are positive and the lines of the old file are negative
+		var line int
+		switch {
+		case threadContext.RightFileEnd != nil:
+			line = threadContext.RightFileEnd.Line
+		case threadContext.RightFileStart != nil:
+			line = threadContext.RightFileStart.Line
+		case threadContext.LeftFileEnd != nil:
+			line = -threadContext.LeftFileEnd.Line
+		case threadContext.LeftFileStart != nil:
+			line = -threadContext.LeftFileStart.Line
+		}
+		if line == 0 {
+			continue
+		}
+
+		var commitID string
+		if prContext := thread.PullRequestThreadContext; prContext != nil && prContext.IterationContext != nil {
+			if iterationCommits == nil {
+				if iterationCommits, err = d.getIterationCommits(reviewable.GetForeignIndex()); err != nil {
+					return nil, err
+				}
+			}
+			commitID = iterationCommits[prContext.IterationContext.SecondComparingIteration]
+		}
+
+		for _, comment := range thread.Comments {
+			if comment.IsDeleted || comment.CommentType != "text" {
+				continue
+			}
+			reviews = append(reviews, &base.Review{
+				ID:           comment.ID,
+				IssueIndex:   reviewable.GetLocalIndex(),
+				ReviewerName: comment.Author.Name(),
+				CommitID:     commitID,
+				CreatedAt:    comment.PublishedDate,
+				State:        base.ReviewStateCommented,
+				Comments: []*base.ReviewComment{{
+					ID:        comment.ID,
+					InReplyTo: comment.ParentCommentID,
+					Content:   comment.Content,
+					TreePath:  strings.TrimPrefix(threadContext.FilePath, "/"),
+					Line:      line,
+					CommitID:  commitID,
+					CreatedAt: comment.PublishedDate,
+					UpdatedAt: comment.LastUpdatedDate,
+				}},
+			})
+		}
+	}
+	return reviews, nil
+}
@@ -0,0 +1,353 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAzureDevOpsURL(t *testing.T) {
+	cases := []struct {
+		url         string
+		baseURL     string
+		projectName string
+		repoName    string
+	}{
+		{"https://org@dev.azure.com/org/test-project/_git/test-repo", "https://dev.azure.com/org", "test-project", "test-repo"},
+		{"https://dev.azure.com/org/test-project/_git/test-repo.git", "https://dev.azure.com/org", "test-project", "test-repo"},
+		{"https://dev.azure.com/org/_git/test-project", "https://dev.azure.com/org", "test-project", "test-project"},
+		{"https://dev.azure.com/org/My%20Project/_git/test-repo", "https://dev.azure.com/org", "My Project", "test-repo"},
+		{"https://org.visualstudio.com/test-project/_git/test-repo", "https://org.visualstudio.com", "test-project", "test-repo"},
+		{"https://org.visualstudio.com/_git/test-project", "https://org.visualstudio.com", "test-project", "test-project"},
+		{"https://org.visualstudio.com/DefaultCollection/test-project/_git/test-repo", "https://org.visualstudio.com/DefaultCollection", "test-project", "test-repo"},
+		{"https://tfs.example.com:8080/tfs/DefaultCollection/test-project/_git/test-repo", "https://tfs.example.com:8080/tfs/DefaultCollection", "test-project", "test-repo"},
+	}
+	for _, c := range cases {
+		u, err := url.Parse(c.url)
+		require.NoError(t, err)
+		baseURL, projectName, repoName, err := parseAzureDevOpsURL(u)
+		require.NoError(t, err, c.url)
+		assert.Equal(t, c.baseURL, baseURL, c.url)
+		assert.Equal(t, c.projectName, projectName, c.url)
+		assert.Equal(t, c.repoName, repoName, c.url)
+	}
+
+	for _, invalid := range []string{"https://dev.azure.com/org/test-project", "https://example.com/_git/test-repo"} {
+		u, _ := url.Parse(invalid)
+		_, _, _, err := parseAzureDevOpsURL(u)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAzureDevOpsDownloadRepo(t *testing.T) {
+	server := newFixtureServer(t, "testdata/azuredevops/full_download")
+	downloader := NewAzureDevOpsDownloader(context.Background(), server.URL+"/org", "test-project", "test-repo", "personal-access-token")
+
+	repo, err := downloader.GetRepoInfo()
+	require.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test-repo",
+		Owner:         "test-project",
+		IsPrivate:     true,
+		Description:   "Test project for testing migration from Azure DevOps to Gitea",
+		CloneURL:      "https://dev.azure.com/org/test-project/_git/test-repo",
+		OriginalURL:   "https://dev.azure.com/org/test-project/_git/test-repo",
+		DefaultBranch: "main",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	require.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{
+			Title:    "Sprint 1",
+			Created:  time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			Deadline: timePtr(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)),
+			Closed:   timePtr(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)),
+			State:    "closed",
+		},
+		{
+			Title: "Release 2",
+			State: "open",
+		},
+		{
+			Title:    "Release 2/Sprint 2",
+			Created:  time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC),
+			Deadline: timePtr(time.Date(2099, 1, 16, 0, 0, 0, 0, time.UTC)),
+			State:    "open",
+		},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	require.NoError(t, err)
+	assertLabelsEqual(t, []*base.Label{
+		{Name: "frontend", Color: "ffffff"},
+		{Name: "ux", Color: "ffffff"},
+		{Name: "backend", Color: "ffffff"},
+	}, labels)
+
+	issues, isEnd, err := downloader.GetIssues(1, 10)
+	require.NoError(t, err)
+	assert.True(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:      1,
+			Title:       "Login page",
+			Content:     "<div>Add a login page with <b>SSO</b>.</div>",
+			Milestone:   "Sprint 1",
+			PosterName:  "Alice Smith",
+			PosterEmail: "alice@example.com",
+			State:       "open",
+			Created:     time.Date(2024, 3, 4, 9, 15, 30, 120000000, time.UTC),
+			Updated:     time.Date(2024, 3, 5, 10, 0, 0, 500000000, time.UTC),
+			Labels: []*base.Label{
+				{Name: "frontend"},
+				{Name: "ux"},
+			},
+			Assignees:    []string{"Bob Jones"},
+			ForeignIndex: 1,
+		},
+		{
+			Number:       2,
+			Title:        "Crash on logout",
+			Content:      "<div>Click on <i>Sign out</i>.</div>",
+			PosterName:   "Bob Jones",
+			PosterEmail:  "bob@example.com",
+			State:        "closed",
+			Created:      time.Date(2024, 3, 6, 14, 0, 0, 0, time.UTC),
+			Updated:      time.Date(2024, 3, 8, 16, 30, 0, 0, time.UTC),
+			Closed:       timePtr(time.Date(2024, 3, 8, 16, 29, 45, 250000000, time.UTC)),
+			ForeignIndex: 2,
+		},
+		{
+			Number:       5,
+			Title:        "Remove the legacy API",
+			Milestone:    "Release 2/Sprint 2",
+			PosterName:   "Alice Smith",
+			PosterEmail:  "alice@example.com",
+			State:        "closed",
+			Created:      time.Date(2024, 3, 10, 8, 0, 0, 0, time.UTC),
+			Updated:      time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC),
+			Closed:       timePtr(time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC)),
+			Labels:       []*base.Label{{Name: "backend"}},
+			ForeignIndex: 5,
+		},
+	}, issues)
+
+	require.Len(t, issues[0].Assets, 1)
+	asset := issues[0].Assets[0]
+	assert.Equal(t, "mockup.png", asset.Name)
+	assert.Equal(t, 18, *asset.Size)
+	assert.Equal(t, time.Date(2024, 3, 5, 9, 59, 12, 300000000, time.UTC), asset.Created)
+	rc, err := asset.DownloadFunc()
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "mockup of the page", string(content))
+	assert.Empty(t, issues[1].Assets)
+
+	comments, _, err := downloader.GetComments(issues[0])
+	require.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterName:  "Bob Jones",
+			PosterEmail: "bob@example.com",
+			Created:     time.Date(2024, 3, 5, 9, 0, 0, 113000000, time.UTC),
+			Updated:     time.Date(2024, 3, 5, 9, 1, 30, 0, time.UTC),
+			Content:     "<div>I'll take it.</div>",
+		},
+		{
+			IssueIndex:  1,
+			PosterName:  "Alice Smith",
+			PosterEmail: "alice@example.com",
+			Created:     time.Date(2024, 3, 5, 11, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 3, 5, 11, 0, 0, 0, time.UTC),
+			Content:     "<div>Thanks!</div>",
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 10)
+	require.NoError(t, err)
+	assert.True(t, isEnd)
+	mainBranch := base.PullRequestBranch{
+		CloneURL:  server.URL + "/org/test-project/_git/test-repo",
+		Ref:       "main",
+		SHA:       "c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e4f50",
+		RepoName:  "test-repo",
+		OwnerName: "test-project",
+	}
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:      14,
+			Title:       "Draft: SSO settings",
+			Content:     "Work in progress",
+			PosterName:  "Carol White",
+			PosterEmail: "carol@example.com",
+			State:       "open",
+			Created:     time.Date(2024, 3, 12, 8, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 3, 12, 8, 0, 0, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/org/test-project/_git/test-repo",
+				Ref:       "sso",
+				SHA:       "9f8e7d6c5b4a39281706f5e4d3c2b1a098765432",
+				RepoName:  "test-repo",
+				OwnerName: "test-project",
+			},
+			Base:         mainBranch,
+			IsDraft:      true,
+			ForeignIndex: 9,
+		},
+		{
+			Number:      13,
+			Title:       "Update the README",
+			PosterName:  "Bob Jones",
+			PosterEmail: "bob@example.com",
+			State:       "closed",
+			Created:     time.Date(2024, 3, 8, 8, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC),
+			Closed:      timePtr(time.Date(2024, 3, 9, 10, 0, 0, 0, time.UTC)),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/org/bob-project/_git/test-repo-fork",
+				Ref:       "readme",
+				SHA:       "b7c8d9e0f1a2b3c4d5e6f708192a3b4c5d6e7f80",
+				RepoName:  "test-repo-fork",
+				OwnerName: "bob-project",
+			},
+			Base:         mainBranch,
+			ForeignIndex: 8,
+		},
+		{
+			Number:      12,
+			Title:       "Add the login page",
+			Content:     "Implements AB#1",
+			PosterName:  "Alice Smith",
+			PosterEmail: "alice@example.com",
+			State:       "closed",
+			Created:     time.Date(2024, 3, 6, 8, 0, 0, 400000000, time.UTC),
+			Updated:     time.Date(2024, 3, 7, 12, 0, 0, 800000000, time.UTC),
+			Closed:      timePtr(time.Date(2024, 3, 7, 12, 0, 0, 800000000, time.UTC)),
+			Labels:      []*base.Label{{Name: "frontend"}},
+			Merged:      true,
+			MergedTime:  timePtr(time.Date(2024, 3, 7, 12, 0, 0, 800000000, time.UTC)),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/org/test-project/_git/test-repo",
+				Ref:       "feature/login",
+				SHA:       "a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e",
+				RepoName:  "test-repo",
+				OwnerName: "test-project",
+			},
+			Base:           mainBranch,
+			MergeCommitSHA: "e5f60718293a4b5c6d7e8f9a0b1c2d3e4f5a6b7c",
+			ForeignIndex:   7,
+		},
+	}, prs)
+	assert.True(t, prs[1].IsForkPullRequest())
+	for _, pr := range prs {
+		assert.True(t, pr.EnsuredSafe)
+	}
+
+	comments, _, err = downloader.GetComments(prs[2])
+	require.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  12,
+			PosterName:  "Bob Jones",
+			PosterEmail: "bob@example.com",
+			Created:     time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC),
+			Content:     "Nice work!",
+		},
+		{
+			IssueIndex:  12,
+			PosterName:  "Alice Smith",
+			PosterEmail: "alice@example.com",
+			Created:     time.Date(2024, 3, 6, 9, 10, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 3, 6, 9, 12, 0, 0, time.UTC),
+			Content:     "Thanks!",
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[2])
+	require.NoError(t, err)
+	firstIteration := "1111111111111111111111111111111111111111"
+	secondIteration := "a1b2c3d4e5f60718293a4b5c6d7e8f9a0b1c2d3e"
+	assertReviewsEqual(t, []*base.Review{
+		{
+			ID:           3,
+			IssueIndex:   12,
+			ReviewerName: "Carol White",
+			CreatedAt:    time.Date(2024, 3, 6, 10, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateChangesRequested,
+		},
+		{
+			ID:           1,
+			IssueIndex:   12,
+			ReviewerName: "Carol White",
+			CommitID:     firstIteration,
+			CreatedAt:    time.Date(2024, 3, 6, 10, 5, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        1,
+				Content:   "Validate the input here.",
+				TreePath:  "src/login.ts",
+				Line:      12,
+				CommitID:  firstIteration,
+				CreatedAt: time.Date(2024, 3, 6, 10, 5, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 6, 10, 5, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           2,
+			IssueIndex:   12,
+			ReviewerName: "Alice Smith",
+			CommitID:     firstIteration,
+			CreatedAt:    time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        2,
+				InReplyTo: 1,
+				Content:   "Done.",
+				TreePath:  "src/login.ts",
+				Line:      12,
+				CommitID:  firstIteration,
+				CreatedAt: time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 6, 11, 2, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           1,
+			IssueIndex:   12,
+			ReviewerName: "Carol White",
+			CommitID:     secondIteration,
+			CreatedAt:    time.Date(2024, 3, 6, 11, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        1,
+				Content:   "Why was this deleted?",
+				TreePath:  "src/legacy.ts",
+				Line:      -4,
+				CommitID:  secondIteration,
+				CreatedAt: time.Date(2024, 3, 6, 11, 30, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 6, 11, 30, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           7,
+			IssueIndex:   12,
+			ReviewerName: "Bob Jones",
+			CreatedAt:    time.Date(2024, 3, 7, 11, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+	}, reviews)
+}
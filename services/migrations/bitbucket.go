@@ -0,0 +1,721 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketDownloader{}
+	_ base.DownloaderFactory = &BitbucketDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketDownloaderFactory{})
+}
+
+// BitbucketDownloaderFactory defines a Bitbucket Cloud downloader factory
+type BitbucketDownloaderFactory struct{}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *BitbucketDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid path: %s", u.Path)
+	}
+	workspace := fields[0]
+	repoName := strings.TrimSuffix(fields[1], ".git")
+
+	baseURL := u.Scheme + "://" + u.Host
+	apiURL := baseURL + "/!api/2.0"
+	if strings.EqualFold(u.Host, "bitbucket.org") {
+		apiURL = "https://api.bitbucket.org/2.0"
+	}
+
+	log.Trace("Create Bitbucket downloader. BaseURL: %s Workspace: %s RepoName: %s", baseURL, workspace, repoName)
+
+	return NewBitbucketDownloader(ctx, baseURL, apiURL, workspace, repoName, opts.AuthUsername, opts.AuthPassword), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketService
+}
+
+type bitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+	AccountID   string `json:"account_id"`
+}
+
+// Name returns the name of the user, the users without nickname like the deleted users have only a display name
+func (u *bitbucketUser) Name() string {
+	if u == nil {
+		return ""
+	}
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.DisplayName
+}
+
+type bitbucketContent struct {
+	Raw string `json:"raw"`
+}
+
+type bitbucketIssueContext struct {
+	IsPullRequest bool
+}
+
+// BitbucketDownloader implements a Downloader interface to get repository information
+// from Bitbucket Cloud via its API 2.0
+type BitbucketDownloader struct {
+	base.NullDownloader
+	ctx           context.Context
+	client        *http.Client
+	baseURL       string
+	apiURL        string
+	workspace     string
+	repoName      string
+	username      string
+	password      string
+	hasIssues     bool
+	mainBranch    string
+	maxIssueIndex int64
+	commitMap     map[string]string
+}
+
+// NewBitbucketDownloader creates a Bitbucket Cloud downloader, the password could be an app password of the user,
+// or an access token of the repository or workspace if the username is empty.
+func NewBitbucketDownloader(ctx context.Context, baseURL, apiURL, workspace, repoName, username, password string) *BitbucketDownloader {
+	return &BitbucketDownloader{
+		ctx:       ctx,
+		client:    NewMigrationHTTPClient(),
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		apiURL:    strings.TrimSuffix(apiURL, "/"),
+		workspace: workspace,
+		repoName:  repoName,
+		username:  username,
+		password:  password,
+		hasIssues: true,
+		commitMap: make(map[string]string),
+	}
+}
+
+// SetContext set context
+func (d *BitbucketDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *BitbucketDownloader) String() string {
+	return fmt.Sprintf("migration from bitbucket %s %s/%s", d.baseURL, d.workspace, d.repoName)
+}
+
+func (d *BitbucketDownloader) LogString() string {
+	if d == nil {
+		return "<BitbucketDownloader nil>"
+	}
+	return fmt.Sprintf("<BitbucketDownloader %s %s/%s>", d.baseURL, d.workspace, d.repoName)
+}
+
+// FormatCloneURL add authentication into remote URLs
+func (d *BitbucketDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return opts.CloneAddr, nil
+}
+
+func (d *BitbucketDownloader) repoPath() string {
+	return fmt.Sprintf("/repositories/%s/%s", url.PathEscape(d.workspace), url.PathEscape(d.repoName))
+}
+
+func (d *BitbucketDownloader) newRequest(rawURL string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(d.ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if d.username != "" {
+		req.SetBasicAuth(d.username, d.password)
+	} else if d.password != "" {
+		req.Header.Set("Authorization", "Bearer "+d.password)
+	}
+	return req, nil
+}
+
+func (d *BitbucketDownloader) callAPI(endpoint string, parameter url.Values, result any) error {
+	u := d.apiURL + endpoint
+	if len(parameter) > 0 {
+		u += "?" + parameter.Encode()
+	}
+	req, err := d.newRequest(u)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s of %s", resp.Status, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// callBitbucketPagedAPI requests a page of the paginated endpoint, the page starts from 1
+func callBitbucketPagedAPI[T any](d *BitbucketDownloader, endpoint string, parameter url.Values, page, perPage int) ([]T, bool, error) {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("page", strconv.Itoa(page))
+	parameter.Set("pagelen", strconv.Itoa(min(perPage, 50)))
+
+	var result bitbucketPage[T]
+	if err := d.callAPI(endpoint, parameter, &result); err != nil {
+		return nil, false, err
+	}
+	return result.Values, result.Next == "", nil
+}
+
+// callBitbucketAllPagesAPI requests all pages of the paginated endpoint
+func callBitbucketAllPagesAPI[T any](d *BitbucketDownloader, endpoint string, parameter url.Values) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		values, isEnd, err := callBitbucketPagedAPI[T](d, endpoint, parameter, page, 50)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, values...)
+		if isEnd {
+			return all, nil
+		}
+	}
+}
+
+// GetRepoInfo returns repository information
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-workspace-repo-slug-get
+func (d *BitbucketDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		Name        string `json:"name"`
+		FullName    string `json:"full_name"`
+		Description string `json:"description"`
+		IsPrivate   bool   `json:"is_private"`
+		HasIssues   bool   `json:"has_issues"`
+		MainBranch  struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+		} `json:"links"`
+	}
+	if err := d.callAPI(d.repoPath(), nil, &rawRepo); err != nil {
+		return nil, err
+	}
+	d.hasIssues = rawRepo.HasIssues
+	d.mainBranch = rawRepo.MainBranch.Name
+
+	var cloneURL string
+	for _, link := range rawRepo.Links.Clone {
+		if link.Name == "https" {
+			// the clone link contains the name of the authenticated user
+			if u, err := url.Parse(link.Href); err == nil {
+				u.User = nil
+				cloneURL = u.String()
+			}
+		}
+	}
+
+	owner, _, _ := strings.Cut(rawRepo.FullName, "/")
+	return &base.Repository{
+		Name:          rawRepo.Name,
+		Owner:         owner,
+		IsPrivate:     rawRepo.IsPrivate,
+		Description:   rawRepo.Description,
+		CloneURL:      cloneURL,
+		OriginalURL:   rawRepo.Links.HTML.Href,
+		DefaultBranch: rawRepo.MainBranch.Name,
+	}, nil
+}
+
+// GetMilestones returns milestones of the issue tracker
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-issue-tracker/#api-repositories-workspace-repo-slug-milestones-get
+func (d *BitbucketDownloader) GetMilestones() ([]*base.Milestone, error) {
+	if !d.hasIssues {
+		return nil, nil
+	}
+	rawMilestones, err := callBitbucketAllPagesAPI[struct {
+		Name string `json:"name"`
+	}](d, d.repoPath()+"/milestones", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := make([]*base.Milestone, 0, len(rawMilestones))
+	for _, m := range rawMilestones {
+		milestones = append(milestones, &base.Milestone{
+			Title: m.Name,
+			State: "open",
+		})
+	}
+	return milestones, nil
+}
+
+// bitbucketIssueKinds are the fixed kinds of the issues which are migrated as labels
+var bitbucketIssueKinds = map[string]*base.Label{
+	"bug":         {Name: "Kind/Bug", Color: "ee0701", Exclusive: true},
+	"enhancement": {Name: "Kind/Enhancement", Color: "84b6eb", Exclusive: true},
+	"proposal":    {Name: "Kind/Proposal", Color: "c5def5", Exclusive: true},
+	"task":        {Name: "Kind/Task", Color: "fbca04", Exclusive: true},
+}
+
+// GetLabels returns the kinds and the components of the issue tracker as labels
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-issue-tracker/#api-repositories-workspace-repo-slug-components-get
+func (d *BitbucketDownloader) GetLabels() ([]*base.Label, error) {
+	if !d.hasIssues {
+		return nil, nil
+	}
+	rawComponents, err := callBitbucketAllPagesAPI[struct {
+		Name string `json:"name"`
+	}](d, d.repoPath()+"/components", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]*base.Label, 0, len(bitbucketIssueKinds)+len(rawComponents))
+	for _, kind := range []string{"bug", "enhancement", "proposal", "task"} {
+		labels = append(labels, bitbucketIssueKinds[kind])
+	}
+	for _, c := range rawComponents {
+		labels = append(labels, &base.Label{
+			Name:  c.Name,
+			Color: "ffffff",
+		})
+	}
+	return labels, nil
+}
+
+// GetIssues returns issues
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-issue-tracker/#api-repositories-workspace-repo-slug-issues-get
+func (d *BitbucketDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if !d.hasIssues {
+		return nil, true, nil
+	}
+	rawIssues, isEnd, err := callBitbucketPagedAPI[struct {
+		ID        int64            `json:"id"`
+		Title     string           `json:"title"`
+		Content   bitbucketContent `json:"content"`
+		State     string           `json:"state"`
+		Kind      string           `json:"kind"`
+		Reporter  *bitbucketUser   `json:"reporter"`
+		Assignee  *bitbucketUser   `json:"assignee"`
+		CreatedOn time.Time        `json:"created_on"`
+		UpdatedOn time.Time        `json:"updated_on"`
+		Milestone *struct {
+			Name string `json:"name"`
+		} `json:"milestone"`
+		Component *struct {
+			Name string `json:"name"`
+		} `json:"component"`
+	}](d, d.repoPath()+"/issues", url.Values{"sort": {"id"}}, page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawIssues))
+	for _, issue := range rawIssues {
+		state := "open"
+		var closed *time.Time
+		switch issue.State {
+		case "resolved", "invalid", "duplicate", "wontfix", "closed":
+			state = "closed"
+			closed = &issue.UpdatedOn
+		}
+
+		var labels []*base.Label
+		if kind, ok := bitbucketIssueKinds[issue.Kind]; ok {
+			labels = append(labels, kind)
+		}
+		if issue.Component != nil {
+			labels = append(labels, &base.Label{Name: issue.Component.Name})
+		}
+		var milestone string
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Name
+		}
+		var assignees []string
+		if issue.Assignee != nil {
+			assignees = append(assignees, issue.Assignee.Name())
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:       issue.ID,
+			Title:        issue.Title,
+			Content:      issue.Content.Raw,
+			PosterName:   issue.Reporter.Name(),
+			Milestone:    milestone,
+			State:        state,
+			Created:      issue.CreatedOn,
+			Updated:      issue.UpdatedOn,
+			Closed:       closed,
+			Labels:       labels,
+			Assignees:    assignees,
+			ForeignIndex: issue.ID,
+			Context:      bitbucketIssueContext{},
+		})
+
+		if d.maxIssueIndex < issue.ID {
+			d.maxIssueIndex = issue.ID
+		}
+	}
+	return issues, isEnd, nil
+}
+
+type bitbucketComment struct {
+	ID        int64            `json:"id"`
+	Content   bitbucketContent `json:"content"`
+	User      *bitbucketUser   `json:"user"`
+	CreatedOn time.Time        `json:"created_on"`
+	UpdatedOn *time.Time       `json:"updated_on"`
+	Deleted   bool             `json:"deleted"`
+	Parent    *struct {
+		ID int64 `json:"id"`
+	} `json:"parent"`
+	Inline *struct {
+		Path string `json:"path"`
+		From *int   `json:"from"`
+		To   *int   `json:"to"`
+	} `json:"inline"`
+}
+
+func (c *bitbucketComment) updated() time.Time {
+	if c.UpdatedOn != nil {
+		return *c.UpdatedOn
+	}
+	return c.CreatedOn
+}
+
+// GetComments returns comments of an issue or a pull request, the inline comments of the pull requests are migrated as reviews
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-issue-tracker/#api-repositories-workspace-repo-slug-issues-issue-id-comments-get
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-pull-request-id-comments-get
+func (d *BitbucketDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	context, ok := commentable.GetContext().(bitbucketIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected context: %+v", commentable.GetContext())
+	}
+
+	endpoint := fmt.Sprintf("%s/issues/%d/comments", d.repoPath(), commentable.GetForeignIndex())
+	if context.IsPullRequest {
+		endpoint = fmt.Sprintf("%s/pullrequests/%d/comments", d.repoPath(), commentable.GetForeignIndex())
+	}
+	rawComments, err := callBitbucketAllPagesAPI[*bitbucketComment](d, endpoint, url.Values{"sort": {"created_on"}})
+	if err != nil {
+		return nil, false, err
+	}
+
+	comments := make([]*base.Comment, 0, len(rawComments))
+	for _, comment := range rawComments {
+		// the changes of the issues are recorded as comments without content
+		if comment.Deleted || comment.Inline != nil || comment.Content.Raw == "" {
+			continue
+		}
+		comments = append(comments, &base.Comment{
+			IssueIndex: commentable.GetLocalIndex(),
+			Index:      comment.ID,
+			PosterName: comment.User.Name(),
+			Content:    comment.Content.Raw,
+			Created:    comment.CreatedOn,
+			Updated:    comment.updated(),
+		})
+	}
+	return comments, true, nil
+}
+
+type bitbucketPullRequestBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit *struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// GetPullRequests returns pull requests, they are numbered after the issues because Bitbucket numbers them separately
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-get
+func (d *BitbucketDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	rawPullRequests, isEnd, err := callBitbucketPagedAPI[struct {
+		ID          int64                      `json:"id"`
+		Title       string                     `json:"title"`
+		Summary     bitbucketContent           `json:"summary"`
+		State       string                     `json:"state"`
+		Draft       bool                       `json:"draft"`
+		Author      *bitbucketUser             `json:"author"`
+		CreatedOn   time.Time                  `json:"created_on"`
+		UpdatedOn   time.Time                  `json:"updated_on"`
+		Source      bitbucketPullRequestBranch `json:"source"`
+		Destination bitbucketPullRequestBranch `json:"destination"`
+		MergeCommit *struct {
+			Hash string `json:"hash"`
+		} `json:"merge_commit"`
+	}](d, d.repoPath()+"/pullrequests", url.Values{
+		"state": {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"},
+		"sort":  {"id"},
+	}, page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		state := "open"
+		var closed, mergedTime *time.Time
+		var mergeCommitSHA string
+		merged := pr.State == "MERGED"
+		if pr.State != "OPEN" {
+			state = "closed"
+			closed = &pr.UpdatedOn
+		}
+		if merged {
+			mergedTime = &pr.UpdatedOn
+			if pr.MergeCommit != nil {
+				mergeCommitSHA = d.getFullCommitSHA(d.workspace+"/"+d.repoName, pr.MergeCommit.Hash)
+			}
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         d.maxIssueIndex + pr.ID,
+			Title:          pr.Title,
+			Content:        pr.Summary.Raw,
+			PosterName:     pr.Author.Name(),
+			State:          state,
+			Created:        pr.CreatedOn,
+			Updated:        pr.UpdatedOn,
+			Closed:         closed,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           d.convertPullRequestBranch(&pr.Source),
+			Base:           d.convertPullRequestBranch(&pr.Destination),
+			IsDraft:        pr.Draft,
+			ForeignIndex:   pr.ID,
+			Context:        bitbucketIssueContext{IsPullRequest: true},
+		})
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.baseURL, d)
+	}
+	return pullRequests, isEnd, nil
+}
+
+func (d *BitbucketDownloader) convertPullRequestBranch(branch *bitbucketPullRequestBranch) base.PullRequestBranch {
+	// the repository of the source branch is missing if the fork has been deleted
+	fullName := d.workspace + "/" + d.repoName
+	if branch.Repository != nil {
+		fullName = branch.Repository.FullName
+	}
+	owner, repoName, _ := strings.Cut(fullName, "/")
+
+	ret := base.PullRequestBranch{
+		Ref:       branch.Branch.Name,
+		RepoName:  repoName,
+		OwnerName: owner,
+		CloneURL:  fmt.Sprintf("%s/%s.git", d.baseURL, fullName),
+	}
+	if branch.Commit != nil {
+		ret.SHA = d.getFullCommitSHA(fullName, branch.Commit.Hash)
+	}
+	return ret
+}
+
+// getFullCommitSHA returns the full SHA of the abbreviated one in the pull requests
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-commits/#api-repositories-workspace-repo-slug-commit-commit-get
+func (d *BitbucketDownloader) getFullCommitSHA(repoFullName, hash string) string {
+	if hash == "" {
+		return ""
+	}
+	sha, ok := d.commitMap[hash]
+	if !ok {
+		var rawCommit struct {
+			Hash string `json:"hash"`
+		}
+		owner, repoName, _ := strings.Cut(repoFullName, "/")
+		endpoint := fmt.Sprintf("/repositories/%s/%s/commit/%s", url.PathEscape(owner), url.PathEscape(repoName), url.PathEscape(hash))
+		if err := d.callAPI(endpoint, nil, &rawCommit); err != nil {
+			log.Warn("Unable to get the commit %s of %s in %s: %v", hash, repoFullName, d, err)
+		}
+		sha = rawCommit.Hash
+		d.commitMap[hash] = sha
+	}
+	return sha
+}
+
+// GetReviews returns the approvals of the participants and the inline comments of the pull request
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-pull-request-id-get
+func (d *BitbucketDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	var rawPullRequest struct {
+		UpdatedOn    time.Time `json:"updated_on"`
+		Participants []struct {
+			User           *bitbucketUser `json:"user"`
+			State          string         `json:"state"`
+			ParticipatedOn *time.Time     `json:"participated_on"`
+		} `json:"participants"`
+	}
+	if err := d.callAPI(fmt.Sprintf("%s/pullrequests/%d", d.repoPath(), reviewable.GetForeignIndex()), nil, &rawPullRequest); err != nil {
+		return nil, err
+	}
+
+	var reviews []*base.Review
+	for _, participant := range rawPullRequest.Participants {
+		var state string
+		switch participant.State {
+		case "approved":
+			state = base.ReviewStateApproved
+		case "changes_requested":
+			state = base.ReviewStateChangesRequested
+		default:
+			continue
+		}
+		createdAt := rawPullRequest.UpdatedOn
+		if participant.ParticipatedOn != nil {
+			createdAt = *participant.ParticipatedOn
+		}
+		reviews = append(reviews, &base.Review{
+			IssueIndex:   reviewable.GetLocalIndex(),
+			ReviewerName: participant.User.Name(),
+			CreatedAt:    createdAt,
+			State:        state,
+		})
+	}
+
+	rawComments, err := callBitbucketAllPagesAPI[*bitbucketComment](d, fmt.Sprintf("%s/pullrequests/%d/comments", d.repoPath(), reviewable.GetForeignIndex()), url.Values{"sort": {"created_on"}})
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range rawComments {
+		if comment.Deleted || comment.Inline == nil {
+			continue
+		}
+		// the lines of the new file are positive and the removed lines of the old file are negative
+		var line int
+		if comment.Inline.To != nil {
+			line = *comment.Inline.To
+		} else if comment.Inline.From != nil {
+			line = -*comment.Inline.From
+		}
+		var inReplyTo int64
+		if comment.Parent != nil {
+			inReplyTo = comment.Parent.ID
+		}
+		reviews = append(reviews, &base.Review{
+			ID:           comment.ID,
+			IssueIndex:   reviewable.GetLocalIndex(),
+			ReviewerName: comment.User.Name(),
+			CreatedAt:    comment.CreatedOn,
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        comment.ID,
+				InReplyTo: inReplyTo,
+				Content:   comment.Content.Raw,
+				TreePath:  comment.Inline.Path,
+				Line:      line,
+				CreatedAt: comment.CreatedOn,
+				UpdatedAt: comment.updated(),
+			}},
+		})
+	}
+	return reviews, nil
+}
+
+// GetReleases returns the downloads of the repository as the assets of a draft release,
+// since Bitbucket has no releases and the downloads don't belong to any tag.
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-downloads/#api-repositories-workspace-repo-slug-downloads-get
+func (d *BitbucketDownloader) GetReleases() ([]*base.Release, error) {
+	rawDownloads, err := callBitbucketAllPagesAPI[struct {
+		Name      string         `json:"name"`
+		Size      int            `json:"size"`
+		Downloads int            `json:"downloads"`
+		CreatedOn time.Time      `json:"created_on"`
+		User      *bitbucketUser `json:"user"`
+	}](d, d.repoPath()+"/downloads", nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(rawDownloads) == 0 {
+		return nil, nil
+	}
+
+	release := &base.Release{
+		TagName:         "downloads",
+		TargetCommitish: d.mainBranch,
+		Name:            "Downloads",
+		Body:            "Files uploaded to the downloads of the repository on Bitbucket.",
+		Draft:           true,
+		PublisherName:   rawDownloads[0].User.Name(),
+		Created:         rawDownloads[0].CreatedOn,
+	}
+	for i, download := range rawDownloads {
+		if download.CreatedOn.Before(release.Created) {
+			release.Created = download.CreatedOn
+		}
+		// SECURITY: the file is always downloaded from the API instead of the link in the response
+		downloadURL := fmt.Sprintf("%s%s/downloads/%s", d.apiURL, d.repoPath(), url.PathEscape(download.Name))
+		release.Assets = append(release.Assets, &base.ReleaseAsset{
+			ID:            int64(i + 1),
+			Name:          download.Name,
+			Size:          &download.Size,
+			DownloadCount: &download.Downloads,
+			Created:       download.CreatedOn,
+			Updated:       download.CreatedOn,
+			DownloadFunc: func() (io.ReadCloser, error) {
+				req, err := d.newRequest(downloadURL)
+				if err != nil {
+					return nil, err
+				}
+				resp, err := d.client.Do(req)
+				if err != nil {
+					return nil, err
+				}
+				if resp.StatusCode != http.StatusOK {
+					resp.Body.Close()
+					return nil, fmt.Errorf("unexpected status %s of download %s", resp.Status, download.Name)
+				}
+				return resp.Body, nil
+			},
+		})
+	}
+	return []*base.Release{release}, nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureServer serves the recorded responses in the directory,
+// the file of a response is named after the path and the sorted query of the request.
+func newFixtureServer(t *testing.T, dir string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ReplaceAll(strings.Trim(r.URL.Path, "/"), "/", "_")
+		if r.URL.RawQuery != "" {
+			name += "_" + r.URL.Query().Encode()
+		}
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("missing fixture %q for %s", name, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBitbucketDownloadRepo(t *testing.T) {
+	server := newFixtureServer(t, "testdata/bitbucket/full_download")
+	downloader := NewBitbucketDownloader(context.Background(), server.URL, server.URL+"/2.0", "gitea-test", "test-repo", "alice", "app-password")
+
+	repo, err := downloader.GetRepoInfo()
+	require.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test-repo",
+		Owner:         "gitea-test",
+		Description:   "Test repository for testing migration from Bitbucket to Gitea",
+		CloneURL:      "https://bitbucket.org/gitea-test/test-repo.git",
+		OriginalURL:   "https://bitbucket.org/gitea-test/test-repo",
+		DefaultBranch: "main",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	require.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{Title: "1.0.0", State: "open"},
+		{Title: "1.1.0", State: "open"},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	require.NoError(t, err)
+	assertLabelsEqual(t, []*base.Label{
+		{Name: "Kind/Bug", Color: "ee0701", Exclusive: true},
+		{Name: "Kind/Enhancement", Color: "84b6eb", Exclusive: true},
+		{Name: "Kind/Proposal", Color: "c5def5", Exclusive: true},
+		{Name: "Kind/Task", Color: "fbca04", Exclusive: true},
+		{Name: "backend", Color: "ffffff"},
+	}, labels)
+
+	issues, isEnd, err := downloader.GetIssues(1, 2)
+	require.NoError(t, err)
+	assert.False(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:     1,
+			Title:      "Crash on startup",
+			Content:    "The server crashes when the config file is missing.",
+			Milestone:  "1.0.0",
+			PosterName: "alice",
+			State:      "closed",
+			Created:    time.Date(2024, 3, 12, 9, 30, 15, 448712000, time.UTC),
+			Updated:    time.Date(2024, 3, 14, 16, 2, 51, 19283000, time.UTC),
+			Closed:     timePtr(time.Date(2024, 3, 14, 16, 2, 51, 19283000, time.UTC)),
+			Labels: []*base.Label{
+				{Name: "Kind/Bug", Color: "ee0701", Exclusive: true},
+				{Name: "backend"},
+			},
+			Assignees:    []string{"bob"},
+			ForeignIndex: 1,
+		},
+		{
+			Number:     2,
+			Title:      "Support dark theme",
+			Content:    "It would be nice to have a dark theme.",
+			PosterName: "bob",
+			State:      "open",
+			Created:    time.Date(2024, 3, 13, 10, 5, 0, 0, time.UTC),
+			Updated:    time.Date(2024, 3, 13, 10, 5, 0, 0, time.UTC),
+			Labels: []*base.Label{
+				{Name: "Kind/Enhancement", Color: "84b6eb", Exclusive: true},
+			},
+			ForeignIndex: 2,
+		},
+	}, issues)
+
+	issues, isEnd, err = downloader.GetIssues(2, 2)
+	require.NoError(t, err)
+	assert.True(t, isEnd)
+	require.Len(t, issues, 1)
+	assert.EqualValues(t, 3, issues[0].Number)
+	assert.Equal(t, "closed", issues[0].State)
+
+	comments, _, err := downloader.GetComments(&base.Issue{Number: 1, ForeignIndex: 1, Context: bitbucketIssueContext{}})
+	require.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex: 1,
+			PosterName: "bob",
+			Created:    time.Date(2024, 3, 12, 10, 1, 2, 0, time.UTC),
+			Updated:    time.Date(2024, 3, 12, 10, 3, 4, 0, time.UTC),
+			Content:    "I can reproduce it with the latest build.",
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 10)
+	require.NoError(t, err)
+	assert.True(t, isEnd)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:     4,
+			Title:      "Fix crash on startup",
+			Content:    "Fixes #1",
+			PosterName: "alice",
+			State:      "closed",
+			Created:    time.Date(2024, 3, 13, 8, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2024, 3, 14, 16, 0, 0, 0, time.UTC),
+			Closed:     timePtr(time.Date(2024, 3, 14, 16, 0, 0, 0, time.UTC)),
+			Merged:     true,
+			MergedTime: timePtr(time.Date(2024, 3, 14, 16, 0, 0, 0, time.UTC)),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/gitea-test/test-repo.git",
+				Ref:       "fix-startup",
+				SHA:       "9a4c8d1e5f3b7a6c2d1e0f9a8b7c6d5e4f3a2b1c",
+				RepoName:  "test-repo",
+				OwnerName: "gitea-test",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  server.URL + "/gitea-test/test-repo.git",
+				Ref:       "main",
+				SHA:       "1b2c3d4e5f607182938a4b5c6d7e8f9012345678",
+				RepoName:  "test-repo",
+				OwnerName: "gitea-test",
+			},
+			MergeCommitSHA: "7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f",
+			ForeignIndex:   1,
+		},
+		{
+			Number:     5,
+			Title:      "Add dark theme",
+			Content:    "Implements a dark theme.",
+			PosterName: "bob",
+			State:      "open",
+			Created:    time.Date(2024, 3, 20, 14, 30, 0, 0, time.UTC),
+			Updated:    time.Date(2024, 3, 21, 9, 0, 0, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/contributor/test-repo.git",
+				Ref:       "dark-theme",
+				SHA:       "5d6e7f8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d4e",
+				RepoName:  "test-repo",
+				OwnerName: "contributor",
+			},
+			Base: base.PullRequestBranch{
+				CloneURL:  server.URL + "/gitea-test/test-repo.git",
+				Ref:       "main",
+				SHA:       "1b2c3d4e5f607182938a4b5c6d7e8f9012345678",
+				RepoName:  "test-repo",
+				OwnerName: "gitea-test",
+			},
+			IsDraft:      true,
+			ForeignIndex: 2,
+		},
+	}, prs)
+	assert.True(t, prs[0].EnsuredSafe)
+	assert.True(t, prs[1].IsForkPullRequest())
+
+	comments, _, err = downloader.GetComments(prs[0])
+	require.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex: 4,
+			PosterName: "bob",
+			Created:    time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC),
+			Content:    "Looks good overall.",
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0])
+	require.NoError(t, err)
+	assertReviewsEqual(t, []*base.Review{
+		{
+			IssueIndex:   4,
+			ReviewerName: "bob",
+			CreatedAt:    time.Date(2024, 3, 14, 15, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+		{
+			ID:           481207,
+			IssueIndex:   4,
+			ReviewerName: "bob",
+			CreatedAt:    time.Date(2024, 3, 13, 9, 5, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        481207,
+				Content:   "Please check for nil here.",
+				TreePath:  "cmd/server.go",
+				Line:      12,
+				CreatedAt: time.Date(2024, 3, 13, 9, 5, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 13, 9, 6, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           481215,
+			IssueIndex:   4,
+			ReviewerName: "alice",
+			CreatedAt:    time.Date(2024, 3, 13, 10, 15, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        481215,
+				InReplyTo: 481207,
+				Content:   "Done.",
+				TreePath:  "cmd/server.go",
+				Line:      12,
+				CreatedAt: time.Date(2024, 3, 13, 10, 15, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 13, 10, 15, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           481220,
+			IssueIndex:   4,
+			ReviewerName: "bob",
+			CreatedAt:    time.Date(2024, 3, 13, 10, 20, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        481220,
+				Content:   "Why was this removed?",
+				TreePath:  "config/config.go",
+				Line:      -30,
+				CreatedAt: time.Date(2024, 3, 13, 10, 20, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 3, 13, 10, 20, 0, 0, time.UTC),
+			}},
+		},
+	}, reviews)
+
+	releases, err := downloader.GetReleases()
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "downloads", releases[0].TagName)
+	assert.Equal(t, "main", releases[0].TargetCommitish)
+	assert.True(t, releases[0].Draft)
+	assert.Equal(t, "alice", releases[0].PublisherName)
+	require.Len(t, releases[0].Assets, 1)
+	asset := releases[0].Assets[0]
+	assert.Equal(t, "test-repo-1.0.0.zip", asset.Name)
+	assert.Equal(t, 22, *asset.Size)
+	assert.Equal(t, 7, *asset.DownloadCount)
+	rc, err := asset.DownloadFunc()
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "content of the archive\n", string(content))
+}
+
+func TestBitbucketDownloaderFactory(t *testing.T) {
+	factory := &BitbucketDownloaderFactory{}
+	downloader, err := factory.New(context.Background(), base.MigrateOptions{
+		CloneAddr: "https://bitbucket.org/gitea-test/test-repo.git",
+	})
+	require.NoError(t, err)
+	d := downloader.(*BitbucketDownloader)
+	assert.Equal(t, "https://bitbucket.org", d.baseURL)
+	assert.Equal(t, "https://api.bitbucket.org/2.0", d.apiURL)
+	assert.Equal(t, "gitea-test", d.workspace)
+	assert.Equal(t, "test-repo", d.repoName)
+
+	_, err = factory.New(context.Background(), base.MigrateOptions{CloneAddr: (&url.URL{Scheme: "https", Host: "bitbucket.org", Path: "/gitea-test"}).String()})
+	assert.Error(t, err)
+}
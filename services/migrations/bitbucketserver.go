@@ -0,0 +1,494 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketServerDownloader{}
+	_ base.DownloaderFactory = &BitbucketServerDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketServerDownloaderFactory{})
+}
+
+// BitbucketServerDownloaderFactory defines a Bitbucket Data Center (formerly Bitbucket Server) downloader factory
+type BitbucketServerDownloaderFactory struct{}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *BitbucketServerDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, projectKey, repoSlug, err := parseBitbucketServerURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("Create Bitbucket Data Center downloader. BaseURL: %s Project: %s RepoSlug: %s", baseURL, projectKey, repoSlug)
+
+	return NewBitbucketServerDownloader(ctx, baseURL, projectKey, repoSlug, opts.AuthUsername, opts.AuthPassword), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketServerDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketServerService
+}
+
+// parseBitbucketServerURL parses the clone URL `<base>/scm/<project>/<repo>.git`
+// or the web URL `<base>/projects/<project>/repos/<repo>/browse` of a repository,
+// the personal repositories of the users belong to the projects named `~<user>`.
+func parseBitbucketServerURL(u *url.URL) (baseURL, projectKey, repoSlug string, err error) {
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i, field := range fields {
+		switch {
+		case field == "scm" && len(fields) == i+3:
+			projectKey, repoSlug = fields[i+1], strings.TrimSuffix(fields[i+2], ".git")
+		case field == "projects" && len(fields) >= i+4 && fields[i+2] == "repos":
+			projectKey, repoSlug = fields[i+1], fields[i+3]
+		case field == "users" && len(fields) >= i+4 && fields[i+2] == "repos":
+			projectKey, repoSlug = "~"+fields[i+1], fields[i+3]
+		default:
+			continue
+		}
+		baseURL = strings.TrimSuffix(fmt.Sprintf("%s://%s/%s", u.Scheme, u.Host, strings.Join(fields[:i], "/")), "/")
+		return baseURL, projectKey, repoSlug, nil
+	}
+	return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+}
+
+type bitbucketServerUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+// bitbucketServerTime is the milliseconds since epoch in the responses
+type bitbucketServerTime int64
+
+func (t bitbucketServerTime) Time() time.Time {
+	return time.UnixMilli(int64(t)).UTC()
+}
+
+type bitbucketServerComment struct {
+	ID          int64                     `json:"id"`
+	Text        string                    `json:"text"`
+	Author      bitbucketServerUser       `json:"author"`
+	CreatedDate bitbucketServerTime       `json:"createdDate"`
+	UpdatedDate bitbucketServerTime       `json:"updatedDate"`
+	Comments    []*bitbucketServerComment `json:"comments"`
+}
+
+type bitbucketServerActivity struct {
+	ID            int64                   `json:"id"`
+	CreatedDate   bitbucketServerTime     `json:"createdDate"`
+	User          bitbucketServerUser     `json:"user"`
+	Action        string                  `json:"action"`
+	CommentAction string                  `json:"commentAction"`
+	Comment       *bitbucketServerComment `json:"comment"`
+	CommentAnchor *struct {
+		Path     string `json:"path"`
+		Line     int    `json:"line"`
+		FileType string `json:"fileType"`
+		ToHash   string `json:"toHash"`
+	} `json:"commentAnchor"`
+}
+
+// BitbucketServerDownloader implements a Downloader interface to get repository information
+// from Bitbucket Data Center via its REST API 1.0.
+// Bitbucket Data Center has no issue tracker, milestones or releases, only the pull requests are migrated.
+type BitbucketServerDownloader struct {
+	base.NullDownloader
+	ctx        context.Context
+	client     *http.Client
+	baseURL    string
+	projectKey string
+	repoSlug   string
+	username   string
+	password   string
+}
+
+// NewBitbucketServerDownloader creates a Bitbucket Data Center downloader, the password could be the password
+// or an HTTP access token of the user, or an HTTP access token of the project or repository if the username is empty.
+func NewBitbucketServerDownloader(ctx context.Context, baseURL, projectKey, repoSlug, username, password string) *BitbucketServerDownloader {
+	return &BitbucketServerDownloader{
+		ctx:        ctx,
+		client:     NewMigrationHTTPClient(),
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		projectKey: projectKey,
+		repoSlug:   repoSlug,
+		username:   username,
+		password:   password,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketServerDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *BitbucketServerDownloader) String() string {
+	return fmt.Sprintf("migration from bitbucket data center %s %s/%s", d.baseURL, d.projectKey, d.repoSlug)
+}
+
+func (d *BitbucketServerDownloader) LogString() string {
+	if d == nil {
+		return "<BitbucketServerDownloader nil>"
+	}
+	return fmt.Sprintf("<BitbucketServerDownloader %s %s/%s>", d.baseURL, d.projectKey, d.repoSlug)
+}
+
+// FormatCloneURL add authentication into remote URLs
+func (d *BitbucketServerDownloader) FormatCloneURL(opts base.MigrateOptions, remoteAddr string) (string, error) {
+	return opts.CloneAddr, nil
+}
+
+func (d *BitbucketServerDownloader) repoPath() string {
+	return fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s", url.PathEscape(d.projectKey), url.PathEscape(d.repoSlug))
+}
+
+func (d *BitbucketServerDownloader) callAPI(endpoint string, parameter url.Values, result any) error {
+	u := d.baseURL + endpoint
+	if len(parameter) > 0 {
+		u += "?" + parameter.Encode()
+	}
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if d.username != "" {
+		req.SetBasicAuth(d.username, d.password)
+	} else if d.password != "" {
+		req.Header.Set("Authorization", "Bearer "+d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s of %s", resp.Status, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type bitbucketServerPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// callBitbucketServerAllPagesAPI requests all pages of the paginated endpoint
+func callBitbucketServerAllPagesAPI[T any](d *BitbucketServerDownloader, endpoint string, parameter url.Values) ([]T, error) {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("limit", "100")
+
+	var all []T
+	for start := 0; ; {
+		parameter.Set("start", strconv.Itoa(start))
+		var result bitbucketServerPage[T]
+		if err := d.callAPI(endpoint, parameter, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Values...)
+		if result.IsLastPage {
+			return all, nil
+		}
+		start = result.NextPageStart
+	}
+}
+
+// GetRepoInfo returns repository information
+// https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-get
+func (d *BitbucketServerDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		Slug        string `json:"slug"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+		Project     struct {
+			Key string `json:"key"`
+		} `json:"project"`
+		Links struct {
+			Clone []struct {
+				Name string `json:"name"`
+				Href string `json:"href"`
+			} `json:"clone"`
+			Self []struct {
+				Href string `json:"href"`
+			} `json:"self"`
+		} `json:"links"`
+	}
+	if err := d.callAPI(d.repoPath(), nil, &rawRepo); err != nil {
+		return nil, err
+	}
+
+	var cloneURL, originalURL string
+	for _, link := range rawRepo.Links.Clone {
+		if link.Name == "http" {
+			// the clone link contains the name of the authenticated user
+			if u, err := url.Parse(link.Href); err == nil {
+				u.User = nil
+				cloneURL = u.String()
+			}
+		}
+	}
+	if len(rawRepo.Links.Self) > 0 {
+		originalURL = rawRepo.Links.Self[0].Href
+	}
+
+	var defaultBranch struct {
+		DisplayID string `json:"displayId"`
+	}
+	if err := d.callAPI(d.repoPath()+"/default-branch", nil, &defaultBranch); err != nil {
+		log.Warn("Unable to get the default branch of %s: %v", d, err)
+	}
+
+	return &base.Repository{
+		Name:          rawRepo.Slug,
+		Owner:         rawRepo.Project.Key,
+		IsPrivate:     !rawRepo.Public,
+		Description:   rawRepo.Description,
+		CloneURL:      cloneURL,
+		OriginalURL:   originalURL,
+		DefaultBranch: defaultBranch.DisplayID,
+	}, nil
+}
+
+type bitbucketServerRef struct {
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Repository   struct {
+		Slug    string `json:"slug"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+	} `json:"repository"`
+}
+
+// GetPullRequests returns pull requests
+// https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-pull-requests/#api-api-latest-projects-projectkey-repos-repositoryslug-pull-requests-get
+func (d *BitbucketServerDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	var rawPullRequests bitbucketServerPage[struct {
+		ID          int64               `json:"id"`
+		Title       string              `json:"title"`
+		Description string              `json:"description"`
+		State       string              `json:"state"`
+		Draft       bool                `json:"draft"`
+		CreatedDate bitbucketServerTime `json:"createdDate"`
+		UpdatedDate bitbucketServerTime `json:"updatedDate"`
+		ClosedDate  bitbucketServerTime `json:"closedDate"`
+		Author      struct {
+			User bitbucketServerUser `json:"user"`
+		} `json:"author"`
+		FromRef    bitbucketServerRef `json:"fromRef"`
+		ToRef      bitbucketServerRef `json:"toRef"`
+		Properties struct {
+			MergeCommit *struct {
+				ID string `json:"id"`
+			} `json:"mergeCommit"`
+		} `json:"properties"`
+	}]
+	err := d.callAPI(d.repoPath()+"/pull-requests", url.Values{
+		"state": {"ALL"},
+		"order": {"OLDEST"},
+		"start": {strconv.Itoa((page - 1) * perPage)},
+		"limit": {strconv.Itoa(perPage)},
+	}, &rawPullRequests)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests.Values))
+	for _, pr := range rawPullRequests.Values {
+		state := "open"
+		var closed, mergedTime *time.Time
+		var mergeCommitSHA string
+		merged := pr.State == "MERGED"
+		if pr.State != "OPEN" {
+			state = "closed"
+			closedTime := pr.UpdatedDate.Time()
+			if pr.ClosedDate != 0 {
+				closedTime = pr.ClosedDate.Time()
+			}
+			closed = &closedTime
+			if merged {
+				mergedTime = &closedTime
+				if pr.Properties.MergeCommit != nil {
+					mergeCommitSHA = pr.Properties.MergeCommit.ID
+				}
+			}
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         pr.ID,
+			Title:          pr.Title,
+			Content:        pr.Description,
+			PosterID:       pr.Author.User.ID,
+			PosterName:     pr.Author.User.Name,
+			PosterEmail:    pr.Author.User.EmailAddress,
+			State:          state,
+			Created:        pr.CreatedDate.Time(),
+			Updated:        pr.UpdatedDate.Time(),
+			Closed:         closed,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           d.convertRef(&pr.FromRef),
+			Base:           d.convertRef(&pr.ToRef),
+			IsDraft:        pr.Draft,
+			ForeignIndex:   pr.ID,
+		})
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.baseURL, d)
+	}
+	return pullRequests, rawPullRequests.IsLastPage, nil
+}
+
+func (d *BitbucketServerDownloader) convertRef(ref *bitbucketServerRef) base.PullRequestBranch {
+	return base.PullRequestBranch{
+		Ref:       ref.DisplayID,
+		SHA:       ref.LatestCommit,
+		RepoName:  ref.Repository.Slug,
+		OwnerName: ref.Repository.Project.Key,
+		CloneURL:  fmt.Sprintf("%s/scm/%s/%s.git", d.baseURL, strings.ToLower(ref.Repository.Project.Key), ref.Repository.Slug),
+	}
+}
+
+// getActivities returns the activities of the pull request in the chronological order
+// https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-pull-requests/#api-api-latest-projects-projectkey-repos-repositoryslug-pull-requests-pullrequestid-activities-get
+func (d *BitbucketServerDownloader) getActivities(pullRequestID int64) ([]*bitbucketServerActivity, error) {
+	activities, err := callBitbucketServerAllPagesAPI[*bitbucketServerActivity](d, fmt.Sprintf("%s/pull-requests/%d/activities", d.repoPath(), pullRequestID), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the newest activities come first
+	for i, j := 0, len(activities)-1; i < j; i, j = i+1, j-1 {
+		activities[i], activities[j] = activities[j], activities[i]
+	}
+	return activities, nil
+}
+
+// flattenBitbucketServerComments returns the comment and its replies, the parent of each reply is in the map
+func flattenBitbucketServerComments(comment *bitbucketServerComment, parents map[int64]int64) []*bitbucketServerComment {
+	ret := []*bitbucketServerComment{comment}
+	for _, reply := range comment.Comments {
+		parents[reply.ID] = comment.ID
+		ret = append(ret, flattenBitbucketServerComments(reply, parents)...)
+	}
+	return ret
+}
+
+// GetComments returns the general comments of the pull request and their replies
+func (d *BitbucketServerDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	activities, err := d.getActivities(commentable.GetForeignIndex())
+	if err != nil {
+		return nil, false, err
+	}
+
+	var comments []*base.Comment
+	for _, activity := range activities {
+		if activity.Action != "COMMENTED" || activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor != nil {
+			continue
+		}
+		for _, comment := range flattenBitbucketServerComments(activity.Comment, map[int64]int64{}) {
+			comments = append(comments, &base.Comment{
+				IssueIndex:  commentable.GetLocalIndex(),
+				Index:       comment.ID,
+				PosterID:    comment.Author.ID,
+				PosterName:  comment.Author.Name,
+				PosterEmail: comment.Author.EmailAddress,
+				Content:     comment.Text,
+				Created:     comment.CreatedDate.Time(),
+				Updated:     comment.UpdatedDate.Time(),
+			})
+		}
+	}
+	return comments, true, nil
+}
+
+// GetReviews returns the approvals and the inline comments of the pull request
+func (d *BitbucketServerDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	activities, err := d.getActivities(reviewable.GetForeignIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*base.Review
+	for _, activity := range activities {
+		switch activity.Action {
+		case "APPROVED", "REVIEWED":
+			// REVIEWED means the reviewer has marked the pull request as "Needs work"
+			state := base.ReviewStateApproved
+			if activity.Action == "REVIEWED" {
+				state = base.ReviewStateChangesRequested
+			}
+			reviews = append(reviews, &base.Review{
+				ID:           activity.ID,
+				IssueIndex:   reviewable.GetLocalIndex(),
+				ReviewerID:   activity.User.ID,
+				ReviewerName: activity.User.Name,
+				CreatedAt:    activity.CreatedDate.Time(),
+				State:        state,
+			})
+		case "COMMENTED":
+			if activity.CommentAction != "ADDED" || activity.Comment == nil || activity.CommentAnchor == nil || activity.CommentAnchor.Line == 0 {
+				continue
+			}
+			anchor := activity.CommentAnchor
+			// the lines of the new file are positive and the lines of the old file are negative
+			line := anchor.Line
+			if anchor.FileType == "FROM" {
+				line = -line
+			}
+			parents := map[int64]int64{}
+			for _, comment := range flattenBitbucketServerComments(activity.Comment, parents) {
+				reviews = append(reviews, &base.Review{
+					ID:           comment.ID,
+					IssueIndex:   reviewable.GetLocalIndex(),
+					ReviewerID:   comment.Author.ID,
+					ReviewerName: comment.Author.Name,
+					CommitID:     anchor.ToHash,
+					CreatedAt:    comment.CreatedDate.Time(),
+					State:        base.ReviewStateCommented,
+					Comments: []*base.ReviewComment{{
+						ID:        comment.ID,
+						InReplyTo: parents[comment.ID],
+						Content:   comment.Text,
+						TreePath:  anchor.Path,
+						Line:      line,
+						CommitID:  anchor.ToHash,
+						PosterID:  comment.Author.ID,
+						CreatedAt: comment.CreatedDate.Time(),
+						UpdatedAt: comment.UpdatedDate.Time(),
+					}},
+				})
+			}
+		}
+	}
+	return reviews, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBitbucketServerURL(t *testing.T) {
+	cases := []struct {
+		url        string
+		baseURL    string
+		projectKey string
+		repoSlug   string
+	}{
+		{"https://bitbucket.example.com/scm/test/test-repo.git", "https://bitbucket.example.com", "test", "test-repo"},
+		{"https://example.com/bitbucket/scm/test/test-repo.git", "https://example.com/bitbucket", "test", "test-repo"},
+		{"https://bitbucket.example.com/projects/TEST/repos/test-repo/browse", "https://bitbucket.example.com", "TEST", "test-repo"},
+		{"https://bitbucket.example.com/users/bob/repos/test-repo/browse", "https://bitbucket.example.com", "~bob", "test-repo"},
+		{"https://bitbucket.example.com/scm/~bob/test-repo.git", "https://bitbucket.example.com", "~bob", "test-repo"},
+	}
+	for _, c := range cases {
+		u, err := url.Parse(c.url)
+		require.NoError(t, err)
+		baseURL, projectKey, repoSlug, err := parseBitbucketServerURL(u)
+		require.NoError(t, err, c.url)
+		assert.Equal(t, c.baseURL, baseURL, c.url)
+		assert.Equal(t, c.projectKey, projectKey, c.url)
+		assert.Equal(t, c.repoSlug, repoSlug, c.url)
+	}
+
+	u, _ := url.Parse("https://bitbucket.example.com/test/test-repo")
+	_, _, _, err := parseBitbucketServerURL(u)
+	assert.Error(t, err)
+}
+
+func TestBitbucketServerDownloadRepo(t *testing.T) {
+	server := newFixtureServer(t, "testdata/bitbucketserver/full_download")
+	downloader := NewBitbucketServerDownloader(context.Background(), server.URL, "TEST", "test-repo", "", "http-access-token")
+
+	repo, err := downloader.GetRepoInfo()
+	require.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test-repo",
+		Owner:         "TEST",
+		IsPrivate:     true,
+		Description:   "Test repository for testing migration from Bitbucket Data Center to Gitea",
+		CloneURL:      "https://bitbucket.example.com/scm/test/test-repo.git",
+		OriginalURL:   "https://bitbucket.example.com/projects/TEST/repos/test-repo/browse",
+		DefaultBranch: "main",
+	}, repo)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 10)
+	require.NoError(t, err)
+	assert.True(t, isEnd)
+	mainBranch := base.PullRequestBranch{
+		CloneURL:  server.URL + "/scm/test/test-repo.git",
+		Ref:       "main",
+		SHA:       "3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d",
+		RepoName:  "test-repo",
+		OwnerName: "TEST",
+	}
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:      1,
+			Title:       "Add the config loader",
+			Content:     "Loads the configuration from the file.",
+			PosterID:    101,
+			PosterName:  "alice",
+			PosterEmail: "alice@example.com",
+			State:       "closed",
+			Created:     time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 4, 1, 14, 30, 0, 0, time.UTC),
+			Closed:      timePtr(time.Date(2024, 4, 1, 14, 30, 0, 0, time.UTC)),
+			Merged:      true,
+			MergedTime:  timePtr(time.Date(2024, 4, 1, 14, 30, 0, 0, time.UTC)),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/scm/test/test-repo.git",
+				Ref:       "config-loader",
+				SHA:       "8f7e6d5c4b3a29180f7e6d5c4b3a29180f7e6d5c",
+				RepoName:  "test-repo",
+				OwnerName: "TEST",
+			},
+			Base:           mainBranch,
+			MergeCommitSHA: "d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3",
+			ForeignIndex:   1,
+		},
+		{
+			Number:      2,
+			Title:       "Fix typo in README",
+			PosterID:    102,
+			PosterName:  "bob",
+			PosterEmail: "bob@example.com",
+			State:       "closed",
+			Created:     time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 4, 2, 11, 0, 0, 0, time.UTC),
+			Closed:      timePtr(time.Date(2024, 4, 2, 11, 0, 0, 0, time.UTC)),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/scm/~bob/test-repo.git",
+				Ref:       "typo",
+				SHA:       "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567",
+				RepoName:  "test-repo",
+				OwnerName: "~BOB",
+			},
+			Base:         mainBranch,
+			ForeignIndex: 2,
+		},
+	}, prs)
+	assert.True(t, prs[0].EnsuredSafe)
+	assert.True(t, prs[1].IsForkPullRequest())
+
+	comments, _, err := downloader.GetComments(prs[0])
+	require.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterID:    102,
+			PosterName:  "bob",
+			PosterEmail: "bob@example.com",
+			Created:     time.Date(2024, 4, 1, 11, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 4, 1, 11, 5, 0, 0, time.UTC),
+			Content:     "Thanks for working on this!",
+		},
+		{
+			IssueIndex:  1,
+			PosterID:    101,
+			PosterName:  "alice",
+			PosterEmail: "alice@example.com",
+			Created:     time.Date(2024, 4, 1, 11, 15, 0, 0, time.UTC),
+			Updated:     time.Date(2024, 4, 1, 11, 15, 0, 0, time.UTC),
+			Content:     "You're welcome.",
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0])
+	require.NoError(t, err)
+	headCommit := "8f7e6d5c4b3a29180f7e6d5c4b3a29180f7e6d5c"
+	assertReviewsEqual(t, []*base.Review{
+		{
+			ID:           10,
+			IssueIndex:   1,
+			ReviewerID:   102,
+			ReviewerName: "bob",
+			CommitID:     headCommit,
+			CreatedAt:    time.Date(2024, 4, 1, 10, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        10,
+				Content:   "Old line removed on purpose?",
+				TreePath:  "main.go",
+				Line:      -21,
+				CommitID:  headCommit,
+				PosterID:  102,
+				CreatedAt: time.Date(2024, 4, 1, 10, 30, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 4, 1, 10, 30, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           13,
+			IssueIndex:   1,
+			ReviewerID:   102,
+			ReviewerName: "bob",
+			CommitID:     headCommit,
+			CreatedAt:    time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        13,
+				Content:   "Use a constant here.",
+				TreePath:  "config/loader.go",
+				Line:      8,
+				CommitID:  headCommit,
+				PosterID:  102,
+				CreatedAt: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           14,
+			IssueIndex:   1,
+			ReviewerID:   101,
+			ReviewerName: "alice",
+			CommitID:     headCommit,
+			CreatedAt:    time.Date(2024, 4, 1, 12, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{{
+				ID:        14,
+				InReplyTo: 13,
+				Content:   "Fixed.",
+				TreePath:  "config/loader.go",
+				Line:      8,
+				CommitID:  headCommit,
+				PosterID:  101,
+				CreatedAt: time.Date(2024, 4, 1, 12, 30, 0, 0, time.UTC),
+				UpdatedAt: time.Date(2024, 4, 1, 12, 30, 0, 0, time.UTC),
+			}},
+		},
+		{
+			ID:           105,
+			IssueIndex:   1,
+			ReviewerID:   103,
+			ReviewerName: "carol",
+			CreatedAt:    time.Date(2024, 4, 1, 13, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateChangesRequested,
+		},
+		{
+			ID:           106,
+			IssueIndex:   1,
+			ReviewerID:   102,
+			ReviewerName: "bob",
+			CreatedAt:    time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+	}, reviews)
+}
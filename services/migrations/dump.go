@@ -290,46 +290,9 @@ func (g *RepositoryDumper) CreateReleases(releases ...*base.Release) error {
 	if g.opts.ReleaseAssets {
 		for _, release := range releases {
 			attachDir := filepath.Join("release_assets", release.TagName)
-			if err := os.MkdirAll(filepath.Join(g.baseDir, attachDir), os.ModePerm); err != nil {
+			if err := g.downloadAssets(attachDir, release.Assets); err != nil {
 				return err
 			}
-			for _, asset := range release.Assets {
-				attachLocalPath := filepath.Join(attachDir, asset.Name)
-
-				// SECURITY: We cannot check the DownloadURL and DownloadFunc are safe here
-				// ... we must assume that they are safe and simply download the attachment
-				// download attachment
-				err := func(attachPath string) error {
-					var rc io.ReadCloser
-					var err error
-					if asset.DownloadURL == nil {
-						rc, err = asset.DownloadFunc()
-						if err != nil {
-							return err
-						}
-					} else {
-						resp, err := http.Get(*asset.DownloadURL)
-						if err != nil {
-							return err
-						}
-						rc = resp.Body
-					}
-					defer rc.Close()
-
-					fw, err := os.Create(attachPath)
-					if err != nil {
-						return fmt.Errorf("create: %w", err)
-					}
-					defer fw.Close()
-
-					_, err = io.Copy(fw, rc)
-					return err
-				}(filepath.Join(g.baseDir, attachLocalPath))
-				if err != nil {
-					return err
-				}
-				asset.DownloadURL = &attachLocalPath // to save the filepath on the yml file, change the source
-			}
 		}
 	}
 
@@ -353,6 +316,54 @@ func (g *RepositoryDumper) CreateReleases(releases ...*base.Release) error {
 	return nil
 }
 
+// downloadAssets downloads the assets into the directory and points their DownloadURL to the local files
+func (g *RepositoryDumper) downloadAssets(attachDir string, assets []*base.ReleaseAsset) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(g.baseDir, attachDir), os.ModePerm); err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		attachLocalPath := filepath.Join(attachDir, asset.Name)
+
+		// SECURITY: We cannot check the DownloadURL and DownloadFunc are safe here
+		// ... we must assume that they are safe and simply download the attachment
+		// download attachment
+		err := func(attachPath string) error {
+			var rc io.ReadCloser
+			var err error
+			if asset.DownloadURL == nil {
+				rc, err = asset.DownloadFunc()
+				if err != nil {
+					return err
+				}
+			} else {
+				resp, err := http.Get(*asset.DownloadURL)
+				if err != nil {
+					return err
+				}
+				rc = resp.Body
+			}
+			defer rc.Close()
+
+			fw, err := os.Create(attachPath)
+			if err != nil {
+				return fmt.Errorf("create: %w", err)
+			}
+			defer fw.Close()
+
+			_, err = io.Copy(fw, rc)
+			return err
+		}(filepath.Join(g.baseDir, attachLocalPath))
+		if err != nil {
+			return err
+		}
+		asset.DownloadURL = &attachLocalPath // to save the filepath on the yml file, change the source
+	}
+	return nil
+}
+
 // SyncTags syncs releases with tags in the database
 func (g *RepositoryDumper) SyncTags() error {
 	return nil
@@ -360,6 +371,12 @@ func (g *RepositoryDumper) SyncTags() error {
 
 // CreateIssues creates issues
 func (g *RepositoryDumper) CreateIssues(issues ...*base.Issue) error {
+	for _, issue := range issues {
+		if err := g.downloadAssets(filepath.Join("issue_assets", strconv.FormatInt(issue.Number, 10)), issue.Assets); err != nil {
+			return err
+		}
+	}
+
 	var err error
 	if g.issueFile == nil {
 		g.issueFile, err = os.Create(filepath.Join(g.baseDir, "issue.yml"))
@@ -313,50 +313,11 @@ func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
 		}
 
 		for _, asset := range release.Assets {
-			if asset.Created.IsZero() {
-				if !asset.Updated.IsZero() {
-					asset.Created = asset.Updated
-				} else {
-					asset.Created = release.Created
-				}
-			}
-			attach := repo_model.Attachment{
-				UUID:          uuid.New().String(),
-				Name:          asset.Name,
-				DownloadCount: int64(*asset.DownloadCount),
-				Size:          int64(*asset.Size),
-				CreatedUnix:   timeutil.TimeStamp(asset.Created.Unix()),
-			}
-
-			// SECURITY: We cannot check the DownloadURL and DownloadFunc are safe here
-			// ... we must assume that they are safe and simply download the attachment
-			err := func() error {
-				// asset.DownloadURL maybe a local file
-				var rc io.ReadCloser
-				var err error
-				if asset.DownloadFunc != nil {
-					rc, err = asset.DownloadFunc()
-					if err != nil {
-						return err
-					}
-				} else if asset.DownloadURL != nil {
-					rc, err = uri.Open(*asset.DownloadURL)
-					if err != nil {
-						return err
-					}
-				}
-				if rc == nil {
-					return nil
-				}
-				_, err = storage.Attachments.Save(attach.RelativePath(), rc, int64(*asset.Size))
-				rc.Close()
-				return err
-			}()
+			attach, err := g.createAttachment(asset, release.Created)
 			if err != nil {
 				return err
 			}
-
-			rel.Attachments = append(rel.Attachments, &attach)
+			rel.Attachments = append(rel.Attachments, attach)
 		}
 
 		rels = append(rels, &rel)
@@ -365,6 +326,58 @@ func (g *GiteaLocalUploader) CreateReleases(releases ...*base.Release) error {
 	return repo_model.InsertReleases(g.ctx, rels...)
 }
 
+// createAttachment downloads the asset into the attachment storage
+func (g *GiteaLocalUploader) createAttachment(asset *base.ReleaseAsset, created time.Time) (*repo_model.Attachment, error) {
+	if asset.Created.IsZero() {
+		if !asset.Updated.IsZero() {
+			asset.Created = asset.Updated
+		} else {
+			asset.Created = created
+		}
+	}
+	attach := repo_model.Attachment{
+		UUID:        uuid.New().String(),
+		RepoID:      g.repo.ID,
+		Name:        asset.Name,
+		Size:        -1,
+		CreatedUnix: timeutil.TimeStamp(asset.Created.Unix()),
+	}
+	if asset.DownloadCount != nil {
+		attach.DownloadCount = int64(*asset.DownloadCount)
+	}
+	if asset.Size != nil {
+		attach.Size = int64(*asset.Size)
+	}
+
+	// SECURITY: We cannot check the DownloadURL and DownloadFunc are safe here
+	// ... we must assume that they are safe and simply download the attachment
+	// asset.DownloadURL maybe a local file
+	var rc io.ReadCloser
+	var err error
+	if asset.DownloadFunc != nil {
+		rc, err = asset.DownloadFunc()
+		if err != nil {
+			return nil, err
+		}
+	} else if asset.DownloadURL != nil {
+		rc, err = uri.Open(*asset.DownloadURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rc == nil {
+		attach.Size = max(attach.Size, 0)
+		return &attach, nil
+	}
+	defer rc.Close()
+	written, err := storage.Attachments.Save(attach.RelativePath(), rc, attach.Size)
+	if err != nil {
+		return nil, err
+	}
+	attach.Size = written
+	return &attach, nil
+}
+
 // SyncTags syncs releases with tags in the database
 func (g *GiteaLocalUploader) SyncTags() error {
 	return repo_module.SyncReleasesWithTags(g.ctx, g.repo, g.gitRepo)
@@ -427,6 +440,14 @@ func (g *GiteaLocalUploader) CreateIssues(issues ...*base.Issue) error {
 		if issue.Closed != nil {
 			is.ClosedUnix = timeutil.TimeStamp(issue.Closed.Unix())
 		}
+		for _, asset := range issue.Assets {
+			attach, err := g.createAttachment(asset, issue.Created)
+			if err != nil {
+				return err
+			}
+			attach.UploaderID = is.PosterID
+			is.Attachments = append(is.Attachments, attach)
+		}
 		// add reactions
 		for _, reaction := range issue.Reactions {
 			res := issues_model.Reaction{
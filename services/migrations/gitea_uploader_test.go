@@ -7,9 +7,11 @@ package migrations
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	base "code.gitea.io/gitea/modules/migration"
 	"code.gitea.io/gitea/modules/optional"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 	repo_service "code.gitea.io/gitea/services/repository"
@@ -228,6 +231,47 @@ func TestGiteaUploadRemapExternalUser(t *testing.T) {
 	assert.EqualValues(t, linkedUser.ID, target.GetUserID())
 }
 
+func TestGiteaUploadIssueAttachments(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+	uploader := NewGiteaLocalUploader(context.Background(), doer, doer.Name, repo.Name)
+	uploader.gitServiceType = structs.AzureDevOpsService
+	uploader.repo = repo
+
+	size := 7
+	assert.NoError(t, uploader.CreateIssues(&base.Issue{
+		Number:     100,
+		Title:      "issue with an attachment",
+		PosterName: "external",
+		State:      "open",
+		Created:    time.Unix(1710000000, 0),
+		Assets: []*base.ReleaseAsset{{
+			Name: "notes.txt",
+			Size: &size,
+			DownloadFunc: func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader("content")), nil
+			},
+		}},
+	}))
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID, Index: 100})
+	attachment := unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{IssueID: issue.ID})
+	assert.Equal(t, "notes.txt", attachment.Name)
+	assert.EqualValues(t, 7, attachment.Size)
+	assert.Equal(t, repo.ID, attachment.RepoID)
+	assert.Equal(t, doer.ID, attachment.UploaderID)
+	assert.EqualValues(t, 1710000000, attachment.CreatedUnix)
+
+	rc, err := storage.Attachments.Open(attachment.RelativePath())
+	assert.NoError(t, err)
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
 func TestGiteaUploadUpdateGitForPullRequest(t *testing.T) {
 	unittest.PrepareTestEnv(t)
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	_ "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/unittest"
 	base "code.gitea.io/gitea/modules/migration"
 
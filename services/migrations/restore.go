@@ -192,6 +192,13 @@ func (r *RepositoryRestorer) GetIssues(page, perPage int) ([]*base.Issue, bool,
 		}
 		return nil, false, err
 	}
+	for _, issue := range issues {
+		for _, asset := range issue.Assets {
+			if asset.DownloadURL != nil {
+				*asset.DownloadURL = "file://" + filepath.Join(r.baseDir, *asset.DownloadURL)
+			}
+		}
+	}
 	return issues, true, nil
 }
 
@@ -6,6 +6,7 @@ package notify
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	packages_model "code.gitea.io/gitea/models/packages"
@@ -110,6 +111,13 @@ func PullRequestSynchronized(ctx context.Context, doer *user_model.User, pr *iss
 	}
 }
 
+// MergeGroupChecksRequested notifies that a merge group of the merge queue has been created for a pull request
+func MergeGroupChecksRequested(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, groupRef git.RefName, groupCommitID, baseCommitID string) {
+	for _, notifier := range notifiers {
+		notifier.MergeGroupChecksRequested(ctx, doer, pr, groupRef, groupCommitID, baseCommitID)
+	}
+}
+
 // PullRequestReview notifies new pull request review
 func PullRequestReview(ctx context.Context, pr *issues_model.PullRequest, review *issues_model.Review, comment *issues_model.Comment, mentions []*user_model.User) {
 	if err := review.LoadReviewer(ctx); err != nil {
@@ -374,3 +382,17 @@ func CreateCommitStatus(ctx context.Context, repo *repo_model.Repository, commit
 		notifier.CreateCommitStatus(ctx, repo, commit, sender, status)
 	}
 }
+
+// WorkflowRunStatusUpdate notifies that the status of a workflow run has changed
+func WorkflowRunStatusUpdate(ctx context.Context, repo *repo_model.Repository, sender *user_model.User, run *actions_model.ActionRun) {
+	for _, notifier := range notifiers {
+		notifier.WorkflowRunStatusUpdate(ctx, repo, sender, run)
+	}
+}
+
+// WorkflowJobStatusUpdate notifies that the status of a workflow job has changed
+func WorkflowJobStatusUpdate(ctx context.Context, repo *repo_model.Repository, sender *user_model.User, job *actions_model.ActionRunJob, task *actions_model.ActionTask) {
+	for _, notifier := range notifiers {
+		notifier.WorkflowJobStatusUpdate(ctx, repo, sender, job, task)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package oauth2_provider //nolint
+
+import (
+	"net/url"
+
+	auth "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// DeviceAuthorizationResponse represents a successful device authorization response
+// https://datatracker.ietf.org/doc/html/rfc8628#section-3.2
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceVerificationURI returns the URI of the page the users enter the user codes of their devices on
+func DeviceVerificationURI() string {
+	return setting.AppURL + "login/oauth/device"
+}
+
+// NewDeviceAuthorizationResponse returns the response to a newly created device authorization
+func NewDeviceAuthorizationResponse(d *auth.OAuth2DeviceAuthorization) *DeviceAuthorizationResponse {
+	verificationURI := DeviceVerificationURI()
+	return &DeviceAuthorizationResponse{
+		DeviceCode:              d.DeviceCode,
+		UserCode:                d.FormattedUserCode(),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(d.FormattedUserCode()),
+		ExpiresIn:               int64(d.ValidUntil - timeutil.TimeStampNow()),
+		Interval:                d.Interval,
+	}
+}
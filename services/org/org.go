@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	org_model "code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/storage"
@@ -52,6 +53,10 @@ func DeleteOrganization(ctx context.Context, org *org_model.Organization, purge
 		return fmt.Errorf("DeleteOrganization: %w", err)
 	}
 
+	if err := quota_model.RemoveUserFromAllGroups(ctx, org.ID); err != nil {
+		return fmt.Errorf("RemoveUserFromAllGroups: %w", err)
+	}
+
 	if err := committer.Commit(); err != nil {
 		return err
 	}
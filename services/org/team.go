@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -18,6 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 
 	"xorm.io/builder"
@@ -25,7 +27,15 @@ import (
 
 // NewTeam creates a record of new team.
 // It's caller's responsibility to assign organization ID.
-func NewTeam(ctx context.Context, t *organization.Team) (err error) {
+func NewTeam(ctx context.Context, t *organization.Team) error {
+	if err := newTeam(ctx, t); err != nil {
+		return err
+	}
+	audit.Record(ctx, audit_model.ActionTeamCreate, nil, audit.OwnerScope(t.OrgID), audit.TeamTarget(t), nil, teamState(ctx, t))
+	return nil
+}
+
+func newTeam(ctx context.Context, t *organization.Team) (err error) {
 	if len(t.Name) == 0 {
 		return util.NewInvalidArgumentErrorf("empty team name")
 	}
@@ -90,7 +100,19 @@ func NewTeam(ctx context.Context, t *organization.Team) (err error) {
 }
 
 // UpdateTeam updates information of team.
-func UpdateTeam(ctx context.Context, t *organization.Team, authChanged, includeAllChanged bool) (err error) {
+func UpdateTeam(ctx context.Context, t *organization.Team, authChanged, includeAllChanged bool) error {
+	before, err := organization.GetTeamByID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if err := updateTeam(ctx, t, authChanged, includeAllChanged); err != nil {
+		return err
+	}
+	audit.Record(ctx, audit_model.ActionTeamUpdate, nil, audit.OwnerScope(t.OrgID), audit.TeamTarget(t), teamState(ctx, before), teamState(ctx, t))
+	return nil
+}
+
+func updateTeam(ctx context.Context, t *organization.Team, authChanged, includeAllChanged bool) (err error) {
 	if len(t.Name) == 0 {
 		return util.NewInvalidArgumentErrorf("empty team name")
 	}
@@ -166,6 +188,15 @@ func UpdateTeam(ctx context.Context, t *organization.Team, authChanged, includeA
 // DeleteTeam deletes given team.
 // It's caller's responsibility to assign organization ID.
 func DeleteTeam(ctx context.Context, t *organization.Team) error {
+	before := teamState(ctx, t)
+	if err := deleteTeam(ctx, t); err != nil {
+		return err
+	}
+	audit.Record(ctx, audit_model.ActionTeamDelete, nil, audit.OwnerScope(t.OrgID), audit.TeamTarget(t), before, nil)
+	return nil
+}
+
+func deleteTeam(ctx context.Context, t *organization.Team) error {
 	ctx, committer, err := db.TxContext(ctx)
 	if err != nil {
 		return err
@@ -293,9 +324,10 @@ func AddTeamMember(ctx context.Context, team *organization.Team, user *user_mode
 		}
 		return nil
 	})
-	if err != nil {
+	if err != nil || isAlreadyMember {
 		return err
 	}
+	audit.Record(ctx, audit_model.ActionTeamMemberAdd, nil, audit.OwnerScope(team.OrgID), audit.TeamTarget(team), nil, teamMemberState(user))
 
 	// this behaviour may spend much time so run it in a goroutine
 	// FIXME: Update watch repos batchly
@@ -389,13 +421,40 @@ func removeInvalidOrgUser(ctx context.Context, orgID int64, user *user_model.Use
 
 // RemoveTeamMember removes member from given team of given organization.
 func RemoveTeamMember(ctx context.Context, team *organization.Team, user *user_model.User) error {
-	ctx, committer, err := db.TxContext(ctx)
-	if err != nil {
+	isMember, err := organization.IsTeamMember(ctx, team.OrgID, team.ID, user.ID)
+	if err != nil || !isMember {
 		return err
 	}
-	defer committer.Close()
-	if err := removeTeamMember(ctx, team, user); err != nil {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		return removeTeamMember(ctx, team, user)
+	}); err != nil {
 		return err
 	}
-	return committer.Commit()
+	audit.Record(ctx, audit_model.ActionTeamMemberRemove, nil, audit.OwnerScope(team.OrgID), audit.TeamTarget(team), teamMemberState(user), nil)
+	return nil
+}
+
+// teamState returns the permissions of the team recorded in the audit log
+func teamState(ctx context.Context, t *organization.Team) map[string]any {
+	if len(t.Units) == 0 {
+		t.Units = nil
+		if err := t.LoadUnits(ctx); err != nil {
+			log.Error("LoadUnits: %v", err)
+		}
+	}
+	units := make(map[string]string, len(t.Units))
+	for _, u := range t.Units {
+		units[u.Unit().NameKey] = u.AccessMode.ToString()
+	}
+	return map[string]any{
+		"name":                      t.Name,
+		"access_mode":               t.AccessMode.ToString(),
+		"includes_all_repositories": t.IncludesAllRepositories,
+		"can_create_org_repo":       t.CanCreateOrgRepo,
+		"units":                     units,
+	}
+}
+
+func teamMemberState(u *user_model.User) map[string]any {
+	return map[string]any{"member": u.Name}
 }
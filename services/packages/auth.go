@@ -22,11 +22,14 @@ type packageClaims struct {
 	PackageMeta
 }
 type PackageMeta struct {
-	UserID int64
-	Scope  auth_model.AccessTokenScope
+	UserID        int64
+	Scope         auth_model.AccessTokenScope
+	ActionsTaskID int64
 }
 
-func CreateAuthorizationToken(u *user_model.User, packageScope auth_model.AccessTokenScope) (string, error) {
+// CreateAuthorizationToken creates a token of the user for the package registries,
+// actionsTaskID is the task authenticated by the Actions token if the user is the Actions user.
+func CreateAuthorizationToken(u *user_model.User, packageScope auth_model.AccessTokenScope, actionsTaskID int64) (string, error) {
 	now := time.Now()
 
 	claims := packageClaims{
@@ -35,8 +38,9 @@ func CreateAuthorizationToken(u *user_model.User, packageScope auth_model.Access
 			NotBefore: jwt.NewNumericDate(now),
 		},
 		PackageMeta: PackageMeta{
-			UserID: u.ID,
-			Scope:  packageScope,
+			UserID:        u.ID,
+			Scope:         packageScope,
+			ActionsTaskID: actionsTaskID,
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
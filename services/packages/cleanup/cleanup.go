@@ -99,10 +99,23 @@ func ExecuteCleanupRules(outerCtx context.Context) error {
 
 				log.Debug("Rule[%d]: remove '%s/%s'", pcr.ID, p.Name, pv.Version)
 
+				var pd *packages_model.PackageDescriptor
+				if pcr.Type == packages_model.TypeContainer {
+					if pd, err = packages_model.GetPackageDescriptor(ctx, pv); err != nil {
+						return fmt.Errorf("CleanupRule [%d]: GetPackageDescriptor failed: %w", pcr.ID, err)
+					}
+				}
+
 				if err := packages_service.DeletePackageVersionAndReferences(ctx, pv); err != nil {
 					return fmt.Errorf("CleanupRule [%d]: DeletePackageVersionAndReferences failed: %w", pcr.ID, err)
 				}
 
+				if pd != nil {
+					if err := packages_service.DeleteContainerReferrers(ctx, pd); err != nil {
+						return fmt.Errorf("CleanupRule [%d]: DeleteContainerReferrers failed: %w", pcr.ID, err)
+					}
+				}
+
 				versionDeleted = true
 				anyVersionDeleted = true
 			}
@@ -104,5 +104,24 @@ func ShouldBeSkipped(ctx context.Context, pcr *packages_model.PackageCleanupRule
 		}
 	}
 
+	// Skip referrers (signatures, SBOMs, ...) as long as their subject exists, they are removed with it
+	pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestSubject)
+	if err != nil {
+		return false, err
+	}
+	if len(pps) != 0 {
+		_, err := container_model.GetContainerBlob(ctx, &container_model.BlobSearchOptions{
+			OwnerID:    p.OwnerID,
+			Image:      p.LowerName,
+			Digest:     pps[0].Value,
+			IsManifest: true,
+		})
+		if err == nil {
+			return true, nil
+		} else if err != container_model.ErrContainerBlobNotExist {
+			return false, err
+		}
+	}
+
 	return false, nil
 }
@@ -14,12 +14,15 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	container_model "code.gitea.io/gitea/models/packages/container"
+	quota_model "code.gitea.io/gitea/models/quota"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/optional"
 	packages_module "code.gitea.io/gitea/modules/packages"
+	container_module "code.gitea.io/gitea/modules/packages/container"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	notify_service "code.gitea.io/gitea/services/notify"
@@ -344,60 +347,67 @@ func CheckCountQuotaExceeded(ctx context.Context, doer, owner *user_model.User)
 	return nil
 }
 
-// CheckSizeQuotaExceeded checks if the upload size is bigger than the allowed size
-// The check is skipped if the doer is an admin.
-func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, packageType packages_model.Type, uploadSize int64) error {
-	if doer.IsAdmin {
-		return nil
-	}
-
-	var typeSpecificSize int64
+// GetTypeSizeLimit returns the maximum size of a file of the package type, -1 if the size isn't limited
+func GetTypeSizeLimit(packageType packages_model.Type) int64 {
 	switch packageType {
 	case packages_model.TypeAlpine:
-		typeSpecificSize = setting.Packages.LimitSizeAlpine
+		return setting.Packages.LimitSizeAlpine
 	case packages_model.TypeArch:
-		typeSpecificSize = setting.Packages.LimitSizeArch
+		return setting.Packages.LimitSizeArch
 	case packages_model.TypeCargo:
-		typeSpecificSize = setting.Packages.LimitSizeCargo
+		return setting.Packages.LimitSizeCargo
 	case packages_model.TypeChef:
-		typeSpecificSize = setting.Packages.LimitSizeChef
+		return setting.Packages.LimitSizeChef
 	case packages_model.TypeComposer:
-		typeSpecificSize = setting.Packages.LimitSizeComposer
+		return setting.Packages.LimitSizeComposer
 	case packages_model.TypeConan:
-		typeSpecificSize = setting.Packages.LimitSizeConan
+		return setting.Packages.LimitSizeConan
 	case packages_model.TypeConda:
-		typeSpecificSize = setting.Packages.LimitSizeConda
+		return setting.Packages.LimitSizeConda
 	case packages_model.TypeContainer:
-		typeSpecificSize = setting.Packages.LimitSizeContainer
+		return setting.Packages.LimitSizeContainer
 	case packages_model.TypeCran:
-		typeSpecificSize = setting.Packages.LimitSizeCran
+		return setting.Packages.LimitSizeCran
 	case packages_model.TypeDebian:
-		typeSpecificSize = setting.Packages.LimitSizeDebian
+		return setting.Packages.LimitSizeDebian
 	case packages_model.TypeGeneric:
-		typeSpecificSize = setting.Packages.LimitSizeGeneric
+		return setting.Packages.LimitSizeGeneric
 	case packages_model.TypeGo:
-		typeSpecificSize = setting.Packages.LimitSizeGo
+		return setting.Packages.LimitSizeGo
 	case packages_model.TypeHelm:
-		typeSpecificSize = setting.Packages.LimitSizeHelm
+		return setting.Packages.LimitSizeHelm
 	case packages_model.TypeMaven:
-		typeSpecificSize = setting.Packages.LimitSizeMaven
+		return setting.Packages.LimitSizeMaven
 	case packages_model.TypeNpm:
-		typeSpecificSize = setting.Packages.LimitSizeNpm
+		return setting.Packages.LimitSizeNpm
 	case packages_model.TypeNuGet:
-		typeSpecificSize = setting.Packages.LimitSizeNuGet
+		return setting.Packages.LimitSizeNuGet
 	case packages_model.TypePub:
-		typeSpecificSize = setting.Packages.LimitSizePub
+		return setting.Packages.LimitSizePub
 	case packages_model.TypePyPI:
-		typeSpecificSize = setting.Packages.LimitSizePyPI
+		return setting.Packages.LimitSizePyPI
 	case packages_model.TypeRpm:
-		typeSpecificSize = setting.Packages.LimitSizeRpm
+		return setting.Packages.LimitSizeRpm
 	case packages_model.TypeRubyGems:
-		typeSpecificSize = setting.Packages.LimitSizeRubyGems
+		return setting.Packages.LimitSizeRubyGems
 	case packages_model.TypeSwift:
-		typeSpecificSize = setting.Packages.LimitSizeSwift
+		return setting.Packages.LimitSizeSwift
+	case packages_model.TypeTerraform:
+		return setting.Packages.LimitSizeTerraform
 	case packages_model.TypeVagrant:
-		typeSpecificSize = setting.Packages.LimitSizeVagrant
+		return setting.Packages.LimitSizeVagrant
 	}
+	return -1
+}
+
+// CheckSizeQuotaExceeded checks if the upload size is bigger than the allowed size
+// The check is skipped if the doer is an admin.
+func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, packageType packages_model.Type, uploadSize int64) error {
+	if doer.IsAdmin {
+		return nil
+	}
+
+	typeSpecificSize := GetTypeSizeLimit(packageType)
 	if typeSpecificSize > -1 && typeSpecificSize < uploadSize {
 		return ErrQuotaTypeSize
 	}
@@ -415,6 +425,15 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 		}
 	}
 
+	acceptable, err := quota_model.EvaluateForUser(ctx, owner.ID, quota_model.LimitSubjectSizePackages)
+	if err != nil {
+		log.Error("EvaluateForUser failed: %v", err)
+		return err
+	}
+	if !acceptable {
+		return ErrQuotaTotalSize
+	}
+
 	return nil
 }
 
@@ -487,6 +506,12 @@ func RemovePackageVersion(ctx context.Context, doer *user_model.User, pv *packag
 		return err
 	}
 
+	if pd.Package.Type == packages_model.TypeContainer {
+		if err := DeleteContainerReferrers(dbCtx, pd); err != nil {
+			return err
+		}
+	}
+
 	if err := committer.Commit(); err != nil {
 		return err
 	}
@@ -557,6 +582,57 @@ func DeletePackageVersionAndReferences(ctx context.Context, pv *packages_model.P
 	return packages_model.DeleteVersionByID(ctx, pv.ID)
 }
 
+// DeleteContainerReferrers deletes the manifests (signatures, SBOMs, ...) which reference the manifest of the deleted container package version.
+// The referrers are kept if the manifest is still available by another version of the image.
+func DeleteContainerReferrers(ctx context.Context, pd *packages_model.PackageDescriptor) error {
+	pfd := container_model.GetManifestFileDescriptor(pd)
+	if pfd == nil {
+		return nil
+	}
+	manifestDigest := pfd.Properties.GetByName(container_module.PropertyDigest)
+
+	_, err := container_model.GetContainerBlob(ctx, &container_model.BlobSearchOptions{
+		OwnerID:    pd.Owner.ID,
+		Image:      pd.Package.LowerName,
+		Digest:     manifestDigest,
+		IsManifest: true,
+	})
+	if err == nil {
+		return nil
+	} else if err != container_model.ErrContainerBlobNotExist {
+		return err
+	}
+
+	pvs, err := container_model.GetReferrers(ctx, &container_model.ReferrerSearchOptions{
+		OwnerID: pd.Owner.ID,
+		Image:   pd.Package.LowerName,
+		Subject: manifestDigest,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pv := range pvs {
+		rpd, err := packages_model.GetPackageDescriptor(ctx, pv)
+		if err != nil {
+			return err
+		}
+
+		log.Trace("Deleting referrer %s of container manifest %s", pv.Version, manifestDigest)
+
+		if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
+			return err
+		}
+
+		// referrers can be referenced too, for example a signed SBOM
+		if err := DeleteContainerReferrers(ctx, rpd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeletePackageFile deletes the package file and its properties
 func DeletePackageFile(ctx context.Context, pf *packages_model.PackageFile) error {
 	if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeFile, pf.ID); err != nil {
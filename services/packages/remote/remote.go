@@ -0,0 +1,326 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+// Package remote fetches packages from the remote registries of the owners.
+// The registry handlers cache the fetched packages as package versions of the owner, so they are served locally from then on.
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	quota_model "code.gitea.io/gitea/models/quota"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/globallock"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+var (
+	// ErrNotFound indicates that the remote registry doesn't have the requested resource
+	ErrNotFound = util.NewNotExistErrorf("resource does not exist in the remote registry")
+	// ErrInvalidURL indicates that the URL of a remote registry is invalid or not allowed
+	ErrInvalidURL = util.NewInvalidArgumentErrorf("remote registry url is invalid or not allowed")
+)
+
+// maxMetadataSize is the maximum size of the metadata fetched from a remote registry
+const maxMetadataSize = 64 * 1024 * 1024
+
+// responseHeaderTimeout is how long the remote registry may take to respond, the body of large packages may take longer
+const responseHeaderTimeout = 60 * time.Second
+
+func allowList() *hostmatcher.HostMatchList {
+	allowedHostListValue := setting.Packages.RemoteAllowedHostList
+	if allowedHostListValue == "" {
+		allowedHostListValue = hostmatcher.MatchBuiltinExternal
+	}
+	return hostmatcher.ParseHostMatchList("packages.REMOTE_ALLOWED_HOST_LIST", allowedHostListValue)
+}
+
+// ValidateURL checks that the URL of a remote registry is an absolute http(s) URL on an allowed host
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	// the host is checked again when connecting because the host name may resolve to another address
+	if !allowList().MatchHostName(u.Hostname()) {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// GetRemote returns the remote registry of the owner for the package type, it returns nil if the owner has none
+func GetRemote(ctx context.Context, owner *user_model.User, packageType packages_model.Type) (*packages_model.PackageRemote, error) {
+	pr, err := packages_model.GetRemoteByOwnerAndType(ctx, owner.ID, packageType)
+	if err != nil {
+		if err == packages_model.ErrPackageRemoteNotExist {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+// GetClient returns a client for the remote registry of the owner for the package type, it returns nil if the owner has none
+func GetClient(ctx context.Context, owner *user_model.User, packageType packages_model.Type) (*Client, error) {
+	pr, err := GetRemote(ctx, owner, packageType)
+	if err != nil || pr == nil {
+		return nil, err
+	}
+	return NewClient(pr), nil
+}
+
+// Creator returns the user the packages fetched for the doer are created by, the doer may be anonymous
+func Creator(doer *user_model.User) *user_model.User {
+	if doer == nil {
+		return user_model.NewGhostUser()
+	}
+	return doer
+}
+
+// Client fetches resources from a remote registry
+type Client struct {
+	Remote *packages_model.PackageRemote
+
+	httpClient *http.Client
+	token      string
+}
+
+// NewClient creates a client for the remote registry, the connections are restricted to the allowed hosts
+func NewClient(remote *packages_model.PackageRemote) *Client {
+	return &Client{
+		Remote: remote,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 proxy.Proxy(),
+				DialContext:           hostmatcher.NewDialContext("package remote", allowList(), nil, setting.Proxy.ProxyURLFixed),
+				ResponseHeaderTimeout: responseHeaderTimeout,
+			},
+		},
+	}
+}
+
+// ResolveURL returns the URL of the path in the remote registry, absolute URLs are returned unchanged
+func (c *Client) ResolveURL(path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return strings.TrimSuffix(c.Remote.URL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// Get requests the path from the remote registry, the caller must close the body of the response.
+// ErrNotFound is returned if the remote registry doesn't have the resource.
+func (c *Client) Get(ctx context.Context, method, path string, header http.Header) (*http.Response, error) {
+	resp, err := c.do(ctx, method, c.ResolveURL(path), header)
+	if err != nil {
+		return nil, err
+	}
+
+	// container registries ask anonymous clients to get a token first
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.requestToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = c.do(ctx, method, c.ResolveURL(path), header); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote registry responded with status %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, u string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", "Gitea "+setting.AppVer)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return c.httpClient.Do(req)
+}
+
+var challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// requestToken gets an anonymous token with the parameters of the bearer challenge
+// https://distribution.github.io/distribution/spec/auth/token/
+func (c *Client) requestToken(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return errors.New("remote registry requires authentication")
+	}
+
+	var realm string
+	query := url.Values{}
+	for _, m := range challengeParamPattern.FindAllStringSubmatch(params, -1) {
+		switch m[1] {
+		case "realm":
+			realm = m[2]
+		case "service", "scope":
+			query.Set(m[1], m[2])
+		}
+	}
+	if realm == "" {
+		return errors.New("remote registry requires authentication")
+	}
+	tokenURL := realm
+	if len(query) > 0 {
+		tokenURL += "?" + query.Encode()
+	}
+
+	resp, err := c.do(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote registry token request responded with status %d", resp.StatusCode)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMetadataSize)).Decode(&token); err != nil {
+		return err
+	}
+	c.token = util.IfZero(token.Token, token.AccessToken)
+	if c.token == "" {
+		return errors.New("remote registry token request returned no token")
+	}
+	return nil
+}
+
+// ReadAll requests the path and returns the body of the response
+func (c *Client) ReadAll(ctx context.Context, path string, header http.Header) ([]byte, error) {
+	resp, err := c.Get(ctx, http.MethodGet, path, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+}
+
+// Download requests the path and buffers the body of the response, the caller must close the buffer.
+// The body may not exceed the size limit of the package type and the storage left to the owner.
+func (c *Client) Download(ctx context.Context, path string, header http.Header) (*packages_module.HashedBuffer, error) {
+	maxSize, errTooLarge, err := c.downloadSizeLimit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.Get(ctx, http.MethodGet, path, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if maxSize == -1 {
+		return packages_module.CreateHashedBufferFromReader(resp.Body)
+	}
+	if resp.ContentLength > maxSize {
+		return nil, errTooLarge
+	}
+	buf, err := packages_module.CreateHashedBufferFromReader(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if buf.Size() > maxSize {
+		buf.Close()
+		return nil, errTooLarge
+	}
+	return buf, nil
+}
+
+// downloadSizeLimit returns the maximum size of a downloaded file, -1 if it isn't limited,
+// and the error to return if the file is larger
+func (c *Client) downloadSizeLimit(ctx context.Context) (int64, error, error) {
+	maxSize, errTooLarge := packages_service.GetTypeSizeLimit(c.Remote.Type), packages_service.ErrQuotaTypeSize
+
+	if setting.Packages.LimitTotalOwnerSize > -1 {
+		totalSize, err := packages_model.CalculateFileSize(ctx, &packages_model.PackageFileSearchOptions{
+			OwnerID: c.Remote.OwnerID,
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+		if left := max(setting.Packages.LimitTotalOwnerSize-totalSize, 0); maxSize == -1 || left < maxSize {
+			maxSize, errTooLarge = left, packages_service.ErrQuotaTotalSize
+		}
+	}
+
+	acceptable, err := quota_model.EvaluateForUser(ctx, c.Remote.OwnerID, quota_model.LimitSubjectSizePackages)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !acceptable {
+		return 0, packages_service.ErrQuotaTotalSize, nil
+	}
+
+	return maxSize, errTooLarge, nil
+}
+
+// LockAndDo runs f while holding a lock for the key, so a resource is fetched from the remote registry only once
+func (c *Client) LockAndDo(ctx context.Context, key string, f func(ctx context.Context) error) error {
+	return globallock.LockAndDo(ctx, fmt.Sprintf("package_remote_%d_%s", c.Remote.ID, key), f)
+}
+
+// Metadata returns the metadata of the key, fetch is called to get it from the remote registry if the cached metadata
+// is older than the metadata TTL. The cached metadata is returned if the remote registry is unavailable.
+func (c *Client) Metadata(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if len(key) > packages_model.MaxRemoteMetadataKeyLength {
+		return fetch(ctx)
+	}
+
+	prm, err := packages_model.GetRemoteMetadata(ctx, c.Remote.ID, key)
+	if err != nil && err != packages_model.ErrPackageRemoteMetadataNotExist {
+		return nil, err
+	}
+	if prm != nil && prm.FetchedUnix.AddDuration(setting.Packages.RemoteMetadataTTL) > timeutil.TimeStampNow() {
+		return []byte(prm.Content), nil
+	}
+
+	content, err := fetch(ctx)
+	if err != nil {
+		if prm != nil && err != ErrNotFound {
+			log.Warn("Unable to refresh the metadata %q from the remote registry %s, using the cached metadata: %v", key, c.Remote.URL, err)
+			return []byte(prm.Content), nil
+		}
+		return nil, err
+	}
+
+	if err := packages_model.SetRemoteMetadata(ctx, c.Remote.ID, key, string(content)); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/organization"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
@@ -28,6 +30,7 @@ import (
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/timeutil"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	issue_service "code.gitea.io/gitea/services/issue"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -173,6 +176,113 @@ func isSignedIfRequired(ctx context.Context, pr *issues_model.PullRequest, doer
 	return sign, err
 }
 
+// UnapprovedCodeOwnersRule is a CODEOWNERS rule matching files changed by a pull request
+// which hasn't been approved by any of its owners yet
+type UnapprovedCodeOwnersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// String returns the pattern and the owners of the rule, e.g. "deploy/.* (@alice, @org/platform)"
+func (r *UnapprovedCodeOwnersRule) String() string {
+	return fmt.Sprintf("%s (@%s)", r.Pattern, strings.Join(r.Owners, ", @"))
+}
+
+// GetUnapprovedCodeOwnersRules returns the CODEOWNERS rules owning the files changed by the pull request
+// which haven't been approved by one of their owners. The CODEOWNERS file is read from
+// the base branch so that a pull request can't change the owners who have to approve it.
+func GetUnapprovedCodeOwnersRules(ctx context.Context, pb *git_model.ProtectedBranch, pr *issues_model.PullRequest) ([]*UnapprovedCodeOwnersRule, error) {
+	if pb == nil || !pb.RequireCodeOwnerApproval {
+		return nil, nil
+	}
+
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return nil, err
+	}
+
+	gitRepo, err := gitrepo.OpenRepository(ctx, pr.BaseRepo)
+	if err != nil {
+		return nil, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	rules := issue_service.GetCodeOwnerRules(ctx, commit)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	var changedFiles []string
+	if pr.MergeBase != "" {
+		changedFiles, err = gitRepo.GetFilesChangedBetween(pr.MergeBase, pr.GetGitRefName())
+	} else {
+		changedFiles, err = issue_service.GetPullRequestChangedFiles(gitRepo, pr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules = issue_service.MatchLastCodeOwnerRules(rules, changedFiles)
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	approverIDs, err := issues_model.GetApprovalReviewerIDs(ctx, pb, pr)
+	if err != nil {
+		return nil, err
+	}
+
+	unapproved := make([]*UnapprovedCodeOwnersRule, 0, len(rules))
+	for _, rule := range rules {
+		approved, err := isCodeOwnersRuleApproved(ctx, rule, approverIDs)
+		if err != nil {
+			return nil, err
+		}
+		if approved {
+			continue
+		}
+
+		unapprovedRule := &UnapprovedCodeOwnersRule{
+			Pattern: rule.Pattern,
+			Owners:  make([]string, 0, len(rule.Users)+len(rule.Teams)),
+		}
+		for _, u := range rule.Users {
+			unapprovedRule.Owners = append(unapprovedRule.Owners, u.Name)
+		}
+		for _, t := range rule.Teams {
+			org, err := user_model.GetUserByID(ctx, t.OrgID)
+			if err != nil {
+				return nil, err
+			}
+			unapprovedRule.Owners = append(unapprovedRule.Owners, org.Name+"/"+t.Name)
+		}
+		unapproved = append(unapproved, unapprovedRule)
+	}
+	return unapproved, nil
+}
+
+// isCodeOwnersRuleApproved returns true if one of the approvers is an owner of the rule
+func isCodeOwnersRuleApproved(ctx context.Context, rule *issues_model.CodeOwnerRule, approverIDs []int64) (bool, error) {
+	for _, u := range rule.Users {
+		if slices.Contains(approverIDs, u.ID) {
+			return true, nil
+		}
+	}
+	for _, t := range rule.Teams {
+		for _, approverID := range approverIDs {
+			if isMember, err := organization.IsTeamMember(ctx, t.OrgID, t.ID, approverID); err != nil {
+				return false, err
+			} else if isMember {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 // checkAndUpdateStatus checks if pull request is possible to leaving checking status,
 // and set to be either conflict or mergeable.
 func checkAndUpdateStatus(ctx context.Context, pr *issues_model.PullRequest) {
@@ -411,5 +521,5 @@ func Init() error {
 
 	go graceful.GetManager().RunWithCancel(prPatchCheckerQueue)
 	go graceful.GetManager().RunWithShutdownContext(InitializePullRequests)
-	return nil
+	return initMergeQueue()
 }
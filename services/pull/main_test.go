@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/models/unittest"
 
 	_ "code.gitea.io/gitea/models/actions"
+	_ "code.gitea.io/gitea/models/audit"
 )
 
 func TestMain(m *testing.M) {
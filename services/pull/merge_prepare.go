@@ -44,6 +44,12 @@ func (ctx *mergeContext) RunOpts() *git.RunOpts {
 }
 
 func createTemporaryRepoForMerge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, expectedHeadCommitID string) (mergeCtx *mergeContext, cancel context.CancelFunc, err error) {
+	return createTemporaryRepoForMergeOnBase(ctx, pr, doer, expectedHeadCommitID, "")
+}
+
+// createTemporaryRepoForMergeOnBase works like createTemporaryRepoForMerge,
+// but the pull request will be merged onto baseCommitID instead of the head of the base branch if it's not empty
+func createTemporaryRepoForMergeOnBase(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, expectedHeadCommitID, baseCommitID string) (mergeCtx *mergeContext, cancel context.CancelFunc, err error) {
 	// Clone base repo.
 	prCtx, cancel, err := createTemporaryRepoForPR(ctx, pr)
 	if err != nil {
@@ -72,6 +78,17 @@ func createTemporaryRepoForMerge(ctx context.Context, pr *issues_model.PullReque
 		}
 	}
 
+	if baseCommitID != "" {
+		// the commit is reachable through the alternates of the base repository
+		for _, branch := range []string{baseBranch, "original_" + baseBranch} {
+			if err := git.NewCommand(ctx, "update-ref").AddDynamicArguments(git.BranchPrefix+branch, baseCommitID).Run(mergeCtx.RunOpts()); err != nil {
+				defer cancel()
+				log.Error("%-v Unable to move %s to %s in %s: %v\n%s\n%s", pr, branch, baseCommitID, mergeCtx.tmpBasePath, err, mergeCtx.outbuf.String(), mergeCtx.errbuf.String())
+				return nil, nil, fmt.Errorf("unable to move %s to %s: %w\n%s\n%s", branch, baseCommitID, err, mergeCtx.outbuf.String(), mergeCtx.errbuf.String())
+			}
+		}
+	}
+
 	mergeCtx.outbuf.Reset()
 	mergeCtx.errbuf.Reset()
 	if err := prepareTemporaryRepoForMerge(mergeCtx); err != nil {
@@ -0,0 +1,548 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/gitrepo"
+	"code.gitea.io/gitea/modules/globallock"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	notify_service "code.gitea.io/gitea/services/notify"
+)
+
+// MergeQueueBranchPrefix is the prefix of the branches holding the merge groups of the merge queues
+const MergeQueueBranchPrefix = "gitea-merge-queue/"
+
+// Reasons for removing a pull request from the merge queue, they are stored as the content of the comment
+const (
+	MergeQueueRemovedCanceled      = "canceled"
+	MergeQueueRemovedConflict      = "conflict"
+	MergeQueueRemovedOutdated      = "outdated"
+	MergeQueueRemovedChecksFailed  = "checks_failed"
+	MergeQueueRemovedChecksTimeout = "checks_timeout"
+	MergeQueueRemovedMergeFailed   = "merge_failed"
+)
+
+// prMergeQueue represents a queue to handle the merge queues of protected branches
+var prMergeQueue *queue.WorkerPoolQueue[string]
+
+func initMergeQueue() error {
+	prMergeQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "pr_merge_queue", mergeQueueHandler)
+	if prMergeQueue == nil {
+		return fmt.Errorf("unable to create pr_merge_queue queue")
+	}
+	go graceful.GetManager().RunWithCancel(prMergeQueue)
+	go graceful.GetManager().RunWithShutdownContext(initializeMergeQueues)
+	return nil
+}
+
+// initializeMergeQueues resumes the merge queues which still have pull requests waiting
+func initializeMergeQueues(ctx context.Context) {
+	entries, err := pull_model.GetMergeQueueBranches(ctx)
+	if err != nil {
+		log.Error("GetMergeQueueBranches: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		triggerMergeQueue(entry.RepoID, entry.BaseBranch)
+	}
+}
+
+func mergeQueueHandler(items ...string) []string {
+	for _, s := range items {
+		var repoID int64
+		var branch string
+		if _, err := fmt.Sscanf(s, "%d_%s", &repoID, &branch); err != nil {
+			log.Error("could not parse data from pr_merge_queue queue (%v): %v", s, err)
+			continue
+		}
+		handleMergeQueue(repoID, branch)
+	}
+	return nil
+}
+
+func triggerMergeQueue(repoID int64, branch string) {
+	log.Trace("Adding branch %s of repo %d to the merge queue checking queue", branch, repoID)
+	if err := prMergeQueue.Push(fmt.Sprintf("%d_%s", repoID, branch)); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Error adding branch %s of repo %d to the merge queue checking queue: %v", branch, repoID, err)
+	}
+}
+
+func getMergeQueueLockKey(repoID int64, branch string) string {
+	return fmt.Sprintf("pull_merge_queue_%d_%s", repoID, branch)
+}
+
+// MergeGroupRefName returns the ref which holds the merge group of the pull request
+func MergeGroupRefName(pr *issues_model.PullRequest) git.RefName {
+	return git.RefNameFromBranch(fmt.Sprintf("%s%s/pr-%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index))
+}
+
+// IsMergeQueueEnabled returns whether the pull request has to be merged through the merge queue of its base branch
+func IsMergeQueueEnabled(ctx context.Context, pr *issues_model.PullRequest) (bool, error) {
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return false, err
+	}
+	return pb != nil && pb.EnableMergeQueue, nil
+}
+
+// AddToMergeQueue adds the pull request to the end of the merge queue of its base branch.
+// Caller should check PR is ready to be merged (review and status checks)
+func AddToMergeQueue(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return fmt.Errorf("unable to load base repo: %w", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(ctx, unit.TypePullRequests)
+	if err != nil {
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	baseGitRepo, err := gitrepo.OpenRepository(ctx, pr.BaseRepo)
+	if err != nil {
+		return err
+	}
+	defer baseGitRepo.Close()
+	headCommitID, err := baseGitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return err
+	}
+	if expectedHeadCommitID != "" && expectedHeadCommitID != headCommitID {
+		return models.ErrSHADoesNotMatch{
+			GivenSHA:   expectedHeadCommitID,
+			CurrentSHA: headCommitID,
+		}
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := pull_model.AddToMergeQueue(ctx, &pull_model.MergeQueueEntry{
+			RepoID:       pr.BaseRepoID,
+			BaseBranch:   pr.BaseBranch,
+			PullID:       pr.ID,
+			DoerID:       doer.ID,
+			MergeStyle:   mergeStyle,
+			Message:      message,
+			HeadCommitID: headCommitID,
+		}); err != nil {
+			return err
+		}
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRAddedToMergeQueue, pr, doer, "")
+		return err
+	}); err != nil {
+		return err
+	}
+
+	triggerMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes the pull request from the merge queue, the merge groups behind it will be rebuilt
+func RemoveFromMergeQueue(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, reason string) error {
+	if err := removeFromMergeQueue(ctx, pr, doer, reason); err != nil {
+		return err
+	}
+	triggerMergeQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// removeFromMergeQueue removes the pull request from the merge queue without processing the queue again,
+// it is used while the lock of the merge queue is held.
+func removeFromMergeQueue(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, reason string) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+			return err
+		}
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRRemovedFromMergeQueue, pr, doer, reason)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	deleteMergeGroupRef(ctx, pr)
+	return nil
+}
+
+// removeOutdatedFromMergeQueue removes the pull requests from the merge queue since their head branches have been updated
+func removeOutdatedFromMergeQueue(ctx context.Context, doer *user_model.User, prs []*issues_model.PullRequest) {
+	for _, pr := range prs {
+		exist, _, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+		if err != nil {
+			log.Error("GetMergeQueueEntryByPullID: %v", err)
+			continue
+		} else if !exist {
+			continue
+		}
+		if err := RemoveFromMergeQueue(ctx, pr, doer, MergeQueueRemovedOutdated); err != nil {
+			log.Error("RemoveFromMergeQueue %-v: %v", pr, err)
+		}
+	}
+}
+
+// StartMergeQueueByGroupCommitID checks the merge queues which have a merge group for the commit
+func StartMergeQueueByGroupCommitID(ctx context.Context, repo *repo_model.Repository, commitID string) error {
+	entries, err := pull_model.GetMergeQueueEntriesByGroupCommitID(ctx, repo.ID, commitID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		triggerMergeQueue(entry.RepoID, entry.BaseBranch)
+	}
+	return nil
+}
+
+func deleteMergeGroupRef(ctx context.Context, pr *issues_model.PullRequest) {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		log.Error("LoadBaseRepo %-v: %v", pr, err)
+		return
+	}
+	ref := MergeGroupRefName(pr)
+	if !git.IsReferenceExist(ctx, pr.BaseRepo.RepoPath(), ref.String()) {
+		return
+	}
+	if err := git.NewCommand(ctx, "update-ref", "-d").AddDynamicArguments(ref.String()).Run(&git.RunOpts{Dir: pr.BaseRepo.RepoPath()}); err != nil {
+		log.Error("Unable to delete merge group %s of %-v: %v", ref, pr, err)
+	}
+}
+
+// mergeQueueItem is a pull request in the merge queue whose merge group is up to date
+type mergeQueueItem struct {
+	entry *pull_model.MergeQueueEntry
+	pr    *issues_model.PullRequest
+}
+
+// handleMergeQueue rebuilds the outdated merge groups of the merge queue
+// and fast-forwards the branch to the merge groups which have passed the required status checks
+func handleMergeQueue(repoID int64, branch string) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(),
+		fmt.Sprintf("Handle merge queue of branch %s in repo %d", branch, repoID))
+	defer finished()
+
+	releaser, err := globallock.Lock(ctx, getMergeQueueLockKey(repoID, branch))
+	if err != nil {
+		log.Error("lock.Lock(): %v", err)
+		return
+	}
+	defer releaser()
+
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		log.Error("GetRepositoryByID[%d]: %v", repoID, err)
+		return
+	}
+
+	for {
+		merged, err := processMergeQueue(ctx, repo, branch)
+		if err != nil {
+			log.Error("processMergeQueue[%-v, %s]: %v", repo, branch, err)
+			return
+		}
+		if !merged {
+			return
+		}
+	}
+}
+
+// processMergeQueue makes sure all merge groups of the queue are up to date and merges the first one if its checks have passed,
+// it returns true if a pull request has been merged or ejected, the queue should be processed again in that case.
+func processMergeQueue(ctx context.Context, repo *repo_model.Repository, branch string) (bool, error) {
+	entries, err := pull_model.GetMergeQueueEntries(ctx, repo.ID, branch)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 {
+		return false, nil
+	}
+
+	baseCommitID, err := gitrepo.GetBranchCommitID(ctx, repo, branch)
+	if err != nil {
+		return false, err
+	}
+
+	items := make([]*mergeQueueItem, 0, len(entries))
+	parentCommitID := baseCommitID
+	for _, entry := range entries {
+		pr, err := issues_model.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			if !issues_model.IsErrPullRequestNotExist(err) {
+				return false, err
+			}
+			if err := pull_model.DeleteMergeQueueEntry(ctx, entry.PullID); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if err := pr.LoadIssue(ctx); err != nil {
+			return false, err
+		}
+		if pr.HasMerged || pr.Issue.IsClosed || pr.BaseBranch != branch {
+			// there is already a comment on the pull request to explain it
+			if err := pull_model.DeleteMergeQueueEntry(ctx, entry.PullID); err != nil {
+				return false, err
+			}
+			deleteMergeGroupRef(ctx, pr)
+			continue
+		}
+		if err := entry.LoadDoer(ctx); err != nil {
+			return false, err
+		}
+
+		if entry.GroupCommitID == "" || entry.BaseCommitID != parentCommitID {
+			if err := buildMergeGroup(ctx, entry, pr, parentCommitID); err != nil {
+				log.Info("Unable to build the merge group of %-v: %v", pr, err)
+				reason := MergeQueueRemovedMergeFailed
+				if models.IsErrMergeConflicts(err) || models.IsErrRebaseConflicts(err) || models.IsErrMergeUnrelatedHistories(err) {
+					reason = MergeQueueRemovedConflict
+				} else if models.IsErrSHADoesNotMatch(err) {
+					reason = MergeQueueRemovedOutdated
+				}
+				if err := removeFromMergeQueue(ctx, pr, entry.Doer, reason); err != nil {
+					return false, err
+				}
+				continue
+			}
+		}
+		parentCommitID = entry.GroupCommitID
+		items = append(items, &mergeQueueItem{entry: entry, pr: pr})
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	// only the first merge group can be merged, the others are based on it
+	first := items[0]
+	state, err := getMergeGroupCommitStatusState(ctx, repo, branch, first.entry.GroupCommitID)
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case state.IsSuccess():
+		if err := fastForwardToMergeGroup(ctx, first.entry, first.pr); err != nil {
+			if git.IsErrPushOutOfDate(err) {
+				// the branch has been changed, the merge groups will be rebuilt
+				return true, nil
+			}
+			log.Info("Unable to merge the merge group of %-v: %v", first.pr, err)
+			return true, removeFromMergeQueue(ctx, first.pr, first.entry.Doer, MergeQueueRemovedMergeFailed)
+		}
+		return true, nil
+	case state.IsFailure(), state.IsError():
+		return true, removeFromMergeQueue(ctx, first.pr, first.entry.Doer, MergeQueueRemovedChecksFailed)
+	default:
+		return false, nil
+	}
+}
+
+// RemoveTimedOutFromMergeQueues removes the first pull requests of the merge queues whose merge groups have been waiting
+// for the required status checks for longer than the timeout, a check which never reports would block the queue otherwise.
+func RemoveTimedOutFromMergeQueues(ctx context.Context, timeout time.Duration) error {
+	branches, err := pull_model.GetMergeQueueBranches(ctx)
+	if err != nil {
+		return err
+	}
+	for _, b := range branches {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before checking the merge queue of branch %s in repo %d", b.BaseBranch, b.RepoID)
+		default:
+		}
+		trigger, err := removeTimedOutFromMergeQueue(ctx, b.RepoID, b.BaseBranch, timeout)
+		if err != nil {
+			log.Error("Unable to check the timeout of the merge queue of branch %s in repo %d: %v", b.BaseBranch, b.RepoID, err)
+		} else if trigger {
+			triggerMergeQueue(b.RepoID, b.BaseBranch)
+		}
+	}
+	return nil
+}
+
+// removeTimedOutFromMergeQueue removes the first pull request of the merge queue if its checks have timed out,
+// it returns true if the merge queue has to be processed again.
+func removeTimedOutFromMergeQueue(ctx context.Context, repoID int64, branch string, timeout time.Duration) (bool, error) {
+	releaser, err := globallock.Lock(ctx, getMergeQueueLockKey(repoID, branch))
+	if err != nil {
+		return false, err
+	}
+	defer releaser()
+
+	entries, err := pull_model.GetMergeQueueEntries(ctx, repoID, branch)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) == 0 {
+		return false, nil
+	}
+	first := entries[0]
+	if first.GroupCommitID == "" || first.GroupUpdatedUnix.AddDuration(timeout) > timeutil.TimeStampNow() {
+		return false, nil
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		return false, err
+	}
+	baseCommitID, err := gitrepo.GetBranchCommitID(ctx, repo, branch)
+	if err != nil {
+		return false, err
+	}
+	if first.BaseCommitID != baseCommitID {
+		// the merge group is outdated, it will be rebuilt
+		return true, nil
+	}
+	state, err := getMergeGroupCommitStatusState(ctx, repo, branch, first.GroupCommitID)
+	if err != nil {
+		return false, err
+	}
+	if state.IsSuccess() || state.IsFailure() || state.IsError() {
+		// the checks have finished, the merge queue will handle the result
+		return true, nil
+	}
+
+	pr, err := issues_model.GetPullRequestByID(ctx, first.PullID)
+	if err != nil {
+		return false, err
+	}
+	if err := first.LoadDoer(ctx); err != nil {
+		return false, err
+	}
+	log.Info("The checks of the merge group of %-v haven't finished in %v", pr, timeout)
+	return true, removeFromMergeQueue(ctx, pr, first.Doer, MergeQueueRemovedChecksTimeout)
+}
+
+// getMergeGroupCommitStatusState returns the state of the required status checks of the branch on the merge group commit
+func getMergeGroupCommitStatusState(ctx context.Context, repo *repo_model.Repository, branch, commitID string) (structs.CommitStatusState, error) {
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, repo.ID, branch)
+	if err != nil {
+		return "", err
+	}
+	var requiredContexts []string
+	if pb != nil && pb.EnableStatusCheck {
+		requiredContexts = pb.StatusCheckContexts
+	}
+
+	commitStatuses, _, err := git_model.GetLatestCommitStatus(ctx, repo.ID, commitID, db.ListOptionsAll)
+	if err != nil {
+		return "", err
+	}
+	return MergeRequiredContextsCommitStatus(commitStatuses, requiredContexts), nil
+}
+
+// buildMergeGroup merges the pull request onto baseCommitID and stores the result as the merge group of the entry
+func buildMergeGroup(ctx context.Context, entry *pull_model.MergeQueueEntry, pr *issues_model.PullRequest, baseCommitID string) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return err
+	}
+	if err := pr.LoadHeadRepo(ctx); err != nil {
+		return err
+	}
+
+	mergeCtx, cancel, err := createTemporaryRepoForMergeOnBase(ctx, pr, entry.Doer, entry.HeadCommitID, baseCommitID)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	if err := doMergeStyle(mergeCtx, entry.MergeStyle, entry.Message); err != nil {
+		return err
+	}
+
+	groupCommitID, err := git.GetFullCommitID(ctx, mergeCtx.tmpBasePath, baseBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get full commit id for the merge group: %w", err)
+	}
+
+	if setting.LFS.StartServer {
+		if err := LFSPush(ctx, mergeCtx.tmpBasePath, groupCommitID, baseCommitID, pr); err != nil {
+			return err
+		}
+	}
+
+	// Fetch the merge group into the base repository rather than pushing it,
+	// the merge group is not a real branch and shouldn't trigger the git hooks.
+	groupRef := MergeGroupRefName(pr)
+	if err := git.NewCommand(ctx, "fetch", "--no-tags").AddDynamicArguments(mergeCtx.tmpBasePath, "+"+git.BranchPrefix+baseBranch+":"+groupRef.String()).
+		Run(&git.RunOpts{Dir: pr.BaseRepo.RepoPath(), Stdout: mergeCtx.outbuf, Stderr: mergeCtx.errbuf}); err != nil {
+		return fmt.Errorf("unable to fetch the merge group into %s: %w\n%s\n%s", groupRef, err, mergeCtx.outbuf.String(), mergeCtx.errbuf.String())
+	}
+
+	entry.BaseCommitID = baseCommitID
+	entry.GroupCommitID = groupCommitID
+	if err := pull_model.UpdateMergeQueueEntryGroup(ctx, entry); err != nil {
+		return err
+	}
+
+	notify_service.MergeGroupChecksRequested(ctx, entry.Doer, pr, groupRef, groupCommitID, baseCommitID)
+	return nil
+}
+
+// fastForwardToMergeGroup pushes the merge group commit to the base branch, which merges the pull request
+func fastForwardToMergeGroup(ctx context.Context, entry *pull_model.MergeQueueEntry, pr *issues_model.PullRequest) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return err
+	}
+	if err := pr.LoadHeadRepo(ctx); err != nil {
+		return err
+	}
+
+	headUser := entry.Doer
+	if err := pr.HeadRepo.LoadOwner(ctx); err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+	} else {
+		headUser = pr.HeadRepo.Owner
+	}
+
+	env := repo_module.FullPushingEnvironment(headUser, entry.Doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID)
+	env = append(env, repo_module.EnvPushTrigger+"="+string(repo_module.PushTriggerPRMergeToBase))
+
+	// Push the commit within the base repository, the post receive hook will mark the pull request as merged.
+	// The push will be rejected if the branch has been changed since the merge group was built.
+	stdout, stderr := &strings.Builder{}, &strings.Builder{}
+	if err := git.NewCommand(ctx, "push", ".").AddDynamicArguments(entry.GroupCommitID + ":" + git.BranchPrefix + pr.BaseBranch).
+		Run(&git.RunOpts{Dir: pr.BaseRepo.RepoPath(), Env: env, Stdout: stdout, Stderr: stderr}); err != nil {
+		if strings.Contains(stderr.String(), "non-fast-forward") || strings.Contains(stderr.String(), "fetch first") {
+			return &git.ErrPushOutOfDate{
+				StdOut: stdout.String(),
+				StdErr: stderr.String(),
+				Err:    err,
+			}
+		}
+		return fmt.Errorf("git push: %w\n%s", err, stderr.String())
+	}
+
+	if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+		return err
+	}
+	deleteMergeGroupRef(ctx, pr)
+
+	go AddTestPullRequestTask(entry.Doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+
+	if err := afterMergePushed(ctx, pr.ID, entry.Doer, false); err != nil {
+		log.Error("afterMergePushed %-v: %v", pr, err)
+	}
+	return nil
+}
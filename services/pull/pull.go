@@ -383,6 +383,8 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 		}
 
 		if isSync {
+			removeOutdatedFromMergeQueue(ctx, doer, prs)
+
 			requests := issues_model.PullRequestList(prs)
 			if err = requests.LoadAttributes(ctx); err != nil {
 				log.Error("PullRequestList.LoadAttributes: %v", err)
@@ -456,6 +458,10 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 			}
 			AddToTaskQueue(ctx, pr)
 		}
+		if len(prs) > 0 {
+			// the merge groups have to be rebuilt if the base branch has been changed
+			triggerMergeQueue(repoID, branch)
+		}
 	})
 }
 
@@ -8,12 +8,14 @@ import (
 	"context"
 	"fmt"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/services/audit"
 
 	"xorm.io/builder"
 )
@@ -32,7 +34,9 @@ func AddOrUpdateCollaborator(ctx context.Context, repo *repo_model.Repository, u
 		return user_model.ErrBlockedUser
 	}
 
-	return db.WithTx(ctx, func(ctx context.Context) error {
+	var oldMode perm.AccessMode
+	changed := false
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
 		collaboration, has, err := db.Get[repo_model.Collaboration](ctx, builder.Eq{
 			"repo_id": repo.ID,
 			"user_id": u.ID,
@@ -43,6 +47,7 @@ func AddOrUpdateCollaborator(ctx context.Context, repo *repo_model.Repository, u
 			if collaboration.Mode == mode {
 				return nil
 			}
+			oldMode = collaboration.Mode
 			if _, err = db.GetEngine(ctx).
 				Where("repo_id=?", repo.ID).
 				And("user_id=?", u.ID).
@@ -60,27 +65,78 @@ func AddOrUpdateCollaborator(ctx context.Context, repo *repo_model.Repository, u
 			return err
 		}
 
+		changed = true
 		return access_model.RecalculateUserAccess(ctx, repo, u.ID)
+	}); err != nil {
+		return err
+	}
+
+	if changed {
+		if oldMode == perm.AccessModeNone {
+			audit.Record(ctx, audit_model.ActionCollaboratorAdd, nil, audit.RepoScope(repo), audit.UserTarget(u), nil, collaboratorState(mode))
+		} else {
+			audit.Record(ctx, audit_model.ActionCollaboratorUpdate, nil, audit.RepoScope(repo), audit.UserTarget(u), collaboratorState(oldMode), collaboratorState(mode))
+		}
+	}
+	return nil
+}
+
+func collaboratorState(mode perm.AccessMode) map[string]any {
+	return map[string]any{"access_mode": mode.ToString()}
+}
+
+// ChangeCollaborationAccessMode changes the access mode of an existing collaborator of the repository
+func ChangeCollaborationAccessMode(ctx context.Context, repo *repo_model.Repository, collaborator *user_model.User, mode perm.AccessMode) error {
+	collaboration, has, err := db.Get[repo_model.Collaboration](ctx, builder.Eq{
+		"repo_id": repo.ID,
+		"user_id": collaborator.ID,
 	})
+	if err != nil || !has {
+		return err
+	}
+	if err := repo_model.ChangeCollaborationAccessMode(ctx, repo, collaborator.ID, mode); err != nil {
+		return err
+	}
+	if mode > perm.AccessModeNone && mode <= perm.AccessModeOwner && mode != collaboration.Mode {
+		audit.Record(ctx, audit_model.ActionCollaboratorUpdate, nil, audit.RepoScope(repo), audit.UserTarget(collaborator), collaboratorState(collaboration.Mode), collaboratorState(mode))
+	}
+	return nil
 }
 
 // DeleteCollaboration removes collaboration relation between the user and repository.
 func DeleteCollaboration(ctx context.Context, repo *repo_model.Repository, collaborator *user_model.User) (err error) {
-	collaboration := &repo_model.Collaboration{
-		RepoID: repo.ID,
-		UserID: collaborator.ID,
+	collaboration, has, err := db.Get[repo_model.Collaboration](ctx, builder.Eq{
+		"repo_id": repo.ID,
+		"user_id": collaborator.ID,
+	})
+	if err != nil || !has {
+		return err
 	}
 
-	ctx, committer, err := db.TxContext(ctx)
+	txCtx, committer, err := db.TxContext(ctx)
 	if err != nil {
 		return err
 	}
 	defer committer.Close()
+	if err := deleteCollaboration(txCtx, repo, collaborator); err != nil {
+		return err
+	}
+	if err := committer.Commit(); err != nil {
+		return err
+	}
 
-	if has, err := db.GetEngine(ctx).Delete(collaboration); err != nil {
+	audit.Record(ctx, audit_model.ActionCollaboratorRemove, nil, audit.RepoScope(repo), audit.UserTarget(collaborator), collaboratorState(collaboration.Mode), nil)
+	return nil
+}
+
+func deleteCollaboration(ctx context.Context, repo *repo_model.Repository, collaborator *user_model.User) (err error) {
+	if has, err := db.GetEngine(ctx).Delete(&repo_model.Collaboration{
+		RepoID: repo.ID,
+		UserID: collaborator.ID,
+	}); err != nil {
 		return err
 	} else if has == 0 {
-		return committer.Commit()
+		return nil
 	}
 
 	if err := repo.LoadOwner(ctx); err != nil {
@@ -100,11 +156,7 @@ func DeleteCollaboration(ctx context.Context, repo *repo_model.Repository, colla
 	}
 
 	// Unassign a user from any issue (s)he has been assigned to in the repository
-	if err := ReconsiderRepoIssuesAssignee(ctx, repo, collaborator); err != nil {
-		return err
-	}
-
-	return committer.Commit()
+	return ReconsiderRepoIssuesAssignee(ctx, repo, collaborator)
 }
 
 func ReconsiderRepoIssuesAssignee(ctx context.Context, repo *repo_model.Repository, user *user_model.User) error {
@@ -0,0 +1,248 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/globallock"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// housekeepingQueue represents a queue of repository IDs to check and optimize
+var housekeepingQueue *queue.WorkerPoolQueue[int64]
+
+func handler(items ...int64) []int64 {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, repoID := range items {
+		repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+		if err != nil {
+			if !repo_model.IsErrRepoNotExist(err) {
+				log.Error("GetRepositoryByID[%d]: %v", repoID, err)
+			}
+			continue
+		}
+		if err := Run(ctx, repo); err != nil {
+			log.Error("Housekeeping of %-v failed: %v", repo, err)
+		}
+	}
+	return nil
+}
+
+// Init starts the housekeeping queue
+func Init(ctx context.Context) error {
+	if !setting.Repository.Housekeeping.Enabled {
+		return nil
+	}
+
+	housekeepingQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "repo_housekeeping", handler)
+	if housekeepingQueue == nil {
+		return errors.New("unable to create repo_housekeeping queue")
+	}
+	housekeepingQueue.SetWorkerMaxNumber(setting.Repository.Housekeeping.MaxConcurrency)
+	go graceful.GetManager().RunWithCancel(housekeepingQueue)
+	return nil
+}
+
+// AddRepoToQueue adds a repository to the housekeeping queue
+func AddRepoToQueue(ctx context.Context, repo *repo_model.Repository) error {
+	if housekeepingQueue == nil {
+		return nil
+	}
+	if err := housekeepingQueue.Push(repo.ID); err != nil {
+		if err == queue.ErrAlreadyInQueue {
+			log.Debug("Repo ID: %d already queued for housekeeping", repo.ID)
+			return nil
+		}
+		return err
+	}
+	return repo_model.SetRepoHousekeepingStatus(ctx, repo.ID, repo_model.HousekeepingStatusQueued)
+}
+
+// CountPush counts a push to a repository and queues the repository for housekeeping after enough pushes
+func CountPush(ctx context.Context, repo *repo_model.Repository) error {
+	if !setting.Repository.Housekeeping.Enabled {
+		return nil
+	}
+	pushes, err := repo_model.IncreaseRepoHousekeepingPushes(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	if setting.Repository.Housekeeping.PushesBeforeCheck > 0 && pushes >= setting.Repository.Housekeeping.PushesBeforeCheck {
+		return AddRepoToQueue(ctx, repo)
+	}
+	return nil
+}
+
+// QueueAllRepositories adds all non-empty repositories to the housekeeping queue
+func QueueAllRepositories(ctx context.Context) error {
+	if housekeepingQueue == nil {
+		return nil
+	}
+
+	log.Trace("Doing: QueueAllRepositories")
+	if err := db.Iterate(
+		ctx,
+		builder.Eq{"is_empty": false},
+		func(ctx context.Context, repo *repo_model.Repository) error {
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("before queuing housekeeping of %s", repo.FullName())
+			default:
+			}
+			return AddRepoToQueue(ctx, repo)
+		},
+	); err != nil {
+		return err
+	}
+	log.Trace("Finished: QueueAllRepositories")
+	return nil
+}
+
+func getRepoHousekeepingLockKey(repoID int64) string {
+	return fmt.Sprintf("repo_housekeeping_%d", repoID)
+}
+
+// Run checks a repository and runs the housekeeping tasks it needs.
+// The statistics and the result are stored in the housekeeping state of the repository.
+func Run(ctx context.Context, repo *repo_model.Repository) error {
+	ok, releaser, err := globallock.TryLock(ctx, getRepoHousekeepingLockKey(repo.ID))
+	if err != nil {
+		return err
+	} else if !ok {
+		log.Debug("Housekeeping of %-v is already running", repo)
+		return nil
+	}
+	defer releaser()
+
+	ctx, _, finished := process.GetManager().AddContext(ctx, fmt.Sprintf("Housekeeping: %s", repo.FullName()))
+	defer finished()
+
+	h, err := repo_model.GetRepoHousekeeping(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	if repo.IsEmpty || repo.IsBeingCreated() {
+		h.Status = repo_model.HousekeepingStatusIdle
+		return repo_model.SaveRepoHousekeeping(ctx, h, "status")
+	}
+
+	stats, err := git.GetObjectsStats(ctx, repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("GetObjectsStats: %w", err)
+	}
+
+	tasks := planTasks(h, stats, git.DefaultFeatures().SupportGeometricRepack, timeutil.TimeStampNow())
+	log.Trace("Housekeeping tasks for %-v: %v", repo, tasks)
+
+	pushesSinceCheck, pushesSinceFullRepack := h.PushesSinceCheck, h.PushesSinceFullRepack
+	h.Status = repo_model.HousekeepingStatusRunning
+	h.LastStartedUnix = timeutil.TimeStampNow()
+	if err := repo_model.SaveRepoHousekeeping(ctx, h, "status", "last_started_unix"); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(tasks))
+	var runErr error
+	for _, task := range tasks {
+		names = append(names, string(task))
+		if runErr = runTask(ctx, repo, task); runErr != nil {
+			runErr = fmt.Errorf("%s: %w", task, runErr)
+			break
+		}
+	}
+
+	if len(tasks) > 0 {
+		if newStats, err := git.GetObjectsStats(ctx, repo.RepoPath()); err != nil {
+			log.Error("GetObjectsStats for %-v: %v", repo, err)
+		} else {
+			stats = newStats
+		}
+		if err := repo_module.UpdateRepoSize(ctx, repo); err != nil {
+			log.Error("Updating size as part of housekeeping failed for %-v: %v", repo, err)
+		}
+	}
+
+	if runErr != nil {
+		if err := system_model.CreateRepositoryNotice("Housekeeping of repository %s failed: %v", repo.FullName(), runErr); err != nil {
+			log.Error("CreateRepositoryNotice: %v", err)
+		}
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		// pushes may have been counted while the tasks were running
+		current, err := repo_model.GetRepoHousekeeping(ctx, repo.ID)
+		if err != nil {
+			return err
+		}
+		h.PushesSinceCheck = max(current.PushesSinceCheck-pushesSinceCheck, 0)
+		h.PushesSinceFullRepack = current.PushesSinceFullRepack
+
+		h.LooseObjects = stats.LooseObjects
+		h.LooseObjectsSize = stats.LooseObjectsSize
+		h.Packs = stats.Packs
+		h.PacksSize = stats.PacksSize
+		h.LooseRefs = stats.LooseRefs
+		h.HasCommitGraph = stats.HasCommitGraph
+		h.HasMultiPackIndex = stats.HasMultiPackIndex
+		h.HasBitmap = stats.HasBitmap
+
+		h.LastTasks = strings.Join(names, ",")
+		h.LastFinishedUnix = timeutil.TimeStampNow()
+		if runErr != nil {
+			h.Status = repo_model.HousekeepingStatusFailed
+			h.LastError = runErr.Error()
+		} else {
+			h.Status = repo_model.HousekeepingStatusIdle
+			h.LastError = ""
+			if slices.Contains(tasks, TaskFullRepack) {
+				h.LastFullRepackUnix = h.LastFinishedUnix
+				h.PushesSinceFullRepack = max(current.PushesSinceFullRepack-pushesSinceFullRepack, 0)
+			}
+		}
+		return repo_model.SaveRepoHousekeeping(ctx, h)
+	}); err != nil {
+		return err
+	}
+	return runErr
+}
+
+func runTask(ctx context.Context, repo *repo_model.Repository, task Task) error {
+	cfg := setting.Repository.Housekeeping
+	timeout := time.Duration(setting.Git.Timeout.GC) * time.Second
+	repoPath := repo.RepoPath()
+
+	switch task {
+	case TaskIncrementalRepack:
+		return git.Repack(ctx, repoPath, git.RepackOptions{Timeout: timeout})
+	case TaskGeometricRepack:
+		return git.Repack(ctx, repoPath, git.RepackOptions{Geometric: cfg.GeometricFactor, Timeout: timeout})
+	case TaskFullRepack:
+		return git.Repack(ctx, repoPath, git.RepackOptions{All: true, UnreachableExpiration: cfg.UnreachableExpiration, Timeout: timeout})
+	case TaskWriteCommitGraph:
+		return git.WriteSplitCommitGraph(ctx, repoPath, timeout)
+	case TaskWriteMultiPackIndex:
+		return git.WriteMultiPackIndex(ctx, repoPath, timeout)
+	case TaskPackRefs:
+		return git.PackRefs(ctx, repoPath, timeout)
+	}
+	return fmt.Errorf("unknown housekeeping task %q", task)
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package housekeeping
+
+import (
+	"slices"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// Task is a housekeeping task which optimizes a repository
+type Task string
+
+const (
+	// TaskIncrementalRepack packs the loose objects into a new pack
+	TaskIncrementalRepack Task = "incremental-repack"
+	// TaskGeometricRepack merges the small packs so that the pack sizes form a geometric progression
+	TaskGeometricRepack Task = "geometric-repack"
+	// TaskFullRepack packs all reachable objects into a single pack and the unreachable objects into a cruft pack
+	TaskFullRepack Task = "full-repack"
+	// TaskWriteCommitGraph writes an incremental commit-graph
+	TaskWriteCommitGraph Task = "write-commit-graph"
+	// TaskWriteMultiPackIndex writes a multi-pack-index with bitmap for all packs
+	TaskWriteMultiPackIndex Task = "write-multi-pack-index"
+	// TaskPackRefs moves the loose references into the packed-refs file
+	TaskPackRefs Task = "pack-refs"
+)
+
+// planTasks decides which tasks are needed to optimize a repository, based on its object statistics
+// and the pushes since the last housekeeping. The repack tasks are mutually exclusive.
+func planTasks(h *repo_model.RepoHousekeeping, stats *git.ObjectsStats, supportGeometricRepack bool, now timeutil.TimeStamp) []Task {
+	cfg := setting.Repository.Housekeeping
+
+	var tasks []Task
+
+	changedSinceFullRepack := h.PushesSinceFullRepack > 0 || stats.LooseObjects > 0 || stats.Packs > 1
+	fullRepackDue := h.LastFullRepackUnix == 0 || now.AsTime().Sub(h.LastFullRepackUnix.AsTime()) >= cfg.FullRepackInterval
+	switch {
+	case changedSinceFullRepack && fullRepackDue:
+		tasks = append(tasks, TaskFullRepack)
+	case stats.Packs > cfg.MaxPacks:
+		if supportGeometricRepack {
+			tasks = append(tasks, TaskGeometricRepack)
+		} else {
+			tasks = append(tasks, TaskFullRepack)
+		}
+	case stats.LooseObjects > cfg.MaxLooseObjects:
+		tasks = append(tasks, TaskIncrementalRepack)
+	}
+
+	// the geometric repack writes the multi-pack-index itself, the full repack leaves at most a cruft pack besides the main pack
+	if supportGeometricRepack && !slices.Contains(tasks, TaskGeometricRepack) && !slices.Contains(tasks, TaskFullRepack) {
+		if slices.Contains(tasks, TaskIncrementalRepack) || (stats.Packs > 1 && !stats.HasMultiPackIndex) {
+			tasks = append(tasks, TaskWriteMultiPackIndex)
+		}
+	}
+
+	if len(tasks) > 0 || h.PushesSinceCheck > 0 || !stats.HasCommitGraph {
+		tasks = append(tasks, TaskWriteCommitGraph)
+	}
+
+	if stats.LooseRefs > cfg.MaxLooseRefs {
+		tasks = append(tasks, TaskPackRefs)
+	}
+
+	return tasks
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package housekeeping
+
+import (
+	"testing"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanTasks(t *testing.T) {
+	defer test.MockVariableValue(&setting.Repository.Housekeeping.MaxLooseObjects, int64(100))()
+	defer test.MockVariableValue(&setting.Repository.Housekeeping.MaxPacks, int64(4))()
+	defer test.MockVariableValue(&setting.Repository.Housekeeping.MaxLooseRefs, int64(10))()
+	defer test.MockVariableValue(&setting.Repository.Housekeeping.FullRepackInterval, 24*time.Hour)()
+
+	now := timeutil.TimeStamp(1700000000)
+	recentFullRepack := now.Add(-3600)
+	oldFullRepack := now.Add(-2 * 86400)
+
+	optimized := git.ObjectsStats{Packs: 1, HasCommitGraph: true, HasBitmap: true}
+
+	cases := []struct {
+		name      string
+		h         repo_model.RepoHousekeeping
+		stats     git.ObjectsStats
+		geometric bool
+		expected  []Task
+	}{
+		{
+			name:  "optimized",
+			h:     repo_model.RepoHousekeeping{LastFullRepackUnix: recentFullRepack},
+			stats: optimized,
+		},
+		{
+			name:     "missing commit-graph",
+			h:        repo_model.RepoHousekeeping{LastFullRepackUnix: recentFullRepack},
+			stats:    git.ObjectsStats{Packs: 1},
+			expected: []Task{TaskWriteCommitGraph},
+		},
+		{
+			name:      "never fully repacked",
+			h:         repo_model.RepoHousekeeping{PushesSinceCheck: 1, PushesSinceFullRepack: 1},
+			stats:     git.ObjectsStats{LooseObjects: 10, Packs: 2},
+			geometric: true,
+			expected:  []Task{TaskFullRepack, TaskWriteCommitGraph},
+		},
+		{
+			name:     "full repack due but unchanged",
+			h:        repo_model.RepoHousekeeping{LastFullRepackUnix: oldFullRepack},
+			stats:    optimized,
+			expected: nil,
+		},
+		{
+			name:      "full repack due",
+			h:         repo_model.RepoHousekeeping{PushesSinceFullRepack: 3, LastFullRepackUnix: oldFullRepack},
+			stats:     optimized,
+			geometric: true,
+			expected:  []Task{TaskFullRepack, TaskWriteCommitGraph},
+		},
+		{
+			name:      "too many packs",
+			h:         repo_model.RepoHousekeeping{PushesSinceCheck: 5, LastFullRepackUnix: recentFullRepack},
+			stats:     git.ObjectsStats{Packs: 5, HasCommitGraph: true},
+			geometric: true,
+			expected:  []Task{TaskGeometricRepack, TaskWriteCommitGraph},
+		},
+		{
+			name:     "too many packs without geometric repack",
+			h:        repo_model.RepoHousekeeping{PushesSinceCheck: 5, LastFullRepackUnix: recentFullRepack},
+			stats:    git.ObjectsStats{Packs: 5, HasCommitGraph: true},
+			expected: []Task{TaskFullRepack, TaskWriteCommitGraph},
+		},
+		{
+			name:      "too many loose objects",
+			h:         repo_model.RepoHousekeeping{PushesSinceCheck: 1, LastFullRepackUnix: recentFullRepack},
+			stats:     git.ObjectsStats{LooseObjects: 101, Packs: 1, HasCommitGraph: true},
+			geometric: true,
+			expected:  []Task{TaskIncrementalRepack, TaskWriteMultiPackIndex, TaskWriteCommitGraph},
+		},
+		{
+			name:     "too many loose objects without geometric repack",
+			h:        repo_model.RepoHousekeeping{PushesSinceCheck: 1, LastFullRepackUnix: recentFullRepack},
+			stats:    git.ObjectsStats{LooseObjects: 101, Packs: 1, HasCommitGraph: true},
+			expected: []Task{TaskIncrementalRepack, TaskWriteCommitGraph},
+		},
+		{
+			name:      "missing multi-pack-index",
+			h:         repo_model.RepoHousekeeping{LastFullRepackUnix: recentFullRepack},
+			stats:     git.ObjectsStats{Packs: 3, HasCommitGraph: true},
+			geometric: true,
+			expected:  []Task{TaskWriteMultiPackIndex, TaskWriteCommitGraph},
+		},
+		{
+			name:     "pushes only",
+			h:        repo_model.RepoHousekeeping{PushesSinceCheck: 2, LastFullRepackUnix: recentFullRepack},
+			stats:    optimized,
+			expected: []Task{TaskWriteCommitGraph},
+		},
+		{
+			name:     "too many loose refs",
+			h:        repo_model.RepoHousekeeping{LastFullRepackUnix: recentFullRepack},
+			stats:    git.ObjectsStats{Packs: 1, HasCommitGraph: true, LooseRefs: 11},
+			expected: []Task{TaskPackRefs},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, planTasks(&c.h, &c.stats, c.geometric, now))
+		})
+	}
+}
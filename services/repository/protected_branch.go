@@ -0,0 +1,57 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repository
+
+import (
+	"context"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/services/audit"
+)
+
+// UpdateProtectBranch creates or updates the branch protection rule and records the change in the audit log
+func UpdateProtectBranch(ctx context.Context, repo *repo_model.Repository, protectBranch *git_model.ProtectedBranch, opts git_model.WhitelistOptions) error {
+	var before *git_model.ProtectedBranch
+	if protectBranch.ID != 0 {
+		var err error
+		if before, err = git_model.GetProtectedBranchRuleByID(ctx, repo.ID, protectBranch.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := git_model.UpdateProtectBranch(ctx, repo, protectBranch, opts); err != nil {
+		return err
+	}
+
+	target := protectedBranchTarget(protectBranch)
+	if before == nil {
+		audit.Record(ctx, audit_model.ActionBranchProtectionCreate, nil, audit.RepoScope(repo), target, nil, protectedBranchState(protectBranch))
+	} else {
+		audit.Record(ctx, audit_model.ActionBranchProtectionUpdate, nil, audit.RepoScope(repo), target, protectedBranchState(before), protectedBranchState(protectBranch))
+	}
+	return nil
+}
+
+// DeleteProtectedBranch deletes the branch protection rule and records the deletion in the audit log
+func DeleteProtectedBranch(ctx context.Context, repo *repo_model.Repository, rule *git_model.ProtectedBranch) error {
+	if err := git_model.DeleteProtectedBranch(ctx, repo, rule.ID); err != nil {
+		return err
+	}
+	audit.Record(ctx, audit_model.ActionBranchProtectionDelete, nil, audit.RepoScope(repo), protectedBranchTarget(rule), protectedBranchState(rule), nil)
+	return nil
+}
+
+func protectedBranchTarget(rule *git_model.ProtectedBranch) audit.Target {
+	return audit.Target{Type: audit_model.TargetBranchProtection, ID: rule.ID, Name: rule.RuleName}
+}
+
+// protectedBranchState returns the options of the rule without the loaded repository and the timestamps
+func protectedBranchState(rule *git_model.ProtectedBranch) *git_model.ProtectedBranch {
+	state := *rule
+	state.Repo = nil
+	state.CreatedUnix, state.UpdatedUnix = 0, 0
+	return &state
+}
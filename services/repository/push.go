@@ -26,6 +26,7 @@ import (
 	issue_service "code.gitea.io/gitea/services/issue"
 	notify_service "code.gitea.io/gitea/services/notify"
 	pull_service "code.gitea.io/gitea/services/pull"
+	"code.gitea.io/gitea/services/repository/housekeeping"
 )
 
 // pushQueue represents a queue to handle update pull request tests
@@ -96,6 +97,10 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 		return fmt.Errorf("Failed to update size for repository: %v", err)
 	}
 
+	if err := housekeeping.CountPush(ctx, repo); err != nil {
+		log.Error("Failed to count push for housekeeping of %-v: %v", repo, err)
+	}
+
 	addTags := make([]string, 0, len(optsList))
 	delTags := make([]string, 0, len(optsList))
 	var pusher *user_model.User
@@ -8,12 +8,14 @@ import (
 	"errors"
 	"fmt"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/audit"
 )
 
 // TeamAddRepository adds new repository to team of organization.
@@ -24,9 +26,13 @@ func TeamAddRepository(ctx context.Context, t *organization.Team, repo *repo_mod
 		return nil
 	}
 
-	return db.WithTx(ctx, func(ctx context.Context) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
 		return addRepositoryToTeam(ctx, t, repo)
-	})
+	}); err != nil {
+		return err
+	}
+	audit.Record(ctx, audit_model.ActionTeamRepoAdd, nil, audit.RepoScope(repo), audit.TeamTarget(t), nil, map[string]any{"access_mode": t.AccessMode.ToString()})
+	return nil
 }
 
 func addRepositoryToTeam(ctx context.Context, t *organization.Team, repo *repo_model.Repository) (err error) {
@@ -160,17 +166,13 @@ func RemoveRepositoryFromTeam(ctx context.Context, t *organization.Team, repoID
 		return err
 	}
 
-	ctx, committer, err := db.TxContext(ctx)
-	if err != nil {
-		return err
-	}
-	defer committer.Close()
-
-	if err = removeRepositoryFromTeam(ctx, t, repo, true); err != nil {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		return removeRepositoryFromTeam(ctx, t, repo, true)
+	}); err != nil {
 		return err
 	}
-
-	return committer.Commit()
+	audit.Record(ctx, audit_model.ActionTeamRepoRemove, nil, audit.RepoScope(repo), audit.TeamTarget(t), map[string]any{"access_mode": t.AccessMode.ToString()}, nil)
+	return nil
 }
 
 // removeRepositoryFromTeam removes a repository from a team and recalculates access
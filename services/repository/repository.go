@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
@@ -22,6 +23,7 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/audit"
 	notify_service "code.gitea.io/gitea/services/notify"
 	pull_service "code.gitea.io/gitea/services/pull"
 )
@@ -66,6 +68,7 @@ func DeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_mod
 	if err := DeleteRepositoryDirectly(ctx, doer, repo.ID); err != nil {
 		return err
 	}
+	audit.Record(ctx, audit_model.ActionRepoDelete, doer, audit.RepoScope(repo), audit.RepoTarget(repo), repoVisibilityState(repo.IsPrivate), nil)
 
 	return packages_model.UnlinkRepositoryFromAllPackages(ctx, repo.ID)
 }
@@ -115,35 +118,53 @@ func Init(ctx context.Context) error {
 }
 
 // UpdateRepository updates a repository
-func UpdateRepository(ctx context.Context, repo *repo_model.Repository, visibilityChanged bool) (err error) {
-	ctx, committer, err := db.TxContext(ctx)
-	if err != nil {
-		return err
+func UpdateRepository(ctx context.Context, repo *repo_model.Repository, visibilityChanged bool) error {
+	wasPrivate := repo.IsPrivate
+	if visibilityChanged {
+		// the visibility of the repository may be unchanged if the visibility of its owner is changed
+		old, err := repo_model.GetRepositoryByID(ctx, repo.ID)
+		if err != nil {
+			return err
+		}
+		wasPrivate = old.IsPrivate
 	}
-	defer committer.Close()
 
-	if err = repo_module.UpdateRepository(ctx, repo, visibilityChanged); err != nil {
-		return fmt.Errorf("updateRepository: %w", err)
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := repo_module.UpdateRepository(ctx, repo, visibilityChanged); err != nil {
+			return fmt.Errorf("updateRepository: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
 	}
 
-	return committer.Commit()
+	if wasPrivate != repo.IsPrivate {
+		audit.Record(ctx, audit_model.ActionRepoVisibility, nil, audit.RepoScope(repo), audit.RepoTarget(repo), repoVisibilityState(wasPrivate), repoVisibilityState(repo.IsPrivate))
+	}
+	return nil
 }
 
-func UpdateRepositoryVisibility(ctx context.Context, repo *repo_model.Repository, isPrivate bool) (err error) {
-	ctx, committer, err := db.TxContext(ctx)
-	if err != nil {
+func UpdateRepositoryVisibility(ctx context.Context, repo *repo_model.Repository, isPrivate bool) error {
+	wasPrivate := repo.IsPrivate
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		repo.IsPrivate = isPrivate
+
+		if err := repo_module.UpdateRepository(ctx, repo, true); err != nil {
+			return fmt.Errorf("UpdateRepositoryVisibility: %w", err)
+		}
+		return nil
+	}); err != nil {
 		return err
 	}
 
-	defer committer.Close()
-
-	repo.IsPrivate = isPrivate
-
-	if err = repo_module.UpdateRepository(ctx, repo, true); err != nil {
-		return fmt.Errorf("UpdateRepositoryVisibility: %w", err)
+	if wasPrivate != isPrivate {
+		audit.Record(ctx, audit_model.ActionRepoVisibility, nil, audit.RepoScope(repo), audit.RepoTarget(repo), repoVisibilityState(wasPrivate), repoVisibilityState(isPrivate))
 	}
+	return nil
+}
 
-	return committer.Commit()
+func repoVisibilityState(isPrivate bool) map[string]any {
+	return map[string]any{"private": isPrivate}
 }
 
 func MakeRepoPublic(ctx context.Context, repo *repo_model.Repository) (err error) {
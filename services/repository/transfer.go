@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
@@ -21,6 +23,8 @@ import (
 	"code.gitea.io/gitea/modules/globallock"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/util"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/audit"
 	notify_service "code.gitea.io/gitea/services/notify"
 )
 
@@ -65,10 +69,17 @@ func TransferOwnership(ctx context.Context, doer, newOwner *user_model.User, rep
 	}
 
 	notify_service.TransferRepository(ctx, doer, repo, oldOwner.Name)
+	audit.Record(ctx, audit_model.ActionRepoTransfer, doer, audit.Scope{OwnerID: oldOwner.ID, RepoID: repo.ID},
+		audit.Target{Type: audit_model.TargetRepository, ID: repo.ID, Name: oldOwner.Name + "/" + repo.Name},
+		repoOwnerState(oldOwner.Name), repoOwnerState(newOwner.Name))
 
 	return nil
 }
 
+func repoOwnerState(ownerName string) map[string]any {
+	return map[string]any{"owner": ownerName}
+}
+
 // transferOwnership transfers all corresponding repository items from old user to new one.
 func transferOwnership(ctx context.Context, doer *user_model.User, newOwnerName string, repo *repo_model.Repository) (err error) {
 	repoRenamed := false
@@ -302,6 +313,22 @@ func transferOwnership(ctx context.Context, doer *user_model.User, newOwnerName
 		return fmt.Errorf("repo_model.NewRedirect: %w", err)
 	}
 
+	// The workflows of this repo are no longer required for the other repos of the old owner
+	requiredWorkflows, err := db.Find[actions_model.ActionRequiredWorkflow](ctx, actions_model.FindRequiredWorkflowsOptions{RepoID: repo.ID})
+	if err != nil {
+		return fmt.Errorf("find required workflows of repo %v: %w", repo.ID, err)
+	}
+	for _, rw := range requiredWorkflows {
+		if err := actions_service.DeleteRequiredWorkflow(ctx, rw); err != nil {
+			return fmt.Errorf("delete required workflow %v: %w", rw.ID, err)
+		}
+	}
+
+	// The status checks required by the old owner are replaced by the ones required by the new owner
+	if err := actions_service.SyncRequiredStatusChecksOfMovedRepo(ctx, repo, oldOwner.ID, repo.Name); err != nil {
+		return fmt.Errorf("SyncRequiredStatusChecksOfMovedRepo: %w", err)
+	}
+
 	return committer.Commit()
 }
 
@@ -380,6 +407,9 @@ func ChangeRepositoryName(ctx context.Context, doer *user_model.User, repo *repo
 	releaser()
 
 	repo.Name = newRepoName
+	if err := actions_service.SyncRequiredStatusChecksOfMovedRepo(ctx, repo, repo.OwnerID, oldRepoName); err != nil {
+		return fmt.Errorf("SyncRequiredStatusChecksOfMovedRepo: %w", err)
+	}
 	notify_service.RenameRepository(ctx, doer, repo, oldRepoName)
 
 	return nil
@@ -431,6 +461,7 @@ func StartRepositoryTransfer(ctx context.Context, doer, newOwner *user_model.Use
 
 	// notify users who are able to accept / reject transfer
 	notify_service.RepoPendingTransfer(ctx, doer, newOwner, repo)
+	audit.Record(ctx, audit_model.ActionRepoTransferStart, doer, audit.RepoScope(repo), audit.RepoTarget(repo), repoOwnerState(repo.OwnerName), repoOwnerState(newOwner.Name))
 
 	return nil
 }
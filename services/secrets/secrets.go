@@ -6,8 +6,13 @@ package secrets
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
 	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/services/audit"
 )
 
 func CreateOrUpdateSecret(ctx context.Context, ownerID, repoID int64, name, data string) (*secret_model.Secret, bool, error) {
@@ -29,12 +34,14 @@ func CreateOrUpdateSecret(ctx context.Context, ownerID, repoID int64, name, data
 		if err != nil {
 			return nil, false, err
 		}
+		recordSecretEvent(ctx, audit_model.ActionSecretCreate, s)
 		return s, true, nil
 	}
 
 	if err := secret_model.UpdateSecret(ctx, s[0].ID, data); err != nil {
 		return nil, false, err
 	}
+	recordSecretEvent(ctx, audit_model.ActionSecretUpdate, s[0])
 
 	return s[0], false, nil
 }
@@ -79,5 +86,87 @@ func deleteSecret(ctx context.Context, s *secret_model.Secret) error {
 	if _, err := db.DeleteByID[secret_model.Secret](ctx, s.ID); err != nil {
 		return err
 	}
+	recordSecretEvent(ctx, audit_model.ActionSecretDelete, s)
 	return nil
 }
+
+// recordSecretEvent records the change of the secret in the audit log, the values of the secrets are never recorded
+func recordSecretEvent(ctx context.Context, action audit_model.Action, s *secret_model.Secret) {
+	scope := audit.OwnerScope(s.OwnerID)
+	if s.RepoID != 0 {
+		repo, err := repo_model.GetRepositoryByID(ctx, s.RepoID)
+		if err != nil {
+			log.Error("GetRepositoryByID: %v", err)
+			scope = audit.Scope{RepoID: s.RepoID}
+		} else {
+			scope = audit.RepoScope(repo)
+		}
+	}
+	target := audit.Target{Type: audit_model.TargetSecret, ID: s.ID, Name: s.Name}
+
+	var before, after any
+	state := map[string]any{"name": s.Name, "environment_id": s.EnvironmentID}
+	switch action {
+	case audit_model.ActionSecretCreate:
+		after = state
+	case audit_model.ActionSecretDelete:
+		before = state
+	}
+	audit.Record(ctx, action, nil, scope, target, before, after)
+}
+
+// CreateOrUpdateEnvironmentSecret creates or updates a secret of the environment
+func CreateOrUpdateEnvironmentSecret(ctx context.Context, env *actions_model.ActionEnvironment, name, data string) (*secret_model.Secret, bool, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, false, err
+	}
+
+	s, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{
+		RepoID:        env.RepoID,
+		EnvironmentID: env.ID,
+		Name:          name,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(s) == 0 {
+		s, err := secret_model.InsertEncryptedEnvironmentSecret(ctx, env, name, data)
+		if err != nil {
+			return nil, false, err
+		}
+		recordSecretEvent(ctx, audit_model.ActionSecretCreate, s)
+		return s, true, nil
+	}
+
+	if err := secret_model.UpdateSecret(ctx, s[0].ID, data); err != nil {
+		return nil, false, err
+	}
+	recordSecretEvent(ctx, audit_model.ActionSecretUpdate, s[0])
+
+	return s[0], false, nil
+}
+
+// DeleteEnvironmentSecret deletes a secret of the environment by id or name, either of them should be set
+func DeleteEnvironmentSecret(ctx context.Context, env *actions_model.ActionEnvironment, secretID int64, name string) error {
+	if secretID == 0 {
+		if err := ValidateName(name); err != nil {
+			return err
+		}
+	}
+
+	s, err := db.Find[secret_model.Secret](ctx, secret_model.FindSecretsOptions{
+		RepoID:        env.RepoID,
+		EnvironmentID: env.ID,
+		SecretID:      secretID,
+		Name:          name,
+	})
+	if err != nil {
+		return err
+	}
+	if len(s) != 1 {
+		return secret_model.ErrSecretNotFound{Name: name}
+	}
+
+	return deleteSecret(ctx, s[0])
+}
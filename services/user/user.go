@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	audit_model "code.gitea.io/gitea/models/audit"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
@@ -24,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/services/agit"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/audit"
 	org_service "code.gitea.io/gitea/services/org"
 	"code.gitea.io/gitea/services/packages"
 	container_service "code.gitea.io/gitea/services/packages/container"
@@ -210,7 +212,7 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 		}
 	}
 
-	ctx, committer, err := db.TxContext(ctx)
+	txCtx, committer, err := db.TxContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -221,7 +223,7 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 	//  however consistency requires that we ensure that this is the case
 
 	// Check ownership of repository.
-	count, err := repo_model.CountRepositories(ctx, repo_model.CountRepositoryOptions{OwnerID: u.ID})
+	count, err := repo_model.CountRepositories(txCtx, repo_model.CountRepositoryOptions{OwnerID: u.ID})
 	if err != nil {
 		return fmt.Errorf("GetRepositoryCount: %w", err)
 	} else if count > 0 {
@@ -229,7 +231,7 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 	}
 
 	// Check membership of organization.
-	count, err = organization.GetOrganizationCount(ctx, u)
+	count, err = organization.GetOrganizationCount(txCtx, u)
 	if err != nil {
 		return fmt.Errorf("GetOrganizationCount: %w", err)
 	} else if count > 0 {
@@ -237,13 +239,13 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 	}
 
 	// Check ownership of packages.
-	if ownsPackages, err := packages_model.HasOwnerPackages(ctx, u.ID); err != nil {
+	if ownsPackages, err := packages_model.HasOwnerPackages(txCtx, u.ID); err != nil {
 		return fmt.Errorf("HasOwnerPackages: %w", err)
 	} else if ownsPackages {
 		return models.ErrUserOwnPackages{UID: u.ID}
 	}
 
-	if err := deleteUser(ctx, u, purge); err != nil {
+	if err := deleteUser(txCtx, u, purge); err != nil {
 		return fmt.Errorf("DeleteUser: %w", err)
 	}
 
@@ -252,6 +254,8 @@ func DeleteUser(ctx context.Context, u *user_model.User, purge bool) error {
 	}
 	_ = committer.Close()
 
+	audit.Record(ctx, audit_model.ActionUserDelete, nil, audit.OwnerScope(u.ID), audit.UserTarget(u), audit.UserState(u), map[string]any{"purge": purge})
+
 	if err = asymkey_service.RewriteAllPublicKeys(ctx); err != nil {
 		return err
 	}
@@ -227,6 +227,33 @@ func TestDiscordPayload(t *testing.T) {
 		assert.Equal(t, p.Sender.AvatarURL, pl.Embeds[0].Author.IconURL)
 	})
 
+	t.Run("WorkflowRun", func(t *testing.T) {
+		p := workflowRunTestPayload()
+
+		pl, err := dc.WorkflowRun(p)
+		require.NoError(t, err)
+
+		assert.Len(t, pl.Embeds, 1)
+		assert.Equal(t, "[test/repo] Workflow run test.yaml #3 completed: success", pl.Embeds[0].Title)
+		assert.Equal(t, "Fix bug", pl.Embeds[0].Description)
+		assert.Equal(t, "http://localhost:3000/test/repo/actions/runs/3", pl.Embeds[0].URL)
+		assert.Equal(t, greenColor, pl.Embeds[0].Color)
+		assert.Equal(t, p.Sender.UserName, pl.Embeds[0].Author.Name)
+	})
+
+	t.Run("WorkflowJob", func(t *testing.T) {
+		p := workflowJobTestPayload()
+
+		pl, err := dc.WorkflowJob(p)
+		require.NoError(t, err)
+
+		assert.Len(t, pl.Embeds, 1)
+		assert.Equal(t, "[test/repo] Workflow job build completed: failure", pl.Embeds[0].Title)
+		assert.Empty(t, pl.Embeds[0].Description)
+		assert.Equal(t, "http://localhost:3000/test/repo/actions/runs/3/jobs/0", pl.Embeds[0].URL)
+		assert.Equal(t, redColor, pl.Embeds[0].Color)
+	})
+
 	t.Run("Wiki", func(t *testing.T) {
 		p := wikiTestPayload()
 
@@ -341,6 +341,57 @@ func packageTestPayload() *api.PackagePayload {
 	}
 }
 
+func workflowRunTestPayload() *api.WorkflowRunPayload {
+	return &api.WorkflowRunPayload{
+		Action: api.HookWorkflowRunCompleted,
+		Repo: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+		WorkflowRun: &api.ActionWorkflowRun{
+			ID:           1,
+			Name:         "test.yaml",
+			DisplayTitle: "Fix bug",
+			RunNumber:    3,
+			Event:        "push",
+			Status:       "completed",
+			Conclusion:   "success",
+			WorkflowID:   "test.yaml",
+			HeadBranch:   "main",
+			HTMLURL:      "http://localhost:3000/test/repo/actions/runs/3",
+		},
+	}
+}
+
+func workflowJobTestPayload() *api.WorkflowJobPayload {
+	return &api.WorkflowJobPayload{
+		Action: api.HookWorkflowJobCompleted,
+		Repo: &api.Repository{
+			HTMLURL:  "http://localhost:3000/test/repo",
+			Name:     "repo",
+			FullName: "test/repo",
+		},
+		Sender: &api.User{
+			UserName:  "user1",
+			AvatarURL: "http://localhost:3000/user1/avatar",
+		},
+		WorkflowJob: &api.ActionWorkflowJob{
+			ID:         1,
+			RunID:      1,
+			RunURL:     "http://localhost:3000/test/repo/actions/runs/3",
+			Name:       "build",
+			Status:     "completed",
+			Conclusion: "failure",
+			HTMLURL:    "http://localhost:3000/test/repo/actions/runs/3/jobs/0",
+		},
+	}
+}
+
 func TestGetIssuesPayloadInfo(t *testing.T) {
 	p := issueTestPayload()
 
@@ -647,3 +698,97 @@ func TestGetIssueCommentPayloadInfo(t *testing.T) {
 		assert.Equal(t, c.color, color, "case %d", i)
 	}
 }
+
+func TestGetWorkflowRunPayloadInfo(t *testing.T) {
+	p := workflowRunTestPayload()
+
+	cases := []struct {
+		action     api.HookWorkflowRunAction
+		conclusion string
+		text       string
+		color      int
+	}{
+		{
+			api.HookWorkflowRunRequested,
+			"",
+			"[test/repo] Workflow run test.yaml #3 requested by user1",
+			yellowColor,
+		},
+		{
+			api.HookWorkflowRunInProgress,
+			"",
+			"[test/repo] Workflow run test.yaml #3 started by user1",
+			yellowColor,
+		},
+		{
+			api.HookWorkflowRunCompleted,
+			"success",
+			"[test/repo] Workflow run test.yaml #3 completed: success by user1",
+			greenColor,
+		},
+		{
+			api.HookWorkflowRunCompleted,
+			"failure",
+			"[test/repo] Workflow run test.yaml #3 completed: failure by user1",
+			redColor,
+		},
+		{
+			api.HookWorkflowRunCompleted,
+			"cancelled",
+			"[test/repo] Workflow run test.yaml #3 completed: cancelled by user1",
+			greyColor,
+		},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		p.WorkflowRun.Conclusion = c.conclusion
+		text, color := getWorkflowRunPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
+
+func TestGetWorkflowJobPayloadInfo(t *testing.T) {
+	p := workflowJobTestPayload()
+
+	cases := []struct {
+		action     api.HookWorkflowJobAction
+		conclusion string
+		text       string
+		color      int
+	}{
+		{
+			api.HookWorkflowJobQueued,
+			"",
+			"[test/repo] Workflow job build queued by user1",
+			yellowColor,
+		},
+		{
+			api.HookWorkflowJobWaiting,
+			"",
+			"[test/repo] Workflow job build waiting by user1",
+			greyColor,
+		},
+		{
+			api.HookWorkflowJobInProgress,
+			"",
+			"[test/repo] Workflow job build started by user1",
+			yellowColor,
+		},
+		{
+			api.HookWorkflowJobCompleted,
+			"skipped",
+			"[test/repo] Workflow job build completed: skipped by user1",
+			greyColor,
+		},
+	}
+
+	for i, c := range cases {
+		p.Action = c.action
+		p.WorkflowJob.Conclusion = c.conclusion
+		text, color := getWorkflowJobPayloadInfo(p, noneLinkFormatter, true)
+		assert.Equal(t, c.text, text, "case %d", i)
+		assert.Equal(t, c.color, color, "case %d", i)
+	}
+}
@@ -6,6 +6,7 @@ package webhook
 import (
 	"context"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	packages_model "code.gitea.io/gitea/models/packages"
@@ -928,3 +929,58 @@ func notifyPackage(ctx context.Context, sender *user_model.User, pd *packages_mo
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
+
+func (m *webhookNotifier) WorkflowRunStatusUpdate(ctx context.Context, repo *repo_model.Repository, sender *user_model.User, run *actions_model.ActionRun) {
+	apiRun, err := convert.ToActionWorkflowRun(ctx, run)
+	if err != nil {
+		log.Error("ToActionWorkflowRun: %v", err)
+		return
+	}
+
+	action := api.HookWorkflowRunRequested
+	if run.Status.IsRunning() {
+		action = api.HookWorkflowRunInProgress
+	} else if run.Status.IsDone() {
+		action = api.HookWorkflowRunCompleted
+	}
+
+	if err := PrepareWebhooks(ctx, EventSource{Repository: repo}, webhook_module.HookEventWorkflowRun, &api.WorkflowRunPayload{
+		Action:      action,
+		WorkflowRun: apiRun,
+		Repo:        convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeOwner}),
+		Sender:      convert.ToUser(ctx, sender, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
+
+func (m *webhookNotifier) WorkflowJobStatusUpdate(ctx context.Context, repo *repo_model.Repository, sender *user_model.User, job *actions_model.ActionRunJob, task *actions_model.ActionTask) {
+	apiJob, err := convert.ToActionWorkflowJob(ctx, job, task)
+	if err != nil {
+		log.Error("ToActionWorkflowJob: %v", err)
+		return
+	}
+
+	var action api.HookWorkflowJobAction
+	switch {
+	case job.Status.IsWaiting():
+		action = api.HookWorkflowJobQueued
+	case job.Status.IsBlocked():
+		action = api.HookWorkflowJobWaiting
+	case job.Status.IsRunning():
+		action = api.HookWorkflowJobInProgress
+	case job.Status.IsDone():
+		action = api.HookWorkflowJobCompleted
+	default:
+		return
+	}
+
+	if err := PrepareWebhooks(ctx, EventSource{Repository: repo}, webhook_module.HookEventWorkflowJob, &api.WorkflowJobPayload{
+		Action:      action,
+		WorkflowJob: apiJob,
+		Repo:        convert.ToRepo(ctx, repo, access_model.Permission{AccessMode: perm.AccessModeOwner}),
+		Sender:      convert.ToUser(ctx, sender, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks: %v", err)
+	}
+}
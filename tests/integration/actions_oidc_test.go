@@ -0,0 +1,211 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcWorkflow = `name: deploy
+on: push
+permissions:
+  contents: read
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+  deploy:
+    runs-on: ubuntu-latest
+    environment: production
+    permissions:
+      id-token: write
+    steps:
+      - run: echo deploy
+`
+
+func TestActionsOIDC(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:          "actions-oidc",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil))
+		_, err = createFileInBranch(user2, repo, ".gitea/workflows/deploy.yml", "main", oidcWorkflow)
+		require.NoError(t, err)
+
+		getJob := func(t *testing.T, jobID string) *actions_model.ActionRunJob {
+			var job *actions_model.ActionRunJob
+			assert.Eventually(t, func() bool {
+				jobs, err := db.Find[actions_model.ActionRunJob](db.DefaultContext, actions_model.FindRunJobOptions{RepoID: repo.ID})
+				require.NoError(t, err)
+				for _, j := range jobs {
+					if j.JobID == jobID {
+						job = j
+						return true
+					}
+				}
+				return false
+			}, 10*time.Second, 100*time.Millisecond)
+			require.NotNil(t, job)
+			return job
+		}
+		// pretend a runner has picked the job
+		startTask := func(t *testing.T, job *actions_model.ActionRunJob) *actions_model.ActionTask {
+			task := &actions_model.ActionTask{
+				JobID:     job.ID,
+				Attempt:   1,
+				RepoID:    job.RepoID,
+				OwnerID:   job.OwnerID,
+				CommitSHA: job.CommitSHA,
+				Status:    actions_model.StatusRunning,
+				Started:   job.Created,
+			}
+			require.NoError(t, task.GenerateToken())
+			require.NoError(t, job.LoadAttributes(db.DefaultContext))
+			var err error
+			task.Permissions, err = actions_model.GetEffectiveJobPermissions(db.DefaultContext, job)
+			require.NoError(t, err)
+			require.NoError(t, db.Insert(db.DefaultContext, task))
+			return task
+		}
+		requestToken := func(t *testing.T, authToken, audience string, status int) string {
+			req := NewRequest(t, "GET", "/api/actions/oidc/token?api-version=2.0&audience="+url.QueryEscape(audience)).
+				SetHeader("Authorization", "Bearer "+authToken)
+			resp := MakeRequest(t, req, status)
+			if status != http.StatusOK {
+				return ""
+			}
+			var result struct {
+				Value string `json:"value"`
+			}
+			DecodeJSON(t, resp, &result)
+			return result.Value
+		}
+
+		issuer := setting.AppURL + "api/actions/oidc"
+		var publicKey *rsa.PublicKey
+
+		t.Run("Discovery", func(t *testing.T) {
+			resp := MakeRequest(t, NewRequest(t, "GET", "/api/actions/oidc/.well-known/openid-configuration"), http.StatusOK)
+			var configuration struct {
+				Issuer            string   `json:"issuer"`
+				JWKsURI           string   `json:"jwks_uri"`
+				Algorithms        []string `json:"id_token_signing_alg_values_supported"`
+				ClaimsSupported   []string `json:"claims_supported"`
+				ResponseSupported []string `json:"response_types_supported"`
+				SubjectSupported  []string `json:"subject_types_supported"`
+			}
+			DecodeJSON(t, resp, &configuration)
+			assert.Equal(t, issuer, configuration.Issuer)
+			assert.Equal(t, issuer+"/.well-known/jwks", configuration.JWKsURI)
+			assert.Equal(t, []string{"RS256"}, configuration.Algorithms)
+			assert.Contains(t, configuration.ClaimsSupported, "run_attempt")
+			assert.Equal(t, []string{"id_token"}, configuration.ResponseSupported)
+			assert.Equal(t, []string{"public"}, configuration.SubjectSupported)
+
+			resp = MakeRequest(t, NewRequest(t, "GET", "/api/actions/oidc/.well-known/jwks"), http.StatusOK)
+			var jwks struct {
+				Keys []map[string]string `json:"keys"`
+			}
+			DecodeJSON(t, resp, &jwks)
+			require.Len(t, jwks.Keys, 1)
+			key := jwks.Keys[0]
+			assert.Equal(t, "RSA", key["kty"])
+			assert.Equal(t, "RS256", key["alg"])
+			assert.NotEmpty(t, key["kid"])
+
+			n, err := base64.RawURLEncoding.DecodeString(key["n"])
+			require.NoError(t, err)
+			e, err := base64.RawURLEncoding.DecodeString(key["e"])
+			require.NoError(t, err)
+			publicKey = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		})
+
+		t.Run("IssueToken", func(t *testing.T) {
+			require.NotNil(t, publicKey)
+			job := getJob(t, "deploy")
+			assert.True(t, job.Permissions.CanRequestIDToken())
+			task := startTask(t, job)
+
+			authToken, err := actions_service.CreateIDTokenRequestToken(task.ID, job.RunID, job.ID)
+			require.NoError(t, err)
+			idToken := requestToken(t, authToken, "sts.example.com", http.StatusOK)
+
+			claims := &actions_service.IDTokenClaims{}
+			parsed, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (any, error) {
+				return publicKey, nil
+			}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(issuer), jwt.WithAudience("sts.example.com"))
+			require.NoError(t, err)
+			assert.True(t, parsed.Valid)
+			assert.Equal(t, "repo:user2/actions-oidc:environment:production", claims.Subject)
+			assert.Equal(t, "user2/actions-oidc", claims.Repository)
+			assert.Equal(t, "user2", claims.RepositoryOwner)
+			assert.Equal(t, "refs/heads/main", claims.Ref)
+			assert.Equal(t, "branch", claims.RefType)
+			assert.Equal(t, job.CommitSHA, claims.SHA)
+			assert.Equal(t, "deploy.yml", claims.Workflow)
+			assert.Equal(t, "deploy", claims.Job)
+			assert.Equal(t, "user2", claims.Actor)
+			assert.Equal(t, "push", claims.EventName)
+			assert.Equal(t, "production", claims.Environment)
+			assert.Equal(t, "1", claims.RunAttempt)
+
+			// the default audience is the owner of the repository
+			idToken = requestToken(t, authToken, "", http.StatusOK)
+			claims = &actions_service.IDTokenClaims{}
+			_, _, err = jwt.NewParser().ParseUnverified(idToken, claims)
+			require.NoError(t, err)
+			assert.Equal(t, jwt.ClaimStrings{setting.AppURL + "user2"}, claims.Audience)
+
+			// the runtime token can't be used to request ID tokens
+			runtimeToken, err := actions_service.CreateAuthorizationToken(task.ID, job.RunID, job.ID)
+			require.NoError(t, err)
+			requestToken(t, runtimeToken, "sts.example.com", http.StatusUnauthorized)
+
+			// no ID tokens after the task is done
+			task.Status = actions_model.StatusSuccess
+			require.NoError(t, actions_model.UpdateTask(db.DefaultContext, task, "status"))
+			requestToken(t, authToken, "sts.example.com", http.StatusForbidden)
+		})
+
+		t.Run("NoPermission", func(t *testing.T) {
+			job := getJob(t, "build")
+			assert.Equal(t, actions_model.JobPermissions{"contents": actions_model.PermissionRead}, job.Permissions)
+			assert.False(t, job.Permissions.CanRequestIDToken())
+			task := startTask(t, job)
+
+			authToken, err := actions_service.CreateIDTokenRequestToken(task.ID, job.RunID, job.ID)
+			require.NoError(t, err)
+			requestToken(t, authToken, "sts.example.com", http.StatusForbidden)
+		})
+	})
+}
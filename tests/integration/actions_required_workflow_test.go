@@ -0,0 +1,179 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	actions_service "code.gitea.io/gitea/services/actions"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const requiredComplianceWorkflow = `name: Compliance
+on: push
+jobs:
+  scan:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo scan
+`
+
+func TestActionsRequiredWorkflow(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		org3 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3})
+
+		createRepo := func(t *testing.T, name string) *repo_model.Repository {
+			repo, err := repo_service.CreateRepository(db.DefaultContext, user2, org3, repo_service.CreateRepoOptions{
+				Name:          name,
+				AutoInit:      true,
+				Readme:        "Default",
+				DefaultBranch: "main",
+			})
+			require.NoError(t, err)
+			require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+				RepoID: repo.ID,
+				Type:   unit_model.TypeActions,
+			}}, nil))
+			return repo
+		}
+		protectMain := func(t *testing.T, repo *repo_model.Repository) {
+			require.NoError(t, git_model.UpdateProtectBranch(db.DefaultContext, repo, &git_model.ProtectedBranch{
+				RepoID:   repo.ID,
+				RuleName: "main",
+				CanPush:  true,
+			}, git_model.WhitelistOptions{}))
+		}
+		getRule := func(t *testing.T, repo *repo_model.Repository) *git_model.ProtectedBranch {
+			rule, err := git_model.GetProtectedBranchRuleByName(db.DefaultContext, repo.ID, "main")
+			require.NoError(t, err)
+			return rule
+		}
+
+		compliance := createRepo(t, "ci-compliance")
+		_, err := createFileInBranch(user2, compliance, ".gitea/workflows/compliance.yml", "main", requiredComplianceWorkflow)
+		require.NoError(t, err)
+		app := createRepo(t, "service-app")
+		protectMain(t, app)
+		docs := createRepo(t, "docs")
+		protectMain(t, docs)
+
+		rw, err := actions_service.CreateRequiredWorkflow(db.DefaultContext, compliance, ".gitea/workflows/compliance.yml", []string{"service-*"})
+		require.NoError(t, err)
+		assert.Equal(t, "Compliance", rw.Name)
+
+		t.Run("StatusChecks", func(t *testing.T) {
+			rule := getRule(t, app)
+			assert.True(t, rule.EnableStatusCheck)
+			assert.Equal(t, []string{"Compliance / *"}, rule.StatusCheckContexts)
+			assert.False(t, getRule(t, docs).EnableStatusCheck)
+
+			// the administrators of the repository cannot remove the required status checks
+			session := loginUser(t, "user2")
+			token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+			enable := false
+			req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/branch_protections/main", app.FullName()), &api.EditBranchProtectionOption{
+				EnableStatusCheck:   &enable,
+				StatusCheckContexts: []string{},
+			}).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			var bp api.BranchProtection
+			DecodeJSON(t, resp, &bp)
+			assert.True(t, bp.EnableStatusCheck)
+			assert.Equal(t, []string{"Compliance / *"}, bp.StatusCheckContexts)
+		})
+
+		t.Run("Runs", func(t *testing.T) {
+			_, err := createFileInBranch(user2, app, "README-app.md", "main", "app")
+			require.NoError(t, err)
+			run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: app.ID, RequiredWorkflowID: rw.ID})
+			assert.Equal(t, "ci-compliance/compliance.yml", run.WorkflowID)
+			unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID, JobID: "scan"})
+
+			_, err = createFileInBranch(user2, docs, "README-docs.md", "main", "docs")
+			require.NoError(t, err)
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: docs.ID})
+
+			// the workflow runs as a workflow of the repository containing it
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: compliance.ID, RequiredWorkflowID: rw.ID})
+		})
+
+		t.Run("SettingsPages", func(t *testing.T) {
+			session := loginUser(t, "user2")
+			resp := session.MakeRequest(t, NewRequest(t, "GET", "/org/org3/settings/actions/required_workflows"), http.StatusOK)
+			assert.Contains(t, resp.Body.String(), "ci-compliance/.gitea/workflows/compliance.yml")
+			resp = session.MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/%s/settings/branches/edit?rule_name=main", app.FullName())), http.StatusOK)
+			assert.Contains(t, resp.Body.String(), "Compliance / *")
+		})
+
+		t.Run("DeleteRule", func(t *testing.T) {
+			// the administrators of the repository cannot delete the rule carrying the required status checks
+			session := loginUser(t, "user2")
+			token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+			req := NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/%s/branch_protections/main", app.FullName())).AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusForbidden)
+
+			rule := getRule(t, app)
+			req = NewRequestWithValues(t, "POST", fmt.Sprintf("/%s/settings/branches/%d/delete", app.FullName(), rule.ID), map[string]string{
+				"_csrf": GetUserCSRFToken(t, session),
+			})
+			session.MakeRequest(t, req, http.StatusOK)
+			assert.NotNil(t, getRule(t, app))
+
+			// the rules without required status checks can be deleted
+			protectMain(t, compliance)
+			req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/%s/branch_protections/main", compliance.FullName())).AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusNoContent)
+			assert.Nil(t, getRule(t, compliance))
+		})
+
+		t.Run("RenameAndTransfer", func(t *testing.T) {
+			token := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteRepository)
+			rename := func(t *testing.T, from, to string) {
+				req := NewRequestWithJSON(t, "PATCH", "/api/v1/repos/org3/"+from, &api.EditRepoOption{Name: &to}).AddTokenAuth(token)
+				MakeRequest(t, req, http.StatusOK)
+			}
+			rename(t, "service-app", "app")
+			assert.Empty(t, getRule(t, app).StatusCheckContexts)
+			rename(t, "app", "service-app")
+			assert.Equal(t, []string{"Compliance / *"}, getRule(t, app).StatusCheckContexts)
+			app = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: app.ID})
+
+			require.NoError(t, repo_service.TransferOwnership(db.DefaultContext, user2, user2, app, nil))
+			assert.Empty(t, getRule(t, app).StatusCheckContexts)
+			app = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: app.ID})
+			require.NoError(t, repo_service.TransferOwnership(db.DefaultContext, user2, org3, app, nil))
+			assert.Equal(t, []string{"Compliance / *"}, getRule(t, app).StatusCheckContexts)
+			app = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: app.ID})
+		})
+
+		t.Run("UpdateAndDelete", func(t *testing.T) {
+			require.NoError(t, actions_service.UpdateRequiredWorkflow(db.DefaultContext, rw, nil))
+			assert.Equal(t, []string{"Compliance / *"}, getRule(t, docs).StatusCheckContexts)
+
+			require.NoError(t, actions_service.UpdateRequiredWorkflow(db.DefaultContext, rw, []string{"docs"}))
+			assert.Empty(t, getRule(t, app).StatusCheckContexts)
+			assert.Equal(t, []string{"Compliance / *"}, getRule(t, docs).StatusCheckContexts)
+
+			require.NoError(t, actions_service.DeleteRequiredWorkflow(db.DefaultContext, rw))
+			assert.Empty(t, getRule(t, docs).StatusCheckContexts)
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRequiredWorkflow{ID: rw.ID})
+		})
+	})
+}
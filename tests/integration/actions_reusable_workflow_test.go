@@ -0,0 +1,200 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_service "code.gitea.io/gitea/services/actions"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const reusableBuildWorkflow = `name: build
+on:
+  workflow_call:
+    inputs:
+      target:
+        type: string
+        required: true
+    secrets:
+      token:
+        required: false
+    outputs:
+      artifact:
+        value: ${{ jobs.package.outputs.artifact }}
+jobs:
+  compile:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ inputs.target }}
+  package:
+    needs: compile
+    runs-on: ubuntu-latest
+    outputs:
+      artifact: ${{ steps.pack.outputs.artifact }}
+    steps:
+      - id: pack
+        run: echo "artifact=app.tar" >> $GITHUB_OUTPUT
+`
+
+const reusableCallerWorkflow = `name: ci
+on: push
+jobs:
+  setup:
+    runs-on: ubuntu-latest
+    outputs:
+      target: ${{ steps.target.outputs.target }}
+    steps:
+      - id: target
+        run: echo "target=linux" >> $GITHUB_OUTPUT
+  build:
+    needs: setup
+    uses: user2/ci-templates/.gitea/workflows/build.yml@main
+    with:
+      target: ${{ needs.setup.outputs.target }}
+    secrets:
+      token: ${{ secrets.DEPLOY_TOKEN }}
+  deploy:
+    needs: build
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ needs.build.outputs.artifact }}
+`
+
+func TestActionsReusableWorkflow(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		user5 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+
+		createRepo := func(t *testing.T, owner *user_model.User, name string, isPrivate bool) *repo_model.Repository {
+			repo, err := repo_service.CreateRepository(db.DefaultContext, owner, owner, repo_service.CreateRepoOptions{
+				Name:          name,
+				AutoInit:      true,
+				Readme:        "Default",
+				DefaultBranch: "main",
+				IsPrivate:     isPrivate,
+			})
+			require.NoError(t, err)
+			require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+				RepoID: repo.ID,
+				Type:   unit_model.TypeActions,
+			}}, nil))
+			return repo
+		}
+
+		templates := createRepo(t, user2, "ci-templates", true)
+		_, err := createFileInBranch(user2, templates, ".gitea/workflows/build.yml", "main", reusableBuildWorkflow)
+		require.NoError(t, err)
+
+		setJobStatus := func(t *testing.T, job *actions_model.ActionRunJob, status actions_model.Status) {
+			job.Status = status
+			_, err := actions_model.UpdateRunJob(db.DefaultContext, job, nil, "status")
+			require.NoError(t, err)
+			require.NoError(t, actions_service.EmitJobsIfReady(job.RunID))
+		}
+		waitJobStatus := func(t *testing.T, id int64, status actions_model.Status) *actions_model.ActionRunJob {
+			var job *actions_model.ActionRunJob
+			assert.Eventually(t, func() bool {
+				job = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: id})
+				return job.Status == status
+			}, 10*time.Second, 100*time.Millisecond, "job %d should be %s", id, status)
+			return job
+		}
+
+		t.Run("CallAcrossRepositories", func(t *testing.T) {
+			app := createRepo(t, user2, "app-reusable-workflow", false)
+			_, err := createFileInBranch(user2, app, ".gitea/workflows/ci.yml", "main", reusableCallerWorkflow)
+			require.NoError(t, err)
+
+			run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: app.ID})
+			jobs, err := actions_model.GetRunJobsByRunID(db.DefaultContext, run.ID)
+			require.NoError(t, err)
+			require.Len(t, jobs, 5)
+			setup, build, compile, pack, deploy := jobs[0], jobs[1], jobs[2], jobs[3], jobs[4]
+			assert.Equal(t, "setup", setup.JobID)
+			assert.Equal(t, "build", build.JobID)
+			assert.Equal(t, "build", build.CallPath)
+			assert.Empty(t, build.CallerPath)
+			assert.Equal(t, "compile", compile.JobID)
+			assert.Equal(t, "build / compile", compile.Name)
+			assert.Equal(t, "build", compile.CallerPath)
+			assert.Equal(t, "package", pack.JobID)
+			assert.Equal(t, []string{"compile"}, pack.Needs)
+			assert.Equal(t, "deploy", deploy.JobID)
+			assert.Equal(t, actions_model.StatusWaiting, setup.Status)
+			for _, job := range jobs[1:] {
+				assert.Equal(t, actions_model.StatusBlocked, job.Status, job.JobID)
+			}
+
+			// the call starts when its needs are done, then the called jobs start
+			setupTask := &actions_model.ActionTask{JobID: setup.ID, RepoID: app.ID, Status: actions_model.StatusSuccess}
+			require.NoError(t, setupTask.GenerateToken())
+			require.NoError(t, db.Insert(db.DefaultContext, setupTask))
+			require.NoError(t, db.Insert(db.DefaultContext, &actions_model.ActionTaskOutput{TaskID: setupTask.ID, OutputKey: "target", OutputValue: "linux"}))
+			setup.TaskID = setupTask.ID
+			_, err = actions_model.UpdateRunJob(db.DefaultContext, setup, nil, "task_id")
+			require.NoError(t, err)
+			setJobStatus(t, setup, actions_model.StatusSuccess)
+			waitJobStatus(t, build.ID, actions_model.StatusRunning)
+			compile = waitJobStatus(t, compile.ID, actions_model.StatusWaiting)
+			assert.Equal(t, actions_model.StatusBlocked, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: pack.ID}).Status)
+
+			// the inputs are passed by the environment variables and only the mapped secrets are available
+			payload, secrets, err := actions_service.PrepareWorkflowCallTask(db.DefaultContext, &actions_model.ActionTask{Job: compile, Token: "task-token"},
+				map[string]string{"GITEA_TOKEN": "task-token", "GITHUB_TOKEN": "task-token", "DEPLOY_TOKEN": "deploy", "OTHER": "other"})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"GITEA_TOKEN": "task-token", "GITHUB_TOKEN": "task-token", "TOKEN": "deploy"}, secrets)
+			swf := &jobparser.SingleWorkflow{}
+			require.NoError(t, yaml.Unmarshal(payload, swf))
+			assert.Equal(t, "linux", swf.Env["INPUT_TARGET"])
+
+			setJobStatus(t, compile, actions_model.StatusSuccess)
+			pack = waitJobStatus(t, pack.ID, actions_model.StatusWaiting)
+			packTask := &actions_model.ActionTask{JobID: pack.ID, RepoID: app.ID, Status: actions_model.StatusSuccess}
+			require.NoError(t, packTask.GenerateToken())
+			require.NoError(t, db.Insert(db.DefaultContext, packTask))
+			require.NoError(t, db.Insert(db.DefaultContext, &actions_model.ActionTaskOutput{TaskID: packTask.ID, OutputKey: "artifact", OutputValue: "app.tar"}))
+			pack.TaskID = packTask.ID
+			_, err = actions_model.UpdateRunJob(db.DefaultContext, pack, nil, "task_id")
+			require.NoError(t, err)
+			setJobStatus(t, pack, actions_model.StatusSuccess)
+
+			// the call finishes with the called jobs and its outputs are mapped from theirs
+			waitJobStatus(t, build.ID, actions_model.StatusSuccess)
+			deploy = waitJobStatus(t, deploy.ID, actions_model.StatusWaiting)
+			needs, err := actions_service.FindJobNeeds(db.DefaultContext, deploy)
+			require.NoError(t, err)
+			require.Contains(t, needs, "build")
+			assert.Equal(t, actions_model.StatusSuccess, needs["build"].Status)
+			assert.Equal(t, map[string]string{"artifact": "app.tar"}, needs["build"].Outputs)
+		})
+
+		t.Run("NoPermission", func(t *testing.T) {
+			app := createRepo(t, user5, "app-reusable-workflow-no-perm", false)
+			_, err := createFileInBranch(user5, app, ".gitea/workflows/ci.yml", "main", reusableCallerWorkflow)
+			require.NoError(t, err)
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: app.ID})
+		})
+
+		t.Run("NestingLimit", func(t *testing.T) {
+			app := createRepo(t, user2, "app-reusable-workflow-loop", false)
+			_, err := createFileInBranch(user2, app, ".gitea/workflows/loop.yml", "main", "on: [push, workflow_call]\njobs:\n  again:\n    uses: ./.gitea/workflows/loop.yml\n")
+			require.NoError(t, err)
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: app.ID})
+		})
+	})
+}
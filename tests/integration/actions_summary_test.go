@@ -0,0 +1,179 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/test"
+	actions_web "code.gitea.io/gitea/routers/web/repo/actions"
+	actions_service "code.gitea.io/gitea/services/actions"
+	repo_service "code.gitea.io/gitea/services/repository"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	runnerv1 "code.gitea.io/actions-proto-go/runner/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const summaryWorkflow = `name: test
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: npm test
+`
+
+func TestActionsSummaryAndAnnotations(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		session := loginUser(t, user2.Name)
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:          "actions-summary",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil))
+		_, err = createFileInBranch(user2, repo, ".gitea/workflows/test.yml", "main", summaryWorkflow)
+		require.NoError(t, err)
+
+		// push a commit to a new branch to trigger the workflow
+		_, err = files_service.ChangeRepoFiles(git.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+			Files: []*files_service.ChangeRepoFile{{
+				Operation:     "create",
+				TreePath:      "app.js",
+				ContentReader: strings.NewReader("let a = 1\nlet b = 2\n"),
+			}},
+			OldBranch: "main",
+			NewBranch: "feature",
+		})
+		require.NoError(t, err)
+
+		var job *actions_model.ActionRunJob
+		assert.Eventually(t, func() bool {
+			jobs, err := db.Find[actions_model.ActionRunJob](db.DefaultContext, actions_model.FindRunJobOptions{RepoID: repo.ID})
+			require.NoError(t, err)
+			for _, j := range jobs {
+				require.NoError(t, j.LoadRun(db.DefaultContext))
+				if j.Run.Ref == git.BranchPrefix+"feature" {
+					job = j
+					return true
+				}
+			}
+			return false
+		}, 10*time.Second, 100*time.Millisecond)
+		require.NotNil(t, job)
+
+		// pretend a runner has picked the job
+		task := &actions_model.ActionTask{
+			JobID:     job.ID,
+			Attempt:   1,
+			RepoID:    job.RepoID,
+			OwnerID:   job.OwnerID,
+			CommitSHA: job.CommitSHA,
+			Status:    actions_model.StatusRunning,
+			Started:   job.Created,
+		}
+		require.NoError(t, task.GenerateToken())
+		require.NoError(t, db.Insert(db.DefaultContext, task))
+		require.NoError(t, db.Insert(db.DefaultContext, []*actions_model.ActionTaskStep{
+			{Name: "Checkout", TaskID: task.ID, Index: 0, RepoID: task.RepoID, Status: actions_model.StatusSuccess},
+			{Name: "Test", TaskID: task.ID, Index: 1, RepoID: task.RepoID, Status: actions_model.StatusRunning},
+		}))
+		job.TaskID = task.ID
+		_, err = actions_model.UpdateRunJob(db.DefaultContext, job, nil, "task_id")
+		require.NoError(t, err)
+
+		t.Run("UploadSummary", func(t *testing.T) {
+			runtimeToken, err := actions_service.CreateAuthorizationToken(task.ID, job.RunID, job.ID)
+			require.NoError(t, err)
+
+			req := NewRequestWithBody(t, "PUT", "/api/actions/summaries/0", strings.NewReader("## Test results\n\n**3** passed"))
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequestWithBody(t, "PUT", "/api/actions/summaries/0", strings.NewReader("## Test results\n\n**3** passed")).
+				SetHeader("Authorization", "Bearer "+runtimeToken)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequestWithBody(t, "PUT", "/api/actions/summaries/-1", strings.NewReader("invalid")).
+				SetHeader("Authorization", "Bearer "+runtimeToken)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			// the task has only two steps
+			req = NewRequestWithBody(t, "PUT", "/api/actions/summaries/2", strings.NewReader("invalid")).
+				SetHeader("Authorization", "Bearer "+runtimeToken)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			unknownTaskToken, err := actions_service.CreateAuthorizationToken(task.ID+1000, job.RunID, job.ID)
+			require.NoError(t, err)
+			req = NewRequestWithBody(t, "PUT", "/api/actions/summaries/0", strings.NewReader("invalid")).
+				SetHeader("Authorization", "Bearer "+unknownTaskToken)
+			MakeRequest(t, req, http.StatusNotFound)
+
+			summaries, err := actions_model.GetTaskSummaries(db.DefaultContext, task.ID)
+			require.NoError(t, err)
+			require.Len(t, summaries, 1)
+			assert.Equal(t, "## Test results\n\n**3** passed", summaries[0].Content)
+		})
+
+		t.Run("CollectAnnotations", func(t *testing.T) {
+			require.NoError(t, actions_service.CollectAnnotations(db.DefaultContext, task, []*runnerv1.LogRow{
+				{Content: "> npm test"},
+				{Content: "::error file=app.js,line=2,title=Lint::Missing semicolon"},
+				{Content: "::warning::Deprecated option"},
+			}, 0))
+			annotations, err := db.Find[actions_model.ActionTaskAnnotation](db.DefaultContext, actions_model.FindTaskAnnotationOptions{TaskID: task.ID})
+			require.NoError(t, err)
+			require.Len(t, annotations, 2)
+			assert.EqualValues(t, 1, annotations[0].LogIndex)
+			assert.Equal(t, job.CommitSHA, annotations[0].CommitSHA)
+		})
+
+		t.Run("RunView", func(t *testing.T) {
+			require.NoError(t, job.LoadRun(db.DefaultContext))
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s/actions/runs/%d/jobs/0", repo.Link(), job.Run.Index), actions_web.ViewRequest{}).
+				SetHeader("X-Csrf-Token", GetUserCSRFToken(t, session))
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			var view actions_web.ViewResponse
+			DecodeJSON(t, resp, &view)
+			assert.Contains(t, string(view.State.CurrentJob.SummaryHTML), "<strong>3</strong> passed")
+			require.Len(t, view.State.CurrentJob.Annotations, 2)
+			assert.Equal(t, "Lint", view.State.CurrentJob.Annotations[0].Title)
+			assert.Equal(t, "app.js#L2", view.State.CurrentJob.Annotations[0].Location)
+			assert.Empty(t, view.State.CurrentJob.Annotations[1].Location)
+		})
+
+		t.Run("PullDiff", func(t *testing.T) {
+			req := NewRequestWithValues(t, "POST", repo.Link()+"/compare/main...feature", map[string]string{
+				"_csrf": GetUserCSRFToken(t, session),
+				"title": "Add app.js",
+			})
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc := NewHTMLParser(t, session.MakeRequest(t, NewRequest(t, "GET", test.RedirectURL(resp)+"/files"), http.StatusOK).Body)
+			annotation := htmlDoc.Find(".diff-annotation-error")
+			assert.Equal(t, 1, annotation.Length())
+			assert.Contains(t, annotation.Text(), "Missing semicolon")
+			// the annotation without a location isn't shown in the diff
+			assert.Equal(t, 0, htmlDoc.Find(".diff-annotation-warning").Length())
+		})
+	})
+}
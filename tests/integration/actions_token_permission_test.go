@@ -0,0 +1,169 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tokenPermissionWorkflow = `name: permissions
+on: push
+jobs:
+  reader:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - run: echo read
+  writer:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+      packages: write
+    steps:
+      - run: echo write
+  default:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo default
+`
+
+func TestActionsTokenPermissions(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		session := loginUser(t, user2.Name)
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:          "actions-token-permissions",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil))
+
+		session.MakeRequest(t, NewRequest(t, "GET", repo.Link()+"/settings/actions/general"), http.StatusOK)
+		session.MakeRequest(t, NewRequest(t, "GET", "/org/org3/settings/actions/general"), http.StatusOK)
+
+		// the jobs without their own permissions could only read the contents and the packages
+		req := NewRequestWithValues(t, "POST", repo.Link()+"/settings/actions/general", map[string]string{
+			"_csrf": GetUserCSRFToken(t, session),
+			"mode":  string(actions_model.TokenPermissionModeRestricted),
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+		repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repo.ID})
+		mode, err := actions_model.GetRepoTokenPermissionMode(db.DefaultContext, repo)
+		require.NoError(t, err)
+		assert.Equal(t, actions_model.TokenPermissionModeRestricted, mode)
+
+		_, err = createFileInBranch(user2, repo, ".gitea/workflows/permissions.yml", "main", tokenPermissionWorkflow)
+		require.NoError(t, err)
+
+		// pretend a runner has picked the job
+		startTask := func(t *testing.T, jobID string) *actions_model.ActionTask {
+			var job *actions_model.ActionRunJob
+			assert.Eventually(t, func() bool {
+				jobs, err := db.Find[actions_model.ActionRunJob](db.DefaultContext, actions_model.FindRunJobOptions{RepoID: repo.ID})
+				require.NoError(t, err)
+				for _, j := range jobs {
+					if j.JobID == jobID {
+						job = j
+						return true
+					}
+				}
+				return false
+			}, 10*time.Second, 100*time.Millisecond)
+			require.NotNil(t, job)
+			require.NoError(t, job.LoadAttributes(db.DefaultContext))
+
+			task := &actions_model.ActionTask{
+				JobID:     job.ID,
+				Attempt:   1,
+				RepoID:    job.RepoID,
+				OwnerID:   job.OwnerID,
+				CommitSHA: job.CommitSHA,
+				Status:    actions_model.StatusRunning,
+				Started:   job.Created,
+			}
+			require.NoError(t, task.GenerateToken())
+			task.Permissions, err = actions_model.GetEffectiveJobPermissions(db.DefaultContext, job)
+			require.NoError(t, err)
+			require.NoError(t, db.Insert(db.DefaultContext, task))
+			return task
+		}
+		createFile := func(t *testing.T, token, name string, status int) {
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/contents/%s", repo.FullName(), name), &api.CreateFileOptions{
+				ContentBase64: base64.StdEncoding.EncodeToString([]byte("content")),
+			}).AddTokenAuth(token)
+			MakeRequest(t, req, status)
+		}
+		pushBranch := func(t *testing.T, token, branch string, success bool) {
+			cloneURL, _ := url.Parse(u.String())
+			cloneURL.Path = repo.FullName() + ".git"
+			cloneURL.User = url.UserPassword("gitea-actions", token)
+			dstPath := t.TempDir()
+			doGitClone(dstPath, cloneURL)(t)
+			doGitCreateBranch(dstPath, branch)(t)
+			doGitAddSomeCommits(dstPath, branch)(t)
+			if success {
+				doGitPushTestRepository(dstPath, "origin", branch)(t)
+			} else {
+				doGitPushTestRepositoryFail(dstPath, "origin", branch)(t)
+			}
+		}
+		uploadPackage := func(t *testing.T, token, version string, status int) {
+			req := NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/generic/token-permissions/%s/file.bin", user2.Name, version), strings.NewReader("package")).
+				AddTokenAuth(token)
+			MakeRequest(t, req, status)
+		}
+
+		t.Run("Reader", func(t *testing.T) {
+			task := startTask(t, "reader")
+			assert.Equal(t, actions_model.JobPermissions{"contents": actions_model.PermissionRead}, task.Permissions)
+
+			MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/contents/README.md", repo.FullName())).AddTokenAuth(task.Token), http.StatusOK)
+			createFile(t, task.Token, "reader.txt", http.StatusForbidden)
+			pushBranch(t, task.Token, "reader", false)
+			uploadPackage(t, task.Token, "1.0.0", http.StatusUnauthorized)
+		})
+
+		t.Run("Writer", func(t *testing.T) {
+			task := startTask(t, "writer")
+			assert.Equal(t, actions_model.PermissionWrite, task.Permissions["contents"])
+
+			createFile(t, task.Token, "writer.txt", http.StatusCreated)
+			pushBranch(t, task.Token, "writer", true)
+			uploadPackage(t, task.Token, "1.0.0", http.StatusCreated)
+		})
+
+		t.Run("Default", func(t *testing.T) {
+			task := startTask(t, "default")
+			assert.Equal(t, actions_model.TokenPermissionModeRestricted.DefaultPermissions(), task.Permissions)
+
+			createFile(t, task.Token, "default.txt", http.StatusForbidden)
+			pushBranch(t, task.Token, "default", false)
+			uploadPackage(t, task.Token, "2.0.0", http.StatusUnauthorized)
+		})
+	})
+}
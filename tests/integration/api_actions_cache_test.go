@@ -0,0 +1,155 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/routers/api/actions"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestActionsCache(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	assert.NoError(t, storage.Clean(storage.ActionsCache))
+
+	token, err := actions_service.CreateAuthorizationToken(48, 792, 193)
+	assert.NoError(t, err)
+
+	relativeURL := func(signed string) string {
+		return signed[strings.Index(signed, "/twirp/"):]
+	}
+
+	createEntry := func(t *testing.T, key, version string) *actions.CreateCacheEntryResponse {
+		req := NewRequestWithBody(t, "POST", "/twirp/github.actions.results.api.v1.CacheService/CreateCacheEntry", toProtoJSON(&actions.CreateCacheEntryRequest{
+			Key:     key,
+			Version: version,
+		})).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var createResp actions.CreateCacheEntryResponse
+		assert.NoError(t, protojson.Unmarshal(resp.Body.Bytes(), &createResp))
+		return &createResp
+	}
+
+	finalizeEntry := func(t *testing.T, key, version string, size int64) *actions.FinalizeCacheEntryUploadResponse {
+		req := NewRequestWithBody(t, "POST", "/twirp/github.actions.results.api.v1.CacheService/FinalizeCacheEntryUpload", toProtoJSON(&actions.FinalizeCacheEntryUploadRequest{
+			Key:       key,
+			Version:   version,
+			SizeBytes: size,
+		})).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var finalizeResp actions.FinalizeCacheEntryUploadResponse
+		assert.NoError(t, protojson.Unmarshal(resp.Body.Bytes(), &finalizeResp))
+		return &finalizeResp
+	}
+
+	getDownloadURL := func(t *testing.T, key, version string, restoreKeys ...string) *actions.GetCacheEntryDownloadURLResponse {
+		req := NewRequestWithBody(t, "POST", "/twirp/github.actions.results.api.v1.CacheService/GetCacheEntryDownloadURL", toProtoJSON(&actions.GetCacheEntryDownloadURLRequest{
+			Key:         key,
+			RestoreKeys: restoreKeys,
+			Version:     version,
+		})).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var downloadResp actions.GetCacheEntryDownloadURLResponse
+		assert.NoError(t, protojson.Unmarshal(resp.Body.Bytes(), &downloadResp))
+		return &downloadResp
+	}
+
+	content := strings.Repeat("A", 1024) + strings.Repeat("B", 1024)
+
+	t.Run("SingleShotUpload", func(t *testing.T) {
+		createResp := createEntry(t, "npm-linux-1", "v1")
+		assert.True(t, createResp.Ok)
+		assert.Contains(t, createResp.SignedUploadUrl, "/twirp/github.actions.results.api.v1.CacheService/UploadCache")
+
+		req := NewRequestWithBody(t, "PUT", relativeURL(createResp.SignedUploadUrl), strings.NewReader(content))
+		MakeRequest(t, req, http.StatusCreated)
+
+		finalizeResp := finalizeEntry(t, "npm-linux-1", "v1", int64(len(content)))
+		assert.True(t, finalizeResp.Ok)
+		assert.NotZero(t, finalizeResp.EntryId)
+
+		// caches are immutable
+		createResp = createEntry(t, "npm-linux-1", "v1")
+		assert.False(t, createResp.Ok)
+	})
+
+	t.Run("BlockUpload", func(t *testing.T) {
+		createResp := createEntry(t, "npm-linux-2", "v1")
+		assert.True(t, createResp.Ok)
+		uploadURL := relativeURL(createResp.SignedUploadUrl)
+
+		blockIDs := []string{"block-2", "block-1"}
+		for i, blockID := range blockIDs {
+			req := NewRequestWithBody(t, "PUT", uploadURL+"&comp=block&blockid="+base64.StdEncoding.EncodeToString([]byte(blockID)), strings.NewReader(content[i*1024:(i+1)*1024]))
+			MakeRequest(t, req, http.StatusCreated)
+		}
+		blockList := "<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"yes\"?><BlockList>"
+		for _, blockID := range blockIDs {
+			blockList += fmt.Sprintf("<Latest>%s</Latest>", base64.StdEncoding.EncodeToString([]byte(blockID)))
+		}
+		blockList += "</BlockList>"
+		req := NewRequestWithBody(t, "PUT", uploadURL+"&comp=blocklist", strings.NewReader(blockList))
+		MakeRequest(t, req, http.StatusCreated)
+
+		finalizeResp := finalizeEntry(t, "npm-linux-2", "v1", int64(len(content)))
+		assert.True(t, finalizeResp.Ok)
+
+		downloadResp := getDownloadURL(t, "npm-linux-2", "v1")
+		assert.True(t, downloadResp.Ok)
+		assert.Equal(t, "npm-linux-2", downloadResp.MatchedKey)
+		resp := MakeRequest(t, NewRequest(t, "GET", relativeURL(downloadResp.SignedDownloadUrl)), http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+	})
+
+	t.Run("FinalizeNotUploaded", func(t *testing.T) {
+		createResp := createEntry(t, "npm-linux-3", "v1")
+		assert.True(t, createResp.Ok)
+		finalizeResp := finalizeEntry(t, "npm-linux-3", "v1", 0)
+		assert.False(t, finalizeResp.Ok)
+
+		downloadResp := getDownloadURL(t, "npm-linux-3", "v1")
+		assert.False(t, downloadResp.Ok)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		// exact match
+		downloadResp := getDownloadURL(t, "npm-linux-1", "v1", "npm-")
+		assert.True(t, downloadResp.Ok)
+		assert.Equal(t, "npm-linux-1", downloadResp.MatchedKey)
+
+		resp := MakeRequest(t, NewRequest(t, "GET", relativeURL(downloadResp.SignedDownloadUrl)), http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+
+		req := NewRequest(t, "GET", relativeURL(downloadResp.SignedDownloadUrl))
+		req.Header.Set("x-ms-range", "bytes=1020-1027")
+		resp = MakeRequest(t, req, http.StatusPartialContent)
+		assert.Equal(t, "AAAABBBB", resp.Body.String())
+
+		// the most recent entry matching the restore key
+		downloadResp = getDownloadURL(t, "npm-macos-1", "v1", "npm-windows-", "npm-linux-")
+		assert.True(t, downloadResp.Ok)
+		assert.Equal(t, "npm-linux-2", downloadResp.MatchedKey)
+
+		// the version must be the same
+		downloadResp = getDownloadURL(t, "npm-linux-1", "v2", "npm-")
+		assert.False(t, downloadResp.Ok)
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		downloadResp := getDownloadURL(t, "npm-linux-1", "v1")
+		assert.True(t, downloadResp.Ok)
+		url := strings.Replace(relativeURL(downloadResp.SignedDownloadUrl), "sig=", "sig=x", 1)
+		MakeRequest(t, NewRequest(t, "GET", url), http.StatusUnauthorized)
+	})
+}
@@ -0,0 +1,232 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	repo_service "code.gitea.io/gitea/services/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dispatchWorkflow = `name: Deploy
+on:
+  workflow_dispatch:
+    inputs:
+      env:
+        type: choice
+        options: [dev, prod]
+        default: dev
+      debug:
+        type: boolean
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploy
+`
+
+func TestAPIActionsRuns(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:          "actions-api",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil))
+		_, err = createFileInBranch(user2, repo, ".gitea/workflows/deploy.yml", "main", dispatchWorkflow)
+		require.NoError(t, err)
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+		apiURL := fmt.Sprintf("/api/v1/repos/%s/actions", repo.FullName())
+
+		dispatch := func(t *testing.T, workflowID string, inputs map[string]string, status int) {
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("%s/workflows/%s/dispatches", apiURL, workflowID), &api.CreateActionWorkflowDispatch{
+				Ref:    "main",
+				Inputs: inputs,
+			}).AddTokenAuth(token)
+			MakeRequest(t, req, status)
+		}
+		var run *actions_model.ActionRun
+		var job *actions_model.ActionRunJob
+
+		t.Run("Workflows", func(t *testing.T) {
+			resp := MakeRequest(t, NewRequest(t, "GET", apiURL+"/workflows").AddTokenAuth(token), http.StatusOK)
+			var workflows api.ActionWorkflowsResponse
+			DecodeJSON(t, resp, &workflows)
+			require.EqualValues(t, 1, workflows.TotalCount)
+			assert.Equal(t, "deploy.yml", workflows.Workflows[0].ID)
+			assert.Equal(t, "Deploy", workflows.Workflows[0].Name)
+			assert.Equal(t, ".gitea/workflows/deploy.yml", workflows.Workflows[0].Path)
+			assert.Equal(t, "active", workflows.Workflows[0].State)
+
+			MakeRequest(t, NewRequest(t, "GET", apiURL+"/workflows/missing.yml").AddTokenAuth(token), http.StatusNotFound)
+		})
+
+		t.Run("Dispatch", func(t *testing.T) {
+			dispatch(t, "missing.yml", nil, http.StatusNotFound)
+			dispatch(t, "deploy.yml", map[string]string{"unknown": "1"}, http.StatusUnprocessableEntity)
+			dispatch(t, "deploy.yml", map[string]string{"env": "staging"}, http.StatusUnprocessableEntity)
+			dispatch(t, "deploy.yml", map[string]string{"debug": "maybe"}, http.StatusUnprocessableEntity)
+			unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: repo.ID})
+
+			dispatch(t, "deploy.yml", map[string]string{"env": "prod"}, http.StatusNoContent)
+			run = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: repo.ID, WorkflowID: "deploy.yml"})
+			assert.Equal(t, "refs/heads/main", run.Ref)
+			var payload api.WorkflowDispatchPayload
+			require.NoError(t, json.Unmarshal([]byte(run.EventPayload), &payload))
+			assert.Equal(t, map[string]any{"env": "prod", "debug": "false"}, payload.Inputs)
+			job = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID, JobID: "deploy"})
+		})
+
+		t.Run("ListAndGetRuns", func(t *testing.T) {
+			listRuns := func(t *testing.T, link string) *api.ActionWorkflowRunsResponse {
+				resp := MakeRequest(t, NewRequest(t, "GET", link).AddTokenAuth(token), http.StatusOK)
+				var runs api.ActionWorkflowRunsResponse
+				DecodeJSON(t, resp, &runs)
+				return &runs
+			}
+			runs := listRuns(t, apiURL+"/runs")
+			require.EqualValues(t, 1, runs.TotalCount)
+			assert.Equal(t, run.ID, runs.WorkflowRuns[0].ID)
+			assert.Equal(t, "queued", runs.WorkflowRuns[0].Status)
+			assert.Equal(t, "workflow_dispatch", runs.WorkflowRuns[0].Event)
+
+			assert.EqualValues(t, 1, listRuns(t, apiURL+"/runs?status=queued&branch=main&event=workflow_dispatch&actor=user2").TotalCount)
+			assert.EqualValues(t, 0, listRuns(t, apiURL+"/runs?status=completed").TotalCount)
+			assert.EqualValues(t, 0, listRuns(t, apiURL+"/runs?actor=user4").TotalCount)
+			assert.EqualValues(t, 1, listRuns(t, apiURL+"/runs?head_sha="+run.CommitSHA).TotalCount)
+			assert.EqualValues(t, 1, listRuns(t, apiURL+"/workflows/deploy.yml/runs").TotalCount)
+			assert.EqualValues(t, 0, listRuns(t, apiURL+"/workflows/other.yml/runs").TotalCount)
+			MakeRequest(t, NewRequest(t, "GET", apiURL+"/runs?status=unknown").AddTokenAuth(token), http.StatusUnprocessableEntity)
+
+			resp := MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/runs/%d", apiURL, run.ID)).AddTokenAuth(token), http.StatusOK)
+			var apiRun api.ActionWorkflowRun
+			DecodeJSON(t, resp, &apiRun)
+			assert.Equal(t, run.ID, apiRun.ID)
+			assert.Equal(t, "main", apiRun.HeadBranch)
+			MakeRequest(t, NewRequest(t, "GET", apiURL+"/runs/999999").AddTokenAuth(token), http.StatusNotFound)
+		})
+
+		t.Run("Jobs", func(t *testing.T) {
+			resp := MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/runs/%d/jobs", apiURL, run.ID)).AddTokenAuth(token), http.StatusOK)
+			var jobs api.ActionWorkflowJobsResponse
+			DecodeJSON(t, resp, &jobs)
+			require.EqualValues(t, 1, jobs.TotalCount)
+			assert.Equal(t, job.ID, jobs.Jobs[0].ID)
+			assert.Equal(t, "deploy", jobs.Jobs[0].Name)
+
+			resp = MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/jobs/%d", apiURL, job.ID)).AddTokenAuth(token), http.StatusOK)
+			var apiJob api.ActionWorkflowJob
+			DecodeJSON(t, resp, &apiJob)
+			assert.Equal(t, run.ID, apiJob.RunID)
+			assert.Equal(t, "queued", apiJob.Status)
+
+			// the job hasn't been picked by a runner
+			MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/jobs/%d/logs", apiURL, job.ID)).AddTokenAuth(token), http.StatusNotFound)
+		})
+
+		t.Run("CancelAndRerun", func(t *testing.T) {
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/jobs/%d/rerun", apiURL, job.ID)).AddTokenAuth(token), http.StatusUnprocessableEntity)
+
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/cancel", apiURL, run.ID)).AddTokenAuth(token), http.StatusAccepted)
+			assert.Equal(t, actions_model.StatusCancelled, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: job.ID}).Status)
+			assert.Equal(t, actions_model.StatusCancelled, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{ID: run.ID}).Status)
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/cancel", apiURL, run.ID)).AddTokenAuth(token), http.StatusConflict)
+
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/rerun", apiURL, run.ID)).AddTokenAuth(token), http.StatusCreated)
+			assert.Equal(t, actions_model.StatusWaiting, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: job.ID}).Status)
+
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/cancel", apiURL, run.ID)).AddTokenAuth(token), http.StatusAccepted)
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/jobs/%d/rerun", apiURL, job.ID)).AddTokenAuth(token), http.StatusCreated)
+			assert.Equal(t, actions_model.StatusWaiting, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: job.ID}).Status)
+
+			// the run doesn't need an approval
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/approve", apiURL, run.ID)).AddTokenAuth(token), http.StatusUnprocessableEntity)
+
+			// the users who can only read the repository cannot change the runs
+			readToken := getTokenForLoggedInUser(t, loginUser(t, "user2"), auth_model.AccessTokenScopeReadRepository)
+			MakeRequest(t, NewRequest(t, "POST", fmt.Sprintf("%s/runs/%d/cancel", apiURL, run.ID)).AddTokenAuth(readToken), http.StatusForbidden)
+		})
+
+		t.Run("EnableAndDisable", func(t *testing.T) {
+			MakeRequest(t, NewRequest(t, "PUT", apiURL+"/workflows/deploy.yml/disable").AddTokenAuth(token), http.StatusNoContent)
+			resp := MakeRequest(t, NewRequest(t, "GET", apiURL+"/workflows/deploy.yml").AddTokenAuth(token), http.StatusOK)
+			var workflow api.ActionWorkflow
+			DecodeJSON(t, resp, &workflow)
+			assert.Equal(t, "disabled_manually", workflow.State)
+			dispatch(t, "deploy.yml", nil, http.StatusUnprocessableEntity)
+
+			MakeRequest(t, NewRequest(t, "PUT", apiURL+"/workflows/deploy.yml/enable").AddTokenAuth(token), http.StatusNoContent)
+			dispatch(t, "deploy.yml", nil, http.StatusNoContent)
+			MakeRequest(t, NewRequest(t, "PUT", apiURL+"/workflows/missing.yml/enable").AddTokenAuth(token), http.StatusNotFound)
+		})
+
+		t.Run("Artifacts", func(t *testing.T) {
+			content := "artifact content"
+			art := &actions_model.ActionArtifact{
+				RunID:           run.ID,
+				RepoID:          repo.ID,
+				OwnerID:         repo.OwnerID,
+				CommitSHA:       run.CommitSHA,
+				StoragePath:     fmt.Sprintf("api-test/%d/dist.zip", run.ID),
+				FileSize:        int64(len(content)),
+				ContentEncoding: "application/zip",
+				ArtifactPath:    "dist.zip",
+				ArtifactName:    "dist",
+				Status:          int64(actions_model.ArtifactStatusUploadConfirmed),
+				ExpiredUnix:     timeutil.TimeStampNow().AddDuration(24 * time.Hour),
+			}
+			_, err := storage.ActionsArtifacts.Save(art.StoragePath, strings.NewReader(content), int64(len(content)))
+			require.NoError(t, err)
+			require.NoError(t, db.Insert(db.DefaultContext, art))
+
+			for _, link := range []string{apiURL + "/artifacts", fmt.Sprintf("%s/runs/%d/artifacts", apiURL, run.ID)} {
+				resp := MakeRequest(t, NewRequest(t, "GET", link).AddTokenAuth(token), http.StatusOK)
+				var artifacts api.ActionArtifactsResponse
+				DecodeJSON(t, resp, &artifacts)
+				require.EqualValues(t, 1, artifacts.TotalCount)
+				assert.Equal(t, art.ID, artifacts.Artifacts[0].ID)
+				assert.Equal(t, "dist", artifacts.Artifacts[0].Name)
+				assert.EqualValues(t, len(content), artifacts.Artifacts[0].SizeInBytes)
+				assert.Equal(t, run.ID, artifacts.Artifacts[0].WorkflowRun.ID)
+			}
+
+			resp := MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/artifacts/%d", apiURL, art.ID)).AddTokenAuth(token), http.StatusOK)
+			var artifact api.ActionArtifact
+			DecodeJSON(t, resp, &artifact)
+			assert.Equal(t, "dist", artifact.Name)
+			assert.False(t, artifact.Expired)
+
+			resp = MakeRequest(t, NewRequest(t, "GET", fmt.Sprintf("%s/artifacts/%d/zip", apiURL, art.ID)).AddTokenAuth(token), http.StatusOK)
+			assert.Equal(t, content, resp.Body.String())
+			MakeRequest(t, NewRequest(t, "GET", apiURL+"/artifacts/999999").AddTokenAuth(token), http.StatusNotFound)
+		})
+	})
+}
@@ -0,0 +1,229 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/tests"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageContainerReferrers(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	image := "signed"
+	tag := "v1"
+	imageURL := fmt.Sprintf("%sv2/%s/%s", setting.AppURL, user.Name, image)
+
+	digestOf := func(content string) string {
+		return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+	}
+
+	configContent := `{"architecture":"amd64","os":"linux"}`
+	layerContent := "layer"
+	manifestContent := fmt.Sprintf(`{"schemaVersion":2,"mediaType":"%s","config":{"mediaType":"%s","digest":"%s","size":%d},"layers":[{"mediaType":"%s","digest":"%s","size":%d}]}`,
+		oci.MediaTypeImageManifest, oci.MediaTypeImageConfig, digestOf(configContent), len(configContent), oci.MediaTypeImageLayerGzip, digestOf(layerContent), len(layerContent))
+	manifestDigest := digestOf(manifestContent)
+
+	emptyContent := "{}"
+
+	artifactManifest := func(artifactType, layerContent, subjectDigest string, subjectSize int) string {
+		return fmt.Sprintf(`{"schemaVersion":2,"mediaType":"%s","artifactType":"%s","config":{"mediaType":"%s","digest":"%s","size":%d},"layers":[{"mediaType":"application/octet-stream","digest":"%s","size":%d}],"subject":{"mediaType":"%s","digest":"%s","size":%d},"annotations":{"org.opencontainers.image.created":"2024-01-01T00:00:00Z"}}`,
+			oci.MediaTypeImageManifest, artifactType, oci.MediaTypeEmptyJSON, digestOf(emptyContent), len(emptyContent), digestOf(layerContent), len(layerContent), oci.MediaTypeImageManifest, subjectDigest, subjectSize)
+	}
+
+	signatureType := "application/vnd.cncf.notary.signature"
+	signatureContent := "signature"
+	signatureManifest := artifactManifest(signatureType, signatureContent, manifestDigest, len(manifestContent))
+	signatureDigest := digestOf(signatureManifest)
+
+	sbomType := "application/spdx+json"
+	sbomContent := `{"spdxVersion":"SPDX-2.3"}`
+	sbomManifest := artifactManifest(sbomType, sbomContent, manifestDigest, len(manifestContent))
+	sbomDigest := digestOf(sbomManifest)
+
+	sbomSignatureContent := "sbom signature"
+	sbomSignatureManifest := artifactManifest(signatureType, sbomSignatureContent, sbomDigest, len(sbomManifest))
+	sbomSignatureDigest := digestOf(sbomSignatureManifest)
+
+	type TokenResponse struct {
+		Token string `json:"token"`
+	}
+
+	req := NewRequest(t, "GET", fmt.Sprintf("%sv2/token", setting.AppURL)).
+		AddBasicAuth(user.Name)
+	resp := MakeRequest(t, req, http.StatusOK)
+	tokenResponse := &TokenResponse{}
+	DecodeJSON(t, resp, &tokenResponse)
+	userToken := "Bearer " + tokenResponse.Token
+
+	uploadBlob := func(t *testing.T, content string) {
+		req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", imageURL, digestOf(content)), strings.NewReader(content)).
+			AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusCreated)
+	}
+
+	uploadManifest := func(t *testing.T, reference, content string) *http.Response {
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", imageURL, reference), strings.NewReader(content)).
+			AddTokenAuth(userToken).
+			SetHeader("Content-Type", oci.MediaTypeImageManifest)
+		return MakeRequest(t, req, http.StatusCreated).Result()
+	}
+
+	getReferrers := func(t *testing.T, subject, query string) (*oci.Index, *http.Response) {
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/referrers/%s%s", imageURL, subject, query)).
+			AddTokenAuth(userToken)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, oci.MediaTypeImageIndex, resp.Header().Get("Content-Type"))
+
+		var index oci.Index
+		DecodeJSON(t, resp, &index)
+		assert.EqualValues(t, 2, index.SchemaVersion)
+		assert.Equal(t, oci.MediaTypeImageIndex, index.MediaType)
+		return &index, resp.Result()
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		for _, content := range []string{configContent, layerContent, emptyContent, signatureContent, sbomContent, sbomSignatureContent} {
+			uploadBlob(t, content)
+		}
+
+		resp := uploadManifest(t, tag, manifestContent)
+		assert.Empty(t, resp.Header.Get("OCI-Subject"))
+		uploadManifest(t, manifestDigest, manifestContent)
+
+		resp = uploadManifest(t, signatureDigest, signatureManifest)
+		assert.Equal(t, manifestDigest, resp.Header.Get("OCI-Subject"))
+		uploadManifest(t, sbomDigest, sbomManifest)
+		resp = uploadManifest(t, sbomSignatureDigest, sbomSignatureManifest)
+		assert.Equal(t, sbomDigest, resp.Header.Get("OCI-Subject"))
+
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeContainer, image, signatureDigest)
+		assert.NoError(t, err)
+		pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+		assert.NoError(t, err)
+
+		metadata := pd.Metadata.(*container_module.Metadata)
+		assert.Equal(t, manifestDigest, metadata.Subject)
+		assert.Equal(t, signatureType, metadata.ArtifactType)
+		assert.Equal(t, container_module.ArtifactKindSignature, metadata.ArtifactKind())
+		assert.False(t, metadata.IsTagged)
+	})
+
+	t.Run("Referrers", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		index, resp := getReferrers(t, manifestDigest, "")
+		assert.Empty(t, resp.Header.Get("OCI-Filters-Applied"))
+		assert.Len(t, index.Manifests, 2)
+
+		descriptors := make(map[string]oci.Descriptor)
+		for _, d := range index.Manifests {
+			descriptors[string(d.Digest)] = d
+		}
+
+		assert.Contains(t, descriptors, signatureDigest)
+		assert.Equal(t, signatureType, descriptors[signatureDigest].ArtifactType)
+		assert.Equal(t, oci.MediaTypeImageManifest, descriptors[signatureDigest].MediaType)
+		assert.EqualValues(t, len(signatureManifest), descriptors[signatureDigest].Size)
+		assert.Equal(t, "2024-01-01T00:00:00Z", descriptors[signatureDigest].Annotations["org.opencontainers.image.created"])
+		assert.Contains(t, descriptors, sbomDigest)
+		assert.Equal(t, sbomType, descriptors[sbomDigest].ArtifactType)
+
+		index, _ = getReferrers(t, sbomDigest, "")
+		assert.Len(t, index.Manifests, 1)
+		assert.EqualValues(t, sbomSignatureDigest, index.Manifests[0].Digest)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		index, resp := getReferrers(t, manifestDigest, "?artifactType="+url.QueryEscape(sbomType))
+		assert.Equal(t, "artifactType", resp.Header.Get("OCI-Filters-Applied"))
+		assert.Len(t, index.Manifests, 1)
+		assert.EqualValues(t, sbomDigest, index.Manifests[0].Digest)
+
+		index, _ = getReferrers(t, manifestDigest, "?artifactType=application/unknown")
+		assert.Empty(t, index.Manifests)
+	})
+
+	t.Run("UnknownSubject", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		index, _ := getReferrers(t, digestOf("unknown"), "")
+		assert.NotNil(t, index.Manifests)
+		assert.Empty(t, index.Manifests)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/referrers/invalid", imageURL)).
+			AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusBadRequest)
+	})
+
+	t.Run("View", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		session := loginUser(t, user.Name)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/container/%s/%s", user.Name, image, tag))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Equal(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`a[href$="/%s"]`, signatureDigest)).Length())
+		assert.Equal(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`a[href$="/%s"]`, sbomDigest)).Length())
+		assert.Contains(t, htmlDoc.doc.Find("table").Text(), "SBOM")
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/container/%s/%s", user.Name, image, signatureDigest))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Equal(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`a[href$="/%s"]`, manifestDigest)).Length())
+	})
+
+	t.Run("DeleteSubject", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		// the manifest is still available by its digest
+		req := NewRequest(t, "DELETE", fmt.Sprintf("%s/manifests/%s", imageURL, tag)).
+			AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusAccepted)
+
+		index, _ := getReferrers(t, manifestDigest, "")
+		assert.Len(t, index.Manifests, 2)
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/manifests/%s", imageURL, manifestDigest)).
+			AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusAccepted)
+
+		index, _ = getReferrers(t, manifestDigest, "")
+		assert.Empty(t, index.Manifests)
+
+		for _, d := range []string{signatureDigest, sbomDigest, sbomSignatureDigest} {
+			_, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeContainer, image, d)
+			assert.ErrorIs(t, err, packages_model.ErrPackageNotExist)
+
+			req = NewRequest(t, "HEAD", fmt.Sprintf("%s/manifests/%s", imageURL, d)).
+				AddTokenAuth(userToken)
+			MakeRequest(t, req, http.StatusNotFound)
+		}
+	})
+}
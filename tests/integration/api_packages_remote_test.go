@@ -0,0 +1,555 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/tests"
+
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteRegistry is a stand-in for the upstream registries, it serves static content and counts the requests
+type remoteRegistry struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	files     map[string]string
+	headers   map[string]http.Header
+	requests  map[string]int
+	available bool
+}
+
+func newRemoteRegistry() *remoteRegistry {
+	r := &remoteRegistry{
+		files:     make(map[string]string),
+		headers:   make(map[string]http.Header),
+		requests:  make(map[string]int),
+		available: true,
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests[req.URL.Path]++
+
+		if !r.available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		// the container registry asks for a token like Docker Hub
+		if strings.HasPrefix(req.URL.Path, "/v2/") {
+			if req.Header.Get("Authorization") != "Bearer remote-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="remote",scope="repository:test:pull"`, r.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if req.URL.Path == "/token" {
+			_, _ = w.Write([]byte(`{"token":"remote-token"}`))
+			return
+		}
+
+		content, ok := r.files[req.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range r.headers[req.URL.Path] {
+			w.Header()[k] = v
+		}
+		if req.Method == http.MethodHead {
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	return r
+}
+
+func (r *remoteRegistry) setFile(path, content string, header http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[path] = content
+	r.headers[path] = header
+}
+
+func (r *remoteRegistry) setAvailable(available bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.available = available
+}
+
+func (r *remoteRegistry) requestCount(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[path]
+}
+
+func TestPackageRemote(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.Packages.RemoteAllowedHostList, "loopback")()
+	defer test.MockVariableValue(&setting.Packages.RemoteMetadataTTL, time.Hour)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	session := loginUser(t, user.Name)
+
+	remote := newRemoteRegistry()
+	defer remote.Close()
+
+	addRemote := func(t *testing.T, packageType packages_model.Type, url string) {
+		req := NewRequestWithValues(t, "POST", "/user/settings/packages/remotes/add", map[string]string{
+			"_csrf": GetUserCSRFToken(t, session),
+			"type":  string(packageType),
+			"url":   url,
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+	}
+
+	t.Run("Settings", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		t.Run("HostNotAllowed", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+			defer test.MockVariableValue(&setting.Packages.RemoteAllowedHostList, "external")()
+
+			addRemote(t, packages_model.TypeNpm, remote.URL)
+
+			has, err := packages_model.HasOwnerRemoteForPackageType(db.DefaultContext, user.ID, packages_model.TypeNpm)
+			assert.NoError(t, err)
+			assert.False(t, has)
+		})
+
+		for _, pt := range packages_model.RemoteTypes {
+			addRemote(t, pt, remote.URL)
+
+			pr, err := packages_model.GetRemoteByOwnerAndType(db.DefaultContext, user.ID, pt)
+			assert.NoError(t, err)
+			assert.Equal(t, remote.URL, pr.URL)
+		}
+
+		t.Run("Duplicate", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			addRemote(t, packages_model.TypeNpm, remote.URL+"/other")
+
+			prs, err := packages_model.GetRemotesByOwner(db.DefaultContext, user.ID)
+			assert.NoError(t, err)
+			assert.Len(t, prs, len(packages_model.RemoteTypes))
+		})
+
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/packages"), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), remote.URL)
+	})
+
+	getVersion := func(t *testing.T, packageType packages_model.Type, name, version string) *packages_model.PackageVersion {
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packageType, name, version)
+		require.NoError(t, err)
+		return pv
+	}
+
+	t.Run("Npm", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		packageName := "@scope/remote-package"
+		packageVersion := "1.2.3"
+		filename := "remote-package-1.2.3.tgz"
+		data, _ := base64.StdEncoding.DecodeString("H4sIAAAAAAAA/ytITM5OTE/VL4DQelnF+XkMVAYGBgZmJiYK2MRBwNDcSIHB2NTMwNDQzMwAqA7IMDUxA9LUdgg2UFpcklgEdAql5kD8ogCnhwio5lJQUMpLzE1VslJQcihOzi9I1S9JLS7RhSYIJR2QgrLUouLM/DyQGkM9Az1D3YIiqExKanFyUWZBCVQ2BKhVwQVJDKwosbQkI78IJO/tZ+LsbRykxFXLNdA+HwWjYBSMgpENACgAbtAACAAA")
+		hash := sha512.Sum512(data)
+
+		remote.setFile("/tarballs/"+filename, string(data), nil)
+		// the stand-in server sees the decoded path
+		remote.setFile("/"+packageName, `{
+			"name": "`+packageName+`",
+			"description": "Remote Description",
+			"dist-tags": {"latest": "`+packageVersion+`"},
+			"versions": {
+				"`+packageVersion+`": {
+					"name": "`+packageName+`",
+					"version": "`+packageVersion+`",
+					"description": "Remote Description",
+					"repository": "https://example.com/remote.git",
+					"dist": {
+						"integrity": "sha512-`+base64.StdEncoding.EncodeToString(hash[:])+`",
+						"tarball": "`+remote.URL+`/tarballs/`+filename+`"
+					}
+				},
+				"0.0.1": {"name": "`+packageName+`", "version": "0.0.1", "license": {"type": "MIT"}}
+			}
+		}`, nil)
+
+		root := fmt.Sprintf("/api/packages/%s/npm/%s", user.Name, strings.ReplaceAll(packageName, "/", "%2f"))
+		tarballURL := fmt.Sprintf("%s/-/%s/%s", root, packageVersion, filename)
+
+		t.Run("Metadata", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", root)
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result map[string]any
+			DecodeJSON(t, resp, &result)
+
+			assert.Equal(t, map[string]any{"latest": packageVersion}, result["dist-tags"])
+			versions := result["versions"].(map[string]any)
+			assert.Len(t, versions, 1)
+			dist := versions[packageVersion].(map[string]any)["dist"].(map[string]any)
+			assert.True(t, strings.HasPrefix(dist["tarball"].(string), setting.AppURL+"api/packages/"+user.Name+"/npm/"))
+			assert.True(t, strings.HasSuffix(dist["tarball"].(string), "/-/"+packageVersion+"/"+filename))
+
+			// the metadata doesn't cache the package
+			pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeNpm, packageName)
+			assert.NoError(t, err)
+			assert.Empty(t, pvs)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", tarballURL)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, data, resp.Body.Bytes())
+
+			pv := getVersion(t, packages_model.TypeNpm, packageName, packageVersion)
+			pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+			assert.NoError(t, err)
+			assert.EqualValues(t, user_model.GhostUserID, pd.Creator.ID)
+			assert.Len(t, pd.Files, 1)
+			assert.Equal(t, filename, pd.Files[0].File.Name)
+			assert.Equal(t, 1, remote.requestCount("/tarballs/"+filename))
+		})
+
+		t.Run("DownloadByName", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/-/%s", root, filename))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, data, resp.Body.Bytes())
+		})
+
+		t.Run("InvalidIntegrity", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			remote.setFile("/broken-package", `{"name": "broken-package", "versions": {"1.0.0": {"name": "broken-package", "version": "1.0.0", "dist": {"integrity": "sha512-AAAA", "tarball": "`+remote.URL+`/tarballs/`+filename+`"}}}}`, nil)
+
+			req := NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/broken-package/-/1.0.0/broken-package-1.0.0.tgz", user.Name))
+			MakeRequest(t, req, http.StatusInternalServerError)
+
+			_, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeNpm, "broken-package", "1.0.0")
+			assert.ErrorIs(t, err, packages_model.ErrPackageNotExist)
+		})
+	})
+
+	t.Run("PyPI", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		packageName := "remote-package"
+		packageVersion := "1.0.1"
+		filename := "remote_package-1.0.1.tar.gz"
+		content := "pypi package content"
+		hash := sha256.Sum256([]byte(content))
+		hashSHA256 := hex.EncodeToString(hash[:])
+
+		remote.setFile("/files/"+filename, content, nil)
+		remote.setFile("/pypi/"+packageName+"/json", `{
+			"info": {"author": "Remote Author", "summary": "Remote Summary", "license": "MIT"},
+			"releases": {
+				"`+packageVersion+`": [{"filename": "`+filename+`", "url": "`+remote.URL+`/files/`+filename+`", "digests": {"sha256": "`+hashSHA256+`"}, "requires_python": ">=3.8"}],
+				"0.9": [{"filename": "yanked.tar.gz", "url": "`+remote.URL+`/files/yanked.tar.gz", "digests": {"sha256": "`+hashSHA256+`"}, "yanked": true}]
+			}
+		}`, nil)
+
+		root := fmt.Sprintf("/api/packages/%s/pypi", user.Name)
+
+		t.Run("Index", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/simple/%s", root, packageName))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			nodes := htmlDoc.doc.Find("a")
+			assert.Equal(t, 1, nodes.Length())
+			href, _ := nodes.Attr("href")
+			assert.Equal(t, fmt.Sprintf("%s%s/files/%s/%s/%s#sha256=%s", setting.AppURL, root[1:], packageName, packageVersion, filename, hashSHA256), href)
+			requiresPython, _ := nodes.Attr("data-requires-python")
+			assert.Equal(t, ">=3.8", requiresPython)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/files/%s/%s/%s", root, packageName, packageVersion, filename))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, content, resp.Body.String())
+
+			pv := getVersion(t, packages_model.TypePyPI, packageName, packageVersion)
+			pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+			assert.NoError(t, err)
+			assert.Len(t, pd.Files, 1)
+			assert.Equal(t, hashSHA256, pd.Files[0].Blob.HashSHA256)
+
+			// the cached file is listed once
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/simple/%s", root, packageName))
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, 1, strings.Count(resp.Body.String(), filename+"#sha256"))
+		})
+	})
+
+	t.Run("Maven", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		groupID := "com.gitea"
+		artifactID := "remote-project"
+		packageVersion := "1.0.0"
+		filename := fmt.Sprintf("%s-%s.jar", artifactID, packageVersion)
+		content := "maven package content"
+		hash := sha1.Sum([]byte(content))
+
+		remotePath := "/com/gitea/remote-project"
+		remote.setFile(remotePath+"/maven-metadata.xml", `<?xml version="1.0" encoding="UTF-8"?>
+<metadata><groupId>com.gitea</groupId><artifactId>remote-project</artifactId><versioning><latest>1.0.0</latest><release>1.0.0</release><versions><version>1.0.0</version></versions></versioning></metadata>`, nil)
+		remote.setFile(remotePath+"/1.0.0/"+filename, content, nil)
+		remote.setFile(remotePath+"/1.0.0/"+filename+".sha1", hex.EncodeToString(hash[:])+"  "+filename, nil)
+		remote.setFile(remotePath+"/1.0.0/broken.jar", content, nil)
+		remote.setFile(remotePath+"/1.0.0/broken.jar.sha1", "0000", nil)
+
+		root := fmt.Sprintf("/api/packages/%s/maven/com/gitea/remote-project", user.Name)
+
+		t.Run("Metadata", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", root+"/maven-metadata.xml")
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Contains(t, resp.Body.String(), "<version>1.0.0</version>")
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s.sha1", root, packageVersion, filename))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, hex.EncodeToString(hash[:]), resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", root, packageVersion, filename))
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, content, resp.Body.String())
+
+			getVersion(t, packages_model.TypeMaven, groupID+"-"+artifactID, packageVersion)
+			assert.Equal(t, 1, remote.requestCount(remotePath+"/1.0.0/"+filename))
+		})
+
+		t.Run("InvalidChecksum", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/broken.jar", root, packageVersion))
+			MakeRequest(t, req, http.StatusInternalServerError)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/missing.jar", root, packageVersion))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("SizeLimit", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			remote.setFile(remotePath+"/1.0.0/large.jar", strings.Repeat("a", 1024), nil)
+
+			t.Run("Type", func(t *testing.T) {
+				defer test.MockVariableValue(&setting.Packages.LimitSizeMaven, 512)()
+
+				req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/large.jar", root, packageVersion))
+				MakeRequest(t, req, http.StatusForbidden)
+			})
+
+			t.Run("Owner", func(t *testing.T) {
+				defer test.MockVariableValue(&setting.Packages.LimitTotalOwnerSize, 512)()
+
+				req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/large.jar", root, packageVersion))
+				MakeRequest(t, req, http.StatusForbidden)
+			})
+
+			pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeMaven, groupID+"-"+artifactID, packageVersion)
+			require.NoError(t, err)
+			_, err = packages_model.GetFileForVersionByName(db.DefaultContext, pv.ID, "large.jar", packages_model.EmptyFileKey)
+			assert.ErrorIs(t, err, packages_model.ErrPackageFileNotExist)
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/large.jar", root, packageVersion))
+			MakeRequest(t, req, http.StatusOK)
+		})
+	})
+
+	t.Run("Container", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		image := "remote-image"
+		tag := "latest"
+
+		blobDigest := "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"
+		blobContent, _ := base64.StdEncoding.DecodeString(`H4sIAAAJbogA/2IYBaNgFIxYAAgAAP//Lq+17wAEAAA=`)
+
+		configDigest := "sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744c406903766b938f0d"
+		configContent := `{"architecture":"amd64","config":{"Env":["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],"Cmd":["/true"],"ArgsEscaped":true,"Image":"sha256:9bd8b88dc68b80cffe126cc820e4b52c6e558eb3b37680bfee8e5f3ed7b8c257"},"container":"b89fe92a887d55c0961f02bdfbfd8ac3ddf66167db374770d2d9e9fab3311510","container_config":{"Hostname":"b89fe92a887d","Env":["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],"Cmd":["/bin/sh","-c","#(nop) ","CMD [\"/true\"]"],"ArgsEscaped":true,"Image":"sha256:9bd8b88dc68b80cffe126cc820e4b52c6e558eb3b37680bfee8e5f3ed7b8c257"},"created":"2022-01-01T00:00:00.000000000Z","docker_version":"20.10.12","history":[{"created":"2022-01-01T00:00:00.000000000Z","created_by":"/bin/sh -c #(nop) COPY file:0e7589b0c800daaf6fa460d2677101e4676dd9491980210cb345480e513f3602 in /true "},{"created":"2022-01-01T00:00:00.000000001Z","created_by":"/bin/sh -c #(nop)  CMD [\"/true\"]","empty_layer":true}],"os":"linux","rootfs":{"type":"layers","diff_ids":["sha256:0ff3b91bdf21ecdf2f2f3d4372c2098a14dbe06cd678e8f0a85fd4902d00e2e2"]}}`
+
+		manifestDigest := "sha256:4f10484d1c1bb13e3956b4de1cd42db8e0f14a75be1617b60f2de3cd59c803c6"
+		manifestContent := `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744c406903766b938f0d","size":1069},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4","size":32}]}`
+
+		updatedManifestDigest := "sha256:4305f5f5572b9a426b88909b036e52ee3cf3d7b9c1b01fac840e90747f56623d"
+		updatedManifestContent := `{"schemaVersion":2,"mediaType":"` + oci.MediaTypeImageManifest + `","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744c406903766b938f0d","size":1069},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4","size":32}]}`
+
+		remoteImagePath := "/v2/" + image
+		remote.setFile(remoteImagePath+"/blobs/"+blobDigest, string(blobContent), nil)
+		remote.setFile(remoteImagePath+"/blobs/"+configDigest, configContent, nil)
+		setManifest := func(digest, content, mediaType string) {
+			header := http.Header{"Content-Type": []string{mediaType}, "Docker-Content-Digest": []string{digest}}
+			remote.setFile(remoteImagePath+"/manifests/"+tag, content, header)
+			remote.setFile(remoteImagePath+"/manifests/"+digest, content, header)
+		}
+		setManifest(manifestDigest, manifestContent, "application/vnd.docker.distribution.manifest.v2+json")
+
+		req := NewRequest(t, "GET", setting.AppURL+"v2/token").AddBasicAuth(user.Name)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var tokenResponse struct {
+			Token string `json:"token"`
+		}
+		DecodeJSON(t, resp, &tokenResponse)
+		token := "Bearer " + tokenResponse.Token
+
+		root := fmt.Sprintf("/v2/%s/%s", user.Name, image)
+
+		t.Run("Manifest", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", root+"/manifests/"+tag).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, manifestDigest, resp.Header().Get("Docker-Content-Digest"))
+			assert.Equal(t, manifestContent, resp.Body.String())
+
+			pv := getVersion(t, packages_model.TypeContainer, image, tag)
+			pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+			assert.NoError(t, err)
+			assert.True(t, pd.Metadata.(*container_module.Metadata).IsTagged)
+			assert.Len(t, pd.Files, 3)
+
+			req = NewRequest(t, "GET", root+"/blobs/"+blobDigest).AddTokenAuth(token)
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, blobContent, resp.Body.Bytes())
+		})
+
+		t.Run("Offline", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			remote.setAvailable(false)
+			defer remote.setAvailable(true)
+			defer test.MockVariableValue(&setting.Packages.RemoteMetadataTTL, 0)()
+
+			req := NewRequest(t, "GET", root+"/manifests/"+tag).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, manifestDigest, resp.Header().Get("Docker-Content-Digest"))
+
+			// the dist tags are only known from the cached remote metadata
+			req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/@scope%%2fremote-package", user.Name))
+			resp = MakeRequest(t, req, http.StatusOK)
+			var result map[string]any
+			DecodeJSON(t, resp, &result)
+			assert.Equal(t, map[string]any{"latest": "1.2.3"}, result["dist-tags"])
+		})
+
+		t.Run("TagUpdate", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			setManifest(updatedManifestDigest, updatedManifestContent, oci.MediaTypeImageManifest)
+
+			// the tag is not refreshed before the metadata TTL expires
+			req := NewRequest(t, "HEAD", root+"/manifests/"+tag).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, manifestDigest, resp.Header().Get("Docker-Content-Digest"))
+
+			defer test.MockVariableValue(&setting.Packages.RemoteMetadataTTL, 0)()
+
+			req = NewRequest(t, "HEAD", root+"/manifests/"+tag).AddTokenAuth(token)
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, updatedManifestDigest, resp.Header().Get("Docker-Content-Digest"))
+		})
+
+		t.Run("PushedTag", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+			defer test.MockVariableValue(&setting.Packages.RemoteMetadataTTL, 0)()
+
+			pushedTag := "pushed"
+			setManifest(manifestDigest, manifestContent, "application/vnd.docker.distribution.manifest.v2+json")
+			remote.setFile(remoteImagePath+"/manifests/"+pushedTag, manifestContent, nil)
+
+			req := NewRequestWithBody(t, "PUT", root+"/manifests/"+pushedTag, strings.NewReader(updatedManifestContent)).
+				AddTokenAuth(token).
+				SetHeader("Content-Type", oci.MediaTypeImageManifest)
+			MakeRequest(t, req, http.StatusCreated)
+
+			// tags pushed to the registry are never replaced by the remote tags
+			req = NewRequest(t, "HEAD", root+"/manifests/"+pushedTag).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, updatedManifestDigest, resp.Header().Get("Docker-Content-Digest"))
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", root+"/manifests/missing").AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+	})
+
+	t.Run("ConnectionNotAllowed", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		defer test.MockVariableValue(&setting.Packages.RemoteAllowedHostList, "external")()
+
+		remote.setFile("/blocked-package", `{"name": "blocked-package", "versions": {}}`, nil)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/blocked-package", user.Name))
+		MakeRequest(t, req, http.StatusBadGateway)
+		assert.Equal(t, 0, remote.requestCount("/blocked-package"))
+	})
+
+	t.Run("DeleteRemote", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		pr, err := packages_model.GetRemoteByOwnerAndType(db.DefaultContext, user.ID, packages_model.TypeNpm)
+		assert.NoError(t, err)
+
+		req := NewRequestWithValues(t, "POST", fmt.Sprintf("/user/settings/packages/remotes/%d/delete", pr.ID), map[string]string{
+			"_csrf": GetUserCSRFToken(t, session),
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+
+		unittest.AssertNotExistsBean(t, &packages_model.PackageRemote{ID: pr.ID})
+		unittest.AssertNotExistsBean(t, &packages_model.PackageRemoteMetadata{RemoteID: pr.ID})
+
+		// the cached package is kept
+		getVersion(t, packages_model.TypeNpm, "@scope/remote-package", "1.2.3")
+	})
+}
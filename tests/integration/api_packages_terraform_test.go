@@ -0,0 +1,385 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageTerraform(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	token := "Bearer " + getUserToken(t, user.Name, auth_model.AccessTokenScopeWritePackage)
+
+	root := fmt.Sprintf("/api/packages/%s/terraform", user.Name)
+
+	var modulesURL, providersURL string
+
+	t.Run("ServiceDiscovery", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/.well-known/terraform.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var services map[string]string
+		DecodeJSON(t, resp, &services)
+
+		assert.Equal(t, setting.AppURL+"api/packages/-/terraform/modules/v1/", services["modules.v1"])
+		assert.Equal(t, setting.AppURL+"api/packages/-/terraform/providers/v1/", services["providers.v1"])
+
+		modulesURL = services["modules.v1"] + user.Name
+		providersURL = services["providers.v1"] + user.Name
+	})
+
+	t.Run("Module", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		moduleName := "vpc"
+		moduleSystem := "aws"
+		moduleVersion := "1.2.0"
+
+		createArchive := func(files map[string]string) []byte {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(zw)
+			for name, content := range files {
+				tw.WriteHeader(&tar.Header{
+					Name: name,
+					Mode: 0o600,
+					Size: int64(len(content)),
+				})
+				tw.Write([]byte(content))
+			}
+			tw.Close()
+			zw.Close()
+			return buf.Bytes()
+		}
+
+		content := createArchive(map[string]string{
+			"README.md": "# VPC Module",
+			"main.tf": `variable "cidr" {
+  description = "CIDR block of the VPC"
+}
+
+output "vpc_id" {
+  value = "id"
+}`,
+		})
+
+		moduleURL := fmt.Sprintf("%s/modules/%s/%s/%s", root, moduleName, moduleSystem, moduleVersion)
+
+		t.Run("Upload", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", moduleURL, bytes.NewReader(content))
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/modules/%s/%s/%s", root, moduleName, moduleSystem, "latest"), bytes.NewReader(content)).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", moduleURL, bytes.NewReader(createArchive(map[string]string{"README.md": ""}))).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", moduleURL, bytes.NewReader(content)).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusCreated)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+			assert.NoError(t, err)
+			assert.Len(t, pvs, 1)
+
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+			assert.Equal(t, "vpc/aws", pd.Package.Name)
+			assert.Equal(t, moduleVersion, pd.Version.Version)
+
+			metadata := pd.Metadata.(*terraform_module.Metadata)
+			assert.Equal(t, terraform_module.KindModule, metadata.Kind)
+			assert.Equal(t, "# VPC Module", metadata.Readme)
+			assert.Len(t, metadata.Variables, 1)
+			assert.Equal(t, "cidr", metadata.Variables[0].Name)
+			assert.True(t, metadata.Variables[0].Required)
+			assert.Len(t, metadata.Outputs, 1)
+
+			assert.Len(t, pd.Files, 1)
+			assert.Equal(t, "vpc-aws-1.2.0.tar.gz", pd.Files[0].File.Name)
+			assert.True(t, pd.Files[0].File.IsLead)
+
+			req = NewRequestWithBody(t, "PUT", moduleURL, bytes.NewReader(content)).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusConflict)
+		})
+
+		t.Run("Versions", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/versions", modulesURL, moduleName, moduleSystem))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result struct {
+				Modules []struct {
+					Versions []struct {
+						Version string `json:"version"`
+					} `json:"versions"`
+				} `json:"modules"`
+			}
+			DecodeJSON(t, resp, &result)
+
+			assert.Len(t, result.Modules, 1)
+			assert.Len(t, result.Modules[0].Versions, 1)
+			assert.Equal(t, moduleVersion, result.Modules[0].Versions[0].Version)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/versions", modulesURL, moduleName, "azurerm"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s/download", modulesURL, moduleName, moduleSystem, "0.0.1"))
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s/download", modulesURL, moduleName, moduleSystem, moduleVersion))
+			resp := MakeRequest(t, req, http.StatusNoContent)
+
+			location := resp.Header().Get("X-Terraform-Get")
+			assert.Equal(t, fmt.Sprintf("%sapi/packages/%s/terraform/modules/vpc/aws/1.2.0/vpc-aws-1.2.0.tar.gz", setting.AppURL, user.Name), location)
+
+			req = NewRequest(t, "GET", location)
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, content, resp.Body.Bytes())
+		})
+
+		t.Run("View", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			session := loginUser(t, user.Name)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+			assert.NoError(t, err)
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+
+			req := NewRequest(t, "GET", pd.VersionWebLink())
+			resp := session.MakeRequest(t, req, http.StatusOK)
+
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			code := htmlDoc.doc.Find("pre code").Text()
+			assert.Contains(t, code, fmt.Sprintf(`/%s/vpc/aws"`, user.LowerName))
+			assert.Contains(t, htmlDoc.doc.Find("table").Text(), "CIDR block of the VPC")
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "DELETE", moduleURL)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", moduleURL).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+			assert.NoError(t, err)
+			assert.Empty(t, pvs)
+
+			req = NewRequest(t, "DELETE", moduleURL).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+	})
+
+	t.Run("Provider", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		providerType := "dns"
+		providerVersion := "2.0.0"
+		prefix := fmt.Sprintf("terraform-provider-%s_%s_", providerType, providerVersion)
+		archiveName := prefix + "linux_amd64.zip"
+		archiveContent := []byte("provider archive")
+		checksumsContent := []byte(fmt.Sprintf("%x  %s\n", sha256.Sum256(archiveContent), archiveName))
+
+		entity, err := openpgp.NewEntity("Gitea", "", user.Email, nil)
+		assert.NoError(t, err)
+
+		// the self-signatures are created on the first private serialization
+		assert.NoError(t, entity.SerializePrivate(io.Discard, nil))
+
+		var publicKey bytes.Buffer
+		w, err := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, entity.Serialize(w))
+		assert.NoError(t, w.Close())
+
+		var signature bytes.Buffer
+		assert.NoError(t, openpgp.DetachSign(&signature, entity, bytes.NewReader(checksumsContent), nil))
+
+		versionURL := fmt.Sprintf("%s/providers/%s/%s", root, providerType, providerVersion)
+
+		upload := func(t *testing.T, filename string, content []byte, expectedStatus int) {
+			req := NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/%s", versionURL, filename), bytes.NewReader(content)).
+				AddTokenAuth(token)
+			MakeRequest(t, req, expectedStatus)
+		}
+
+		t.Run("Upload", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			upload(t, "terraform-provider-other_2.0.0_linux_amd64.zip", archiveContent, http.StatusBadRequest)
+			upload(t, prefix+"SHA256SUMS.sig", signature.Bytes(), http.StatusBadRequest)
+
+			upload(t, archiveName, archiveContent, http.StatusCreated)
+			upload(t, archiveName, archiveContent, http.StatusConflict)
+
+			upload(t, prefix+"SHA256SUMS", []byte(fmt.Sprintf("%x  %s\n", sha256.Sum256([]byte("other")), archiveName)), http.StatusBadRequest)
+			upload(t, prefix+"SHA256SUMS", checksumsContent, http.StatusCreated)
+			upload(t, prefix+"darwin_arm64.zip", []byte("not in checksums"), http.StatusBadRequest)
+
+			upload(t, prefix+"manifest.json", []byte(`{"version":1,"metadata":{"protocol_versions":["6.0"]}}`), http.StatusCreated)
+			// the rejected manifest doesn't change the protocols
+			upload(t, prefix+"manifest.json", []byte(`{"version":1,"metadata":{"protocol_versions":["5.0"]}}`), http.StatusConflict)
+
+			// the signing key must belong to the uploader
+			upload(t, prefix+"SHA256SUMS.sig", signature.Bytes(), http.StatusBadRequest)
+
+			_, err := asymkey_model.AddGPGKey(db.DefaultContext, user.ID, publicKey.String(), "", "")
+			assert.NoError(t, err)
+
+			upload(t, prefix+"SHA256SUMS.sig", []byte("invalid"), http.StatusBadRequest)
+			upload(t, prefix+"SHA256SUMS.sig", signature.Bytes(), http.StatusCreated)
+
+			pv, err := packages.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages.TypeTerraform, providerType, providerVersion)
+			assert.NoError(t, err)
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pv)
+			assert.NoError(t, err)
+
+			metadata := pd.Metadata.(*terraform_module.Metadata)
+			assert.Equal(t, terraform_module.KindProvider, metadata.Kind)
+			assert.Equal(t, []string{"6.0"}, metadata.Protocols)
+			assert.Len(t, pd.Files, 4)
+		})
+
+		t.Run("Versions", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/versions", providersURL, providerType))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result struct {
+				Versions []struct {
+					Version   string   `json:"version"`
+					Protocols []string `json:"protocols"`
+					Platforms []struct {
+						OS   string `json:"os"`
+						Arch string `json:"arch"`
+					} `json:"platforms"`
+				} `json:"versions"`
+			}
+			DecodeJSON(t, resp, &result)
+
+			assert.Len(t, result.Versions, 1)
+			assert.Equal(t, providerVersion, result.Versions[0].Version)
+			assert.Equal(t, []string{"6.0"}, result.Versions[0].Protocols)
+			assert.Len(t, result.Versions[0].Platforms, 1)
+			assert.Equal(t, "linux", result.Versions[0].Platforms[0].OS)
+			assert.Equal(t, "amd64", result.Versions[0].Platforms[0].Arch)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/download/darwin/arm64", providersURL, providerType, providerVersion))
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/download/linux/amd64", providersURL, providerType, providerVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result struct {
+				Protocols           []string `json:"protocols"`
+				OS                  string   `json:"os"`
+				Arch                string   `json:"arch"`
+				Filename            string   `json:"filename"`
+				DownloadURL         string   `json:"download_url"`
+				SHASumsURL          string   `json:"shasums_url"`
+				SHASumsSignatureURL string   `json:"shasums_signature_url"`
+				SHASum              string   `json:"shasum"`
+				SigningKeys         struct {
+					GPGPublicKeys []struct {
+						KeyID      string `json:"key_id"`
+						ASCIIArmor string `json:"ascii_armor"`
+					} `json:"gpg_public_keys"`
+				} `json:"signing_keys"`
+			}
+			DecodeJSON(t, resp, &result)
+
+			assert.Equal(t, []string{"6.0"}, result.Protocols)
+			assert.Equal(t, "linux", result.OS)
+			assert.Equal(t, "amd64", result.Arch)
+			assert.Equal(t, archiveName, result.Filename)
+			assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(archiveContent)), result.SHASum)
+			assert.Len(t, result.SigningKeys.GPGPublicKeys, 1)
+			assert.Equal(t, entity.PrimaryKey.KeyIdString(), result.SigningKeys.GPGPublicKeys[0].KeyID)
+
+			keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(result.SigningKeys.GPGPublicKeys[0].ASCIIArmor))
+			assert.NoError(t, err)
+
+			download := func(t *testing.T, u string) []byte {
+				req := NewRequest(t, "GET", u)
+				return MakeRequest(t, req, http.StatusOK).Body.Bytes()
+			}
+
+			assert.Equal(t, archiveContent, download(t, result.DownloadURL))
+			checksums := download(t, result.SHASumsURL)
+			assert.Equal(t, checksumsContent, checksums)
+			_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(checksums), bytes.NewReader(download(t, result.SHASumsSignatureURL)))
+			assert.NoError(t, err)
+		})
+
+		t.Run("View", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/terraform/%s/%s", user.Name, providerType, providerVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			assert.Contains(t, htmlDoc.doc.Find("pre code").Text(), "required_providers")
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "DELETE", versionURL).
+				AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/versions", providersURL, providerType))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+	})
+}
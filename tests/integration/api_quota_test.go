@@ -0,0 +1,173 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	issues_model "code.gitea.io/gitea/models/issues"
+	quota_model "code.gitea.io/gitea/models/quota"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIQuota(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.Quota.Enabled, true)()
+
+	adminToken := getUserToken(t, "user1", auth_model.AccessTokenScopeWriteAdmin)
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+	userToken := getUserToken(t, user.Name, auth_model.AccessTokenScopeWriteUser, auth_model.AccessTokenScopeWriteIssue, auth_model.AccessTokenScopeWritePackage)
+
+	t.Run("AdminManageRulesAndGroups", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "POST", "/api/v1/admin/quota/rules", api.CreateQuotaRuleOption{
+			Name:     "no-uploads",
+			Limit:    0,
+			Subjects: []string{"size:attachments", "size:packages"},
+		}).AddTokenAuth(adminToken)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		rule := &api.QuotaRule{}
+		DecodeJSON(t, resp, rule)
+		assert.Equal(t, "no-uploads", rule.Name)
+		assert.Equal(t, []string{"size:attachments", "size:packages"}, rule.Subjects)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/quota/rules", api.CreateQuotaRuleOption{
+			Name:     "no-uploads",
+			Subjects: []string{"size:all"},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/quota/rules", api.CreateQuotaRuleOption{
+			Name:     "invalid",
+			Subjects: []string{"size:unknown"},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/quota/groups", api.CreateQuotaGroupOption{
+			Name:  "restricted",
+			Rules: []string{"rule-not-exist"},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/admin/quota/groups", api.CreateQuotaGroupOption{
+			Name:  "restricted",
+			Rules: []string{"no-uploads"},
+		}).AddTokenAuth(adminToken)
+		resp = MakeRequest(t, req, http.StatusCreated)
+		group := &api.QuotaGroup{}
+		DecodeJSON(t, resp, group)
+		require.Len(t, group.Rules, 1)
+
+		req = NewRequest(t, "PUT", "/api/v1/admin/quota/groups/restricted/users/"+user.Name).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", "/api/v1/admin/quota/groups/restricted/users").AddTokenAuth(adminToken)
+		resp = MakeRequest(t, req, http.StatusOK)
+		var users []*api.User
+		DecodeJSON(t, resp, &users)
+		require.Len(t, users, 1)
+		assert.Equal(t, user.ID, users[0].ID)
+
+		// only site admins could manage the quota
+		req = NewRequest(t, "GET", "/api/v1/admin/quota/rules").AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusForbidden)
+	})
+
+	t.Run("GetQuota", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/api/v1/user/quota").AddTokenAuth(userToken)
+		resp := MakeRequest(t, req, http.StatusOK)
+		info := &api.QuotaInfo{}
+		DecodeJSON(t, resp, info)
+		require.Len(t, info.Groups, 1)
+		assert.Equal(t, "restricted", info.Groups[0].Name)
+		assert.Equal(t, info.Used.Git+info.Used.LFS+info.Used.Packages+info.Used.Artifacts+info.Used.Attachments, info.Used.Total)
+
+		req = NewRequest(t, "GET", "/api/v1/admin/users/"+user.Name+"/quota").AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+
+		// the organization isn't in any group
+		req = NewRequest(t, "GET", "/api/v1/orgs/org3/quota").AddTokenAuth(getUserToken(t, "user2", auth_model.AccessTokenScopeReadOrganization))
+		resp = MakeRequest(t, req, http.StatusOK)
+		info = &api.QuotaInfo{}
+		DecodeJSON(t, resp, info)
+		assert.Empty(t, info.Groups)
+	})
+
+	t.Run("SettingsPage", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		session := loginUser(t, user.Name)
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/user/settings/storage"), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "no-uploads")
+		session.MakeRequest(t, NewRequest(t, "GET", "/org/org3/settings/storage"), http.StatusOK)
+	})
+
+	t.Run("UploadsRejected", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, OwnerID: user.ID})
+		issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID})
+		uploadAttachment := func(t *testing.T, expectedStatus int) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, err := writer.CreateFormFile("attachment", "image.png")
+			require.NoError(t, err)
+			buff := generateImg()
+			_, err = part.Write(buff.Bytes())
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			req := NewRequestWithBody(t, "POST", fmt.Sprintf("/api/v1/repos/%s/issues/%d/assets", repo.FullName(), issue.Index), body).
+				AddTokenAuth(userToken)
+			req.Header.Add("Content-Type", writer.FormDataContentType())
+			MakeRequest(t, req, expectedStatus)
+		}
+
+		uploadAttachment(t, http.StatusRequestEntityTooLarge)
+
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/generic/quota/1.0/file.bin", user.Name), bytes.NewReader([]byte{1, 2, 3})).
+			AddTokenAuth(userToken)
+		MakeRequest(t, req, http.StatusForbidden)
+
+		limit := int64(-1)
+		req = NewRequestWithJSON(t, "PATCH", "/api/v1/admin/quota/rules/no-uploads", api.EditQuotaRuleOption{Limit: &limit}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+
+		uploadAttachment(t, http.StatusCreated)
+	})
+
+	t.Run("AdminDelete", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", "/api/v1/admin/quota/groups/restricted/rules/no-uploads").AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusNoContent)
+		req = NewRequest(t, "DELETE", "/api/v1/admin/quota/groups/restricted/rules/no-uploads").AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "DELETE", "/api/v1/admin/quota/rules/no-uploads").AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &quota_model.Rule{Name: "no-uploads"})
+
+		req = NewRequest(t, "DELETE", "/api/v1/admin/quota/groups/restricted").AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &quota_model.GroupUser{UserID: user.ID})
+	})
+}
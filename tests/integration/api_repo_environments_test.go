@@ -0,0 +1,145 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	auth_model "code.gitea.io/gitea/models/auth"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIRepoEnvironments(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID})
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+	envURL := fmt.Sprintf("/api/v1/repos/%s/environments/production", repo.FullName())
+
+	t.Run("CreateOrUpdate", func(t *testing.T) {
+		req := NewRequestWithJSON(t, "PUT", envURL, api.CreateOrUpdateEnvironmentOption{
+			WaitTimer:     5,
+			BranchFilters: []string{"master", "release/*"},
+			Reviewers:     []string{user.Name},
+		}).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		env := &api.Environment{}
+		DecodeJSON(t, resp, env)
+		assert.Equal(t, "production", env.Name)
+		assert.EqualValues(t, 5, env.WaitTimer)
+		assert.Equal(t, []string{"master", "release/*"}, env.BranchFilters)
+		require.Len(t, env.Reviewers, 1)
+		assert.Equal(t, user.ID, env.Reviewers[0].ID)
+
+		req = NewRequestWithJSON(t, "PUT", envURL, api.CreateOrUpdateEnvironmentOption{}).AddTokenAuth(token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		env = &api.Environment{}
+		DecodeJSON(t, resp, env)
+		assert.EqualValues(t, 0, env.WaitTimer)
+		assert.Empty(t, env.BranchFilters)
+		assert.Empty(t, env.Reviewers)
+
+		req = NewRequestWithJSON(t, "PUT", envURL, api.CreateOrUpdateEnvironmentOption{
+			WaitTimer: actions_model.MaxEnvironmentWaitTimer + 1,
+		}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		req = NewRequestWithJSON(t, "PUT", envURL, api.CreateOrUpdateEnvironmentOption{
+			Reviewers: []string{"user-not-exist"},
+		}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/environments", repo.FullName())).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var envs []*api.Environment
+		DecodeJSON(t, resp, &envs)
+		require.Len(t, envs, 1)
+		assert.Equal(t, "production", envs[0].Name)
+
+		req = NewRequest(t, "GET", envURL).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusOK)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/environments/staging", repo.FullName())).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("SecretsAndVariables", func(t *testing.T) {
+		req := NewRequestWithJSON(t, "PUT", envURL+"/secrets/deploy_key", api.CreateOrUpdateSecretOption{Data: "secret"}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusCreated)
+		req = NewRequestWithJSON(t, "PUT", envURL+"/secrets/deploy_key", api.CreateOrUpdateSecretOption{Data: "secret2"}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		// the secret of the environment isn't a secret of the repository
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/actions/secrets", repo.FullName())).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var secrets []*api.Secret
+		DecodeJSON(t, resp, &secrets)
+		assert.Empty(t, secrets)
+
+		req = NewRequest(t, "GET", envURL+"/secrets").AddTokenAuth(token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &secrets)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, "DEPLOY_KEY", secrets[0].Name)
+
+		req = NewRequestWithJSON(t, "POST", envURL+"/variables/target", api.CreateVariableOption{Value: "prod"}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusCreated)
+		req = NewRequestWithJSON(t, "POST", envURL+"/variables/target", api.CreateVariableOption{Value: "prod"}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusConflict)
+		req = NewRequestWithJSON(t, "PUT", envURL+"/variables/target", api.UpdateVariableOption{Value: "production"}).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		req = NewRequest(t, "GET", envURL+"/variables/target").AddTokenAuth(token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		variable := &api.ActionVariable{}
+		DecodeJSON(t, resp, variable)
+		assert.Equal(t, "production", variable.Data)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/actions/variables/target", repo.FullName())).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "DELETE", envURL+"/secrets/deploy_key").AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNoContent)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		env := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionEnvironment{RepoID: repo.ID, Name: "production"})
+
+		req := NewRequest(t, "DELETE", envURL).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		unittest.AssertNotExistsBean(t, &actions_model.ActionEnvironment{ID: env.ID})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionVariable{EnvironmentID: env.ID})
+		unittest.AssertNotExistsBean(t, &secret_model.Secret{EnvironmentID: env.ID})
+
+		req = NewRequest(t, "DELETE", envURL).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Deployments", func(t *testing.T) {
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/deployments?status=waiting", repo.FullName())).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var deployments []*api.Deployment
+		DecodeJSON(t, resp, &deployments)
+		assert.Empty(t, deployments)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/deployments?status=unknown", repo.FullName())).AddTokenAuth(token)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+	})
+}
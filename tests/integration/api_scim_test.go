@@ -0,0 +1,295 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/routers"
+	"code.gitea.io/gitea/services/auth/source/pam"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scimTestUser struct {
+	ID         string `json:"id"`
+	ExternalID string `json:"externalId"`
+	UserName   string `json:"userName"`
+	Active     bool   `json:"active"`
+	Groups     []struct {
+		Value   string `json:"value"`
+		Display string `json:"display"`
+	} `json:"groups"`
+}
+
+type scimTestGroup struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Members     []struct {
+		Value string `json:"value"`
+	} `json:"members"`
+}
+
+type scimTestList[T any] struct {
+	TotalResults int `json:"totalResults"`
+	Resources    []T `json:"Resources"`
+}
+
+func TestAPISCIM(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+	defer test.MockVariableValue(&setting.SCIM.Enabled, true)()
+	defer test.MockVariableValue(&setting.SCIM.AuthSource, "scim-idp")()
+	defer test.MockVariableValue(&testWebRoutes, routers.NormalRoutes())()
+
+	source := &auth_model.Source{
+		Type:     auth_model.PAM,
+		Name:     "scim-idp",
+		IsActive: true,
+		Cfg:      &pam.Source{ServiceName: "gitea"},
+	}
+	require.NoError(t, auth_model.CreateSource(db.DefaultContext, source))
+
+	adminToken := getUserToken(t, "user1", auth_model.AccessTokenScopeWriteAdmin)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users"), http.StatusUnauthorized)
+
+		token := getUserToken(t, "user1", auth_model.AccessTokenScopeReadAdmin)
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users").AddTokenAuth(token), http.StatusForbidden)
+
+		token = getUserToken(t, "user2", auth_model.AccessTokenScopeWriteAdmin)
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users").AddTokenAuth(token), http.StatusForbidden)
+	})
+
+	var created scimTestUser
+	t.Run("CreateUser", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		body := map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+			"userName":   "scim-alice",
+			"externalId": "00u1a2b3c4",
+			"name":       map[string]string{"givenName": "Alice", "familyName": "Liddell"},
+			"emails":     []map[string]any{{"value": "alice@scim.example.com", "type": "work", "primary": true}},
+			"active":     true,
+		}
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Users", body).AddTokenAuth(adminToken)
+		resp := MakeRequest(t, req, http.StatusCreated)
+		assert.Equal(t, "application/scim+json", resp.Header().Get("Content-Type"))
+		DecodeJSON(t, resp, &created)
+		assert.Equal(t, "scim-alice", created.UserName)
+		assert.Equal(t, "00u1a2b3c4", created.ExternalID)
+		assert.True(t, created.Active)
+
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.Equal(t, "Alice Liddell", u.FullName)
+		assert.Equal(t, "alice@scim.example.com", u.Email)
+		assert.Equal(t, source.ID, u.LoginSource)
+		assert.Equal(t, auth_model.PAM, u.LoginType)
+		assert.Equal(t, "00u1a2b3c4", u.LoginName)
+		assert.Equal(t, fmt.Sprint(u.ID), created.ID)
+
+		// the user name and the email must be unique
+		req = NewRequestWithJSON(t, "POST", "/scim/v2/Users", body).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("GetUsers", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		var list scimTestList[scimTestUser]
+		resp := MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users").AddTokenAuth(adminToken), http.StatusOK)
+		DecodeJSON(t, resp, &list)
+		// only the users of the authentication source are listed
+		assert.Equal(t, 1, list.TotalResults)
+		require.Len(t, list.Resources, 1)
+		assert.Equal(t, "scim-alice", list.Resources[0].UserName)
+
+		for filter, count := range map[string]int{
+			`userName eq "scim-alice"`:                 1,
+			`externalId eq "00u1a2b3c4"`:               1,
+			`emails.value eq "alice@scim.example.com"`: 1,
+			`userName eq "user2"`:                      0,
+			`userName eq "nobody"`:                     0,
+			`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "scim-alice"`: 1,
+		} {
+			resp = MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users?filter="+url.QueryEscape(filter)).AddTokenAuth(adminToken), http.StatusOK)
+			list = scimTestList[scimTestUser]{}
+			DecodeJSON(t, resp, &list)
+			assert.Equal(t, count, list.TotalResults, filter)
+		}
+
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users?filter="+url.QueryEscape(`userName sw "scim"`)).AddTokenAuth(adminToken), http.StatusBadRequest)
+
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users/"+created.ID).AddTokenAuth(adminToken), http.StatusOK)
+		// the local users can't be read or changed
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users/2").AddTokenAuth(adminToken), http.StatusNotFound)
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Users/2").AddTokenAuth(adminToken), http.StatusNotFound)
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+created.ID, map[string]any{
+			"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{
+				{"op": "Replace", "path": "displayName", "value": "Alice L."},
+				{"op": "Replace", "path": `emails[type eq "work"].value`, "value": "alice.l@scim.example.com"},
+			},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.Equal(t, "Alice L.", u.FullName)
+		assert.Equal(t, "alice.l@scim.example.com", u.Email)
+
+		// deactivate the user with a string value like Entra ID
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+created.ID, map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{{"op": "Replace", "path": "active", "value": "False"}},
+		}).AddTokenAuth(adminToken)
+		var res scimTestUser
+		DecodeJSON(t, MakeRequest(t, req, http.StatusOK), &res)
+		assert.False(t, res.Active)
+		u = unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.False(t, u.IsActive)
+		assert.True(t, u.ProhibitLogin)
+
+		// reactivate the user without a path like Okta
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+created.ID, map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{{"op": "replace", "value": map[string]any{"active": true}}},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		u = unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.True(t, u.IsActive)
+		assert.False(t, u.ProhibitLogin)
+
+		req = NewRequestWithJSON(t, "PUT", "/scim/v2/Users/"+created.ID, map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:schemas:core:2.0:User"},
+			"userName":   "scim-alice",
+			"externalId": "00u1a2b3c4",
+			"name":       map[string]string{"givenName": "Alice", "familyName": "Hargreaves"},
+			"emails":     []map[string]any{{"value": "alice.l@scim.example.com", "primary": true}},
+			"active":     true,
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		u = unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.Equal(t, "Alice Hargreaves", u.FullName)
+
+		// the provisioned users can't be renamed
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Users/"+created.ID, map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{{"op": "replace", "path": "userName", "value": "scim-alice2"}},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusBadRequest)
+	})
+
+	var group scimTestGroup
+	t.Run("Groups", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		body := map[string]any{
+			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:Group"},
+			"displayName": "org3/engineering",
+			"members":     []map[string]any{{"value": created.ID}},
+		}
+		req := NewRequestWithJSON(t, "POST", "/scim/v2/Groups", body).AddTokenAuth(adminToken)
+		DecodeJSON(t, MakeRequest(t, req, http.StatusCreated), &group)
+		assert.Equal(t, "org3/engineering", group.DisplayName)
+		require.Len(t, group.Members, 1)
+		assert.Equal(t, created.ID, group.Members[0].Value)
+
+		team := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "engineering"})
+		u := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: "scim-alice"})
+		assert.True(t, team.IsMember(db.DefaultContext, u.ID))
+		assert.Equal(t, fmt.Sprint(team.ID), group.ID)
+
+		req = NewRequestWithJSON(t, "POST", "/scim/v2/Groups", body).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequestWithJSON(t, "POST", "/scim/v2/Groups", map[string]any{
+			"displayName": "no-such-org/engineering",
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		var list scimTestList[scimTestGroup]
+		resp := MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Groups?filter="+url.QueryEscape(`displayName eq "org3/engineering"`)).AddTokenAuth(adminToken), http.StatusOK)
+		DecodeJSON(t, resp, &list)
+		require.Equal(t, 1, list.TotalResults)
+		assert.Equal(t, group.ID, list.Resources[0].ID)
+
+		var userRes scimTestUser
+		DecodeJSON(t, MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users/"+created.ID).AddTokenAuth(adminToken), http.StatusOK), &userRes)
+		require.Len(t, userRes.Groups, 1)
+		assert.Equal(t, "org3/engineering", userRes.Groups[0].Display)
+
+		// an existing team with local members, the local members are kept
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/2", map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{{"op": "add", "path": "members", "value": []map[string]any{{"value": created.ID}}}},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		team1 := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 2})
+		assert.True(t, team1.IsMember(db.DefaultContext, u.ID))
+		assert.True(t, team1.IsMember(db.DefaultContext, 2))
+
+		req = NewRequestWithJSON(t, "PUT", "/scim/v2/Groups/2", map[string]any{
+			"schemas":     []string{"urn:ietf:params:scim:schemas:core:2.0:Group"},
+			"displayName": "org3/team1",
+			"members":     []map[string]any{},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusOK)
+		assert.False(t, team1.IsMember(db.DefaultContext, u.ID))
+		assert.True(t, team1.IsMember(db.DefaultContext, 2))
+
+		// the local users can't be added by the identity provider
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/"+group.ID, map[string]any{
+			"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{{"op": "add", "path": "members", "value": []map[string]any{{"value": "2"}}}},
+		}).AddTokenAuth(adminToken)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		// rename the group and remove its member
+		req = NewRequestWithJSON(t, "PATCH", "/scim/v2/Groups/"+group.ID, map[string]any{
+			"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+			"Operations": []map[string]any{
+				{"op": "replace", "value": map[string]any{"id": group.ID, "displayName": "org3/platform"}},
+				{"op": "remove", "path": fmt.Sprintf(`members[value eq "%s"]`, created.ID)},
+			},
+		}).AddTokenAuth(adminToken)
+		var patched scimTestGroup
+		DecodeJSON(t, MakeRequest(t, req, http.StatusOK), &patched)
+		assert.Equal(t, "org3/platform", patched.DisplayName)
+		assert.Empty(t, patched.Members)
+		team = unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: team.ID})
+		assert.Equal(t, "platform", team.Name)
+		assert.False(t, team.IsMember(db.DefaultContext, u.ID))
+
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Groups/1").AddTokenAuth(adminToken), http.StatusBadRequest)
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Groups/"+group.ID).AddTokenAuth(adminToken), http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &organization.Team{ID: team.ID})
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		MakeRequest(t, NewRequest(t, "DELETE", "/scim/v2/Users/"+created.ID).AddTokenAuth(adminToken), http.StatusNoContent)
+		unittest.AssertNotExistsBean(t, &user_model.User{Name: "scim-alice"})
+		MakeRequest(t, NewRequest(t, "GET", "/scim/v2/Users/"+created.ID).AddTokenAuth(adminToken), http.StatusNotFound)
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	audit_model "code.gitea.io/gitea/models/audit"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	// failed and successful logins
+	req := NewRequestWithValues(t, "POST", "/user/login", map[string]string{
+		"user_name": "user2",
+		"password":  "wrong password",
+	})
+	emptyTestSession(t).MakeRequest(t, req, http.StatusOK)
+	session := loginUser(t, "user2")
+
+	failed := unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionUserLoginFailed, TargetName: "user2"})
+	assert.Zero(t, failed.ActorID)
+	assert.JSONEq(t, `{"reason":"password"}`, failed.After)
+	login := unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionUserLogin, ActorName: "user2"})
+	assert.NotEmpty(t, login.IPAddress)
+
+	// the changes of a repository and of a repository of an organization
+	token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository, auth_model.AccessTokenScopeReadOrganization)
+	unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionAccessTokenCreate, ActorName: "user2", OwnerID: 2})
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/collaborators/user4", &api.AddCollaboratorOption{
+		Permission: util.ToPointer("write"),
+	}).AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusNoContent)
+	collaborator := unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionCollaboratorAdd, OwnerID: 2, RepoID: 1})
+	assert.Equal(t, "user2", collaborator.ActorName)
+	assert.Equal(t, "user4", collaborator.TargetName)
+	assert.JSONEq(t, `{"access_mode":"write"}`, collaborator.After)
+
+	repo3 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3})
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/org3/repo3", &api.EditRepoOption{
+		Private: util.ToPointer(!repo3.IsPrivate),
+	}).AddTokenAuth(token)
+	MakeRequest(t, req, http.StatusOK)
+	visibility := unittest.AssertExistsAndLoadBean(t, &audit_model.Event{Action: audit_model.ActionRepoVisibility, OwnerID: 3, RepoID: 3})
+	assert.JSONEq(t, fmt.Sprintf(`{"private":%t}`, repo3.IsPrivate), visibility.Before)
+	assert.JSONEq(t, fmt.Sprintf(`{"private":%t}`, !repo3.IsPrivate), visibility.After)
+
+	count, err := audit_model.VerifyChain(db.DefaultContext)
+	require.NoError(t, err)
+	assert.EqualValues(t, unittest.GetCount(t, &audit_model.Event{}), count)
+
+	listEvents := func(t *testing.T, url, token string) []*api.AuditEvent {
+		req := NewRequest(t, "GET", url).AddTokenAuth(token)
+		resp := MakeRequest(t, req, http.StatusOK)
+		var events []*api.AuditEvent
+		DecodeJSON(t, resp, &events)
+		return events
+	}
+	actionsOf := func(events []*api.AuditEvent) []string {
+		actions := make([]string, 0, len(events))
+		for _, e := range events {
+			actions = append(actions, e.Action)
+		}
+		return actions
+	}
+
+	t.Run("API", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		events := listEvents(t, "/api/v1/repos/user2/repo1/audit", token)
+		assert.Equal(t, []string{"collaborator_add"}, actionsOf(events))
+		assert.Equal(t, "user4", events[0].Target)
+		assert.Equal(t, "write", events[0].After["access_mode"])
+
+		events = listEvents(t, "/api/v1/orgs/org3/audit", token)
+		assert.Equal(t, []string{"repo_visibility"}, actionsOf(events))
+
+		adminToken := getUserToken(t, "user1", auth_model.AccessTokenScopeReadAdmin)
+		events = listEvents(t, "/api/v1/admin/audit?actor=user2", adminToken)
+		assert.Equal(t, []string{"repo_visibility", "collaborator_add", "access_token_create", "user_login"}, actionsOf(events))
+		events = listEvents(t, "/api/v1/admin/audit?action=user_login_failed", adminToken)
+		assert.Equal(t, []string{"user_login_failed"}, actionsOf(events))
+		events = listEvents(t, "/api/v1/admin/audit?target_type=repository&limit=1", adminToken)
+		assert.Equal(t, []string{"repo_visibility"}, actionsOf(events))
+		events = listEvents(t, "/api/v1/admin/audit?before=2000-01-01T00:00:00Z", adminToken)
+		assert.Empty(t, events)
+
+		// the repository admins and the organization owners only
+		user4Token := getUserToken(t, "user4", auth_model.AccessTokenScopeReadRepository, auth_model.AccessTokenScopeReadOrganization)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/repos/user2/repo1/audit").AddTokenAuth(user4Token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/orgs/org3/audit").AddTokenAuth(user4Token), http.StatusForbidden)
+		MakeRequest(t, NewRequest(t, "GET", "/api/v1/admin/audit").AddTokenAuth(token), http.StatusForbidden)
+	})
+
+	t.Run("Web", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/audit"), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Equal(t, 1, htmlDoc.Find(".repo-setting-content tbody tr").Length())
+		assert.Contains(t, htmlDoc.Find(".repo-setting-content tbody").Text(), "user4")
+
+		resp = session.MakeRequest(t, NewRequest(t, "GET", "/org/org3/settings/audit"), http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.Find(".org-setting-content tbody").Text(), "org3/repo3")
+
+		adminSession := loginUser(t, "user1")
+		resp = adminSession.MakeRequest(t, NewRequest(t, "GET", "/-/admin/audit?action=user_login_failed"), http.StatusOK)
+		htmlDoc = NewHTMLParser(t, resp.Body)
+		assert.Equal(t, 1, htmlDoc.Find(".admin-setting-content tbody tr").Length())
+		assert.Contains(t, htmlDoc.Find(".admin-setting-content tbody").Text(), "user2")
+
+		session.MakeRequest(t, NewRequest(t, "GET", "/-/admin/audit"), http.StatusForbidden)
+		loginUser(t, "user4").MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/settings/audit"), http.StatusNotFound)
+	})
+}
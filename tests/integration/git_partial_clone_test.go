@@ -0,0 +1,137 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPartialClone(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		ctx := NewAPITestContext(t, "user2", "repo1", auth_model.AccessTokenScopeWriteUser)
+		u.Path = ctx.GitPath()
+
+		t.Run("HTTP", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			testGitPartialClone(t, u)
+		})
+
+		t.Run("SSH", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			withKeyFile(t, "my-testing-key", func(keyFile string) {
+				t.Run("CreateUserKey", doAPICreateUserKey(ctx, "test-key", keyFile))
+
+				testGitPartialClone(t, createSSHUrl(ctx.GitPath(), u))
+			})
+		})
+	})
+}
+
+func testGitPartialClone(t *testing.T, u *url.URL) {
+	session := loginUser(t, "user2")
+
+	updateSettings := func(t *testing.T, enable bool, filters ...string) {
+		values := url.Values{
+			"_csrf":                 {GetUserCSRFToken(t, session)},
+			"action":                {"partial_clone"},
+			"partial_clone_filters": filters,
+		}
+		if enable {
+			values.Set("enable_partial_clone", "on")
+		}
+		req := NewRequestWithURLValues(t, "POST", "/user2/repo1/settings", values)
+		session.MakeRequest(t, req, http.StatusSeeOther)
+	}
+
+	clone := func(t *testing.T, filter string) (string, error) {
+		dstPath := t.TempDir()
+		_, _, err := git.NewCommand(git.DefaultContext, "-c", "protocol.version=2", "clone", "--no-checkout").
+			AddOptionFormat("--filter=%s", filter).
+			AddDynamicArguments(u.String(), dstPath).
+			RunStdString(&git.RunOpts{})
+		return dstPath, err
+	}
+
+	missingObjects := func(t *testing.T, dstPath string) int {
+		stdout, _, err := git.NewCommand(git.DefaultContext, "rev-list", "--objects", "--all", "--missing=print").
+			RunStdString(&git.RunOpts{Dir: dstPath})
+		assert.NoError(t, err)
+		count := 0
+		for _, line := range strings.Split(stdout, "\n") {
+			if strings.HasPrefix(line, "?") {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("Filters", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		for _, filter := range []string{"blob:none", "blob:limit=1", "tree:0"} {
+			dstPath, err := clone(t, filter)
+			assert.NoError(t, err, filter)
+
+			stdout, _, err := git.NewCommand(git.DefaultContext, "config", "remote.origin.promisor").RunStdString(&git.RunOpts{Dir: dstPath})
+			assert.NoError(t, err)
+			assert.Equal(t, "true", strings.TrimSpace(stdout))
+			assert.Positive(t, missingObjects(t, dstPath), filter)
+		}
+	})
+
+	t.Run("MaxTreeDepth", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		defer test.MockVariableValue(&setting.Git.PartialCloneMaxTreeDepth, 0)()
+
+		_, err := clone(t, "tree:1")
+		assert.Error(t, err)
+		_, err = clone(t, "tree:0")
+		assert.NoError(t, err)
+	})
+
+	t.Run("RepoFilters", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		updateSettings(t, true, "blob:none")
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"})
+		assert.False(t, repo.DisablePartialClone)
+		assert.Equal(t, []string{"blob:none"}, repo.PartialCloneFilters)
+
+		_, err := clone(t, "blob:none")
+		assert.NoError(t, err)
+		_, err = clone(t, "tree:0")
+		assert.Error(t, err)
+
+		updateSettings(t, true, setting.Git.PartialCloneFilters...)
+		repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerName: "user2", Name: "repo1"})
+		assert.Empty(t, repo.PartialCloneFilters)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		updateSettings(t, false)
+		defer updateSettings(t, true, setting.Git.PartialCloneFilters...)
+
+		// the server ignores the filter and sends all objects
+		dstPath, err := clone(t, "blob:none")
+		assert.NoError(t, err)
+		assert.Zero(t, missingObjects(t, dstPath))
+	})
+}
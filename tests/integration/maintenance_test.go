@@ -0,0 +1,94 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setMaintenanceConfig(t *testing.T, session *TestSession, key, value string) {
+	req := NewRequestWithValues(t, "POST", "/-/admin/config", map[string]string{
+		"_csrf": GetUserCSRFToken(t, session),
+		"key":   key,
+		"value": value,
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		adminSession := loginUser(t, "user1")
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository, auth_model.AccessTokenScopeWriteUser)
+
+		dstPath := t.TempDir()
+		u.Path = "user2/repo1.git"
+		u.User = url.UserPassword("user2", userPassword)
+		doGitClone(dstPath, u)(t)
+
+		setMaintenanceConfig(t, adminSession, "maintenance.message", "Upgrading the storage")
+		setMaintenanceConfig(t, adminSession, "maintenance.enabled", "true")
+		defer func() {
+			setMaintenanceConfig(t, adminSession, "maintenance.enabled", "false")
+			setMaintenanceConfig(t, adminSession, "maintenance.message", "")
+			assert.False(t, queue.GetManager().IsPaused())
+		}()
+
+		assert.True(t, queue.GetManager().IsPaused())
+
+		t.Run("WebRead", func(t *testing.T) {
+			resp := session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo1"), http.StatusOK)
+			assert.Contains(t, resp.Body.String(), "Upgrading the storage")
+		})
+
+		t.Run("WebWrite", func(t *testing.T) {
+			req := NewRequestWithValues(t, "POST", "/user2/repo1/issues/new", map[string]string{
+				"_csrf": GetUserCSRFToken(t, session),
+				"title": "issue during maintenance",
+			})
+			req.Header.Set("Accept", "text/html")
+			resp := session.MakeRequest(t, req, http.StatusServiceUnavailable)
+			assert.Contains(t, resp.Body.String(), "Upgrading the storage")
+		})
+
+		t.Run("APIRead", func(t *testing.T) {
+			req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1").AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusOK)
+		})
+
+		t.Run("APIWrite", func(t *testing.T) {
+			req := NewRequestWithJSON(t, "POST", "/api/v1/user/repos", &api.CreateRepoOption{
+				Name: "maintenance-repo",
+			}).AddTokenAuth(token)
+			resp := MakeRequest(t, req, http.StatusServiceUnavailable)
+			var apiErr api.APIError
+			DecodeJSON(t, resp, &apiErr)
+			assert.Equal(t, "Upgrading the storage", apiErr.Message)
+		})
+
+		t.Run("Clone", func(t *testing.T) {
+			doGitClone(t.TempDir(), u)(t)
+		})
+
+		t.Run("Push", func(t *testing.T) {
+			doGitCreateBranch(dstPath, "maintenance")(t)
+			doGitAddSomeCommits(dstPath, "maintenance")(t)
+			_, _, err := git.NewCommand(git.DefaultContext, "push", "origin", "maintenance").RunStdString(&git.RunOpts{Dir: dstPath})
+			assert.ErrorContains(t, err, "Upgrading the storage")
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			MakeRequest(t, NewRequest(t, "GET", "/user2/repo1/archive/master.zip"), http.StatusOK)
+		})
+	})
+}
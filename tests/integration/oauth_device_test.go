@@ -0,0 +1,152 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/test"
+	oauth2_provider "code.gitea.io/gitea/services/oauth2_provider"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthDeviceFlow(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	app, err := auth_model.CreateOAuth2Application(db.DefaultContext, auth_model.CreateOAuth2ApplicationOptions{
+		Name:             "device-app",
+		UserID:           2,
+		EnableDeviceFlow: true,
+		RedirectURIs:     []string{"http://127.0.0.1"},
+	})
+	require.NoError(t, err)
+
+	requestDeviceAuthorization := func(t *testing.T, clientID string) *oauth2_provider.DeviceAuthorizationResponse {
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+			"client_id": clientID,
+			"scope":     "read:user",
+		})
+		resp := MakeRequest(t, req, http.StatusOK)
+		authorization := new(oauth2_provider.DeviceAuthorizationResponse)
+		DecodeJSON(t, resp, authorization)
+		return authorization
+	}
+	pollToken := func(t *testing.T, deviceCode string, expectedStatus int) *httptest.ResponseRecorder {
+		req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+			"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
+			"client_id":   app.ClientID,
+			"device_code": deviceCode,
+		})
+		return MakeRequest(t, req, expectedStatus)
+	}
+	pollTokenError := func(t *testing.T, deviceCode string) string {
+		tokenErr := new(oauth2_provider.AccessTokenError)
+		DecodeJSON(t, pollToken(t, deviceCode, http.StatusBadRequest), tokenErr)
+		return string(tokenErr.ErrorCode)
+	}
+	verifyDevice := func(t *testing.T, session *TestSession, userCode string, granted bool) {
+		req := NewRequest(t, "GET", "/login/oauth/device?user_code="+userCode)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "#authorize-device", true)
+
+		req = NewRequestWithValues(t, "POST", "/login/oauth/device", map[string]string{
+			"_csrf":     htmlDoc.GetCSRF(),
+			"user_code": userCode,
+			"granted":   map[bool]string{true: "true", false: "false"}[granted],
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+	}
+
+	session := loginUser(t, "user2")
+
+	t.Run("Approved", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		authorization := requestDeviceAuthorization(t, app.ClientID)
+		assert.NotEmpty(t, authorization.DeviceCode)
+		assert.Len(t, authorization.UserCode, 9)
+		assert.Equal(t, setting.AppURL+"login/oauth/device", authorization.VerificationURI)
+		assert.Equal(t, setting.OAuth2.DeviceCodePollingInterval, authorization.Interval)
+		assert.Positive(t, authorization.ExpiresIn)
+
+		assert.Equal(t, oauth2_provider.AccessTokenErrorCodeAuthorizationPending, pollTokenError(t, authorization.DeviceCode))
+		// polling again right away is too fast
+		assert.Equal(t, oauth2_provider.AccessTokenErrorCodeSlowDown, pollTokenError(t, authorization.DeviceCode))
+
+		// the users may enter the code in lower case
+		verifyDevice(t, session, strings.ToLower(authorization.UserCode), true)
+
+		resp := pollToken(t, authorization.DeviceCode, http.StatusOK)
+		token := new(oauth2_provider.AccessTokenResponse)
+		DecodeJSON(t, resp, token)
+		assert.NotEmpty(t, token.AccessToken)
+		assert.NotEmpty(t, token.RefreshToken)
+
+		req := NewRequest(t, "GET", "/api/v1/user").AddTokenAuth(token.AccessToken)
+		resp = MakeRequest(t, req, http.StatusOK)
+		user := new(api.User)
+		DecodeJSON(t, resp, user)
+		assert.Equal(t, "user2", user.UserName)
+
+		// the device code can't be used twice
+		assert.Equal(t, oauth2_provider.AccessTokenErrorCodeInvalidGrant, pollTokenError(t, authorization.DeviceCode))
+		// nor the user code
+		req = NewRequest(t, "GET", "/login/oauth/device?user_code="+authorization.UserCode)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		NewHTMLParser(t, resp.Body).AssertElement(t, "#authorize-device", false)
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		authorization := requestDeviceAuthorization(t, app.ClientID)
+		verifyDevice(t, session, authorization.UserCode, false)
+		assert.Equal(t, oauth2_provider.AccessTokenErrorCodeAccessDenied, pollTokenError(t, authorization.DeviceCode))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+		defer test.MockVariableValue(&setting.OAuth2.DeviceCodeExpirationTime, 0)()
+
+		authorization := requestDeviceAuthorization(t, app.ClientID)
+		assert.Equal(t, oauth2_provider.AccessTokenErrorCodeExpiredToken, pollTokenError(t, authorization.DeviceCode))
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		// the fixture application doesn't enable the device flow
+		fixtureApp := unittest.AssertExistsAndLoadBean(t, &auth_model.OAuth2Application{ID: 1})
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+			"client_id":     fixtureApp.ClientID,
+			"client_secret": "4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA=",
+		})
+		resp := MakeRequest(t, req, http.StatusBadRequest)
+		tokenErr := new(oauth2_provider.AccessTokenError)
+		DecodeJSON(t, resp, tokenErr)
+		assert.EqualValues(t, oauth2_provider.AccessTokenErrorCodeUnauthorizedClient, tokenErr.ErrorCode)
+	})
+
+	t.Run("OIDCWellKnown", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := MakeRequest(t, NewRequest(t, "GET", "/.well-known/openid-configuration"), http.StatusOK)
+		var wellKnown map[string]any
+		DecodeJSON(t, resp, &wellKnown)
+		assert.Equal(t, setting.AppURL+"login/oauth/device_authorization", wellKnown["device_authorization_endpoint"])
+		assert.Contains(t, wellKnown["grant_types_supported"], "urn:ietf:params:oauth:grant-type:device_code")
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/gitrepo"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	actions_service "code.gitea.io/gitea/services/actions"
+	"code.gitea.io/gitea/services/forms"
+	pull_service "code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
+	commitstatus_service "code.gitea.io/gitea/services/repository/commitstatus"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mergeQueueCheckWorkflow = `name: CI
+on: merge_group
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo test
+`
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo, err := repo_service.CreateRepositoryDirectly(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:             "test_merge_queue",
+			Readme:           "Default",
+			AutoInit:         true,
+			ObjectFormatName: git.Sha1ObjectFormat.Name(),
+			DefaultBranch:    "master",
+		})
+		require.NoError(t, err)
+		require.NoError(t, repo_service.UpdateRepositoryUnits(db.DefaultContext, repo, []repo_model.RepoUnit{{
+			RepoID: repo.ID,
+			Type:   unit_model.TypeActions,
+		}}, nil))
+		_, err = createFileInBranch(user2, repo, ".gitea/workflows/ci.yml", repo.DefaultBranch, mergeQueueCheckWorkflow)
+		require.NoError(t, err)
+		require.NoError(t, git_model.UpdateProtectBranch(db.DefaultContext, repo, &git_model.ProtectedBranch{
+			RepoID:              repo.ID,
+			RuleName:            "master",
+			CanPush:             true,
+			EnableStatusCheck:   true,
+			StatusCheckContexts: []string{"CI / test (*)"},
+			EnableMergeQueue:    true,
+		}, git_model.WhitelistOptions{}))
+
+		session := loginUser(t, "user2")
+		token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+
+		getStatusState := func(t *testing.T, commitID string) api.CommitStatusState {
+			statuses, _, err := git_model.GetLatestCommitStatus(db.DefaultContext, repo.ID, commitID, db.ListOptionsAll)
+			require.NoError(t, err)
+			for _, status := range statuses {
+				if status.Context == "CI / test (merge_group)" {
+					return status.State
+				}
+			}
+			return ""
+		}
+		// finishGroupChecks completes the job of the merge_group run of the merge group as a runner would
+		finishGroupChecks := func(t *testing.T, pr *issues_model.PullRequest, entry *pull_model.MergeQueueEntry, status actions_model.Status) {
+			run := &actions_model.ActionRun{RepoID: repo.ID, Event: webhook_module.HookEventMergeGroup, CommitSHA: entry.GroupCommitID}
+			assert.Eventually(t, func() bool {
+				return unittest.BeanExists(t, run)
+			}, 10*time.Second, 100*time.Millisecond)
+			run = unittest.AssertExistsAndLoadBean(t, run)
+			assert.Equal(t, pull_service.MergeGroupRefName(pr).String(), run.Ref)
+			// the status of the waiting job is posted on the merge group commit
+			assert.Eventually(t, func() bool {
+				return getStatusState(t, entry.GroupCommitID) == api.CommitStatusPending
+			}, 10*time.Second, 100*time.Millisecond)
+
+			job := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID})
+			job.Status = status
+			_, err := actions_model.UpdateRunJob(db.DefaultContext, job, nil, "status")
+			require.NoError(t, err)
+			actions_service.CreateCommitStatus(db.DefaultContext, job)
+		}
+		createPull := func(t *testing.T, branch, treePath, content string) *issues_model.PullRequest {
+			_, err := files_service.ChangeRepoFiles(db.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+				OldBranch: repo.DefaultBranch,
+				NewBranch: branch,
+				Files: []*files_service.ChangeRepoFile{
+					{
+						Operation:     "create",
+						TreePath:      treePath,
+						ContentReader: strings.NewReader(content),
+					},
+				},
+			})
+			require.NoError(t, err)
+			testPullCreate(t, session, "user2", repo.Name, false, repo.DefaultBranch, branch, "Add "+treePath)
+			pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{BaseRepoID: repo.ID, HeadBranch: branch})
+
+			headCommitID, err := gitrepo.GetBranchCommitID(db.DefaultContext, repo, branch)
+			require.NoError(t, err)
+			// the pull requests themselves are checked by an external CI
+			require.NoError(t, commitstatus_service.CreateCommitStatus(db.DefaultContext, repo, user2, headCommitID, &git_model.CommitStatus{
+				State:     api.CommitStatusSuccess,
+				TargetURL: "https://gitea.com",
+				Context:   "CI / test (pull_request)",
+			}))
+			assert.Eventually(t, func() bool {
+				pr = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr.ID})
+				return pr.Status == issues_model.PullRequestStatusMergeable
+			}, 10*time.Second, 100*time.Millisecond)
+			return pr
+		}
+		queuePull := func(t *testing.T, pr *issues_model.PullRequest) {
+			req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/pulls/%d/merge", repo.FullName(), pr.Index), &forms.MergePullRequestForm{
+				Do: string(repo_model.MergeStyleMerge),
+			}).AddTokenAuth(token)
+			MakeRequest(t, req, http.StatusCreated)
+		}
+		getEntry := func(t *testing.T, pr *issues_model.PullRequest) *pull_model.MergeQueueEntry {
+			exist, entry, err := pull_model.GetMergeQueueEntryByPullID(db.DefaultContext, pr.ID)
+			require.NoError(t, err)
+			if !exist {
+				return nil
+			}
+			return entry
+		}
+		waitForGroup := func(t *testing.T, pr *issues_model.PullRequest, baseCommitID string) *pull_model.MergeQueueEntry {
+			var entry *pull_model.MergeQueueEntry
+			assert.Eventually(t, func() bool {
+				entry = getEntry(t, pr)
+				return entry != nil && entry.GroupCommitID != "" && entry.BaseCommitID == baseCommitID
+			}, 10*time.Second, 100*time.Millisecond)
+			require.NotNil(t, entry)
+			return entry
+		}
+		waitForRemoval := func(t *testing.T, pr *issues_model.PullRequest, reason string) {
+			assert.Eventually(t, func() bool {
+				return getEntry(t, pr) == nil
+			}, 10*time.Second, 100*time.Millisecond)
+			unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: pr.IssueID, Type: issues_model.CommentTypePRRemovedFromMergeQueue, Content: reason})
+			assert.False(t, git.IsReferenceExist(db.DefaultContext, repo.RepoPath(), pull_service.MergeGroupRefName(pr).String()))
+		}
+		getMasterCommitID := func(t *testing.T) string {
+			commitID, err := gitrepo.GetBranchCommitID(db.DefaultContext, repo, "master")
+			require.NoError(t, err)
+			return commitID
+		}
+
+		prA := createPull(t, "add-a", "a.txt", "a\n")
+		prConflict := createPull(t, "add-a-conflict", "a.txt", "conflict\n")
+		prB := createPull(t, "add-b", "b.txt", "b\n")
+		initialCommitID := getMasterCommitID(t)
+
+		var groupA, groupB *pull_model.MergeQueueEntry
+		t.Run("Queue", func(t *testing.T) {
+			queuePull(t, prA)
+			queuePull(t, prConflict)
+			queuePull(t, prB)
+			unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: prA.IssueID, Type: issues_model.CommentTypePRAddedToMergeQueue})
+
+			// the merge groups are built on top of each other, the base branch isn't changed
+			groupA = waitForGroup(t, prA, initialCommitID)
+			groupB = waitForGroup(t, prB, groupA.GroupCommitID)
+			assert.Equal(t, initialCommitID, getMasterCommitID(t))
+
+			gitRepo, err := gitrepo.OpenRepository(db.DefaultContext, repo)
+			require.NoError(t, err)
+			defer gitRepo.Close()
+			commitID, err := gitRepo.GetRefCommitID(pull_service.MergeGroupRefName(prA).String())
+			require.NoError(t, err)
+			assert.Equal(t, groupA.GroupCommitID, commitID)
+			commitID, err = gitRepo.GetRefCommitID(pull_service.MergeGroupRefName(prB).String())
+			require.NoError(t, err)
+			assert.Equal(t, groupB.GroupCommitID, commitID)
+		})
+
+		t.Run("EjectConflict", func(t *testing.T) {
+			// the pull request conflicts with the merge group of the pull request queued before it
+			waitForRemoval(t, prConflict, pull_service.MergeQueueRemovedConflict)
+		})
+
+		t.Run("PushToMergeGroupBranch", func(t *testing.T) {
+			cloneURL, _ := url.Parse(u.String())
+			cloneURL.Path = repo.FullName() + ".git"
+			cloneURL.User = url.UserPassword("user2", userPassword)
+			dstPath := t.TempDir()
+			doGitClone(dstPath, cloneURL)(t)
+
+			doGitCreateBranch(dstPath, "merge-group")(t)
+			doGitAddSomeCommits(dstPath, "merge-group")(t)
+			push := func(t *testing.T, refName git.RefName) {
+				_, stderr, err := git.NewCommand(git.DefaultContext, "push", "-f", "origin").AddDynamicArguments("merge-group:" + refName.String()).RunStdString(&git.RunOpts{Dir: dstPath})
+				assert.Error(t, err)
+				assert.Contains(t, stderr, "managed by the merge queue")
+			}
+
+			newRef := git.RefNameFromBranch(pull_service.MergeQueueBranchPrefix + "master/pr-100")
+			push(t, newRef)
+			assert.False(t, git.IsReferenceExist(db.DefaultContext, repo.RepoPath(), newRef.String()))
+
+			// the existing merge groups can't be overwritten either
+			push(t, pull_service.MergeGroupRefName(prA))
+			assert.Equal(t, groupA.GroupCommitID, getEntry(t, prA).GroupCommitID)
+		})
+
+		t.Run("EjectFailedChecks", func(t *testing.T) {
+			finishGroupChecks(t, prA, groupA, actions_model.StatusFailure)
+			assert.Equal(t, api.CommitStatusFailure, getStatusState(t, groupA.GroupCommitID))
+			waitForRemoval(t, prA, pull_service.MergeQueueRemovedChecksFailed)
+
+			// the merge group behind it is rebuilt on the base branch
+			groupB = waitForGroup(t, prB, initialCommitID)
+			assert.Equal(t, initialCommitID, getMasterCommitID(t))
+			prA = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: prA.ID})
+			assert.False(t, prA.HasMerged)
+		})
+
+		t.Run("FastForward", func(t *testing.T) {
+			finishGroupChecks(t, prB, groupB, actions_model.StatusSuccess)
+			assert.Equal(t, api.CommitStatusSuccess, getStatusState(t, groupB.GroupCommitID))
+			assert.Eventually(t, func() bool {
+				return getEntry(t, prB) == nil
+			}, 10*time.Second, 100*time.Millisecond)
+
+			assert.Equal(t, groupB.GroupCommitID, getMasterCommitID(t))
+			assert.Eventually(t, func() bool {
+				prB = unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: prB.ID})
+				return prB.HasMerged
+			}, 10*time.Second, 100*time.Millisecond)
+			assert.Equal(t, groupB.GroupCommitID, prB.MergedCommitID)
+			assert.False(t, git.IsReferenceExist(db.DefaultContext, repo.RepoPath(), pull_service.MergeGroupRefName(prB).String()))
+		})
+
+		t.Run("EjectChecksTimeout", func(t *testing.T) {
+			masterCommitID := getMasterCommitID(t)
+			require.NoError(t, pull_service.AddToMergeQueue(db.DefaultContext, prA, user2, repo_model.MergeStyleMerge, "", "Merge add-a"))
+			groupA = waitForGroup(t, prA, masterCommitID)
+			assert.Eventually(t, func() bool {
+				return getStatusState(t, groupA.GroupCommitID) == api.CommitStatusPending
+			}, 10*time.Second, 100*time.Millisecond)
+
+			// the checks are still allowed to report
+			require.NoError(t, pull_service.RemoveTimedOutFromMergeQueues(db.DefaultContext, time.Hour))
+			assert.NotNil(t, getEntry(t, prA))
+
+			require.NoError(t, pull_service.RemoveTimedOutFromMergeQueues(db.DefaultContext, 0))
+			waitForRemoval(t, prA, pull_service.MergeQueueRemovedChecksTimeout)
+			assert.Equal(t, masterCommitID, getMasterCommitID(t))
+		})
+	})
+}
@@ -4,21 +4,29 @@
 package integration
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"testing"
 
+	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	issues_model "code.gitea.io/gitea/models/issues"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/gitrepo"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 	issue_service "code.gitea.io/gitea/services/issue"
+	pull_service "code.gitea.io/gitea/services/pull"
 	repo_service "code.gitea.io/gitea/services/repository"
 	files_service "code.gitea.io/gitea/services/repository/files"
 	"code.gitea.io/gitea/tests"
@@ -180,6 +188,87 @@ func TestPullView_CodeOwner(t *testing.T) {
 	})
 }
 
+func TestPullMerge_CodeOwnerApproval(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		repo, err := repo_service.CreateRepositoryDirectly(db.DefaultContext, user2, user2, repo_service.CreateRepoOptions{
+			Name:             "test_codeowner_approval",
+			Readme:           "Default",
+			AutoInit:         true,
+			ObjectFormatName: git.Sha1ObjectFormat.Name(),
+			DefaultBranch:    "master",
+		})
+		assert.NoError(t, err)
+
+		// the docs are owned by user5 only, the last matching rule wins
+		_, err = files_service.ChangeRepoFiles(db.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+			OldBranch: repo.DefaultBranch,
+			Files: []*files_service.ChangeRepoFile{
+				{
+					Operation:     "create",
+					TreePath:      "CODEOWNERS",
+					ContentReader: strings.NewReader(".* @user4\ndocs/.* @user5\n"),
+				},
+			},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, git_model.UpdateProtectBranch(db.DefaultContext, repo, &git_model.ProtectedBranch{
+			RepoID:                   repo.ID,
+			RuleName:                 "master",
+			RequireCodeOwnerApproval: true,
+		}, git_model.WhitelistOptions{}))
+
+		_, err = files_service.ChangeRepoFiles(db.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+			NewBranch: "update-docs",
+			Files: []*files_service.ChangeRepoFile{
+				{
+					Operation:     "create",
+					TreePath:      "docs/guide.md",
+					ContentReader: strings.NewReader("# Guide\n"),
+				},
+			},
+		})
+		assert.NoError(t, err)
+		session := loginUser(t, "user2")
+		testPullCreate(t, session, "user2", repo.Name, false, repo.DefaultBranch, "update-docs", "Update the docs")
+		pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{BaseRepoID: repo.ID, HeadBranch: "update-docs"})
+		prURL := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d", repo.OwnerName, repo.Name, pr.Index)
+		headCommitID, err := gitrepo.GetBranchCommitID(db.DefaultContext, repo, "update-docs")
+		assert.NoError(t, err)
+
+		assertUnapprovedCodeOwners := func(t *testing.T, expected []*api.PullRequestCodeOwnersRule) {
+			err := pull_service.CheckPullBranchProtections(db.DefaultContext, pr, false)
+			if len(expected) == 0 {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, models.IsErrDisallowedToMerge(err), "%v", err)
+			}
+
+			req := NewRequest(t, "GET", prURL).AddTokenAuth(getUserToken(t, "user2", auth_model.AccessTokenScopeReadRepository))
+			var apiPR api.PullRequest
+			DecodeJSON(t, MakeRequest(t, req, http.StatusOK), &apiPR)
+			assert.Equal(t, expected, apiPR.UnapprovedCodeOwners)
+		}
+		approve := func(t *testing.T, userName string) {
+			session := loginUser(t, userName)
+			req := NewRequest(t, "GET", path.Join(repo.OwnerName, repo.Name, "pulls", strconv.FormatInt(pr.Index, 10)))
+			htmlDoc := NewHTMLParser(t, session.MakeRequest(t, req, http.StatusOK).Body)
+			testSubmitReview(t, session, htmlDoc.GetCSRF(), repo.OwnerName, repo.Name, strconv.FormatInt(pr.Index, 10), headCommitID, "approve", http.StatusOK)
+		}
+
+		assertUnapprovedCodeOwners(t, []*api.PullRequestCodeOwnersRule{{Pattern: "docs/.*", Owners: []string{"user5"}}})
+
+		// the approval of the owner of another rule doesn't count
+		approve(t, "user4")
+		assertUnapprovedCodeOwners(t, []*api.PullRequestCodeOwnersRule{{Pattern: "docs/.*", Owners: []string{"user5"}}})
+
+		// the approval of the owner counts although user5 isn't an official reviewer
+		approve(t, "user5")
+		unittest.AssertExistsAndLoadBean(t, &issues_model.Review{IssueID: pr.IssueID, ReviewerID: 5, Type: issues_model.ReviewTypeApprove, Official: false})
+		assertUnapprovedCodeOwners(t, nil)
+	})
+}
+
 func TestPullView_GivenApproveOrRejectReviewOnClosedPR(t *testing.T) {
 	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
 		user1Session := loginUser(t, "user1")
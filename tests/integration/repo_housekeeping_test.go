@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/test"
+	"code.gitea.io/gitea/services/repository/housekeeping"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoHousekeeping(t *testing.T) {
+	defer test.MockVariableValue(&setting.Repository.Housekeeping.Enabled, true)()
+
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+
+		u.Path = "user2/repo1.git"
+		u.User = url.UserPassword("user2", userPassword)
+		dstPath := t.TempDir()
+		t.Run("Clone", doGitClone(dstPath, u))
+		t.Run("Push", func(t *testing.T) {
+			doCommitAndPush(t, 1024, dstPath, "housekeeping-")
+
+			assert.Eventually(t, func() bool {
+				h, err := repo_model.GetRepoHousekeeping(db.DefaultContext, repo.ID)
+				return assert.NoError(t, err) && h.PushesSinceCheck > 0
+			}, 10*time.Second, 100*time.Millisecond)
+		})
+
+		t.Run("Run", func(t *testing.T) {
+			assert.NoError(t, housekeeping.Run(db.DefaultContext, repo))
+
+			h := unittest.AssertExistsAndLoadBean(t, &repo_model.RepoHousekeeping{RepoID: repo.ID})
+			assert.Equal(t, repo_model.HousekeepingStatusIdle, h.Status)
+			assert.Contains(t, h.Tasks(), string(housekeeping.TaskFullRepack))
+			assert.Contains(t, h.Tasks(), string(housekeeping.TaskWriteCommitGraph))
+			assert.Empty(t, h.LastError)
+			assert.NotZero(t, h.LastFullRepackUnix)
+			assert.Zero(t, h.PushesSinceFullRepack)
+			assert.Zero(t, h.LooseObjects)
+			assert.EqualValues(t, 1, h.Packs)
+			assert.True(t, h.HasCommitGraph)
+			assert.True(t, h.HasBitmap)
+
+			// a second run has nothing left to optimize
+			assert.NoError(t, housekeeping.Run(db.DefaultContext, repo))
+			h = unittest.AssertExistsAndLoadBean(t, &repo_model.RepoHousekeeping{RepoID: repo.ID})
+			assert.Empty(t, h.Tasks())
+		})
+
+		t.Run("AdminPage", func(t *testing.T) {
+			session := loginUser(t, "user1")
+			req := NewRequest(t, "GET", "/-/admin/repos/housekeeping")
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc := NewHTMLParser(t, resp.Body)
+			assert.EqualValues(t, 1, htmlDoc.Find(`a[href="/user2/repo1"]`).Length())
+
+			req = NewRequest(t, "GET", "/-/admin/repos/housekeeping?status=failed")
+			resp = session.MakeRequest(t, req, http.StatusOK)
+			htmlDoc = NewHTMLParser(t, resp.Body)
+			assert.EqualValues(t, 0, htmlDoc.Find(`a[href="/user2/repo1"]`).Length())
+
+			req = NewRequestWithValues(t, "POST", "/-/admin/repos/housekeeping", map[string]string{
+				"_csrf":  GetUserCSRFToken(t, session),
+				"id":     "1",
+				"status": "idle",
+			})
+			resp = session.MakeRequest(t, req, http.StatusSeeOther)
+			assert.Equal(t, setting.AppSubURL+"/-/admin/repos/housekeeping?page=&status=idle", resp.Header().Get("Location"))
+
+			req = NewRequestWithValues(t, "POST", "/-/admin/repos/housekeeping", map[string]string{
+				"_csrf": GetUserCSRFToken(t, session),
+				"id":    "-1",
+			})
+			session.MakeRequest(t, req, http.StatusNotFound)
+
+			session = loginUser(t, "user2")
+			req = NewRequest(t, "GET", "/-/admin/repos/housekeeping")
+			session.MakeRequest(t, req, http.StatusForbidden)
+		})
+	})
+}
@@ -122,9 +122,9 @@ export function initAdminCommon(): void {
   // New authentication
   if (document.querySelector<HTMLDivElement>('.admin.new.authentication')) {
     document.querySelector<HTMLInputElement>('#auth_type')?.addEventListener('change', function () {
-      hideElem('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi');
+      hideElem('.ldap, .dldap, .smtp, .pam, .oauth2, .has-tls, .search-page-size, .sspi, .saml');
 
-      for (const input of document.querySelectorAll<HTMLInputElement>('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required]')) {
+      for (const input of document.querySelectorAll<HTMLInputElement>('.ldap input[required], .binddnrequired input[required], .dldap input[required], .smtp input[required], .pam input[required], .oauth2 input[required], .has-tls input[required], .sspi input[required], .saml input[required]')) {
         input.removeAttribute('required');
       }
 
@@ -171,6 +171,9 @@ export function initAdminCommon(): void {
             input.setAttribute('required', 'required');
           }
           break;
+        case '8': // SAML
+          showElem('.saml');
+          break;
       }
       if (authType === '2' || authType === '5') {
         onSecurityProtocolChange();